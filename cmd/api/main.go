@@ -5,12 +5,17 @@ import (
 	"event-coming/internal/cache"
 	"event-coming/internal/config"
 	"event-coming/internal/domain"
+	"event-coming/internal/geocoding"
 	"event-coming/internal/handler"
 	"event-coming/internal/repository/postgres"
 	"event-coming/internal/router"
 	"event-coming/internal/service"
 	"event-coming/internal/service/eta"
+	"event-coming/internal/storage"
 	"event-coming/internal/websocket"
+	"event-coming/internal/whatsapp"
+	"event-coming/pkg/pagination"
+	"event-coming/pkg/secretbox"
 	"fmt"
 	"net/http"
 	"os"
@@ -36,6 +41,10 @@ func main() {
 	if err != nil {
 		logger.Fatal("failed to load configuration", zap.Error(err))
 	}
+	pagination.Configure(cfg.Pagination.DefaultPerPage, cfg.Pagination.MaxPerPage)
+	if !domain.IsSupportedPhoneRegion(cfg.App.DefaultPhoneRegion) {
+		logger.Fatal("unsupported default phone region", zap.String("region", cfg.App.DefaultPhoneRegion))
+	}
 
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -53,6 +62,8 @@ func main() {
 
 	if cfg.App.Debug {
 		logger.Info("Running AutoMigrate (dev mode)...")
+		// Trigram indexes back the substring search on names and phone numbers
+		db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm")
 		db.AutoMigrate(
 			&domain.User{},
 			&domain.RefreshToken{},
@@ -64,6 +75,17 @@ func main() {
 			&domain.UserEntity{},
 			&domain.Location{},
 			&domain.Scheduler{},
+			&domain.NotificationDelivery{},
+			&domain.AuditLog{},
+			&domain.StatusHistory{},
+			&domain.WebhookSubscription{},
+			&domain.WebhookDelivery{},
+			&domain.Attachment{},
+			&domain.ParticipantGroup{},
+			&domain.ParticipantGroupMember{},
+			&domain.WhatsAppSender{},
+			&domain.EventOrganizer{},
+			&domain.APIKey{},
 		)
 	}
 
@@ -77,15 +99,15 @@ func main() {
 	logger.Info("Connected to Redis")
 
 	// Initialize WebSocket Hub and PubSub
-	wsHub := websocket.NewHub(logger)
+	wsHub := websocket.NewHub(websocket.NewConfig(&cfg.WebSocket), logger)
 	wsPubSub := websocket.NewPubSub(redisClient, wsHub, logger)
 
 	// Start WebSocket Hub
 	go wsHub.Run(ctx)
 
-	// Subscribe to all Redis channels
-	if err := wsPubSub.SubscribeAll(ctx); err != nil {
-		logger.Warn("Failed to subscribe to Redis PubSub", zap.Error(err))
+	// Start Redis PubSub (channels are subscribed as clients join events)
+	if err := wsPubSub.Start(ctx); err != nil {
+		logger.Warn("Failed to start Redis PubSub", zap.Error(err))
 	}
 
 	// Initialize repositories
@@ -97,36 +119,108 @@ func main() {
 	entityRepo := postgres.NewEntityRepository(db)
 	locationRepo := postgres.NewLocationRepository(db)
 	passRepo := postgres.NewPasswordResetTokenRepository(db)
+	deliveryRepo := postgres.NewNotificationDeliveryRepository(db)
+	transactor := postgres.NewTransactor(db)
+	auditRepo := postgres.NewAuditLogRepository(db)
+	statusHistoryRepo := postgres.NewStatusHistoryRepository(db)
+	webhookRepo := postgres.NewWebhookRepository(db)
+	attachmentRepo := postgres.NewAttachmentRepository(db)
+	groupRepo := postgres.NewParticipantGroupRepository(db)
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
+	whatsappSenderRepo := postgres.NewWhatsAppSenderRepository(db)
+	organizerRepo := postgres.NewEventOrganizerRepository(db)
+
+	// Storage de anexos de eventos
+	attachmentStorage, err := storage.New(&cfg.Storage)
+	if err != nil {
+		logger.Fatal("failed to initialize attachment storage", zap.Error(err))
+	}
+
+	// Geocoding do endereço dos eventos (nil se nenhum provider configurado)
+	geocoder, err := geocoding.New(&cfg.Geocoding, cache.NewGeocodeCache(redisClient, "geocode:", cfg.Geocoding.CacheTTL))
+	if err != nil {
+		logger.Fatal("failed to initialize geocoder", zap.Error(err))
+	}
+
 	// Initialize location buffer
 	locationBuffer := cache.NewLocationBuffer(redisClient)
 
+	// Initialize WhatsApp client (nil if not configured)
+	var whatsappClient *whatsapp.Client
+	if cfg.WhatsApp.AccessToken != "" {
+		whatsappClient = whatsapp.NewClient(&cfg.WhatsApp)
+	}
+
+	// Criptografa os tokens dos remetentes próprios das entidades (nil sem chave)
+	var senderTokens *secretbox.Box
+	if cfg.WhatsApp.SenderEncryptionKey != "" {
+		senderTokens, err = secretbox.NewFromBase64(cfg.WhatsApp.SenderEncryptionKey)
+		if err != nil {
+			logger.Fatal("invalid WhatsApp sender encryption key", zap.Error(err))
+		}
+	}
+
+	// Access tokens revogados (logout / reset de senha)
+	tokenDenylist := cache.NewTokenDenylist(redisClient, "auth:denylist:")
+
 	// Initialize services
+	auditService := service.NewAuditService(auditRepo, logger)
 	authService := service.NewAuthService(
 		userRepo,
 		tokenRepo,
 		passRepo,
 		entityRepo,
+		tokenDenylist,
+		auditService,
 		&cfg.JWT,
 	)
-	eventCacheService := service.NewEventCacheService(redisClient)
-	participantService := service.NewParticipantService(participantRepo, eventRepo)
-	eventService := service.NewEventService(eventRepo, schedulerRepo, participantRepo)
-	entityService := service.NewEntityService(entityRepo)
-	locationService := service.NewLocationService(locationRepo, participantRepo, eventRepo, locationBuffer, logger)
+	statusHistoryService := service.NewStatusHistoryService(statusHistoryRepo, logger)
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepo, &cfg.Webhooks, logger)
+	webhookService := service.NewWebhookService(webhookRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, logger)
+	eventCacheService := service.NewEventCacheService(redisClient, eventRepo, participantRepo, &cfg.Cache)
+	whatsappSenderService := service.NewWhatsAppSenderService(whatsappSenderRepo, whatsappClient, &cfg.WhatsApp, senderTokens, logger)
+	notificationService := service.NewNotificationService(whatsappSenderService, deliveryRepo, eventRepo, participantRepo, groupRepo, logger)
+	phoneNormalizer := service.NewPhoneNormalizer(entityRepo, cfg.App.DefaultPhoneRegion, logger)
+	participantService := service.NewParticipantService(participantRepo, eventRepo, transactor, eventCacheService, auditService, statusHistoryService, webhookDispatcher, notificationService, wsPubSub, &cfg.RSVP, &cfg.Participant, phoneNormalizer)
+	locationService := service.NewLocationService(locationRepo, participantRepo, eventRepo, locationBuffer, wsPubSub, &cfg.Location, logger)
+	redactionService := service.NewParticipantRedactionService(eventRepo, participantRepo, locationRepo, deliveryRepo, auditRepo, transactor, locationBuffer, auditService, &cfg.Worker, logger)
+	eventService := service.NewEventService(eventRepo, entityRepo, userRepo, schedulerRepo, participantRepo, attachmentRepo, groupRepo, organizerRepo, locationRepo, deliveryRepo, transactor, eventCacheService, auditService, webhookDispatcher, participantService, locationService, redactionService, &cfg.Event, &cfg.Scheduling, attachmentStorage, &cfg.Attachment, geocoder, phoneNormalizer, logger)
+	groupService := service.NewParticipantGroupService(groupRepo, participantRepo, eventRepo, logger)
+	entityService := service.NewEntityService(entityRepo, userRepo, transactor, auditService, &cfg.Entity)
 	etaService := eta.NewETAService(locationRepo, &cfg.OSRM)
+	deliveryService := service.NewDeliveryTrackingService(deliveryRepo, nil, logger)
+	inboundMessages := cache.NewIdempotencyStore(redisClient, "webhook:inbound:processed:")
+	pendingDeclineReasons := cache.NewIdempotencyStore(redisClient, "webhook:inbound:decline_reason:")
+	replyKeywords := service.NewDefaultKeywordMatcher(cfg.WhatsApp.ConfirmKeywords, cfg.WhatsApp.DenyKeywords)
+	var replySender service.MessageSender
+	if whatsappClient != nil {
+		replySender = whatsappClient
+	}
+	inboundService := service.NewInboundMessageService(participantService, locationService, inboundMessages, pendingDeclineReasons, replyKeywords, replySender, logger)
+
+	searchService := service.NewSearchService(eventRepo, participantRepo)
+	schedulerService := service.NewSchedulerService(schedulerRepo, participantRepo, eventRepo, entityRepo, userRepo, notificationService, eventCacheService, statusHistoryService, webhookDispatcher, &cfg.Worker, logger)
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(authService)
-	websocketHandler := handler.NewWebSocketHandler(wsHub, wsPubSub, logger)
+	authHandler := handler.NewAuthHandler(authService, &cfg.JWT)
+	websocketHandler := handler.NewWebSocketHandler(wsHub, wsPubSub, eventService, participantService, locationService, &cfg.JWT, tokenDenylist, logger)
+	wsHub.SetInboundHandler(websocketHandler)
 	eventCacheHandler := handler.NewEventCacheHandler(eventCacheService, logger)
 	participantHandler := handler.NewParticipantHandler(participantService, logger)
-	eventHandler := handler.NewEventHandler(eventService, logger)
-	entityHandler := handler.NewEntityHandler(entityService, logger)
+	groupHandler := handler.NewParticipantGroupHandler(groupService, logger)
+	eventHandler := handler.NewEventHandler(eventService, notificationService, logger)
+	entityHandler := handler.NewEntityHandler(entityService, auditService, webhookService, apiKeyService, whatsappSenderService, logger)
 	locationHandler := handler.NewLocationHandler(locationService, etaService, eventService)
-	webhookHandler := handler.NewWebhookHandler(&cfg.WhatsApp, participantService, locationService, logger)
+	webhookHandler := handler.NewWebhookHandler(&cfg.WhatsApp, inboundService, deliveryService, whatsappClient, logger)
+	schedulerHandler := handler.NewSchedulerHandler(schedulerService, logger)
+	searchHandler := handler.NewSearchHandler(searchService, logger)
 
 	// Setup router
-	r := router.NewRouter(cfg, logger, authHandler, websocketHandler, eventCacheHandler, participantHandler, eventHandler, entityHandler, locationHandler, webhookHandler)
+	rateLimiter := cache.NewRateLimiter(redisClient, "ratelimit:")
+	healthHandler := handler.NewHealthHandler(sqlDB, redisClient, whatsappClient, logger)
+	idempotencyStore := cache.NewIdempotencyStore(redisClient, "idempotency:")
+	r := router.NewRouter(cfg, logger, rateLimiter, idempotencyStore, tokenDenylist, apiKeyService, eventService, healthHandler, authHandler, websocketHandler, eventCacheHandler, participantHandler, groupHandler, eventHandler, entityHandler, locationHandler, webhookHandler, schedulerHandler, searchHandler)
 	engine := r.Setup()
 
 	// Create HTTP server
@@ -138,6 +232,10 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	// SSE streams are long-lived responses: end them as soon as shutdown starts so it
+	// doesn't wait for them
+	srv.RegisterOnShutdown(wsHub.CloseStreams)
+
 	// Start server in a goroutine
 	go func() {
 		logger.Info("Starting HTTP server", zap.String("addr", srv.Addr))
@@ -161,5 +259,18 @@ func main() {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Close WebSocket clients so they can reconnect to another instance
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer drainCancel()
+
+	if err := wsHub.Drain(drainCtx); err != nil {
+		logger.Warn("WebSocket drain did not complete", zap.Error(err))
+	}
+
+	// Wait for webhook deliveries started by the last requests
+	if err := webhookDispatcher.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Webhook deliveries did not complete", zap.Error(err))
+	}
+
 	logger.Info("Server exited gracefully")
 }
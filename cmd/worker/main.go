@@ -14,6 +14,7 @@ import (
 	"event-coming/internal/service"
 	"event-coming/internal/whatsapp"
 	"event-coming/internal/worker"
+	"event-coming/pkg/secretbox"
 
 	"go.uber.org/zap"
 )
@@ -61,6 +62,36 @@ func main() {
 	schedulerRepo := postgres.NewSchedulerRepository(db)
 	participantRepo := postgres.NewParticipantRepository(db)
 	eventRepo := postgres.NewEventRepository(db)
+	entityRepo := postgres.NewEntityRepository(db)
+	userRepo := postgres.NewUserRepository(db)
+	deliveryRepo := postgres.NewNotificationDeliveryRepository(db)
+	webhookRepo := postgres.NewWebhookRepository(db)
+	statusHistoryRepo := postgres.NewStatusHistoryRepository(db)
+	groupRepo := postgres.NewParticipantGroupRepository(db)
+	whatsappSenderRepo := postgres.NewWhatsAppSenderRepository(db)
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
+	passwordResetRepo := postgres.NewPasswordResetTokenRepository(db)
+	locationRepo := postgres.NewLocationRepository(db)
+	auditRepo := postgres.NewAuditLogRepository(db)
+	transactor := postgres.NewTransactor(db)
+
+	// One-off commands run once and exit instead of starting the workers
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backfill-phone-numbers":
+			// Fills participants.phone_number for participants stored before the column existed
+			phoneNormalizer := service.NewPhoneNormalizer(entityRepo, cfg.App.DefaultPhoneRegion, logger)
+			participantService := service.NewParticipantService(participantRepo, eventRepo, transactor, nil, nil, nil, nil, nil, nil, &cfg.RSVP, &cfg.Participant, phoneNormalizer)
+			filled, err := participantService.BackfillPhoneNumbers(ctx)
+			if err != nil {
+				logger.Fatal("failed to backfill participant phone numbers", zap.Error(err), zap.Int("participants", filled))
+			}
+			logger.Info("Backfilled participant phone numbers", zap.Int("participants", filled))
+			return
+		default:
+			logger.Fatal("unknown command", zap.String("command", os.Args[1]))
+		}
+	}
 
 	// Initialize WhatsApp client (pode ser nil se não configurado)
 	var whatsappClient *whatsapp.Client
@@ -68,29 +99,67 @@ func main() {
 		whatsappClient = whatsapp.NewClient(&cfg.WhatsApp)
 		logger.Info("WhatsApp client initialized")
 	} else {
-		logger.Warn("WhatsApp client not configured, notifications will be skipped")
+		logger.Warn("WhatsApp client not configured, only entities with their own sender will be notified")
+	}
+
+	// Descriptografa os tokens dos remetentes próprios das entidades (nil sem chave)
+	var senderTokens *secretbox.Box
+	if cfg.WhatsApp.SenderEncryptionKey != "" {
+		senderTokens, err = secretbox.NewFromBase64(cfg.WhatsApp.SenderEncryptionKey)
+		if err != nil {
+			logger.Fatal("invalid WhatsApp sender encryption key", zap.Error(err))
+		}
 	}
 
 	// Initialize services
-	notificationService := service.NewNotificationService(whatsappClient, logger)
+	whatsappSenderService := service.NewWhatsAppSenderService(whatsappSenderRepo, whatsappClient, &cfg.WhatsApp, senderTokens, logger)
+	notificationService := service.NewNotificationService(whatsappSenderService, deliveryRepo, eventRepo, participantRepo, groupRepo, logger)
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepo, &cfg.Webhooks, logger)
+	statusHistoryService := service.NewStatusHistoryService(statusHistoryRepo, logger)
 	schedulerService := service.NewSchedulerService(
 		schedulerRepo,
 		participantRepo,
 		eventRepo,
+		entityRepo,
+		userRepo,
 		notificationService,
+		service.NewEventCacheService(redisClient, eventRepo, participantRepo, &cfg.Cache),
+		statusHistoryService,
+		webhookDispatcher,
+		&cfg.Worker,
 		logger,
 	)
 
+	tokenPurgeService := service.NewTokenPurgeService(refreshTokenRepo, passwordResetRepo, &cfg.Worker)
+
+	// Redação automática só roda com retenção configurada
+	redactionInterval := cfg.Worker.RedactionInterval
+	if cfg.Worker.ParticipantDataRetention <= 0 {
+		redactionInterval = 0
+	}
+	auditService := service.NewAuditService(auditRepo, logger)
+	redactionService := service.NewParticipantRedactionService(eventRepo, participantRepo, locationRepo, deliveryRepo, auditRepo, transactor, cache.NewLocationBuffer(redisClient), auditService, &cfg.Worker, logger)
+
+	if cfg.Worker.DryRun {
+		logger.Warn("Scheduler running in dry run mode: notifications are only logged")
+	}
+
 	// Initialize workers
 	schedulerWorker := worker.NewSchedulerWorker(
 		schedulerService,
 		logger,
 		30*time.Second, // Intervalo de processamento
 		100,            // Batch size
+		20*time.Second, // Prazo para terminar o lote em andamento no shutdown
 	)
 
+	tokenPurgeWorker := worker.NewTokenPurgeWorker(tokenPurgeService, logger, cfg.Worker.TokenPurgeInterval)
+	redactionWorker := worker.NewParticipantRedactionWorker(redactionService, logger, redactionInterval)
+
 	// Start workers in goroutines
 	go schedulerWorker.Start(ctx)
+	go tokenPurgeWorker.Start(ctx)
+	go redactionWorker.Start(ctx)
 
 	logger.Info("All workers started")
 
@@ -106,6 +175,15 @@ func main() {
 
 	// Stop workers gracefully
 	schedulerWorker.Stop()
+	tokenPurgeWorker.Stop()
+	redactionWorker.Stop()
+
+	// Wait for webhook deliveries started by the last tasks
+	webhookCtx, webhookCancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer webhookCancel()
+	if err := webhookDispatcher.Shutdown(webhookCtx); err != nil {
+		logger.Warn("Webhook deliveries did not complete", zap.Error(err))
+	}
 
 	logger.Info("Workers exited gracefully")
 }
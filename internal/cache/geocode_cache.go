@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GeocodeCache stores resolved coordinates by (normalized) address
+type GeocodeCache struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewGeocodeCache creates a new geocode cache using the given key prefix
+func NewGeocodeCache(client *redis.Client, prefix string, ttl time.Duration) *GeocodeCache {
+	return &GeocodeCache{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+// Get returns the cached coordinates of the address; ok is false on a miss
+func (c *GeocodeCache) Get(ctx context.Context, address string) (lat, lng float64, ok bool, err error) {
+	value, err := c.client.Get(ctx, c.key(address)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to get geocode: %w", err)
+	}
+
+	latStr, lngStr, found := strings.Cut(value, ",")
+	if !found {
+		return 0, 0, false, nil
+	}
+	if lat, err = strconv.ParseFloat(latStr, 64); err != nil {
+		return 0, 0, false, nil
+	}
+	if lng, err = strconv.ParseFloat(lngStr, 64); err != nil {
+		return 0, 0, false, nil
+	}
+	return lat, lng, true, nil
+}
+
+// Set caches the coordinates of the address
+func (c *GeocodeCache) Set(ctx context.Context, address string, lat, lng float64) error {
+	value := strconv.FormatFloat(lat, 'f', -1, 64) + "," + strconv.FormatFloat(lng, 'f', -1, 64)
+	if err := c.client.Set(ctx, c.key(address), value, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set geocode: %w", err)
+	}
+	return nil
+}
+
+// key usa o hash do endereço normalizado: endereços podem ser longos e ter qualquer caractere
+func (c *GeocodeCache) key(address string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(address)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return c.prefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyStore records processed keys in Redis so repeated deliveries can be skipped
+type IdempotencyStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewIdempotencyStore creates a new idempotency store using the given key prefix
+func NewIdempotencyStore(client *redis.Client, prefix string) *IdempotencyStore {
+	return &IdempotencyStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Claim marks the key as processed. It returns false if the key was already claimed.
+func (s *IdempotencyStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := s.client.SetNX(ctx, s.prefix+key, time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	return ok, nil
+}
+
+// Release removes a claimed key so it can be processed again
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// Exists reports whether the key has been claimed
+func (s *IdempotencyStore) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.prefix+key).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Load returns the value stored under key, or ok=false if there is none
+func (s *IdempotencyStore) Load(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+	return value, true, nil
+}
+
+// Store saves a value under key (e.g. a response to replay)
+func (s *IdempotencyStore) Store(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.client.Set(ctx, s.prefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+	return nil
+}
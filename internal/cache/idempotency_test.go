@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyStore_ClaimAndRelease(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := NewIdempotencyStore(client, "test:")
+
+	first, err := store.Claim(ctx, "key", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, first)
+	assert.Equal(t, time.Hour, mr.TTL("test:key"))
+
+	again, err := store.Claim(ctx, "key", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, again)
+
+	require.NoError(t, store.Release(ctx, "key"))
+	afterRelease, err := store.Claim(ctx, "key", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, afterRelease)
+
+	// Claims expire with their TTL
+	mr.FastForward(time.Hour)
+	expired, err := store.Claim(ctx, "key", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, expired)
+}
+
+func TestIdempotencyStore_Exists(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := NewIdempotencyStore(client, "test:")
+
+	exists, err := store.Exists(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = store.Claim(ctx, "key", time.Hour)
+	require.NoError(t, err)
+	exists, err = store.Exists(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -61,6 +61,10 @@ func (b *LocationBuffer) PushWithTTL(ctx context.Context, location *domain.Locat
 		return fmt.Errorf("failed to cache latest location: %w", err)
 	}
 
+	if err := b.indexLatest(ctx, location, ttl); err != nil {
+		return err
+	}
+
 	// Publish to pub/sub for real-time updates
 	channel := fmt.Sprintf("location:updates:%s", location.EventID)
 	if err := b.client.Publish(ctx, channel, data).Err(); err != nil {
@@ -93,6 +97,10 @@ func (b *LocationBuffer) SetLatestLocation(ctx context.Context, location *domain
 		return fmt.Errorf("failed to set latest location: %w", err)
 	}
 
+	if err := b.indexLatest(ctx, location, ttl); err != nil {
+		return err
+	}
+
 	// Also publish for real-time updates
 	channel := fmt.Sprintf("location:updates:%s", location.EventID)
 	b.client.Publish(ctx, channel, data)
@@ -100,6 +108,51 @@ func (b *LocationBuffer) SetLatestLocation(ctx context.Context, location *domain
 	return nil
 }
 
+// LocationIndexKey returns the key of the set holding the participant ids
+// with a latest location cached for an event
+func LocationIndexKey(eventID uuid.UUID) string {
+	return fmt.Sprintf("location:index:%s", eventID)
+}
+
+// indexLatest adds the participant to the event location index.
+// The index lives at least as long as the latest location it points to.
+func (b *LocationBuffer) indexLatest(ctx context.Context, location *domain.Location, ttl time.Duration) error {
+	indexKey := LocationIndexKey(location.EventID)
+
+	pipe := b.client.Pipeline()
+	pipe.SAdd(ctx, indexKey, location.ParticipantID.String())
+	// Only ever extend the TTL so the index never expires before an entry it points to
+	pipe.ExpireNX(ctx, indexKey, ttl)
+	pipe.ExpireGT(ctx, indexKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to index latest location: %w", err)
+	}
+
+	return nil
+}
+
+// ClearEvent deletes the cached latest locations of an event and its index
+func (b *LocationBuffer) ClearEvent(ctx context.Context, eventID uuid.UUID) error {
+	indexKey := LocationIndexKey(eventID)
+
+	members, err := b.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read location index: %w", err)
+	}
+
+	keys := make([]string, 0, len(members)+1)
+	for _, participantID := range members {
+		keys = append(keys, fmt.Sprintf("location:latest:%s:%s", eventID, participantID))
+	}
+	keys = append(keys, indexKey)
+
+	if err := b.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to clear event locations: %w", err)
+	}
+
+	return nil
+}
+
 // PopBatch retrieves and removes a batch of locations from the buffer
 func (b *LocationBuffer) PopBatch(ctx context.Context, orgID uuid.UUID, batchSize int) ([]*domain.Location, error) {
 	bufferKey := fmt.Sprintf("location:buffer:%s", orgID)
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/testutil"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocationBuffer(t *testing.T) (*LocationBuffer, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewLocationBuffer(client), mr
+}
+
+func TestLocationBuffer_IndexesLatestLocations(t *testing.T) {
+	ctx := context.Background()
+	buffer, mr := newTestLocationBuffer(t)
+
+	first := testutil.NewTestLocation()
+	second := testutil.NewTestLocation()
+	second.ID = uuid.New()
+	second.ParticipantID = uuid.New()
+
+	require.NoError(t, buffer.PushWithTTL(ctx, first, time.Hour))
+	require.NoError(t, buffer.SetLatestLocation(ctx, second, time.Now().Add(time.Hour)))
+
+	members, err := mr.SMembers(LocationIndexKey(first.EventID))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{first.ParticipantID.String(), second.ParticipantID.String()}, members)
+}
+
+func TestLocationBuffer_IndexTTLIsOnlyExtended(t *testing.T) {
+	ctx := context.Background()
+	buffer, mr := newTestLocationBuffer(t)
+
+	location := testutil.NewTestLocation()
+	indexKey := LocationIndexKey(location.EventID)
+
+	require.NoError(t, buffer.PushWithTTL(ctx, location, 3*time.Hour))
+	assert.Equal(t, 3*time.Hour, mr.TTL(indexKey))
+
+	// A shorter TTL for another entry must not shrink the index
+	other := testutil.NewTestLocation()
+	other.ParticipantID = uuid.New()
+	require.NoError(t, buffer.PushWithTTL(ctx, other, time.Hour))
+	assert.Equal(t, 3*time.Hour, mr.TTL(indexKey))
+
+	require.NoError(t, buffer.PushWithTTL(ctx, other, 5*time.Hour))
+	assert.Equal(t, 5*time.Hour, mr.TTL(indexKey))
+}
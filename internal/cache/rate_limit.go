@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript atomically refills and takes a token from a bucket stored as a hash.
+// Returns {allowed (0/1), retry_after_ms}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, retry}
+`)
+
+// RateLimiter is a token-bucket rate limiter shared across instances through Redis
+type RateLimiter struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRateLimiter creates a new Redis rate limiter using the given key prefix
+func NewRateLimiter(client *redis.Client, prefix string) *RateLimiter {
+	return &RateLimiter{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Allow takes a token from the bucket identified by key. rate is the refill rate in
+// tokens per second and burst the bucket size. When the request is rejected it
+// returns how long until a token is available.
+func (l *RateLimiter) Allow(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	res, err := tokenBucketScript.Run(ctx, l.client, []string{l.prefix + key},
+		rate, burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiter := NewRateLimiter(client, "test:")
+
+	// The burst is available immediately
+	for i := 0; i < 3; i++ {
+		allowed, _, err := limiter.Allow(ctx, "key", 1, 3)
+		require.NoError(t, err)
+		assert.True(t, allowed, "request %d", i+1)
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "key", 1, 3)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	assert.LessOrEqual(t, retryAfter, time.Second)
+
+	// Buckets are per key
+	allowed, _, err = limiter.Allow(ctx, "other", 1, 3)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.True(t, mr.Exists("test:key"))
+}
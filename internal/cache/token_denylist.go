@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenDenylist revokes access tokens before they expire. Entries only live
+// as long as the tokens they revoke.
+type TokenDenylist struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewTokenDenylist creates a new access token denylist using the given key prefix
+func NewTokenDenylist(client *redis.Client, prefix string) *TokenDenylist {
+	return &TokenDenylist{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Revoke denylists a single token by its jti until it would have expired
+func (d *TokenDenylist) Revoke(ctx context.Context, jti string, ttl time.Duration) error {
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	if err := d.client.Set(ctx, d.prefix+"jti:"+jti, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeUser revokes every token of the user issued up to now. ttl should be
+// the access token lifetime, after which those tokens expire anyway.
+func (d *TokenDenylist) RevokeUser(ctx context.Context, userID uuid.UUID, ttl time.Duration) error {
+	if err := d.client.Set(ctx, d.prefix+"user:"+userID.String(), time.Now().Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token (jti, issued at iat to userID) was revoked
+func (d *TokenDenylist) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, iat time.Time) (bool, error) {
+	if jti != "" {
+		n, err := d.client.Exists(ctx, d.prefix+"jti:"+jti).Result()
+		if err != nil {
+			return false, fmt.Errorf("failed to check token denylist: %w", err)
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+
+	revokedAt, err := d.client.Get(ctx, d.prefix+"user:"+userID.String()).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check token denylist: %w", err)
+	}
+
+	ts, err := strconv.ParseInt(revokedAt, 10, 64)
+	if err != nil {
+		return false, nil
+	}
+	// iat e ts têm resolução de segundos: um token emitido no mesmo segundo da revogação
+	// (ex.: o login logo após o reset de senha) continua válido
+	return iat.Unix() < ts, nil
+}
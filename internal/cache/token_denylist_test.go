@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenDenylist(t *testing.T) (*TokenDenylist, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewTokenDenylist(client, "denylist:"), mr
+}
+
+func TestTokenDenylist_Revoke(t *testing.T) {
+	ctx := context.Background()
+	denylist, mr := newTestTokenDenylist(t)
+	userID := uuid.New()
+
+	require.NoError(t, denylist.Revoke(ctx, "jti-1", time.Minute))
+
+	revoked, err := denylist.IsRevoked(ctx, "jti-1", userID, time.Now())
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = denylist.IsRevoked(ctx, "jti-2", userID, time.Now())
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// The entry only lives as long as the token
+	mr.FastForward(time.Minute)
+	revoked, err = denylist.IsRevoked(ctx, "jti-1", userID, time.Now())
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenDenylist_RevokeUser(t *testing.T) {
+	ctx := context.Background()
+	denylist, mr := newTestTokenDenylist(t)
+	userID := uuid.New()
+
+	require.NoError(t, denylist.RevokeUser(ctx, userID, time.Hour))
+	stored, err := mr.Get("denylist:user:" + userID.String())
+	require.NoError(t, err)
+	ts, err := strconv.ParseInt(stored, 10, 64)
+	require.NoError(t, err)
+	revokedAt := time.Unix(ts, 0)
+
+	revoked, err := denylist.IsRevoked(ctx, "", userID, revokedAt.Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, revoked, "issued before the revocation")
+
+	// iat has second resolution: a token issued in the revocation second stays valid
+	revoked, err = denylist.IsRevoked(ctx, "", userID, revokedAt)
+	require.NoError(t, err)
+	assert.False(t, revoked, "issued in the revocation second")
+
+	revoked, err = denylist.IsRevoked(ctx, "", uuid.New(), revokedAt.Add(-time.Second))
+	require.NoError(t, err)
+	assert.False(t, revoked, "another user")
+
+}
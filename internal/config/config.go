@@ -10,13 +10,28 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	App      AppConfig
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	WhatsApp WhatsAppConfig
-	OSRM     OSRMConfig
+	App         AppConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	JWT         JWTConfig
+	WhatsApp    WhatsAppConfig
+	OSRM        OSRMConfig
+	WebSocket   WebSocketConfig
+	Cache       CacheConfig
+	Entity      EntityConfig
+	Event       EventConfig
+	Participant ParticipantConfig
+	Scheduling  SchedulingConfig
+	RateLimit   RateLimitConfig       `mapstructure:"rate_limit"`
+	Webhooks    OutboundWebhookConfig `mapstructure:"outbound_webhook"`
+	Storage     StorageConfig
+	Attachment  AttachmentConfig
+	RSVP        RSVPConfig
+	Geocoding   GeocodingConfig
+	Location    LocationConfig
+	Pagination  PaginationConfig
+	Worker      WorkerConfig
 }
 
 // AppConfig holds application-level configuration
@@ -24,6 +39,9 @@ type AppConfig struct {
 	Name        string `mapstructure:"name"`
 	Environment string `mapstructure:"environment"`
 	Debug       bool   `mapstructure:"debug"`
+	// Region (ISO 3166-1, e.g. "BR") assumed for phone numbers without the country code;
+	// empty keeps only the digits
+	DefaultPhoneRegion string `mapstructure:"default_phone_region"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -33,6 +51,13 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	// Proxies (IPs or CIDRs) whose X-Forwarded-For is trusted for the client IP;
+	// empty trusts none, so the rate limits key on the connection address
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// Origins allowed to call the API from a browser ("*" = any, without credentials)
+	CORSOrigins []string `mapstructure:"cors_origins"`
+	CORSMethods []string `mapstructure:"cors_methods"`
+	CORSHeaders []string `mapstructure:"cors_headers"`
 }
 
 // DatabaseConfig holds PostgreSQL connection configuration
@@ -71,6 +96,115 @@ type JWTConfig struct {
 	Issuer           string        `mapstructure:"issuer"`
 	AccessExpiresIn  time.Duration `mapstructure:"access_expires_in"`
 	RefreshExpiresIn time.Duration `mapstructure:"refresh_expires_in"`
+	// Expiry of the access tokens a super admin gets to act as another user
+	ImpersonationExpiresIn time.Duration `mapstructure:"impersonation_expires_in"`
+	// Signing keys selected by the token "kid" header, for zero-downtime rotation.
+	// Loaded from jwt.keys ("kid:secret,kid2:secret2"); jwt.active_kid signs new tokens.
+	Keys      []JWTKey `mapstructure:"-"`
+	ActiveKID string   `mapstructure:"active_kid"`
+	// Accept access tokens when the revocation denylist (Redis) can't be read. Off by
+	// default: revoked tokens must not work again while Redis is down
+	DenylistFailOpen bool `mapstructure:"denylist_fail_open"`
+}
+
+// JWTKey is an access token signing key
+type JWTKey struct {
+	KID    string
+	Secret string
+	Active bool // Signs new tokens; the other keys are only used for verification
+}
+
+// SigningKey returns the key used to sign new access tokens. Without keys configured
+// it falls back to AccessSecret with an empty kid.
+func (c *JWTConfig) SigningKey() (kid, secret string) {
+	for _, key := range c.Keys {
+		if key.Active {
+			return key.KID, key.Secret
+		}
+	}
+	if len(c.Keys) > 0 {
+		return c.Keys[0].KID, c.Keys[0].Secret
+	}
+	return "", c.AccessSecret
+}
+
+// VerificationKey returns the secret for the given kid. Tokens without a kid
+// (issued before key rotation was enabled) are verified with AccessSecret.
+func (c *JWTConfig) VerificationKey(kid string) (string, bool) {
+	if kid == "" {
+		return c.AccessSecret, c.AccessSecret != ""
+	}
+	for _, key := range c.Keys {
+		if key.KID == kid {
+			return key.Secret, true
+		}
+	}
+	return "", false
+}
+
+// PlaceholderSecret is the default of the signing secrets, only accepted in debug mode:
+// anyone reading the source could forge tokens signed with it
+const PlaceholderSecret = "change-me-in-production"
+
+// Validate rejects JWT settings that would mint unusable tokens: non-positive
+// expirations, a refresh expiry shorter than the access one, an impersonation
+// expiry longer than the access one and, outside debug mode, a missing or
+// placeholder signing secret
+func (c *JWTConfig) Validate(debug bool) error {
+	if c.AccessExpiresIn <= 0 {
+		return fmt.Errorf("jwt.access_expires_in must be positive, got %s", c.AccessExpiresIn)
+	}
+	if c.RefreshExpiresIn <= 0 {
+		return fmt.Errorf("jwt.refresh_expires_in must be positive, got %s", c.RefreshExpiresIn)
+	}
+	if c.RefreshExpiresIn < c.AccessExpiresIn {
+		return fmt.Errorf("jwt.refresh_expires_in (%s) must not be shorter than jwt.access_expires_in (%s)",
+			c.RefreshExpiresIn, c.AccessExpiresIn)
+	}
+	if c.ImpersonationExpiresIn <= 0 {
+		return fmt.Errorf("jwt.impersonation_expires_in must be positive, got %s", c.ImpersonationExpiresIn)
+	}
+	if c.ImpersonationExpiresIn > c.AccessExpiresIn {
+		return fmt.Errorf("jwt.impersonation_expires_in (%s) must not be longer than jwt.access_expires_in (%s)",
+			c.ImpersonationExpiresIn, c.AccessExpiresIn)
+	}
+	if !debug {
+		if _, secret := c.SigningKey(); secret == "" {
+			return fmt.Errorf("jwt.access_secret (or jwt.keys) must be set when app.debug is false")
+		}
+		if c.RefreshSecret == "" {
+			return fmt.Errorf("jwt.refresh_secret must be set when app.debug is false")
+		}
+		if c.AccessSecret == PlaceholderSecret {
+			return fmt.Errorf("jwt.access_secret must be changed from the default when app.debug is false")
+		}
+		for _, key := range c.Keys {
+			if key.Secret == PlaceholderSecret {
+				return fmt.Errorf("jwt.keys secret %q must be changed from the default when app.debug is false", key.KID)
+			}
+		}
+		if c.RefreshSecret == PlaceholderSecret {
+			return fmt.Errorf("jwt.refresh_secret must be changed from the default when app.debug is false")
+		}
+	}
+	return nil
+}
+
+// parseJWTKeys parses "kid:secret" pairs separated by commas
+func parseJWTKeys(spec, activeKID string) []JWTKey {
+	var keys []JWTKey
+	for _, entry := range strings.Split(spec, ",") {
+		kid, secret, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || kid == "" || secret == "" {
+			continue
+		}
+		keys = append(keys, JWTKey{
+			KID:    kid,
+			Secret: secret,
+			Active: kid == activeKID,
+		})
+	}
+	return keys
 }
 
 // WhatsAppConfig holds WhatsApp Cloud API configuration
@@ -84,6 +218,35 @@ type WhatsAppConfig struct {
 	BaseURL            string `mapstructure:"base_url"`
 	WebhookVerifyToken string `mapstructure:"webhook_verify_token"`
 	WebhookSecret      string `mapstructure:"webhook_secret"`
+	// Signed webhooks with a timestamp header older (or further in the future) than this
+	// are rejected as replays; 0 disables the age check
+	WebhookTimestampTolerance time.Duration `mapstructure:"webhook_timestamp_tolerance"`
+	// Extra free-text keywords, added to the built-in pt/en/es sets
+	ConfirmKeywords []string `mapstructure:"confirm_keywords"`
+	DenyKeywords    []string `mapstructure:"deny_keywords"`
+	// Reply with instructions when a free-text answer isn't understood
+	HelpOnUnknownReply bool `mapstructure:"help_on_unknown_reply"`
+	// Base64 32-byte key that encrypts the access tokens of entity-specific senders at
+	// rest; without it entities can't configure their own sender
+	SenderEncryptionKey string `mapstructure:"sender_encryption_key"`
+	// Deadline for each Cloud API request; a send that doesn't answer in time fails
+	// (and the scheduler task is retried) instead of blocking the worker
+	SendTimeout time.Duration `mapstructure:"send_timeout"`
+	// Consecutive failed sends (network errors, timeouts, 5xx, 429) that open the circuit
+	// breaker; while open, sends fail fast for BreakerCooldown. 0 disables the breaker
+	BreakerThreshold int           `mapstructure:"breaker_threshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`
+}
+
+// Validate rejects a negative breaker threshold and an enabled breaker without cooldown
+func (c *WhatsAppConfig) Validate() error {
+	if c.BreakerThreshold < 0 {
+		return fmt.Errorf("whatsapp.breaker_threshold must not be negative, got %d", c.BreakerThreshold)
+	}
+	if c.BreakerThreshold > 0 && c.BreakerCooldown <= 0 {
+		return fmt.Errorf("whatsapp.breaker_cooldown must be positive when the breaker is enabled, got %s", c.BreakerCooldown)
+	}
+	return nil
 }
 
 // OSRMConfig holds OSRM routing service configuration
@@ -93,6 +256,217 @@ type OSRMConfig struct {
 	Timeout time.Duration `mapstructure:"timeout"`
 }
 
+// WebSocketConfig holds WebSocket hub configuration
+type WebSocketConfig struct {
+	SendBufferSize     int           `mapstructure:"send_buffer_size"`
+	BackpressurePolicy string        `mapstructure:"backpressure_policy"`
+	ReplaySize         int           `mapstructure:"replay_size"`
+	WriteWait          time.Duration `mapstructure:"write_wait"`
+	PongWait           time.Duration `mapstructure:"pong_wait"`
+	PingPeriod         time.Duration `mapstructure:"ping_period"`
+	MaxMessageSize     int64         `mapstructure:"max_message_size"`
+}
+
+// CacheConfig holds event cache (Redis) configuration
+type CacheConfig struct {
+	DefaultTTL    time.Duration `mapstructure:"default_ttl"`
+	MinTTL        time.Duration `mapstructure:"min_ttl"`
+	AfterEventTTL time.Duration `mapstructure:"after_event_ttl"`
+	// How long responses to Idempotency-Key requests are replayed
+	IdempotencyTTL time.Duration `mapstructure:"idempotency_ttl"`
+}
+
+// EntityConfig holds entity hierarchy configuration
+type EntityConfig struct {
+	MaxHierarchyDepth int `mapstructure:"max_hierarchy_depth"`
+}
+
+// EventConfig holds event creation settings
+type EventConfig struct {
+	// Creating an event with the same (normalized) name as another event of the entity
+	// starting within this window returns 409 unless allow_duplicate is set (0 = disabled)
+	DuplicateWindow time.Duration `mapstructure:"duplicate_window"`
+	// Used when the event doesn't set its own timezone (IANA) / locale (pt, en, es)
+	DefaultTimezone string `mapstructure:"default_timezone"`
+	DefaultLocale   string `mapstructure:"default_locale"`
+	// Calendars with more VEVENTs are rejected by POST /events/import.ics with 422, since
+	// each imported event may be geocoded (0 = unlimited)
+	ImportMaxEvents int `mapstructure:"import_max_events"`
+}
+
+// LocationConfig holds participant location settings
+type LocationConfig struct {
+	// Live location views flag positions older than this as stale
+	StaleAfter time.Duration `mapstructure:"stale_after"`
+	// An eta_update is published over WebSocket when a participant's ETA changes by at
+	// least this many minutes, and no more often than ETAUpdateInterval
+	ETAUpdateThreshold int           `mapstructure:"eta_update_threshold"`
+	ETAUpdateInterval  time.Duration `mapstructure:"eta_update_interval"`
+	// Updates from the same participant closer than this to the latest accepted one are
+	// dropped; 0 disables throttling
+	MinUpdateInterval time.Duration `mapstructure:"min_update_interval"`
+	// Points implying a faster move (m/s) from the previous accepted one are rejected as
+	// GPS glitches; 0 disables the check
+	MaxSpeed float64 `mapstructure:"max_speed"`
+	// Larger POST /participants/:id/locations/batch payloads are rejected with 422
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+}
+
+// PaginationConfig holds the page size of paginated list endpoints
+type PaginationConfig struct {
+	// per_page used when the request doesn't send one
+	DefaultPerPage int `mapstructure:"default_per_page"`
+	// Larger per_page values are capped to this
+	MaxPerPage int `mapstructure:"max_per_page"`
+}
+
+// ParticipantConfig holds participant import limits
+type ParticipantConfig struct {
+	// Larger POST /events/:id/participants/batch payloads are rejected with 422
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+	// Entries checked for duplicates with a single query and created per round
+	BatchChunkSize int `mapstructure:"batch_chunk_size"`
+}
+
+// WorkerConfig holds scheduler worker settings
+type WorkerConfig struct {
+	// Runs the scheduled tasks logging the messages they would send, without sending them
+	// nor changing events (closure)
+	DryRun bool `mapstructure:"dry_run"`
+	// In dry run, marks the tasks as processed; otherwise they stay pending (and are logged
+	// again on the next run), so they still fire once dry run is turned off
+	DryRunMarkProcessed bool `mapstructure:"dry_run_mark_processed"`
+	// How long after its end an event still active is completed automatically, for
+	// entities that opted in (auto_complete_events)
+	AutoCompleteGrace time.Duration `mapstructure:"auto_complete_grace"`
+	// How often expired, revoked and used auth tokens are purged (0 disables the purge)
+	TokenPurgeInterval time.Duration `mapstructure:"token_purge_interval"`
+	// How long tokens are kept after they expire or are revoked/used before being purged
+	TokenRetention time.Duration `mapstructure:"token_retention"`
+	// How long after a completed or cancelled event ends its participants' personal data
+	// is redacted automatically (0 disables; redaction can still be requested per event)
+	ParticipantDataRetention time.Duration `mapstructure:"participant_data_retention"`
+	// How often ended events are checked for automatic redaction
+	RedactionInterval time.Duration `mapstructure:"redaction_interval"`
+	// Send attempts per participant within a scheduled task, separate from the task's
+	// max_retries: a participant whose sends keep failing is skipped after this many,
+	// without holding back the rest (<= 1 tries each participant once)
+	NotificationMaxAttempts int `mapstructure:"notification_max_attempts"`
+}
+
+// SchedulingConfig holds the guards applied when an event's schedulers are created
+type SchedulingConfig struct {
+	// Schedulers whose computed time already passed: "skip" or "fire_once" (run once right away)
+	PastPolicy string `mapstructure:"past_policy"`
+	// Reminders with less lead than this before the event start are skipped
+	MinReminderLead time.Duration `mapstructure:"min_reminder_lead"`
+	// Reminders are never scheduled earlier than this before the event start (0 = no limit)
+	MaxReminderLead time.Duration `mapstructure:"max_reminder_lead"`
+}
+
+// Políticas para schedulers cujo horário calculado já passou
+const (
+	PastSchedulerSkip     = "skip"
+	PastSchedulerFireOnce = "fire_once"
+)
+
+// Validate rejects an unknown past policy and negative reminder leads
+func (c *SchedulingConfig) Validate() error {
+	if c.PastPolicy != PastSchedulerSkip && c.PastPolicy != PastSchedulerFireOnce {
+		return fmt.Errorf("scheduling.past_policy must be %q or %q, got %q", PastSchedulerSkip, PastSchedulerFireOnce, c.PastPolicy)
+	}
+	if c.MinReminderLead < 0 {
+		return fmt.Errorf("scheduling.min_reminder_lead must not be negative, got %s", c.MinReminderLead)
+	}
+	if c.MaxReminderLead < 0 {
+		return fmt.Errorf("scheduling.max_reminder_lead must not be negative, got %s", c.MaxReminderLead)
+	}
+	return nil
+}
+
+// RateLimitConfig holds rate limits (token bucket) for public endpoints
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Per IP on /auth/register, /auth/login and /auth/forgot-password
+	AuthRequestsPerSecond float64 `mapstructure:"auth_requests_per_second"`
+	AuthBurst             int     `mapstructure:"auth_burst"`
+	// Per email on /auth/login and /auth/forgot-password
+	EmailRequestsPerSecond float64 `mapstructure:"email_requests_per_second"`
+	EmailBurst             int     `mapstructure:"email_burst"`
+	// Per IP on the WhatsApp webhook
+	WebhookRequestsPerSecond float64 `mapstructure:"webhook_requests_per_second"`
+	WebhookBurst             int     `mapstructure:"webhook_burst"`
+	// Per participant on POST /participants/:id/resend-confirmation
+	ResendRequestsPerSecond float64 `mapstructure:"resend_requests_per_second"`
+	ResendBurst             int     `mapstructure:"resend_burst"`
+	// Per IP on the public RSVP endpoints (/rsvp/:token)
+	RSVPRequestsPerSecond float64 `mapstructure:"rsvp_requests_per_second"`
+	RSVPBurst             int     `mapstructure:"rsvp_burst"`
+}
+
+// OutboundWebhookConfig holds delivery settings for outbound (customer) webhooks
+type OutboundWebhookConfig struct {
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Attempts per delivery; 5xx and network errors are retried with exponential backoff
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+}
+
+// StorageConfig holds the file storage backend used for event attachments
+type StorageConfig struct {
+	// "local" (default), "memory" or "s3" (any S3-compatible service)
+	Driver    string `mapstructure:"driver"`
+	LocalPath string `mapstructure:"local_path"`
+
+	S3Endpoint  string `mapstructure:"s3_endpoint"`
+	S3Region    string `mapstructure:"s3_region"`
+	S3Bucket    string `mapstructure:"s3_bucket"`
+	S3AccessKey string `mapstructure:"s3_access_key"`
+	S3SecretKey string `mapstructure:"s3_secret_key"`
+}
+
+// AttachmentConfig holds the limits for event attachment uploads
+type AttachmentConfig struct {
+	MaxSize int64 `mapstructure:"max_size"`
+	// Content types are detected from the file contents, not trusted from the client
+	AllowedContentTypes []string `mapstructure:"allowed_content_types"`
+}
+
+// RSVPConfig holds the signed invite links participants use to answer without logging in
+type RSVPConfig struct {
+	// Signs the RSVP tokens; changing it invalidates every link already sent
+	Secret   string        `mapstructure:"secret"`
+	TokenTTL time.Duration `mapstructure:"token_ttl"`
+	// Public RSVP page; the token is appended as the "token" query parameter
+	PageURL string `mapstructure:"page_url"`
+}
+
+// Validate rejects, outside debug mode, a missing or placeholder secret: anyone could
+// sign RSVP links and answer invitations for any participant
+func (c *RSVPConfig) Validate(debug bool) error {
+	if debug {
+		return nil
+	}
+	if c.Secret == "" {
+		return fmt.Errorf("rsvp.secret must be set when app.debug is false")
+	}
+	if c.Secret == PlaceholderSecret {
+		return fmt.Errorf("rsvp.secret must be changed from the default when app.debug is false")
+	}
+	return nil
+}
+
+// GeocodingConfig holds the provider used to fill event coordinates from the address
+type GeocodingConfig struct {
+	// "" (disabled, default) or "nominatim" (OpenStreetMap or a self-hosted instance)
+	Provider  string        `mapstructure:"provider"`
+	BaseURL   string        `mapstructure:"base_url"`
+	UserAgent string        `mapstructure:"user_agent"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	// How long a resolved address is cached in Redis
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
 // Load reads configuration from environment variables and files
 func Load() (*Config, error) {
 	v := viper.New()
@@ -119,6 +493,20 @@ func Load() (*Config, error) {
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	config.JWT.Keys = parseJWTKeys(v.GetString("jwt.keys"), config.JWT.ActiveKID)
+
+	if err := config.JWT.Validate(config.App.Debug); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := config.RSVP.Validate(config.App.Debug); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := config.Scheduling.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := config.WhatsApp.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
 	return &config, nil
 }
@@ -144,16 +532,133 @@ func bindEnvVariables(v *viper.Viper) {
 	// Server bindings
 	v.BindEnv("server.host", "EVENT_COMING_SERVER_HOST")
 	v.BindEnv("server.port", "EVENT_COMING_SERVER_PORT")
+	v.BindEnv("server.trusted_proxies", "EVENT_COMING_SERVER_TRUSTED_PROXIES")
+	v.BindEnv("server.cors_origins", "EVENT_COMING_SERVER_CORS_ORIGINS")
+	v.BindEnv("server.cors_methods", "EVENT_COMING_SERVER_CORS_METHODS")
+	v.BindEnv("server.cors_headers", "EVENT_COMING_SERVER_CORS_HEADERS")
 
 	// JWT bindings
 	v.BindEnv("jwt.access_secret", "EVENT_COMING_JWT_ACCESS_SECRET")
 	v.BindEnv("jwt.refresh_secret", "EVENT_COMING_JWT_REFRESH_SECRET")
 	v.BindEnv("jwt.access_expires_in", "EVENT_COMING_JWT_ACCESS_EXPIRES_IN")
 	v.BindEnv("jwt.refresh_expires_in", "EVENT_COMING_JWT_REFRESH_EXPIRES_IN")
+	v.BindEnv("jwt.impersonation_expires_in", "EVENT_COMING_JWT_IMPERSONATION_EXPIRES_IN")
+	v.BindEnv("jwt.keys", "EVENT_COMING_JWT_KEYS")
+	v.BindEnv("jwt.active_kid", "EVENT_COMING_JWT_ACTIVE_KID")
+	v.BindEnv("jwt.denylist_fail_open", "EVENT_COMING_JWT_DENYLIST_FAIL_OPEN")
+
+	// WhatsApp bindings
+	v.BindEnv("whatsapp.confirm_keywords", "EVENT_COMING_WHATSAPP_CONFIRM_KEYWORDS")
+	v.BindEnv("whatsapp.deny_keywords", "EVENT_COMING_WHATSAPP_DENY_KEYWORDS")
+	v.BindEnv("whatsapp.help_on_unknown_reply", "EVENT_COMING_WHATSAPP_HELP_ON_UNKNOWN_REPLY")
+	v.BindEnv("whatsapp.webhook_timestamp_tolerance", "EVENT_COMING_WHATSAPP_WEBHOOK_TIMESTAMP_TOLERANCE")
+	v.BindEnv("whatsapp.sender_encryption_key", "EVENT_COMING_WHATSAPP_SENDER_ENCRYPTION_KEY")
+	v.BindEnv("whatsapp.send_timeout", "EVENT_COMING_WHATSAPP_SEND_TIMEOUT")
+	v.BindEnv("whatsapp.breaker_threshold", "EVENT_COMING_WHATSAPP_BREAKER_THRESHOLD")
+	v.BindEnv("whatsapp.breaker_cooldown", "EVENT_COMING_WHATSAPP_BREAKER_COOLDOWN")
+
+	// WebSocket bindings
+	v.BindEnv("websocket.send_buffer_size", "EVENT_COMING_WEBSOCKET_SEND_BUFFER_SIZE")
+	v.BindEnv("websocket.backpressure_policy", "EVENT_COMING_WEBSOCKET_BACKPRESSURE_POLICY")
+	v.BindEnv("websocket.replay_size", "EVENT_COMING_WEBSOCKET_REPLAY_SIZE")
+	v.BindEnv("websocket.write_wait", "EVENT_COMING_WEBSOCKET_WRITE_WAIT")
+	v.BindEnv("websocket.pong_wait", "EVENT_COMING_WEBSOCKET_PONG_WAIT")
+	v.BindEnv("websocket.ping_period", "EVENT_COMING_WEBSOCKET_PING_PERIOD")
+	v.BindEnv("websocket.max_message_size", "EVENT_COMING_WEBSOCKET_MAX_MESSAGE_SIZE")
+
+	// Cache bindings
+	v.BindEnv("cache.default_ttl", "EVENT_COMING_CACHE_DEFAULT_TTL")
+	v.BindEnv("cache.min_ttl", "EVENT_COMING_CACHE_MIN_TTL")
+	v.BindEnv("cache.after_event_ttl", "EVENT_COMING_CACHE_AFTER_EVENT_TTL")
+	v.BindEnv("cache.idempotency_ttl", "EVENT_COMING_CACHE_IDEMPOTENCY_TTL")
+
+	// Rate limit bindings
+	v.BindEnv("rate_limit.enabled", "EVENT_COMING_RATE_LIMIT_ENABLED")
+	v.BindEnv("rate_limit.auth_requests_per_second", "EVENT_COMING_RATE_LIMIT_AUTH_REQUESTS_PER_SECOND")
+	v.BindEnv("rate_limit.auth_burst", "EVENT_COMING_RATE_LIMIT_AUTH_BURST")
+	v.BindEnv("rate_limit.email_requests_per_second", "EVENT_COMING_RATE_LIMIT_EMAIL_REQUESTS_PER_SECOND")
+	v.BindEnv("rate_limit.email_burst", "EVENT_COMING_RATE_LIMIT_EMAIL_BURST")
+	v.BindEnv("rate_limit.webhook_requests_per_second", "EVENT_COMING_RATE_LIMIT_WEBHOOK_REQUESTS_PER_SECOND")
+	v.BindEnv("rate_limit.webhook_burst", "EVENT_COMING_RATE_LIMIT_WEBHOOK_BURST")
+	v.BindEnv("rate_limit.resend_requests_per_second", "EVENT_COMING_RATE_LIMIT_RESEND_REQUESTS_PER_SECOND")
+	v.BindEnv("rate_limit.resend_burst", "EVENT_COMING_RATE_LIMIT_RESEND_BURST")
+	v.BindEnv("rate_limit.rsvp_requests_per_second", "EVENT_COMING_RATE_LIMIT_RSVP_REQUESTS_PER_SECOND")
+	v.BindEnv("rate_limit.rsvp_burst", "EVENT_COMING_RATE_LIMIT_RSVP_BURST")
+
+	// Entity bindings
+	v.BindEnv("entity.max_hierarchy_depth", "EVENT_COMING_ENTITY_MAX_HIERARCHY_DEPTH")
+
+	// Event bindings
+	v.BindEnv("event.duplicate_window", "EVENT_COMING_EVENT_DUPLICATE_WINDOW")
+	v.BindEnv("event.default_timezone", "EVENT_COMING_EVENT_DEFAULT_TIMEZONE")
+	v.BindEnv("event.default_locale", "EVENT_COMING_EVENT_DEFAULT_LOCALE")
+	v.BindEnv("event.import_max_events", "EVENT_COMING_EVENT_IMPORT_MAX_EVENTS")
+
+	// Location bindings
+	v.BindEnv("location.stale_after", "EVENT_COMING_LOCATION_STALE_AFTER")
+	v.BindEnv("location.eta_update_threshold", "EVENT_COMING_LOCATION_ETA_UPDATE_THRESHOLD")
+	v.BindEnv("location.eta_update_interval", "EVENT_COMING_LOCATION_ETA_UPDATE_INTERVAL")
+	v.BindEnv("location.min_update_interval", "EVENT_COMING_LOCATION_MIN_UPDATE_INTERVAL")
+	v.BindEnv("location.max_speed", "EVENT_COMING_LOCATION_MAX_SPEED")
+	v.BindEnv("location.max_batch_size", "EVENT_COMING_LOCATION_MAX_BATCH_SIZE")
+
+	// Worker bindings
+	v.BindEnv("worker.dry_run", "EVENT_COMING_WORKER_DRY_RUN")
+	v.BindEnv("worker.dry_run_mark_processed", "EVENT_COMING_WORKER_DRY_RUN_MARK_PROCESSED")
+	v.BindEnv("worker.auto_complete_grace", "EVENT_COMING_WORKER_AUTO_COMPLETE_GRACE")
+	v.BindEnv("worker.token_purge_interval", "EVENT_COMING_WORKER_TOKEN_PURGE_INTERVAL")
+	v.BindEnv("worker.token_retention", "EVENT_COMING_WORKER_TOKEN_RETENTION")
+	v.BindEnv("worker.participant_data_retention", "EVENT_COMING_WORKER_PARTICIPANT_DATA_RETENTION")
+	v.BindEnv("worker.redaction_interval", "EVENT_COMING_WORKER_REDACTION_INTERVAL")
+	v.BindEnv("worker.notification_max_attempts", "EVENT_COMING_WORKER_NOTIFICATION_MAX_ATTEMPTS")
+
+	// Pagination bindings
+	v.BindEnv("pagination.default_per_page", "EVENT_COMING_PAGINATION_DEFAULT_PER_PAGE")
+	v.BindEnv("pagination.max_per_page", "EVENT_COMING_PAGINATION_MAX_PER_PAGE")
+
+	// Participant bindings
+	v.BindEnv("participant.max_batch_size", "EVENT_COMING_PARTICIPANT_MAX_BATCH_SIZE")
+	v.BindEnv("participant.batch_chunk_size", "EVENT_COMING_PARTICIPANT_BATCH_CHUNK_SIZE")
+
+	// Scheduling bindings
+	v.BindEnv("scheduling.past_policy", "EVENT_COMING_SCHEDULING_PAST_POLICY")
+	v.BindEnv("scheduling.min_reminder_lead", "EVENT_COMING_SCHEDULING_MIN_REMINDER_LEAD")
+	v.BindEnv("scheduling.max_reminder_lead", "EVENT_COMING_SCHEDULING_MAX_REMINDER_LEAD")
+
+	// Outbound webhook bindings
+	v.BindEnv("outbound_webhook.timeout", "EVENT_COMING_OUTBOUND_WEBHOOK_TIMEOUT")
+	v.BindEnv("outbound_webhook.max_attempts", "EVENT_COMING_OUTBOUND_WEBHOOK_MAX_ATTEMPTS")
+	v.BindEnv("outbound_webhook.retry_backoff", "EVENT_COMING_OUTBOUND_WEBHOOK_RETRY_BACKOFF")
+
+	// Storage bindings
+	v.BindEnv("storage.driver", "EVENT_COMING_STORAGE_DRIVER")
+	v.BindEnv("storage.local_path", "EVENT_COMING_STORAGE_LOCAL_PATH")
+	v.BindEnv("storage.s3_endpoint", "EVENT_COMING_STORAGE_S3_ENDPOINT")
+	v.BindEnv("storage.s3_region", "EVENT_COMING_STORAGE_S3_REGION")
+	v.BindEnv("storage.s3_bucket", "EVENT_COMING_STORAGE_S3_BUCKET")
+	v.BindEnv("storage.s3_access_key", "EVENT_COMING_STORAGE_S3_ACCESS_KEY")
+	v.BindEnv("storage.s3_secret_key", "EVENT_COMING_STORAGE_S3_SECRET_KEY")
+
+	// Attachment bindings
+	v.BindEnv("attachment.max_size", "EVENT_COMING_ATTACHMENT_MAX_SIZE")
+	v.BindEnv("attachment.allowed_content_types", "EVENT_COMING_ATTACHMENT_ALLOWED_CONTENT_TYPES")
+
+	// RSVP bindings
+	v.BindEnv("rsvp.secret", "EVENT_COMING_RSVP_SECRET")
+	v.BindEnv("rsvp.token_ttl", "EVENT_COMING_RSVP_TOKEN_TTL")
+	v.BindEnv("rsvp.page_url", "EVENT_COMING_RSVP_PAGE_URL")
+
+	// Geocoding bindings
+	v.BindEnv("geocoding.provider", "EVENT_COMING_GEOCODING_PROVIDER")
+	v.BindEnv("geocoding.base_url", "EVENT_COMING_GEOCODING_BASE_URL")
+	v.BindEnv("geocoding.user_agent", "EVENT_COMING_GEOCODING_USER_AGENT")
+	v.BindEnv("geocoding.timeout", "EVENT_COMING_GEOCODING_TIMEOUT")
+	v.BindEnv("geocoding.cache_ttl", "EVENT_COMING_GEOCODING_CACHE_TTL")
 
 	// App bindings
 	v.BindEnv("app.environment", "EVENT_COMING_APP_ENVIRONMENT")
 	v.BindEnv("app.debug", "EVENT_COMING_APP_DEBUG")
+	v.BindEnv("app.default_phone_region", "EVENT_COMING_APP_DEFAULT_PHONE_REGION")
 }
 
 func setDefaults(v *viper.Viper) {
@@ -161,6 +666,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("app.name", "event-coming")
 	v.SetDefault("app.environment", "development")
 	v.SetDefault("app.debug", true)
+	v.SetDefault("app.default_phone_region", "")
 
 	// Server defaults
 	v.SetDefault("server.host", "0.0.0.0")
@@ -168,6 +674,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.read_timeout", 30*time.Second)
 	v.SetDefault("server.write_timeout", 30*time.Second)
 	v.SetDefault("server.idle_timeout", 60*time.Second)
+	v.SetDefault("server.cors_origins", []string{})
+	v.SetDefault("server.cors_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	v.SetDefault("server.cors_headers", []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Origin", "Cache-Control", "X-Requested-With", "Idempotency-Key", "If-Match"})
 
 	// Database defaults
 	v.SetDefault("database.host", "localhost")
@@ -193,13 +702,15 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("redis.idle_timeout", 5*time.Minute)
 
 	// JWT defaults
-	v.SetDefault("jwt.access_secret", "change-me-in-production")
-	v.SetDefault("jwt.refresh_secret", "change-me-in-production")
+	v.SetDefault("jwt.access_secret", PlaceholderSecret)
+	v.SetDefault("jwt.refresh_secret", PlaceholderSecret)
 	v.SetDefault("jwt.access_token_ttl", 15*time.Minute)
 	v.SetDefault("jwt.refresh_token_ttl", 7*24*time.Hour)
 	v.SetDefault("jwt.issuer", "event-coming")
 	v.SetDefault("jwt.access_expires_in", 15*time.Minute)
 	v.SetDefault("jwt.refresh_expires_in", 7*24*time.Hour)
+	v.SetDefault("jwt.impersonation_expires_in", 10*time.Minute)
+	v.SetDefault("jwt.denylist_fail_open", false)
 
 	// WhatsApp defaults
 	v.SetDefault("whatsapp.verify_token", "")
@@ -211,11 +722,118 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("whatsapp.base_url", "https://graph.facebook.com")
 	v.SetDefault("whatsapp.webhook_verify_token", "event-coming-webhook-token")
 	v.SetDefault("whatsapp.webhook_secret", "")
+	v.SetDefault("whatsapp.help_on_unknown_reply", false)
+	v.SetDefault("whatsapp.webhook_timestamp_tolerance", 5*time.Minute)
+	v.SetDefault("whatsapp.sender_encryption_key", "")
+	v.SetDefault("whatsapp.send_timeout", 10*time.Second)
+	v.SetDefault("whatsapp.breaker_threshold", 5)
+	v.SetDefault("whatsapp.breaker_cooldown", 30*time.Second)
 
 	// OSRM defaults
 	v.SetDefault("osrm.enabled", false)
 	v.SetDefault("osrm.base_url", "http://localhost:5000")
 	v.SetDefault("osrm.timeout", 10*time.Second)
+
+	// WebSocket defaults
+	v.SetDefault("websocket.send_buffer_size", 256)
+	v.SetDefault("websocket.backpressure_policy", "disconnect")
+	v.SetDefault("websocket.replay_size", 50)
+	v.SetDefault("websocket.write_wait", 10*time.Second)
+	v.SetDefault("websocket.pong_wait", 60*time.Second)
+	v.SetDefault("websocket.ping_period", 54*time.Second)
+	v.SetDefault("websocket.max_message_size", 4096)
+
+	// Cache defaults
+	v.SetDefault("cache.default_ttl", 24*time.Hour)
+	v.SetDefault("cache.min_ttl", 1*time.Hour)
+	v.SetDefault("cache.after_event_ttl", 1*time.Hour)
+	v.SetDefault("cache.idempotency_ttl", 24*time.Hour)
+
+	// Rate limit defaults
+	v.SetDefault("rate_limit.enabled", true)
+	v.SetDefault("rate_limit.auth_requests_per_second", 0.2) // 12/min
+	v.SetDefault("rate_limit.auth_burst", 10)
+	v.SetDefault("rate_limit.email_requests_per_second", 0.05) // 3/min
+	v.SetDefault("rate_limit.email_burst", 5)
+	v.SetDefault("rate_limit.webhook_requests_per_second", 50)
+	v.SetDefault("rate_limit.webhook_burst", 100)
+	v.SetDefault("rate_limit.resend_requests_per_second", 1.0/600) // 1 a cada 10 min
+	v.SetDefault("rate_limit.resend_burst", 2)
+	v.SetDefault("rate_limit.rsvp_requests_per_second", 1)
+	v.SetDefault("rate_limit.rsvp_burst", 10)
+
+	// Entity defaults
+	v.SetDefault("entity.max_hierarchy_depth", 10)
+
+	// Event defaults
+	v.SetDefault("event.duplicate_window", 1*time.Hour)
+	v.SetDefault("event.default_timezone", "America/Sao_Paulo")
+	v.SetDefault("event.default_locale", "pt")
+	v.SetDefault("event.import_max_events", 200)
+
+	// Location defaults
+	v.SetDefault("location.stale_after", 5*time.Minute)
+	v.SetDefault("location.eta_update_threshold", 2)
+	v.SetDefault("location.eta_update_interval", 30*time.Second)
+	v.SetDefault("location.min_update_interval", 5*time.Second)
+	v.SetDefault("location.max_speed", 0)
+	v.SetDefault("location.max_batch_size", 1000)
+
+	// Worker defaults
+	v.SetDefault("worker.dry_run", false)
+	v.SetDefault("worker.dry_run_mark_processed", false)
+	v.SetDefault("worker.auto_complete_grace", time.Hour)
+	v.SetDefault("worker.token_purge_interval", time.Hour)
+	v.SetDefault("worker.token_retention", 7*24*time.Hour)
+	v.SetDefault("worker.participant_data_retention", 0)
+	v.SetDefault("worker.redaction_interval", time.Hour)
+	v.SetDefault("worker.notification_max_attempts", 3)
+
+	// Pagination defaults
+	v.SetDefault("pagination.default_per_page", 20)
+	v.SetDefault("pagination.max_per_page", 100)
+
+	// Participant defaults
+	v.SetDefault("participant.max_batch_size", 1000)
+	v.SetDefault("participant.batch_chunk_size", 100)
+
+	// Scheduling defaults
+	v.SetDefault("scheduling.past_policy", PastSchedulerSkip)
+	v.SetDefault("scheduling.min_reminder_lead", 15*time.Minute)
+	v.SetDefault("scheduling.max_reminder_lead", 7*24*time.Hour)
+
+	// Outbound webhook defaults
+	v.SetDefault("outbound_webhook.timeout", 10*time.Second)
+	v.SetDefault("outbound_webhook.max_attempts", 5)
+	v.SetDefault("outbound_webhook.retry_backoff", 1*time.Second)
+
+	// Storage defaults
+	v.SetDefault("storage.driver", "local")
+	v.SetDefault("storage.local_path", "./data/attachments")
+	v.SetDefault("storage.s3_region", "us-east-1")
+
+	// Attachment defaults
+	v.SetDefault("attachment.max_size", 10<<20) // 10 MB
+	v.SetDefault("attachment.allowed_content_types", []string{
+		"application/pdf",
+		"image/png",
+		"image/jpeg",
+		"image/gif",
+		"image/webp",
+		"text/plain",
+	})
+
+	// RSVP defaults
+	v.SetDefault("rsvp.secret", PlaceholderSecret)
+	v.SetDefault("rsvp.token_ttl", 30*24*time.Hour)
+	v.SetDefault("rsvp.page_url", "http://localhost:3000/rsvp")
+
+	// Geocoding defaults
+	v.SetDefault("geocoding.provider", "")
+	v.SetDefault("geocoding.base_url", "https://nominatim.openstreetmap.org")
+	v.SetDefault("geocoding.user_agent", "event-coming")
+	v.SetDefault("geocoding.timeout", 5*time.Second)
+	v.SetDefault("geocoding.cache_ttl", 30*24*time.Hour)
 }
 
 // GetDSN returns the PostgreSQL connection string
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJWTKeys(t *testing.T) {
+	keys := parseJWTKeys(" k1:secret1 ,k2:secret2,invalid,:nokid,k3:", "k2")
+	assert.Equal(t, []JWTKey{
+		{KID: "k1", Secret: "secret1"},
+		{KID: "k2", Secret: "secret2", Active: true},
+	}, keys)
+}
+
+func TestJWTConfig_SigningKey(t *testing.T) {
+	cfg := &JWTConfig{AccessSecret: "legacy"}
+	kid, secret := cfg.SigningKey()
+	assert.Empty(t, kid)
+	assert.Equal(t, "legacy", secret)
+
+	// Without an active kid the first key signs
+	cfg.Keys = parseJWTKeys("k1:secret1,k2:secret2", "")
+	kid, secret = cfg.SigningKey()
+	assert.Equal(t, "k1", kid)
+	assert.Equal(t, "secret1", secret)
+
+	cfg.Keys = parseJWTKeys("k1:secret1,k2:secret2", "k2")
+	kid, secret = cfg.SigningKey()
+	assert.Equal(t, "k2", kid)
+	assert.Equal(t, "secret2", secret)
+}
+
+func TestJWTConfig_Validate_RejectsPlaceholderSecretsOutsideDebug(t *testing.T) {
+	valid := func() *JWTConfig {
+		return &JWTConfig{
+			AccessSecret:           "access",
+			RefreshSecret:          "refresh",
+			AccessExpiresIn:        15 * time.Minute,
+			RefreshExpiresIn:       time.Hour,
+			ImpersonationExpiresIn: 10 * time.Minute,
+		}
+	}
+	assert.NoError(t, valid().Validate(false))
+
+	cfg := valid()
+	cfg.AccessSecret = PlaceholderSecret
+	assert.Error(t, cfg.Validate(false))
+	assert.NoError(t, cfg.Validate(true))
+
+	cfg = valid()
+	cfg.RefreshSecret = PlaceholderSecret
+	assert.Error(t, cfg.Validate(false))
+
+	cfg = valid()
+	cfg.Keys = parseJWTKeys("k1:secret1,k2:"+PlaceholderSecret, "k1")
+	assert.Error(t, cfg.Validate(false))
+}
+
+func TestRSVPConfig_Validate(t *testing.T) {
+	cfg := &RSVPConfig{Secret: "rsvp-secret"}
+	assert.NoError(t, cfg.Validate(false))
+
+	cfg.Secret = PlaceholderSecret
+	assert.Error(t, cfg.Validate(false))
+	assert.NoError(t, cfg.Validate(true))
+
+	cfg.Secret = ""
+	assert.Error(t, cfg.Validate(false))
+}
+
+func TestSchedulingConfig_Validate(t *testing.T) {
+	cfg := &SchedulingConfig{PastPolicy: PastSchedulerSkip, MinReminderLead: 15 * time.Minute}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.PastPolicy = PastSchedulerFireOnce
+	assert.NoError(t, cfg.Validate())
+
+	cfg.PastPolicy = "fire_all"
+	assert.Error(t, cfg.Validate())
+
+	cfg.PastPolicy = PastSchedulerSkip
+	cfg.MinReminderLead = -time.Minute
+	assert.Error(t, cfg.Validate())
+
+	cfg.MinReminderLead = 0
+	cfg.MaxReminderLead = -time.Hour
+	assert.Error(t, cfg.Validate())
+}
+
+func TestWhatsAppConfig_Validate(t *testing.T) {
+	cfg := &WhatsAppConfig{BreakerThreshold: 5, BreakerCooldown: 30 * time.Second}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.BreakerCooldown = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg.BreakerThreshold = 0
+	assert.NoError(t, cfg.Validate(), "a disabled breaker needs no cooldown")
+
+	cfg.BreakerThreshold = -1
+	assert.Error(t, cfg.Validate())
+}
+
+func TestJWTConfig_Validate(t *testing.T) {
+	valid := func() *JWTConfig {
+		return &JWTConfig{
+			AccessSecret:           "access",
+			RefreshSecret:          "refresh",
+			AccessExpiresIn:        15 * time.Minute,
+			RefreshExpiresIn:       7 * 24 * time.Hour,
+			ImpersonationExpiresIn: 10 * time.Minute,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *JWTConfig)
+		debug   bool
+		wantErr string
+	}{
+		{name: "valid", modify: func(cfg *JWTConfig) {}},
+		{name: "zero access expiry", modify: func(cfg *JWTConfig) { cfg.AccessExpiresIn = 0 }, wantErr: "jwt.access_expires_in must be positive"},
+		{name: "negative refresh expiry", modify: func(cfg *JWTConfig) { cfg.RefreshExpiresIn = -time.Hour }, wantErr: "jwt.refresh_expires_in must be positive"},
+		{name: "refresh shorter than access", modify: func(cfg *JWTConfig) { cfg.RefreshExpiresIn = time.Minute }, wantErr: "must not be shorter than jwt.access_expires_in"},
+		{name: "zero impersonation expiry", modify: func(cfg *JWTConfig) { cfg.ImpersonationExpiresIn = 0 }, wantErr: "jwt.impersonation_expires_in must be positive"},
+		{name: "impersonation longer than access", modify: func(cfg *JWTConfig) { cfg.ImpersonationExpiresIn = time.Hour }, wantErr: "must not be longer than jwt.access_expires_in"},
+		{name: "empty access secret", modify: func(cfg *JWTConfig) { cfg.AccessSecret = "" }, wantErr: "jwt.access_secret (or jwt.keys) must be set"},
+		{name: "keys replace the access secret", modify: func(cfg *JWTConfig) {
+			cfg.AccessSecret = ""
+			cfg.Keys = parseJWTKeys("k1:secret1", "k1")
+		}},
+		{name: "empty refresh secret", modify: func(cfg *JWTConfig) { cfg.RefreshSecret = "" }, wantErr: "jwt.refresh_secret must be set"},
+		{name: "empty secrets allowed in debug", modify: func(cfg *JWTConfig) {
+			cfg.AccessSecret = ""
+			cfg.RefreshSecret = ""
+		}, debug: true},
+		{name: "debug still checks expiries", modify: func(cfg *JWTConfig) { cfg.AccessExpiresIn = 0 }, debug: true, wantErr: "jwt.access_expires_in must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+
+			err := cfg.Validate(tt.debug)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoad_RejectsInvalidJWTConfig(t *testing.T) {
+	t.Setenv("EVENT_COMING_JWT_ACCESS_EXPIRES_IN", "0s")
+
+	_, err := Load()
+	assert.ErrorContains(t, err, "jwt.access_expires_in must be positive")
+}
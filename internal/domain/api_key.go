@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix identifies API keys in the Authorization header ("Bearer sk_...")
+const APIKeyPrefix = "sk_"
+
+// APIKey authenticates server-to-server integrations of an entity. Only the
+// SHA-256 hash of the key is stored, like refresh tokens
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntityID   uuid.UUID  `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" db:"name" gorm:"size:100;not null"`
+	Prefix     string     `json:"prefix" db:"prefix" gorm:"size:20;not null"` // Início da chave, para identificá-la na listagem
+	KeyHash    string     `json:"-" db:"key_hash" gorm:"size:64;uniqueIndex;not null"`
+	Role       UserRole   `json:"role" db:"role" gorm:"size:50;not null"`
+	CreatedBy  uuid.UUID  `json:"created_by" db:"created_by" gorm:"type:uuid;not null"` // Usuário em nome de quem a chave age (auditoria, autoria)
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at" gorm:"index"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"` // nil: não expira
+}
+
+// Expired reports whether the key has an expiry that already passed
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsAPIKey reports whether the bearer token is an API key rather than a JWT
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// HashAPIKey returns the hex SHA-256 of the raw key, as stored in KeyHash
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
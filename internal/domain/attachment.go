@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a file (agenda, map...) attached to an event. The content lives in the
+// configured storage under StorageKey.
+type Attachment struct {
+	ID          uuid.UUID `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntityID    uuid.UUID `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	EventID     uuid.UUID `json:"event_id" db:"event_id" gorm:"type:uuid;not null;index"`
+	Filename    string    `json:"filename" db:"filename" gorm:"size:255;not null"`
+	ContentType string    `json:"content_type" db:"content_type" gorm:"size:100;not null"`
+	StorageKey  string    `json:"-" db:"storage_key" gorm:"size:500;not null"`
+	Size        int64     `json:"size" db:"size" gorm:"not null"`
+	UploadedBy  uuid.UUID `json:"uploaded_by" db:"uploaded_by" gorm:"type:uuid;not null"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+}
+
+func (Attachment) TableName() string {
+	return "event_attachments"
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction represents a mutation recorded in the audit log
+type AuditAction string
+
+const (
+	AuditActionCreate       AuditAction = "create"
+	AuditActionUpdate       AuditAction = "update"
+	AuditActionDelete       AuditAction = "delete"
+	AuditActionStatusChange AuditAction = "status_change"
+	AuditActionRedact       AuditAction = "redact"
+	AuditActionImpersonate  AuditAction = "impersonate"
+)
+
+// AuditTargetType represents the type of resource that was changed
+type AuditTargetType string
+
+const (
+	AuditTargetEntity      AuditTargetType = "entity"
+	AuditTargetEvent       AuditTargetType = "event"
+	AuditTargetParticipant AuditTargetType = "participant"
+	AuditTargetAttachment  AuditTargetType = "attachment"
+	AuditTargetUser        AuditTargetType = "user"
+)
+
+// AuditLog records who changed what inside an entity
+type AuditLog struct {
+	ID          uuid.UUID  `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntityID    uuid.UUID  `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	ActorUserID *uuid.UUID `json:"actor_user_id,omitempty" db:"actor_user_id" gorm:"type:uuid;index"` // nil for system changes
+	// Super admin acting as ActorUserID with an impersonation token; nil otherwise
+	ImpersonatorUserID *uuid.UUID      `json:"impersonator_user_id,omitempty" db:"impersonator_user_id" gorm:"type:uuid;index"`
+	Action             AuditAction     `json:"action" db:"action" gorm:"size:50;not null;index"`
+	TargetType         AuditTargetType `json:"target_type" db:"target_type" gorm:"size:50;not null"`
+	TargetID           uuid.UUID       `json:"target_id" db:"target_id" gorm:"type:uuid;not null;index"`
+	Diff               map[string]any  `json:"diff,omitempty" db:"diff" gorm:"type:jsonb;serializer:json"`
+	CreatedAt          time.Time       `json:"created_at" db:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditLogQuery holds query parameters for the audit log
+type AuditLogQuery struct {
+	EntityID uuid.UUID
+	Action   *AuditAction
+	Page     int
+	PerPage  int
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type actorContextKey struct{}
+
+type impersonatorContextKey struct{}
+
+type statusSourceContextKey struct{}
+
+// WithActor returns a context carrying the id of the user performing the request
+func WithActor(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, userID)
+}
+
+// ActorFromContext returns the acting user id, or nil for system operations
+func ActorFromContext(ctx context.Context) *uuid.UUID {
+	if userID, ok := ctx.Value(actorContextKey{}).(uuid.UUID); ok {
+		return &userID
+	}
+	return nil
+}
+
+// WithImpersonator returns a context carrying the id of the super admin acting as the
+// actor with an impersonation token
+func WithImpersonator(ctx context.Context, adminUserID uuid.UUID) context.Context {
+	return context.WithValue(ctx, impersonatorContextKey{}, adminUserID)
+}
+
+// ImpersonatorFromContext returns the impersonating super admin id, or nil when the actor
+// acts as themselves
+func ImpersonatorFromContext(ctx context.Context) *uuid.UUID {
+	if userID, ok := ctx.Value(impersonatorContextKey{}).(uuid.UUID); ok {
+		return &userID
+	}
+	return nil
+}
+
+// WithStatusSource returns a context carrying what triggers the status changes made with it
+func WithStatusSource(ctx context.Context, source StatusSource) context.Context {
+	return context.WithValue(ctx, statusSourceContextKey{}, source)
+}
+
+// StatusSourceFromContext returns the source set with WithStatusSource. Without one,
+// changes with an actor come from an admin and the rest from the system
+func StatusSourceFromContext(ctx context.Context) StatusSource {
+	if source, ok := ctx.Value(statusSourceContextKey{}).(StatusSource); ok {
+		return source
+	}
+	if ActorFromContext(ctx) != nil {
+		return StatusSourceAdmin
+	}
+	return StatusSourceSystem
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -44,7 +45,7 @@ type Entity struct {
 	Relationship     EntityRelationship     `json:"relationship,omitempty" db:"relationship" gorm:"size:50"`
 	ParentID         *uuid.UUID             `json:"parent_id,omitempty" db:"parent_id" gorm:"type:uuid;index"` // Entidade pai (hierarquia)
 	Type             EntityType             `json:"type" db:"type" gorm:"size:50;not null;default:'natural person';index"`
-	Name             string                 `json:"name" db:"name" gorm:"size:200"`
+	Name             string                 `json:"name" db:"name" gorm:"size:200;index:idx_entities_name_trgm,type:gin,expression:LOWER(name) gin_trgm_ops"`
 	Email            *string                `json:"email,omitempty" db:"email" gorm:"size:255;index"`
 	PhoneNumber      *string                `json:"phone_number,omitempty" db:"phone_number" gorm:"size:20;index"`
 	Document         *string                `json:"document,omitempty" db:"document" gorm:"size:50;index"` // CPF, CNPJ, etc.
@@ -55,6 +56,21 @@ type Entity struct {
 	EntityPermission EntityPermission       `json:"entity_permission" db:"entity_permission" gorm:"size:50;not null;default:'Participant'"`
 	DocumentType     DocumentType           `json:"document_type" db:"document_type" gorm:"size:20"`
 	Description      *string                `json:"description,omitempty" db:"description" gorm:"size:500"`
+	// Antecedência padrão (em minutos antes do início) dos schedulers dos eventos; nil usa o padrão do sistema
+	ConfirmationOffsetMinutes *int `json:"confirmation_offset_minutes,omitempty" db:"confirmation_offset_minutes"`
+	ReminderOffsetMinutes     *int `json:"reminder_offset_minutes,omitempty" db:"reminder_offset_minutes"`
+	LocationOffsetMinutes     *int `json:"location_offset_minutes,omitempty" db:"location_offset_minutes"`
+	// Janela de silêncio ("HH:MM", no fuso do evento) em que notificações agendadas são adiadas
+	QuietHoursStart *string `json:"quiet_hours_start,omitempty" db:"quiet_hours_start" gorm:"size:5"`
+	QuietHoursEnd   *string `json:"quiet_hours_end,omitempty" db:"quiet_hours_end" gorm:"size:5"`
+	// Tipos de evento personalizados ("delivery", "appointment") aceitos além de demand e periodic
+	EventTypes EventTypes `json:"event_types,omitempty" db:"event_types" gorm:"type:jsonb"`
+	// Junta em uma única mensagem os lembretes de quem tem vários eventos no mesmo dia
+	ReminderDigest bool `json:"reminder_digest" db:"reminder_digest" gorm:"not null;default:false"`
+	// Completa automaticamente os eventos que ficaram active depois do fim (mais a carência do worker)
+	AutoCompleteEvents bool `json:"auto_complete_events" db:"auto_complete_events" gorm:"not null;default:false"`
+	// Região (ISO 3166-1, ex.: "BR") dos telefones informados sem o código do país; nil usa o padrão da aplicação
+	PhoneRegion *string `json:"phone_region,omitempty" db:"phone_region" gorm:"size:2"`
 	// Relacionamentos
 	Parent       *Entity       `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
 	Children     []Entity      `json:"children,omitempty" gorm:"foreignKey:ParentID"`
@@ -67,12 +83,64 @@ func (Entity) TableName() string {
 	return "entities"
 }
 
+// EntityDescendant é uma entidade da subárvore com sua profundidade em relação à raiz
+type EntityDescendant struct {
+	Entity
+	Depth int `json:"depth" db:"depth" gorm:"column:depth"`
+}
+
 // CanCreateEvents retorna true se a entidade pode criar eventos
 // Todas as entidades podem criar eventos
 func (e *Entity) CanCreateEvents() bool {
 	return e.Active && (e.EntityPermission == EntityPermissionAdmin || e.EntityPermission == EntityPermissionStakeholder)
 }
 
+// AllowsEventType retorna true se a entidade pode criar eventos do tipo t: os tipos
+// embutidos são sempre aceitos, os personalizados só se estiverem configurados
+func (e *Entity) AllowsEventType(t EventType) bool {
+	if t.IsBuiltin() {
+		return true
+	}
+	return e != nil && slices.Contains(e.EventTypes, t)
+}
+
+// Antecedência padrão dos schedulers quando nem o evento nem a entidade definem uma
+const (
+	DefaultConfirmationOffset = 24 * time.Hour
+	DefaultReminderOffset     = 2 * time.Hour
+	DefaultLocationOffset     = 1 * time.Hour
+)
+
+// SchedulerOffsets holds how long before the event start each scheduler fires
+type SchedulerOffsets struct {
+	Confirmation time.Duration
+	Reminder     time.Duration
+	Location     time.Duration
+}
+
+// SchedulerOffsets retorna a antecedência dos schedulers da entidade, usando o padrão do
+// sistema para os valores não configurados (ou inválidos)
+func (e *Entity) SchedulerOffsets() SchedulerOffsets {
+	offsets := SchedulerOffsets{
+		Confirmation: DefaultConfirmationOffset,
+		Reminder:     DefaultReminderOffset,
+		Location:     DefaultLocationOffset,
+	}
+	if e == nil {
+		return offsets
+	}
+	if e.ConfirmationOffsetMinutes != nil && *e.ConfirmationOffsetMinutes > 0 {
+		offsets.Confirmation = time.Duration(*e.ConfirmationOffsetMinutes) * time.Minute
+	}
+	if e.ReminderOffsetMinutes != nil && *e.ReminderOffsetMinutes > 0 {
+		offsets.Reminder = time.Duration(*e.ReminderOffsetMinutes) * time.Minute
+	}
+	if e.LocationOffsetMinutes != nil && *e.LocationOffsetMinutes > 0 {
+		offsets.Location = time.Duration(*e.LocationOffsetMinutes) * time.Minute
+	}
+	return offsets
+}
+
 // CreateEntityInput holds data for creating an entity
 type CreateEntityInput struct {
 	ParentID    *uuid.UUID
@@ -94,4 +162,73 @@ type UpdateEntityInput struct {
 	Document    *string
 	IsActive    *bool
 	Metadata    map[string]interface{}
+
+	ConfirmationOffsetMinutes *int
+	ReminderOffsetMinutes     *int
+	LocationOffsetMinutes     *int
+
+	QuietHoursStart *string
+	QuietHoursEnd   *string
+
+	EventTypes         EventTypes
+	ReminderDigest     *bool
+	AutoCompleteEvents *bool
+	PhoneRegion        *string // "" volta a usar a região padrão da aplicação
+}
+
+// QuietHours é a janela diária (em minutos desde a meia-noite) em que notificações não
+// são enviadas. Start > End indica uma janela que atravessa a meia-noite (22:00-07:00)
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// QuietHours retorna a janela de silêncio da entidade; false se não estiver configurada,
+// for inválida ou vazia (início igual ao fim)
+func (e *Entity) QuietHours() (QuietHours, bool) {
+	if e == nil || e.QuietHoursStart == nil || e.QuietHoursEnd == nil {
+		return QuietHours{}, false
+	}
+	start, err := time.Parse("15:04", *e.QuietHoursStart)
+	if err != nil {
+		return QuietHours{}, false
+	}
+	end, err := time.Parse("15:04", *e.QuietHoursEnd)
+	if err != nil {
+		return QuietHours{}, false
+	}
+	q := QuietHours{
+		Start: start.Hour()*60 + start.Minute(),
+		End:   end.Hour()*60 + end.Minute(),
+	}
+	if q.Start == q.End {
+		return QuietHours{}, false
+	}
+	return q, true
+}
+
+// NextAllowed retorna t se estiver fora da janela; senão, o fim da janela (no fuso loc)
+func (q QuietHours) NextAllowed(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	year, month, day := local.Date()
+	boundary := func(dayOffset int) time.Time {
+		return time.Date(year, month, day+dayOffset, q.End/60, q.End%60, 0, 0, loc)
+	}
+
+	if q.Start < q.End {
+		if minute >= q.Start && minute < q.End {
+			return boundary(0)
+		}
+		return t
+	}
+
+	// Janela atravessa a meia-noite
+	if minute >= q.Start {
+		return boundary(1)
+	}
+	if minute < q.End {
+		return boundary(0)
+	}
+	return t
 }
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_SchedulerOffsets(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	defaults := SchedulerOffsets{
+		Confirmation: DefaultConfirmationOffset,
+		Reminder:     DefaultReminderOffset,
+		Location:     DefaultLocationOffset,
+	}
+
+	tests := []struct {
+		name   string
+		entity *Entity
+		want   SchedulerOffsets
+	}{
+		{name: "nil entity", entity: nil, want: defaults},
+		{name: "unset", entity: &Entity{}, want: defaults},
+		{
+			name: "configured",
+			entity: &Entity{
+				ConfirmationOffsetMinutes: intPtr(12 * 60),
+				ReminderOffsetMinutes:     intPtr(45),
+				LocationOffsetMinutes:     intPtr(10),
+			},
+			want: SchedulerOffsets{Confirmation: 12 * time.Hour, Reminder: 45 * time.Minute, Location: 10 * time.Minute},
+		},
+		{
+			name:   "non-positive ignored",
+			entity: &Entity{ConfirmationOffsetMinutes: intPtr(0), ReminderOffsetMinutes: intPtr(-30), LocationOffsetMinutes: intPtr(20)},
+			want:   SchedulerOffsets{Confirmation: DefaultConfirmationOffset, Reminder: DefaultReminderOffset, Location: 20 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.entity.SchedulerOffsets())
+		})
+	}
+}
+
+func TestEntity_QuietHours(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+
+	_, ok := (&Entity{}).QuietHours()
+	assert.False(t, ok)
+	_, ok = (&Entity{QuietHoursStart: strPtr("22:00"), QuietHoursEnd: strPtr("22:00")}).QuietHours()
+	assert.False(t, ok, "empty window")
+	_, ok = (&Entity{QuietHoursStart: strPtr("25:00"), QuietHoursEnd: strPtr("07:00")}).QuietHours()
+	assert.False(t, ok, "invalid time")
+
+	q, ok := (&Entity{QuietHoursStart: strPtr("22:00"), QuietHoursEnd: strPtr("07:30")}).QuietHours()
+	assert.True(t, ok)
+	assert.Equal(t, QuietHours{Start: 22 * 60, End: 7*60 + 30}, q)
+}
+
+func TestQuietHours_NextAllowed(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.June, day, hour, minute, 0, 0, saoPaulo)
+	}
+
+	overnight := QuietHours{Start: 22 * 60, End: 7 * 60}
+	daytime := QuietHours{Start: 12 * 60, End: 14 * 60}
+
+	tests := []struct {
+		name  string
+		quiet QuietHours
+		t     time.Time
+		want  time.Time
+	}{
+		{"before an overnight window", overnight, at(14, 21, 59), at(14, 21, 59)},
+		{"late in an overnight window", overnight, at(14, 23, 0), at(15, 7, 0)},
+		{"early in an overnight window", overnight, at(15, 3, 0), at(15, 7, 0)},
+		{"end of an overnight window", overnight, at(15, 7, 0), at(15, 7, 0)},
+		{"inside a daytime window", daytime, at(14, 12, 30), at(14, 14, 0)},
+		{"after a daytime window", daytime, at(14, 14, 1), at(14, 14, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(tt.quiet.NextAllowed(tt.t, saoPaulo)), "got %s", tt.quiet.NextAllowed(tt.t, saoPaulo))
+		})
+	}
+
+	// The window is read in the event's timezone: 03:00 UTC is 00:00 in São Paulo
+	utc := time.Date(2025, time.June, 15, 3, 0, 0, 0, time.UTC)
+	assert.True(t, at(15, 7, 0).Equal(overnight.NextAllowed(utc, saoPaulo)))
+	assert.True(t, utc.Equal(overnight.NextAllowed(utc, time.FixedZone("UTC+9", 9*3600))))
+}
+
+func TestEntity_AllowsEventType(t *testing.T) {
+	entity := &Entity{EventTypes: EventTypes{"delivery", "appointment"}}
+
+	assert.True(t, entity.AllowsEventType(EventTypeDemand))
+	assert.True(t, entity.AllowsEventType(EventTypePeriodic))
+	assert.True(t, entity.AllowsEventType("delivery"))
+	assert.False(t, entity.AllowsEventType("workshop"))
+
+	// Sem tipos configurados só os embutidos valem
+	assert.True(t, (&Entity{}).AllowsEventType(EventTypeDemand))
+	assert.False(t, (&Entity{}).AllowsEventType("delivery"))
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
 
 // Domain errors
 var (
@@ -13,4 +17,42 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrTokenExpired      = errors.New("token expired")
 	ErrInvalidToken      = errors.New("invalid token")
+	ErrVersionConflict   = errors.New("resource was modified by another request")
+	ErrLastOwner         = errors.New("entity must keep at least one owner")
+	ErrEventFull         = errors.New("event has reached its participant limit")
+	ErrBatchTooLarge     = errors.New("batch exceeds the maximum size")
+	ErrDuplicateEvent    = errors.New("a similar event already exists")
+	ErrParticipantNotPending = errors.New("participant already answered the invitation")
+	ErrLocationConsentRequired = errors.New("participant has not consented to location sharing")
+	ErrSchedulerNotPending = errors.New("scheduler is not pending")
+	ErrAttachmentTooLarge = errors.New("attachment exceeds the maximum size")
+	ErrUnsupportedContentType = errors.New("attachment content type is not allowed")
+	ErrInviteClosed = errors.New("invite can no longer be answered")
+	ErrUnknownEventType = errors.New("event type is not configured for the entity")
+	ErrEventNotRecurring = errors.New("event has no recurrence rule")
+	ErrFeatureDisabled = errors.New("feature is disabled for the event")
+	ErrChannelUnavailable = errors.New("notification channel is not configured")
+	ErrEncryptionUnavailable = errors.New("secret encryption is not configured")
+	ErrEventNotFinished = errors.New("event has not finished")
+	ErrCheckInClosed = errors.New("check-in is not open for the event")
+	ErrTokenCheckUnavailable = errors.New("token revocation could not be checked")
 )
+
+// DuplicateEventError is returned when an event with the same name and a close start
+// time already exists in the entity. It matches ErrDuplicateEvent with errors.Is.
+type DuplicateEventError struct {
+	ExistingEventID uuid.UUID
+}
+
+func (e *DuplicateEventError) Error() string {
+	return ErrDuplicateEvent.Error()
+}
+
+func (e *DuplicateEventError) Is(target error) bool {
+	return target == ErrDuplicateEvent
+}
+
+// Details exposes the existing event to the client
+func (e *DuplicateEventError) Details() any {
+	return map[string]any{"existing_event_id": e.ExistingEventID}
+}
@@ -1,6 +1,11 @@
 package domain
 
 import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +20,171 @@ const (
 	EventTypePeriodic EventType = "periodic" // Recurring events
 )
 
+// IsBuiltin reports whether t is one of the built-in event types, allowed for every entity
+func (t EventType) IsBuiltin() bool {
+	return t == EventTypeDemand || t == EventTypePeriodic
+}
+
+// EventTypes is the list of custom event types an entity allows besides the built-in ones
+type EventTypes []EventType
+
+// Value implements driver.Valuer for jsonb storage
+func (t EventTypes) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements sql.Scanner for jsonb storage
+func (t *EventTypes) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for EventTypes: %T", value)
+	}
+
+	return json.Unmarshal(data, t)
+}
+
+// ExDates are the occurrences (original start times) excluded from a recurring event,
+// like RFC 5545 EXDATE
+type ExDates []time.Time
+
+// Value implements driver.Valuer for jsonb storage
+func (d ExDates) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return json.Marshal(d)
+}
+
+// Scan implements sql.Scanner for jsonb storage
+func (d *ExDates) Scan(value interface{}) error {
+	if value == nil {
+		*d = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for ExDates: %T", value)
+	}
+
+	return json.Unmarshal(data, d)
+}
+
+// Contains reports whether the occurrence is excluded
+func (d ExDates) Contains(occurrence time.Time) bool {
+	for _, t := range d {
+		if t.Equal(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+// EventFeatures toggles the optional subsystems of an event. A nil field counts as
+// enabled, so events stored before the flags existed keep their behavior
+type EventFeatures struct {
+	LocationTracking *bool `json:"location_tracking,omitempty"` // GPS ingestion and location requests
+	Notifications    *bool `json:"notifications,omitempty"`     // WhatsApp confirmations, reminders and organizer summaries
+	PublicRSVP       *bool `json:"public_rsvp,omitempty"`       // Invite links and the public RSVP page
+}
+
+// LocationTrackingEnabled reports whether the event accepts participant locations
+func (f EventFeatures) LocationTrackingEnabled() bool {
+	return f.LocationTracking == nil || *f.LocationTracking
+}
+
+// NotificationsEnabled reports whether the event sends scheduled messages
+func (f EventFeatures) NotificationsEnabled() bool {
+	return f.Notifications == nil || *f.Notifications
+}
+
+// PublicRSVPEnabled reports whether participants can answer through the RSVP page
+func (f EventFeatures) PublicRSVPEnabled() bool {
+	return f.PublicRSVP == nil || *f.PublicRSVP
+}
+
+// Allows reports whether a scheduler action may run for the event. Closure never
+// depends on a feature
+func (f EventFeatures) Allows(action SchedulerAction) bool {
+	switch action {
+	case SchedulerActionConfirmation, SchedulerActionReminder,
+		SchedulerActionOrganizerSummary, SchedulerActionOrganizerWrapUp,
+		SchedulerActionBroadcast:
+		return f.NotificationsEnabled()
+	case SchedulerActionLocation:
+		return f.LocationTrackingEnabled() && f.NotificationsEnabled()
+	default:
+		return true
+	}
+}
+
+// Merge returns f with the fields set in changes overwritten
+func (f EventFeatures) Merge(changes EventFeatures) EventFeatures {
+	if changes.LocationTracking != nil {
+		f.LocationTracking = changes.LocationTracking
+	}
+	if changes.Notifications != nil {
+		f.Notifications = changes.Notifications
+	}
+	if changes.PublicRSVP != nil {
+		f.PublicRSVP = changes.PublicRSVP
+	}
+	return f
+}
+
+// Resolved returns the features with every field set, for API responses
+func (f EventFeatures) Resolved() EventFeatures {
+	locationTracking, notifications, publicRSVP := f.LocationTrackingEnabled(), f.NotificationsEnabled(), f.PublicRSVPEnabled()
+	return EventFeatures{
+		LocationTracking: &locationTracking,
+		Notifications:    &notifications,
+		PublicRSVP:       &publicRSVP,
+	}
+}
+
+// Value implements driver.Valuer for jsonb storage
+func (f EventFeatures) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+// Scan implements sql.Scanner for jsonb storage
+func (f *EventFeatures) Scan(value interface{}) error {
+	if value == nil {
+		*f = EventFeatures{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for EventFeatures: %T", value)
+	}
+
+	return json.Unmarshal(data, f)
+}
+
 // EventStatus represents the status of an event
 type EventStatus string
 
@@ -28,23 +198,44 @@ const (
 
 // Event represents an event
 type Event struct {
-	ID                   uuid.UUID      `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	EntityID             uuid.UUID      `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"` // Entidade que criou o evento
-	Name                 string         `json:"name" db:"name" gorm:"size:200;not null"`
-	Description          *string        `json:"description,omitempty" db:"description" gorm:"size:1000"`
-	Type                 EventType      `json:"type" db:"type" gorm:"size:50;not null"`
-	Status               EventStatus    `json:"status" db:"status" gorm:"size:50;not null;default:'draft'"`
-	LocationLat          float64        `json:"location_lat" db:"location_lat" gorm:"not null"`
-	LocationLng          float64        `json:"location_lng" db:"location_lng" gorm:"not null"`
-	LocationAddress      *string        `json:"location_address,omitempty" db:"location_address" gorm:"size:500"`
-	StartTime            time.Time      `json:"start_time" db:"start_time" gorm:"not null"`
-	EndTime              *time.Time     `json:"end_time,omitempty" db:"end_time"`
-	RRuleString          *string        `json:"rrule_string,omitempty" db:"rrule_string" gorm:"size:500"`
-	ConfirmationDeadline *time.Time     `json:"confirmation_deadline,omitempty" db:"confirmation_deadline"`
-	CreatedBy            uuid.UUID      `json:"created_by" db:"created_by" gorm:"type:uuid;not null"`
-	CreatedAt            time.Time      `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt            time.Time      `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt            gorm.DeletedAt `json:"-" db:"deleted_at" gorm:"index"` // Soft delete
+	ID                   uuid.UUID       `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntityID             uuid.UUID       `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"` // Entidade que criou o evento
+	Name                 string          `json:"name" db:"name" gorm:"size:200;not null;index:idx_events_name_trgm,type:gin,expression:LOWER(name) gin_trgm_ops"`
+	Description          *string         `json:"description,omitempty" db:"description" gorm:"size:1000"`
+	Type                 EventType       `json:"type" db:"type" gorm:"size:50;not null"`
+	Status               EventStatus     `json:"status" db:"status" gorm:"size:50;not null;default:'draft'"`
+	LocationLat          float64         `json:"location_lat" db:"location_lat" gorm:"not null"`
+	LocationLng          float64         `json:"location_lng" db:"location_lng" gorm:"not null"`
+	LocationAddress      *string         `json:"location_address,omitempty" db:"location_address" gorm:"size:500"`
+	StartTime            time.Time       `json:"start_time" db:"start_time" gorm:"not null"`
+	Timezone             string          `json:"timezone" db:"timezone" gorm:"size:64;not null;default:'UTC'"` // IANA, usado para exibir horários
+	Locale               string          `json:"locale" db:"locale" gorm:"size:8;not null;default:'pt'"`       // Idioma das datas formatadas (pt, en, es)
+	EndTime              *time.Time      `json:"end_time,omitempty" db:"end_time"`
+	RRuleString          *string         `json:"rrule_string,omitempty" db:"rrule_string" gorm:"size:500"`
+	ExDates              ExDates         `json:"ex_dates,omitempty" db:"ex_dates" gorm:"type:jsonb"` // Ocorrências canceladas da recorrência
+	ConfirmationDeadline *time.Time      `json:"confirmation_deadline,omitempty" db:"confirmation_deadline"`
+	ResponseOptions      ResponseOptions `json:"response_options,omitempty" db:"response_options" gorm:"type:jsonb"`
+	MarkNoShows          bool            `json:"mark_no_shows" db:"mark_no_shows" gorm:"not null;default:false"` // Ao encerrar, confirmados sem check-in viram no_show
+	Features             EventFeatures   `json:"features" db:"features" gorm:"type:jsonb"`                       // Subsistemas opcionais (localização, notificações, RSVP)
+	CreatedBy            uuid.UUID       `json:"created_by" db:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt            time.Time       `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time       `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+	Version              int             `json:"version" db:"version" gorm:"not null;default:1"` // Controle de concorrência otimista
+	DeletedAt            gorm.DeletedAt  `json:"-" db:"deleted_at" gorm:"index"`                 // Soft delete
+
+	// Quando os dados pessoais dos participantes foram apagados; irreversível
+	ParticipantsRedactedAt *time.Time `json:"participants_redacted_at,omitempty" db:"participants_redacted_at"`
+	// UID do VEVENT de origem quando o evento veio de um arquivo ICS; evita importar duas vezes
+	ImportUID *string `json:"import_uid,omitempty" db:"import_uid" gorm:"size:255;index"`
+	// Quanto antes do início e depois do fim o check-in é aceito; nil usa o padrão
+	CheckInWindowBeforeMinutes *int `json:"check_in_window_before_minutes,omitempty" db:"check_in_window_before_minutes"`
+	CheckInWindowAfterMinutes  *int `json:"check_in_window_after_minutes,omitempty" db:"check_in_window_after_minutes"`
+	// Locais do evento em ordem; o primeiro é espelhado em LocationLat/LocationLng/LocationAddress
+	Locations EventLocations `json:"locations,omitempty" db:"locations" gorm:"type:jsonb"`
+	// Local usado por geofence e ETA; nil usa o principal
+	TargetLocationID *uuid.UUID `json:"target_location_id,omitempty" db:"target_location_id" gorm:"type:uuid"`
+	// Máximo de participantes confirmados (check-in incluído); nil não tem limite
+	Capacity *int `json:"capacity,omitempty" db:"capacity"`
 
 	// Relacionamento
 	Entity *Entity `json:"entity,omitempty" gorm:"foreignKey:EntityID"`
@@ -54,17 +245,191 @@ func (Event) TableName() string {
 	return "events"
 }
 
+// HasSeat reports whether the event accepts one more confirmed participant when taken
+// participants already hold a seat (see ParticipantStatus.HoldsSeat). Without a capacity
+// there's always a seat
+func (e *Event) HasSeat(taken int) bool {
+	return e.Capacity == nil || taken < *e.Capacity
+}
+
+// HasCoordinates reports whether the event's target location (see TargetCoordinates)
+// has coordinates. (0, 0) means none were given nor resolved from the address
+func (e *Event) HasCoordinates() bool {
+	lat, lng := e.TargetCoordinates()
+	return lat != 0 || lng != 0
+}
+
+// Default check-in window of events that don't set one: generous enough for events
+// created before the window existed
+const (
+	DefaultCheckInWindowBefore = 24 * time.Hour
+	DefaultCheckInWindowAfter  = 24 * time.Hour
+)
+
+// CheckInWindow returns how long before the start and after the end check-in is accepted
+func (e *Event) CheckInWindow() (before, after time.Duration) {
+	before, after = DefaultCheckInWindowBefore, DefaultCheckInWindowAfter
+	if e.CheckInWindowBeforeMinutes != nil && *e.CheckInWindowBeforeMinutes >= 0 {
+		before = time.Duration(*e.CheckInWindowBeforeMinutes) * time.Minute
+	}
+	if e.CheckInWindowAfterMinutes != nil && *e.CheckInWindowAfterMinutes >= 0 {
+		after = time.Duration(*e.CheckInWindowAfterMinutes) * time.Minute
+	}
+	return before, after
+}
+
+// CheckCheckIn returns ErrCheckInClosed unless at is inside the event's check-in window
+// around start and end (start when there is no end): an occurrence's times for
+// participants of a recurring event
+func (e *Event) CheckCheckIn(start time.Time, end *time.Time, at time.Time) error {
+	before, after := e.CheckInWindow()
+	if end == nil {
+		end = &start
+	}
+
+	opens, closes := start.Add(-before), end.Add(after)
+	if at.Before(opens) {
+		return fmt.Errorf("%w: opens at %s", ErrCheckInClosed, opens.UTC().Format(time.RFC3339))
+	}
+	if at.After(closes) {
+		return fmt.Errorf("%w: closed at %s", ErrCheckInClosed, closes.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// NormalizeEventName lowercases the name and collapses whitespace, so "Culto  Domingo "
+// and "culto domingo" are considered the same event name
+func NormalizeEventName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// ResponseOption describes what happens when a participant picks an option
+// (WhatsApp list or button reply) for an event
+type ResponseOption struct {
+	Title    string                 `json:"title"`
+	Status   *ParticipantStatus     `json:"status,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ResponseOptions maps option ids to their effect on the participant
+type ResponseOptions map[string]ResponseOption
+
+// Validate checks that every option has an id and only sets an invite answer
+// (confirmed or denied) as the participant status
+func (o ResponseOptions) Validate() error {
+	for id, option := range o {
+		if strings.TrimSpace(id) == "" {
+			return fmt.Errorf("%w: response option id is required", ErrInvalidInput)
+		}
+		if option.Status == nil {
+			continue
+		}
+		switch *option.Status {
+		case ParticipantStatusConfirmed, ParticipantStatusDenied:
+		default:
+			return fmt.Errorf("%w: response option %q has invalid status %q", ErrInvalidInput, id, *option.Status)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON rejects repeated option ids, which a plain map would silently collapse
+func (o *ResponseOptions) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*o = nil
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	seen := make(map[string]struct{}, len(raw))
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		id := key.(string)
+		if _, dup := seen[id]; dup {
+			return fmt.Errorf("duplicate response option %q", id)
+		}
+		seen[id] = struct{}{}
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+
+	options := make(ResponseOptions, len(raw))
+	for id, value := range raw {
+		var option ResponseOption
+		if err := json.Unmarshal(value, &option); err != nil {
+			return err
+		}
+		options[id] = option
+	}
+	*o = options
+	return nil
+}
+
+// Value implements driver.Valuer for jsonb storage
+func (o ResponseOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
+
+// Scan implements sql.Scanner for jsonb storage
+func (o *ResponseOptions) Scan(value interface{}) error {
+	if value == nil {
+		*o = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for ResponseOptions: %T", value)
+	}
+
+	return json.Unmarshal(data, o)
+}
+
+// ParticipantEvent é um evento junto com a participação de uma pessoa nele
+type ParticipantEvent struct {
+	Event
+	ParticipantID     uuid.UUID         `json:"participant_id" db:"participant_id" gorm:"column:participant_id"`
+	ParticipantStatus ParticipantStatus `json:"participant_status" db:"participant_status" gorm:"column:participant_status"`
+}
+
 // EventInstance represents a specific instance of a recurring event
 type EventInstance struct {
 	ID           uuid.UUID   `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	EventID      uuid.UUID   `json:"event_id" db:"event_id" gorm:"type:uuid;not null;index"`
+	EventID      uuid.UUID   `json:"event_id" db:"event_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_event_instances_occurrence"`
 	EntityID     uuid.UUID   `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
-	InstanceDate time.Time   `json:"instance_date" db:"instance_date" gorm:"not null"`
+	InstanceDate time.Time   `json:"instance_date" db:"instance_date" gorm:"not null;uniqueIndex:idx_event_instances_occurrence"` // Início original da ocorrência (RECURRENCE-ID)
 	Status       EventStatus `json:"status" db:"status" gorm:"size:50;not null;default:'scheduled'"`
 	StartTime    time.Time   `json:"start_time" db:"start_time" gorm:"not null"`
 	EndTime      *time.Time  `json:"end_time,omitempty" db:"end_time"`
-	CreatedAt    time.Time   `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+	// Local da ocorrência quando difere do evento; nil usa o do evento
+	LocationLat     *float64 `json:"location_lat,omitempty" db:"location_lat"`
+	LocationLng     *float64 `json:"location_lng,omitempty" db:"location_lng"`
+	LocationAddress *string  `json:"location_address,omitempty" db:"location_address" gorm:"size:500"`
+	// Editada individualmente (movida, cancelada...); a regeneração nunca a sobrescreve
+	Overridden bool      `json:"overridden" db:"overridden" gorm:"not null;default:false"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
 }
 
 func (EventInstance) TableName() string {
@@ -75,7 +440,7 @@ func (EventInstance) TableName() string {
 type CreateEventInput struct {
 	Name                 string     `json:"name" validate:"required,min=3,max=200"`
 	Description          *string    `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Type                 EventType  `json:"type" validate:"required,oneof=demand periodic"`
+	Type                 EventType  `json:"type" validate:"required,max=50"`
 	LocationLat          float64    `json:"location_lat" validate:"required,latitude"`
 	LocationLng          float64    `json:"location_lng" validate:"required,longitude"`
 	LocationAddress      *string    `json:"location_address,omitempty" validate:"omitempty,max=500"`
@@ -87,13 +452,35 @@ type CreateEventInput struct {
 
 // UpdateEventInput holds data for updating an event
 type UpdateEventInput struct {
-	Name                 *string      `json:"name,omitempty" validate:"omitempty,min=3,max=200"`
-	Description          *string      `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Status               *EventStatus `json:"status,omitempty" validate:"omitempty,oneof=draft scheduled active completed cancelled"`
-	LocationLat          *float64     `json:"location_lat,omitempty" validate:"omitempty,latitude"`
-	LocationLng          *float64     `json:"location_lng,omitempty" validate:"omitempty,longitude"`
-	LocationAddress      *string      `json:"location_address,omitempty" validate:"omitempty,max=500"`
-	StartTime            *time.Time   `json:"start_time,omitempty"`
-	EndTime              *time.Time   `json:"end_time,omitempty"`
-	ConfirmationDeadline *time.Time   `json:"confirmation_deadline,omitempty"`
+	Name                       *string         `json:"name,omitempty" validate:"omitempty,min=3,max=200"`
+	Description                *string         `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Status                     *EventStatus    `json:"status,omitempty" validate:"omitempty,oneof=draft scheduled active completed cancelled"`
+	LocationLat                *float64        `json:"location_lat,omitempty" validate:"omitempty,latitude"`
+	LocationLng                *float64        `json:"location_lng,omitempty" validate:"omitempty,longitude"`
+	LocationAddress            *string         `json:"location_address,omitempty" validate:"omitempty,max=500"`
+	StartTime                  *time.Time      `json:"start_time,omitempty"`
+	EndTime                    *time.Time      `json:"end_time,omitempty"`
+	Timezone                   *string         `json:"timezone,omitempty"`
+	Locale                     *string         `json:"locale,omitempty"`
+	ConfirmationDeadline       *time.Time      `json:"confirmation_deadline,omitempty"`
+	ResponseOptions            ResponseOptions `json:"response_options,omitempty"`
+	MarkNoShows                *bool           `json:"mark_no_shows,omitempty"`
+	ExDates                    ExDates         `json:"ex_dates,omitempty"`
+	Features                   *EventFeatures  `json:"features,omitempty"`
+	CheckInWindowBeforeMinutes *int            `json:"check_in_window_before_minutes,omitempty"`
+	CheckInWindowAfterMinutes  *int            `json:"check_in_window_after_minutes,omitempty"`
+	Locations                  EventLocations  `json:"locations,omitempty"`
+	TargetLocationID           *uuid.UUID      `json:"target_location_id,omitempty"`
+	Capacity                   *int            `json:"capacity,omitempty"`
+	// Optional fields set to NULL (sent as null); they win over the value fields above
+	ClearDescription          bool `json:"-"`
+	ClearLocationAddress      bool `json:"-"`
+	ClearEndTime              bool `json:"-"`
+	ClearConfirmationDeadline bool `json:"-"`
+	ClearCheckInWindowBefore  bool `json:"-"`
+	ClearCheckInWindowAfter   bool `json:"-"`
+	ClearTargetLocation       bool `json:"-"`
+	ClearCapacity             bool `json:"-"`
+	// ExpectedVersion rejects the update with ErrVersionConflict if the stored version differs
+	ExpectedVersion *int `json:"-"`
 }
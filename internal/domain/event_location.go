@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxEventLocations caps the venues of a single event
+const MaxEventLocations = 20
+
+// EventLocation is one of the venues of an event: a meeting point, the ceremony, the
+// party... The first of the event's locations is the primary one
+type EventLocation struct {
+	ID      uuid.UUID `json:"id"`
+	Label   string    `json:"label"`
+	Lat     float64   `json:"lat"`
+	Lng     float64   `json:"lng"`
+	Address *string   `json:"address,omitempty"`
+}
+
+// EventLocations are the ordered venues of an event
+type EventLocations []EventLocation
+
+// Value implements driver.Valuer for jsonb storage
+func (l EventLocations) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements sql.Scanner for jsonb storage
+func (l *EventLocations) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for EventLocations: %T", value)
+	}
+
+	return json.Unmarshal(data, l)
+}
+
+// Find returns the location with the id, or nil
+func (l EventLocations) Find(id uuid.UUID) *EventLocation {
+	for i := range l {
+		if l[i].ID == id {
+			return &l[i]
+		}
+	}
+	return nil
+}
+
+// SetLocations replaces the event's venues and mirrors the primary one into LocationLat,
+// LocationLng and LocationAddress, which clients of the single location keep reading.
+// Without locations the single location is left as it was. A target location that's no
+// longer in the list is dropped
+func (e *Event) SetLocations(locations EventLocations) {
+	e.Locations = locations
+	if e.TargetLocationID != nil && locations.Find(*e.TargetLocationID) == nil {
+		e.TargetLocationID = nil
+	}
+	if len(locations) == 0 {
+		return
+	}
+
+	primary := locations[0]
+	e.LocationLat, e.LocationLng, e.LocationAddress = primary.Lat, primary.Lng, primary.Address
+}
+
+// TargetCoordinates returns where geofence and ETA measure arrival: the target location
+// when one is chosen, otherwise the primary (single) location
+func (e *Event) TargetCoordinates() (lat, lng float64) {
+	if e.TargetLocationID != nil {
+		if target := e.Locations.Find(*e.TargetLocationID); target != nil {
+			return target.Lat, target.Lng
+		}
+	}
+	return e.LocationLat, e.LocationLng
+}
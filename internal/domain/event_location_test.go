@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent_SetLocations_MirrorsPrimary(t *testing.T) {
+	oldAddress := "Rua Antiga, 1"
+	event := &Event{LocationLat: -23.5, LocationLng: -46.6, LocationAddress: &oldAddress}
+
+	church := "Igreja Matriz"
+	meetingPoint := EventLocation{ID: uuid.New(), Label: "Ponto de encontro", Lat: -23.55, Lng: -46.63}
+	ceremony := EventLocation{ID: uuid.New(), Label: "Cerimônia", Lat: -23.60, Lng: -46.70, Address: &church}
+
+	event.SetLocations(EventLocations{meetingPoint, ceremony})
+	assert.Equal(t, -23.55, event.LocationLat)
+	assert.Equal(t, -46.63, event.LocationLng)
+	assert.Nil(t, event.LocationAddress, "the primary has no address")
+
+	// Reordenar troca o principal espelhado
+	event.SetLocations(EventLocations{ceremony, meetingPoint})
+	assert.Equal(t, -23.60, event.LocationLat)
+	assert.Equal(t, -46.70, event.LocationLng)
+	require.NotNil(t, event.LocationAddress)
+	assert.Equal(t, church, *event.LocationAddress)
+
+	// Sem locais o local único fica como estava
+	event.SetLocations(EventLocations{})
+	assert.Equal(t, -23.60, event.LocationLat)
+	assert.Equal(t, church, *event.LocationAddress)
+}
+
+func TestEvent_TargetCoordinates(t *testing.T) {
+	meetingPoint := EventLocation{ID: uuid.New(), Label: "Ponto de encontro", Lat: -23.55, Lng: -46.63}
+	destination := EventLocation{ID: uuid.New(), Label: "Destino", Lat: -22.90, Lng: -43.20}
+
+	event := &Event{}
+	event.SetLocations(EventLocations{meetingPoint, destination})
+
+	lat, lng := event.TargetCoordinates()
+	assert.Equal(t, [2]float64{-23.55, -46.63}, [2]float64{lat, lng}, "without a target, the primary")
+
+	event.TargetLocationID = &destination.ID
+	lat, lng = event.TargetCoordinates()
+	assert.Equal(t, [2]float64{-22.90, -43.20}, [2]float64{lat, lng})
+
+	// Removido da lista, o alvo deixa de valer
+	event.SetLocations(EventLocations{meetingPoint})
+	assert.Nil(t, event.TargetLocationID)
+	lat, lng = event.TargetCoordinates()
+	assert.Equal(t, [2]float64{-23.55, -46.63}, [2]float64{lat, lng})
+}
+
+func TestEventLocations_ValueAndScan(t *testing.T) {
+	locations := EventLocations{{ID: uuid.New(), Label: "Festa", Lat: 1.5, Lng: -2.5}}
+
+	value, err := locations.Value()
+	require.NoError(t, err)
+
+	var scanned EventLocations
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, locations, scanned)
+}
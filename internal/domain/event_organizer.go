@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventOrganizer is a member of the entity who can manage an event created by someone
+// else (update, delete and change its status), regardless of their entity role
+type EventOrganizer struct {
+	EventID   uuid.UUID `json:"event_id" db:"event_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id" gorm:"type:uuid;primaryKey;index"`
+	EntityID  uuid.UUID `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	AddedBy   uuid.UUID `json:"added_by" db:"added_by" gorm:"type:uuid;not null"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (EventOrganizer) TableName() string {
+	return "event_organizers"
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseOptions_Validate(t *testing.T) {
+	confirmed := ParticipantStatusConfirmed
+	checkedIn := ParticipantStatusCheckedIn
+
+	assert.NoError(t, ResponseOptions{"morning": {Title: "Morning", Status: &confirmed}}.Validate())
+	assert.NoError(t, ResponseOptions{"vegan": {Title: "Vegan", Metadata: map[string]interface{}{"diet": "vegan"}}}.Validate())
+	assert.ErrorIs(t, ResponseOptions{" ": {Title: "Blank"}}.Validate(), ErrInvalidInput)
+	assert.ErrorIs(t, ResponseOptions{"arrived": {Title: "Arrived", Status: &checkedIn}}.Validate(), ErrInvalidInput)
+}
+
+func TestResponseOptions_UnmarshalJSON(t *testing.T) {
+	var options ResponseOptions
+	require.NoError(t, json.Unmarshal([]byte(`{"morning":{"title":"Morning","metadata":{"session":"morning"}}}`), &options))
+	assert.Equal(t, "Morning", options["morning"].Title)
+	assert.Equal(t, "morning", options["morning"].Metadata["session"])
+
+	err := json.Unmarshal([]byte(`{"morning":{"title":"A"},"morning":{"title":"B"}}`), &options)
+	assert.ErrorContains(t, err, `duplicate response option "morning"`)
+}
+
+func TestResponseOptions_ValueAndScan(t *testing.T) {
+	confirmed := ParticipantStatusConfirmed
+	options := ResponseOptions{"afternoon": {Title: "Afternoon", Status: &confirmed}}
+
+	value, err := options.Value()
+	require.NoError(t, err)
+
+	var scanned ResponseOptions
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, options, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Nil(t, scanned)
+}
+
+func TestNormalizeEventName(t *testing.T) {
+	assert.Equal(t, "culto domingo", NormalizeEventName("  Culto \t Domingo "))
+	assert.Equal(t, NormalizeEventName("culto domingo"), NormalizeEventName("CULTO  DOMINGO"))
+	assert.NotEqual(t, NormalizeEventName("culto domingo"), NormalizeEventName("culto sábado"))
+}
+
+func TestEventFeatures_Allows(t *testing.T) {
+	off := false
+	var defaults EventFeatures
+	assert.True(t, defaults.Allows(SchedulerActionReminder))
+	assert.True(t, defaults.Allows(SchedulerActionLocation))
+
+	silent := EventFeatures{Notifications: &off}
+	assert.False(t, silent.Allows(SchedulerActionConfirmation))
+	assert.False(t, silent.Allows(SchedulerActionLocation))
+	assert.True(t, silent.Allows(SchedulerActionClosure))
+
+	untracked := EventFeatures{LocationTracking: &off}
+	assert.False(t, untracked.Allows(SchedulerActionLocation))
+	assert.True(t, untracked.Allows(SchedulerActionReminder))
+}
+
+func TestEventFeatures_Merge(t *testing.T) {
+	on, off := true, false
+	current := EventFeatures{Notifications: &off, PublicRSVP: &off}
+
+	merged := current.Merge(EventFeatures{PublicRSVP: &on})
+	assert.False(t, merged.NotificationsEnabled())
+	assert.True(t, merged.PublicRSVPEnabled())
+	assert.True(t, merged.LocationTrackingEnabled())
+
+	resolved := merged.Resolved()
+	require.NotNil(t, resolved.LocationTracking)
+	assert.True(t, *resolved.LocationTracking)
+}
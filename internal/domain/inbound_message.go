@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// InboundMessageType represents the kind of message received from a messaging provider
+type InboundMessageType string
+
+const (
+	InboundMessageText     InboundMessageType = "text"
+	InboundMessageLocation InboundMessageType = "location"
+	InboundMessageReply    InboundMessageType = "reply"
+	InboundMessageUnknown  InboundMessageType = "unknown"
+)
+
+// InboundLocation is a location shared by the sender
+type InboundLocation struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// InboundMessage is a provider-agnostic message received from a participant
+// (WhatsApp, SMS, Telegram...)
+type InboundMessage struct {
+	Provider          string
+	ProviderMessageID string
+	From              string
+	Type              InboundMessageType
+	Text              string
+	Location          *InboundLocation
+	// ReplyID is the id/payload of the button or list option picked (Type == reply)
+	ReplyID   string
+	Timestamp time.Time
+}
+
+// InboundParser converts a provider's raw webhook payload into inbound messages
+type InboundParser interface {
+	Provider() string
+	Parse(body []byte) ([]*InboundMessage, error)
+}
@@ -27,6 +27,16 @@ func (Location) TableName() string {
 	return "locations"
 }
 
+// Staleness returns how many seconds ago the location was reported (0 for timestamps in
+// the future, i.e. clock skew) and whether that is beyond staleAfter
+func (l *Location) Staleness(now time.Time, staleAfter time.Duration) (int64, bool) {
+	age := now.Sub(l.Timestamp)
+	if age < 0 {
+		age = 0
+	}
+	return int64(age / time.Second), age > staleAfter
+}
+
 // CreateLocationInput holds data for creating a location
 type CreateLocationInput struct {
 	ParticipantID uuid.UUID  `json:"participant_id" validate:"required"`
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocation_Staleness(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	staleAfter := 5 * time.Minute
+
+	tests := []struct {
+		name        string
+		age         time.Duration
+		wantSeconds int64
+		wantStale   bool
+	}{
+		{name: "fresh", age: 30 * time.Second, wantSeconds: 30},
+		{name: "exactly at the threshold is fresh", age: staleAfter, wantSeconds: 300},
+		{name: "just past the threshold is stale", age: staleAfter + time.Second, wantSeconds: 301, wantStale: true},
+		{name: "old", age: 20 * time.Minute, wantSeconds: 1200, wantStale: true},
+		{name: "future timestamp (clock skew)", age: -time.Minute, wantSeconds: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := &Location{Timestamp: now.Add(-tt.age)}
+			seconds, stale := loc.Staleness(now, staleAfter)
+			assert.Equal(t, tt.wantSeconds, seconds)
+			assert.Equal(t, tt.wantStale, stale)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryStatus represents the delivery status of an outbound notification
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent      DeliveryStatus = "sent"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusRead      DeliveryStatus = "read"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// NotificationDelivery tracks an outbound notification through the provider's status callbacks
+type NotificationDelivery struct {
+	ID                uuid.UUID      `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntityID          uuid.UUID      `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	EventID           *uuid.UUID     `json:"event_id,omitempty" db:"event_id" gorm:"type:uuid;index"`
+	ParticipantID     *uuid.UUID     `json:"participant_id,omitempty" db:"participant_id" gorm:"type:uuid;index"`
+	Channel           string         `json:"channel" db:"channel" gorm:"size:20;not null;default:'whatsapp'"`
+	Recipient         string         `json:"recipient" db:"recipient" gorm:"size:20;not null"`
+	ProviderMessageID string         `json:"provider_message_id" db:"provider_message_id" gorm:"size:128;not null;uniqueIndex"`
+	Status            DeliveryStatus `json:"status" db:"status" gorm:"size:20;not null"`
+	ErrorCode         *int           `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage      *string        `json:"error_message,omitempty" db:"error_message" gorm:"size:500"`
+	CreatedAt         time.Time      `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time      `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
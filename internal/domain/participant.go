@@ -18,20 +18,56 @@ const (
 	ParticipantStatusNoShow    ParticipantStatus = "no_show"
 )
 
+// HoldsSeat reports whether the status takes one of the event's seats (Event.Capacity)
+func (s ParticipantStatus) HoldsSeat() bool {
+	return s == ParticipantStatusConfirmed || s == ParticipantStatusCheckedIn
+}
+
 // Participant represents a participant in an event
 type Participant struct {
-	ID          uuid.UUID              `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	EventID     uuid.UUID              `json:"event_id" db:"event_id" gorm:"type:uuid;not null;index"`
-	InstanceID  *uuid.UUID             `json:"instance_id,omitempty" db:"instance_id" gorm:"type:uuid;index"`
-	EntityID    uuid.UUID              `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`          // Entidade dona do evento
-	RefEntityID *uuid.UUID             `json:"ref_entity_id,omitempty" db:"ref_entity_id" gorm:"type:uuid;index"` // Referência opcional para entidade cadastrada do participante
-	Status      ParticipantStatus      `json:"status" db:"status" gorm:"size:50;not null;default:'pending'"`
-	ConfirmedAt *time.Time             `json:"confirmed_at,omitempty" db:"confirmed_at"`
-	CheckedInAt *time.Time             `json:"checked_in_at,omitempty" db:"checked_in_at"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty" db:"metadata" gorm:"type:jsonb"`
-	CreatedAt   time.Time              `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt   gorm.DeletedAt         `json:"-" db:"deleted_at" gorm:"index"` // Soft delete
+	ID          uuid.UUID         `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EventID     uuid.UUID         `json:"event_id" db:"event_id" gorm:"type:uuid;not null;index"`
+	InstanceID  *uuid.UUID        `json:"instance_id,omitempty" db:"instance_id" gorm:"type:uuid;index"`
+	EntityID    uuid.UUID         `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`          // Entidade dona do evento
+	RefEntityID *uuid.UUID        `json:"ref_entity_id,omitempty" db:"ref_entity_id" gorm:"type:uuid;index"` // Referência opcional para entidade cadastrada do participante
+	Status      ParticipantStatus `json:"status" db:"status" gorm:"size:50;not null;default:'pending'"`
+	ConfirmedAt *time.Time        `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CheckedInAt *time.Time        `json:"checked_in_at,omitempty" db:"checked_in_at"`
+	// Consentimento para compartilhar localização; sem ele as localizações recebidas são descartadas
+	LocationConsent   bool                   `json:"location_consent" db:"location_consent" gorm:"not null;default:false"`
+	LocationConsentAt *time.Time             `json:"location_consent_at,omitempty" db:"location_consent_at"`
+	Metadata          map[string]interface{} `json:"metadata,omitempty" db:"metadata" gorm:"type:jsonb"`
+	CreatedAt         time.Time              `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time              `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+	Version           int                    `json:"version" db:"version" gorm:"not null;default:1"` // Controle de concorrência otimista
+	DeletedAt         gorm.DeletedAt         `json:"-" db:"deleted_at" gorm:"index"`                 // Soft delete
+
+	// Primeira abertura do link de convite (RSVP)
+	InviteViewedAt *time.Time `json:"invite_viewed_at,omitempty" db:"invite_viewed_at"`
+
+	// Lembrete já enviado em um resumo com os outros eventos do dia (entidades com reminder_digest)
+	ReminderDigestAt *time.Time `json:"reminder_digest_at,omitempty" db:"reminder_digest_at"`
+
+	// Anotações internas dos organizadores; nunca expostas ao participante
+	Notes *string `json:"notes,omitempty" db:"notes" gorm:"size:1000"`
+
+	// Motivo informado pelo participante ao recusar (página de RSVP ou resposta no WhatsApp)
+	DeclineReason *string `json:"decline_reason,omitempty" db:"decline_reason" gorm:"size:500"`
+
+	// Telefone normalizado na gravação (NormalizePhoneNumber); indexado para as buscas
+	// por telefone, que comparam por igualdade, e para a busca por trecho dos dígitos
+	// (trigramas)
+	PhoneNumber string `json:"-" db:"phone_number" gorm:"size:20;index;index:idx_participants_phone_trgm,type:gin,expression:phone_number gin_trgm_ops"`
+
+	// Convidado para todas as ocorrências do evento recorrente; a geração de ocorrências cria
+	// uma cópia dele em cada instância, com status próprio
+	Series bool `json:"series" db:"series" gorm:"not null;default:false"`
+	// Participante da série de que esta inscrição na ocorrência foi copiada
+	SeriesParticipantID *uuid.UUID `json:"series_participant_id,omitempty" db:"series_participant_id" gorm:"type:uuid;index"`
+
+	// Dados pessoais apagados após o evento: sem vínculo com a pessoa, metadata, anotações
+	// nem motivo de recusa; status e horários continuam para os relatórios
+	RedactedAt *time.Time `json:"redacted_at,omitempty" db:"redacted_at"`
 
 	// Relacionamento
 	Entity    *Entity `json:"entity,omitempty" gorm:"foreignKey:EntityID"`
@@ -59,6 +95,56 @@ type UpdateParticipantInput struct {
 	Email       *string                `json:"email,omitempty" validate:"omitempty,email"`
 	Status      *ParticipantStatus     `json:"status,omitempty" validate:"omitempty,oneof=pending confirmed denied checked_in no_show"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Notes       *string                `json:"notes,omitempty" validate:"omitempty,max=1000"`
+	// ClearNotes sets notes to NULL (sent as null); it wins over Notes
+	ClearNotes bool `json:"-"`
+	// ExpectedVersion rejects the update with ErrVersionConflict if the stored version differs
+	ExpectedVersion *int `json:"-"`
+}
+
+// EventStats counts an event's participants by status
+type EventStats struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Confirmed int `json:"confirmed"`
+	Denied    int `json:"denied"`
+	CheckedIn int `json:"checked_in"`
+	NoShow    int `json:"no_show"`
+}
+
+// NewEventStats counts the participants by status
+func NewEventStats(participants []*Participant) EventStats {
+	stats := EventStats{Total: len(participants)}
+	for _, p := range participants {
+		switch p.Status {
+		case ParticipantStatusPending:
+			stats.Pending++
+		case ParticipantStatusConfirmed:
+			stats.Confirmed++
+		case ParticipantStatusDenied:
+			stats.Denied++
+		case ParticipantStatusCheckedIn:
+			stats.CheckedIn++
+		case ParticipantStatusNoShow:
+			stats.NoShow++
+		}
+	}
+	return stats
+}
+
+// NewEventStatsFromCounts builds the stats from participant counts per status
+func NewEventStatsFromCounts(counts map[ParticipantStatus]int) EventStats {
+	stats := EventStats{
+		Pending:   counts[ParticipantStatusPending],
+		Confirmed: counts[ParticipantStatusConfirmed],
+		Denied:    counts[ParticipantStatusDenied],
+		CheckedIn: counts[ParticipantStatusCheckedIn],
+		NoShow:    counts[ParticipantStatusNoShow],
+	}
+	for _, n := range counts {
+		stats.Total += n
+	}
+	return stats
 }
 
 // ParticipantDistance holds participant distance information
@@ -69,3 +155,15 @@ type ParticipantDistance struct {
 	ETA           *int      `json:"eta_minutes,omitempty"`
 	LastUpdate    time.Time `json:"last_update"`
 }
+
+// NormalizePhoneNumber keeps only the digits of a phone number, so "+55 (11) 99999-9999"
+// and the provider's "5511999999999" compare equal
+func NormalizePhoneNumber(phone string) string {
+	digits := make([]byte, 0, len(phone))
+	for i := 0; i < len(phone); i++ {
+		if phone[i] >= '0' && phone[i] <= '9' {
+			digits = append(digits, phone[i])
+		}
+	}
+	return string(digits)
+}
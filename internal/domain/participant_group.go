@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ParticipantGroup is a named subset of an event's participants ("VIPs", "speakers"),
+// used to target broadcasts and filter listings
+type ParticipantGroup struct {
+	ID          uuid.UUID `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntityID    uuid.UUID `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	EventID     uuid.UUID `json:"event_id" db:"event_id" gorm:"type:uuid;not null;index"`
+	Name        string    `json:"name" db:"name" gorm:"size:100;not null"`
+	Description *string   `json:"description,omitempty" db:"description" gorm:"size:500"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (ParticipantGroup) TableName() string {
+	return "participant_groups"
+}
+
+// ParticipantGroupMember links a participant to a group of the same event
+type ParticipantGroupMember struct {
+	GroupID       uuid.UUID `json:"group_id" db:"group_id" gorm:"type:uuid;primaryKey"`
+	ParticipantID uuid.UUID `json:"participant_id" db:"participant_id" gorm:"type:uuid;primaryKey;index"`
+	EntityID      uuid.UUID `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+}
+
+func (ParticipantGroupMember) TableName() string {
+	return "participant_group_members"
+}
@@ -0,0 +1,82 @@
+package domain
+
+import "strings"
+
+// phoneRegion describes how national numbers of a region are written
+type phoneRegion struct {
+	countryCode     string
+	trunkPrefix     string // Dialed before the area code inside the country ("0" in BR, "1" in US)
+	nationalLengths []int  // Digits of a national number without the trunk prefix
+}
+
+// phoneRegions are the regions (ISO 3166-1 alpha-2) whose national numbers can be completed
+// with the country code
+var phoneRegions = map[string]phoneRegion{
+	"AR": {countryCode: "54", trunkPrefix: "0", nationalLengths: []int{10}},
+	"BR": {countryCode: "55", trunkPrefix: "0", nationalLengths: []int{10, 11}},
+	"CA": {countryCode: "1", trunkPrefix: "1", nationalLengths: []int{10}},
+	"CL": {countryCode: "56", nationalLengths: []int{9}},
+	"CO": {countryCode: "57", nationalLengths: []int{10}},
+	"DE": {countryCode: "49", trunkPrefix: "0", nationalLengths: []int{10, 11}},
+	"ES": {countryCode: "34", nationalLengths: []int{9}},
+	"FR": {countryCode: "33", trunkPrefix: "0", nationalLengths: []int{9}},
+	"GB": {countryCode: "44", trunkPrefix: "0", nationalLengths: []int{10}},
+	"IT": {countryCode: "39", nationalLengths: []int{9, 10}},
+	"MX": {countryCode: "52", nationalLengths: []int{10}},
+	"PE": {countryCode: "51", nationalLengths: []int{9}},
+	"PT": {countryCode: "351", nationalLengths: []int{9}},
+	"PY": {countryCode: "595", trunkPrefix: "0", nationalLengths: []int{9}},
+	"US": {countryCode: "1", trunkPrefix: "1", nationalLengths: []int{10}},
+	"UY": {countryCode: "598", trunkPrefix: "0", nationalLengths: []int{8}},
+}
+
+// IsSupportedPhoneRegion reports whether region (case-insensitive) is known to
+// NormalizePhoneNumberForRegion. Empty means no region and is always supported
+func IsSupportedPhoneRegion(region string) bool {
+	if region == "" {
+		return true
+	}
+	_, ok := phoneRegions[strings.ToUpper(region)]
+	return ok
+}
+
+// NormalizePhoneNumberForRegion is NormalizePhoneNumber for numbers that may omit the
+// country code: a national number of the region ("(11) 99999-9999" or "011 99999-9999"
+// in BR) gets the region's country code, so it matches the provider's "5511999999999".
+// Numbers starting with "+" or "00", numbers already including the country code and an
+// empty or unknown region keep only the digits
+func NormalizePhoneNumberForRegion(phone, region string) string {
+	digits := NormalizePhoneNumber(phone)
+
+	trimmed := strings.TrimSpace(phone)
+	if strings.HasPrefix(trimmed, "+") {
+		return digits
+	}
+	if strings.HasPrefix(trimmed, "00") {
+		return strings.TrimPrefix(digits, "00")
+	}
+
+	info, ok := phoneRegions[strings.ToUpper(region)]
+	if !ok {
+		return digits
+	}
+
+	national := digits
+	if info.trunkPrefix != "" && strings.HasPrefix(national, info.trunkPrefix) &&
+		info.isNationalLength(len(national)-len(info.trunkPrefix)) {
+		national = strings.TrimPrefix(national, info.trunkPrefix)
+	}
+	if !info.isNationalLength(len(national)) {
+		return digits
+	}
+	return info.countryCode + national
+}
+
+func (r phoneRegion) isNationalLength(n int) bool {
+	for _, length := range r.nationalLengths {
+		if n == length {
+			return true
+		}
+	}
+	return false
+}
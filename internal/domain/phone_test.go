@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhoneNumberForRegion(t *testing.T) {
+	tests := []struct {
+		name   string
+		phone  string
+		region string
+		want   string
+	}{
+		{name: "no region keeps digits", phone: "(11) 99999-9999", region: "", want: "11999999999"},
+		{name: "unknown region keeps digits", phone: "(11) 99999-9999", region: "ZZ", want: "11999999999"},
+		{name: "BR mobile", phone: "(11) 99999-9999", region: "BR", want: "5511999999999"},
+		{name: "BR landline", phone: "11 3333-4444", region: "BR", want: "551133334444"},
+		{name: "BR with trunk prefix", phone: "011 99999-9999", region: "BR", want: "5511999999999"},
+		{name: "lowercase region", phone: "(11) 99999-9999", region: "br", want: "5511999999999"},
+		{name: "BR already international", phone: "5511999999999", region: "BR", want: "5511999999999"},
+		{name: "US", phone: "(555) 123-4567", region: "US", want: "15551234567"},
+		{name: "US with trunk prefix", phone: "1 555 123 4567", region: "US", want: "15551234567"},
+		{name: "GB with trunk prefix", phone: "07700 900123", region: "GB", want: "447700900123"},
+		{name: "PT", phone: "912 345 678", region: "PT", want: "351912345678"},
+		{name: "plus sign is never completed", phone: "+1 555 123 4567", region: "BR", want: "15551234567"},
+		{name: "international prefix 00", phone: "00 351 912 345 678", region: "BR", want: "351912345678"},
+		{name: "too short for a national number", phone: "12345", region: "BR", want: "12345"},
+		{name: "empty", phone: "", region: "BR", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizePhoneNumberForRegion(tt.phone, tt.region))
+		})
+	}
+}
+
+func TestIsSupportedPhoneRegion(t *testing.T) {
+	assert.True(t, IsSupportedPhoneRegion(""))
+	assert.True(t, IsSupportedPhoneRegion("BR"))
+	assert.True(t, IsSupportedPhoneRegion("us"))
+	assert.False(t, IsSupportedPhoneRegion("ZZ"))
+}
@@ -14,6 +14,24 @@ const (
 	SchedulerActionReminder     SchedulerAction = "reminder"
 	SchedulerActionClosure      SchedulerAction = "closure"
 	SchedulerActionLocation     SchedulerAction = "location"
+	// Resumo de presença enviado ao organizador (criador do evento) antes do início e após o fechamento
+	SchedulerActionOrganizerSummary SchedulerAction = "organizer_summary"
+	SchedulerActionOrganizerWrapUp  SchedulerAction = "organizer_wrap_up"
+	// Broadcast do organizador aos confirmados; a mensagem e o grupo (opcional) vão no Metadata
+	SchedulerActionBroadcast SchedulerAction = "broadcast"
+)
+
+// Chaves do Metadata de um scheduler de broadcast
+const (
+	BroadcastMetadataMessage = "message"
+	BroadcastMetadataGroupID = "group_id"
+)
+
+// Chaves do Metadata com o progresso dos envios de uma task, para que o retry só reenvie
+// a quem falhou
+const (
+	SchedulerMetadataNotified     = "notified"      // Participantes já notificados pela task
+	SchedulerMetadataSendAttempts = "send_attempts" // Envios que falharam, por participante
 )
 
 // SchedulerStatus represents the status of a scheduler
@@ -21,6 +39,7 @@ type SchedulerStatus string
 
 const (
 	SchedulerStatusPending   SchedulerStatus = "pending"
+	SchedulerStatusRunning   SchedulerStatus = "running" // Reservada por um worker
 	SchedulerStatusProcessed SchedulerStatus = "processed"
 	SchedulerStatusFailed    SchedulerStatus = "failed"
 	SchedulerStatusSkipped   SchedulerStatus = "skipped"
@@ -36,6 +55,7 @@ type Scheduler struct {
 	Status       SchedulerStatus        `json:"status" db:"status" gorm:"size:50;not null;default:'pending'"`
 	ScheduledAt  time.Time              `json:"scheduled_at" db:"scheduled_at" gorm:"not null;index"`
 	ProcessedAt  *time.Time             `json:"processed_at,omitempty" db:"processed_at"`
+	ClaimedAt    *time.Time             `json:"claimed_at,omitempty" db:"claimed_at"`
 	Retries      int                    `json:"retries" db:"retries" gorm:"default:0"`
 	MaxRetries   int                    `json:"max_retries" db:"max_retries" gorm:"default:3"`
 	ErrorMessage *string                `json:"error_message,omitempty" db:"error_message" gorm:"size:500"`
@@ -48,11 +68,18 @@ func (Scheduler) TableName() string {
 	return "schedulers"
 }
 
+// DueScheduler é um scheduler pendente junto com o evento que ele dispara
+type DueScheduler struct {
+	Scheduler
+	EventName      string    `json:"event_name" db:"event_name" gorm:"column:event_name"`
+	EventStartTime time.Time `json:"event_start_time" db:"event_start_time" gorm:"column:event_start_time"`
+}
+
 // CreateSchedulerInput holds data for creating a scheduler
 type CreateSchedulerInput struct {
 	EventID     uuid.UUID              `json:"event_id" validate:"required"`
 	InstanceID  *uuid.UUID             `json:"instance_id,omitempty"`
-	Action      SchedulerAction        `json:"action" validate:"required,oneof=confirmation reminder closure location"`
+	Action      SchedulerAction        `json:"action" validate:"required,oneof=confirmation reminder closure location organizer_summary organizer_wrap_up"`
 	ScheduledAt time.Time              `json:"scheduled_at" validate:"required"`
 	MaxRetries  int                    `json:"max_retries" validate:"min=0,max=10"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
@@ -15,6 +15,18 @@ const (
 	StatusResourceScheduler   StatusResourceType = "scheduler"
 )
 
+// StatusSource identifies what triggered a status change
+type StatusSource string
+
+const (
+	StatusSourceAdmin    StatusSource = "admin"    // API request by a member or API key
+	StatusSourceWhatsApp StatusSource = "whatsapp" // Participant reply over WhatsApp
+	StatusSourceRSVP     StatusSource = "rsvp"     // Public RSVP page
+	StatusSourceSystem   StatusSource = "system"   // Worker transitions (no-show on closure)
+	// Answer lost by the provider (e.g. webhook outage) applied manually by an admin
+	StatusSourceReconcile StatusSource = "reconcile"
+)
+
 // StatusHistory tracks status changes for events, participants, etc.
 type StatusHistory struct {
 	ID           uuid.UUID          `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
@@ -24,6 +36,7 @@ type StatusHistory struct {
 	OldStatus    string             `json:"old_status" db:"old_status" gorm:"size:50"`
 	NewStatus    string             `json:"new_status" db:"new_status" gorm:"size:50;not null"`
 	ChangedBy    *uuid.UUID         `json:"changed_by,omitempty" db:"changed_by" gorm:"type:uuid"` // User or system (nil for auto)
+	Source       StatusSource       `json:"source" db:"source" gorm:"size:50;not null;default:'admin'"`
 	Reason       *string            `json:"reason,omitempty" db:"reason" gorm:"size:500"`
 	Metadata     map[string]any     `json:"metadata,omitempty" db:"metadata" gorm:"type:jsonb"`
 	CreatedAt    time.Time          `json:"created_at" db:"created_at" gorm:"autoCreateTime;index"`
@@ -17,6 +17,20 @@ const (
 	UserRoleEntityViewer  UserRole = "entity_viewer"
 )
 
+// RoleLevels ranks the roles: a role has the permissions of every role with a lower level
+var RoleLevels = map[UserRole]int{
+	UserRoleSuperAdmin:    100,
+	UserRoleEntityOwner:   50,
+	UserRoleEntityAdmin:   40,
+	UserRoleEntityManager: 30,
+	UserRoleEntityViewer:  10,
+}
+
+// Level returns the role's permission level; unknown roles have none (0)
+func (r UserRole) Level() int {
+	return RoleLevels[r]
+}
+
 // User represents a user in the system
 type User struct {
 	ID            uuid.UUID  `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookEventType represents a lifecycle event delivered to outbound webhooks
+type WebhookEventType string
+
+const (
+	WebhookEventEventCreated         WebhookEventType = "event.created"
+	WebhookEventEventActivated       WebhookEventType = "event.activated"
+	WebhookEventEventCancelled       WebhookEventType = "event.cancelled"
+	WebhookEventEventCompleted       WebhookEventType = "event.completed"
+	WebhookEventParticipantConfirmed WebhookEventType = "participant.confirmed"
+	WebhookEventParticipantCheckedIn WebhookEventType = "participant.checked_in"
+)
+
+// WebhookSubscription is an external URL that receives signed lifecycle callbacks of an entity
+type WebhookSubscription struct {
+	ID        uuid.UUID          `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntityID  uuid.UUID          `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	URL       string             `json:"url" db:"url" gorm:"size:500;not null"`
+	Secret    string             `json:"-" db:"secret" gorm:"size:128;not null"`               // Chave HMAC das assinaturas
+	Events    []WebhookEventType `json:"events" db:"events" gorm:"type:jsonb;serializer:json"` // Vazio = todos os eventos
+	Active    bool               `json:"active" db:"active" gorm:"not null;default:true"`
+	CreatedAt time.Time          `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time          `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt     `json:"-" db:"deleted_at" gorm:"index"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Subscribes reports whether the subscription wants the given event type
+func (s *WebhookSubscription) Subscribes(eventType WebhookEventType) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery logs the outcome of delivering a callback to a subscription
+type WebhookDelivery struct {
+	ID             uuid.UUID        `json:"id" db:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SubscriptionID uuid.UUID        `json:"subscription_id" db:"subscription_id" gorm:"type:uuid;not null;index"`
+	EntityID       uuid.UUID        `json:"entity_id" db:"entity_id" gorm:"type:uuid;not null;index"`
+	EventType      WebhookEventType `json:"event_type" db:"event_type" gorm:"size:50;not null"`
+	Payload        map[string]any   `json:"payload" db:"payload" gorm:"type:jsonb;serializer:json"`
+	Attempts       int              `json:"attempts" db:"attempts" gorm:"not null"`
+	StatusCode     *int             `json:"status_code,omitempty" db:"status_code"`
+	Success        bool             `json:"success" db:"success" gorm:"not null"`
+	ErrorMessage   *string          `json:"error_message,omitempty" db:"error_message" gorm:"size:500"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WhatsAppSender is the entity's own WhatsApp Cloud API number. When set, the entity's
+// notifications are sent from it instead of the global number
+type WhatsAppSender struct {
+	EntityID      uuid.UUID `json:"entity_id" db:"entity_id" gorm:"type:uuid;primaryKey"`
+	PhoneNumberID string    `json:"phone_number_id" db:"phone_number_id" gorm:"size:50;not null"`
+	// Access token sealed with the configured encryption key; never returned by the API
+	AccessToken string    `json:"-" db:"access_token" gorm:"type:text;not null"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (WhatsAppSender) TableName() string {
+	return "entity_whatsapp_senders"
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CreateAPIKeyRequest representa a criação de uma chave de API da entidade
+type CreateAPIKeyRequest struct {
+	Name string          `json:"name" validate:"required,min=2,max=100"`
+	Role domain.UserRole `json:"role" validate:"required,oneof=entity_admin entity_manager entity_viewer"`
+	// Opcional; a chave para de funcionar neste horário
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyResponse representa uma chave de API cadastrada
+type APIKeyResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	EntityID   uuid.UUID       `json:"entity_id"`
+	Name       string          `json:"name"`
+	Prefix     string          `json:"prefix"`
+	Role       domain.UserRole `json:"role"`
+	Key        string          `json:"key,omitempty"` // Só retornada na criação
+	CreatedBy  uuid.UUID       `json:"created_by"`
+	LastUsedAt *time.Time      `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	RevokedAt  *time.Time      `json:"revoked_at,omitempty"`
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"`
+}
+
+// ToAPIKeyResponse converte domain.APIKey para APIKeyResponse (sem a chave)
+func ToAPIKeyResponse(k *domain.APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         k.ID,
+		EntityID:   k.EntityID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Role:       k.Role,
+		CreatedBy:  k.CreatedBy,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+		RevokedAt:  k.RevokedAt,
+		ExpiresAt:  k.ExpiresAt,
+	}
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogResponse representa uma entrada do log de auditoria
+type AuditLogResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	EntityID    uuid.UUID  `json:"entity_id"`
+	ActorUserID *uuid.UUID `json:"actor_user_id,omitempty"`
+	// Super admin que agia como actor_user_id com um token de impersonação
+	ImpersonatorUserID *uuid.UUID             `json:"impersonator_user_id,omitempty"`
+	Action             domain.AuditAction     `json:"action"`
+	TargetType         domain.AuditTargetType `json:"target_type"`
+	TargetID           uuid.UUID              `json:"target_id"`
+	Diff               map[string]any         `json:"diff,omitempty"`
+	CreatedAt          time.Time              `json:"created_at"`
+}
+
+// ToAuditLogResponseList converte entradas do domínio para resposta
+func ToAuditLogResponseList(entries []*domain.AuditLog) []*AuditLogResponse {
+	responses := make([]*AuditLogResponse, len(entries))
+	for i, e := range entries {
+		responses[i] = &AuditLogResponse{
+			ID:                 e.ID,
+			EntityID:           e.EntityID,
+			ActorUserID:        e.ActorUserID,
+			ImpersonatorUserID: e.ImpersonatorUserID,
+			Action:             e.Action,
+			TargetType:         e.TargetType,
+			TargetID:           e.TargetID,
+			Diff:               e.Diff,
+			CreatedAt:          e.CreatedAt,
+		}
+	}
+	return responses
+}
@@ -89,8 +89,22 @@ type UserResponse struct {
 
 type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
+	// Access token do header Authorization (opcional), denylisted até expirar
+	AccessTokenID        string    `json:"-"`
+	AccessTokenExpiresAt time.Time `json:"-"`
 }
 
 type LogoutResponse struct {
 	Message string `json:"message"`
 }
+
+// ==================== IMPERSONATE ====================
+
+// ImpersonateResponse é o access token de curta duração com que um super admin age como
+// outro usuário. Não há refresh token: ao expirar, é preciso impersonar de novo
+type ImpersonateResponse struct {
+	AccessToken    string `json:"access_token"`
+	ExpiresIn      int64  `json:"expires_in"` // segundos até expirar
+	UserID         string `json:"user_id"`
+	ImpersonatorID string `json:"impersonator_id"`
+}
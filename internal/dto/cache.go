@@ -47,5 +47,6 @@ type EventCacheResponse struct {
 	TotalConfirmed int                           `json:"total_confirmed"`
 	TotalPending   int                           `json:"total_pending"`
 	TotalDenied    int                           `json:"total_denied"`
+	TotalNoShow    int                           `json:"total_no_show"`
 	FetchedAt      time.Time                     `json:"fetched_at"`
 }
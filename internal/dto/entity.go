@@ -19,6 +19,21 @@ type CreateEntityRequest struct {
 	PhoneNumber *string                `json:"phone_number,omitempty" validate:"omitempty,max=20"`
 	Document    *string                `json:"document,omitempty" validate:"omitempty,max=50"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	// Antecedência padrão (minutos antes do início) dos schedulers dos eventos da entidade
+	ConfirmationOffsetMinutes *int `json:"confirmation_offset_minutes,omitempty" validate:"omitempty,min=1,max=43200"`
+	ReminderOffsetMinutes     *int `json:"reminder_offset_minutes,omitempty" validate:"omitempty,min=1,max=43200"`
+	LocationOffsetMinutes     *int `json:"location_offset_minutes,omitempty" validate:"omitempty,min=1,max=43200"`
+	// Janela de silêncio das notificações ("HH:MM", no fuso do evento)
+	QuietHoursStart *string `json:"quiet_hours_start,omitempty" validate:"omitempty,datetime=15:04"`
+	QuietHoursEnd   *string `json:"quiet_hours_end,omitempty" validate:"omitempty,datetime=15:04"`
+	// Tipos de evento personalizados aceitos além de demand e periodic
+	EventTypes domain.EventTypes `json:"event_types,omitempty" validate:"omitempty,max=50,dive,min=2,max=50"`
+	// Junta os lembretes de eventos no mesmo dia em uma única mensagem por participante
+	ReminderDigest *bool `json:"reminder_digest,omitempty"`
+	// Completa automaticamente os eventos que ficaram active depois do fim
+	AutoCompleteEvents *bool `json:"auto_complete_events,omitempty"`
+	// Região (ISO 3166-1, ex.: "BR") dos telefones informados sem o código do país
+	PhoneRegion *string `json:"phone_region,omitempty"`
 }
 
 // ==================== UPDATE ====================
@@ -33,25 +48,49 @@ type UpdateEntityRequest struct {
 	Document    *string                `json:"document,omitempty" validate:"omitempty,max=50"`
 	IsActive    *bool                  `json:"is_active,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	// Antecedência padrão (minutos antes do início) dos schedulers dos eventos da entidade
+	ConfirmationOffsetMinutes *int `json:"confirmation_offset_minutes,omitempty" validate:"omitempty,min=1,max=43200"`
+	ReminderOffsetMinutes     *int `json:"reminder_offset_minutes,omitempty" validate:"omitempty,min=1,max=43200"`
+	LocationOffsetMinutes     *int `json:"location_offset_minutes,omitempty" validate:"omitempty,min=1,max=43200"`
+	// Janela de silêncio das notificações ("HH:MM", no fuso do evento); "" remove
+	QuietHoursStart *string `json:"quiet_hours_start,omitempty" validate:"omitempty,datetime=15:04"`
+	QuietHoursEnd   *string `json:"quiet_hours_end,omitempty" validate:"omitempty,datetime=15:04"`
+	// Tipos de evento personalizados aceitos além de demand e periodic; [] remove todos
+	EventTypes domain.EventTypes `json:"event_types,omitempty" validate:"omitempty,max=50,dive,min=2,max=50"`
+	// Junta os lembretes de eventos no mesmo dia em uma única mensagem por participante
+	ReminderDigest *bool `json:"reminder_digest,omitempty"`
+	// Completa automaticamente os eventos que ficaram active depois do fim
+	AutoCompleteEvents *bool `json:"auto_complete_events,omitempty"`
+	// Região (ISO 3166-1, ex.: "BR") dos telefones informados sem o código do país; "" volta ao padrão da aplicação
+	PhoneRegion *string `json:"phone_region,omitempty"`
 }
 
 // ==================== RESPONSE ====================
 
 // EntityResponse representa a resposta com dados da entidade
 type EntityResponse struct {
-	ID               uuid.UUID               `json:"id"`
-	ParentID         *uuid.UUID              `json:"parent_id,omitempty"`
-	Type             domain.EntityType       `json:"type"`
-	Name             string                  `json:"name"`
-	Email            *string                 `json:"email,omitempty"`
-	PhoneNumber      *string                 `json:"phone_number,omitempty"`
-	Document         *string                 `json:"document,omitempty"`
-	IsActive         bool                    `json:"is_active"`
-	EntityPermission domain.EntityPermission `json:"entity_permission"`
-	Metadata         map[string]interface{}  `json:"metadata,omitempty"`
-	CreatedAt        time.Time               `json:"created_at"`
-	UpdatedAt        time.Time               `json:"updated_at"`
-	Children         []*EntityResponse       `json:"children,omitempty"`
+	ID                        uuid.UUID               `json:"id"`
+	ParentID                  *uuid.UUID              `json:"parent_id,omitempty"`
+	Type                      domain.EntityType       `json:"type"`
+	Name                      string                  `json:"name"`
+	Email                     *string                 `json:"email,omitempty"`
+	PhoneNumber               *string                 `json:"phone_number,omitempty"`
+	Document                  *string                 `json:"document,omitempty"`
+	IsActive                  bool                    `json:"is_active"`
+	EntityPermission          domain.EntityPermission `json:"entity_permission"`
+	Metadata                  map[string]interface{}  `json:"metadata,omitempty"`
+	ConfirmationOffsetMinutes *int                    `json:"confirmation_offset_minutes,omitempty"`
+	ReminderOffsetMinutes     *int                    `json:"reminder_offset_minutes,omitempty"`
+	LocationOffsetMinutes     *int                    `json:"location_offset_minutes,omitempty"`
+	QuietHoursStart           *string                 `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd             *string                 `json:"quiet_hours_end,omitempty"`
+	EventTypes                domain.EventTypes       `json:"event_types,omitempty"`
+	ReminderDigest            bool                    `json:"reminder_digest"`
+	AutoCompleteEvents        bool                    `json:"auto_complete_events"`
+	PhoneRegion               *string                 `json:"phone_region,omitempty"`
+	CreatedAt                 time.Time               `json:"created_at"`
+	UpdatedAt                 time.Time               `json:"updated_at"`
+	Children                  []*EntityResponse       `json:"children,omitempty"`
 }
 
 // ToEntityResponse converte domain.Entity para EntityResponse
@@ -61,18 +100,27 @@ func ToEntityResponse(e *domain.Entity) *EntityResponse {
 	}
 
 	resp := &EntityResponse{
-		ID:               e.ID,
-		ParentID:         e.ParentID,
-		Type:             e.Type,
-		Name:             e.Name,
-		Email:            e.Email,
-		PhoneNumber:      e.PhoneNumber,
-		Document:         e.Document,
-		IsActive:         e.Active,
-		EntityPermission: e.EntityPermission,
-		Metadata:         e.Metadata,
-		CreatedAt:        e.CreatedAt,
-		UpdatedAt:        e.UpdatedAt,
+		ID:                        e.ID,
+		ParentID:                  e.ParentID,
+		Type:                      e.Type,
+		Name:                      e.Name,
+		Email:                     e.Email,
+		PhoneNumber:               e.PhoneNumber,
+		Document:                  e.Document,
+		IsActive:                  e.Active,
+		EntityPermission:          e.EntityPermission,
+		Metadata:                  e.Metadata,
+		ConfirmationOffsetMinutes: e.ConfirmationOffsetMinutes,
+		ReminderOffsetMinutes:     e.ReminderOffsetMinutes,
+		LocationOffsetMinutes:     e.LocationOffsetMinutes,
+		QuietHoursStart:           e.QuietHoursStart,
+		QuietHoursEnd:             e.QuietHoursEnd,
+		EventTypes:                e.EventTypes,
+		ReminderDigest:            e.ReminderDigest,
+		AutoCompleteEvents:        e.AutoCompleteEvents,
+		PhoneRegion:               e.PhoneRegion,
+		CreatedAt:                 e.CreatedAt,
+		UpdatedAt:                 e.UpdatedAt,
 	}
 
 	// Converter children se existirem
@@ -95,3 +143,54 @@ func ToEntityResponseList(entities []*domain.Entity) []*EntityResponse {
 	}
 	return responses
 }
+
+// EntityDescendantResponse representa uma entidade da subárvore com sua profundidade
+type EntityDescendantResponse struct {
+	*EntityResponse
+	Depth int `json:"depth"`
+}
+
+// ToEntityDescendantResponseList converte descendentes do domínio para resposta
+func ToEntityDescendantResponseList(descendants []*domain.EntityDescendant) []*EntityDescendantResponse {
+	responses := make([]*EntityDescendantResponse, len(descendants))
+	for i, d := range descendants {
+		responses[i] = &EntityDescendantResponse{
+			EntityResponse: ToEntityResponse(&d.Entity),
+			Depth:          d.Depth,
+		}
+	}
+	return responses
+}
+
+// ==================== MEMBERS ====================
+
+// AddMemberRequest representa o request para adicionar um usuário à entidade
+type AddMemberRequest struct {
+	UserID uuid.UUID       `json:"user_id" validate:"required"`
+	Role   domain.UserRole `json:"role" validate:"required,oneof=entity_owner entity_admin entity_manager entity_viewer"`
+}
+
+// UpdateMemberRoleRequest representa o request para alterar o papel de um membro
+type UpdateMemberRoleRequest struct {
+	Role domain.UserRole `json:"role" validate:"required,oneof=entity_owner entity_admin entity_manager entity_viewer"`
+}
+
+// MemberResponse representa um membro da entidade
+type MemberResponse struct {
+	UserID    uuid.UUID       `json:"user_id"`
+	EntityID  uuid.UUID       `json:"entity_id"`
+	Role      domain.UserRole `json:"role"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ToMemberResponse converte domain.UserEntity para MemberResponse
+func ToMemberResponse(m *domain.UserEntity) *MemberResponse {
+	return &MemberResponse{
+		UserID:    m.UserID,
+		EntityID:  m.EntityID,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
@@ -1,9 +1,11 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 
 	"event-coming/internal/domain"
+	"event-coming/pkg/timefmt"
 
 	"github.com/google/uuid"
 )
@@ -24,40 +26,97 @@ type SchedulerConfig struct {
 	ConfirmationTime     *time.Time `json:"confirmation_time"`
 	SendReminder         bool       `json:"send_reminder"`
 	ReminderTime         *time.Time `json:"reminder_time"`
-	ReminderBeforeHours  *int       `json:"reminder_before_hours"`
+	ReminderBeforeHours  *int       `json:"reminder_before_hours" validate:"omitempty,min=1"`
 	TrackLocation        bool       `json:"track_location"`
 	LocationTrackingTime *time.Time `json:"location_tracking_time"`
+	// Envia ao criador do evento um resumo de presença antes do início e outro após o fechamento
+	NotifyOrganizer             bool `json:"notify_organizer"`
+	OrganizerSummaryBeforeHours *int `json:"organizer_summary_before_hours" validate:"omitempty,min=1"`
 }
 
 // CreateEventRequest representa o request de criação de evento
 type CreateEventRequest struct {
 	Name                 string             `json:"name" validate:"required,min=3,max=200"`
 	Description          *string            `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Type                 domain.EventType   `json:"type" validate:"required,oneof=demand periodic"`
-	LocationLat          float64            `json:"location_lat" validate:"required"`
-	LocationLng          float64            `json:"location_lng" validate:"required"`
+	Type                 domain.EventType   `json:"type" validate:"required,max=50"`                                        // demand, periodic ou um tipo configurado na entidade
+	LocationLat          float64            `json:"location_lat" validate:"required_without_all=LocationAddress Locations"` // Sem coordenadas, vêm do geocoding do endereço
+	LocationLng          float64            `json:"location_lng" validate:"required_without_all=LocationAddress Locations"`
 	LocationAddress      *string            `json:"location_address,omitempty" validate:"omitempty,max=500"`
 	StartTime            time.Time          `json:"start_time" validate:"required"`
 	EndTime              *time.Time         `json:"end_time,omitempty"`
+	Timezone             string             `json:"timezone,omitempty" validate:"omitempty,timezone"`     // IANA (ex.: America/Sao_Paulo)
+	Locale               string             `json:"locale,omitempty" validate:"omitempty,oneof=pt en es"` // Idioma das datas formatadas
 	RRuleString          *string            `json:"rrule_string,omitempty" validate:"omitempty,max=500"`
 	ConfirmationDeadline *time.Time         `json:"confirmation_deadline,omitempty"`
 	Participants         []ParticipantInput `json:"participants,omitempty" validate:"omitempty,max=100,dive"`
 	Scheduler            *SchedulerConfig   `json:"scheduler,omitempty"`
+	// Opções de resposta (lista/botões do WhatsApp) indexadas pelo id da opção; o status
+	// de uma opção só pode ser confirmed ou denied, e ids repetidos são rejeitados
+	ResponseOptions domain.ResponseOptions `json:"response_options,omitempty"`
+	// Marca confirmados sem check-in como no_show quando o evento é encerrado
+	MarkNoShows bool `json:"mark_no_shows"`
+	// Liga/desliga rastreamento, notificações e RSVP público; campos omitidos ficam habilitados
+	Features domain.EventFeatures `json:"features"`
+	// Check-in aceito de tantos minutos antes do início até tantos depois do fim (padrão: 24h)
+	CheckInWindowBeforeMinutes *int `json:"check_in_window_before_minutes,omitempty" validate:"omitempty,min=0,max=43200"`
+	CheckInWindowAfterMinutes  *int `json:"check_in_window_after_minutes,omitempty" validate:"omitempty,min=0,max=43200"`
+	// Locais do evento em ordem (ponto de encontro, cerimônia...); o primeiro substitui
+	// location_lat/location_lng/location_address
+	Locations []EventLocationRequest `json:"locations,omitempty" validate:"omitempty,max=20,dive"`
+	// Máximo de participantes confirmados (check-in incluído); sem ele não há limite
+	Capacity *int `json:"capacity,omitempty" validate:"omitempty,min=1"`
+	// Cria mesmo que já exista evento com mesmo nome e início próximo
+	AllowDuplicate bool `json:"allow_duplicate"`
 }
 
 // ==================== UPDATE ====================
 
 // UpdateEventRequest representa o request de atualização
 type UpdateEventRequest struct {
-	Name                 *string             `json:"name,omitempty" validate:"omitempty,min=3,max=200"`
-	Description          *string             `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Status               *domain.EventStatus `json:"status,omitempty"`
-	LocationLat          *float64            `json:"location_lat,omitempty"`
-	LocationLng          *float64            `json:"location_lng,omitempty"`
-	LocationAddress      *string             `json:"location_address,omitempty" validate:"omitempty,max=500"`
-	StartTime            *time.Time          `json:"start_time,omitempty"`
-	EndTime              *time.Time          `json:"end_time,omitempty"`
-	ConfirmationDeadline *time.Time          `json:"confirmation_deadline,omitempty"`
+	Name                 *string                `json:"name,omitempty" validate:"omitempty,min=3,max=200"`
+	Description          *string                `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Status               *domain.EventStatus    `json:"status,omitempty"`
+	LocationLat          *float64               `json:"location_lat,omitempty"`
+	LocationLng          *float64               `json:"location_lng,omitempty"`
+	LocationAddress      *string                `json:"location_address,omitempty" validate:"omitempty,max=500"`
+	StartTime            *time.Time             `json:"start_time,omitempty"`
+	EndTime              *time.Time             `json:"end_time,omitempty"`
+	Timezone             *string                `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Locale               *string                `json:"locale,omitempty" validate:"omitempty,oneof=pt en es"`
+	ConfirmationDeadline *time.Time             `json:"confirmation_deadline,omitempty"`
+	ResponseOptions      domain.ResponseOptions `json:"response_options,omitempty"`
+	MarkNoShows          *bool                  `json:"mark_no_shows,omitempty"`
+	Features             *domain.EventFeatures  `json:"features,omitempty"` // Só os campos informados mudam
+	// null volta para a janela de check-in padrão
+	CheckInWindowBeforeMinutes *int `json:"check_in_window_before_minutes,omitempty" validate:"omitempty,min=0,max=43200"`
+	CheckInWindowAfterMinutes  *int `json:"check_in_window_after_minutes,omitempty" validate:"omitempty,min=0,max=43200"`
+	// null remove o limite; abaixo dos já confirmados, só bloqueia novas confirmações
+	Capacity *int `json:"capacity,omitempty" validate:"omitempty,min=1"`
+	// Versão esperada (concorrência otimista); também aceita via header If-Match
+	Version *int `json:"version,omitempty"`
+
+	nulls map[string]bool // Campos enviados como null
+}
+
+// UnmarshalJSON decodifica o request guardando os campos enviados como null, para que
+// description, location_address, end_time e confirmation_deadline possam ser removidos
+func (r *UpdateEventRequest) UnmarshalJSON(data []byte) error {
+	type plain UpdateEventRequest
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+
+	nulls, err := nullFields(data)
+	if err != nil {
+		return err
+	}
+	r.nulls = nulls
+	return nil
+}
+
+// IsNull retorna true se o campo foi enviado explicitamente como null
+func (r *UpdateEventRequest) IsNull(field string) bool {
+	return r.nulls[field]
 }
 
 // ==================== RESPONSE ====================
@@ -74,18 +133,84 @@ type EventResponse struct {
 	LocationLng          float64                `json:"location_lng"`
 	LocationAddress      *string                `json:"location_address,omitempty"`
 	StartTime            time.Time              `json:"start_time"`
+	StartTimeLocal       string                 `json:"start_time_local"` // Início no fuso e idioma do evento, ex.: "sáb, 14/06 às 14:00 (-03)"
 	EndTime              *time.Time             `json:"end_time,omitempty"`
+	Timezone             string                 `json:"timezone"`
+	Locale               string                 `json:"locale"`
 	RRuleString          *string                `json:"rrule_string,omitempty"`
+	ExDates              domain.ExDates         `json:"ex_dates,omitempty"`
 	ConfirmationDeadline *time.Time             `json:"confirmation_deadline,omitempty"`
+	ResponseOptions      domain.ResponseOptions `json:"response_options,omitempty"`
+	MarkNoShows          bool                   `json:"mark_no_shows"`
+	Features             domain.EventFeatures   `json:"features"`
 	CreatedBy            uuid.UUID              `json:"created_by"`
 	CreatedAt            time.Time              `json:"created_at"`
 	UpdatedAt            time.Time              `json:"updated_at"`
-	Participants         []*ParticipantResponse `json:"participants,omitempty"`
-	SchedulersCreated    int                    `json:"schedulers_created,omitempty"`
+	Version              int                    `json:"version"`
+	RedactedAt           *time.Time             `json:"participants_redacted_at,omitempty"` // Dados pessoais dos participantes apagados
+	ImportUID            *string                `json:"import_uid,omitempty"`               // UID do VEVENT de origem, para eventos importados de ICS
+	// Janela de check-in efetiva, em minutos antes do início e depois do fim
+	CheckInWindowBeforeMinutes int                      `json:"check_in_window_before_minutes"`
+	CheckInWindowAfterMinutes  int                      `json:"check_in_window_after_minutes"`
+	Locations                  []*EventLocationResponse `json:"locations,omitempty"`
+	TargetLocationID           *uuid.UUID               `json:"target_location_id,omitempty"` // Local usado por geofence e ETA; sem ele, o principal
+	Capacity                   *int                     `json:"capacity,omitempty"`           // Máximo de confirmados; sem ele não há limite
+	Participants               []*ParticipantResponse   `json:"participants,omitempty"`
+	SchedulersCreated          int                      `json:"schedulers_created,omitempty"`
+}
+
+// AttendeeEventResponse representa um evento visto por quem participa dele
+type AttendeeEventResponse struct {
+	*EventResponse
+	ParticipantID     uuid.UUID                `json:"participant_id"`
+	ParticipantStatus domain.ParticipantStatus `json:"participant_status"`
+}
+
+// EventDetailResponse reúne numa resposta o que o app carrega ao abrir um evento: o
+// evento, a primeira página de participantes, os números por status e quantos
+// participantes estão compartilhando a localização agora
+type EventDetailResponse struct {
+	Event             *EventResponse         `json:"event"`
+	Participants      []*ParticipantResponse `json:"participants"`
+	ParticipantsTotal int64                  `json:"participants_total"`
+	Stats             domain.EventStats      `json:"stats"`
+	LiveLocations     int                    `json:"live_locations"`
+}
+
+// ParticipantRedactionResponse resume a remoção dos dados pessoais dos participantes de
+// um evento
+type ParticipantRedactionResponse struct {
+	EventID      uuid.UUID `json:"event_id"`
+	Participants int64     `json:"participants"` // Participantes com dados apagados nesta execução
+	Locations    int64     `json:"locations"`    // Localizações apagadas
+	Deliveries   int64     `json:"deliveries"`   // Notificações com o telefone do destinatário apagado
+	AuditLogs    int64     `json:"audit_logs"`   // Entradas do audit log dos participantes com o diff apagado
+	RedactedAt   time.Time `json:"redacted_at"`
+}
+
+// EventImportResponse resume a importação de um arquivo ICS
+type EventImportResponse struct {
+	Imported int              `json:"imported"`
+	Skipped  int              `json:"skipped"` // VEVENTs cujo UID já foi importado, ou exceções de uma ocorrência (RECURRENCE-ID)
+	Events   []*EventResponse `json:"events"`  // Eventos criados, em rascunho
+	// Eventos criados com dados diferentes do arquivo (ex.: fuso desconhecido trocado pelo padrão)
+	Adjusted []*EventImportAdjustment `json:"adjusted"`
+}
+
+// EventImportAdjustment descreve o que foi alterado para importar um VEVENT
+type EventImportAdjustment struct {
+	UID    string `json:"uid"`
+	Reason string `json:"reason"`
+}
+
+// FormatEventTime formata t no fuso e idioma do evento
+func FormatEventTime(e *domain.Event, t time.Time) string {
+	return timefmt.Format(t, timefmt.LoadLocation(e.Timezone), e.Locale)
 }
 
 // ToEventResponse converte domain.Event para EventResponse
 func ToEventResponse(e *domain.Event) *EventResponse {
+	checkInBefore, checkInAfter := e.CheckInWindow()
 	return &EventResponse{
 		ID:                   e.ID,
 		EntityID:             e.EntityID,
@@ -97,11 +222,113 @@ func ToEventResponse(e *domain.Event) *EventResponse {
 		LocationLng:          e.LocationLng,
 		LocationAddress:      e.LocationAddress,
 		StartTime:            e.StartTime,
+		StartTimeLocal:       FormatEventTime(e, e.StartTime),
 		EndTime:              e.EndTime,
+		Timezone:             e.Timezone,
+		Locale:               e.Locale,
 		RRuleString:          e.RRuleString,
+		ExDates:              e.ExDates,
 		ConfirmationDeadline: e.ConfirmationDeadline,
+		ResponseOptions:      e.ResponseOptions,
+		MarkNoShows:          e.MarkNoShows,
+		Features:             e.Features.Resolved(),
 		CreatedBy:            e.CreatedBy,
 		CreatedAt:            e.CreatedAt,
 		UpdatedAt:            e.UpdatedAt,
+		Version:              e.Version,
+		RedactedAt:           e.ParticipantsRedactedAt,
+		ImportUID:            e.ImportUID,
+
+		CheckInWindowBeforeMinutes: int(checkInBefore / time.Minute),
+		CheckInWindowAfterMinutes:  int(checkInAfter / time.Minute),
+		Locations:                  ToEventLocationResponses(e),
+		TargetLocationID:           e.TargetLocationID,
+		Capacity:                   e.Capacity,
+	}
+}
+
+// ScheduledTaskPreview representa um scheduler que seria criado para o evento
+type ScheduledTaskPreview struct {
+	Action      domain.SchedulerAction `json:"action"`
+	ScheduledAt time.Time              `json:"scheduled_at"`
+	InPast      bool                   `json:"in_past"`    // Horário calculado já passou
+	FiredLate   bool                   `json:"fired_late"` // Seria disparado uma vez, imediatamente (política fire_once)
+	Skipped     bool                   `json:"skipped"`    // Não seria criado (ver SkipReason)
+	SkipReason  string                 `json:"skip_reason,omitempty"`
+}
+
+// AttachmentResponse representa um anexo de evento na resposta
+type AttachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	EventID     uuid.UUID `json:"event_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ToAttachmentResponse converte domain.Attachment para AttachmentResponse
+func ToAttachmentResponse(a *domain.Attachment) *AttachmentResponse {
+	return &AttachmentResponse{
+		ID:          a.ID,
+		EventID:     a.EventID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		UploadedBy:  a.UploadedBy,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// GenerateInstancesRequest representa a geração das ocorrências de um evento recorrente
+type GenerateInstancesRequest struct {
+	Until time.Time `json:"until" validate:"required"`
+}
+
+// CancelInstanceRequest identifica a ocorrência a cancelar pelo seu início original
+type CancelInstanceRequest struct {
+	Occurrence time.Time `json:"occurrence" validate:"required"`
+}
+
+// OverrideInstanceRequest representa a edição de uma única ocorrência; campos omitidos
+// mantêm o valor atual da ocorrência
+type OverrideInstanceRequest struct {
+	Occurrence      time.Time           `json:"occurrence" validate:"required"`
+	StartTime       *time.Time          `json:"start_time,omitempty"`
+	EndTime         *time.Time          `json:"end_time,omitempty"`
+	LocationLat     *float64            `json:"location_lat,omitempty" validate:"omitempty,latitude"`
+	LocationLng     *float64            `json:"location_lng,omitempty" validate:"omitempty,longitude"`
+	LocationAddress *string             `json:"location_address,omitempty" validate:"omitempty,max=500"`
+	Status          *domain.EventStatus `json:"status,omitempty" validate:"omitempty,oneof=scheduled active completed"`
+}
+
+// EventInstanceResponse representa uma ocorrência de evento recorrente
+type EventInstanceResponse struct {
+	ID              uuid.UUID          `json:"id"`
+	EventID         uuid.UUID          `json:"event_id"`
+	Occurrence      time.Time          `json:"occurrence"`
+	Status          domain.EventStatus `json:"status"`
+	StartTime       time.Time          `json:"start_time"`
+	EndTime         *time.Time         `json:"end_time,omitempty"`
+	LocationLat     *float64           `json:"location_lat,omitempty"`
+	LocationLng     *float64           `json:"location_lng,omitempty"`
+	LocationAddress *string            `json:"location_address,omitempty"`
+	Overridden      bool               `json:"overridden"`
+}
+
+// ToEventInstanceResponse converte domain.EventInstance para EventInstanceResponse
+func ToEventInstanceResponse(i *domain.EventInstance) *EventInstanceResponse {
+	return &EventInstanceResponse{
+		ID:              i.ID,
+		EventID:         i.EventID,
+		Occurrence:      i.InstanceDate,
+		Status:          i.Status,
+		StartTime:       i.StartTime,
+		EndTime:         i.EndTime,
+		LocationLat:     i.LocationLat,
+		LocationLng:     i.LocationLng,
+		LocationAddress: i.LocationAddress,
+		Overridden:      i.Overridden,
 	}
 }
@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// EventLocationRequest representa um local do evento; sem coordenadas, vêm do geocoding
+// do endereço
+type EventLocationRequest struct {
+	Label   string   `json:"label" validate:"required,max=100"`
+	Lat     *float64 `json:"lat,omitempty" validate:"required_without=Address,omitempty,latitude"`
+	Lng     *float64 `json:"lng,omitempty" validate:"required_without=Address,omitempty,longitude"`
+	Address *string  `json:"address,omitempty" validate:"omitempty,max=500"`
+}
+
+// AddEventLocationRequest adiciona um local ao evento, no fim da lista ou na posição
+// informada (0 torna o local o principal)
+type AddEventLocationRequest struct {
+	EventLocationRequest
+	Position *int `json:"position,omitempty" validate:"omitempty,min=0"`
+}
+
+// UpdateEventLocationRequest altera um local do evento; só os campos informados mudam
+type UpdateEventLocationRequest struct {
+	Label    *string  `json:"label,omitempty" validate:"omitempty,min=1,max=100"`
+	Lat      *float64 `json:"lat,omitempty" validate:"omitempty,latitude"`
+	Lng      *float64 `json:"lng,omitempty" validate:"omitempty,longitude"`
+	Address  *string  `json:"address,omitempty" validate:"omitempty,max=500"`
+	Position *int     `json:"position,omitempty" validate:"omitempty,min=0"` // Move o local na lista
+}
+
+// SetTargetLocationRequest escolhe o local usado por geofence e ETA; null volta para o
+// local principal
+type SetTargetLocationRequest struct {
+	LocationID *uuid.UUID `json:"location_id"`
+}
+
+// EventLocationResponse representa um local do evento
+type EventLocationResponse struct {
+	ID      uuid.UUID `json:"id"`
+	Label   string    `json:"label"`
+	Lat     float64   `json:"lat"`
+	Lng     float64   `json:"lng"`
+	Address *string   `json:"address,omitempty"`
+	Primary bool      `json:"primary"` // Espelhado em location_lat/location_lng/location_address do evento
+	Target  bool      `json:"target"`  // Usado por geofence e ETA
+}
+
+// ToEventLocationResponses converte os locais do evento, marcando o principal e o alvo
+func ToEventLocationResponses(e *domain.Event) []*EventLocationResponse {
+	if len(e.Locations) == 0 {
+		return []*EventLocationResponse{}
+	}
+
+	responses := make([]*EventLocationResponse, len(e.Locations))
+	for i, l := range e.Locations {
+		responses[i] = &EventLocationResponse{
+			ID:      l.ID,
+			Label:   l.Label,
+			Lat:     l.Lat,
+			Lng:     l.Lng,
+			Address: l.Address,
+			Primary: i == 0,
+		}
+	}
+
+	target := 0
+	if e.TargetLocationID != nil {
+		for i, l := range e.Locations {
+			if l.ID == *e.TargetLocationID {
+				target = i
+			}
+		}
+	}
+	responses[target].Target = true
+	return responses
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// AddEventOrganizerRequest adiciona um membro da entidade como co-organizador do evento
+type AddEventOrganizerRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+// EventOrganizerResponse representa um co-organizador do evento
+type EventOrganizerResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	AddedBy   uuid.UUID `json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ToEventOrganizerResponse converte domain.EventOrganizer para EventOrganizerResponse
+func ToEventOrganizerResponse(o *domain.EventOrganizer) *EventOrganizerResponse {
+	resp := &EventOrganizerResponse{
+		UserID:    o.UserID,
+		AddedBy:   o.AddedBy,
+		CreatedAt: o.CreatedAt,
+	}
+	if o.User != nil {
+		resp.Name = o.User.Name
+		resp.Email = o.User.Email
+	}
+	return resp
+}
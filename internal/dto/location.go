@@ -22,6 +22,21 @@ type CreateLocationRequest struct {
 	Timestamp *time.Time `json:"timestamp,omitempty"`
 }
 
+// BatchCreateLocationsRequest representa o envio de pontos acumulados offline pelo app
+type BatchCreateLocationsRequest struct {
+	Locations []CreateLocationRequest `json:"locations" binding:"required,min=1,dive"` // Máximo em config.LocationConfig
+}
+
+// BatchCreateLocationsResponse resume o envio em lote: os pontos gravados, em ordem de
+// timestamp, e os erros dos rejeitados pelo índice no request
+type BatchCreateLocationsResponse struct {
+	Accepted  int                 `json:"accepted"`
+	Throttled int                 `json:"throttled"`
+	Rejected  int                 `json:"rejected"`
+	Locations []*LocationResponse `json:"locations"`
+	Errors    []string            `json:"errors,omitempty"`
+}
+
 // ==================== RESPONSE ====================
 
 // LocationResponse representa a resposta com dados de localização
@@ -38,6 +53,13 @@ type LocationResponse struct {
 	Heading       *float64  `json:"heading,omitempty"`
 	Timestamp     time.Time `json:"timestamp"`
 	CreatedAt     time.Time `json:"created_at"`
+	// Idade da posição e se passou do limite de "stale"; só nas visões ao vivo do evento
+	StaleSeconds *int64 `json:"stale_seconds,omitempty"`
+	IsStale      *bool  `json:"is_stale,omitempty"`
+	// Atualização descartada por chegar cedo demais após a última aceita; não foi salva
+	Throttled bool `json:"throttled,omitempty"`
+	// Distância em linha reta até o evento; só na visão do evento ordenada por distância
+	DistanceMeters *float64 `json:"distance_meters,omitempty"`
 }
 
 // ToLocationResponse converte domain.Location para LocationResponse
@@ -61,6 +83,18 @@ func ToLocationResponse(loc *domain.Location) *LocationResponse {
 	}
 }
 
+// ToLocationResponseWithStaleness converte a location incluindo StaleSeconds/IsStale
+func ToLocationResponseWithStaleness(loc *domain.Location, now time.Time, staleAfter time.Duration) *LocationResponse {
+	resp := ToLocationResponse(loc)
+	if resp == nil {
+		return nil
+	}
+	staleSeconds, isStale := loc.Staleness(now, staleAfter)
+	resp.StaleSeconds = &staleSeconds
+	resp.IsStale = &isStale
+	return resp
+}
+
 // ToLocationResponseList converte lista de locations
 func ToLocationResponseList(locations []*domain.Location) []*LocationResponse {
 	responses := make([]*LocationResponse, len(locations))
@@ -121,3 +155,34 @@ func ToEventETAResponse(eventID, entityID uuid.UUID, results []*eta.ETAResult) *
 		FetchedAt:    time.Now(),
 	}
 }
+
+// ==================== LIVE SNAPSHOT ====================
+
+// Origem da localização no snapshot ao vivo
+const (
+	LocationSourceCache    = "cache"
+	LocationSourceDatabase = "database"
+)
+
+// LocationSortDistance ordena as visões de localização do evento do participante mais
+// próximo ao mais distante (?sort=distance); sem sort, a ordem é a de cadastro
+const LocationSortDistance = "distance"
+
+// LiveParticipantLocation representa a última posição conhecida de um participante
+type LiveParticipantLocation struct {
+	ParticipantID  uuid.UUID                `json:"participant_id"`
+	Status         domain.ParticipantStatus `json:"status"`
+	Location       *LocationResponse        `json:"location,omitempty"`
+	Source         string                   `json:"source,omitempty"`
+	DistanceMeters *float64                 `json:"distance_meters,omitempty"`
+	ETAMinutes     *int                     `json:"eta_minutes,omitempty"`
+}
+
+// LiveEventLocationsResponse representa o snapshot ao vivo das localizações de um evento
+type LiveEventLocationsResponse struct {
+	EventID      uuid.UUID                  `json:"event_id"`
+	EntityID     uuid.UUID                  `json:"entity_id"`
+	Participants []*LiveParticipantLocation `json:"participants"`
+	TotalTracked int                        `json:"total_tracked"`
+	FetchedAt    time.Time                  `json:"fetched_at"`
+}
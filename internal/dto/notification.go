@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BroadcastPreviewRequest é a mensagem que o organizador quer enviar aos confirmados,
+// opcionalmente só aos de um grupo
+type BroadcastPreviewRequest struct {
+	Message string     `json:"message" validate:"required,max=4000"`
+	GroupID *uuid.UUID `json:"group_id,omitempty"`
+}
+
+// BroadcastPreviewResponse mostra como o broadcast sairia, sem enviar nada
+type BroadcastPreviewResponse struct {
+	Locale      string            `json:"locale"`             // Idioma do evento, usado no envio
+	GroupID     *uuid.UUID        `json:"group_id,omitempty"` // Grupo alvo; ausente quando vai para todos os confirmados
+	Messages    map[string]string `json:"messages"`           // Texto renderizado por idioma (pt, en, es)
+	Confirmed   int               `json:"confirmed"`          // Participantes confirmados (do grupo, se informado)
+	Recipients  int               `json:"recipients"`         // Confirmados com telefone válido
+	Unreachable int               `json:"unreachable"`        // Confirmados sem telefone válido
+}
+
+// ScheduleBroadcastRequest agenda o envio de um broadcast aos confirmados (ou aos confirmados
+// de um grupo). Sem scheduled_at, ou com uma data passada, sai no próximo ciclo do worker
+type ScheduleBroadcastRequest struct {
+	Message     string     `json:"message" validate:"required,max=4000"`
+	GroupID     *uuid.UUID `json:"group_id,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 
 	"event-coming/internal/domain"
@@ -17,11 +18,13 @@ type CreateParticipantRequest struct {
 	Email       *string                `json:"email,omitempty" validate:"omitempty,email"`
 	InstanceID  *uuid.UUID             `json:"instance_id,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	// Convida para todas as ocorrências do evento recorrente (não combina com instance_id)
+	Series bool `json:"series,omitempty"`
 }
 
 // BatchCreateParticipantsRequest representa request de criação em lote
 type BatchCreateParticipantsRequest struct {
-	Participants []CreateParticipantRequest `json:"participants" validate:"required,min=1,max=100"`
+	Participants []CreateParticipantRequest `json:"participants" validate:"required,min=1,dive"` // Máximo em config.ParticipantConfig
 }
 
 // ==================== UPDATE ====================
@@ -33,39 +36,183 @@ type UpdateParticipantRequest struct {
 	Email       *string                   `json:"email,omitempty" validate:"omitempty,email"`
 	Status      *domain.ParticipantStatus `json:"status,omitempty"`
 	Metadata    map[string]interface{}    `json:"metadata,omitempty"`
+	// Anotações internas ("VIP, precisa de vaga"); não aparecem na página de RSVP. "" ou null remove
+	Notes *string `json:"notes,omitempty" validate:"omitempty,max=1000"`
+	// Versão esperada (concorrência otimista); também aceita via header If-Match
+	Version *int `json:"version,omitempty"`
+
+	nulls map[string]bool // Campos enviados como null
+}
+
+// UnmarshalJSON decodifica o request guardando os campos enviados como null
+func (r *UpdateParticipantRequest) UnmarshalJSON(data []byte) error {
+	type plain UpdateParticipantRequest
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+
+	nulls, err := nullFields(data)
+	if err != nil {
+		return err
+	}
+	r.nulls = nulls
+	return nil
+}
+
+// IsNull retorna true se o campo foi enviado explicitamente como null
+func (r *UpdateParticipantRequest) IsNull(field string) bool {
+	return r.nulls[field]
+}
+
+// BatchUpdateStatusRequest representa a atualização de status em lote
+type BatchUpdateStatusRequest struct {
+	ParticipantIDs []uuid.UUID              `json:"participant_ids" validate:"required,min=1,max=100,dive,required"`
+	Status         domain.ParticipantStatus `json:"status" validate:"required,oneof=pending confirmed denied checked_in no_show"`
+}
+
+// RespondInviteRequest representa a resposta do participante pelo link de convite
+type RespondInviteRequest struct {
+	Status domain.ParticipantStatus `json:"status" validate:"required,oneof=confirmed denied"`
+	// Motivo opcional da recusa; ignorado ao confirmar
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+// ReconcileParticipantRequest aplica uma resposta que o participante deu pelo WhatsApp mas
+// que não chegou ao webhook
+type ReconcileParticipantRequest struct {
+	Status domain.ParticipantStatus `json:"status" validate:"required,oneof=confirmed denied"`
+	// Motivo opcional da recusa; ignorado ao confirmar
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=500"`
 }
 
 // ==================== RESPONSE ====================
 
+// InviteLinkResponse representa o link de convite gerado para um participante
+type InviteLinkResponse struct {
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// InviteResponse representa os dados pré-preenchidos da página pública de RSVP
+type InviteResponse struct {
+	ParticipantID   uuid.UUID                `json:"participant_id"`
+	Status          domain.ParticipantStatus `json:"status"`
+	EventID         uuid.UUID                `json:"event_id"`
+	EventName       string                   `json:"event_name"`
+	StartTime       time.Time                `json:"start_time"`
+	StartTimeLocal  string                   `json:"start_time_local"`
+	LocationAddress *string                  `json:"location_address,omitempty"`
+}
+
+// CopyParticipantsResponse representa o resultado da cópia de participantes entre eventos
+type CopyParticipantsResponse struct {
+	Copied       int                    `json:"copied"`
+	Skipped      int                    `json:"skipped"` // Já presentes no evento de destino ou repetidos na origem
+	Participants []*ParticipantResponse `json:"participants"`
+}
+
+// BatchStatusResult representa o resultado da atualização de um participante do lote.
+// Error é o código do erro (o mesmo das respostas de erro da API), preenchido pelo handler a partir de Err
+type BatchStatusResult struct {
+	ParticipantID uuid.UUID            `json:"participant_id"`
+	Success       bool                 `json:"success"`
+	Participant   *ParticipantResponse `json:"participant,omitempty"`
+	Error         string               `json:"error,omitempty"`
+	Err           error                `json:"-"`
+}
+
 // ParticipantResponse representa a resposta com dados do participante
 type ParticipantResponse struct {
-	ID          uuid.UUID                `json:"id"`
-	EventID     uuid.UUID                `json:"event_id"`
-	InstanceID  *uuid.UUID               `json:"instance_id,omitempty"`
-	EntityID    uuid.UUID                `json:"entity_id"`
-	Name        string                   `json:"name"`
-	PhoneNumber string                   `json:"phone_number"`
-	Email       *string                  `json:"email,omitempty"`
-	Status      domain.ParticipantStatus `json:"status"`
-	ConfirmedAt *time.Time               `json:"confirmed_at,omitempty"`
-	CheckedInAt *time.Time               `json:"checked_in_at,omitempty"`
-	Metadata    map[string]interface{}   `json:"metadata,omitempty"`
-	CreatedAt   time.Time                `json:"created_at"`
-	UpdatedAt   time.Time                `json:"updated_at"`
+	ID                  uuid.UUID                `json:"id"`
+	EventID             uuid.UUID                `json:"event_id"`
+	InstanceID          *uuid.UUID               `json:"instance_id,omitempty"`
+	EntityID            uuid.UUID                `json:"entity_id"`
+	Name                string                   `json:"name"`
+	PhoneNumber         string                   `json:"phone_number"`
+	Email               *string                  `json:"email,omitempty"`
+	Status              domain.ParticipantStatus `json:"status"`
+	ConfirmedAt         *time.Time               `json:"confirmed_at,omitempty"`
+	CheckedInAt         *time.Time               `json:"checked_in_at,omitempty"`
+	LocationConsent     bool                     `json:"location_consent"`
+	LocationConsentAt   *time.Time               `json:"location_consent_at,omitempty"`
+	InviteViewedAt      *time.Time               `json:"invite_viewed_at,omitempty"`
+	Metadata            map[string]interface{}   `json:"metadata,omitempty"`
+	Notes               *string                  `json:"notes,omitempty"`
+	DeclineReason       *string                  `json:"decline_reason,omitempty"`
+	Series              bool                     `json:"series"`
+	SeriesParticipantID *uuid.UUID               `json:"series_participant_id,omitempty"`
+	RedactedAt          *time.Time               `json:"redacted_at,omitempty"`
+	CreatedAt           time.Time                `json:"created_at"`
+	UpdatedAt           time.Time                `json:"updated_at"`
+	Version             int                      `json:"version"`
+}
+
+// ParticipantEventResponse representa um evento do participante e seu status nele
+type ParticipantEventResponse struct {
+	EventID         uuid.UUID                `json:"event_id"`
+	EventName       string                   `json:"event_name"`
+	StartTime       time.Time                `json:"start_time"`
+	StartTimeLocal  string                   `json:"start_time_local"`
+	LocationAddress *string                  `json:"location_address,omitempty"`
+	ParticipantID   uuid.UUID                `json:"participant_id"`
+	Status          domain.ParticipantStatus `json:"status"`
 }
 
 // ToParticipantResponse converte domain.Participant para ParticipantResponse
 func ToParticipantResponse(p *domain.Participant) *ParticipantResponse {
 	return &ParticipantResponse{
-		ID:          p.ID,
-		EventID:     p.EventID,
-		InstanceID:  p.InstanceID,
-		EntityID:    p.EntityID,
-		Status:      p.Status,
-		ConfirmedAt: p.ConfirmedAt,
-		CheckedInAt: p.CheckedInAt,
-		Metadata:    p.Metadata,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+		ID:                  p.ID,
+		EventID:             p.EventID,
+		InstanceID:          p.InstanceID,
+		EntityID:            p.EntityID,
+		Status:              p.Status,
+		ConfirmedAt:         p.ConfirmedAt,
+		CheckedInAt:         p.CheckedInAt,
+		LocationConsent:     p.LocationConsent,
+		LocationConsentAt:   p.LocationConsentAt,
+		InviteViewedAt:      p.InviteViewedAt,
+		Metadata:            p.Metadata,
+		Notes:               p.Notes,
+		DeclineReason:       p.DeclineReason,
+		Series:              p.Series,
+		SeriesParticipantID: p.SeriesParticipantID,
+		RedactedAt:          p.RedactedAt,
+		CreatedAt:           p.CreatedAt,
+		UpdatedAt:           p.UpdatedAt,
+		Version:             p.Version,
+	}
+}
+
+// ToInviteResponse monta os dados da página de RSVP a partir do participante e do evento
+func ToInviteResponse(p *domain.Participant, e *domain.Event) *InviteResponse {
+	return &InviteResponse{
+		ParticipantID:   p.ID,
+		Status:          p.Status,
+		EventID:         e.ID,
+		EventName:       e.Name,
+		StartTime:       e.StartTime,
+		StartTimeLocal:  FormatEventTime(e, e.StartTime),
+		LocationAddress: e.LocationAddress,
+	}
+}
+
+// ParticipantStatusEventResponse é uma mudança de status na linha do tempo do participante
+type ParticipantStatusEventResponse struct {
+	OldStatus domain.ParticipantStatus `json:"old_status,omitempty"`
+	NewStatus domain.ParticipantStatus `json:"new_status"`
+	Source    domain.StatusSource      `json:"source"`               // admin, whatsapp, rsvp ou system
+	ChangedBy *uuid.UUID               `json:"changed_by,omitempty"` // Usuário autor; vazio para o participante ou o sistema
+	ChangedAt time.Time                `json:"changed_at"`
+}
+
+// ToParticipantStatusEventResponse converte domain.StatusHistory para ParticipantStatusEventResponse
+func ToParticipantStatusEventResponse(h *domain.StatusHistory) *ParticipantStatusEventResponse {
+	return &ParticipantStatusEventResponse{
+		OldStatus: domain.ParticipantStatus(h.OldStatus),
+		NewStatus: domain.ParticipantStatus(h.NewStatus),
+		Source:    h.Source,
+		ChangedBy: h.ChangedBy,
+		ChangedAt: h.CreatedAt,
 	}
 }
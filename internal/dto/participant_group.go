@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CreateParticipantGroupRequest representa a criação de um grupo de participantes
+type CreateParticipantGroupRequest struct {
+	Name        string  `json:"name" validate:"required,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+}
+
+// UpdateParticipantGroupRequest representa a atualização de um grupo; campos ausentes não mudam
+type UpdateParticipantGroupRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+}
+
+// ParticipantGroupMembersRequest lista os participantes adicionados a um grupo
+type ParticipantGroupMembersRequest struct {
+	ParticipantIDs []uuid.UUID `json:"participant_ids" validate:"required,min=1,max=500"`
+}
+
+// ParticipantGroupResponse representa um grupo de participantes na resposta
+type ParticipantGroupResponse struct {
+	ID          uuid.UUID `json:"id"`
+	EventID     uuid.UUID `json:"event_id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToParticipantGroupResponse converte domain.ParticipantGroup para ParticipantGroupResponse
+func ToParticipantGroupResponse(g *domain.ParticipantGroup) *ParticipantGroupResponse {
+	return &ParticipantGroupResponse{
+		ID:          g.ID,
+		EventID:     g.EventID,
+		Name:        g.Name,
+		Description: g.Description,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// nullFields returns the top-level fields of a JSON object sent explicitly as null.
+// Update requests use pointer fields, so an omitted field and a null one both decode to
+// nil; this is what tells "leave as is" (omitted) from "clear it" (null), as in JSON Merge
+// Patch (RFC 7396)
+func nullFields(data []byte) (map[string]bool, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	var fields map[string]bool
+	for name, value := range raw {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			if fields == nil {
+				fields = make(map[string]bool)
+			}
+			fields[name] = true
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,140 @@
+package dto
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// EventReportResponse é o relatório consolidado de um evento (pós-evento)
+type EventReportResponse struct {
+	EventID        uuid.UUID           `json:"event_id"`
+	EventName      string              `json:"event_name"`
+	Status         domain.EventStatus  `json:"status"`
+	StartTime      time.Time           `json:"start_time"`
+	StartTimeLocal string              `json:"start_time_local"`
+	GeneratedAt    time.Time           `json:"generated_at"`
+	Stats          domain.EventStats   `json:"stats"`
+	Funnel         ReportFunnel        `json:"funnel"`
+	CheckIns       ReportCheckIns      `json:"check_ins"`
+	NoShows        ReportNoShows       `json:"no_shows"`
+	Notifications  ReportNotifications `json:"notifications"`
+	ETAAccuracy    ReportETAAccuracy   `json:"eta_accuracy"`
+}
+
+// ReportFunnel conta quantos participantes chegaram a cada etapa: convidados, abriram o
+// convite, responderam, confirmaram (incluindo quem depois fez check-in ou faltou) e
+// fizeram check-in
+type ReportFunnel struct {
+	Invited      int `json:"invited"`
+	InviteViewed int `json:"invite_viewed"`
+	Responded    int `json:"responded"`
+	Confirmed    int `json:"confirmed"`
+	CheckedIn    int `json:"checked_in"`
+}
+
+// ReportCheckIns resume os horários de check-in em relação ao início do evento
+type ReportCheckIns struct {
+	Count                   int        `json:"count"`
+	OnTime                  int        `json:"on_time"`
+	Late                    int        `json:"late"`
+	First                   *time.Time `json:"first,omitempty"`
+	Last                    *time.Time `json:"last,omitempty"`
+	AverageMinutesFromStart *float64   `json:"average_minutes_from_start,omitempty"` // Negativo = antes do início
+}
+
+// ReportNoShows lista quem confirmou e não apareceu
+type ReportNoShows struct {
+	Count                   int         `json:"count"`
+	ConfirmedWithoutCheckIn int         `json:"confirmed_without_check_in"` // Ainda não marcados como no_show
+	ParticipantIDs          []uuid.UUID `json:"participant_ids"`
+}
+
+// ReportNotifications resume as entregas das mensagens do evento pelo último status
+type ReportNotifications struct {
+	Total        int64   `json:"total"`
+	Sent         int64   `json:"sent"`
+	Delivered    int64   `json:"delivered"`
+	Read         int64   `json:"read"`
+	Failed       int64   `json:"failed"`
+	DeliveryRate float64 `json:"delivery_rate"` // Entregues ou lidas / total
+	ReadRate     float64 `json:"read_rate"`
+	FailureRate  float64 `json:"failure_rate"`
+}
+
+// ReportETAAccuracy compara a chegada prevista pela primeira localização de cada participante
+// com o horário do check-in
+type ReportETAAccuracy struct {
+	Samples             int      `json:"samples"`
+	AverageErrorMinutes *float64 `json:"average_error_minutes,omitempty"`
+}
+
+// WriteCSV escreve o relatório como linhas "section,metric,value"
+func (r *EventReportResponse) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	rows := [][]string{
+		{"section", "metric", "value"},
+		{"event", "id", r.EventID.String()},
+		{"event", "name", r.EventName},
+		{"event", "status", string(r.Status)},
+		{"event", "start_time", r.StartTime.Format(time.RFC3339)},
+		{"event", "generated_at", r.GeneratedAt.Format(time.RFC3339)},
+		{"stats", "total", strconv.Itoa(r.Stats.Total)},
+		{"stats", "pending", strconv.Itoa(r.Stats.Pending)},
+		{"stats", "confirmed", strconv.Itoa(r.Stats.Confirmed)},
+		{"stats", "denied", strconv.Itoa(r.Stats.Denied)},
+		{"stats", "checked_in", strconv.Itoa(r.Stats.CheckedIn)},
+		{"stats", "no_show", strconv.Itoa(r.Stats.NoShow)},
+		{"funnel", "invited", strconv.Itoa(r.Funnel.Invited)},
+		{"funnel", "invite_viewed", strconv.Itoa(r.Funnel.InviteViewed)},
+		{"funnel", "responded", strconv.Itoa(r.Funnel.Responded)},
+		{"funnel", "confirmed", strconv.Itoa(r.Funnel.Confirmed)},
+		{"funnel", "checked_in", strconv.Itoa(r.Funnel.CheckedIn)},
+		{"check_ins", "count", strconv.Itoa(r.CheckIns.Count)},
+		{"check_ins", "on_time", strconv.Itoa(r.CheckIns.OnTime)},
+		{"check_ins", "late", strconv.Itoa(r.CheckIns.Late)},
+		{"check_ins", "first", formatOptionalTime(r.CheckIns.First)},
+		{"check_ins", "last", formatOptionalTime(r.CheckIns.Last)},
+		{"check_ins", "average_minutes_from_start", formatOptionalFloat(r.CheckIns.AverageMinutesFromStart)},
+		{"no_shows", "count", strconv.Itoa(r.NoShows.Count)},
+		{"no_shows", "confirmed_without_check_in", strconv.Itoa(r.NoShows.ConfirmedWithoutCheckIn)},
+		{"notifications", "total", strconv.FormatInt(r.Notifications.Total, 10)},
+		{"notifications", "sent", strconv.FormatInt(r.Notifications.Sent, 10)},
+		{"notifications", "delivered", strconv.FormatInt(r.Notifications.Delivered, 10)},
+		{"notifications", "read", strconv.FormatInt(r.Notifications.Read, 10)},
+		{"notifications", "failed", strconv.FormatInt(r.Notifications.Failed, 10)},
+		{"notifications", "delivery_rate", strconv.FormatFloat(r.Notifications.DeliveryRate, 'f', 4, 64)},
+		{"notifications", "read_rate", strconv.FormatFloat(r.Notifications.ReadRate, 'f', 4, 64)},
+		{"notifications", "failure_rate", strconv.FormatFloat(r.Notifications.FailureRate, 'f', 4, 64)},
+		{"eta_accuracy", "samples", strconv.Itoa(r.ETAAccuracy.Samples)},
+		{"eta_accuracy", "average_error_minutes", formatOptionalFloat(r.ETAAccuracy.AverageErrorMinutes)},
+	}
+	for _, id := range r.NoShows.ParticipantIDs {
+		rows = append(rows, []string{"no_shows", "participant_id", id.String()})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatOptionalFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', 2, 64)
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// SearchResultType discrimina o tipo de cada resultado da busca
+type SearchResultType string
+
+const (
+	SearchResultEvent       SearchResultType = "event"
+	SearchResultParticipant SearchResultType = "participant"
+)
+
+// SearchResult representa um resultado da busca global
+type SearchResult struct {
+	Type        SearchResultType `json:"type"`
+	ID          uuid.UUID        `json:"id"`
+	Name        string           `json:"name"`
+	Status      string           `json:"status"`
+	EventID     *uuid.UUID       `json:"event_id,omitempty"`
+	PhoneNumber *string          `json:"phone_number,omitempty"`
+	StartTime   *time.Time       `json:"start_time,omitempty"`
+}
+
+// SearchResponse representa a resposta da busca global: eventos primeiro, depois participantes
+type SearchResponse struct {
+	Query   string          `json:"query"`
+	Results []*SearchResult `json:"results"`
+}
+
+// EventSearchResult converte um evento em resultado de busca
+func EventSearchResult(e *domain.Event) *SearchResult {
+	startTime := e.StartTime
+	return &SearchResult{
+		Type:      SearchResultEvent,
+		ID:        e.ID,
+		Name:      e.Name,
+		Status:    string(e.Status),
+		StartTime: &startTime,
+	}
+}
+
+// ParticipantSearchResult converte um participante (com RefEntity carregada) em resultado de busca
+func ParticipantSearchResult(p *domain.Participant) *SearchResult {
+	eventID := p.EventID
+	result := &SearchResult{
+		Type:    SearchResultParticipant,
+		ID:      p.ID,
+		Status:  string(p.Status),
+		EventID: &eventID,
+	}
+	if p.RefEntity != nil {
+		result.Name = p.RefEntity.Name
+		result.PhoneNumber = p.RefEntity.PhoneNumber
+	}
+	return result
+}
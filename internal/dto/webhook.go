@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CreateWebhookRequest representa o cadastro de um webhook de saída
+type CreateWebhookRequest struct {
+	URL    string                    `json:"url" validate:"required,url,max=500"`
+	Events []domain.WebhookEventType `json:"events,omitempty" validate:"omitempty,dive,oneof=event.created event.activated event.cancelled event.completed participant.confirmed participant.checked_in"`
+	// Opcional; se vazio um segredo aleatório é gerado
+	Secret *string `json:"secret,omitempty" validate:"omitempty,min=16,max=128"`
+}
+
+// WebhookResponse representa um webhook cadastrado
+type WebhookResponse struct {
+	ID        uuid.UUID                 `json:"id"`
+	EntityID  uuid.UUID                 `json:"entity_id"`
+	URL       string                    `json:"url"`
+	Events    []domain.WebhookEventType `json:"events"`
+	Active    bool                      `json:"active"`
+	Secret    string                    `json:"secret,omitempty"` // Só retornado na criação
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+// ToWebhookResponse converte domain.WebhookSubscription para WebhookResponse (sem o segredo)
+func ToWebhookResponse(s *domain.WebhookSubscription) *WebhookResponse {
+	return &WebhookResponse{
+		ID:        s.ID,
+		EntityID:  s.EntityID,
+		URL:       s.URL,
+		Events:    s.Events,
+		Active:    s.Active,
+		CreatedAt: s.CreatedAt,
+	}
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// SetWhatsAppSenderRequest configura o número WhatsApp próprio da entidade
+type SetWhatsAppSenderRequest struct {
+	// Phone number ID do número na WhatsApp Cloud API
+	PhoneNumberID string `json:"phone_number_id" validate:"required,numeric,max=50"`
+	// Token de acesso com permissão de envio pelo número; guardado criptografado
+	AccessToken string `json:"access_token" validate:"required,max=1024"`
+}
+
+// WhatsAppSenderResponse representa o remetente da entidade; o token nunca é retornado
+type WhatsAppSenderResponse struct {
+	EntityID      uuid.UUID `json:"entity_id"`
+	PhoneNumberID string    `json:"phone_number_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ToWhatsAppSenderResponse converte domain.WhatsAppSender para WhatsAppSenderResponse
+func ToWhatsAppSenderResponse(s *domain.WhatsAppSender) *WhatsAppSenderResponse {
+	return &WhatsAppSenderResponse{
+		EntityID:      s.EntityID,
+		PhoneNumberID: s.PhoneNumberID,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
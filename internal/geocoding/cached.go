@@ -0,0 +1,37 @@
+package geocoding
+
+import (
+	"context"
+
+	"event-coming/internal/cache"
+)
+
+// CachedGeocoder caches the coordinates resolved by another geocoder. Cache errors
+// never fail the lookup; failed lookups aren't cached.
+type CachedGeocoder struct {
+	next  Geocoder
+	cache *cache.GeocodeCache
+}
+
+// NewCachedGeocoder wraps next with the geocode cache
+func NewCachedGeocoder(next Geocoder, geocodeCache *cache.GeocodeCache) *CachedGeocoder {
+	return &CachedGeocoder{
+		next:  next,
+		cache: geocodeCache,
+	}
+}
+
+// Geocode returns the cached coordinates or asks the wrapped geocoder
+func (g *CachedGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	if lat, lng, ok, err := g.cache.Get(ctx, address); err == nil && ok {
+		return lat, lng, nil
+	}
+
+	lat, lng, err := g.next.Geocode(ctx, address)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	_ = g.cache.Set(ctx, address, lat, lng)
+	return lat, lng, nil
+}
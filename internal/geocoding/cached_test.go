@@ -0,0 +1,61 @@
+package geocoding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingGeocoder answers every lookup with the same result and counts the calls
+type countingGeocoder struct {
+	lat, lng float64
+	err      error
+	calls    int
+}
+
+func (g *countingGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	g.calls++
+	return g.lat, g.lng, g.err
+}
+
+func newTestGeocodeCache(t *testing.T) *cache.GeocodeCache {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return cache.NewGeocodeCache(client, "geocode:", time.Hour)
+}
+
+func TestCachedGeocoder_CachesResolvedCoordinates(t *testing.T) {
+	ctx := context.Background()
+	next := &countingGeocoder{lat: -23.56, lng: -46.65}
+	geocoder := NewCachedGeocoder(next, newTestGeocodeCache(t))
+
+	for i := 0; i < 2; i++ {
+		lat, lng, err := geocoder.Geocode(ctx, "Av. Paulista, 1000")
+		require.NoError(t, err)
+		assert.Equal(t, -23.56, lat)
+		assert.Equal(t, -46.65, lng)
+	}
+	assert.Equal(t, 1, next.calls)
+}
+
+func TestCachedGeocoder_DoesNotCacheFailures(t *testing.T) {
+	ctx := context.Background()
+	next := &countingGeocoder{err: ErrNoResults}
+	geocoder := NewCachedGeocoder(next, newTestGeocodeCache(t))
+
+	for i := 0; i < 2; i++ {
+		_, _, err := geocoder.Geocode(ctx, "Nowhere")
+		assert.ErrorIs(t, err, ErrNoResults)
+	}
+	assert.Equal(t, 2, next.calls)
+}
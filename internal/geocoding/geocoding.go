@@ -0,0 +1,42 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+)
+
+// ErrNoResults is returned when the provider can't find the address
+var ErrNoResults = errors.New("geocoding: address not found")
+
+// Geocoder resolves a free-text address into coordinates
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (lat, lng float64, err error)
+}
+
+// Providers de geocoding suportados
+const (
+	ProviderNominatim = "nominatim"
+)
+
+// New creates the geocoder selected by cfg.Provider, cached in geocodeCache when it
+// isn't nil. Returns nil (geocoding disabled) when no provider is configured.
+func New(cfg *config.GeocodingConfig, geocodeCache *cache.GeocodeCache) (Geocoder, error) {
+	var geocoder Geocoder
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case ProviderNominatim:
+		geocoder = NewNominatimGeocoder(cfg)
+	default:
+		return nil, fmt.Errorf("unknown geocoding provider %q", cfg.Provider)
+	}
+
+	if geocodeCache != nil {
+		geocoder = NewCachedGeocoder(geocoder, geocodeCache)
+	}
+	return geocoder, nil
+}
@@ -0,0 +1,78 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"event-coming/internal/config"
+)
+
+// NominatimGeocoder resolves addresses with the Nominatim search API (OpenStreetMap)
+type NominatimGeocoder struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+// NewNominatimGeocoder creates a geocoder for the Nominatim instance at cfg.BaseURL
+func NewNominatimGeocoder(cfg *config.GeocodingConfig) *NominatimGeocoder {
+	return &NominatimGeocoder{
+		baseURL:   strings.TrimRight(cfg.BaseURL, "/"),
+		userAgent: cfg.UserAgent,
+		client:    &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+type nominatimResult struct {
+	Lat string `json:"lat"`
+	Lon string `json:"lon"`
+}
+
+// Geocode returns the coordinates of the best match for the address
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	query := url.Values{}
+	query.Set("q", address)
+	query.Set("format", "json")
+	query.Set("limit", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to build geocoding request: %w", err)
+	}
+	// A política de uso do Nominatim exige um User-Agent identificando a aplicação
+	req.Header.Set("User-Agent", g.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("geocoding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("geocoding request failed with status %d", resp.StatusCode)
+	}
+
+	var results []nominatimResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode geocoding response: %w", err)
+	}
+	if len(results) == 0 {
+		return 0, 0, ErrNoResults
+	}
+
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude in geocoding response: %w", err)
+	}
+	lng, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude in geocoding response: %w", err)
+	}
+	return lat, lng, nil
+}
@@ -2,33 +2,36 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
+	"event-coming/internal/config"
 	"event-coming/internal/dto"
+	"event-coming/internal/handler/middleware"
 	"event-coming/internal/service"
+	"event-coming/pkg/response"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // AuthHandler contém as dependências para handlers de auth
 type AuthHandler struct {
 	authService service.AuthService // ← Dependência injetada
+	jwtConfig   *config.JWTConfig
 }
 
 // NewAuthHandler cria um novo AuthHandler
-func NewAuthHandler(authService service.AuthService) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, jwtConfig *config.JWTConfig) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
+		jwtConfig:   jwtConfig,
 	}
 }
 
 // Login processa POST /auth/login
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid request",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -47,11 +50,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) Register(c *gin.Context) {
 	// 1. Parse + Validação do JSON
 	var req dto.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid request",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -72,11 +71,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // Refresh processa POST /auth/refresh
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var req dto.RefreshRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid request",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -94,14 +89,20 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 // Logout processa POST /auth/logout
 func (h *AuthHandler) Logout(c *gin.Context) {
 	var req dto.LogoutRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid request",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
+	// Access token atual (opcional): é revogado junto com o refresh token
+	if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		if claims, err := middleware.ParseAccessToken(h.jwtConfig, bearer); err == nil {
+			req.AccessTokenID, _ = claims["jti"].(string)
+			if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+				req.AccessTokenExpiresAt = exp.Time
+			}
+		}
+	}
+
 	if err := h.authService.Logout(c.Request.Context(), req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "invalid token",
@@ -117,11 +118,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 // ForgotPassword processa POST /auth/forgot-password
 func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	var req dto.ForgotPasswordRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid request",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -140,11 +137,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 // ResetPassword processa POST /auth/reset-password
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req dto.ResetPasswordRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid request",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -164,3 +157,50 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// DeactivateUser processa POST /admin/users/:id/deactivate: desativa o usuário e revoga
+// seus tokens
+func (h *AuthHandler) DeactivateUser(c *gin.Context) {
+	adminID, ok := c.Get("user_id")
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "missing user_id")
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid_id", "invalid user ID")
+		return
+	}
+
+	if err := h.authService.DeactivateUser(c.Request.Context(), adminID.(uuid.UUID), targetID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// Impersonate processa POST /admin/users/:id/impersonate: emite um access token de curta
+// duração para o super admin agir como o usuário
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	adminID, ok := c.Get("user_id")
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "missing user_id")
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid_id", "invalid user ID")
+		return
+	}
+
+	result, err := h.authService.Impersonate(c.Request.Context(), adminID.(uuid.UUID), targetID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"event-coming/pkg/response"
+	"event-coming/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindJSON decodes the request body into req and validates both `binding` and
+// `validate` tags. On failure it writes a 400 (malformed body) or a 422 with
+// field-level errors and returns false.
+func bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		if details := validator.FormatValidationErrors(err); len(details) > 0 {
+			response.ValidationError(c, details)
+			return false
+		}
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid request body")
+		return false
+	}
+
+	if err := validator.Validate.Struct(req); err != nil {
+		response.ValidationError(c, validator.FormatValidationErrors(err))
+		return false
+	}
+
+	return true
+}
+
+// applyIfMatch sets *version from the If-Match header ("3", "\"3\"" or W/"3") when the
+// body didn't carry one. Writes a 400 and returns false if the header is malformed.
+func applyIfMatch(c *gin.Context, version **int) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || *version != nil {
+		return true
+	}
+
+	value := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid If-Match header")
+		return false
+	}
+
+	*version = &v
+	return true
+}
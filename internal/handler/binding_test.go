@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"event-coming/internal/dto"
+	"event-coming/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bindTestResponse is the error body written by bindJSON
+type bindTestResponse struct {
+	Success bool `json:"success"`
+	Error   struct {
+		Code    string                      `json:"code"`
+		Details []validator.ValidationError `json:"details"`
+	} `json:"error"`
+}
+
+// bindTestRequest runs bindJSON for body against a fresh req value
+func bindTestRequest[T any](t *testing.T, body string) (*httptest.ResponseRecorder, bindTestResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var req T
+	if bindJSON(c, &req) {
+		c.Status(http.StatusOK)
+	}
+
+	var resp bindTestResponse
+	if w.Code != http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, resp
+}
+
+func TestBindJSON_MissingRequiredField(t *testing.T) {
+	// binding tags (validated by gin)
+	w, resp := bindTestRequest[dto.RegisterRequest](t, `{"name": "Maria", "password": "secret123"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.False(t, resp.Success)
+	assert.Equal(t, "validation_error", resp.Error.Code)
+	assert.Equal(t, []validator.ValidationError{
+		{Field: "email", Rule: "required", Message: "email is required"},
+	}, resp.Error.Details)
+
+	// validate tags (validated after binding)
+	w, resp = bindTestRequest[dto.CreateParticipantRequest](t, `{"phone_number": "+5511999999999"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, []validator.ValidationError{
+		{Field: "name", Rule: "required", Message: "name is required"},
+	}, resp.Error.Details)
+}
+
+func TestBindJSON_NestedFieldPath(t *testing.T) {
+	w, resp := bindTestRequest[dto.RegisterRequest](t, `{
+		"name": "Maria", "email": "maria@example.com", "password": "secret123",
+		"entity": {"type": "company", "name": "X"}
+	}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Len(t, resp.Error.Details, 1)
+	assert.Equal(t, "entity.name", resp.Error.Details[0].Field)
+	assert.Equal(t, "min", resp.Error.Details[0].Rule)
+}
+
+func TestBindJSON_MalformedBody(t *testing.T) {
+	w, resp := bindTestRequest[dto.RegisterRequest](t, `{"name":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "bad_request", resp.Error.Code)
+	assert.Empty(t, resp.Error.Details)
+}
+
+func TestBindJSON_Valid(t *testing.T) {
+	w, _ := bindTestRequest[dto.CreateParticipantRequest](t, `{"name": "Maria", "phone_number": "+5511999999999"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestApplyIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bodyVersion := 5
+
+	tests := []struct {
+		name    string
+		header  string
+		version *int
+		want    *int
+		ok      bool
+	}{
+		{name: "no header", ok: true},
+		{name: "bare number", header: "3", want: intPtr(3), ok: true},
+		{name: "quoted", header: `"3"`, want: intPtr(3), ok: true},
+		{name: "weak", header: `W/"3"`, want: intPtr(3), ok: true},
+		{name: "body version wins", header: `"3"`, version: &bodyVersion, want: &bodyVersion, ok: true},
+		{name: "malformed", header: "*", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-Match", tt.header)
+			}
+
+			version := tt.version
+			assert.Equal(t, tt.ok, applyIfMatch(c, &version))
+			if !tt.ok {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+			assert.Equal(t, tt.want, version)
+		})
+	}
+}
+
+func intPtr(v int) *int { return &v }
@@ -1,14 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
 	"event-coming/internal/service"
+	"event-coming/pkg/pagination"
 	"event-coming/pkg/response"
-	"event-coming/pkg/validator"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -17,15 +18,23 @@ import (
 
 // EntityHandler handles entity HTTP requests
 type EntityHandler struct {
-	entityService *service.EntityService
-	logger        *zap.Logger
+	entityService  *service.EntityService
+	auditService   *service.AuditService
+	webhookService *service.WebhookService
+	apiKeyService  *service.APIKeyService
+	senderService  *service.WhatsAppSenderService
+	logger         *zap.Logger
 }
 
 // NewEntityHandler creates a new entity handler
-func NewEntityHandler(entityService *service.EntityService, logger *zap.Logger) *EntityHandler {
+func NewEntityHandler(entityService *service.EntityService, auditService *service.AuditService, webhookService *service.WebhookService, apiKeyService *service.APIKeyService, senderService *service.WhatsAppSenderService, logger *zap.Logger) *EntityHandler {
 	return &EntityHandler{
-		entityService: entityService,
-		logger:        logger,
+		entityService:  entityService,
+		auditService:   auditService,
+		webhookService: webhookService,
+		apiKeyService:  apiKeyService,
+		senderService:  senderService,
+		logger:         logger,
 	}
 }
 
@@ -33,22 +42,14 @@ func NewEntityHandler(entityService *service.EntityService, logger *zap.Logger)
 func (h *EntityHandler) Create(c *gin.Context) {
 	var req dto.CreateEntityRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("Failed to bind request", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid request body")
-		return
-	}
-
-	if err := validator.Validate.Struct(&req); err != nil {
-		h.logger.Warn("Validation failed", zap.Error(err))
-		response.ValidationError(c, validator.FormatValidationErrors(err))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	entity, err := h.entityService.Create(c.Request.Context(), &req)
 	if err != nil {
 		h.logger.Error("Failed to create entity", zap.Error(err))
-		response.HandleDomainError(c, err)
+		response.FromError(c, err)
 		return
 	}
 
@@ -67,7 +68,7 @@ func (h *EntityHandler) GetByID(c *gin.Context) {
 	entity, err := h.entityService.GetByID(c.Request.Context(), id)
 	if err != nil {
 		h.logger.Error("Failed to get entity", zap.Error(err))
-		response.HandleDomainError(c, err)
+		response.FromError(c, err)
 		return
 	}
 
@@ -84,26 +85,18 @@ func (h *EntityHandler) Update(c *gin.Context) {
 	}
 
 	var req dto.UpdateEntityRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("Failed to bind request", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid request body")
-		return
-	}
-
-	if err := validator.Validate.Struct(&req); err != nil {
-		h.logger.Warn("Validation failed", zap.Error(err))
-		response.ValidationError(c, validator.FormatValidationErrors(err))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	entity, err := h.entityService.Update(c.Request.Context(), id, &req)
 	if err != nil {
 		h.logger.Error("Failed to update entity", zap.Error(err))
-		if err == domain.ErrInvalidInput {
-			response.Error(c, http.StatusBadRequest, "bad_request", "Entity cannot be its own parent")
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.Error(c, http.StatusBadRequest, "bad_request", "Entity cannot be its own parent or a descendant of itself")
 			return
 		}
-		response.HandleDomainError(c, err)
+		response.FromError(c, err)
 		return
 	}
 
@@ -121,7 +114,7 @@ func (h *EntityHandler) Delete(c *gin.Context) {
 
 	if err := h.entityService.Delete(c.Request.Context(), id); err != nil {
 		h.logger.Error("Failed to delete entity", zap.Error(err))
-		response.HandleDomainError(c, err)
+		response.FromError(c, err)
 		return
 	}
 
@@ -130,13 +123,14 @@ func (h *EntityHandler) Delete(c *gin.Context) {
 
 // List handles GET /entities
 func (h *EntityHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	page, _ := strconv.Atoi(c.Query("page"))
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+	page, perPage = pagination.Normalize(page, perPage)
 
 	entities, total, err := h.entityService.List(c.Request.Context(), page, perPage)
 	if err != nil {
 		h.logger.Error("Failed to list entities", zap.Error(err))
-		response.HandleDomainError(c, err)
+		response.FromError(c, err)
 		return
 	}
 
@@ -152,19 +146,38 @@ func (h *EntityHandler) ListByParent(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	page, _ := strconv.Atoi(c.Query("page"))
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+	page, perPage = pagination.Normalize(page, perPage)
 
 	entities, total, err := h.entityService.ListByParent(c.Request.Context(), parentID, page, perPage)
 	if err != nil {
 		h.logger.Error("Failed to list child entities", zap.Error(err))
-		response.HandleDomainError(c, err)
+		response.FromError(c, err)
 		return
 	}
 
 	response.Paginated(c, entities, page, perPage, total)
 }
 
+// ListDescendants handles GET /entities/:id/descendants
+func (h *EntityHandler) ListDescendants(c *gin.Context) {
+	rootID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	descendants, err := h.entityService.ListDescendants(c.Request.Context(), rootID)
+	if err != nil {
+		h.logger.Error("Failed to list entity descendants", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, descendants)
+}
+
 // GetByDocument handles GET /entities/document/:document
 func (h *EntityHandler) GetByDocument(c *gin.Context) {
 	document := c.Param("document")
@@ -176,9 +189,389 @@ func (h *EntityHandler) GetByDocument(c *gin.Context) {
 	entity, err := h.entityService.GetByDocument(c.Request.Context(), document)
 	if err != nil {
 		h.logger.Error("Failed to get entity by document", zap.Error(err))
-		response.HandleDomainError(c, err)
+		response.FromError(c, err)
 		return
 	}
 
 	response.Success(c, entity)
 }
+
+// parseMemberParams extracts the entity ID, the acting user and (optionally) the member user ID
+func (h *EntityHandler) parseMemberParams(c *gin.Context, withUser bool) (entID, actorID, userID uuid.UUID, ok bool) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	actor, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "user_id not found in context")
+		return
+	}
+	actorID = actor.(uuid.UUID)
+
+	if withUser {
+		userID, err = uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "bad_request", "Invalid user ID")
+			return
+		}
+	}
+
+	return entID, actorID, userID, true
+}
+
+// AddMember handles POST /entities/:id/members
+func (h *EntityHandler) AddMember(c *gin.Context) {
+	entID, actorID, _, ok := h.parseMemberParams(c, false)
+	if !ok {
+		return
+	}
+
+	var req dto.AddMemberRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	member, err := h.entityService.AddMember(c.Request.Context(), actorID, entID, req.UserID, req.Role)
+	if err != nil {
+		h.logger.Error("Failed to add entity member", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.Created(c, member)
+}
+
+// UpdateMemberRole handles PUT /entities/:id/members/:user_id
+func (h *EntityHandler) UpdateMemberRole(c *gin.Context) {
+	entID, actorID, userID, ok := h.parseMemberParams(c, true)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateMemberRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	member, err := h.entityService.UpdateMemberRole(c.Request.Context(), actorID, entID, userID, req.Role)
+	if err != nil {
+		h.logger.Error("Failed to update entity member role", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, member)
+}
+
+// RemoveMember handles DELETE /entities/:id/members/:user_id
+func (h *EntityHandler) RemoveMember(c *gin.Context) {
+	entID, actorID, userID, ok := h.parseMemberParams(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.entityService.RemoveMember(c.Request.Context(), actorID, entID, userID); err != nil {
+		h.logger.Error("Failed to remove entity member", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// ListAudit handles GET /entities/:id/audit?action=<action>&page=&per_page=
+func (h *EntityHandler) ListAudit(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	// Only the entity bound to the token can read its audit trail
+	if !authorizeTokenEntity(c, entID) {
+		return
+	}
+
+	var action *domain.AuditAction
+	if a := c.Query("action"); a != "" {
+		filter := domain.AuditAction(a)
+		action = &filter
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+	page, perPage = pagination.Normalize(page, perPage)
+
+	entries, total, err := h.auditService.List(c.Request.Context(), entID, action, page, perPage)
+	if err != nil {
+		h.logger.Error("Failed to list audit log", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.Paginated(c, entries, page, perPage, total)
+}
+
+// authorizeTokenEntity allows only the entity bound to the token (or a super admin),
+// writing 403 otherwise
+func authorizeTokenEntity(c *gin.Context, entID uuid.UUID) bool {
+	tokenEntity, exists := c.Get("entity_id")
+	if exists && tokenEntity.(uuid.UUID) == entID {
+		return true
+	}
+
+	role, _ := c.Get("role")
+	if role == domain.UserRoleSuperAdmin {
+		return true
+	}
+
+	response.Error(c, http.StatusForbidden, "forbidden", "Forbidden")
+	return false
+}
+
+// ==================== WEBHOOKS ====================
+
+// CreateWebhook handles POST /entities/:id/webhooks
+func (h *EntityHandler) CreateWebhook(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	if !authorizeTokenEntity(c, entID) {
+		return
+	}
+
+	var req dto.CreateWebhookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	webhook, err := h.webhookService.Create(c.Request.Context(), entID, &req)
+	if err != nil {
+		h.logger.Error("Failed to create webhook", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.Created(c, webhook)
+}
+
+// ListWebhooks handles GET /entities/:id/webhooks
+func (h *EntityHandler) ListWebhooks(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	if !authorizeTokenEntity(c, entID) {
+		return
+	}
+
+	webhooks, err := h.webhookService.List(c.Request.Context(), entID)
+	if err != nil {
+		h.logger.Error("Failed to list webhooks", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, webhooks)
+}
+
+// DeleteWebhook handles DELETE /entities/:id/webhooks/:webhook_id
+func (h *EntityHandler) DeleteWebhook(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid webhook ID")
+		return
+	}
+
+	if !authorizeTokenEntity(c, entID) {
+		return
+	}
+
+	if err := h.webhookService.Delete(c.Request.Context(), entID, webhookID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// ==================== API KEYS ====================
+
+// authorizeKeyManagement allows managing API keys only with a user session of the entity:
+// a request authenticated by an API key can't issue or revoke keys
+func authorizeKeyManagement(c *gin.Context, entID uuid.UUID) bool {
+	if _, viaAPIKey := c.Get("api_key_id"); viaAPIKey {
+		response.Error(c, http.StatusForbidden, "forbidden", "API keys can't manage API keys")
+		return false
+	}
+	return authorizeTokenEntity(c, entID)
+}
+
+// CreateAPIKey handles POST /entities/:id/api-keys
+func (h *EntityHandler) CreateAPIKey(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	if !authorizeKeyManagement(c, entID) {
+		return
+	}
+
+	userID, ok := c.MustGet("user_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "invalid user_id")
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	key, err := h.apiKeyService.Create(c.Request.Context(), entID, userID, &req)
+	if err != nil {
+		h.logger.Error("Failed to create API key", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.Created(c, key)
+}
+
+// ListAPIKeys handles GET /entities/:id/api-keys
+func (h *EntityHandler) ListAPIKeys(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	if !authorizeKeyManagement(c, entID) {
+		return
+	}
+
+	keys, err := h.apiKeyService.List(c.Request.Context(), entID)
+	if err != nil {
+		h.logger.Error("Failed to list API keys", zap.Error(err))
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, keys)
+}
+
+// RevokeAPIKey handles DELETE /entities/:id/api-keys/:key_id
+func (h *EntityHandler) RevokeAPIKey(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("key_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid API key ID")
+		return
+	}
+
+	if !authorizeKeyManagement(c, entID) {
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(c.Request.Context(), entID, keyID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// ==================== WHATSAPP SENDER ====================
+
+// SetWhatsAppSender handles PUT /entities/:id/whatsapp-sender
+func (h *EntityHandler) SetWhatsAppSender(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	if !authorizeTokenEntity(c, entID) {
+		return
+	}
+
+	var req dto.SetWhatsAppSenderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	sender, err := h.senderService.Set(c.Request.Context(), entID, &req)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to set WhatsApp sender", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to set whatsapp sender")
+		return
+	}
+
+	response.Success(c, sender)
+}
+
+// GetWhatsAppSender handles GET /entities/:id/whatsapp-sender
+func (h *EntityHandler) GetWhatsAppSender(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	if !authorizeTokenEntity(c, entID) {
+		return
+	}
+
+	sender, err := h.senderService.Get(c.Request.Context(), entID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, sender)
+}
+
+// DeleteWhatsAppSender handles DELETE /entities/:id/whatsapp-sender
+func (h *EntityHandler) DeleteWhatsAppSender(c *gin.Context) {
+	entID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid entity ID")
+		return
+	}
+
+	if !authorizeTokenEntity(c, entID) {
+		return
+	}
+
+	if err := h.senderService.Delete(c.Request.Context(), entID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
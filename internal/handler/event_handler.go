@@ -1,12 +1,17 @@
 package handler
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
 	"event-coming/internal/service"
+	"event-coming/pkg/pagination"
 	"event-coming/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -16,15 +21,17 @@ import (
 
 // EventHandler gerencia requisições de eventos
 type EventHandler struct {
-	service *service.EventService
-	logger  *zap.Logger
+	service       *service.EventService
+	notifications service.NotificationService
+	logger        *zap.Logger
 }
 
 // NewEventHandler cria um novo handler de eventos
-func NewEventHandler(service *service.EventService, logger *zap.Logger) *EventHandler {
+func NewEventHandler(service *service.EventService, notifications service.NotificationService, logger *zap.Logger) *EventHandler {
 	return &EventHandler{
-		service: service,
-		logger:  logger,
+		service:       service,
+		notifications: notifications,
+		logger:        logger,
 	}
 }
 
@@ -58,13 +65,16 @@ func (h *EventHandler) Create(c *gin.Context) {
 	}
 
 	var req dto.CreateEventRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	event, err := h.service.Create(c.Request.Context(), entityID, userID, &req)
 	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
 		h.logger.Error("Failed to create event",
 			zap.String("entity_id", entityIDStr.(string)),
 			zap.Error(err),
@@ -76,6 +86,83 @@ func (h *EventHandler) Create(c *gin.Context) {
 	response.Created(c, event)
 }
 
+// PreviewSchedule calcula os horários dos schedulers sem criar o evento
+// POST /api/v1/events/preview-schedule
+func (h *EventHandler) PreviewSchedule(c *gin.Context) {
+	entityID, ok := c.Get("entity_id")
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "entity_id not found in context")
+		return
+	}
+
+	var req dto.CreateEventRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	previews, err := h.service.PreviewSchedule(c.Request.Context(), entityID.(uuid.UUID), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.Error(c, http.StatusBadRequest, "invalid_input", err.Error())
+			return
+		}
+		h.logger.Error("Failed to preview event schedule", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to preview schedule")
+		return
+	}
+
+	response.Success(c, previews)
+}
+
+// maxICSImportSize é o maior arquivo ICS aceito na importação, em bytes
+const maxICSImportSize = 5 << 20
+
+// ImportICS cria eventos em rascunho a partir de um arquivo ICS enviado no corpo
+// POST /api/v1/events/import.ics
+func (h *EventHandler) ImportICS(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+	userID, ok := c.MustGet("user_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "invalid user_id")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxICSImportSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(c, http.StatusRequestEntityTooLarge, "payload_too_large", "calendar exceeds the maximum size")
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "bad_request", "failed to read calendar")
+		return
+	}
+
+	result, err := h.service.ImportICS(c.Request.Context(), entityID, userID, bytes.NewReader(body))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.Error(c, http.StatusBadRequest, "invalid_input", err.Error())
+			return
+		}
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to import events from ICS",
+			zap.String("entity_id", entityID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to import events")
+		return
+	}
+
+	response.Created(c, result)
+}
+
 // GetByID busca um evento por ID
 // GET /api/v1/events/:id
 func (h *EventHandler) GetByID(c *gin.Context) {
@@ -109,8 +196,8 @@ func (h *EventHandler) GetByID(c *gin.Context) {
 	}
 
 	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "event not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		h.logger.Error("Failed to get event",
@@ -124,8 +211,73 @@ func (h *EventHandler) GetByID(c *gin.Context) {
 	response.Success(c, event)
 }
 
+// GetDetail retorna o evento com a primeira página de participantes, os números por
+// status e a contagem de localizações ao vivo, numa chamada só
+// GET /api/v1/events/:id/detail
+func (h *EventHandler) GetDetail(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	detail, err := h.service.GetDetail(c.Request.Context(), entityID, eventID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to get event detail",
+			zap.String("event_id", eventID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to get event detail")
+		return
+	}
+
+	response.Success(c, detail)
+}
+
+// RedactParticipants apaga os dados pessoais dos participantes de um evento encerrado
+// POST /api/v1/events/:id/redact
+func (h *EventHandler) RedactParticipants(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	result, err := h.service.RedactParticipants(c.Request.Context(), entityID, eventID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to redact event participants",
+			zap.String("event_id", eventID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to redact event participants")
+		return
+	}
+
+	response.Success(c, result)
+}
+
 // Update atualiza um evento
-// PUT /api/v1/events/:id
+// PUT|PATCH /api/v1/events/:id
 func (h *EventHandler) Update(c *gin.Context) {
 	entityIDStr, exists := c.Get("entity_id")
 	if !exists {
@@ -147,15 +299,14 @@ func (h *EventHandler) Update(c *gin.Context) {
 	}
 
 	var req dto.UpdateEventRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+	if !bindJSON(c, &req) || !applyIfMatch(c, &req.Version) {
 		return
 	}
 
 	event, err := h.service.Update(c.Request.Context(), entityID, eventID, &req)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "event not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		h.logger.Error("Failed to update event",
@@ -192,8 +343,8 @@ func (h *EventHandler) Delete(c *gin.Context) {
 	}
 
 	if err := h.service.Delete(c.Request.Context(), entityID, eventID); err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "event not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		h.logger.Error("Failed to delete event",
@@ -223,15 +374,9 @@ func (h *EventHandler) List(c *gin.Context) {
 	}
 
 	// Paginação
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
-
-	if page < 1 {
-		page = 1
-	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 20
-	}
+	page, _ := strconv.Atoi(c.Query("page"))
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+	page, perPage = pagination.Normalize(page, perPage)
 
 	// Filtro por status
 	statusStr := c.Query("status")
@@ -258,6 +403,36 @@ func (h *EventHandler) List(c *gin.Context) {
 	response.Paginated(c, events, page, perPage, total)
 }
 
+// ListMine lista os eventos em que o usuário autenticado é participante, em qualquer entidade
+// GET /api/v1/me/events
+func (h *EventHandler) ListMine(c *gin.Context) {
+	userID, ok := c.MustGet("user_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "invalid user_id")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+	page, perPage = pagination.Normalize(page, perPage)
+
+	events, total, err := h.service.ListForUser(c.Request.Context(), userID, page, perPage)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to list participant events",
+			zap.String("user_id", userID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to list events")
+		return
+	}
+
+	response.Paginated(c, events, page, perPage, total)
+}
+
 // Activate ativa um evento
 // POST /api/v1/events/:id/activate
 func (h *EventHandler) Activate(c *gin.Context) {
@@ -282,8 +457,8 @@ func (h *EventHandler) Activate(c *gin.Context) {
 
 	event, err := h.service.Activate(c.Request.Context(), entityID, eventID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "event not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		h.logger.Error("Failed to activate event",
@@ -321,8 +496,8 @@ func (h *EventHandler) Cancel(c *gin.Context) {
 
 	event, err := h.service.Cancel(c.Request.Context(), entityID, eventID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "event not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		h.logger.Error("Failed to cancel event",
@@ -360,8 +535,8 @@ func (h *EventHandler) Complete(c *gin.Context) {
 
 	event, err := h.service.Complete(c.Request.Context(), entityID, eventID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "event not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		h.logger.Error("Failed to complete event",
@@ -374,3 +549,643 @@ func (h *EventHandler) Complete(c *gin.Context) {
 
 	response.Success(c, event)
 }
+
+// multipartOverhead é a folga para boundaries e headers do multipart acima do tamanho máximo do arquivo
+const multipartOverhead = 1 << 20
+
+// UploadAttachment anexa um arquivo ao evento (multipart, campo "file")
+// POST /api/v1/events/:id/attachments
+func (h *EventHandler) UploadAttachment(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+	userID, ok := c.MustGet("user_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "invalid user_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaxAttachmentSize()+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.FromError(c, domain.ErrAttachmentTooLarge)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "bad_request", "multipart field 'file' is required")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "failed to read uploaded file")
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.AddAttachment(c.Request.Context(), entityID, eventID, userID, fileHeader.Filename, fileHeader.Size, file)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to add attachment",
+			zap.String("event_id", eventID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to add attachment")
+		return
+	}
+
+	response.Created(c, attachment)
+}
+
+// ListAttachments lista os anexos de um evento
+// GET /api/v1/events/:id/attachments
+func (h *EventHandler) ListAttachments(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	attachments, err := h.service.ListAttachments(c.Request.Context(), entityID, eventID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to list attachments",
+			zap.String("event_id", eventID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to list attachments")
+		return
+	}
+
+	response.Success(c, attachments)
+}
+
+// DownloadAttachment devolve o conteúdo de um anexo
+// GET /api/v1/events/:id/attachments/:attachment_id
+func (h *EventHandler) DownloadAttachment(c *gin.Context) {
+	entityID, eventID, attachmentID, ok := h.attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.service.OpenAttachment(c.Request.Context(), entityID, eventID, attachmentID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to open attachment",
+			zap.String("attachment_id", attachmentID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to read attachment")
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment remove um anexo do evento
+// DELETE /api/v1/events/:id/attachments/:attachment_id
+func (h *EventHandler) DeleteAttachment(c *gin.Context) {
+	entityID, eventID, attachmentID, ok := h.attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveAttachment(c.Request.Context(), entityID, eventID, attachmentID); err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to remove attachment",
+			zap.String("attachment_id", attachmentID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to remove attachment")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// attachmentParams lê entity_id do contexto e os IDs de evento e anexo da rota
+func (h *EventHandler) attachmentParams(c *gin.Context) (entityID, eventID, attachmentID uuid.UUID, ok bool) {
+	entityID, ok = c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	var err error
+	if eventID, err = uuid.Parse(c.Param("id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return entityID, eventID, attachmentID, false
+	}
+	if attachmentID, err = uuid.Parse(c.Param("attachment_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid attachment_id")
+		return entityID, eventID, attachmentID, false
+	}
+
+	return entityID, eventID, attachmentID, true
+}
+
+// ListInstances lista as ocorrências geradas de um evento recorrente
+// GET /api/v1/events/:id/instances
+func (h *EventHandler) ListInstances(c *gin.Context) {
+	entityID, eventID, ok := instanceParams(c)
+	if !ok {
+		return
+	}
+
+	instances, err := h.service.ListInstances(c.Request.Context(), entityID, eventID)
+	if err != nil {
+		h.instanceError(c, eventID, "list instances", err)
+		return
+	}
+
+	response.Success(c, instances)
+}
+
+// GenerateInstances gera as ocorrências do evento recorrente até "until", preservando as editadas
+// POST /api/v1/events/:id/instances/generate
+func (h *EventHandler) GenerateInstances(c *gin.Context) {
+	entityID, eventID, ok := instanceParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.GenerateInstancesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	instances, err := h.service.GenerateInstances(c.Request.Context(), entityID, eventID, req.Until)
+	if err != nil {
+		h.instanceError(c, eventID, "generate instances", err)
+		return
+	}
+
+	response.Success(c, instances)
+}
+
+// CancelInstance cancela uma única ocorrência (EXDATE)
+// POST /api/v1/events/:id/instances/cancel
+func (h *EventHandler) CancelInstance(c *gin.Context) {
+	entityID, eventID, ok := instanceParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CancelInstanceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	instance, err := h.service.CancelInstance(c.Request.Context(), entityID, eventID, req.Occurrence)
+	if err != nil {
+		h.instanceError(c, eventID, "cancel instance", err)
+		return
+	}
+
+	response.Success(c, instance)
+}
+
+// OverrideInstance altera horário, local ou status de uma única ocorrência
+// PUT /api/v1/events/:id/instances
+func (h *EventHandler) OverrideInstance(c *gin.Context) {
+	entityID, eventID, ok := instanceParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.OverrideInstanceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	instance, err := h.service.OverrideInstance(c.Request.Context(), entityID, eventID, &req)
+	if err != nil {
+		h.instanceError(c, eventID, "override instance", err)
+		return
+	}
+
+	response.Success(c, instance)
+}
+
+// instanceParams lê a entidade do token e o evento da rota
+func instanceParams(c *gin.Context) (entityID, eventID uuid.UUID, ok bool) {
+	entityID, ok = c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return entityID, eventID, false
+	}
+
+	return entityID, eventID, true
+}
+
+func (h *EventHandler) instanceError(c *gin.Context, eventID uuid.UUID, action string, err error) {
+	if response.IsDomainError(err) {
+		response.FromError(c, err)
+		return
+	}
+	h.logger.Error("Failed to "+action,
+		zap.String("event_id", eventID.String()),
+		zap.Error(err),
+	)
+	response.Error(c, http.StatusInternalServerError, "internal_error", "failed to "+action)
+}
+
+// GetReport retorna o relatório consolidado do evento em JSON ou, com ?format=csv, em CSV
+// GET /api/v1/events/:id/report
+func (h *EventHandler) GetReport(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid format, expected json or csv")
+		return
+	}
+
+	report, err := h.service.GenerateReport(c.Request.Context(), entityID, eventID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to generate event report",
+			zap.String("event_id", eventID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to generate event report")
+		return
+	}
+
+	if format == "json" {
+		response.Success(c, report)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		h.logger.Error("Failed to write event report CSV", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to generate event report")
+		return
+	}
+
+	filename := "event-" + eventID.String() + "-report.csv"
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// PreviewBroadcast mostra o broadcast renderizado e quantos confirmados (do evento ou do
+// grupo informado) o receberiam, sem enviar nada
+// POST /api/v1/events/:id/broadcast/preview
+func (h *EventHandler) PreviewBroadcast(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	var req dto.BroadcastPreviewRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	preview, err := h.notifications.PreviewBroadcast(c.Request.Context(), entityID, eventID, req.GroupID, req.Message)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to preview broadcast",
+			zap.String("event_id", eventID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to preview broadcast")
+		return
+	}
+
+	response.Success(c, preview)
+}
+
+// ScheduleBroadcast agenda um broadcast aos confirmados do evento ou de um grupo; sem
+// scheduled_at o worker envia no próximo ciclo
+// POST /api/v1/events/:id/broadcast
+func (h *EventHandler) ScheduleBroadcast(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	var req dto.ScheduleBroadcastRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	scheduler, err := h.service.ScheduleBroadcast(c.Request.Context(), entityID, eventID, &req)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to schedule broadcast",
+			zap.String("event_id", eventID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to schedule broadcast")
+		return
+	}
+
+	response.Created(c, scheduler)
+}
+
+// ListOrganizers lista os co-organizadores do evento
+// GET /api/v1/events/:id/organizers
+func (h *EventHandler) ListOrganizers(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	organizers, err := h.service.ListOrganizers(c.Request.Context(), entityID, eventID)
+	if err != nil {
+		h.instanceError(c, eventID, "list event organizers", err)
+		return
+	}
+
+	response.Success(c, organizers)
+}
+
+// AddOrganizer adiciona um membro da entidade como co-organizador do evento
+// POST /api/v1/events/:id/organizers
+func (h *EventHandler) AddOrganizer(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	actorID, ok := c.MustGet("user_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "invalid user_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	var req dto.AddEventOrganizerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	organizers, err := h.service.AddOrganizer(c.Request.Context(), entityID, eventID, actorID, req.UserID)
+	if err != nil {
+		h.instanceError(c, eventID, "add event organizer", err)
+		return
+	}
+
+	response.Created(c, organizers)
+}
+
+// RemoveOrganizer tira um co-organizador do evento
+// DELETE /api/v1/events/:id/organizers/:user_id
+func (h *EventHandler) RemoveOrganizer(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid user_id")
+		return
+	}
+
+	if err := h.service.RemoveOrganizer(c.Request.Context(), entityID, eventID, userID); err != nil {
+		h.instanceError(c, eventID, "remove event organizer", err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// ListLocations lista os locais do evento; o primeiro é o principal
+// GET /api/v1/events/:id/venues
+func (h *EventHandler) ListLocations(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	locations, err := h.service.ListLocations(c.Request.Context(), entityID, eventID)
+	if err != nil {
+		h.instanceError(c, eventID, "list event locations", err)
+		return
+	}
+
+	response.Success(c, locations)
+}
+
+// AddLocation adiciona um local ao evento
+// POST /api/v1/events/:id/venues
+func (h *EventHandler) AddLocation(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	var req dto.AddEventLocationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	locations, err := h.service.AddLocation(c.Request.Context(), entityID, eventID, &req)
+	if err != nil {
+		h.instanceError(c, eventID, "add event location", err)
+		return
+	}
+
+	response.Created(c, locations)
+}
+
+// UpdateLocation altera ou move um local do evento
+// PATCH /api/v1/events/:id/venues/:location_id
+func (h *EventHandler) UpdateLocation(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	locationID, err := uuid.Parse(c.Param("location_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid location_id")
+		return
+	}
+
+	var req dto.UpdateEventLocationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	locations, err := h.service.UpdateLocation(c.Request.Context(), entityID, eventID, locationID, &req)
+	if err != nil {
+		h.instanceError(c, eventID, "update event location", err)
+		return
+	}
+
+	response.Success(c, locations)
+}
+
+// RemoveLocation tira um local do evento
+// DELETE /api/v1/events/:id/venues/:location_id
+func (h *EventHandler) RemoveLocation(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	locationID, err := uuid.Parse(c.Param("location_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid location_id")
+		return
+	}
+
+	if err := h.service.RemoveLocation(c.Request.Context(), entityID, eventID, locationID); err != nil {
+		h.instanceError(c, eventID, "remove event location", err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// SetTargetLocation escolhe o local usado por geofence e ETA
+// PUT /api/v1/events/:id/venues/target
+func (h *EventHandler) SetTargetLocation(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	var req dto.SetTargetLocationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	locations, err := h.service.SetTargetLocation(c.Request.Context(), entityID, eventID, req.LocationID)
+	if err != nil {
+		h.instanceError(c, eventID, "set event target location", err)
+		return
+	}
+
+	response.Success(c, locations)
+}
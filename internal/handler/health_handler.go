@@ -2,166 +2,94 @@ package handler
 
 import (
 	"context"
+	"database/sql"
 	"net/http"
-	"runtime"
 	"time"
 
+	"event-coming/internal/whatsapp"
+
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
-	"gorm.io/gorm"
+	"go.uber.org/zap"
 )
 
-// HealthHandler handles health check endpoints
+// readinessTimeout bounds each dependency check
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler handles liveness and readiness probes
 type HealthHandler struct {
-	db          *gorm.DB
-	redisClient *redis.Client
-	startTime   time.Time
+	db             *sql.DB
+	redis          *redis.Client
+	whatsappClient *whatsapp.Client
+	logger         *zap.Logger
 }
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler(db *gorm.DB, redisClient *redis.Client) *HealthHandler {
+func NewHealthHandler(db *sql.DB, redisClient *redis.Client, whatsappClient *whatsapp.Client, logger *zap.Logger) *HealthHandler {
 	return &HealthHandler{
-		db:          db,
-		redisClient: redisClient,
-		startTime:   time.Now(),
+		db:             db,
+		redis:          redisClient,
+		whatsappClient: whatsappClient,
+		logger:         logger,
 	}
 }
 
-// HealthStatus represents overall health status
-type HealthStatus struct {
-	Status    string                     `json:"status"`
-	Service   string                     `json:"service"`
-	Version   string                     `json:"version,omitempty"`
-	Uptime    string                     `json:"uptime"`
-	Timestamp time.Time                  `json:"timestamp"`
-	Checks    map[string]ComponentHealth `json:"checks"`
-}
-
-// ComponentHealth represents health of a single component
-type ComponentHealth struct {
-	Status    string `json:"status"`
-	Message   string `json:"message,omitempty"`
-	LatencyMs int64  `json:"latency_ms,omitempty"`
-}
-
-// Health returns basic health status (liveness probe)
-// GET /health
-func (h *HealthHandler) Health(c *gin.Context) {
+// Live handles GET /health/live (the process is up; no dependency checks)
+func (h *HealthHandler) Live(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "ok",
 		"service": "event-coming",
 	})
 }
 
-// Ready returns detailed health status (readiness probe)
-// GET /ready
+// Ready handles GET /health/ready. Returns 503 when Postgres or Redis is unreachable.
 func (h *HealthHandler) Ready(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
-
-	checks := make(map[string]ComponentHealth)
-	allHealthy := true
-
-	// Check PostgreSQL
-	dbHealth := h.checkDatabase(ctx)
-	checks["database"] = dbHealth
-	if dbHealth.Status != "healthy" {
-		allHealthy = false
+	checks := gin.H{
+		"postgres": h.check(c.Request.Context(), "postgres", func(ctx context.Context) error {
+			return h.db.PingContext(ctx)
+		}),
+		"redis": h.check(c.Request.Context(), "redis", func(ctx context.Context) error {
+			return h.redis.Ping(ctx).Err()
+		}),
 	}
 
-	// Check Redis
-	redisHealth := h.checkRedis(ctx)
-	checks["redis"] = redisHealth
-	if redisHealth.Status != "healthy" {
-		allHealthy = false
-	}
-
-	// Build response
-	status := "healthy"
-	httpStatus := http.StatusOK
-	if !allHealthy {
-		status = "unhealthy"
-		httpStatus = http.StatusServiceUnavailable
-	}
-
-	response := HealthStatus{
-		Status:    status,
-		Service:   "event-coming",
-		Version:   "1.0.0",
-		Uptime:    time.Since(h.startTime).String(),
-		Timestamp: time.Now(),
-		Checks:    checks,
-	}
-
-	c.JSON(httpStatus, response)
-}
-
-// Metrics returns basic metrics (could be expanded for Prometheus)
-// GET /metrics
-func (h *HealthHandler) Metrics(c *gin.Context) {
-	var memStats runtime.MemStats
-	runtime.ReadMemStats(&memStats)
-
-	c.JSON(http.StatusOK, gin.H{
-		"goroutines":     runtime.NumGoroutine(),
-		"alloc_mb":       memStats.Alloc / 1024 / 1024,
-		"total_alloc_mb": memStats.TotalAlloc / 1024 / 1024,
-		"sys_mb":         memStats.Sys / 1024 / 1024,
-		"gc_cycles":      memStats.NumGC,
-		"uptime_seconds": time.Since(h.startTime).Seconds(),
-	})
-}
-
-// checkDatabase checks PostgreSQL connectivity
-func (h *HealthHandler) checkDatabase(ctx context.Context) ComponentHealth {
-	if h.db == nil {
-		return ComponentHealth{
-			Status:  "unhealthy",
-			Message: "database not configured",
-		}
-	}
-
-	start := time.Now()
-	sqlDB, err := h.db.DB()
-	if err != nil {
-		return ComponentHealth{
-			Status:  "unhealthy",
-			Message: err.Error(),
+	status := http.StatusOK
+	overall := "ok"
+	for _, result := range checks {
+		if result != "ok" {
+			status = http.StatusServiceUnavailable
+			overall = "unavailable"
 		}
 	}
 
-	if err := sqlDB.PingContext(ctx); err != nil {
-		return ComponentHealth{
-			Status:  "unhealthy",
-			Message: err.Error(),
+	// Informativo: não afeta a prontidão. Com o circuit breaker aberto os envios falham
+	// sem chamar a API até o fim do cooldown
+	whatsappStatus := "unconfigured"
+	if h.whatsappClient != nil {
+		whatsappStatus = "configured"
+		if state := h.whatsappClient.CircuitState(); state != whatsapp.CircuitClosed {
+			whatsappStatus = "circuit_" + string(state)
 		}
 	}
 
-	return ComponentHealth{
-		Status:    "healthy",
-		LatencyMs: time.Since(start).Milliseconds(),
-	}
+	c.JSON(status, gin.H{
+		"status": overall,
+		"checks": checks,
+		"info": gin.H{
+			"whatsapp": whatsappStatus,
+		},
+	})
 }
 
-// checkRedis checks Redis connectivity
-func (h *HealthHandler) checkRedis(ctx context.Context) ComponentHealth {
-	if h.redisClient == nil {
-		return ComponentHealth{
-			Status:  "unhealthy",
-			Message: "redis not configured",
-		}
-	}
-
-	start := time.Now()
-	if err := h.redisClient.Ping(ctx).Err(); err != nil {
-		return ComponentHealth{
-			Status:  "unhealthy",
-			Message: err.Error(),
-		}
-	}
+// check runs a dependency ping with a timeout and returns "ok" or "unavailable". The
+// error is only logged: the probe is public and must not expose connection details
+func (h *HealthHandler) check(ctx context.Context, name string, ping func(ctx context.Context) error) string {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
 
-	return ComponentHealth{
-		Status:    "healthy",
-		LatencyMs: time.Since(start).Milliseconds(),
+	if err := ping(ctx); err != nil {
+		h.logger.Warn("Readiness check failed", zap.String("dependency", name), zap.Error(err))
+		return "unavailable"
 	}
+	return "ok"
 }
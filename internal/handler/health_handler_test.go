@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/whatsapp"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// pingDriver is a database/sql driver whose connections only answer pings
+type pingDriver struct {
+	err error
+}
+
+func (d pingDriver) Open(string) (driver.Conn, error) {
+	return pingConn{err: d.err}, nil
+}
+
+type pingConn struct {
+	err error
+}
+
+func (c pingConn) Ping(context.Context) error               { return c.err }
+func (c pingConn) Prepare(string) (driver.Stmt, error)      { return nil, errors.New("not supported") }
+func (c pingConn) Close() error                             { return nil }
+func (c pingConn) Begin() (driver.Tx, error)                { return nil, errors.New("not supported") }
+func (c pingConn) ResetSession(context.Context) error       { return c.err }
+func (c pingConn) IsValid() bool                            { return c.err == nil }
+func (c pingConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func init() {
+	sql.Register("health-ok", pingDriver{})
+	sql.Register("health-down", pingDriver{err: errors.New("dial tcp 10.0.0.5:5432: connection refused")})
+}
+
+func newTestHealthRouter(t *testing.T, dbDriver string, redisUp bool, whatsappClient *whatsapp.Client) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := sql.Open(dbDriver, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	if !redisUp {
+		mr.Close()
+	}
+
+	h := NewHealthHandler(db, client, whatsappClient, zap.NewNop())
+	router := gin.New()
+	router.GET("/health/live", h.Live)
+	router.GET("/health/ready", h.Ready)
+	return router
+}
+
+func getHealth(t *testing.T, router *gin.Engine, path string) (int, map[string]any) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestHealthHandler_Ready_AllUp(t *testing.T) {
+	client := whatsapp.NewClient(&config.WhatsAppConfig{})
+	router := newTestHealthRouter(t, "health-ok", true, client)
+
+	code, body := getHealth(t, router, "/health/ready")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+	assert.Equal(t, map[string]any{"postgres": "ok", "redis": "ok"}, body["checks"])
+	assert.Equal(t, map[string]any{"whatsapp": "configured"}, body["info"])
+}
+
+func TestHealthHandler_Ready_RedisDown(t *testing.T) {
+	router := newTestHealthRouter(t, "health-ok", false, nil)
+
+	code, body := getHealth(t, router, "/health/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", body["status"])
+	assert.Equal(t, map[string]any{"postgres": "ok", "redis": "unavailable"}, body["checks"])
+	assert.Equal(t, map[string]any{"whatsapp": "unconfigured"}, body["info"])
+}
+
+func TestHealthHandler_Ready_PostgresDownDoesNotExposeError(t *testing.T) {
+	router := newTestHealthRouter(t, "health-down", true, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"postgres":"unavailable"`)
+	assert.NotContains(t, w.Body.String(), "10.0.0.5")
+}
+
+func TestHealthHandler_Live_IgnoresDependencies(t *testing.T) {
+	router := newTestHealthRouter(t, "health-down", false, nil)
+
+	code, body := getHealth(t, router, "/health/live")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+}
+
+func TestHealthHandler_Ready_ReportsOpenWhatsAppCircuit(t *testing.T) {
+	breaker := whatsapp.NewCircuitBreaker(1, time.Minute)
+	breaker.Failure()
+	client := whatsapp.NewClientWithBreaker(&config.WhatsAppConfig{}, breaker)
+	router := newTestHealthRouter(t, "health-ok", true, client)
+
+	code, body := getHealth(t, router, "/health/ready")
+	assert.Equal(t, http.StatusOK, code, "the WhatsApp circuit doesn't affect readiness")
+	assert.Equal(t, map[string]any{"whatsapp": "circuit_open"}, body["info"])
+}
@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
 	"event-coming/internal/service"
 	"event-coming/internal/service/eta"
+	"event-coming/pkg/pagination"
 	"event-coming/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -50,15 +53,47 @@ func (h *LocationHandler) CreateLocation(c *gin.Context) {
 	}
 
 	var req dto.CreateLocationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	result, err := h.locationService.CreateLocation(c.Request.Context(), participantID, entityID.(uuid.UUID), &req)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "Participant not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+
+	response.Created(c, result)
+}
+
+// CreateLocationsBatch saves the locations a participant's app buffered while offline
+// POST /participants/:id/locations/batch
+func (h *LocationHandler) CreateLocationsBatch(c *gin.Context) {
+	participantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid participant ID")
+		return
+	}
+
+	entityID, exists := c.Get("entity_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "Entity not found in context")
+		return
+	}
+
+	var req dto.BatchCreateLocationsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.locationService.CreateLocationsBatch(c.Request.Context(), participantID, entityID.(uuid.UUID), req.Locations)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
@@ -135,8 +170,8 @@ func (h *LocationHandler) GetLatestLocation(c *gin.Context) {
 		entityID.(uuid.UUID),
 	)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "Location not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
@@ -147,7 +182,7 @@ func (h *LocationHandler) GetLatestLocation(c *gin.Context) {
 }
 
 // GetEventLocations gets latest locations for all participants in an event
-// GET /events/:id/locations
+// GET /events/:id/locations?sort=distance
 func (h *LocationHandler) GetEventLocations(c *gin.Context) {
 	eventID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -161,10 +196,16 @@ func (h *LocationHandler) GetEventLocations(c *gin.Context) {
 		return
 	}
 
+	byDistance, ok := locationSortByDistance(c)
+	if !ok {
+		return
+	}
+
 	locations, err := h.locationService.GetEventLocations(
 		c.Request.Context(),
 		eventID,
 		entityID.(uuid.UUID),
+		byDistance,
 	)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
@@ -174,6 +215,74 @@ func (h *LocationHandler) GetEventLocations(c *gin.Context) {
 	response.Success(c, locations)
 }
 
+// GetLiveEventLocations gets a live location snapshot for all participants in an event,
+// or for one page of them when page or per_page is given
+// GET /events/:id/locations/live[?sort=distance&page=1&per_page=20]
+func (h *LocationHandler) GetLiveEventLocations(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid event ID")
+		return
+	}
+
+	entityID, exists := c.Get("entity_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "Entity not found in context")
+		return
+	}
+
+	byDistance, ok := locationSortByDistance(c)
+	if !ok {
+		return
+	}
+
+	// Without page/per_page the whole snapshot is returned, as polling maps expect
+	var page, perPage int
+	paginated := c.Query("page") != "" || c.Query("per_page") != ""
+	if paginated {
+		page, _ = strconv.Atoi(c.Query("page"))
+		perPage, _ = strconv.Atoi(c.Query("per_page"))
+		page, perPage = pagination.Normalize(page, perPage)
+	}
+
+	snapshot, total, err := h.locationService.GetLiveEventLocations(
+		c.Request.Context(),
+		entityID.(uuid.UUID),
+		eventID,
+		byDistance,
+		page,
+		perPage,
+	)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+
+	if !paginated {
+		response.Success(c, snapshot)
+		return
+	}
+	response.Paginated(c, snapshot, page, perPage, total)
+}
+
+// locationSortByDistance reads the sort query param of the event location views; only
+// "distance" is accepted
+func locationSortByDistance(c *gin.Context) (bool, bool) {
+	switch c.Query("sort") {
+	case "":
+		return false, true
+	case dto.LocationSortDistance:
+		return true, true
+	default:
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid sort, expected distance")
+		return false, false
+	}
+}
+
 // GetParticipantETA gets ETA for a participant to reach event location
 // GET /eta/participants/:id
 func (h *LocationHandler) GetParticipantETA(c *gin.Context) {
@@ -201,19 +310,14 @@ func (h *LocationHandler) GetParticipantETA(c *gin.Context) {
 		return
 	}
 
-	// Get event to get target location
-	event, err := h.eventService.GetByID(c.Request.Context(), entityID.(uuid.UUID), eventID)
-	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "Event not found")
-			return
-		}
-		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
+	locationID, ok := parseLocationIDQuery(c)
+	if !ok {
 		return
 	}
 
-	if event.LocationLat == 0 && event.LocationLng == 0 {
-		response.Error(c, http.StatusBadRequest, "bad_request", "Event does not have a location defined")
+	// Destination: the chosen location, or the event's target location
+	lat, lng, ok := h.destination(c, entityID.(uuid.UUID), eventID, locationID)
+	if !ok {
 		return
 	}
 
@@ -221,8 +325,8 @@ func (h *LocationHandler) GetParticipantETA(c *gin.Context) {
 		c.Request.Context(),
 		participantID,
 		entityID.(uuid.UUID),
-		event.LocationLat,
-		event.LocationLng,
+		lat,
+		lng,
 	)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
@@ -233,6 +337,41 @@ func (h *LocationHandler) GetParticipantETA(c *gin.Context) {
 	response.Success(c, etaResponse)
 }
 
+// parseLocationIDQuery reads the optional location_id query parameter, one of the
+// event's locations to compute the ETA to
+func parseLocationIDQuery(c *gin.Context) (*uuid.UUID, bool) {
+	raw := c.Query("location_id")
+	if raw == "" {
+		return nil, true
+	}
+
+	locationID, err := uuid.Parse(raw)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "Invalid location ID")
+		return nil, false
+	}
+	return &locationID, true
+}
+
+// destination resolves the coordinates the ETA is computed to, writing the error
+// response when it can't
+func (h *LocationHandler) destination(c *gin.Context, entityID, eventID uuid.UUID, locationID *uuid.UUID) (float64, float64, bool) {
+	lat, lng, err := h.eventService.Destination(c.Request.Context(), entityID, eventID, locationID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.Error(c, http.StatusBadRequest, "bad_request", "Event does not have a location defined")
+			return 0, 0, false
+		}
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return 0, 0, false
+		}
+		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
 // GetEventETAs gets ETAs for all participants in an event
 // GET /eta/events/:id
 func (h *LocationHandler) GetEventETAs(c *gin.Context) {
@@ -251,16 +390,21 @@ func (h *LocationHandler) GetEventETAs(c *gin.Context) {
 	// Get event with participants
 	event, err := h.eventService.GetByIDWithParticipants(c.Request.Context(), entityID.(uuid.UUID), eventID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			response.Error(c, http.StatusNotFound, "not_found", "Event not found")
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
 		return
 	}
 
-	if event.LocationLat == 0 && event.LocationLng == 0 {
-		response.Error(c, http.StatusBadRequest, "bad_request", "Event does not have a location defined")
+	locationID, ok := parseLocationIDQuery(c)
+	if !ok {
+		return
+	}
+
+	lat, lng, ok := h.destination(c, entityID.(uuid.UUID), eventID, locationID)
+	if !ok {
 		return
 	}
 
@@ -275,8 +419,8 @@ func (h *LocationHandler) GetEventETAs(c *gin.Context) {
 		c.Request.Context(),
 		participantIDs,
 		entityID.(uuid.UUID),
-		event.LocationLat,
-		event.LocationLng,
+		lat,
+		lng,
 	)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
@@ -1,8 +1,13 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
+	"event-coming/internal/cache"
 	"event-coming/internal/config"
 	"event-coming/internal/domain"
 	"event-coming/pkg/response"
@@ -10,19 +15,26 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
-// RoleHierarchy maps roles to their permission levels
-var RoleHierarchy = map[domain.UserRole]int{
-	domain.UserRoleSuperAdmin:    100,
-	domain.UserRoleEntityOwner:   50,
-	domain.UserRoleEntityAdmin:   40,
-	domain.UserRoleEntityManager: 30,
-	domain.UserRoleEntityViewer:  10,
+// RoleHierarchy maps roles to their permission levels (see domain.RoleLevels)
+var RoleHierarchy = domain.RoleLevels
+
+// APIKeyAuthenticator resolves an entity API key ("sk_...") to its stored key
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*domain.APIKey, error)
+}
+
+// EventManagerChecker tells whether a user may manage an event without an admin role:
+// its creator or one of its co-organizers
+type EventManagerChecker interface {
+	CanManageEvent(ctx context.Context, entID, eventID, userID uuid.UUID) (bool, error)
 }
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
+// AuthMiddleware validates JWT tokens and, when apiKeys is set, entity API keys.
+// Both paths set entity_id, role and user_id (for keys, the user who created them)
+func AuthMiddleware(cfg *config.JWTConfig, denylist *cache.TokenDenylist, apiKeys APIKeyAuthenticator, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -41,21 +53,36 @@ func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(cfg.AccessSecret), nil
-		})
+		if apiKeys != nil && domain.IsAPIKey(tokenString) {
+			key, err := apiKeys.Authenticate(c.Request.Context(), tokenString)
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidToken) {
+					response.Error(c, 401, "unauthorized", "Invalid API key")
+				} else {
+					response.Error(c, 500, "internal_error", "Failed to verify API key")
+				}
+				c.Abort()
+				return
+			}
 
-		if err != nil || !token.Valid {
-			response.Error(c, 401, "unauthorized", "Invalid token")
-			c.Abort()
+			c.Set("api_key_id", key.ID)
+			c.Set("user_id", key.CreatedBy)
+			c.Set("entity_id", key.EntityID)
+			c.Set("role", key.Role)
+			c.Request = c.Request.WithContext(domain.WithActor(c.Request.Context(), key.CreatedBy))
+
+			c.Next()
 			return
 		}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			response.Error(c, 401, "unauthorized", "Invalid token claims")
+		// Parse and validate token
+		claims, err := ValidateAccessToken(c.Request.Context(), cfg, denylist, tokenString, logger)
+		if err != nil {
+			if errors.Is(err, domain.ErrTokenCheckUnavailable) {
+				response.FromError(c, err)
+			} else {
+				response.Error(c, 401, "unauthorized", "Invalid token")
+			}
 			c.Abort()
 			return
 		}
@@ -64,6 +91,8 @@ func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
 		if userIDStr, ok := claims["user_id"].(string); ok {
 			if userID, err := uuid.Parse(userIDStr); err == nil {
 				c.Set("user_id", userID)
+				// Makes the actor available to services (audit log)
+				c.Request = c.Request.WithContext(domain.WithActor(c.Request.Context(), userID))
 			}
 		}
 
@@ -81,10 +110,102 @@ func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
 			c.Set("role", domain.UserRole(role))
 		}
 
+		// Impersonation tokens carry the super admin in the "act" claim; the audit log
+		// records them alongside the impersonated user
+		if impersonatorID, ok := impersonatorFromClaims(claims); ok {
+			c.Set("impersonator_id", impersonatorID)
+			c.Request = c.Request.WithContext(domain.WithImpersonator(c.Request.Context(), impersonatorID))
+		}
+
 		c.Next()
 	}
 }
 
+// impersonatorFromClaims returns the actor of an impersonation token ("act": {"sub": ...})
+func impersonatorFromClaims(claims jwt.MapClaims) (uuid.UUID, bool) {
+	act, ok := claims["act"].(map[string]interface{})
+	if !ok {
+		return uuid.Nil, false
+	}
+	sub, ok := act["sub"].(string)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ParseAccessToken validates an access token against the key selected by its
+// "kid" header and returns its claims
+func ParseAccessToken(cfg *config.JWTConfig, tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, domain.ErrInvalidToken
+		}
+		kid, _ := token.Header["kid"].(string)
+		secret, ok := cfg.VerificationKey(kid)
+		if !ok {
+			return nil, domain.ErrInvalidToken
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, domain.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// ValidateAccessToken parses the access token and rejects it if it was revoked
+// (logout, password reset). If the denylist can't be reached the error is logged and the
+// token is rejected with ErrTokenCheckUnavailable, unless cfg.DenylistFailOpen accepts it.
+func ValidateAccessToken(ctx context.Context, cfg *config.JWTConfig, denylist *cache.TokenDenylist, tokenString string, logger *zap.Logger) (jwt.MapClaims, error) {
+	claims, err := ParseAccessToken(cfg, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if denylist == nil {
+		return claims, nil
+	}
+
+	jti, _ := claims["jti"].(string)
+	userIDStr, _ := claims["user_id"].(string)
+	userID, _ := uuid.Parse(userIDStr)
+
+	var issuedAt time.Time
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		issuedAt = iat.Time
+	}
+
+	revoked, err := denylist.IsRevoked(ctx, jti, userID, issuedAt)
+	if err != nil {
+		if logger != nil {
+			logger.Error("Failed to check token revocation",
+				zap.Bool("fail_open", cfg.DenylistFailOpen),
+				zap.Error(err),
+			)
+		}
+		if cfg.DenylistFailOpen {
+			return claims, nil
+		}
+		return nil, fmt.Errorf("%w: %v", domain.ErrTokenCheckUnavailable, err)
+	}
+	if revoked {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
 // RequireRole checks if the user has at least the required role level
 func RequireRole(requiredRole domain.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -169,3 +290,57 @@ func RequireEntityAccess() gin.HandlerFunc {
 func RequireOwnerOrAdmin() gin.HandlerFunc {
 	return RequireRole(domain.UserRoleEntityAdmin)
 }
+
+// RequireEventManager allows managing the event in the :id route param to entity admins
+// (and above) by role. Entity managers are deferred to the event-level check, which
+// accepts the event's creator and co-organizers; viewers are always rejected. API keys
+// are judged by their own role only: user_id is the key's creator, whose events the key
+// doesn't get to manage
+func RequireEventManager(checker EventManagerChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		userRole, _ := role.(domain.UserRole)
+		if HasPermission(userRole, domain.UserRoleEntityAdmin) {
+			c.Next()
+			return
+		}
+
+		_, isAPIKey := c.Get("api_key_id")
+		if isAPIKey || !HasPermission(userRole, domain.UserRoleEntityManager) {
+			response.Error(c, 403, "forbidden", "Insufficient permissions")
+			c.Abort()
+			return
+		}
+
+		entityValue, _ := c.Get("entity_id")
+		userValue, _ := c.Get("user_id")
+		entityID, okEntity := entityValue.(uuid.UUID)
+		userID, okUser := userValue.(uuid.UUID)
+		if !okEntity || !okUser {
+			response.Error(c, 403, "forbidden", "No event access")
+			c.Abort()
+			return
+		}
+
+		eventID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			response.Error(c, 400, "bad_request", "Invalid event ID")
+			c.Abort()
+			return
+		}
+
+		allowed, err := checker.CanManageEvent(c.Request.Context(), entityID, eventID, userID)
+		if err != nil {
+			response.FromError(c, err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			response.Error(c, 403, "forbidden", "Only the event's creator, co-organizers or entity admins can manage it")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
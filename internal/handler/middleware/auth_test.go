@@ -0,0 +1,352 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestJWTConfig has "current" signing new tokens and "previous" kept for verification
+func newTestJWTConfig() *config.JWTConfig {
+	return &config.JWTConfig{
+		AccessSecret: "legacy-secret",
+		Keys: []config.JWTKey{
+			{KID: "current", Secret: "current-secret", Active: true},
+			{KID: "previous", Secret: "previous-secret"},
+		},
+		ActiveKID: "current",
+	}
+}
+
+func signTestToken(t *testing.T, kid, secret string) string {
+	t.Helper()
+
+	return signTestClaims(t, kid, secret, jwt.MapClaims{
+		"user_id": uuid.New().String(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+func signTestClaims(t *testing.T, kid, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString([]byte(secret))
+	require.NoError(t, err)
+	return signed
+}
+
+func authenticate(cfg *config.JWTConfig, token string) int {
+	return authenticateWithDenylist(cfg, nil, token)
+}
+
+func authenticateWithDenylist(cfg *config.JWTConfig, denylist *cache.TokenDenylist, token string) int {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(cfg, denylist, nil, zap.NewNop()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAuthMiddleware_KeyRotation(t *testing.T) {
+	cfg := newTestJWTConfig()
+
+	tests := []struct {
+		name   string
+		kid    string
+		secret string
+		want   int
+	}{
+		{name: "active key", kid: "current", secret: "current-secret", want: http.StatusOK},
+		{name: "retired but still valid key", kid: "previous", secret: "previous-secret", want: http.StatusOK},
+		{name: "token without kid uses the access secret", secret: "legacy-secret", want: http.StatusOK},
+		{name: "unknown kid", kid: "removed", secret: "removed-secret", want: http.StatusUnauthorized},
+		{name: "kid with another key's secret", kid: "previous", secret: "current-secret", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, authenticate(cfg, signTestToken(t, tt.kid, tt.secret)))
+		})
+	}
+}
+
+func TestAuthMiddleware_RemovedKeyIsRejected(t *testing.T) {
+	cfg := newTestJWTConfig()
+	token := signTestToken(t, "previous", "previous-secret")
+	require.Equal(t, http.StatusOK, authenticate(cfg, token))
+
+	// Rotation finished: the old key leaves the set
+	cfg.Keys = cfg.Keys[:1]
+	assert.Equal(t, http.StatusUnauthorized, authenticate(cfg, token))
+}
+
+func newTestDenylist(t *testing.T) *cache.TokenDenylist {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return cache.NewTokenDenylist(client, "denylist:")
+}
+
+func TestAuthMiddleware_DenylistedTokenIsRejected(t *testing.T) {
+	ctx := context.Background()
+	cfg := newTestJWTConfig()
+	denylist := newTestDenylist(t)
+
+	claims := jwt.MapClaims{
+		"user_id": uuid.New().String(),
+		"jti":     uuid.New().String(),
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+	token := signTestClaims(t, "current", "current-secret", claims)
+	require.Equal(t, http.StatusOK, authenticateWithDenylist(cfg, denylist, token))
+
+	// Logout: only this token is revoked
+	require.NoError(t, denylist.Revoke(ctx, claims["jti"].(string), time.Hour))
+	assert.Equal(t, http.StatusUnauthorized, authenticateWithDenylist(cfg, denylist, token))
+
+	claims["jti"] = uuid.New().String()
+	assert.Equal(t, http.StatusOK, authenticateWithDenylist(cfg, denylist, signTestClaims(t, "current", "current-secret", claims)))
+}
+
+func TestAuthMiddleware_TokensIssuedBeforeUserRevocationAreRejected(t *testing.T) {
+	ctx := context.Background()
+	cfg := newTestJWTConfig()
+	denylist := newTestDenylist(t)
+	userID := uuid.New()
+
+	tokenAt := func(iat time.Time) string {
+		return signTestClaims(t, "current", "current-secret", jwt.MapClaims{
+			"user_id": userID.String(),
+			"jti":     uuid.New().String(),
+			"iat":     iat.Unix(),
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+	}
+
+	// Password reset or deactivation revokes every token issued before it
+	require.NoError(t, denylist.RevokeUser(ctx, userID, time.Hour))
+	assert.Equal(t, http.StatusUnauthorized, authenticateWithDenylist(cfg, denylist, tokenAt(time.Now().Add(-time.Minute))))
+
+	// Tokens issued afterwards, e.g. the login right after the reset, keep working
+	assert.Equal(t, http.StatusOK, authenticateWithDenylist(cfg, denylist, tokenAt(time.Now().Add(time.Second))))
+}
+
+func TestAuthMiddleware_UnreachableDenylist(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	denylist := cache.NewTokenDenylist(client, "denylist:")
+
+	token := signTestClaims(t, "current", "current-secret", jwt.MapClaims{
+		"user_id": uuid.New().String(),
+		"jti":     uuid.New().String(),
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	mr.SetError("connection lost")
+
+	// Fails closed by default: a revoked token must not work while Redis is down
+	cfg := newTestJWTConfig()
+	assert.Equal(t, http.StatusServiceUnavailable, authenticateWithDenylist(cfg, denylist, token))
+
+	cfg.DenylistFailOpen = true
+	assert.Equal(t, http.StatusOK, authenticateWithDenylist(cfg, denylist, token))
+}
+
+// stubAPIKeys authenticates a fixed set of keys; revoked ones are removed from the map
+type stubAPIKeys struct {
+	keys map[string]*domain.APIKey
+	err  error
+}
+
+func (s *stubAPIKeys) Authenticate(ctx context.Context, rawKey string) (*domain.APIKey, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	key, ok := s.keys[rawKey]
+	if !ok {
+		return nil, domain.ErrInvalidToken
+	}
+	return key, nil
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		EntityID:  uuid.New(),
+		Role:      domain.UserRoleEntityManager,
+		CreatedBy: uuid.New(),
+	}
+	apiKeys := &stubAPIKeys{keys: map[string]*domain.APIKey{"sk_valid": key}}
+
+	var gotEntity, gotUser uuid.UUID
+	var gotRole domain.UserRole
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(newTestJWTConfig(), nil, apiKeys, zap.NewNop()), func(c *gin.Context) {
+		gotEntity = c.MustGet("entity_id").(uuid.UUID)
+		gotUser = c.MustGet("user_id").(uuid.UUID)
+		gotRole = c.MustGet("role").(domain.UserRole)
+		c.Status(http.StatusOK)
+	})
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Same context keys as the JWT path, acting as the key's creator
+	require.Equal(t, http.StatusOK, call("sk_valid"))
+	assert.Equal(t, key.EntityID, gotEntity)
+	assert.Equal(t, key.CreatedBy, gotUser)
+	assert.Equal(t, domain.UserRoleEntityManager, gotRole)
+
+	assert.Equal(t, http.StatusUnauthorized, call("sk_unknown"))
+
+	// Revoked keys stop working on the next request
+	delete(apiKeys.keys, "sk_valid")
+	assert.Equal(t, http.StatusUnauthorized, call("sk_valid"))
+
+	// A lookup failure isn't reported as bad credentials
+	apiKeys.err = errors.New("connection refused")
+	assert.Equal(t, http.StatusInternalServerError, call("sk_valid"))
+}
+
+func TestAuthMiddleware_SurfacesImpersonator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := newTestJWTConfig()
+
+	var gotUser, gotActor uuid.UUID
+	var gotImpersonator *uuid.UUID
+	var hasImpersonator bool
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(cfg, nil, nil, zap.NewNop()), func(c *gin.Context) {
+		gotUser = c.MustGet("user_id").(uuid.UUID)
+		gotActor = *domain.ActorFromContext(c.Request.Context())
+		_, hasImpersonator = c.Get("impersonator_id")
+		gotImpersonator = domain.ImpersonatorFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	call := func(claims jwt.MapClaims) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+signTestClaims(t, "current", "current-secret", claims))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	userID, adminID := uuid.New(), uuid.New()
+	require.Equal(t, http.StatusOK, call(jwt.MapClaims{
+		"sub":     userID.String(),
+		"user_id": userID.String(),
+		"act":     map[string]interface{}{"sub": adminID.String()},
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}))
+	assert.Equal(t, userID, gotUser, "requests act as the impersonated user")
+	assert.Equal(t, userID, gotActor)
+	assert.True(t, hasImpersonator)
+	require.NotNil(t, gotImpersonator)
+	assert.Equal(t, adminID, *gotImpersonator)
+
+	// Regular tokens have no impersonator
+	require.Equal(t, http.StatusOK, call(jwt.MapClaims{
+		"user_id": userID.String(),
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}))
+	assert.False(t, hasImpersonator)
+	assert.Nil(t, gotImpersonator)
+}
+
+// stubEventManagers allows the users in managers; err fails every check
+type stubEventManagers struct {
+	managers map[uuid.UUID]bool
+	err      error
+	calls    int
+}
+
+func (s *stubEventManagers) CanManageEvent(ctx context.Context, entID, eventID, userID uuid.UUID) (bool, error) {
+	s.calls++
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.managers[userID], nil
+}
+
+func TestRequireEventManager(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	coOrganizer, stranger := uuid.New(), uuid.New()
+	checker := &stubEventManagers{managers: map[uuid.UUID]bool{coOrganizer: true}}
+
+	call := func(userID uuid.UUID, role domain.UserRole, apiKey bool) int {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("entity_id", uuid.New())
+			c.Set("user_id", userID)
+			c.Set("role", role)
+			if apiKey {
+				c.Set("api_key_id", uuid.New())
+			}
+		})
+		router.PUT("/events/:id", RequireEventManager(checker), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodPut, "/events/"+uuid.NewString(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Managers manage the events they created or co-organize
+	assert.Equal(t, http.StatusOK, call(coOrganizer, domain.UserRoleEntityManager, false))
+	assert.Equal(t, http.StatusForbidden, call(stranger, domain.UserRoleEntityManager, false))
+
+	// Viewers can't, not even their own events
+	calls := checker.calls
+	assert.Equal(t, http.StatusForbidden, call(coOrganizer, domain.UserRoleEntityViewer, false))
+
+	// A key is judged by its own role, not by the events of the user who created it
+	assert.Equal(t, http.StatusForbidden, call(coOrganizer, domain.UserRoleEntityViewer, true))
+	assert.Equal(t, http.StatusForbidden, call(coOrganizer, domain.UserRoleEntityManager, true))
+	assert.Equal(t, http.StatusOK, call(stranger, domain.UserRoleEntityAdmin, true))
+
+	// Entity admins are allowed by role, without the event-level check
+	assert.Equal(t, http.StatusOK, call(stranger, domain.UserRoleEntityAdmin, false))
+	assert.Equal(t, calls, checker.calls)
+
+	checker.err = domain.ErrNotFound
+	assert.Equal(t, http.StatusNotFound, call(coOrganizer, domain.UserRoleEntityManager, false))
+}
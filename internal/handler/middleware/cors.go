@@ -1,22 +1,72 @@
 package middleware
 
 import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"event-coming/internal/config"
+
 	"github.com/gin-gonic/gin"
 )
 
-// CORS sets up CORS headers
-func CORS() gin.HandlerFunc {
+// CORS sets up CORS headers. The request Origin is echoed back (with credentials)
+// only when it is in the configured allowlist; a "*" entry allows any origin
+// without credentials. With no origins configured, debug mode allows localhost.
+func CORS(cfg *config.ServerConfig, debug bool) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(cfg.CORSOrigins))
+	wildcard := false
+	for _, origin := range cfg.CORSOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "*" {
+			wildcard = true
+			continue
+		}
+		if origin != "" {
+			allowed[origin] = true
+		}
+	}
+	localhostFallback := debug && len(allowed) == 0 && !wildcard
+
+	methods := strings.Join(cfg.CORSMethods, ", ")
+	headers := strings.Join(cfg.CORSHeaders, ", ")
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		origin := c.GetHeader("Origin")
+		h := c.Writer.Header()
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if origin != "" {
+			h.Add("Vary", "Origin")
+
+			switch {
+			case allowed[origin] || (localhostFallback && isLocalhostOrigin(origin)):
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Credentials", "true")
+			case wildcard:
+				h.Set("Access-Control-Allow-Origin", "*")
+			}
+
+			if h.Get("Access-Control-Allow-Origin") != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+				h.Set("Access-Control-Allow-Methods", methods)
+			}
+		}
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
 		c.Next()
 	}
 }
+
+// isLocalhostOrigin reports whether origin points to localhost (any port)
+func isLocalhostOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"event-coming/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCORSRouter(origins []string, debug bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CORS(&config.ServerConfig{
+		CORSOrigins: origins,
+		CORSMethods: []string{"GET", "POST"},
+		CORSHeaders: []string{"Authorization", "Content-Type"},
+	}, debug))
+	router.GET("/events", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func requestWithOrigin(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/events", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS_AllowedOriginIsEchoed(t *testing.T) {
+	router := newTestCORSRouter([]string{"https://app.example.com/"}, false)
+
+	w := requestWithOrigin(router, http.MethodGet, "https://app.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+}
+
+func TestCORS_DisallowedOriginIsNotReflected(t *testing.T) {
+	router := newTestCORSRouter([]string{"https://app.example.com"}, false)
+
+	w := requestWithOrigin(router, http.MethodGet, "https://evil.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORS_WildcardNeverAllowsCredentials(t *testing.T) {
+	router := newTestCORSRouter([]string{"*"}, false)
+
+	w := requestWithOrigin(router, http.MethodGet, "https://any.example.com")
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_LocalhostFallbackOnlyInDebug(t *testing.T) {
+	debug := newTestCORSRouter(nil, true)
+	assert.Equal(t, "http://localhost:3000", requestWithOrigin(debug, http.MethodGet, "http://localhost:3000").Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, requestWithOrigin(debug, http.MethodGet, "https://app.example.com").Header().Get("Access-Control-Allow-Origin"))
+
+	production := newTestCORSRouter(nil, false)
+	assert.Empty(t, requestWithOrigin(production, http.MethodGet, "http://localhost:3000").Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_PreflightIsAnswered(t *testing.T) {
+	router := newTestCORSRouter([]string{"https://app.example.com"}, false)
+
+	w := requestWithOrigin(router, http.MethodOptions, "https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// idempotencyLockTTL bounds how long a request holds the key while it is being processed
+const idempotencyLockTTL = time.Minute
+
+// maxIdempotentBody caps the body read for the fingerprint; the idempotent routes take
+// JSON, and the largest (a 500-point location batch) stays well below it
+const maxIdempotentBody = 1 << 20
+
+// storedResponse is the response replayed for a repeated Idempotency-Key
+type storedResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// responseRecorder captures the response body while writing it to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response when a POST is retried with the same
+// Idempotency-Key header, so creates aren't duplicated. Keys are scoped by entity and
+// request path (so /events/A and /events/B don't share keys). Reusing a key with a different body returns 422; a retry while the first
+// request is still running returns 409. Should be used after AuthMiddleware.
+func Idempotency(store *cache.IdempotencyStore, ttl time.Duration, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader("Idempotency-Key")
+		if idempotencyKey == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				response.Error(c, http.StatusRequestEntityTooLarge, "request_too_large", "Request body is too large")
+			} else {
+				response.Error(c, http.StatusBadRequest, "bad_request", "Failed to read body")
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+
+		entityID, _ := c.Get("entity_id")
+		key := fmt.Sprintf("%v:%s:%s", entityID, c.Request.URL.Path, idempotencyKey)
+		ctx := c.Request.Context()
+
+		stored, found, err := store.Load(ctx, "response:"+key)
+		if err != nil {
+			// Redis indisponível: processa normalmente
+			logger.Warn("Idempotency store unavailable", zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if found {
+			var resp storedResponse
+			if err := json.Unmarshal(stored, &resp); err == nil {
+				if resp.Fingerprint != fingerprint {
+					response.Error(c, http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency-Key was already used with a different request body")
+					c.Abort()
+					return
+				}
+
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(resp.Status, resp.ContentType, resp.Body)
+				c.Abort()
+				return
+			}
+		}
+
+		claimed, err := store.Claim(ctx, "lock:"+key, idempotencyLockTTL)
+		if err != nil {
+			logger.Warn("Idempotency store unavailable", zap.Error(err))
+			c.Next()
+			return
+		}
+		if !claimed {
+			response.Error(c, http.StatusConflict, "idempotency_in_progress", "A request with this Idempotency-Key is still being processed")
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Server errors are not stored so the client can retry
+		if status := recorder.Status(); status < http.StatusInternalServerError {
+			data, _ := json.Marshal(storedResponse{
+				Fingerprint: fingerprint,
+				Status:      status,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        recorder.body.Bytes(),
+			})
+			if err := store.Store(ctx, "response:"+key, data, ttl); err != nil {
+				logger.Warn("Failed to store idempotent response", zap.Error(err))
+			}
+		}
+
+		if err := store.Release(ctx, "lock:"+key); err != nil {
+			logger.Warn("Failed to release idempotency lock", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestIdempotencyRouter counts how many times the create handler actually runs
+func newTestIdempotencyRouter(t *testing.T) (*gin.Engine, *int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := cache.NewIdempotencyStore(client, "idempotency:")
+	calls := 0
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("entity_id", "entity-1")
+	})
+	router.POST("/events", Idempotency(store, time.Hour, zap.NewNop()), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": strconv.Itoa(calls)})
+	})
+	return router, &calls
+}
+
+func postEvent(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	router, calls := newTestIdempotencyRouter(t)
+
+	first := postEvent(router, "key-1", `{"name": "Event"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	second := postEvent(router, "key-1", `{"name": "Event"}`)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotency_ConflictingPayloadIsRejected(t *testing.T) {
+	router, calls := newTestIdempotencyRouter(t)
+
+	assert.Equal(t, http.StatusCreated, postEvent(router, "key-1", `{"name": "Event"}`).Code)
+
+	w := postEvent(router, "key-1", `{"name": "Other event"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "idempotency_key_reused")
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotency_WithoutKeyAlwaysRuns(t *testing.T) {
+	router, calls := newTestIdempotencyRouter(t)
+
+	postEvent(router, "", `{"name": "Event"}`)
+	postEvent(router, "", `{"name": "Event"}`)
+	assert.Equal(t, 2, *calls)
+}
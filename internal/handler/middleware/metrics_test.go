@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"event-coming/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMetricsRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	registry := metrics.NewRegistry()
+	metrics.NewWebSocketMetrics(registry, func() (int64, int64) { return 3, 1 })
+
+	router := gin.New()
+	router.Use(Metrics(metrics.NewHTTPMetrics(registry)))
+	router.GET("/metrics", gin.WrapH(metrics.Handler(registry)))
+	router.GET("/events/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func scrapeMetrics(t *testing.T, router *gin.Engine) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_CountsRequestsByRouteTemplate(t *testing.T) {
+	router := newTestMetricsRouter()
+
+	for _, path := range []string{"/events/1", "/events/2", "/unknown/path"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrapeMetrics(t, router)
+	assert.Contains(t, body, `event_coming_http_requests_total{method="GET",route="/events/:id",status="200"} 2`)
+	assert.Contains(t, body, `event_coming_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `event_coming_http_request_duration_seconds_count{method="GET",route="/events/:id",status="200"} 2`)
+	assert.NotContains(t, body, "/events/1")
+	assert.NotContains(t, body, "/unknown/path")
+}
+
+func TestMetrics_ExportsWebSocketBackpressureCounters(t *testing.T) {
+	body := scrapeMetrics(t, newTestMetricsRouter())
+
+	assert.Contains(t, body, "event_coming_websocket_dropped_messages_total 3")
+	assert.Contains(t, body, "event_coming_websocket_disconnected_clients_total 1")
+}
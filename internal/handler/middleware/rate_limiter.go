@@ -1,13 +1,21 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"event-coming/internal/cache"
 	"event-coming/pkg/response"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // RateLimiterConfig holds rate limiter configuration
@@ -163,3 +171,90 @@ func RateLimitByUserMiddleware(limiter *RateLimiter) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RateLimitRule is a token-bucket limit applied to a group of routes
+type RateLimitRule struct {
+	// Name is used as part of the Redis key (e.g. "auth", "login_email")
+	Name string
+	// Requests per second allowed
+	RequestsPerSecond float64
+	// Burst size (max tokens)
+	BurstSize int
+	// Key identifies the caller; an empty key skips the limit
+	Key func(c *gin.Context) string
+}
+
+// ByIP keys the limit by client IP
+func ByIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// ByParam keys the limit by a route parameter (e.g. the participant id)
+func ByParam(name string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		return c.Param(name)
+	}
+}
+
+// maxEmailKeyBody caps how much of the body ByJSONEmail reads; login bodies are tiny
+const maxEmailKeyBody = 8 << 10
+
+// ByJSONEmail keys the limit by the "email" field of the JSON body.
+// The body is restored so the handler can still bind it. Bodies over
+// maxEmailKeyBody are rejected by the handler when it binds them.
+func ByJSONEmail(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	limited := http.MaxBytesReader(c.Writer, c.Request.Body, maxEmailKeyBody)
+	body, err := io.ReadAll(limited)
+	if err != nil {
+		// The limited reader keeps failing, so the handler's bind fails too
+		c.Request.Body = limited
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(payload.Email))
+}
+
+// RedisRateLimitMiddleware rate limits requests with a Redis-backed token bucket.
+// Rejected requests get 429 with a Retry-After header. If Redis is unavailable
+// requests are let through.
+func RedisRateLimitMiddleware(limiter *cache.RateLimiter, rule RateLimitRule, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := rule.Key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter, err := limiter.Allow(c.Request.Context(), rule.Name+":"+key, rule.RequestsPerSecond, rule.BurstSize)
+		if err != nil {
+			logger.Warn("Rate limiter unavailable", zap.String("rule", rule.Name), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			response.Error(c, http.StatusTooManyRequests, "rate_limit_exceeded", "Too many requests, please try again later")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
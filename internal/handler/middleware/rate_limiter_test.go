@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"event-coming/internal/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestRateLimitRouter(t *testing.T, rules ...RateLimitRule) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiter := cache.NewRateLimiter(client, "ratelimit:")
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(nil))
+
+	handlers := make([]gin.HandlerFunc, 0, len(rules)+1)
+	for _, rule := range rules {
+		handlers = append(handlers, RedisRateLimitMiddleware(limiter, rule, zap.NewNop()))
+	}
+	handlers = append(handlers, func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	router.POST("/auth/login", handlers...)
+	return router
+}
+
+func postLogin(router *gin.Engine, ip, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body))
+	req.RemoteAddr = ip + ":12345"
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRedisRateLimitMiddleware_NthRequestGets429(t *testing.T) {
+	router := newTestRateLimitRouter(t, RateLimitRule{Name: "auth", RequestsPerSecond: 0.1, BurstSize: 3, Key: ByIP})
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, postLogin(router, "10.0.0.1", "{}", nil).Code, "request %d", i+1)
+	}
+
+	w := postLogin(router, "10.0.0.1", "{}", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "rate_limit_exceeded")
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, retryAfter, 1)
+
+	// Another IP has its own bucket
+	assert.Equal(t, http.StatusOK, postLogin(router, "10.0.0.2", "{}", nil).Code)
+}
+
+func TestRedisRateLimitMiddleware_IgnoresForwardedForWithoutTrustedProxies(t *testing.T) {
+	router := newTestRateLimitRouter(t, RateLimitRule{Name: "auth", RequestsPerSecond: 0.1, BurstSize: 1, Key: ByIP})
+
+	assert.Equal(t, http.StatusOK, postLogin(router, "10.0.0.1", "{}", map[string]string{"X-Forwarded-For": "1.1.1.1"}).Code)
+	// A spoofed X-Forwarded-For doesn't get a fresh bucket
+	assert.Equal(t, http.StatusTooManyRequests,
+		postLogin(router, "10.0.0.1", "{}", map[string]string{"X-Forwarded-For": "2.2.2.2"}).Code)
+}
+
+func TestRedisRateLimitMiddleware_ByJSONEmail(t *testing.T) {
+	router := newTestRateLimitRouter(t, RateLimitRule{Name: "login_email", RequestsPerSecond: 0.1, BurstSize: 2, Key: ByJSONEmail})
+
+	body := `{"email": "maria@example.com", "password": "secret123"}`
+	w := postLogin(router, "10.0.0.1", body, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	// The handler still reads the whole body
+	assert.Equal(t, body, w.Body.String())
+
+	// The email is normalized, so changing IP or case doesn't reset the limit
+	assert.Equal(t, http.StatusOK, postLogin(router, "10.0.0.2", `{"email": "MARIA@example.com "}`, nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, postLogin(router, "10.0.0.3", body, nil).Code)
+
+	// Without an email the limit is skipped
+	assert.Equal(t, http.StatusOK, postLogin(router, "10.0.0.1", `{}`, nil).Code)
+}
+
+func TestByJSONEmail_CapsBodyRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	body := `{"email": "maria@example.com", "padding": "` + strings.Repeat("x", maxEmailKeyBody) + `"}`
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body))
+
+	assert.Empty(t, ByJSONEmail(c))
+	_, err := io.ReadAll(c.Request.Body)
+	assert.Error(t, err)
+}
+
+func TestRedisRateLimitMiddleware_ByParam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiter := cache.NewRateLimiter(client, "ratelimit:")
+	rule := RateLimitRule{Name: "resend_confirmation", RequestsPerSecond: 0.001, BurstSize: 1, Key: ByParam("id")}
+	router := gin.New()
+	router.POST("/participants/:id/resend-confirmation", RedisRateLimitMiddleware(limiter, rule, zap.NewNop()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	resend := func(id string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/participants/"+id+"/resend-confirmation", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, resend("p1"))
+	assert.Equal(t, http.StatusTooManyRequests, resend("p1"))
+	// Each participant has its own bucket
+	assert.Equal(t, http.StatusOK, resend("p2"))
+}
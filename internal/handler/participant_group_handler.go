@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"net/http"
+
+	"event-coming/internal/dto"
+	"event-coming/internal/service"
+	"event-coming/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ParticipantGroupHandler gerencia requisições de grupos de participantes
+type ParticipantGroupHandler struct {
+	service *service.ParticipantGroupService
+	logger  *zap.Logger
+}
+
+// NewParticipantGroupHandler cria um novo handler de grupos de participantes
+func NewParticipantGroupHandler(service *service.ParticipantGroupService, logger *zap.Logger) *ParticipantGroupHandler {
+	return &ParticipantGroupHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Create cria um grupo no evento
+// POST /api/v1/events/:id/groups
+func (h *ParticipantGroupHandler) Create(c *gin.Context) {
+	entityID, eventID, ok := h.eventParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateParticipantGroupRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	group, err := h.service.Create(c.Request.Context(), entityID, eventID, &req)
+	if err != nil {
+		h.fail(c, err, "failed to create group", eventID)
+		return
+	}
+
+	response.Created(c, group)
+}
+
+// List lista os grupos do evento
+// GET /api/v1/events/:id/groups
+func (h *ParticipantGroupHandler) List(c *gin.Context) {
+	entityID, eventID, ok := h.eventParams(c)
+	if !ok {
+		return
+	}
+
+	groups, err := h.service.List(c.Request.Context(), entityID, eventID)
+	if err != nil {
+		h.fail(c, err, "failed to list groups", eventID)
+		return
+	}
+
+	response.Success(c, groups)
+}
+
+// Update altera o nome e/ou a descrição do grupo
+// PUT /api/v1/events/:id/groups/:group_id
+func (h *ParticipantGroupHandler) Update(c *gin.Context) {
+	entityID, eventID, groupID, ok := h.groupParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateParticipantGroupRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	group, err := h.service.Update(c.Request.Context(), entityID, eventID, groupID, &req)
+	if err != nil {
+		h.fail(c, err, "failed to update group", eventID)
+		return
+	}
+
+	response.Success(c, group)
+}
+
+// Delete remove o grupo; os participantes continuam no evento
+// DELETE /api/v1/events/:id/groups/:group_id
+func (h *ParticipantGroupHandler) Delete(c *gin.Context) {
+	entityID, eventID, groupID, ok := h.groupParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), entityID, eventID, groupID); err != nil {
+		h.fail(c, err, "failed to delete group", eventID)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// AddMembers adiciona participantes do evento ao grupo
+// POST /api/v1/events/:id/groups/:group_id/members
+func (h *ParticipantGroupHandler) AddMembers(c *gin.Context) {
+	entityID, eventID, groupID, ok := h.groupParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ParticipantGroupMembersRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.AddMembers(c.Request.Context(), entityID, eventID, groupID, req.ParticipantIDs); err != nil {
+		h.fail(c, err, "failed to add group members", eventID)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// RemoveMember tira um participante do grupo
+// DELETE /api/v1/events/:id/groups/:group_id/members/:participant_id
+func (h *ParticipantGroupHandler) RemoveMember(c *gin.Context) {
+	entityID, eventID, groupID, ok := h.groupParams(c)
+	if !ok {
+		return
+	}
+
+	participantID, err := uuid.Parse(c.Param("participant_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid participant_id")
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), entityID, eventID, groupID, participantID); err != nil {
+		h.fail(c, err, "failed to remove group member", eventID)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// eventParams lê entity_id do contexto e o ID do evento da rota
+func (h *ParticipantGroupHandler) eventParams(c *gin.Context) (entityID, eventID uuid.UUID, ok bool) {
+	entityID, ok = c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	var err error
+	if eventID, err = uuid.Parse(c.Param("id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return entityID, eventID, false
+	}
+
+	return entityID, eventID, true
+}
+
+// groupParams lê entity_id do contexto e os IDs de evento e grupo da rota
+func (h *ParticipantGroupHandler) groupParams(c *gin.Context) (entityID, eventID, groupID uuid.UUID, ok bool) {
+	entityID, eventID, ok = h.eventParams(c)
+	if !ok {
+		return
+	}
+
+	var err error
+	if groupID, err = uuid.Parse(c.Param("group_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid group_id")
+		return entityID, eventID, groupID, false
+	}
+
+	return entityID, eventID, groupID, true
+}
+
+// fail responde o erro de domínio ou registra a falha e responde 500
+func (h *ParticipantGroupHandler) fail(c *gin.Context, err error, message string, eventID uuid.UUID) {
+	if response.IsDomainError(err) {
+		response.FromError(c, err)
+		return
+	}
+	h.logger.Error("Participant group request failed",
+		zap.String("operation", message),
+		zap.String("event_id", eventID.String()),
+		zap.Error(err),
+	)
+	response.Error(c, http.StatusInternalServerError, "internal_error", message)
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"event-coming/internal/domain"
 	"event-coming/internal/dto"
+	"event-coming/internal/handler/middleware"
 	"event-coming/internal/service"
+	"event-coming/pkg/pagination"
 	"event-coming/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -50,27 +54,20 @@ func (h *ParticipantHandler) Create(c *gin.Context) {
 	}
 
 	var req dto.CreateParticipantRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	participant, err := h.service.Create(c.Request.Context(), entityID, eventID, &req)
 	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
 		h.logger.Error("Failed to create participant",
 			zap.String("event_id", eventIDStr),
 			zap.Error(err),
 		)
-
-		if err.Error() == "event not found" {
-			response.Error(c, http.StatusNotFound, "not_found", "event not found")
-			return
-		}
-		if err.Error() == "participant with this phone number already exists in this event" {
-			response.Error(c, http.StatusConflict, "conflict", err.Error())
-			return
-		}
-
 		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to create participant")
 		return
 	}
@@ -114,7 +111,7 @@ func (h *ParticipantHandler) GetByID(c *gin.Context) {
 }
 
 // Update atualiza um participante
-// PUT /api/v1/participants/:id
+// PUT|PATCH /api/v1/participants/:id
 func (h *ParticipantHandler) Update(c *gin.Context) {
 	entityIDStr, exists := c.Get("entity_id")
 	if !exists {
@@ -136,13 +133,20 @@ func (h *ParticipantHandler) Update(c *gin.Context) {
 	}
 
 	var req dto.UpdateParticipantRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+	if !bindJSON(c, &req) || !applyIfMatch(c, &req.Version) {
 		return
 	}
 
 	participant, err := h.service.Update(c.Request.Context(), entityID, participantID, &req)
 	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.Error(c, http.StatusNotFound, "not_found", "participant not found")
+			return
+		}
+		if errors.Is(err, domain.ErrVersionConflict) {
+			response.Error(c, http.StatusConflict, "version_conflict", "participant was modified by another request")
+			return
+		}
 		h.logger.Error("Failed to update participant",
 			zap.String("participant_id", participantIDStr),
 			zap.Error(err),
@@ -188,8 +192,8 @@ func (h *ParticipantHandler) Delete(c *gin.Context) {
 	response.NoContent(c)
 }
 
-// ListByEvent lista participantes de um evento
-// GET /api/v1/events/:event_id/participants
+// ListByEvent lista participantes de um evento, opcionalmente só os de um grupo ou de uma ocorrência
+// GET /api/v1/events/:event_id/participants?group_id=&instance_id=
 func (h *ParticipantHandler) ListByEvent(c *gin.Context) {
 	entityIDStr, exists := c.Get("entity_id")
 	if !exists {
@@ -211,18 +215,36 @@ func (h *ParticipantHandler) ListByEvent(c *gin.Context) {
 	}
 
 	// Paginação
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
-
-	if page < 1 {
-		page = 1
+	page, _ := strconv.Atoi(c.Query("page"))
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+	page, perPage = pagination.Normalize(page, perPage)
+
+	var groupID *uuid.UUID
+	if groupIDStr := c.Query("group_id"); groupIDStr != "" {
+		parsed, err := uuid.Parse(groupIDStr)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "bad_request", "invalid group_id")
+			return
+		}
+		groupID = &parsed
 	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 20
+
+	var instanceID *uuid.UUID
+	if instanceIDStr := c.Query("instance_id"); instanceIDStr != "" {
+		parsed, err := uuid.Parse(instanceIDStr)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "bad_request", "invalid instance_id")
+			return
+		}
+		instanceID = &parsed
 	}
 
-	participants, total, err := h.service.ListByEvent(c.Request.Context(), entityID, eventID, page, perPage)
+	participants, total, err := h.service.ListByEvent(c.Request.Context(), entityID, eventID, groupID, instanceID, page, perPage)
 	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
 		h.logger.Error("Failed to list participants",
 			zap.String("event_id", eventIDStr),
 			zap.Error(err),
@@ -269,17 +291,183 @@ func (h *ParticipantHandler) Confirm(c *gin.Context) {
 	response.Success(c, participant)
 }
 
-// CheckIn faz check-in do participante
-// POST /api/v1/participants/:id/check-in
-func (h *ParticipantHandler) CheckIn(c *gin.Context) {
-	entityIDStr, exists := c.Get("entity_id")
-	if !exists {
-		response.Error(c, http.StatusBadRequest, "bad_request", "entity_id not found in context")
+// ResendConfirmation reenvia o pedido de confirmação a um participante pendente
+// POST /api/v1/participants/:id/resend-confirmation
+func (h *ParticipantHandler) ResendConfirmation(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
 		return
 	}
 
-	entityID, err := uuid.Parse(entityIDStr.(string))
+	participantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid participant_id")
+		return
+	}
+
+	if err := h.service.ResendConfirmation(c.Request.Context(), entityID, participantID); err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to resend confirmation",
+			zap.String("participant_id", participantID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to resend confirmation")
+		return
+	}
+
+	response.Success(c, gin.H{"sent": true})
+}
+
+// GenerateInviteLink gera o link de convite (RSVP) do participante
+// POST /api/v1/participants/:id/invite-link
+func (h *ParticipantHandler) GenerateInviteLink(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	participantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid participant_id")
+		return
+	}
+
+	link, err := h.service.GenerateInviteLink(c.Request.Context(), entityID, participantID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to generate invite link",
+			zap.String("participant_id", participantID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to generate invite link")
+		return
+	}
+
+	response.Success(c, link)
+}
+
+// GetStatusHistory retorna a linha do tempo de status do participante
+// GET /api/v1/participants/:id/history
+func (h *ParticipantHandler) GetStatusHistory(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	participantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid participant_id")
+		return
+	}
+
+	history, err := h.service.GetStatusHistory(c.Request.Context(), entityID, participantID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to get participant status history",
+			zap.String("participant_id", participantID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to get status history")
+		return
+	}
+
+	response.Success(c, history)
+}
+
+// Reconcile aplica uma resposta do participante que se perdeu no webhook (admin)
+// POST /api/v1/participants/:id/reconcile
+func (h *ParticipantHandler) Reconcile(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	participantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid participant_id")
+		return
+	}
+
+	var req dto.ReconcileParticipantRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	participant, err := h.service.Reconcile(c.Request.Context(), entityID, participantID, req.Status, req.Reason)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to reconcile participant",
+			zap.String("participant_id", participantID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to reconcile participant")
+		return
+	}
+
+	response.Success(c, participant)
+}
+
+// ViewInvite retorna os dados pré-preenchidos da página de RSVP e registra a abertura do link
+// GET /api/v1/rsvp/:token (público)
+func (h *ParticipantHandler) ViewInvite(c *gin.Context) {
+	invite, err := h.service.ViewInvite(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to load invite", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to load invite")
+		return
+	}
+
+	response.Success(c, invite)
+}
+
+// RespondToInvite registra a resposta do participante pela página de RSVP
+// POST /api/v1/rsvp/:token (público)
+func (h *ParticipantHandler) RespondToInvite(c *gin.Context) {
+	var req dto.RespondInviteRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	invite, err := h.service.RespondToInvite(c.Request.Context(), c.Param("token"), req.Status, req.Reason)
 	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to respond to invite", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to respond to invite")
+		return
+	}
+
+	response.Success(c, invite)
+}
+
+// CheckIn faz check-in do participante. Com ?override_window=true, admins fazem o
+// check-in fora da janela do evento
+// POST /api/v1/participants/:id/check-in
+func (h *ParticipantHandler) CheckIn(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
 		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
 		return
 	}
@@ -291,8 +479,21 @@ func (h *ParticipantHandler) CheckIn(c *gin.Context) {
 		return
 	}
 
-	participant, err := h.service.CheckInParticipant(c.Request.Context(), entityID, participantID)
+	overrideWindow := c.Query("override_window") == "true"
+	if overrideWindow {
+		role, _ := c.Get("role")
+		if userRole, ok := role.(domain.UserRole); !ok || !middleware.HasPermission(userRole, domain.UserRoleEntityAdmin) {
+			response.Error(c, http.StatusForbidden, "forbidden", "Only entity admins can check in outside the event's check-in window")
+			return
+		}
+	}
+
+	participant, err := h.service.CheckInParticipant(c.Request.Context(), entityID, participantID, overrideWindow)
 	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
 		h.logger.Error("Failed to check-in participant",
 			zap.String("participant_id", participantIDStr),
 			zap.Error(err),
@@ -307,14 +508,8 @@ func (h *ParticipantHandler) CheckIn(c *gin.Context) {
 // BatchCreate cria múltiplos participantes
 // POST /api/v1/events/:event_id/participants/batch
 func (h *ParticipantHandler) BatchCreate(c *gin.Context) {
-	entityIDStr, exists := c.Get("entity_id")
-	if !exists {
-		response.Error(c, http.StatusBadRequest, "bad_request", "entity_id not found in context")
-		return
-	}
-
-	entityID, err := uuid.Parse(entityIDStr.(string))
-	if err != nil {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
 		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
 		return
 	}
@@ -327,12 +522,24 @@ func (h *ParticipantHandler) BatchCreate(c *gin.Context) {
 	}
 
 	var req dto.BatchCreateParticipantsRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	participants, errors := h.service.BatchCreate(c.Request.Context(), entityID, eventID, &req)
+	participants, errors, err := h.service.BatchCreate(c.Request.Context(), entityID, eventID, &req)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to batch create participants",
+			zap.String("event_id", eventID.String()),
+			zap.Int("created", len(participants)),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to create participants")
+		return
+	}
 
 	// Preparar resposta
 	errorMessages := make([]string, len(errors))
@@ -348,3 +555,89 @@ func (h *ParticipantHandler) BatchCreate(c *gin.Context) {
 		"errors":       errorMessages,
 	})
 }
+
+// CopyFromEvent copia os participantes de outro evento da entidade como novos convites pendentes
+// POST /api/v1/events/:id/participants/copy-from/:source_id
+func (h *ParticipantHandler) CopyFromEvent(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	sourceID, err := uuid.Parse(c.Param("source_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid source_id")
+		return
+	}
+
+	result, err := h.service.CopyFromEvent(c.Request.Context(), entityID, sourceID, eventID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to copy participants",
+			zap.String("event_id", eventID.String()),
+			zap.String("source_id", sourceID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to copy participants")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// BatchUpdateStatus atualiza o status de vários participantes do evento
+// POST /api/v1/events/:id/participants/bulk/status
+func (h *ParticipantHandler) BatchUpdateStatus(c *gin.Context) {
+	value, exists := c.Get("entity_id")
+	entityID, ok := value.(uuid.UUID)
+	if !exists || !ok {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "Entity not found in context")
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid event_id")
+		return
+	}
+
+	var req dto.BatchUpdateStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	results := h.service.BatchUpdateStatus(c.Request.Context(), entityID, eventID, req.ParticipantIDs, req.Status)
+
+	updated := 0
+	for _, r := range results {
+		if r.Success {
+			updated++
+			continue
+		}
+		// Só o código vai para o cliente; erros inesperados ficam no log
+		r.Error = response.CodeFromError(r.Err)
+		if r.Error == "internal_error" {
+			h.logger.Error("Failed to update participant status",
+				zap.String("participant_id", r.ParticipantID.String()),
+				zap.Error(r.Err),
+			)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"updated": updated,
+		"failed":  len(results) - updated,
+		"results": results,
+	})
+}
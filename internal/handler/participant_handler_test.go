@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/service"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestParticipantRouter serves the participant routes for the test entity
+func newTestParticipantRouter(participantRepo *mocks.MockParticipantRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	svc := service.NewParticipantService(participantRepo, new(mocks.MockEventRepository), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h := NewParticipantHandler(svc, zap.NewNop())
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("entity_id", testutil.TestEntityID.String())
+	})
+	router.PUT("/participants/:id", h.Update)
+	return router
+}
+
+// expectedVersion matches an update input carrying the given expected version
+func expectedVersion(version int) interface{} {
+	return mock.MatchedBy(func(input *domain.UpdateParticipantInput) bool {
+		return input.ExpectedVersion != nil && *input.ExpectedVersion == version
+	})
+}
+
+func TestParticipantHandler_Update_StaleVersionIsRejected(t *testing.T) {
+	participant := testutil.NewTestParticipant()
+	participantRepo := new(mocks.MockParticipantRepository)
+	participantRepo.On("GetByID", mock.Anything, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	participantRepo.On("Update", mock.Anything, participant.ID, testutil.TestEntityID, expectedVersion(2)).
+		Return(domain.ErrVersionConflict)
+	router := newTestParticipantRouter(participantRepo)
+
+	tests := []struct {
+		name    string
+		body    string
+		ifMatch string
+	}{
+		{name: "version in body", body: `{"name": "Maria", "version": 2}`},
+		{name: "If-Match header", body: `{"name": "Maria"}`, ifMatch: `"2"`},
+		{name: "weak If-Match header", body: `{"name": "Maria"}`, ifMatch: `W/"2"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/participants/"+participant.ID.String(), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			assert.Contains(t, w.Body.String(), "version_conflict")
+		})
+	}
+}
+
+func TestParticipantHandler_Update_InvalidIfMatch(t *testing.T) {
+	participantRepo := new(mocks.MockParticipantRepository)
+	router := newTestParticipantRouter(participantRepo)
+
+	req := httptest.NewRequest(http.MethodPut, "/participants/"+testutil.NewTestParticipant().ID.String(), strings.NewReader(`{"name": "Maria"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	participantRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParticipantHandler_BatchUpdateStatus_ReportsErrorCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	participant := testutil.NewTestParticipant()
+	missingID := uuid.New()
+	participantRepo := new(mocks.MockParticipantRepository)
+	participantRepo.On("GetByID", mock.Anything, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	participantRepo.On("Update", mock.Anything, participant.ID, testutil.TestEntityID, mock.Anything).Return(nil)
+	participantRepo.On("GetByID", mock.Anything, missingID, testutil.TestEntityID).Return(nil, domain.ErrNotFound)
+	eventRepo := new(mocks.MockEventRepository)
+	eventRepo.On("GetByID", mock.Anything, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	svc := service.NewParticipantService(participantRepo, eventRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	h := NewParticipantHandler(svc, zap.NewNop())
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("entity_id", testutil.TestEntityID)
+	})
+	router.POST("/events/:id/participants/bulk/status", h.BatchUpdateStatus)
+
+	body := `{"participant_ids": ["` + participant.ID.String() + `", "` + missingID.String() + `"], "status": "checked_in"}`
+	req := httptest.NewRequest(http.MethodPost, "/events/"+participant.EventID.String()+"/participants/bulk/status", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Updated int `json:"updated"`
+		Failed  int `json:"failed"`
+		Results []struct {
+			ParticipantID uuid.UUID `json:"participant_id"`
+			Success       bool      `json:"success"`
+			Error         string    `json:"error"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Updated)
+	assert.Equal(t, 1, resp.Failed)
+	require.Len(t, resp.Results, 2)
+	assert.True(t, resp.Results[0].Success)
+	assert.Empty(t, resp.Results[0].Error)
+	assert.Equal(t, missingID, resp.Results[1].ParticipantID)
+	assert.Equal(t, "not_found", resp.Results[1].Error)
+}
+
+func TestParticipantHandler_ResendConfirmation_AnsweredParticipantIsConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusConfirmed
+	participantRepo := new(mocks.MockParticipantRepository)
+	participantRepo.On("GetByID", mock.Anything, participant.ID, testutil.TestEntityID).Return(participant, nil)
+
+	h := NewParticipantHandler(service.NewParticipantService(participantRepo, new(mocks.MockEventRepository), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil), zap.NewNop())
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("entity_id", testutil.TestEntityID)
+	})
+	router.POST("/participants/:id/resend-confirmation", h.ResendConfirmation)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/participants/"+participant.ID.String()+"/resend-confirmation", nil))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "participant_not_pending")
+}
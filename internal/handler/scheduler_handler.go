@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"event-coming/internal/service"
+	"event-coming/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SchedulerHandler gerencia requisições de agendamentos
+type SchedulerHandler struct {
+	service service.SchedulerService
+	logger  *zap.Logger
+}
+
+// NewSchedulerHandler cria um novo handler de agendamentos
+func NewSchedulerHandler(service service.SchedulerService, logger *zap.Logger) *SchedulerHandler {
+	return &SchedulerHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RunNow dispara um agendamento pendente imediatamente, sem esperar o worker
+// POST /api/v1/schedulers/:id/run
+func (h *SchedulerHandler) RunNow(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	schedulerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid scheduler_id")
+		return
+	}
+
+	scheduler, err := h.service.RunNow(c.Request.Context(), schedulerID, entityID)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to run scheduler",
+			zap.String("scheduler_id", schedulerID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to run scheduler")
+		return
+	}
+
+	response.Success(c, scheduler)
+}
+
+// ListDue lista as tasks pendentes de todas as entidades que disparam na janela
+// (padrão: próxima hora), para o plantão antecipar picos
+// GET /api/v1/admin/schedulers/due?from=&to=&limit=
+func (h *SchedulerHandler) ListDue(c *gin.Context) {
+	from := time.Now()
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "bad_request", "invalid from, expected RFC3339")
+			return
+		}
+		from = parsed
+	}
+
+	to := from.Add(time.Hour)
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "bad_request", "invalid to, expected RFC3339")
+			return
+		}
+		to = parsed
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	schedulers, err := h.service.ListDueBetween(c.Request.Context(), from, to, limit)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to list due schedulers", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to list due schedulers")
+		return
+	}
+
+	response.Success(c, schedulers)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"event-coming/internal/service"
+	"event-coming/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SearchHandler gerencia requisições de busca
+type SearchHandler struct {
+	service *service.SearchService
+	logger  *zap.Logger
+}
+
+// NewSearchHandler cria um novo handler de busca
+func NewSearchHandler(service *service.SearchService, logger *zap.Logger) *SearchHandler {
+	return &SearchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Global busca eventos e participantes da entidade
+// GET /api/v1/search?q=
+func (h *SearchHandler) Global(c *gin.Context) {
+	entityID, ok := c.MustGet("entity_id").(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusBadRequest, "bad_request", "invalid entity_id")
+		return
+	}
+
+	query := c.Query("q")
+
+	results, err := h.service.Global(c.Request.Context(), entityID, query)
+	if err != nil {
+		if response.IsDomainError(err) {
+			response.FromError(c, err)
+			return
+		}
+		h.logger.Error("Failed to search",
+			zap.String("entity_id", entityID.String()),
+			zap.Error(err),
+		)
+		response.Error(c, http.StatusInternalServerError, "internal_error", "failed to search")
+		return
+	}
+
+	response.Success(c, results)
+}
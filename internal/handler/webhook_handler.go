@@ -1,18 +1,20 @@
 package handler
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"event-coming/internal/config"
 	"event-coming/internal/domain"
-	"event-coming/internal/dto"
 	"event-coming/internal/service"
 	"event-coming/internal/whatsapp"
 	"event-coming/pkg/response"
@@ -21,26 +23,36 @@ import (
 	"go.uber.org/zap"
 )
 
+// webhookTimestampHeader carries the Unix time (seconds) a webhook was sent at. When
+// present it is part of the signed payload ("<timestamp>.<body>"), so it can't be changed
+// to make a captured request look fresh
+const webhookTimestampHeader = "X-Webhook-Timestamp"
+
 // WebhookHandler handles WhatsApp webhook requests
 type WebhookHandler struct {
-	cfg                *config.WhatsAppConfig
-	participantService *service.ParticipantService
-	locationService    *service.LocationService
-	logger             *zap.Logger
+	cfg             *config.WhatsAppConfig
+	parser          *whatsapp.InboundParser
+	inboundService  *service.InboundMessageService
+	deliveryService *service.DeliveryTrackingService
+	whatsappClient  *whatsapp.Client
+	logger          *zap.Logger
 }
 
 // NewWebhookHandler creates a new webhook handler
 func NewWebhookHandler(
 	cfg *config.WhatsAppConfig,
-	participantService *service.ParticipantService,
-	locationService *service.LocationService,
+	inboundService *service.InboundMessageService,
+	deliveryService *service.DeliveryTrackingService,
+	whatsappClient *whatsapp.Client,
 	logger *zap.Logger,
 ) *WebhookHandler {
 	return &WebhookHandler{
-		cfg:                cfg,
-		participantService: participantService,
-		locationService:    locationService,
-		logger:             logger,
+		cfg:             cfg,
+		parser:          whatsapp.NewInboundParser(),
+		inboundService:  inboundService,
+		deliveryService: deliveryService,
+		whatsappClient:  whatsappClient,
+		logger:          logger,
 	}
 }
 
@@ -75,14 +87,23 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 		return
 	}
 
+	// Restore the body so later readers (binding, middleware) still see it
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
 	// Verify signature if webhook secret is configured
 	if h.cfg.WebhookSecret != "" {
 		signature := c.GetHeader("X-Hub-Signature-256")
-		if !h.verifySignature(body, signature) {
+		timestamp := c.GetHeader(webhookTimestampHeader)
+		if !h.verifySignature(signedPayload(timestamp, body), signature) {
 			h.logger.Warn("Invalid webhook signature")
 			response.Error(c, http.StatusUnauthorized, "unauthorized", "Invalid signature")
 			return
 		}
+		if timestamp != "" && !h.freshTimestamp(timestamp, time.Now()) {
+			h.logger.Warn("Rejected stale webhook", zap.String("timestamp", timestamp))
+			response.Error(c, http.StatusUnauthorized, "unauthorized", "Stale webhook timestamp")
+			return
+		}
 	}
 
 	// Parse payload using json.Unmarshal (body already consumed by io.ReadAll)
@@ -93,205 +114,165 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 		return
 	}
 
-	// Process messages
+	// Process messages through the provider-agnostic service
+	failed := false
+	for _, msg := range h.parser.ParsePayload(&payload) {
+		if !h.processMessage(c, msg) {
+			failed = true
+		}
+	}
+
+	// Process delivery status callbacks
 	for _, entry := range payload.Entry {
 		for _, change := range entry.Changes {
 			if change.Field == "messages" {
-				h.processMessages(c, change.Value)
+				h.processStatuses(c, change.Value)
 			}
 		}
 	}
 
-	// Always return 200 to acknowledge receipt
-	c.Status(http.StatusOK)
-}
-
-// processMessages processes incoming messages
-func (h *WebhookHandler) processMessages(c *gin.Context, value whatsapp.Value) {
-	for _, msg := range value.Messages {
-		switch msg.Type {
-		case "location":
-			h.handleLocationMessage(c, msg)
-		case "interactive":
-			h.handleInteractiveMessage(c, msg)
-		case "button":
-			h.handleButtonMessage(c, msg)
-		case "text":
-			h.handleTextMessage(c, msg)
-		}
-	}
-}
-
-// handleLocationMessage processes location messages from participants
-func (h *WebhookHandler) handleLocationMessage(c *gin.Context, msg whatsapp.Message) {
-	if msg.Location == nil {
+	// A message that failed unexpectedly (e.g. database down) gets a 500 so WhatsApp
+	// redelivers the payload; messages already handled are skipped by their id.
+	// WhatsApp only retries non-2xx answers, so with the previous blanket 200 releasing
+	// the message id would never lead to the message being handled again
+	if failed {
+		response.Error(c, http.StatusInternalServerError, "internal_error", "Failed to process message")
 		return
 	}
 
-	phoneNumber := msg.From
-	h.logger.Info("Received location from WhatsApp",
-		zap.String("phone", phoneNumber),
-		zap.Float64("lat", msg.Location.Latitude),
-		zap.Float64("lng", msg.Location.Longitude),
-	)
+	c.Status(http.StatusOK)
+}
 
-	// Find participant by phone number
-	participant, err := h.participantService.GetByPhoneNumber(c.Request.Context(), phoneNumber)
-	if err != nil {
+// processMessage hands an inbound message to the service and answers
+// unrecognized replies with instructions when enabled. Returns false when
+// handling failed unexpectedly and the message should be redelivered
+func (h *WebhookHandler) processMessage(c *gin.Context, msg *domain.InboundMessage) bool {
+	err := h.inboundService.Handle(c.Request.Context(), msg)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrUnrecognizedReply):
+		if h.cfg.HelpOnUnknownReply {
+			h.sendReplyHelp(c, msg.From)
+		}
+	case errors.Is(err, domain.ErrLocationConsentRequired):
+		// O serviço já registrou a rejeição; a localização é descartada
+		h.logger.Info("Discarded location from participant without consent",
+			zap.String("phone", msg.From),
+		)
+	case errors.Is(err, domain.ErrNotFound):
 		h.logger.Warn("Participant not found for phone number",
-			zap.String("phone", phoneNumber),
+			zap.String("phone", msg.From),
+			zap.String("type", string(msg.Type)),
+		)
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrConflict):
+		h.logger.Warn("Inbound message rejected",
+			zap.String("phone", msg.From),
+			zap.String("message_id", msg.ProviderMessageID),
 			zap.Error(err),
 		)
-		return
-	}
-
-	// Parse timestamp
-	timestamp := time.Now()
-	if ts, err := strconv.ParseInt(msg.Timestamp, 10, 64); err == nil {
-		timestamp = time.Unix(ts, 0)
-	}
-
-	// Create location
-	locationReq := &dto.CreateLocationRequest{
-		Latitude:  msg.Location.Latitude,
-		Longitude: msg.Location.Longitude,
-		Timestamp: &timestamp,
-	}
-
-	_, err = h.locationService.CreateLocation(
-		c.Request.Context(),
-		participant.ID,
-		participant.EntityID,
-		locationReq,
-	)
-	if err != nil {
-		h.logger.Error("Failed to save location",
-			zap.String("phone", phoneNumber),
+	default:
+		h.logger.Error("Failed to process inbound message",
+			zap.String("phone", msg.From),
+			zap.String("message_id", msg.ProviderMessageID),
 			zap.Error(err),
 		)
-		return
+		return false
 	}
-
-	h.logger.Info("Location saved successfully",
-		zap.String("phone", phoneNumber),
-		zap.String("participant_id", participant.ID.String()),
-	)
+	return true
 }
 
-// handleInteractiveMessage processes interactive button replies (confirmation)
-func (h *WebhookHandler) handleInteractiveMessage(c *gin.Context, msg whatsapp.Message) {
-	if msg.Interactive == nil || msg.Interactive.ButtonReply == nil {
+// processStatuses updates the delivery log from sent/delivered/read/failed callbacks
+func (h *WebhookHandler) processStatuses(c *gin.Context, value whatsapp.Value) {
+	if h.deliveryService == nil {
 		return
 	}
 
-	phoneNumber := msg.From
-	buttonPayload := msg.Interactive.ButtonReply.Payload
-
-	h.logger.Info("Received interactive reply",
-		zap.String("phone", phoneNumber),
-		zap.String("payload", buttonPayload),
-	)
-
-	h.processConfirmationResponse(c, phoneNumber, buttonPayload)
-}
+	for _, st := range value.Statuses {
+		var errorCode *int
+		var errorMessage *string
+		if len(st.Errors) > 0 {
+			code := st.Errors[0].Code
+			msg := st.Errors[0].Title
+			errorCode = &code
+			errorMessage = &msg
+		}
 
-// handleButtonMessage processes button replies
-func (h *WebhookHandler) handleButtonMessage(c *gin.Context, msg whatsapp.Message) {
-	if msg.Button == nil {
-		return
+		err := h.deliveryService.UpdateStatus(c.Request.Context(), st.ID, domain.DeliveryStatus(st.Status), errorCode, errorMessage)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				// Messages not sent by us (or sent before tracking existed)
+				h.logger.Debug("No delivery log for status callback", zap.String("message_id", st.ID))
+				continue
+			}
+			h.logger.Warn("Failed to update delivery status",
+				zap.String("message_id", st.ID),
+				zap.String("status", st.Status),
+				zap.Error(err),
+			)
+		}
 	}
-
-	phoneNumber := msg.From
-	buttonPayload := msg.Button.Payload
-
-	h.logger.Info("Received button reply",
-		zap.String("phone", phoneNumber),
-		zap.String("payload", buttonPayload),
-	)
-
-	h.processConfirmationResponse(c, phoneNumber, buttonPayload)
 }
 
-// handleTextMessage processes text messages (fallback confirmation)
-func (h *WebhookHandler) handleTextMessage(c *gin.Context, msg whatsapp.Message) {
-	if msg.Text == nil {
+// sendReplyHelp explains how to answer when a free-text reply wasn't understood.
+// Only known participants get here (the service checks the sender first).
+func (h *WebhookHandler) sendReplyHelp(c *gin.Context, phoneNumber string) {
+	if h.whatsappClient == nil {
 		return
 	}
 
-	phoneNumber := msg.From
-	text := msg.Text.Body
-
-	h.logger.Info("Received text message",
-		zap.String("phone", phoneNumber),
-		zap.String("text", text),
-	)
-
-	// Simple text-based confirmation (yes/no/sim/não)
-	switch text {
-	case "1", "yes", "sim", "confirmo", "vou":
-		h.processConfirmationResponse(c, phoneNumber, "confirm_yes")
-	case "2", "no", "não", "nao", "não vou":
-		h.processConfirmationResponse(c, phoneNumber, "confirm_no")
-	}
-}
-
-// processConfirmationResponse processes confirmation responses
-func (h *WebhookHandler) processConfirmationResponse(c *gin.Context, phoneNumber, payload string) {
-	// Find participant by phone number
-	participant, err := h.participantService.GetByPhoneNumber(c.Request.Context(), phoneNumber)
-	if err != nil {
-		h.logger.Warn("Participant not found for confirmation",
+	message := "Não entendi sua resposta. 🤔\n\n" +
+		"Responda *SIM* para confirmar ou *NÃO* para recusar sua presença."
+	if err := h.whatsappClient.SendTextMessage(c.Request.Context(), phoneNumber, message); err != nil {
+		h.logger.Warn("Failed to send reply help",
 			zap.String("phone", phoneNumber),
 			zap.Error(err),
 		)
-		return
 	}
+}
 
-	var newStatus domain.ParticipantStatus
-	switch payload {
-	case "confirm_yes", "CONFIRM_YES", "yes", "1":
-		newStatus = domain.ParticipantStatusConfirmed
-	case "confirm_no", "CONFIRM_NO", "no", "2":
-		newStatus = domain.ParticipantStatusDenied
-	default:
-		h.logger.Warn("Unknown confirmation payload",
-			zap.String("phone", phoneNumber),
-			zap.String("payload", payload),
-		)
-		return
+// verifySignature verifies the X-Hub-Signature-256 header ("sha256=<hex>")
+// against the HMAC-SHA256 of the raw body using a constant-time comparison
+func (h *WebhookHandler) verifySignature(body []byte, signature string) bool {
+	const prefix = "sha256="
+	if !strings.HasPrefix(signature, prefix) {
+		return false
 	}
 
-	// Update participant status
-	err = h.participantService.UpdateStatus(c.Request.Context(), participant.ID, participant.EntityID, newStatus)
+	received, err := hex.DecodeString(signature[len(prefix):])
 	if err != nil {
-		h.logger.Error("Failed to update participant status",
-			zap.String("phone", phoneNumber),
-			zap.Error(err),
-		)
-		return
+		return false
 	}
 
-	h.logger.Info("Participant confirmation processed",
-		zap.String("phone", phoneNumber),
-		zap.String("participant_id", participant.ID.String()),
-		zap.String("status", string(newStatus)),
-	)
+	mac := hmac.New(sha256.New, []byte(h.cfg.WebhookSecret))
+	mac.Write(body)
+	expectedMAC := mac.Sum(nil)
+
+	return hmac.Equal(received, expectedMAC)
 }
 
-// verifySignature verifies the webhook signature
-func (h *WebhookHandler) verifySignature(body []byte, signature string) bool {
-	if signature == "" {
-		return false
+// signedPayload returns the bytes covered by the signature: the raw body, prefixed by
+// "<timestamp>." when the provider sent a timestamp
+func signedPayload(timestamp string, body []byte) []byte {
+	if timestamp == "" {
+		return body
 	}
+	return append([]byte(timestamp+"."), body...)
+}
 
-	// Remove "sha256=" prefix
-	if len(signature) > 7 && signature[:7] == "sha256=" {
-		signature = signature[7:]
+// freshTimestamp reports whether the Unix timestamp is within the configured tolerance
+// of now, in either direction. Unparseable timestamps are never fresh
+func (h *WebhookHandler) freshTimestamp(timestamp string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if h.cfg.WebhookTimestampTolerance <= 0 {
+		return true
 	}
 
-	mac := hmac.New(sha256.New, []byte(h.cfg.WebhookSecret))
-	mac.Write(body)
-	expectedMAC := hex.EncodeToString(mac.Sum(nil))
-
-	return hmac.Equal([]byte(signature), []byte(expectedMAC))
+	age := now.Sub(time.Unix(seconds, 0))
+	if age < 0 {
+		age = -age
+	}
+	return age <= h.cfg.WebhookTimestampTolerance
 }
@@ -0,0 +1,418 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/service"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+	"event-coming/internal/whatsapp"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testWebhookSecret = "test-webhook-secret"
+
+func newTestWebhookHandler() *WebhookHandler {
+	cfg := &config.WhatsAppConfig{WebhookSecret: testWebhookSecret}
+	return NewWebhookHandler(cfg, nil, nil, nil, zap.NewNop())
+}
+
+// webhookTestDeps holds the mocks behind a WebhookHandler wired to real services
+type webhookTestDeps struct {
+	participantRepo *mocks.MockParticipantRepository
+	eventRepo       *mocks.MockEventRepository
+	locationRepo    *mocks.MockLocationRepository
+	deliveryRepo    *mocks.MockNotificationDeliveryRepository
+	router          *gin.Engine
+}
+
+func newWebhookTestDeps(t *testing.T) *webhookTestDeps {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	d := &webhookTestDeps{
+		participantRepo: new(mocks.MockParticipantRepository),
+		eventRepo:       new(mocks.MockEventRepository),
+		locationRepo:    new(mocks.MockLocationRepository),
+		deliveryRepo:    new(mocks.MockNotificationDeliveryRepository),
+	}
+
+	participantService := service.NewParticipantService(d.participantRepo, d.eventRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	locationService := service.NewLocationService(d.locationRepo, d.participantRepo, d.eventRepo, nil, nil, &config.LocationConfig{}, zap.NewNop())
+	deliveryService := service.NewDeliveryTrackingService(d.deliveryRepo, nil, zap.NewNop())
+	processed := cache.NewIdempotencyStore(client, "webhook:whatsapp:processed:")
+	keywords := service.NewKeywordMatcher(service.DefaultKeywordSets)
+	inboundService := service.NewInboundMessageService(participantService, locationService, processed, nil, keywords, nil, zap.NewNop())
+	h := NewWebhookHandler(&config.WhatsAppConfig{}, inboundService, deliveryService, nil, zap.NewNop())
+
+	d.router = gin.New()
+	d.router.POST("/webhook/whatsapp", h.HandleWebhook)
+	return d
+}
+
+// deliver posts a webhook carrying a single location message
+func (d *webhookTestDeps) deliver(t *testing.T, messageID, from string) int {
+	t.Helper()
+
+	payload := whatsapp.WebhookPayload{
+		Object: "whatsapp_business_account",
+		Entry: []whatsapp.Entry{{
+			Changes: []whatsapp.Change{{
+				Field: "messages",
+				Value: whatsapp.Value{Messages: []whatsapp.Message{{
+					ID:       messageID,
+					From:     from,
+					Type:     "location",
+					Location: &whatsapp.Location{Latitude: -23.55, Longitude: -46.63},
+				}}},
+			}},
+		}},
+	}
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	return d.post(string(body))
+}
+
+// post sends a raw webhook body
+func (d *webhookTestDeps) post(body string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	d.router.ServeHTTP(w, req)
+	return w.Code
+}
+
+// signWebhookBody returns the X-Hub-Signature-256 header value for body
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookHandler_VerifySignature(t *testing.T) {
+	h := newTestWebhookHandler()
+	body := []byte(`{"object":"whatsapp_business_account","entry":[]}`)
+	valid := signWebhookBody(testWebhookSecret, body)
+
+	tests := []struct {
+		name      string
+		signature string
+		want      bool
+	}{
+		{name: "valid", signature: valid, want: true},
+		{name: "missing", signature: "", want: false},
+		{name: "wrong secret", signature: signWebhookBody("other-secret", body), want: false},
+		{name: "tampered body", signature: signWebhookBody(testWebhookSecret, append(body, ' ')), want: false},
+		{name: "missing prefix", signature: strings.TrimPrefix(valid, "sha256="), want: false},
+		{name: "not hex", signature: "sha256=not-hex", want: false},
+		{name: "uppercase hex", signature: "sha256=" + strings.ToUpper(strings.TrimPrefix(valid, "sha256=")), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, h.verifySignature(body, tt.signature))
+		})
+	}
+}
+
+func TestWebhookHandler_HandleWebhook_Signature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"object":"whatsapp_business_account","entry":[]}`
+
+	tests := []struct {
+		name       string
+		signature  string
+		wantStatus int
+	}{
+		{name: "valid signature", signature: signWebhookBody(testWebhookSecret, []byte(body)), wantStatus: http.StatusOK},
+		{name: "invalid signature", signature: signWebhookBody("other-secret", []byte(body)), wantStatus: http.StatusUnauthorized},
+		{name: "missing signature", signature: "", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/webhook/whatsapp", newTestWebhookHandler().HandleWebhook)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_HandleWebhook_Timestamp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := []byte(`{"object":"whatsapp_business_account","entry":[]}`)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name       string
+		timestamp  string
+		signedAs   string
+		wantStatus int
+	}{
+		{name: "fresh", timestamp: strconv.FormatInt(now, 10), wantStatus: http.StatusOK},
+		{name: "stale", timestamp: strconv.FormatInt(now-600, 10), wantStatus: http.StatusUnauthorized},
+		{name: "too far in the future", timestamp: strconv.FormatInt(now+600, 10), wantStatus: http.StatusUnauthorized},
+		{name: "not a number", timestamp: "yesterday", wantStatus: http.StatusUnauthorized},
+		// A captured request can't be refreshed by rewriting the header
+		{name: "timestamp swapped after signing", timestamp: strconv.FormatInt(now, 10), signedAs: strconv.FormatInt(now-600, 10), wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestWebhookHandler()
+			h.cfg.WebhookTimestampTolerance = 5 * time.Minute
+			r := gin.New()
+			r.POST("/webhook/whatsapp", h.HandleWebhook)
+
+			signedAs := tt.signedAs
+			if signedAs == "" {
+				signedAs = tt.timestamp
+			}
+			req := httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", bytes.NewReader(body))
+			req.Header.Set(webhookTimestampHeader, tt.timestamp)
+			req.Header.Set("X-Hub-Signature-256", signWebhookBody(testWebhookSecret, signedPayload(signedAs, body)))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_HandleWebhook_RestoresBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"object":"whatsapp_business_account","entry":[]}`
+	var seen string
+
+	r := gin.New()
+	r.POST("/webhook/whatsapp", newTestWebhookHandler().HandleWebhook, func(c *gin.Context) {
+		data, _ := c.GetRawData()
+		seen = string(data)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/whatsapp", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature-256", signWebhookBody(testWebhookSecret, []byte(body)))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, seen)
+}
+
+func TestWebhookHandler_HandleWebhook_SkipsRedeliveredMessage(t *testing.T) {
+	d := newWebhookTestDeps(t)
+	participant := testutil.NewTestParticipant()
+	participant.LocationConsent = true
+	event := testutil.NewTestEvent()
+
+	d.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	d.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+	d.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+	d.locationRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Location")).Return(nil)
+
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.1", "5511999999999"))
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.1", "5511999999999"))
+
+	d.locationRepo.AssertNumberOfCalls(t, "Create", 1)
+
+	// A different message id is processed normally
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.2", "5511999999999"))
+	d.locationRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestWebhookHandler_HandleWebhook_FailedMessageIsProcessedOnRedelivery(t *testing.T) {
+	d := newWebhookTestDeps(t)
+	participant := testutil.NewTestParticipant()
+	participant.LocationConsent = true
+	event := testutil.NewTestEvent()
+
+	d.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	d.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+	d.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+	d.locationRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Location")).
+		Return(errors.New("database down")).Once()
+	d.locationRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Location")).Return(nil)
+
+	assert.Equal(t, http.StatusInternalServerError, d.deliver(t, "wamid.1", "5511999999999"))
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.1", "5511999999999"))
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.1", "5511999999999"))
+
+	d.locationRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestWebhookHandler_HandleWebhook_LocationWithoutConsentIsDiscarded(t *testing.T) {
+	d := newWebhookTestDeps(t)
+	participant := testutil.NewTestParticipant()
+
+	d.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	d.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+
+	// Acknowledged so the provider stops retrying, and not processed again on redelivery
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.1", "5511999999999"))
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.1", "5511999999999"))
+
+	d.participantRepo.AssertNumberOfCalls(t, "GetActiveByPhoneNumber", 1)
+	d.locationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookHandler_HandleWebhook_UnknownNumberIsAcknowledged(t *testing.T) {
+	d := newWebhookTestDeps(t)
+
+	d.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511000000000").Return(nil, domain.ErrNotFound)
+
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.1", "5511000000000"))
+	assert.Equal(t, http.StatusOK, d.deliver(t, "wamid.1", "5511000000000"))
+
+	d.participantRepo.AssertNumberOfCalls(t, "GetActiveByPhoneNumber", 1)
+	d.locationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookHandler_HandleWebhook_UpdatesDeliveryLogFromStatuses(t *testing.T) {
+	d := newWebhookTestDeps(t)
+
+	delivered := &domain.NotificationDelivery{ProviderMessageID: "wamid.sent", Status: domain.DeliveryStatusSent}
+	failed := &domain.NotificationDelivery{ProviderMessageID: "wamid.failed", Status: domain.DeliveryStatusSent}
+	d.deliveryRepo.On("GetByProviderMessageID", mock.Anything, "wamid.sent").Return(delivered, nil)
+	d.deliveryRepo.On("GetByProviderMessageID", mock.Anything, "wamid.failed").Return(failed, nil)
+	d.deliveryRepo.On("GetByProviderMessageID", mock.Anything, "wamid.unknown").Return(nil, domain.ErrNotFound)
+	d.deliveryRepo.On("UpdateStatus", mock.Anything, mock.AnythingOfType("*domain.NotificationDelivery")).Return(nil)
+
+	body := `{
+		"object": "whatsapp_business_account",
+		"entry": [{"changes": [{"field": "messages", "value": {"statuses": [
+			{"id": "wamid.sent", "status": "delivered", "timestamp": "1700000000", "recipient_id": "5511999999999"},
+			{"id": "wamid.failed", "status": "failed", "timestamp": "1700000000", "recipient_id": "5511999999999",
+				"errors": [{"code": 131026, "title": "Message undeliverable"}]},
+			{"id": "wamid.unknown", "status": "read", "timestamp": "1700000000", "recipient_id": "5511999999999"}
+		]}}]}]
+	}`
+
+	assert.Equal(t, http.StatusOK, d.post(body))
+
+	assert.Equal(t, domain.DeliveryStatusDelivered, delivered.Status)
+	assert.Equal(t, domain.DeliveryStatusFailed, failed.Status)
+	require.NotNil(t, failed.ErrorCode)
+	assert.Equal(t, 131026, *failed.ErrorCode)
+	require.NotNil(t, failed.ErrorMessage)
+	assert.Equal(t, "Message undeliverable", *failed.ErrorMessage)
+	d.deliveryRepo.AssertNumberOfCalls(t, "UpdateStatus", 2)
+}
+
+func TestWebhookHandler_HandleWebhook_ListReplySelectsSessionSlot(t *testing.T) {
+	d := newWebhookTestDeps(t)
+
+	participant := testutil.NewTestParticipant()
+	participant.Metadata = map[string]interface{}{"team": "blue"}
+	event := testutil.NewTestEvent()
+	confirmed := domain.ParticipantStatusConfirmed
+	event.ResponseOptions = domain.ResponseOptions{
+		"session_morning":   {Title: "Morning", Status: &confirmed, Metadata: map[string]interface{}{"session": "morning"}},
+		"session_afternoon": {Title: "Afternoon", Status: &confirmed, Metadata: map[string]interface{}{"session": "afternoon"}},
+	}
+
+	d.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	d.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+	d.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+
+	var input *domain.UpdateParticipantInput
+	d.participantRepo.On("Update", mock.Anything, participant.ID, participant.EntityID, mock.AnythingOfType("*domain.UpdateParticipantInput")).
+		Run(func(args mock.Arguments) { input = args.Get(3).(*domain.UpdateParticipantInput) }).
+		Return(nil)
+
+	body := `{
+		"object": "whatsapp_business_account",
+		"entry": [{"changes": [{"field": "messages", "value": {"messages": [{
+			"id": "wamid.list", "from": "5511999999999", "timestamp": "1700000000", "type": "interactive",
+			"interactive": {"type": "list_reply", "list_reply": {"id": "session_afternoon", "title": "Afternoon"}}
+		}]}}]}]
+	}`
+
+	assert.Equal(t, http.StatusOK, d.post(body))
+
+	require.NotNil(t, input)
+	require.NotNil(t, input.Status)
+	assert.Equal(t, domain.ParticipantStatusConfirmed, *input.Status)
+	assert.Equal(t, "afternoon", input.Metadata["session"])
+	assert.Equal(t, "session_afternoon", input.Metadata["response_option"])
+	assert.Equal(t, "blue", input.Metadata["team"], "existing metadata is kept")
+}
+
+func TestWebhookHandler_HandleWebhook_UnknownButtonFallsBackToConfirmation(t *testing.T) {
+	d := newWebhookTestDeps(t)
+
+	participant := testutil.NewTestParticipant()
+	event := testutil.NewTestEvent()
+
+	d.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	d.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+	d.participantRepo.On("UpdateStatus", mock.Anything, participant.ID, participant.EntityID, domain.ParticipantStatusDenied).Return(nil)
+	d.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+
+	body := `{
+		"object": "whatsapp_business_account",
+		"entry": [{"changes": [{"field": "messages", "value": {"messages": [{
+			"id": "wamid.button", "from": "5511999999999", "timestamp": "1700000000", "type": "interactive",
+			"interactive": {"type": "button_reply", "button_reply": {"id": "confirm_no", "title": "No"}}
+		}]}}]}]
+	}`
+
+	assert.Equal(t, http.StatusOK, d.post(body))
+	d.participantRepo.AssertCalled(t, "UpdateStatus", mock.Anything, participant.ID, participant.EntityID, domain.ParticipantStatusDenied)
+}
+
+func TestWebhookHandler_HandleWebhook_FreeTextConfirmation(t *testing.T) {
+	d := newWebhookTestDeps(t)
+
+	participant := testutil.NewTestParticipant()
+	d.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	d.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(testutil.NewTestEvent(), nil)
+	d.participantRepo.On("UpdateStatus", mock.Anything, participant.ID, participant.EntityID, domain.ParticipantStatusConfirmed).Return(nil)
+	d.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+
+	body := `{
+		"object": "whatsapp_business_account",
+		"entry": [{"changes": [{"field": "messages", "value": {"messages": [{
+			"id": "wamid.text", "from": "5511999999999", "timestamp": "1700000000", "type": "text",
+			"text": {"body": "Sim! Estarei lá"}
+		}]}}]}]
+	}`
+
+	assert.Equal(t, http.StatusOK, d.post(body))
+	d.participantRepo.AssertCalled(t, "UpdateStatus", mock.Anything, participant.ID, participant.EntityID, domain.ParticipantStatusConfirmed)
+}
@@ -1,11 +1,25 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/handler/middleware"
+	"event-coming/internal/service"
 	"event-coming/internal/websocket"
+	"event-coming/pkg/response"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	gorillaws "github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
@@ -21,34 +35,89 @@ var upgrader = gorillaws.Upgrader{
 
 // WebSocketHandler gerencia conexões WebSocket
 type WebSocketHandler struct {
-	hub    *websocket.Hub
-	pubsub *websocket.PubSub
-	logger *zap.Logger
+	hub                *websocket.Hub
+	pubsub             *websocket.PubSub
+	eventService       *service.EventService
+	participantService *service.ParticipantService
+	locationService    *service.LocationService
+	jwtConfig          *config.JWTConfig
+	denylist           *cache.TokenDenylist
+	logger             *zap.Logger
 }
 
 // NewWebSocketHandler cria um novo handler de WebSocket
-func NewWebSocketHandler(hub *websocket.Hub, pubsub *websocket.PubSub, logger *zap.Logger) *WebSocketHandler {
+func NewWebSocketHandler(
+	hub *websocket.Hub,
+	pubsub *websocket.PubSub,
+	eventService *service.EventService,
+	participantService *service.ParticipantService,
+	locationService *service.LocationService,
+	jwtConfig *config.JWTConfig,
+	denylist *cache.TokenDenylist,
+	logger *zap.Logger,
+) *WebSocketHandler {
 	return &WebSocketHandler{
-		hub:    hub,
-		pubsub: pubsub,
-		logger: logger,
+		hub:                hub,
+		pubsub:             pubsub,
+		eventService:       eventService,
+		participantService: participantService,
+		locationService:    locationService,
+		jwtConfig:          jwtConfig,
+		denylist:           denylist,
+		logger:             logger,
 	}
 }
 
+// HubStats retorna os contadores de backpressure do hub, exportados em /metrics
+func (h *WebSocketHandler) HubStats() (droppedMessages, disconnectedClients int64) {
+	stats := h.hub.GetStats()
+	return stats.DroppedMessages, stats.DisconnectedClients
+}
+
 // HandleConnection processa novas conexões WebSocket
-// GET /api/ws/:entity/:event
+// GET /api/v1/ws/:event?token=<access_token>&participant=<participant_id>
+// O token também pode ser enviado no header "Authorization: Bearer <token>".
+// O parâmetro participant é opcional e habilita o envio de localizações pelo socket; ele
+// precisa ser o participante do próprio usuário no evento (pelo telefone do cadastro).
+// A validação acontece antes do upgrade para que erros retornem 401/403 em HTTP.
 func (h *WebSocketHandler) HandleConnection(c *gin.Context) {
-	entityID := c.Param("entity")
-	eventID := c.Param("event")
-
-	if entityID == "" || eventID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "organization and event are required"})
+	entityUUID, eventUUID, userIDStr, ok := h.authorizeEvent(c, "event")
+	if !ok {
 		return
 	}
 
-	// Obter userID do contexto (se autenticado)
-	userID, _ := c.Get("user_id")
-	userIDStr, _ := userID.(string)
+	// Participante que poderá enviar localizações por este socket
+	var participantIDStr string
+	if requested := c.Query("participant"); requested != "" {
+		participantID, err := uuid.Parse(requested)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "invalid_id", "Invalid participant ID")
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			response.Error(c, http.StatusForbidden, "forbidden", "Participant does not belong to user")
+			return
+		}
+		participant, err := h.eventService.ParticipantForUser(c.Request.Context(), entityUUID, eventUUID, userID)
+		if err != nil {
+			if !errors.Is(err, domain.ErrNotFound) {
+				h.logger.Error("Failed to resolve WebSocket participant", zap.Error(err))
+				response.Error(c, http.StatusInternalServerError, "internal_error", "Failed to resolve participant")
+				return
+			}
+			response.Error(c, http.StatusForbidden, "forbidden", "Participant does not belong to user")
+			return
+		}
+		if participant.ID != participantID {
+			response.Error(c, http.StatusForbidden, "forbidden", "Participant does not belong to user")
+			return
+		}
+		participantIDStr = participantID.String()
+	}
+
+	entityID := entityUUID.String()
+	eventID := eventUUID.String()
 
 	// Upgrade para WebSocket
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
@@ -58,21 +127,14 @@ func (h *WebSocketHandler) HandleConnection(c *gin.Context) {
 	}
 
 	// Criar cliente
-	client := websocket.NewClient(conn, h.hub, entityID, eventID, userIDStr, h.logger)
+	client := websocket.NewClient(conn, h.hub, entityID, eventID, userIDStr, participantIDStr, h.logger)
 
-	// Registrar no hub
-	h.hub.Register(client)
+	// Enviar o histórico recente antes de registrar, para que as mensagens
+	// ao vivo cheguem depois do snapshot
+	h.replayRecent(c.Request.Context(), client, entityID, eventID)
 
-	// Inscrever no Redis PubSub para este evento (se ainda não inscrito)
-	go func() {
-		if err := h.pubsub.Subscribe(c.Request.Context(), entityID, eventID); err != nil {
-			h.logger.Warn("Failed to subscribe to Redis channel",
-				zap.String("org_id", entityID),
-				zap.String("event_id", eventID),
-				zap.Error(err),
-			)
-		}
-	}()
+	// Registrar no hub (o primeiro cliente do evento ativa a inscrição no Redis)
+	h.hub.Register(client)
 
 	// Iniciar goroutines de leitura e escrita
 	go client.WritePump()
@@ -85,6 +147,264 @@ func (h *WebSocketHandler) HandleConnection(c *gin.Context) {
 	)
 }
 
+// HandleStream envia as atualizações do evento por server-sent events, alternativa ao
+// WebSocket para redes que bloqueiam o upgrade
+// GET /api/v1/events/:id/stream?token=<access_token>
+// Autenticação igual à do WebSocket (o EventSource do navegador não envia headers, daí o
+// query param). Cada mensagem é um "data:" com o mesmo JSON do WebSocket; o stream é
+// só de leitura, e comentários periódicos mantêm a conexão viva em proxies
+func (h *WebSocketHandler) HandleStream(c *gin.Context) {
+	entityUUID, eventUUID, userIDStr, ok := h.authorizeEvent(c, "id")
+	if !ok {
+		return
+	}
+
+	entityID := entityUUID.String()
+	eventID := eventUUID.String()
+	client := websocket.NewStreamClient(h.hub, entityID, eventID, userIDStr, h.logger)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Sem buffer no nginx
+	c.Status(http.StatusOK)
+
+	// Mesma ordem do WebSocket: histórico antes do registro, ao vivo depois
+	h.replayRecent(c.Request.Context(), client, entityID, eventID)
+	h.hub.Register(client)
+	defer h.hub.Unregister(client)
+
+	h.logger.Info("SSE stream established",
+		zap.String("org_id", entityID),
+		zap.String("event_id", eventID),
+		zap.String("client_id", client.ID),
+	)
+
+	cfg := h.hub.Config()
+	rc := http.NewResponseController(c.Writer)
+	ticker := time.NewTicker(cfg.PingPeriod)
+	defer ticker.Stop()
+
+	// write escreve e envia o trecho na hora; o prazo por escrita substitui o WriteTimeout
+	// do servidor, que cortaria o stream
+	write := func(chunk string) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+		if _, err := io.WriteString(c.Writer, chunk); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !write(": connected\n\n") {
+		return
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case message, ok := <-client.Messages():
+			// Hub desconectou o cliente (backpressure ou shutdown)
+			if !ok {
+				return
+			}
+			if !write("data: " + string(message) + "\n\n") {
+				return
+			}
+
+		case <-ticker.C:
+			if !write(": ping\n\n") {
+				return
+			}
+		}
+	}
+}
+
+// authorizeEvent valida o token de uma conexão em tempo real (WebSocket ou SSE), enviado
+// no header "Authorization: Bearer <token>" ou no query param "token", e se o evento do
+// parâmetro pertence à entidade dele. Responde com o erro e retorna ok false quando não
+func (h *WebSocketHandler) authorizeEvent(c *gin.Context, eventParam string) (entityUUID, eventUUID uuid.UUID, userID string, ok bool) {
+	eventUUID, err := uuid.Parse(c.Param(eventParam))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid_id", "Invalid event ID")
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	tokenString := extractWebSocketToken(c)
+	if tokenString == "" {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "Missing access token")
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	claims, err := middleware.ValidateAccessToken(c.Request.Context(), h.jwtConfig, h.denylist, tokenString, h.logger)
+	if err != nil {
+		if errors.Is(err, domain.ErrTokenCheckUnavailable) {
+			response.FromError(c, err)
+		} else {
+			response.Error(c, http.StatusUnauthorized, "unauthorized", "Invalid token")
+		}
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	entityIDStr, _ := claims["entity_id"].(string)
+	entityUUID, err = uuid.Parse(entityIDStr)
+	if err != nil {
+		response.Error(c, http.StatusForbidden, "forbidden", "Token is not bound to an entity")
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	// Se o cliente informar a entidade explicitamente, ela deve bater com a do token
+	if requested := c.Query("entity"); requested != "" && requested != entityUUID.String() {
+		response.Error(c, http.StatusForbidden, "forbidden", "Entity does not match token")
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	// O evento precisa pertencer à entidade do token
+	if _, err := h.eventService.GetByID(c.Request.Context(), entityUUID, eventUUID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.Error(c, http.StatusForbidden, "forbidden", "Event does not belong to entity")
+			return uuid.Nil, uuid.Nil, "", false
+		}
+		h.logger.Error("Failed to validate event for real-time connection", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "internal_error", "Failed to validate event")
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	userID, _ = claims["user_id"].(string)
+	return entityUUID, eventUUID, userID, true
+}
+
+// inboundLocationErrors são os erros de domínio com código fixo para o cliente; os demais
+// (banco, cache) chegam a ele só como mensagem genérica
+var inboundLocationErrors = []struct {
+	err     error
+	code    string
+	message string
+}{
+	{domain.ErrForbidden, "forbidden", "Connection cannot send locations for this participant"},
+	{domain.ErrInvalidInput, "invalid_payload", "Invalid location"},
+	{domain.ErrNotFound, "not_found", "Participant not found"},
+	{domain.ErrLocationConsentRequired, "location_consent_required", "Participant has not consented to location sharing"},
+}
+
+// HandleLocation recebe uma localização enviada pelo cliente e a grava
+// em nome do participante vinculado à conexão
+func (h *WebSocketHandler) HandleLocation(ctx context.Context, client *websocket.Client, data *websocket.InboundLocationData) error {
+	err := h.handleLocation(ctx, client, data)
+	if err == nil {
+		return nil
+	}
+	for _, known := range inboundLocationErrors {
+		if errors.Is(err, known.err) {
+			return fmt.Errorf("%w: %w", &websocket.InboundError{Code: known.code, Message: known.message}, err)
+		}
+	}
+	return err
+}
+
+func (h *WebSocketHandler) handleLocation(ctx context.Context, client *websocket.Client, data *websocket.InboundLocationData) error {
+	if client.ParticipantID == "" {
+		return domain.ErrForbidden
+	}
+
+	// Impede que um cliente envie localização em nome de outro participante
+	if data.ParticipantID != "" && data.ParticipantID != client.ParticipantID {
+		return domain.ErrForbidden
+	}
+
+	if data.Latitude < -90 || data.Latitude > 90 || data.Longitude < -180 || data.Longitude > 180 {
+		return domain.ErrInvalidInput
+	}
+
+	participantID, err := uuid.Parse(client.ParticipantID)
+	if err != nil {
+		return domain.ErrForbidden
+	}
+	entityID, err := uuid.Parse(client.EntityID)
+	if err != nil {
+		return domain.ErrForbidden
+	}
+
+	_, err = h.locationService.CreateLocation(ctx, participantID, entityID, &dto.CreateLocationRequest{
+		Latitude:  data.Latitude,
+		Longitude: data.Longitude,
+		Accuracy:  data.Accuracy,
+		Altitude:  data.Altitude,
+		Speed:     data.Speed,
+		Heading:   data.Heading,
+		Timestamp: data.Timestamp,
+	})
+	return err
+}
+
+// replayRecent envia ao cliente as últimas mensagens do evento
+func (h *WebSocketHandler) replayRecent(ctx context.Context, client *websocket.Client, entityID, eventID string) {
+	messages, err := h.pubsub.GetRecent(ctx, entityID, eventID)
+	if err != nil {
+		h.logger.Warn("Failed to load message history",
+			zap.String("event_id", eventID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, msg := range messages {
+		if err := h.hub.SendTo(client, msg); err != nil {
+			h.logger.Warn("Message history replay truncated",
+				zap.String("client_id", client.ID),
+				zap.Error(err),
+			)
+			return
+		}
+	}
+}
+
+// GetPresence retorna os usuários conectados ao WebSocket de um evento
+// GET /api/v1/events/:id/presence
+func (h *WebSocketHandler) GetPresence(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid_id", "Invalid event ID")
+		return
+	}
+
+	entityID, exists := c.Get("entity_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "unauthorized", "Entity not found in context")
+		return
+	}
+	entID := entityID.(uuid.UUID)
+
+	if _, err := h.eventService.GetByID(c.Request.Context(), entID, eventID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.Error(c, http.StatusNotFound, "not_found", "Event not found")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+
+	presence := h.hub.GetPresence(entID.String(), eventID.String())
+
+	response.Success(c, gin.H{
+		"event_id": eventID,
+		"users":    presence,
+		"total":    len(presence),
+	})
+}
+
+// extractWebSocketToken obtém o token do header Authorization ou do query param "token"
+func extractWebSocketToken(c *gin.Context) string {
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			return parts[1]
+		}
+		return ""
+	}
+	return c.Query("token")
+}
+
 // GetConnectionCount retorna o número de conexões para um evento
 // GET /api/v1/events/:org/:event/connections
 // func (h *WebSocketHandler) GetConnectionCount(c *gin.Context) {
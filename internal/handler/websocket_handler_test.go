@@ -0,0 +1,504 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/service"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+	"event-coming/internal/websocket"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	gorillaws "github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testAccessSecret = "test-access-secret"
+
+// webSocketTestDeps reúne os mocks usados pelo WebSocketHandler
+type webSocketTestDeps struct {
+	eventRepo       *mocks.MockEventRepository
+	userRepo        *mocks.MockUserRepository
+	participantRepo *mocks.MockParticipantRepository
+	locationRepo    *mocks.MockLocationRepository
+	hub             *websocket.Hub
+	pubsub          *websocket.PubSub
+	redis           *miniredis.Miniredis
+	// stopHub para o loop do hub e espera ele terminar
+	stopHub func()
+}
+
+func newWebSocketTestDeps() *webSocketTestDeps {
+	return &webSocketTestDeps{
+		eventRepo:       new(mocks.MockEventRepository),
+		userRepo:        new(mocks.MockUserRepository),
+		participantRepo: new(mocks.MockParticipantRepository),
+		locationRepo:    new(mocks.MockLocationRepository),
+	}
+}
+
+// withHub liga um hub em execução e um PubSub sobre um Redis em memória
+func (d *webSocketTestDeps) withHub(t *testing.T, wsConfig *config.WebSocketConfig) *webSocketTestDeps {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+
+	d.hub = websocket.NewHub(websocket.NewConfig(wsConfig), zap.NewNop())
+	stopped := make(chan struct{})
+	go func() {
+		d.hub.Run(ctx)
+		close(stopped)
+	}()
+	d.stopHub = func() {
+		cancel()
+		<-stopped
+	}
+	d.pubsub = websocket.NewPubSub(redisClient, d.hub, zap.NewNop())
+	d.redis = mr
+	return d
+}
+
+func (d *webSocketTestDeps) handler() *WebSocketHandler {
+	eventService := service.NewEventService(d.eventRepo, nil, d.userRepo, new(mocks.MockSchedulerRepository), d.participantRepo, nil, nil, nil, nil, nil, new(mocks.MockTransactor), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	participantService := service.NewParticipantService(d.participantRepo, d.eventRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	locationService := service.NewLocationService(d.locationRepo, d.participantRepo, d.eventRepo, nil, nil, &config.LocationConfig{}, zap.NewNop())
+	h := NewWebSocketHandler(d.hub, d.pubsub, eventService, participantService, locationService, &config.JWTConfig{AccessSecret: testAccessSecret}, nil, zap.NewNop())
+	if d.hub != nil {
+		d.hub.SetInboundHandler(h)
+	}
+	return h
+}
+
+func (d *webSocketTestDeps) router() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	h := d.handler()
+	r.GET("/ws/:event", h.HandleConnection)
+	r.GET("/events/:id/stream", h.HandleStream)
+	return r
+}
+
+// dial abre uma conexão WebSocket real contra o router
+func (d *webSocketTestDeps) dial(t *testing.T, query string) *gorillaws.Conn {
+	t.Helper()
+
+	server := httptest.NewServer(d.router())
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/" + testutil.TestEventID.String() + "?" + query
+	conn, _, err := gorillaws.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// openStream abre o stream SSE do evento contra o router
+func (d *webSocketTestDeps) openStream(t *testing.T, query string) *http.Response {
+	t.Helper()
+
+	server := httptest.NewServer(d.router())
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	url := server.URL + "/events/" + testutil.TestEventID.String() + "/stream?" + query
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// readStreamMessage lê o próximo "data:" do stream, ignorando comentários
+func readStreamMessage(t *testing.T, stream *bufio.Reader) websocket.Message {
+	t.Helper()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				lines <- data
+				return
+			}
+		}
+	}()
+
+	select {
+	case data, ok := <-lines:
+		require.True(t, ok, "stream closed")
+		var msg websocket.Message
+		require.NoError(t, json.Unmarshal([]byte(data), &msg))
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message on the stream")
+		return websocket.Message{}
+	}
+}
+
+func readTestMessage(t *testing.T, conn *gorillaws.Conn) websocket.Message {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var msg websocket.Message
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func signTestAccessToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func testAccessClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id":   testutil.TestUserID.String(),
+		"entity_id": testutil.TestEntityID.String(),
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestWebSocketHandler_HandleConnection_RejectsBeforeUpgrade(t *testing.T) {
+	eventPath := "/ws/" + testutil.TestEventID.String()
+	validToken := signTestAccessToken(t, testAccessSecret, testAccessClaims())
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		eventFound bool
+		wantStatus int
+	}{
+		{
+			name:       "missing token",
+			path:       eventPath,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "malformed token",
+			path:       eventPath + "?token=not-a-jwt",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token signed with another secret",
+			path:       eventPath + "?token=" + signTestAccessToken(t, "other-secret", testAccessClaims()),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "expired token",
+			path: eventPath + "?token=" + signTestAccessToken(t, testAccessSecret, jwt.MapClaims{
+				"entity_id": testutil.TestEntityID.String(),
+				"exp":       time.Now().Add(-time.Minute).Unix(),
+			}),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "non bearer authorization header",
+			path:       eventPath,
+			header:     "Basic " + validToken,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "token without entity",
+			path: eventPath + "?token=" + signTestAccessToken(t, testAccessSecret, jwt.MapClaims{
+				"user_id": testutil.TestUserID.String(),
+				"exp":     time.Now().Add(time.Hour).Unix(),
+			}),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "entity does not match token",
+			path:       eventPath + "?entity=" + testutil.TestUserID.String() + "&token=" + validToken,
+			eventFound: true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "event of another entity",
+			path:       eventPath,
+			header:     "Bearer " + validToken,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid event id",
+			path:       "/ws/not-a-uuid?token=" + validToken,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "participant of another user",
+			path:       eventPath + "?participant=" + uuid.NewString() + "&token=" + validToken,
+			eventFound: true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid participant id",
+			path:       eventPath + "?participant=abc&token=" + validToken,
+			eventFound: true,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newWebSocketTestDeps()
+			if tt.eventFound {
+				deps.eventRepo.On("GetByID", mock.Anything, testutil.TestEventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+			} else {
+				deps.eventRepo.On("GetByID", mock.Anything, testutil.TestEventID, testutil.TestEntityID).Return(nil, domain.ErrNotFound)
+			}
+			user := testutil.NewTestUser()
+			deps.userRepo.On("GetByID", mock.Anything, testutil.TestUserID).Return(user, nil)
+			deps.participantRepo.On("GetByPhoneNumber", mock.Anything, *user.Phone, testutil.TestEventID, testutil.TestEntityID).
+				Return(testutil.NewTestParticipant(), nil)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			deps.router().ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestWebSocketHandler_HandleConnection_ReplaysRecentMessages(t *testing.T) {
+	deps := newWebSocketTestDeps().withHub(t, &config.WebSocketConfig{ReplaySize: 10})
+	deps.eventRepo.On("GetByID", mock.Anything, testutil.TestEventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	ctx := context.Background()
+	entityID, eventID := testutil.TestEntityID.String(), testutil.TestEventID.String()
+	for _, name := range []string{"first", "second"} {
+		require.NoError(t, deps.pubsub.PublishLocationUpdate(ctx, entityID, eventID, &websocket.LocationUpdateData{ParticipantName: name}))
+	}
+
+	conn := deps.dial(t, "token="+signTestAccessToken(t, testAccessSecret, testAccessClaims()))
+
+	for _, want := range []string{"first", "second"} {
+		msg := readTestMessage(t, conn)
+		assert.Equal(t, websocket.MessageTypeLocationUpdate, msg.Type)
+
+		var data websocket.LocationUpdateData
+		require.NoError(t, json.Unmarshal(msg.Data, &data))
+		assert.Equal(t, want, data.ParticipantName)
+	}
+}
+
+func TestWebSocketHandler_HandleStream_RejectsLikeWebSocket(t *testing.T) {
+	streamPath := "/events/" + testutil.TestEventID.String() + "/stream"
+	validToken := signTestAccessToken(t, testAccessSecret, testAccessClaims())
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"missing token", streamPath, http.StatusUnauthorized},
+		{"token signed with another secret", streamPath + "?token=" + signTestAccessToken(t, "other-secret", testAccessClaims()), http.StatusUnauthorized},
+		{"event of another entity", streamPath + "?token=" + validToken, http.StatusForbidden},
+		{"invalid event id", "/events/not-a-uuid/stream?token=" + validToken, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newWebSocketTestDeps()
+			deps.eventRepo.On("GetByID", mock.Anything, testutil.TestEventID, testutil.TestEntityID).Return(nil, domain.ErrNotFound)
+
+			w := httptest.NewRecorder()
+			deps.router().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.NotEqual(t, "text/event-stream", w.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestWebSocketHandler_HandleStream_DeliversPublishedMessages(t *testing.T) {
+	deps := newWebSocketTestDeps().withHub(t, &config.WebSocketConfig{ReplaySize: 10})
+	deps.eventRepo.On("GetByID", mock.Anything, testutil.TestEventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, deps.pubsub.Start(ctx))
+
+	entityID, eventID := testutil.TestEntityID.String(), testutil.TestEventID.String()
+	require.NoError(t, deps.pubsub.PublishLocationUpdate(ctx, entityID, eventID, &websocket.LocationUpdateData{ParticipantName: "before"}))
+
+	resp := deps.openStream(t, "token="+signTestAccessToken(t, testAccessSecret, testAccessClaims()))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	stream := bufio.NewReader(resp.Body)
+
+	// O histórico chega primeiro, como no WebSocket
+	msg := readStreamMessage(t, stream)
+	var location websocket.LocationUpdateData
+	require.NoError(t, json.Unmarshal(msg.Data, &location))
+	assert.Equal(t, "before", location.ParticipantName)
+
+	// Presença do usuário conectado pelo stream
+	msg = readStreamMessage(t, stream)
+	assert.Equal(t, websocket.MessageTypeParticipantJoin, msg.Type)
+
+	// Publicado por qualquer instância no Redis, depois da inscrição no canal do evento
+	channel := "ws:event:" + entityID + ":" + eventID
+	require.Eventually(t, func() bool {
+		return deps.redis.PubSubNumSub(channel)[channel] == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, deps.pubsub.PublishETAUpdate(ctx, entityID, eventID, &websocket.ETAUpdateData{ETAMinutes: 12}))
+
+	msg = readStreamMessage(t, stream)
+	assert.Equal(t, websocket.MessageTypeETAUpdate, msg.Type)
+	var eta websocket.ETAUpdateData
+	require.NoError(t, json.Unmarshal(msg.Data, &eta))
+	assert.Equal(t, 12, eta.ETAMinutes)
+
+	// Ao fechar a conexão o cliente sai do hub
+	resp.Body.Close()
+	require.Eventually(t, func() bool {
+		return deps.hub.GetClientCount(entityID, eventID) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketHandler_HandleStream_DisconnectsAfterHubStops(t *testing.T) {
+	deps := newWebSocketTestDeps().withHub(t, &config.WebSocketConfig{})
+	deps.eventRepo.On("GetByID", mock.Anything, testutil.TestEventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	resp := deps.openStream(t, "token="+signTestAccessToken(t, testAccessSecret, testAccessClaims()))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	msg := readStreamMessage(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, websocket.MessageTypeParticipantJoin, msg.Type)
+
+	// Com o loop do hub parado (shutdown), sair do stream não pode ficar preso no hub
+	deps.stopHub()
+	resp.Body.Close()
+
+	entityID, eventID := testutil.TestEntityID.String(), testutil.TestEventID.String()
+	require.Eventually(t, func() bool {
+		return deps.hub.GetClientCount(entityID, eventID) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketHandler_InboundLocation(t *testing.T) {
+	deps := newWebSocketTestDeps().withHub(t, &config.WebSocketConfig{})
+
+	user := testutil.NewTestUser()
+	participant := testutil.NewTestParticipant()
+	participant.LocationConsent = true
+	deps.eventRepo.On("GetByID", mock.Anything, testutil.TestEventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	deps.userRepo.On("GetByID", mock.Anything, testutil.TestUserID).Return(user, nil)
+	deps.participantRepo.On("GetByPhoneNumber", mock.Anything, *user.Phone, testutil.TestEventID, testutil.TestEntityID).Return(participant, nil)
+	deps.participantRepo.On("GetByID", mock.Anything, participant.ID, testutil.TestEntityID).Return(participant, nil)
+
+	stored := make(chan *domain.Location, 1)
+	deps.locationRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Location")).
+		Run(func(args mock.Arguments) { stored <- args.Get(1).(*domain.Location) }).
+		Return(nil)
+
+	conn := deps.dial(t, "participant="+participant.ID.String()+"&token="+signTestAccessToken(t, testAccessSecret, testAccessClaims()))
+
+	send := func(msgType websocket.MessageType, data any) {
+		payload, err := json.Marshal(data)
+		require.NoError(t, err)
+		require.NoError(t, conn.WriteJSON(&websocket.Message{Type: msgType, Timestamp: time.Now(), Data: payload}))
+	}
+	readError := func() websocket.ErrorData {
+		msg := readTestMessage(t, conn)
+		// A própria entrada do usuário chega como presença antes das respostas
+		for msg.Type == websocket.MessageTypeParticipantJoin {
+			msg = readTestMessage(t, conn)
+		}
+		require.Equal(t, websocket.MessageTypeError, msg.Type)
+		var data websocket.ErrorData
+		require.NoError(t, json.Unmarshal(msg.Data, &data))
+		return data
+	}
+
+	t.Run("valid location is stored for the connection's participant", func(t *testing.T) {
+		send(websocket.MessageTypeLocationUpdate, &websocket.InboundLocationData{Latitude: -23.56, Longitude: -46.65})
+
+		select {
+		case loc := <-stored:
+			assert.Equal(t, participant.ID, loc.ParticipantID)
+			assert.Equal(t, testutil.TestEntityID, loc.EntityID)
+			assert.Equal(t, -23.56, loc.Latitude)
+		case <-time.After(2 * time.Second):
+			t.Fatal("location was not stored")
+		}
+	})
+
+	t.Run("spoofed participant id is rejected", func(t *testing.T) {
+		send(websocket.MessageTypeLocationUpdate, &websocket.InboundLocationData{
+			ParticipantID: uuid.NewString(),
+			Latitude:      -23.56,
+			Longitude:     -46.65,
+		})
+
+		assert.Equal(t, "forbidden", readError().Code)
+	})
+
+	t.Run("invalid coordinates are rejected", func(t *testing.T) {
+		send(websocket.MessageTypeLocationUpdate, &websocket.InboundLocationData{Latitude: 91, Longitude: 0})
+
+		assert.Equal(t, "invalid_payload", readError().Code)
+	})
+
+	t.Run("message types clients cannot send are rejected", func(t *testing.T) {
+		send(websocket.MessageTypeEventUpdate, map[string]string{})
+
+		assert.Equal(t, "message_not_allowed", readError().Code)
+	})
+
+	deps.locationRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestWebSocketHandler_HandleLocation_HidesInternalErrors(t *testing.T) {
+	deps := newWebSocketTestDeps()
+	participant := testutil.NewTestParticipant()
+	deps.participantRepo.On("GetByID", mock.Anything, participant.ID, testutil.TestEntityID).
+		Return(nil, errors.New("connection refused"))
+
+	hub := websocket.NewHub(websocket.DefaultConfig(), zap.NewNop())
+	client := websocket.NewClient(nil, hub, testutil.TestEntityID.String(), testutil.TestEventID.String(), "", participant.ID.String(), zap.NewNop())
+	err := deps.handler().HandleLocation(context.Background(), client, &websocket.InboundLocationData{Latitude: 1, Longitude: 1})
+	require.Error(t, err)
+
+	var inboundErr *websocket.InboundError
+	assert.False(t, errors.As(err, &inboundErr), "internal errors must not be shown to the client")
+
+	client = websocket.NewClient(nil, hub, testutil.TestEntityID.String(), testutil.TestEventID.String(), "", "", zap.NewNop())
+	err = deps.handler().HandleLocation(context.Background(), client, &websocket.InboundLocationData{Latitude: 1, Longitude: 1})
+	require.ErrorAs(t, err, &inboundErr)
+	assert.Equal(t, "forbidden", inboundErr.Code)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
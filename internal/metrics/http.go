@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// HTTPMetrics holds the Prometheus collectors for HTTP requests. Routes are
+// labeled by their template (/events/:id), never by the raw path.
+type HTTPMetrics struct {
+	RequestsTotal   *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+	InFlight        *prometheus.GaugeVec
+}
+
+// NewRegistry creates a registry with the Go runtime and process collectors
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
+}
+
+// NewHTTPMetrics creates the HTTP collectors and registers them in reg
+func NewHTTPMetrics(reg prometheus.Registerer) *HTTPMetrics {
+	m := &HTTPMetrics{
+		RequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "event_coming",
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests",
+		}, []string{"method", "route", "status"}),
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "event_coming",
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency in seconds",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		InFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "event_coming",
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served",
+		}, []string{"method", "route"}),
+	}
+
+	reg.MustRegister(m.RequestsTotal, m.RequestDuration, m.InFlight)
+	return m
+}
+
+// Handler serves the metrics of the registry in the Prometheus text format
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg})
+}
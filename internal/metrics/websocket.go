@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WebSocketStats reports the hub's backpressure counters
+type WebSocketStats func() (droppedMessages, disconnectedClients int64)
+
+// NewWebSocketMetrics registers the WebSocket hub backpressure counters in reg. The
+// values are read from stats at scrape time, so the hub keeps its own atomics.
+func NewWebSocketMetrics(reg prometheus.Registerer, stats WebSocketStats) {
+	reg.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "event_coming",
+			Name:      "websocket_dropped_messages_total",
+			Help:      "Messages dropped because a client's send buffer was full",
+		}, func() float64 {
+			dropped, _ := stats()
+			return float64(dropped)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "event_coming",
+			Name:      "websocket_disconnected_clients_total",
+			Help:      "Clients disconnected by the backpressure policy",
+		}, func() float64 {
+			_, disconnected := stats()
+			return float64(disconnected)
+		}),
+	)
+}
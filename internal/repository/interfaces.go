@@ -9,6 +9,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// Transactor runs a unit of work in a database transaction. Repository calls made
+// with the context passed to fn participate in the transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // EntityRepository defines entity data access methods
 type EntityRepository interface {
 	Create(ctx context.Context, entity *domain.Entity) error
@@ -18,6 +24,7 @@ type EntityRepository interface {
 	List(ctx context.Context, page, perPage int) ([]*domain.Entity, int64, error)
 	ListByParent(ctx context.Context, parentID uuid.UUID, page, perPage int) ([]*domain.Entity, int64, error)
 	GetByDocument(ctx context.Context, document string) (*domain.Entity, error)
+	ListDescendants(ctx context.Context, rootID uuid.UUID, maxDepth int) ([]*domain.EntityDescendant, error)
 }
 
 // UserRepository defines user data access methods
@@ -34,19 +41,56 @@ type UserRepository interface {
 	RemoveFromEntity(ctx context.Context, userID, entityID uuid.UUID) error
 	GetUserEntities(ctx context.Context, userID uuid.UUID) ([]*domain.UserEntity, error)
 	GetEntityUsers(ctx context.Context, entityID uuid.UUID) ([]*domain.User, error)
+	GetEntityMembership(ctx context.Context, userID, entityID uuid.UUID) (*domain.UserEntity, error)
+	UpdateEntityRole(ctx context.Context, userID, entityID uuid.UUID, role domain.UserRole) error
+	// LockEntityUsersByRole locks the memberships with the role (SELECT ... FOR UPDATE) until
+	// the surrounding transaction ends and returns how many there are
+	LockEntityUsersByRole(ctx context.Context, entityID uuid.UUID, role domain.UserRole) (int64, error)
 }
 
 // EventRepository defines event data access methods
 type EventRepository interface {
 	Create(ctx context.Context, event *domain.Event) error
 	GetByID(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.Event, error)
+	// GetForUpdate reads the event locking its row (SELECT ... FOR UPDATE) until the
+	// transaction in ctx ends; concurrent callers wait. Must run inside WithinTransaction
+	GetForUpdate(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.Event, error)
 	Update(ctx context.Context, id uuid.UUID, entityID uuid.UUID, input *domain.UpdateEventInput) error
 	Delete(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error
 	List(ctx context.Context, entityID uuid.UUID, page, perPage int) ([]*domain.Event, int64, error)
 	ListByStatus(ctx context.Context, entityID uuid.UUID, status domain.EventStatus, page, perPage int) ([]*domain.Event, int64, error)
+	// ListByParticipantPhone lists the events, of any entity, a phone number participates in.
+	// The phone number must be normalized like the participants' (NormalizePhoneNumber).
+	ListByParticipantPhone(ctx context.Context, phoneNumber string, page, perPage int) ([]*domain.ParticipantEvent, int64, error)
+	// ListActiveByParticipantPhone lists the active events, of any entity, a phone number
+	// participates in, soonest first, with a single query. The phone number must be
+	// normalized like the participants' (NormalizePhoneNumber).
+	ListActiveByParticipantPhone(ctx context.Context, phoneNumber string) ([]*domain.ParticipantEvent, error)
+	// FindDuplicate returns a non-cancelled event of the entity whose normalized name equals
+	// name and whose start time is in [from, to], or ErrNotFound
+	FindDuplicate(ctx context.Context, entityID uuid.UUID, name string, from, to time.Time) (*domain.Event, error)
+	// GetByImportUID returns the event of the entity imported from the ICS VEVENT with the uid, or ErrNotFound
+	GetByImportUID(ctx context.Context, entityID uuid.UUID, uid string) (*domain.Event, error)
+	// ListReminderDigest lists the non-cancelled events of the entity starting in [from, to)
+	// where the phone number (normalized like the participants') is a confirmed participant
+	// not yet covered by a reminder digest, ordered by start time
+	ListReminderDigest(ctx context.Context, entityID uuid.UUID, phoneNumber string, from, to time.Time) ([]*domain.ParticipantEvent, error)
+	// Search finds up to limit events of the entity whose name contains query
+	// (case-insensitive), prefix matches first
+	Search(ctx context.Context, entityID uuid.UUID, query string, limit int) ([]*domain.Event, error)
+	// ListEndedActive lists up to limit active events, of every entity with auto_complete_events,
+	// that ended (end_time, or start_time without one) before endedBefore, oldest first
+	ListEndedActive(ctx context.Context, endedBefore time.Time, limit int) ([]*domain.Event, error)
+	// ListPendingRedaction lists up to limit completed or cancelled events, of every entity,
+	// that ended before endedBefore and whose participants weren't redacted yet, oldest first
+	ListPendingRedaction(ctx context.Context, endedBefore time.Time, limit int) ([]*domain.Event, error)
+	// MarkParticipantsRedacted records when the event's participants were redacted
+	MarkParticipantsRedacted(ctx context.Context, id uuid.UUID, entityID uuid.UUID, at time.Time) error
 
 	// Event instance methods
 	CreateInstance(ctx context.Context, instance *domain.EventInstance) error
+	// UpdateInstance saves the status, times, location and override flag of an instance
+	UpdateInstance(ctx context.Context, instance *domain.EventInstance) error
 	GetInstanceByID(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.EventInstance, error)
 	ListInstances(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.EventInstance, error)
 }
@@ -59,10 +103,43 @@ type ParticipantRepository interface {
 	Delete(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error
 	ListByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID, page, perPage int) ([]*domain.Participant, int64, error)
 	ListByEventInstance(ctx context.Context, instanceID uuid.UUID, entityID uuid.UUID, page, perPage int) ([]*domain.Participant, int64, error)
+	// CountByEventStatus counts all the event's participants by status with a single query
+	CountByEventStatus(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (map[domain.ParticipantStatus]int, error)
+	// ListByGroup lists the participants of the event that are members of the group
+	ListByGroup(ctx context.Context, eventID, groupID, entityID uuid.UUID, page, perPage int) ([]*domain.Participant, int64, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, entityID uuid.UUID, status domain.ParticipantStatus) error
+	// MarkNoShows moves confirmed participants that never checked in to no_show and returns their ids
+	MarkNoShows(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]uuid.UUID, error)
 	GetByPhoneNumber(ctx context.Context, phoneNumber string, eventID uuid.UUID, entityID uuid.UUID) (*domain.Participant, error)
+	// MarkReminderDigest records that the participants' reminders were sent in a digest
+	MarkReminderDigest(ctx context.Context, entityID uuid.UUID, ids []uuid.UUID) error
+	// ListAllByEvent lists every participant of the event, with RefEntity (name and phone) preloaded
+	ListAllByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.Participant, error)
+	// ExistingPhoneNumbers returns which of phoneNumbers already have a participant in the
+	// event, with a single query
+	ExistingPhoneNumbers(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID, phoneNumbers []string) ([]string, error)
 	// GetActiveByPhoneNumber finds a participant by phone number in active events
 	GetActiveByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.Participant, error)
+	// ListMissingPhoneNumber lists, by id after afterID, up to limit participants
+	// without a stored phone number whose referenced entity has one, with RefEntity loaded
+	ListMissingPhoneNumber(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Participant, error)
+	// SetPhoneNumber stores the participant's normalized phone number
+	SetPhoneNumber(ctx context.Context, id uuid.UUID, entityID uuid.UUID, phoneNumber string) error
+	// SetLocationConsent records whether the participant allows location sharing
+	SetLocationConsent(ctx context.Context, id uuid.UUID, entityID uuid.UUID, consent bool) error
+	// SetDeclineReason records why the participant declined; nil clears it
+	SetDeclineReason(ctx context.Context, id uuid.UUID, entityID uuid.UUID, reason *string) error
+	// MarkInviteViewed records the first time the participant opened the invite link;
+	// later opens keep the original timestamp
+	MarkInviteViewed(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error
+	// Search finds up to limit participants of the entity whose registered entity's name
+	// contains query (case-insensitive) or whose phone digits contain the query digits.
+	// RefEntity is preloaded.
+	Search(ctx context.Context, entityID uuid.UUID, query string, limit int) ([]*domain.Participant, error)
+	// RedactByEvent clears the personal data (referenced entity, phone number, metadata,
+	// notes, decline reason) of the event's participants not yet redacted, soft-deleted
+	// ones included, keeping status and timestamps. Returns how many were redacted
+	RedactByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID, at time.Time) (int64, error)
 }
 
 // LocationRepository defines location data access methods
@@ -71,7 +148,11 @@ type LocationRepository interface {
 	BatchCreate(ctx context.Context, locations []*domain.Location) error
 	GetLatestByParticipant(ctx context.Context, participantID uuid.UUID, entityID uuid.UUID) (*domain.Location, error)
 	GetLatestByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.Location, error)
+	// GetFirstByEvent returns the earliest location of each participant of the event
+	GetFirstByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.Location, error)
 	GetHistory(ctx context.Context, participantID uuid.UUID, entityID uuid.UUID, from, to time.Time) ([]*domain.Location, error)
+	// DeleteByEvent deletes every location of the event and returns how many were deleted
+	DeleteByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error)
 }
 
 // SchedulerRepository defines scheduler data access methods
@@ -81,9 +162,22 @@ type SchedulerRepository interface {
 	Update(ctx context.Context, scheduler *domain.Scheduler) error
 	Delete(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error
 	ListPending(ctx context.Context, before time.Time, limit int) ([]*domain.Scheduler, error)
+	// ListDueBetween lists pending tasks of every entity scheduled in [from, to], in firing order
+	ListDueBetween(ctx context.Context, from, to time.Time, limit int) ([]*domain.DueScheduler, error)
+	// ClaimPending atomically reserves up to limit due tasks (status running) so concurrent
+	// workers never get the same task. Running tasks claimed before staleBefore are reclaimed.
+	ClaimPending(ctx context.Context, before time.Time, staleBefore time.Time, limit int) ([]*domain.Scheduler, error)
+	// Claim reserves a single pending task; returns domain.ErrSchedulerNotPending if it isn't pending
+	Claim(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error
+	// ReleaseClaims puts reserved tasks back to pending
+	ReleaseClaims(ctx context.Context, ids []uuid.UUID) error
+	// Reschedule puts a reserved task back to pending with a new scheduled_at
+	Reschedule(ctx context.Context, id uuid.UUID, entityID uuid.UUID, scheduledAt time.Time) error
 	MarkAsProcessed(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error
 	MarkAsFailed(ctx context.Context, id uuid.UUID, entityID uuid.UUID, errorMsg string) error
 	IncrementRetries(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error
+	// UpdateMetadata replaces the task's metadata
+	UpdateMetadata(ctx context.Context, id uuid.UUID, entityID uuid.UUID, metadata map[string]interface{}) error
 }
 
 // RefreshTokenRepository defines refresh token data access methods
@@ -100,7 +194,8 @@ type RefreshTokenRepository interface {
 	// Revogar todos do usuário (reset password, segurança)
 	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error
 
-	DeleteExpired(ctx context.Context) error
+	// Apagar os expirados ou revogados antes de before; retorna quantos foram apagados
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
 
 // PasswordResetTokenRepository defines password reset token data access methods
@@ -109,7 +204,8 @@ type PasswordResetTokenRepository interface {
 	GetByToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error)
 	MarkAsUsed(ctx context.Context, id uuid.UUID) error
 	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
-	DeleteExpired(ctx context.Context) error
+	// DeleteExpired removes tokens that expired or were used before before and returns how many
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
 
 // StatusHistoryRepository defines status history data access methods
@@ -118,3 +214,80 @@ type StatusHistoryRepository interface {
 	ListByResource(ctx context.Context, resourceType domain.StatusResourceType, resourceID uuid.UUID, page, perPage int) ([]*domain.StatusHistory, int64, error)
 	ListByEntity(ctx context.Context, entityID uuid.UUID, resourceType *domain.StatusResourceType, page, perPage int) ([]*domain.StatusHistory, int64, error)
 }
+
+// AuditLogRepository defines audit log data access methods
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *domain.AuditLog) error
+	List(ctx context.Context, query *domain.AuditLogQuery) ([]*domain.AuditLog, int64, error)
+	RedactEventParticipants(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error)
+}
+
+// WebhookRepository defines outbound webhook subscription and delivery log data access methods
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error
+	ListSubscriptions(ctx context.Context, entityID uuid.UUID) ([]*domain.WebhookSubscription, error)
+	ListActiveSubscriptions(ctx context.Context, entityID uuid.UUID) ([]*domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id, entityID uuid.UUID) error
+	CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+}
+
+// APIKeyRepository defines entity API key data access methods
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	// GetByHash returns the non-revoked key with the given hash
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	ListByEntity(ctx context.Context, entityID uuid.UUID) ([]*domain.APIKey, error)
+	Revoke(ctx context.Context, id, entityID uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+// AttachmentRepository defines event attachment data access methods
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	GetByID(ctx context.Context, id, eventID, entityID uuid.UUID) (*domain.Attachment, error)
+	ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.Attachment, error)
+	Delete(ctx context.Context, id, entityID uuid.UUID) error
+}
+
+// ParticipantGroupRepository defines participant group and membership data access methods
+type ParticipantGroupRepository interface {
+	Create(ctx context.Context, group *domain.ParticipantGroup) error
+	GetByID(ctx context.Context, id, eventID, entityID uuid.UUID) (*domain.ParticipantGroup, error)
+	ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.ParticipantGroup, error)
+	Update(ctx context.Context, group *domain.ParticipantGroup) error
+	// Delete removes the group together with its memberships
+	Delete(ctx context.Context, id, entityID uuid.UUID) error
+	// AddMembers adds the participants to the group; participants already in it are ignored
+	AddMembers(ctx context.Context, groupID, entityID uuid.UUID, participantIDs []uuid.UUID) error
+	RemoveMembers(ctx context.Context, groupID, entityID uuid.UUID, participantIDs []uuid.UUID) error
+	ListMemberIDs(ctx context.Context, groupID, entityID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// EventOrganizerRepository defines event co-organizer data access methods
+type EventOrganizerRepository interface {
+	// Add saves a co-organizer; one that already exists is ignored
+	Add(ctx context.Context, organizer *domain.EventOrganizer) error
+	Remove(ctx context.Context, eventID, userID, entityID uuid.UUID) error
+	ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.EventOrganizer, error)
+	IsOrganizer(ctx context.Context, eventID, userID, entityID uuid.UUID) (bool, error)
+}
+
+// WhatsAppSenderRepository defines data access methods for entity-specific WhatsApp senders
+type WhatsAppSenderRepository interface {
+	// Upsert creates or replaces the entity's sender
+	Upsert(ctx context.Context, sender *domain.WhatsAppSender) error
+	GetByEntity(ctx context.Context, entityID uuid.UUID) (*domain.WhatsAppSender, error)
+	Delete(ctx context.Context, entityID uuid.UUID) error
+}
+
+// NotificationDeliveryRepository defines notification delivery log data access methods
+type NotificationDeliveryRepository interface {
+	Create(ctx context.Context, delivery *domain.NotificationDelivery) error
+	GetByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.NotificationDelivery, error)
+	UpdateStatus(ctx context.Context, delivery *domain.NotificationDelivery) error
+	// CountByEventStatus counts the event's notifications by their latest delivery status
+	CountByEventStatus(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (map[domain.DeliveryStatus]int64, error)
+	// RedactRecipients blanks the recipient phone of the event's notifications, keeping
+	// their statuses, and returns how many were changed
+	RedactRecipients(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error)
+}
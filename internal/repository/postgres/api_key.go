@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *gorm.DB) *apiKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create saves a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	return conn(ctx, r.db).Create(key).Error
+}
+
+// GetByHash returns the non-revoked key with the given hash
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+
+	result := conn(ctx, r.db).
+		Where("key_hash = ? AND revoked_at IS NULL", keyHash).
+		First(&key)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Error
+	}
+
+	return &key, nil
+}
+
+// ListByEntity returns the keys of an entity, revoked ones included
+func (r *apiKeyRepository) ListByEntity(ctx context.Context, entityID uuid.UUID) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := conn(ctx, r.db).
+		Where("entity_id = ?", entityID).
+		Order("created_at ASC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Revoke marks a key as revoked; it stops authenticating immediately
+func (r *apiKeyRepository) Revoke(ctx context.Context, id, entityID uuid.UUID) error {
+	result := conn(ctx, r.db).
+		Model(&domain.APIKey{}).
+		Where("id = ? AND entity_id = ? AND revoked_at IS NULL", id, entityID).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed records when the key was last used
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).
+		Model(&domain.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", time.Now()).Error
+}
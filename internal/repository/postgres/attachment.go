@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a new event attachment repository
+func NewAttachmentRepository(db *gorm.DB) *attachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// Create saves a new attachment
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	return conn(ctx, r.db).Create(attachment).Error
+}
+
+// GetByID returns an attachment of an event
+func (r *attachmentRepository) GetByID(ctx context.Context, id, eventID, entityID uuid.UUID) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+
+	err := conn(ctx, r.db).
+		Where("id = ? AND event_id = ? AND entity_id = ?", id, eventID, entityID).
+		First(&attachment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// ListByEvent returns the attachments of an event, oldest first
+func (r *attachmentRepository) ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.Attachment, error) {
+	var attachments []*domain.Attachment
+	err := conn(ctx, r.db).
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// Delete removes an attachment
+func (r *attachmentRepository) Delete(ctx context.Context, id, entityID uuid.UUID) error {
+	result := conn(ctx, r.db).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Delete(&domain.Attachment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *gorm.DB) *auditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Create saves a new audit log entry
+func (r *auditLogRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	return conn(ctx, r.db).Create(entry).Error
+}
+
+// List returns the audit log of an entity, newest first
+func (r *auditLogRepository) List(ctx context.Context, query *domain.AuditLogQuery) ([]*domain.AuditLog, int64, error) {
+	var entries []*domain.AuditLog
+	var total int64
+
+	db := conn(ctx, r.db).Model(&domain.AuditLog{}).
+		Where("entity_id = ?", query.EntityID)
+
+	if query.Action != nil {
+		db = db.Where("action = ?", *query.Action)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.PerPage
+	if err := db.Order("created_at DESC").Offset(offset).Limit(query.PerPage).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// RedactEventParticipants clears the diff of the audit entries about the event's
+// participants, soft-deleted ones included, since it holds their personal data
+func (r *auditLogRepository) RedactEventParticipants(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error) {
+	result := conn(ctx, r.db).
+		Model(&domain.AuditLog{}).
+		Where("entity_id = ? AND target_type = ? AND diff IS NOT NULL", entityID, domain.AuditTargetParticipant).
+		Where("target_id IN (?)", conn(ctx, r.db).Unscoped().Model(&domain.Participant{}).Select("id").Where("event_id = ? AND entity_id = ?", eventID, entityID)).
+		Update("diff", gorm.Expr("NULL"))
+	return result.RowsAffected, result.Error
+}
@@ -22,13 +22,13 @@ func NewEntityRepository(db *gorm.DB) repository.EntityRepository {
 
 // Create creates a new entity
 func (r *entityRepository) Create(ctx context.Context, entity *domain.Entity) error {
-	return r.db.WithContext(ctx).Create(entity).Error
+	return conn(ctx, r.db).Create(entity).Error
 }
 
 // GetByID retrieves an entity by ID
 func (r *entityRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Entity, error) {
 	var entity domain.Entity
-	err := r.db.WithContext(ctx).
+	err := conn(ctx, r.db).
 		Where("id = ?", id).
 		First(&entity).Error
 	if err != nil {
@@ -68,12 +68,43 @@ func (r *entityRepository) Update(ctx context.Context, id uuid.UUID, input *doma
 	if input.Metadata != nil {
 		updates["metadata"] = input.Metadata
 	}
+	if input.ConfirmationOffsetMinutes != nil {
+		updates["confirmation_offset_minutes"] = *input.ConfirmationOffsetMinutes
+	}
+	if input.ReminderOffsetMinutes != nil {
+		updates["reminder_offset_minutes"] = *input.ReminderOffsetMinutes
+	}
+	if input.LocationOffsetMinutes != nil {
+		updates["location_offset_minutes"] = *input.LocationOffsetMinutes
+	}
+	if input.QuietHoursStart != nil {
+		updates["quiet_hours_start"] = *input.QuietHoursStart
+	}
+	if input.QuietHoursEnd != nil {
+		updates["quiet_hours_end"] = *input.QuietHoursEnd
+	}
+	if input.EventTypes != nil {
+		updates["event_types"] = input.EventTypes
+	}
+	if input.ReminderDigest != nil {
+		updates["reminder_digest"] = *input.ReminderDigest
+	}
+	if input.AutoCompleteEvents != nil {
+		updates["auto_complete_events"] = *input.AutoCompleteEvents
+	}
+	if input.PhoneRegion != nil {
+		if *input.PhoneRegion == "" {
+			updates["phone_region"] = nil
+		} else {
+			updates["phone_region"] = *input.PhoneRegion
+		}
+	}
 
 	if len(updates) == 0 {
 		return nil
 	}
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.Entity{}).
 		Where("id = ?", id).
 		Updates(updates)
@@ -89,7 +120,7 @@ func (r *entityRepository) Update(ctx context.Context, id uuid.UUID, input *doma
 
 // Delete deletes an entity
 func (r *entityRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("id = ?", id).
 		Delete(&domain.Entity{})
 	if result.Error != nil {
@@ -108,13 +139,13 @@ func (r *entityRepository) List(ctx context.Context, page, perPage int) ([]*doma
 
 	offset := (page - 1) * perPage
 
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Model(&domain.Entity{}).
 		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(perPage).
@@ -132,14 +163,14 @@ func (r *entityRepository) ListByParent(ctx context.Context, parentID uuid.UUID,
 
 	offset := (page - 1) * perPage
 
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Model(&domain.Entity{}).
 		Where("parent_id = ?", parentID).
 		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Where("parent_id = ?", parentID).
 		Order("created_at DESC").
 		Offset(offset).
@@ -154,7 +185,7 @@ func (r *entityRepository) ListByParent(ctx context.Context, parentID uuid.UUID,
 // GetByDocument retrieves an entity by document
 func (r *entityRepository) GetByDocument(ctx context.Context, document string) (*domain.Entity, error) {
 	var entity domain.Entity
-	err := r.db.WithContext(ctx).
+	err := conn(ctx, r.db).
 		Where("document = ?", document).
 		First(&entity).Error
 	if err != nil {
@@ -165,3 +196,29 @@ func (r *entityRepository) GetByDocument(ctx context.Context, document string) (
 	}
 	return &entity, nil
 }
+
+// ListDescendants returns the whole subtree under rootID (up to maxDepth levels)
+// using a recursive CTE, ordered by depth
+func (r *entityRepository) ListDescendants(ctx context.Context, rootID uuid.UUID, maxDepth int) ([]*domain.EntityDescendant, error) {
+	var descendants []*domain.EntityDescendant
+
+	err := conn(ctx, r.db).Raw(`
+		WITH RECURSIVE descendants AS (
+			SELECT e.*, 1 AS depth
+			FROM entities e
+			WHERE e.parent_id = ?
+			UNION ALL
+			SELECT c.*, d.depth + 1
+			FROM entities c
+			JOIN descendants d ON c.parent_id = d.id
+			WHERE d.depth < ?
+		)
+		SELECT * FROM descendants
+		ORDER BY depth, name`, rootID, maxDepth).
+		Scan(&descendants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return descendants, nil
+}
@@ -3,12 +3,14 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"event-coming/internal/domain"
 	"event-coming/internal/repository"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type eventRepository struct {
@@ -27,14 +29,32 @@ func (r *eventRepository) Create(ctx context.Context, event *domain.Event) error
 		event.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(event)
+	result := conn(ctx, r.db).Create(event)
 	return result.Error
 }
 
 func (r *eventRepository) GetByID(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.Event, error) {
 	var event domain.Event
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		First(&event)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Error
+	}
+
+	return &event, nil
+}
+
+func (r *eventRepository) GetForUpdate(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.Event, error) {
+	var event domain.Event
+
+	result := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		First(&event)
 
@@ -75,32 +95,114 @@ func (r *eventRepository) Update(ctx context.Context, id uuid.UUID, entityID uui
 	if input.EndTime != nil {
 		updates["end_time"] = *input.EndTime
 	}
+	if input.Timezone != nil {
+		updates["timezone"] = *input.Timezone
+	}
+	if input.Locale != nil {
+		updates["locale"] = *input.Locale
+	}
 	if input.ConfirmationDeadline != nil {
 		updates["confirmation_deadline"] = *input.ConfirmationDeadline
 	}
+	if input.ResponseOptions != nil {
+		updates["response_options"] = input.ResponseOptions
+	}
+	if input.MarkNoShows != nil {
+		updates["mark_no_shows"] = *input.MarkNoShows
+	}
+	if input.ExDates != nil {
+		updates["ex_dates"] = input.ExDates
+	}
+	if input.Features != nil {
+		updates["features"] = *input.Features
+	}
+	if input.CheckInWindowBeforeMinutes != nil {
+		updates["check_in_window_before_minutes"] = *input.CheckInWindowBeforeMinutes
+	}
+	if input.CheckInWindowAfterMinutes != nil {
+		updates["check_in_window_after_minutes"] = *input.CheckInWindowAfterMinutes
+	}
+	if input.Locations != nil {
+		updates["locations"] = input.Locations
+	}
+	if input.TargetLocationID != nil {
+		updates["target_location_id"] = *input.TargetLocationID
+	}
+	if input.Capacity != nil {
+		updates["capacity"] = *input.Capacity
+	}
+	if input.ClearDescription {
+		updates["description"] = nil
+	}
+	if input.ClearLocationAddress {
+		updates["location_address"] = nil
+	}
+	if input.ClearEndTime {
+		updates["end_time"] = nil
+	}
+	if input.ClearConfirmationDeadline {
+		updates["confirmation_deadline"] = nil
+	}
+	if input.ClearCheckInWindowBefore {
+		updates["check_in_window_before_minutes"] = nil
+	}
+	if input.ClearCheckInWindowAfter {
+		updates["check_in_window_after_minutes"] = nil
+	}
+	if input.ClearTargetLocation {
+		updates["target_location_id"] = nil
+	}
+	if input.ClearCapacity {
+		updates["capacity"] = nil
+	}
 
 	if len(updates) == 0 {
 		return nil
 	}
 
-	result := r.db.WithContext(ctx).
+	updates["version"] = gorm.Expr("version + 1")
+
+	query := conn(ctx, r.db).
 		Model(&domain.Event{}).
-		Where("id = ? AND entity_id = ?", id, entityID).
-		Updates(updates)
+		Where("id = ? AND entity_id = ?", id, entityID)
+	if input.ExpectedVersion != nil {
+		query = query.Where("version = ?", *input.ExpectedVersion)
+	}
 
+	result := query.Updates(updates)
 	if result.Error != nil {
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return domain.ErrNotFound
+		return r.missingOrStale(ctx, id, entityID, input.ExpectedVersion)
 	}
 
 	return nil
 }
 
+// missingOrStale distinguishes a missing row from a version mismatch after an update touched nothing
+func (r *eventRepository) missingOrStale(ctx context.Context, id uuid.UUID, entityID uuid.UUID, expectedVersion *int) error {
+	if expectedVersion == nil {
+		return domain.ErrNotFound
+	}
+
+	var count int64
+	if err := conn(ctx, r.db).
+		Model(&domain.Event{}).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return domain.ErrNotFound
+	}
+	return domain.ErrVersionConflict
+}
+
 func (r *eventRepository) Delete(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		Delete(&domain.Event{})
 
@@ -122,7 +224,7 @@ func (r *eventRepository) List(ctx context.Context, entityID uuid.UUID, page, pe
 	offset := (page - 1) * perPage
 
 	// Count total
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Model(&domain.Event{}).
 		Where("entity_id = ?", entityID).
 		Count(&total).Error; err != nil {
@@ -130,7 +232,7 @@ func (r *eventRepository) List(ctx context.Context, entityID uuid.UUID, page, pe
 	}
 
 	// Get paginated results
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Where("entity_id = ?", entityID).
 		Order("created_at DESC").
 		Offset(offset).
@@ -142,6 +244,165 @@ func (r *eventRepository) List(ctx context.Context, entityID uuid.UUID, page, pe
 	return events, total, nil
 }
 
+func (r *eventRepository) ListReminderDigest(ctx context.Context, entityID uuid.UUID, phoneNumber string, from, to time.Time) ([]*domain.ParticipantEvent, error) {
+	var events []*domain.ParticipantEvent
+
+	err := conn(ctx, r.db).
+		Model(&domain.Event{}).
+		Select("events.*, participants.id AS participant_id, participants.status AS participant_status").
+		Joins("JOIN participants ON participants.event_id = events.id AND participants.deleted_at IS NULL").
+		Where("events.entity_id = ? AND events.status <> ?", entityID, domain.EventStatusCancelled).
+		Where("events.start_time >= ? AND events.start_time < ?", from, to).
+		Where("participants.phone_number = ? AND participants.status = ? AND participants.reminder_digest_at IS NULL",
+			phoneNumber, domain.ParticipantStatusConfirmed).
+		Order("events.start_time ASC").
+		Scan(&events).Error
+
+	return events, err
+}
+
+func (r *eventRepository) ListEndedActive(ctx context.Context, endedBefore time.Time, limit int) ([]*domain.Event, error) {
+	var events []*domain.Event
+
+	err := conn(ctx, r.db).
+		Select("events.*").
+		Joins("JOIN entities ON entities.id = events.entity_id AND entities.auto_complete_events").
+		Where("events.status = ? AND COALESCE(events.end_time, events.start_time) < ?", domain.EventStatusActive, endedBefore).
+		Order("COALESCE(events.end_time, events.start_time) ASC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+func (r *eventRepository) ListPendingRedaction(ctx context.Context, endedBefore time.Time, limit int) ([]*domain.Event, error) {
+	var events []*domain.Event
+
+	err := conn(ctx, r.db).
+		Where("status IN ? AND participants_redacted_at IS NULL AND COALESCE(end_time, start_time) < ?",
+			[]domain.EventStatus{domain.EventStatusCompleted, domain.EventStatusCancelled}, endedBefore).
+		Order("COALESCE(end_time, start_time) ASC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+func (r *eventRepository) MarkParticipantsRedacted(ctx context.Context, id uuid.UUID, entityID uuid.UUID, at time.Time) error {
+	result := conn(ctx, r.db).
+		Model(&domain.Event{}).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Update("participants_redacted_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *eventRepository) ListByParticipantPhone(ctx context.Context, phoneNumber string, page, perPage int) ([]*domain.ParticipantEvent, int64, error) {
+	var events []*domain.ParticipantEvent
+	var total int64
+
+	offset := (page - 1) * perPage
+
+	query := func() *gorm.DB {
+		return conn(ctx, r.db).
+			Model(&domain.Event{}).
+			Joins("JOIN participants ON participants.event_id = events.id AND participants.deleted_at IS NULL").
+			Where("participants.phone_number = ?", phoneNumber)
+	}
+
+	// Count total
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	if err := query().
+		Select("events.*, participants.id AS participant_id, participants.status AS participant_status").
+		Order("events.start_time DESC").
+		Offset(offset).
+		Limit(perPage).
+		Scan(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (r *eventRepository) ListActiveByParticipantPhone(ctx context.Context, phoneNumber string) ([]*domain.ParticipantEvent, error) {
+	var events []*domain.ParticipantEvent
+	now := time.Now()
+
+	err := conn(ctx, r.db).
+		Model(&domain.Event{}).
+		Select("events.*, participants.id AS participant_id, participants.status AS participant_status").
+		Joins("JOIN participants ON participants.event_id = events.id AND participants.deleted_at IS NULL").
+		Where("participants.phone_number = ?", phoneNumber).
+		Where("events.status = ?", domain.EventStatusActive).
+		Where("events.start_time <= ? AND events.end_time >= ?", now.Add(24*time.Hour), now).
+		Order("events.start_time ASC").
+		Scan(&events).Error
+
+	return events, err
+}
+
+func (r *eventRepository) FindDuplicate(ctx context.Context, entityID uuid.UUID, name string, from, to time.Time) (*domain.Event, error) {
+	var event domain.Event
+
+	result := conn(ctx, r.db).
+		Where("entity_id = ? AND status <> ?", entityID, domain.EventStatusCancelled).
+		Where("LOWER(REGEXP_REPLACE(TRIM(name), '\\s+', ' ', 'g')) = ?", domain.NormalizeEventName(name)).
+		Where("start_time BETWEEN ? AND ?", from, to).
+		Order("created_at DESC").
+		First(&event)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Error
+	}
+
+	return &event, nil
+}
+
+func (r *eventRepository) GetByImportUID(ctx context.Context, entityID uuid.UUID, uid string) (*domain.Event, error) {
+	var event domain.Event
+
+	result := conn(ctx, r.db).
+		Where("entity_id = ? AND import_uid = ?", entityID, uid).
+		First(&event)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Error
+	}
+
+	return &event, nil
+}
+
+func (r *eventRepository) Search(ctx context.Context, entityID uuid.UUID, query string, limit int) ([]*domain.Event, error) {
+	var events []*domain.Event
+
+	err := conn(ctx, r.db).
+		Where("entity_id = ? AND LOWER(name) LIKE ?", entityID, containsPattern(query)).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "LOWER(name) LIKE ? DESC, start_time DESC",
+			Vars:               []interface{}{prefixPattern(query)},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
 func (r *eventRepository) ListByStatus(ctx context.Context, entityID uuid.UUID, status domain.EventStatus, page, perPage int) ([]*domain.Event, int64, error) {
 	var events []*domain.Event
 	var total int64
@@ -149,7 +410,7 @@ func (r *eventRepository) ListByStatus(ctx context.Context, entityID uuid.UUID,
 	offset := (page - 1) * perPage
 
 	// Count total
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Model(&domain.Event{}).
 		Where("entity_id = ? AND status = ?", entityID, status).
 		Count(&total).Error; err != nil {
@@ -157,7 +418,7 @@ func (r *eventRepository) ListByStatus(ctx context.Context, entityID uuid.UUID,
 	}
 
 	// Get paginated results
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Where("entity_id = ? AND status = ?", entityID, status).
 		Order("start_time ASC").
 		Offset(offset).
@@ -176,14 +437,39 @@ func (r *eventRepository) CreateInstance(ctx context.Context, instance *domain.E
 		instance.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(instance)
+	result := conn(ctx, r.db).Create(instance)
 	return result.Error
 }
 
+func (r *eventRepository) UpdateInstance(ctx context.Context, instance *domain.EventInstance) error {
+	result := conn(ctx, r.db).
+		Model(&domain.EventInstance{}).
+		Where("id = ? AND entity_id = ?", instance.ID, instance.EntityID).
+		Updates(map[string]interface{}{
+			"status":           instance.Status,
+			"start_time":       instance.StartTime,
+			"end_time":         instance.EndTime,
+			"location_lat":     instance.LocationLat,
+			"location_lng":     instance.LocationLng,
+			"location_address": instance.LocationAddress,
+			"overridden":       instance.Overridden,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *eventRepository) GetInstanceByID(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.EventInstance, error) {
 	var instance domain.EventInstance
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		First(&instance)
 
@@ -200,7 +486,7 @@ func (r *eventRepository) GetInstanceByID(ctx context.Context, id uuid.UUID, ent
 func (r *eventRepository) ListInstances(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.EventInstance, error) {
 	var instances []*domain.EventInstance
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("event_id = ? AND entity_id = ?", eventID, entityID).
 		Order("instance_date ASC").
 		Find(&instances)
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type eventOrganizerRepository struct {
+	db *gorm.DB
+}
+
+// NewEventOrganizerRepository creates a new event co-organizer repository
+func NewEventOrganizerRepository(db *gorm.DB) *eventOrganizerRepository {
+	return &eventOrganizerRepository{db: db}
+}
+
+// Add saves a co-organizer; adding one that already exists does nothing
+func (r *eventOrganizerRepository) Add(ctx context.Context, organizer *domain.EventOrganizer) error {
+	return conn(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(organizer).Error
+}
+
+// Remove deletes a co-organizer of the event
+func (r *eventOrganizerRepository) Remove(ctx context.Context, eventID, userID, entityID uuid.UUID) error {
+	result := conn(ctx, r.db).
+		Where("event_id = ? AND user_id = ? AND entity_id = ?", eventID, userID, entityID).
+		Delete(&domain.EventOrganizer{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// ListByEvent returns the event's co-organizers with their users, oldest first
+func (r *eventOrganizerRepository) ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.EventOrganizer, error) {
+	var organizers []*domain.EventOrganizer
+	err := conn(ctx, r.db).
+		Preload("User").
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Order("created_at ASC").
+		Find(&organizers).Error
+	return organizers, err
+}
+
+// IsOrganizer reports whether the user is a co-organizer of the event
+func (r *eventOrganizerRepository) IsOrganizer(ctx context.Context, eventID, userID, entityID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).
+		Model(&domain.EventOrganizer{}).
+		Where("event_id = ? AND user_id = ? AND entity_id = ?", eventID, userID, entityID).
+		Count(&count).Error
+	return count > 0, err
+}
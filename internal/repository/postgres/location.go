@@ -26,7 +26,7 @@ func (r *locationRepository) Create(ctx context.Context, location *domain.Locati
 		location.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(location)
+	result := conn(ctx, r.db).Create(location)
 	return result.Error
 }
 
@@ -42,14 +42,14 @@ func (r *locationRepository) BatchCreate(ctx context.Context, locations []*domai
 		}
 	}
 
-	result := r.db.WithContext(ctx).CreateInBatches(locations, 100)
+	result := conn(ctx, r.db).CreateInBatches(locations, 100)
 	return result.Error
 }
 
 func (r *locationRepository) GetLatestByParticipant(ctx context.Context, participantID uuid.UUID, entityID uuid.UUID) (*domain.Location, error) {
 	var location domain.Location
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("participant_id = ? AND entity_id = ?", participantID, entityID).
 		Order("timestamp DESC").
 		First(&location)
@@ -68,13 +68,42 @@ func (r *locationRepository) GetLatestByEvent(ctx context.Context, eventID uuid.
 	var locations []*domain.Location
 
 	// Subquery to get latest location per participant
-	subQuery := r.db.WithContext(ctx).
+	subQuery := conn(ctx, r.db).
 		Model(&domain.Location{}).
 		Select("participant_id, MAX(timestamp) as max_timestamp").
 		Where("event_id = ? AND entity_id = ?", eventID, entityID).
 		Group("participant_id")
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Where("(participant_id, timestamp) IN (?)", subQuery).
+		Find(&locations)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return locations, nil
+}
+
+func (r *locationRepository) DeleteByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Delete(&domain.Location{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *locationRepository) GetFirstByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.Location, error) {
+	var locations []*domain.Location
+
+	// Subquery to get first location per participant
+	subQuery := conn(ctx, r.db).
+		Model(&domain.Location{}).
+		Select("participant_id, MIN(timestamp) as min_timestamp").
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Group("participant_id")
+
+	result := conn(ctx, r.db).
 		Where("event_id = ? AND entity_id = ?", eventID, entityID).
 		Where("(participant_id, timestamp) IN (?)", subQuery).
 		Find(&locations)
@@ -89,7 +118,7 @@ func (r *locationRepository) GetLatestByEvent(ctx context.Context, eventID uuid.
 func (r *locationRepository) GetHistory(ctx context.Context, participantID uuid.UUID, entityID uuid.UUID, from, to time.Time) ([]*domain.Location, error) {
 	var locations []*domain.Location
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("participant_id = ? AND entity_id = ?", participantID, entityID).
 		Where("timestamp >= ? AND timestamp <= ?", from, to).
 		Order("timestamp ASC").
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type notificationDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationDeliveryRepository creates a new notification delivery repository
+func NewNotificationDeliveryRepository(db *gorm.DB) *notificationDeliveryRepository {
+	return &notificationDeliveryRepository{db: db}
+}
+
+// Create saves a new delivery log entry
+func (r *notificationDeliveryRepository) Create(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	return conn(ctx, r.db).Create(delivery).Error
+}
+
+// GetByProviderMessageID finds a delivery by the provider message id
+func (r *notificationDeliveryRepository) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.NotificationDelivery, error) {
+	var delivery domain.NotificationDelivery
+	err := conn(ctx, r.db).
+		Where("provider_message_id = ?", providerMessageID).
+		First(&delivery).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// UpdateStatus updates the delivery status and error details
+func (r *notificationDeliveryRepository) UpdateStatus(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	result := conn(ctx, r.db).
+		Model(&domain.NotificationDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":        delivery.Status,
+			"error_code":    delivery.ErrorCode,
+			"error_message": delivery.ErrorMessage,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// RedactRecipients blanks the recipient phone of the event's notifications
+func (r *notificationDeliveryRepository) RedactRecipients(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error) {
+	result := conn(ctx, r.db).
+		Model(&domain.NotificationDelivery{}).
+		Where("event_id = ? AND entity_id = ? AND recipient <> ''", eventID, entityID).
+		Update("recipient", "")
+	return result.RowsAffected, result.Error
+}
+
+// CountByEventStatus counts the event's notifications by their latest delivery status
+func (r *notificationDeliveryRepository) CountByEventStatus(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (map[domain.DeliveryStatus]int64, error) {
+	var rows []struct {
+		Status domain.DeliveryStatus
+		Count  int64
+	}
+
+	err := conn(ctx, r.db).
+		Model(&domain.NotificationDelivery{}).
+		Select("status, COUNT(*) AS count").
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[domain.DeliveryStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
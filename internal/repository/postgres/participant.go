@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type participantRepository struct {
@@ -26,14 +27,14 @@ func (r *participantRepository) Create(ctx context.Context, participant *domain.
 		participant.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(participant)
+	result := conn(ctx, r.db).Create(participant)
 	return result.Error
 }
 
 func (r *participantRepository) GetByID(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.Participant, error) {
 	var participant domain.Participant
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		First(&participant)
 
@@ -65,29 +66,60 @@ func (r *participantRepository) Update(ctx context.Context, id uuid.UUID, entity
 	if input.Metadata != nil {
 		updates["metadata"] = input.Metadata
 	}
+	if input.Notes != nil {
+		updates["notes"] = *input.Notes
+	}
+	if input.ClearNotes {
+		updates["notes"] = nil
+	}
 
 	if len(updates) == 0 {
 		return nil
 	}
 
-	result := r.db.WithContext(ctx).
+	updates["version"] = gorm.Expr("version + 1")
+
+	query := conn(ctx, r.db).
 		Model(&domain.Participant{}).
-		Where("id = ? AND entity_id = ?", id, entityID).
-		Updates(updates)
+		Where("id = ? AND entity_id = ?", id, entityID)
+	if input.ExpectedVersion != nil {
+		query = query.Where("version = ?", *input.ExpectedVersion)
+	}
 
+	result := query.Updates(updates)
 	if result.Error != nil {
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return domain.ErrNotFound
+		return r.missingOrStale(ctx, id, entityID, input.ExpectedVersion)
 	}
 
 	return nil
 }
 
+// missingOrStale distinguishes a missing row from a version mismatch after an update touched nothing
+func (r *participantRepository) missingOrStale(ctx context.Context, id uuid.UUID, entityID uuid.UUID, expectedVersion *int) error {
+	if expectedVersion == nil {
+		return domain.ErrNotFound
+	}
+
+	var count int64
+	if err := conn(ctx, r.db).
+		Model(&domain.Participant{}).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return domain.ErrNotFound
+	}
+	return domain.ErrVersionConflict
+}
+
 func (r *participantRepository) Delete(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		Delete(&domain.Participant{})
 
@@ -109,7 +141,7 @@ func (r *participantRepository) ListByEvent(ctx context.Context, eventID uuid.UU
 	offset := (page - 1) * perPage
 
 	// Count total
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Model(&domain.Participant{}).
 		Where("event_id = ? AND entity_id = ?", eventID, entityID).
 		Count(&total).Error; err != nil {
@@ -117,7 +149,7 @@ func (r *participantRepository) ListByEvent(ctx context.Context, eventID uuid.UU
 	}
 
 	// Get paginated results
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Where("event_id = ? AND entity_id = ?", eventID, entityID).
 		Order("name ASC").
 		Offset(offset).
@@ -129,6 +161,73 @@ func (r *participantRepository) ListByEvent(ctx context.Context, eventID uuid.UU
 	return participants, total, nil
 }
 
+// ListByGroup lists the participants of the event that are members of the group
+func (r *participantRepository) ListByGroup(ctx context.Context, eventID, groupID, entityID uuid.UUID, page, perPage int) ([]*domain.Participant, int64, error) {
+	var participants []*domain.Participant
+	var total int64
+
+	offset := (page - 1) * perPage
+
+	members := func() *gorm.DB {
+		return conn(ctx, r.db).
+			Model(&domain.Participant{}).
+			Joins("JOIN participant_group_members m ON m.participant_id = participants.id").
+			Where("m.group_id = ? AND participants.event_id = ? AND participants.entity_id = ?", groupID, eventID, entityID)
+	}
+
+	if err := members().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := members().
+		Select("participants.*").
+		Order("participants.created_at ASC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&participants).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return participants, total, nil
+}
+
+func (r *participantRepository) ListAllByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.Participant, error) {
+	var participants []*domain.Participant
+
+	if err := conn(ctx, r.db).
+		Preload("RefEntity").
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Order("created_at ASC").
+		Find(&participants).Error; err != nil {
+		return nil, err
+	}
+
+	return participants, nil
+}
+
+func (r *participantRepository) CountByEventStatus(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (map[domain.ParticipantStatus]int, error) {
+	var rows []struct {
+		Status domain.ParticipantStatus
+		Count  int
+	}
+
+	err := conn(ctx, r.db).
+		Model(&domain.Participant{}).
+		Select("status, COUNT(*) AS count").
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[domain.ParticipantStatus]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
 func (r *participantRepository) ListByEventInstance(ctx context.Context, instanceID uuid.UUID, entityID uuid.UUID, page, perPage int) ([]*domain.Participant, int64, error) {
 	var participants []*domain.Participant
 	var total int64
@@ -136,7 +235,7 @@ func (r *participantRepository) ListByEventInstance(ctx context.Context, instanc
 	offset := (page - 1) * perPage
 
 	// Count total
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Model(&domain.Participant{}).
 		Where("instance_id = ? AND entity_id = ?", instanceID, entityID).
 		Count(&total).Error; err != nil {
@@ -144,9 +243,9 @@ func (r *participantRepository) ListByEventInstance(ctx context.Context, instanc
 	}
 
 	// Get paginated results
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Where("instance_id = ? AND entity_id = ?", instanceID, entityID).
-		Order("name ASC").
+		Order("created_at ASC").
 		Offset(offset).
 		Limit(perPage).
 		Find(&participants).Error; err != nil {
@@ -158,7 +257,8 @@ func (r *participantRepository) ListByEventInstance(ctx context.Context, instanc
 
 func (r *participantRepository) UpdateStatus(ctx context.Context, id uuid.UUID, entityID uuid.UUID, status domain.ParticipantStatus) error {
 	updates := map[string]interface{}{
-		"status": status,
+		"status":  status,
+		"version": gorm.Expr("version + 1"),
 	}
 
 	// Set confirmed_at or checked_in_at based on status
@@ -170,7 +270,7 @@ func (r *participantRepository) UpdateStatus(ctx context.Context, id uuid.UUID,
 		updates["checked_in_at"] = now
 	}
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.Participant{}).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		Updates(updates)
@@ -186,10 +286,122 @@ func (r *participantRepository) UpdateStatus(ctx context.Context, id uuid.UUID,
 	return nil
 }
 
+func (r *participantRepository) ListMissingPhoneNumber(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Participant, error) {
+	var participants []*domain.Participant
+
+	err := conn(ctx, r.db).
+		Joins("JOIN entities ON entities.id = participants.ref_entity_id").
+		Preload("RefEntity").
+		Where("participants.id > ?", afterID).
+		Where("COALESCE(participants.phone_number, '') = ''").
+		Where("COALESCE(entities.phone_number, '') <> ''").
+		Order("participants.id ASC").
+		Limit(limit).
+		Find(&participants).Error
+
+	return participants, err
+}
+
+func (r *participantRepository) SetPhoneNumber(ctx context.Context, id uuid.UUID, entityID uuid.UUID, phoneNumber string) error {
+	return conn(ctx, r.db).
+		Model(&domain.Participant{}).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Update("phone_number", phoneNumber).Error
+}
+
+func (r *participantRepository) SetLocationConsent(ctx context.Context, id uuid.UUID, entityID uuid.UUID, consent bool) error {
+	updates := map[string]interface{}{
+		"location_consent": consent,
+		"version":          gorm.Expr("version + 1"),
+	}
+	if consent {
+		updates["location_consent_at"] = time.Now()
+	} else {
+		updates["location_consent_at"] = nil
+	}
+
+	result := conn(ctx, r.db).
+		Model(&domain.Participant{}).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Updates(updates)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *participantRepository) SetDeclineReason(ctx context.Context, id uuid.UUID, entityID uuid.UUID, reason *string) error {
+	result := conn(ctx, r.db).
+		Model(&domain.Participant{}).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Updates(map[string]interface{}{
+			"decline_reason": reason,
+			"version":        gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *participantRepository) MarkInviteViewed(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
+	// UpdateColumn: abrir o convite não é uma edição do participante (não mexe em updated_at/version)
+	return conn(ctx, r.db).
+		Model(&domain.Participant{}).
+		Where("id = ? AND entity_id = ? AND invite_viewed_at IS NULL", id, entityID).
+		UpdateColumn("invite_viewed_at", time.Now()).Error
+}
+
+func (r *participantRepository) MarkReminderDigest(ctx context.Context, entityID uuid.UUID, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	// UpdateColumn: controle de envio, não uma edição do participante
+	return conn(ctx, r.db).
+		Model(&domain.Participant{}).
+		Where("id IN ? AND entity_id = ?", ids, entityID).
+		UpdateColumn("reminder_digest_at", time.Now()).Error
+}
+
+func (r *participantRepository) MarkNoShows(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]uuid.UUID, error) {
+	var marked []*domain.Participant
+
+	err := conn(ctx, r.db).
+		Model(&marked).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("event_id = ? AND entity_id = ? AND status = ? AND checked_in_at IS NULL",
+			eventID, entityID, domain.ParticipantStatusConfirmed).
+		Updates(map[string]interface{}{
+			"status":  domain.ParticipantStatusNoShow,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(marked))
+	for i, p := range marked {
+		ids[i] = p.ID
+	}
+	return ids, nil
+}
+
 func (r *participantRepository) GetByPhoneNumber(ctx context.Context, phoneNumber string, eventID uuid.UUID, entityID uuid.UUID) (*domain.Participant, error) {
 	var participant domain.Participant
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("phone_number = ? AND event_id = ? AND entity_id = ?", phoneNumber, eventID, entityID).
 		First(&participant)
 
@@ -203,13 +415,28 @@ func (r *participantRepository) GetByPhoneNumber(ctx context.Context, phoneNumbe
 	return &participant, nil
 }
 
+func (r *participantRepository) ExistingPhoneNumbers(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID, phoneNumbers []string) ([]string, error) {
+	var existing []string
+	if len(phoneNumbers) == 0 {
+		return existing, nil
+	}
+
+	err := conn(ctx, r.db).
+		Model(&domain.Participant{}).
+		Where("event_id = ? AND entity_id = ? AND phone_number IN ?", eventID, entityID, phoneNumbers).
+		Distinct().
+		Pluck("phone_number", &existing).Error
+
+	return existing, err
+}
+
 // GetActiveByPhoneNumber finds a participant by phone number in active events
 // Returns the most recent participant with an active event
 func (r *participantRepository) GetActiveByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.Participant, error) {
 	var participant domain.Participant
 
 	// Join with events to find participants in active events
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Joins("JOIN events ON events.id = participants.event_id").
 		Where("participants.phone_number = ?", phoneNumber).
 		Where("events.status = ?", domain.EventStatusActive).
@@ -226,3 +453,51 @@ func (r *participantRepository) GetActiveByPhoneNumber(ctx context.Context, phon
 
 	return &participant, nil
 }
+
+func (r *participantRepository) Search(ctx context.Context, entityID uuid.UUID, query string, limit int) ([]*domain.Participant, error) {
+	var participants []*domain.Participant
+
+	// Participants without a referenced entity are only found by their phone number
+	match := r.db.Where("LOWER(entities.name) LIKE ?", containsPattern(query))
+	if digits := domain.NormalizePhoneNumber(query); digits != "" {
+		match = match.Or("participants.phone_number LIKE ?", "%"+digits+"%")
+	}
+
+	err := conn(ctx, r.db).
+		Preload("RefEntity").
+		Joins("LEFT JOIN entities ON entities.id = participants.ref_entity_id").
+		Where("participants.entity_id = ?", entityID).
+		Where(match).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "COALESCE(LOWER(entities.name) LIKE ?, false) DESC, participants.created_at DESC",
+			Vars:               []interface{}{prefixPattern(query)},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&participants).Error
+
+	return participants, err
+}
+
+func (r *participantRepository) RedactByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID, at time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Unscoped().
+		Model(&domain.Participant{}).
+		Where("event_id = ? AND entity_id = ? AND redacted_at IS NULL", eventID, entityID).
+		Updates(redactedParticipantColumns(at))
+	return result.RowsAffected, result.Error
+}
+
+// redactedParticipantColumns are the columns RedactByEvent writes: the personal data is
+// cleared and everything else (status, confirmation and check-in times) is left alone
+func redactedParticipantColumns(at time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"ref_entity_id":  nil,
+		"phone_number":   "",
+		"metadata":       nil,
+		"notes":          nil,
+		"decline_reason": nil,
+		"redacted_at":    at,
+		"version":        gorm.Expr("version + 1"),
+	}
+}
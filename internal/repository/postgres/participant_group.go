@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type participantGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewParticipantGroupRepository creates a new participant group repository
+func NewParticipantGroupRepository(db *gorm.DB) *participantGroupRepository {
+	return &participantGroupRepository{db: db}
+}
+
+// Create saves a new group
+func (r *participantGroupRepository) Create(ctx context.Context, group *domain.ParticipantGroup) error {
+	return conn(ctx, r.db).Create(group).Error
+}
+
+// GetByID returns a group of an event
+func (r *participantGroupRepository) GetByID(ctx context.Context, id, eventID, entityID uuid.UUID) (*domain.ParticipantGroup, error) {
+	var group domain.ParticipantGroup
+
+	err := conn(ctx, r.db).
+		Where("id = ? AND event_id = ? AND entity_id = ?", id, eventID, entityID).
+		First(&group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// ListByEvent returns the groups of an event ordered by name
+func (r *participantGroupRepository) ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.ParticipantGroup, error) {
+	var groups []*domain.ParticipantGroup
+	err := conn(ctx, r.db).
+		Where("event_id = ? AND entity_id = ?", eventID, entityID).
+		Order("name ASC").
+		Find(&groups).Error
+	return groups, err
+}
+
+// Update saves the name and description of a group
+func (r *participantGroupRepository) Update(ctx context.Context, group *domain.ParticipantGroup) error {
+	result := conn(ctx, r.db).
+		Model(&domain.ParticipantGroup{}).
+		Where("id = ? AND entity_id = ?", group.ID, group.EntityID).
+		Updates(map[string]interface{}{
+			"name":        group.Name,
+			"description": group.Description,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the group together with its memberships
+func (r *participantGroupRepository) Delete(ctx context.Context, id, entityID uuid.UUID) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Where("id = ? AND entity_id = ?", id, entityID).
+			Delete(&domain.ParticipantGroup{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+
+		return tx.
+			Where("group_id = ? AND entity_id = ?", id, entityID).
+			Delete(&domain.ParticipantGroupMember{}).Error
+	})
+}
+
+// AddMembers adds the participants to the group; participants already in it are ignored
+func (r *participantGroupRepository) AddMembers(ctx context.Context, groupID, entityID uuid.UUID, participantIDs []uuid.UUID) error {
+	if len(participantIDs) == 0 {
+		return nil
+	}
+
+	members := make([]*domain.ParticipantGroupMember, len(participantIDs))
+	for i, participantID := range participantIDs {
+		members[i] = &domain.ParticipantGroupMember{
+			GroupID:       groupID,
+			ParticipantID: participantID,
+			EntityID:      entityID,
+		}
+	}
+
+	return conn(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&members).Error
+}
+
+// RemoveMembers removes the participants from the group
+func (r *participantGroupRepository) RemoveMembers(ctx context.Context, groupID, entityID uuid.UUID, participantIDs []uuid.UUID) error {
+	if len(participantIDs) == 0 {
+		return nil
+	}
+
+	return conn(ctx, r.db).
+		Where("group_id = ? AND entity_id = ? AND participant_id IN ?", groupID, entityID, participantIDs).
+		Delete(&domain.ParticipantGroupMember{}).Error
+}
+
+// ListMemberIDs returns the ids of the participants in the group
+func (r *participantGroupRepository) ListMemberIDs(ctx context.Context, groupID, entityID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := conn(ctx, r.db).
+		Model(&domain.ParticipantGroupMember{}).
+		Where("group_id = ? AND entity_id = ?", groupID, entityID).
+		Pluck("participant_id", &ids).Error
+	return ids, err
+}
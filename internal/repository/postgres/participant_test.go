@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactedParticipantColumns_ClearsPersonalDataOnly(t *testing.T) {
+	at := time.Now()
+	columns := redactedParticipantColumns(at)
+
+	assert.Equal(t, "", columns["phone_number"])
+	for _, column := range []string{"ref_entity_id", "metadata", "notes", "decline_reason"} {
+		value, ok := columns[column]
+		assert.True(t, ok, column)
+		assert.Nil(t, value, column)
+	}
+	assert.Equal(t, at, columns["redacted_at"])
+
+	// Status e horários ficam para os relatórios
+	for _, column := range []string{"status", "confirmed_at", "checked_in_at", "invite_viewed_at", "created_at"} {
+		assert.NotContains(t, columns, column)
+	}
+}
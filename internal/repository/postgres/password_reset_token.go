@@ -26,14 +26,14 @@ func (r *passwordResetTokenRepository) Create(ctx context.Context, token *domain
 		token.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(token)
+	result := conn(ctx, r.db).Create(token)
 	return result.Error
 }
 
 func (r *passwordResetTokenRepository) GetByToken(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
 	var token domain.PasswordResetToken
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("token = ? AND expires_at > ? AND used_at IS NULL", tokenHash, time.Now()).
 		First(&token)
 
@@ -49,7 +49,7 @@ func (r *passwordResetTokenRepository) GetByToken(ctx context.Context, tokenHash
 
 func (r *passwordResetTokenRepository) MarkAsUsed(ctx context.Context, id uuid.UUID) error {
 	now := time.Now()
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.PasswordResetToken{}).
 		Where("id = ?", id).
 		Update("used_at", now)
@@ -66,17 +66,18 @@ func (r *passwordResetTokenRepository) MarkAsUsed(ctx context.Context, id uuid.U
 }
 
 func (r *passwordResetTokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("user_id = ?", userID).
 		Delete(&domain.PasswordResetToken{})
 
 	return result.Error
 }
 
-func (r *passwordResetTokenRepository) DeleteExpired(ctx context.Context) error {
-	result := r.db.WithContext(ctx).
-		Where("expires_at < ? OR used_at IS NOT NULL", time.Now()).
+// DeleteExpired apaga os tokens que expiraram ou foram usados antes de before
+func (r *passwordResetTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("expires_at < ? OR used_at < ?", before, before).
 		Delete(&domain.PasswordResetToken{})
 
-	return result.Error
+	return result.RowsAffected, result.Error
 }
@@ -26,7 +26,7 @@ func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.Refre
 		token.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(token)
+	result := conn(ctx, r.db).Create(token)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -37,7 +37,7 @@ func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.Refre
 func (r *refreshTokenRepository) GetByToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
 	var refreshToken domain.RefreshToken
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("token = ? AND revoked_at IS NULL AND expires_at > ?", token, time.Now()).
 		First(&refreshToken)
 
@@ -54,7 +54,7 @@ func (r *refreshTokenRepository) GetByToken(ctx context.Context, token string) (
 func (r *refreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
 	now := time.Now()
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.RefreshToken{}).
 		Where("id = ? AND revoked_at IS NULL", id).
 		Update("revoked_at", now)
@@ -73,7 +73,7 @@ func (r *refreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error
 func (r *refreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
 	now := time.Now()
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.RefreshToken{}).
 		Where("user_id = ? AND revoked_at IS NULL", userID).
 		Update("revoked_at", now)
@@ -89,7 +89,7 @@ func (r *refreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID u
 func (r *refreshTokenRepository) RevokeByToken(ctx context.Context, tokenHash string) error {
 	now := time.Now()
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.RefreshToken{}).
 		Where("token = ? AND revoked_at IS NULL", tokenHash).
 		Update("revoked_at", now)
@@ -101,14 +101,15 @@ func (r *refreshTokenRepository) RevokeByToken(ctx context.Context, tokenHash st
 	return nil
 }
 
-func (r *refreshTokenRepository) DeleteExpired(ctx context.Context) error {
-	result := r.db.WithContext(ctx).
-		Where("expires_at < ? OR revoked_at IS NOT NULL", time.Now()).
+// DeleteExpired apaga os tokens que expiraram ou foram revogados antes de before
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
 		Delete(&domain.RefreshToken{})
 
 	if result.Error != nil {
-		return result.Error
+		return 0, result.Error
 	}
 
-	return nil
+	return result.RowsAffected, nil
 }
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type schedulerRepository struct {
@@ -26,14 +28,14 @@ func (r *schedulerRepository) Create(ctx context.Context, scheduler *domain.Sche
 		scheduler.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(scheduler)
+	result := conn(ctx, r.db).Create(scheduler)
 	return result.Error
 }
 
 func (r *schedulerRepository) GetByID(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.Scheduler, error) {
 	var scheduler domain.Scheduler
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		First(&scheduler)
 
@@ -48,7 +50,7 @@ func (r *schedulerRepository) GetByID(ctx context.Context, id uuid.UUID, entityI
 }
 
 func (r *schedulerRepository) Update(ctx context.Context, scheduler *domain.Scheduler) error {
-	result := r.db.WithContext(ctx).Save(scheduler)
+	result := conn(ctx, r.db).Save(scheduler)
 
 	if result.Error != nil {
 		return result.Error
@@ -62,7 +64,7 @@ func (r *schedulerRepository) Update(ctx context.Context, scheduler *domain.Sche
 }
 
 func (r *schedulerRepository) Delete(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		Delete(&domain.Scheduler{})
 
@@ -80,7 +82,7 @@ func (r *schedulerRepository) Delete(ctx context.Context, id uuid.UUID, entityID
 func (r *schedulerRepository) ListPending(ctx context.Context, before time.Time, limit int) ([]*domain.Scheduler, error) {
 	var schedulers []*domain.Scheduler
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("status = ? AND scheduled_at <= ? AND retries < max_retries", domain.SchedulerStatusPending, before).
 		Order("scheduled_at ASC").
 		Limit(limit).
@@ -93,10 +95,132 @@ func (r *schedulerRepository) ListPending(ctx context.Context, before time.Time,
 	return schedulers, nil
 }
 
+func (r *schedulerRepository) ListDueBetween(ctx context.Context, from, to time.Time, limit int) ([]*domain.DueScheduler, error) {
+	var schedulers []*domain.DueScheduler
+
+	result := conn(ctx, r.db).
+		Model(&domain.Scheduler{}).
+		Select("schedulers.*, events.name AS event_name, events.start_time AS event_start_time").
+		Joins("JOIN events ON events.id = schedulers.event_id AND events.deleted_at IS NULL").
+		Where("schedulers.status = ? AND schedulers.scheduled_at BETWEEN ? AND ? AND schedulers.retries < schedulers.max_retries",
+			domain.SchedulerStatusPending, from, to).
+		Order("schedulers.scheduled_at ASC").
+		Limit(limit).
+		Scan(&schedulers)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return schedulers, nil
+}
+
+func (r *schedulerRepository) ClaimPending(ctx context.Context, before time.Time, staleBefore time.Time, limit int) ([]*domain.Scheduler, error) {
+	var schedulers []*domain.Scheduler
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED: linhas sendo reservadas por outro worker ficam de fora
+		result := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("((status = ? AND scheduled_at <= ?) OR (status = ? AND claimed_at < ?)) AND retries < max_retries",
+				domain.SchedulerStatusPending, before, domain.SchedulerStatusRunning, staleBefore).
+			Order("scheduled_at ASC").
+			Limit(limit).
+			Find(&schedulers)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(schedulers) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(schedulers))
+		for i, s := range schedulers {
+			ids[i] = s.ID
+		}
+
+		now := time.Now()
+		if err := tx.Model(&domain.Scheduler{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":     domain.SchedulerStatusRunning,
+				"claimed_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		for _, s := range schedulers {
+			s.Status = domain.SchedulerStatusRunning
+			s.ClaimedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return schedulers, nil
+}
+
+func (r *schedulerRepository) Claim(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
+	result := conn(ctx, r.db).
+		Model(&domain.Scheduler{}).
+		Where("id = ? AND entity_id = ? AND status = ?", id, entityID, domain.SchedulerStatusPending).
+		Updates(map[string]interface{}{
+			"status":     domain.SchedulerStatusRunning,
+			"claimed_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrSchedulerNotPending
+	}
+
+	return nil
+}
+
+func (r *schedulerRepository) ReleaseClaims(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return conn(ctx, r.db).
+		Model(&domain.Scheduler{}).
+		Where("id IN ? AND status = ?", ids, domain.SchedulerStatusRunning).
+		Updates(map[string]interface{}{
+			"status":     domain.SchedulerStatusPending,
+			"claimed_at": nil,
+		}).Error
+}
+
+func (r *schedulerRepository) Reschedule(ctx context.Context, id uuid.UUID, entityID uuid.UUID, scheduledAt time.Time) error {
+	result := conn(ctx, r.db).
+		Model(&domain.Scheduler{}).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Updates(map[string]interface{}{
+			"status":       domain.SchedulerStatusPending,
+			"scheduled_at": scheduledAt,
+			"claimed_at":   nil,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *schedulerRepository) MarkAsProcessed(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
 	now := time.Now()
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.Scheduler{}).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		Updates(map[string]interface{}{
@@ -116,7 +240,7 @@ func (r *schedulerRepository) MarkAsProcessed(ctx context.Context, id uuid.UUID,
 }
 
 func (r *schedulerRepository) MarkAsFailed(ctx context.Context, id uuid.UUID, entityID uuid.UUID, errorMsg string) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.Scheduler{}).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		Updates(map[string]interface{}{
@@ -136,7 +260,7 @@ func (r *schedulerRepository) MarkAsFailed(ctx context.Context, id uuid.UUID, en
 }
 
 func (r *schedulerRepository) IncrementRetries(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.Scheduler{}).
 		Where("id = ? AND entity_id = ?", id, entityID).
 		UpdateColumn("retries", gorm.Expr("retries + 1"))
@@ -151,3 +275,25 @@ func (r *schedulerRepository) IncrementRetries(ctx context.Context, id uuid.UUID
 
 	return nil
 }
+
+func (r *schedulerRepository) UpdateMetadata(ctx context.Context, id uuid.UUID, entityID uuid.UUID, metadata map[string]interface{}) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	result := conn(ctx, r.db).
+		Model(&domain.Scheduler{}).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Update("metadata", string(data))
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package postgres
+
+import "strings"
+
+// likeEscaper escapa os curingas do LIKE para que o termo buscado seja literal
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern monta o padrão LIKE (minúsculo) que encontra query em qualquer posição
+func containsPattern(query string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(query)) + "%"
+}
+
+// prefixPattern monta o padrão LIKE (minúsculo) que encontra query no início
+func prefixPattern(query string) string {
+	return likeEscaper.Replace(strings.ToLower(query)) + "%"
+}
@@ -20,7 +20,7 @@ func NewStatusHistoryRepository(db *gorm.DB) *statusHistoryRepository {
 
 // Create saves a new status history entry
 func (r *statusHistoryRepository) Create(ctx context.Context, history *domain.StatusHistory) error {
-	return r.db.WithContext(ctx).Create(history).Error
+	return conn(ctx, r.db).Create(history).Error
 }
 
 // ListByResource returns status history for a specific resource
@@ -33,7 +33,7 @@ func (r *statusHistoryRepository) ListByResource(
 	var histories []*domain.StatusHistory
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&domain.StatusHistory{}).
+	query := conn(ctx, r.db).Model(&domain.StatusHistory{}).
 		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID)
 
 	if err := query.Count(&total).Error; err != nil {
@@ -58,7 +58,7 @@ func (r *statusHistoryRepository) ListByEntity(
 	var histories []*domain.StatusHistory
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&domain.StatusHistory{}).
+	query := conn(ctx, r.db).Model(&domain.StatusHistory{}).
 		Where("entity_id = ?", entityID)
 
 	if resourceType != nil {
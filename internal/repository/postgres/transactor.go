@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+// conn returns the transaction bound to ctx (see Transactor) or the base connection
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new transactor. Repositories called with the context
+// passed to fn run inside the transaction.
+func NewTransactor(db *gorm.DB) *transactor {
+	return &transactor{db: db}
+}
+
+// WithinTransaction runs fn in a database transaction, committing if it returns nil
+// and rolling back otherwise. Nested calls reuse the outer transaction.
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
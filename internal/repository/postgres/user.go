@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
@@ -28,7 +29,7 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 		user.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(user)
+	result := conn(ctx, r.db).Create(user)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -39,7 +40,7 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	var user domain.User
 
-	result := r.db.WithContext(ctx).First(&user, "id = ?", id)
+	result := conn(ctx, r.db).First(&user, "id = ?", id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrNotFound
@@ -53,7 +54,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Use
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
 
-	result := r.db.WithContext(ctx).First(&user, "email = ?", email)
+	result := conn(ctx, r.db).First(&user, "email = ?", email)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrNotFound
@@ -65,7 +66,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 }
 
 func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
-	result := r.db.WithContext(ctx).Save(user)
+	result := conn(ctx, r.db).Save(user)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -78,7 +79,7 @@ func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 }
 
 func (r *userRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, loginTime time.Time) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&domain.User{}).
 		Where("id = ?", id).
 		Update("last_login_at", loginTime)
@@ -95,7 +96,7 @@ func (r *userRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, logi
 }
 
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&domain.User{}, "id = ?", id)
+	result := conn(ctx, r.db).Delete(&domain.User{}, "id = ?", id)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -114,7 +115,7 @@ func (r *userRepository) AddToEntity(ctx context.Context, userEnt *domain.UserEn
 		userEnt.ID = uuid.New()
 	}
 
-	result := r.db.WithContext(ctx).Create(userEnt)
+	result := conn(ctx, r.db).Create(userEnt)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -123,7 +124,7 @@ func (r *userRepository) AddToEntity(ctx context.Context, userEnt *domain.UserEn
 }
 
 func (r *userRepository) RemoveFromEntity(ctx context.Context, userID, entID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Delete(&domain.UserEntity{}, "user_id = ? AND entity_id = ?", userID, entID)
 
 	if result.Error != nil {
@@ -140,7 +141,7 @@ func (r *userRepository) RemoveFromEntity(ctx context.Context, userID, entID uui
 func (r *userRepository) GetUserEntities(ctx context.Context, userID uuid.UUID) ([]*domain.UserEntity, error) {
 	var userOrgs []*domain.UserEntity
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("user_id = ?", userID).
 		Find(&userOrgs)
 
@@ -154,7 +155,7 @@ func (r *userRepository) GetUserEntities(ctx context.Context, userID uuid.UUID)
 func (r *userRepository) GetEntityUsers(ctx context.Context, entID uuid.UUID) ([]*domain.User, error) {
 	var users []*domain.User
 
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Joins("JOIN user_entities ON user_entities.user_id = users.id").
 		Where("user_entities.entity_id = ?", entID).
 		Find(&users)
@@ -165,3 +166,53 @@ func (r *userRepository) GetEntityUsers(ctx context.Context, entID uuid.UUID) ([
 
 	return users, nil
 }
+
+func (r *userRepository) GetEntityMembership(ctx context.Context, userID, entID uuid.UUID) (*domain.UserEntity, error) {
+	var membership domain.UserEntity
+
+	result := conn(ctx, r.db).
+		First(&membership, "user_id = ? AND entity_id = ?", userID, entID)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, result.Error
+	}
+
+	return &membership, nil
+}
+
+func (r *userRepository) UpdateEntityRole(ctx context.Context, userID, entID uuid.UUID, role domain.UserRole) error {
+	result := conn(ctx, r.db).
+		Model(&domain.UserEntity{}).
+		Where("user_id = ? AND entity_id = ?", userID, entID).
+		Update("role", role)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) LockEntityUsersByRole(ctx context.Context, entID uuid.UUID, role domain.UserRole) (int64, error) {
+	// FOR UPDATE can't be combined with COUNT, so the rows themselves are locked
+	var userIDs []uuid.UUID
+
+	result := conn(ctx, r.db).
+		Model(&domain.UserEntity{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("entity_id = ? AND role = ?", entID, role).
+		Pluck("user_id", &userIDs)
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return int64(len(userIDs)), nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new outbound webhook repository
+func NewWebhookRepository(db *gorm.DB) *webhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// CreateSubscription saves a new webhook subscription
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	return conn(ctx, r.db).Create(subscription).Error
+}
+
+// ListSubscriptions returns the webhook subscriptions of an entity
+func (r *webhookRepository) ListSubscriptions(ctx context.Context, entityID uuid.UUID) ([]*domain.WebhookSubscription, error) {
+	var subscriptions []*domain.WebhookSubscription
+	err := conn(ctx, r.db).
+		Where("entity_id = ?", entityID).
+		Order("created_at ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// ListActiveSubscriptions returns the active webhook subscriptions of an entity
+func (r *webhookRepository) ListActiveSubscriptions(ctx context.Context, entityID uuid.UUID) ([]*domain.WebhookSubscription, error) {
+	var subscriptions []*domain.WebhookSubscription
+	err := conn(ctx, r.db).
+		Where("entity_id = ? AND active = ?", entityID, true).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteSubscription removes a webhook subscription
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id, entityID uuid.UUID) error {
+	result := conn(ctx, r.db).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		Delete(&domain.WebhookSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// CreateDelivery saves a delivery log entry
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return conn(ctx, r.db).Create(delivery).Error
+}
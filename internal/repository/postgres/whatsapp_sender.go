@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type whatsAppSenderRepository struct {
+	db *gorm.DB
+}
+
+// NewWhatsAppSenderRepository creates a new WhatsApp sender repository
+func NewWhatsAppSenderRepository(db *gorm.DB) *whatsAppSenderRepository {
+	return &whatsAppSenderRepository{db: db}
+}
+
+// Upsert saves the entity's sender, replacing the one already configured
+func (r *whatsAppSenderRepository) Upsert(ctx context.Context, sender *domain.WhatsAppSender) error {
+	return conn(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "entity_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"phone_number_id", "access_token", "updated_at"}),
+		}).
+		Create(sender).Error
+}
+
+// GetByEntity returns the entity's sender, or domain.ErrNotFound if it uses the global one
+func (r *whatsAppSenderRepository) GetByEntity(ctx context.Context, entityID uuid.UUID) (*domain.WhatsAppSender, error) {
+	var sender domain.WhatsAppSender
+
+	err := conn(ctx, r.db).Where("entity_id = ?", entityID).First(&sender).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &sender, nil
+}
+
+// Delete removes the entity's sender
+func (r *whatsAppSenderRepository) Delete(ctx context.Context, entityID uuid.UUID) error {
+	result := conn(ctx, r.db).Where("entity_id = ?", entityID).Delete(&domain.WhatsAppSender{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package router
 
 import (
+	"event-coming/internal/cache"
 	"event-coming/internal/config"
+	"event-coming/internal/domain"
 	"event-coming/internal/handler"
 	"event-coming/internal/handler/middleware"
+	"event-coming/internal/metrics"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -14,47 +17,78 @@ type Router struct {
 	engine             *gin.Engine
 	config             *config.Config
 	logger             *zap.Logger
+	rateLimiter        *cache.RateLimiter
+	idempotency        *cache.IdempotencyStore
+	denylist           *cache.TokenDenylist
+	apiKeys            middleware.APIKeyAuthenticator
+	eventManagers      middleware.EventManagerChecker
+	healthHandler      *handler.HealthHandler
 	authHandler        *handler.AuthHandler
 	websocketHandler   *handler.WebSocketHandler
 	eventCacheHandler  *handler.EventCacheHandler
 	participantHandler *handler.ParticipantHandler
+	groupHandler       *handler.ParticipantGroupHandler
 	eventHandler       *handler.EventHandler
 	entityHandler      *handler.EntityHandler
 	locationHandler    *handler.LocationHandler
 	webhookHandler     *handler.WebhookHandler
+	schedulerHandler   *handler.SchedulerHandler
+	searchHandler      *handler.SearchHandler
 }
 
 // NewRouter creates a new router
 func NewRouter(
 	cfg *config.Config,
 	logger *zap.Logger,
+	rateLimiter *cache.RateLimiter,
+	idempotency *cache.IdempotencyStore,
+	denylist *cache.TokenDenylist,
+	apiKeys middleware.APIKeyAuthenticator,
+	eventManagers middleware.EventManagerChecker,
+	healthHandler *handler.HealthHandler,
 	authHandler *handler.AuthHandler,
 	websocketHandler *handler.WebSocketHandler,
 	eventCacheHandler *handler.EventCacheHandler,
 	participantHandler *handler.ParticipantHandler,
+	groupHandler *handler.ParticipantGroupHandler,
 	eventHandler *handler.EventHandler,
 	entityHandler *handler.EntityHandler,
 	locationHandler *handler.LocationHandler,
 	webhookHandler *handler.WebhookHandler,
+	schedulerHandler *handler.SchedulerHandler,
+	searchHandler *handler.SearchHandler,
 ) *Router {
 	if !cfg.App.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	engine := gin.New()
+	// Without trusted proxies gin would take the client IP from any X-Forwarded-For
+	if err := engine.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Fatal("Invalid trusted proxies", zap.Error(err))
+	}
 
 	return &Router{
 		engine:             engine,
 		config:             cfg,
 		logger:             logger,
+		rateLimiter:        rateLimiter,
+		idempotency:        idempotency,
+		denylist:           denylist,
+		apiKeys:            apiKeys,
+		eventManagers:      eventManagers,
+		healthHandler:      healthHandler,
 		authHandler:        authHandler,
 		websocketHandler:   websocketHandler,
 		eventCacheHandler:  eventCacheHandler,
 		participantHandler: participantHandler,
+		groupHandler:       groupHandler,
 		eventHandler:       eventHandler,
 		entityHandler:      entityHandler,
 		locationHandler:    locationHandler,
 		webhookHandler:     webhookHandler,
+		schedulerHandler:   schedulerHandler,
+		searchHandler:      searchHandler,
 	}
 }
 
@@ -64,41 +98,58 @@ func (r *Router) Setup() *gin.Engine {
 	r.engine.Use(middleware.RequestID())
 	r.engine.Use(middleware.Recovery(r.logger))
 	r.engine.Use(middleware.Logger(r.logger))
-	r.engine.Use(middleware.CORS())
+	r.engine.Use(middleware.CORS(&r.config.Server, r.config.App.Debug))
+
+	// Prometheus metrics (labels use the route template, not the raw path)
+	registry := metrics.NewRegistry()
+	r.engine.Use(middleware.Metrics(metrics.NewHTTPMetrics(registry)))
+	metrics.NewWebSocketMetrics(registry, r.websocketHandler.HubStats)
+	r.engine.GET("/metrics", gin.WrapH(metrics.Handler(registry)))
 
-	// Health check
-	r.engine.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "ok",
-			"service": "event-coming",
-		})
-	})
+	// Health checks (liveness is cheap; readiness pings Postgres and Redis)
+	r.engine.GET("/health", r.healthHandler.Live)
+	r.engine.GET("/health/live", r.healthHandler.Live)
+	r.engine.GET("/health/ready", r.healthHandler.Ready)
 
 	// API v1 routes
 	v1 := r.engine.Group("/api/v1")
 	{
 		// Public routes
+		rl := r.config.RateLimit
+		perIP := r.rateLimit("auth", rl.AuthRequestsPerSecond, rl.AuthBurst, middleware.ByIP)
+		perEmail := r.rateLimit("auth_email", rl.EmailRequestsPerSecond, rl.EmailBurst, middleware.ByJSONEmail)
+
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", r.authHandler.Register)
-			auth.POST("/login", r.authHandler.Login)
+			auth.POST("/register", perIP, r.authHandler.Register)
+			auth.POST("/login", perIP, perEmail, r.authHandler.Login)
 			auth.POST("/refresh", r.authHandler.Refresh)
 			auth.POST("/logout", r.authHandler.Logout)
-			auth.POST("/forgot-password", r.authHandler.ForgotPassword)
+			auth.POST("/forgot-password", perIP, perEmail, r.authHandler.ForgotPassword)
 			auth.POST("/reset-password", r.authHandler.ResetPassword)
 		}
 
+		// RSVP page (public - the signed token identifies the participant)
+		rsvp := v1.Group("/rsvp")
+		rsvp.Use(r.rateLimit("rsvp", rl.RSVPRequestsPerSecond, rl.RSVPBurst, middleware.ByIP))
+		{
+			rsvp.GET("/:token", r.participantHandler.ViewInvite)
+			rsvp.POST("/:token", r.participantHandler.RespondToInvite)
+		}
+
 		// WhatsApp webhook (public - called by WhatsApp servers)
 		webhook := v1.Group("/webhook")
 		{
 			webhook.GET("/whatsapp", r.webhookHandler.VerifyWebhook)
-			webhook.POST("/whatsapp", r.webhookHandler.HandleWebhook)
+			webhook.POST("/whatsapp", r.rateLimit("webhook", rl.WebhookRequestsPerSecond, rl.WebhookBurst, middleware.ByIP), r.webhookHandler.HandleWebhook)
 		}
 
 		// Protected routes (require authentication)
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware(&r.config.JWT))
+		protected.Use(middleware.AuthMiddleware(&r.config.JWT, r.denylist, r.apiKeys, r.logger))
 		{
+			idempotent := middleware.Idempotency(r.idempotency, r.config.Cache.IdempotencyTTL, r.logger)
+
 			// Entities
 			entities := protected.Group("/entities")
 			{
@@ -108,30 +159,90 @@ func (r *Router) Setup() *gin.Engine {
 				entities.PUT("/:id", r.entityHandler.Update)
 				entities.DELETE("/:id", r.entityHandler.Delete)
 				entities.GET("/:id/children", r.entityHandler.ListByParent)
+				entities.GET("/:id/descendants", r.entityHandler.ListDescendants)
+				entities.POST("/:id/members", r.entityHandler.AddMember)
+				entities.GET("/:id/audit", r.entityHandler.ListAudit)
+				entities.POST("/:id/webhooks", middleware.RequireOwnerOrAdmin(), r.entityHandler.CreateWebhook)
+				entities.GET("/:id/webhooks", middleware.RequireOwnerOrAdmin(), r.entityHandler.ListWebhooks)
+				entities.DELETE("/:id/webhooks/:webhook_id", middleware.RequireOwnerOrAdmin(), r.entityHandler.DeleteWebhook)
+				entities.POST("/:id/api-keys", middleware.RequireOwnerOrAdmin(), r.entityHandler.CreateAPIKey)
+				entities.GET("/:id/api-keys", middleware.RequireOwnerOrAdmin(), r.entityHandler.ListAPIKeys)
+				entities.DELETE("/:id/api-keys/:key_id", middleware.RequireOwnerOrAdmin(), r.entityHandler.RevokeAPIKey)
+				entities.PUT("/:id/whatsapp-sender", middleware.RequireOwnerOrAdmin(), r.entityHandler.SetWhatsAppSender)
+				entities.GET("/:id/whatsapp-sender", middleware.RequireOwnerOrAdmin(), r.entityHandler.GetWhatsAppSender)
+				entities.DELETE("/:id/whatsapp-sender", middleware.RequireOwnerOrAdmin(), r.entityHandler.DeleteWhatsAppSender)
+				entities.PUT("/:id/members/:user_id", r.entityHandler.UpdateMemberRole)
+				entities.DELETE("/:id/members/:user_id", r.entityHandler.RemoveMember)
 				entities.GET("/document/:document", r.entityHandler.GetByDocument)
 			}
 
 			// Events
 			events := protected.Group("/events")
 			{
-				events.POST("", r.eventHandler.Create)
+				// Criador, co-organizadores ou admins da entidade
+				manage := middleware.RequireEventManager(r.eventManagers)
+
+				events.POST("", idempotent, r.eventHandler.Create)
+				events.POST("/preview-schedule", r.eventHandler.PreviewSchedule)
+				events.POST("/import.ics", r.eventHandler.ImportICS)
 				events.GET("/:id", r.eventHandler.GetByID)
-				events.PUT("/:id", r.eventHandler.Update)
-				events.DELETE("/:id", r.eventHandler.Delete)
+				events.GET("/:id/detail", r.eventHandler.GetDetail)
+				events.PUT("/:id", manage, r.eventHandler.Update)
+				events.PATCH("/:id", manage, r.eventHandler.Update)
+				events.DELETE("/:id", manage, r.eventHandler.Delete)
 				events.GET("", r.eventHandler.List)
 
 				// Event actions
-				events.POST("/:id/activate", r.eventHandler.Activate)
-				events.POST("/:id/cancel", r.eventHandler.Cancel)
-				events.POST("/:id/complete", r.eventHandler.Complete)
+				events.POST("/:id/activate", manage, r.eventHandler.Activate)
+				events.POST("/:id/cancel", manage, r.eventHandler.Cancel)
+				events.POST("/:id/complete", manage, r.eventHandler.Complete)
+				events.POST("/:id/redact", middleware.RequireOwnerOrAdmin(), r.eventHandler.RedactParticipants)
+				events.GET("/:id/organizers", r.eventHandler.ListOrganizers)
+				events.POST("/:id/organizers", manage, r.eventHandler.AddOrganizer)
+				events.DELETE("/:id/organizers/:user_id", manage, r.eventHandler.RemoveOrganizer)
+				// Locais do evento (/:id/locations são as localizações dos participantes)
+				events.GET("/:id/venues", r.eventHandler.ListLocations)
+				events.POST("/:id/venues", manage, r.eventHandler.AddLocation)
+				events.PUT("/:id/venues/target", manage, r.eventHandler.SetTargetLocation)
+				events.PATCH("/:id/venues/:location_id", manage, r.eventHandler.UpdateLocation)
+				events.DELETE("/:id/venues/:location_id", manage, r.eventHandler.RemoveLocation)
+				events.GET("/:id/report", r.eventHandler.GetReport)
+				events.POST("/:id/broadcast", manage, r.eventHandler.ScheduleBroadcast)
+				events.POST("/:id/broadcast/preview", manage, r.eventHandler.PreviewBroadcast)
+
+				// Ocorrências de eventos recorrentes
+				events.GET("/:id/instances", r.eventHandler.ListInstances)
+				events.POST("/:id/instances/generate", manage, r.eventHandler.GenerateInstances)
+				events.POST("/:id/instances/cancel", manage, r.eventHandler.CancelInstance)
+				events.PUT("/:id/instances", manage, r.eventHandler.OverrideInstance)
 
 				// Participants dentro de Events (usando :id consistente)
-				events.POST("/:id/participants", r.participantHandler.Create)
+				events.POST("/:id/participants", idempotent, r.participantHandler.Create)
 				events.GET("/:id/participants", r.participantHandler.ListByEvent)
-				events.POST("/:id/participants/batch", r.participantHandler.BatchCreate)
+				events.POST("/:id/participants/batch", idempotent, r.participantHandler.BatchCreate)
+				events.POST("/:id/participants/bulk/status", r.participantHandler.BatchUpdateStatus)
+				events.POST("/:id/participants/copy-from/:source_id", r.participantHandler.CopyFromEvent)
+
+				// Grupos de participantes (segmentos para broadcasts e filtros)
+				events.POST("/:id/groups", manage, r.groupHandler.Create)
+				events.GET("/:id/groups", r.groupHandler.List)
+				events.PUT("/:id/groups/:group_id", manage, r.groupHandler.Update)
+				events.DELETE("/:id/groups/:group_id", manage, r.groupHandler.Delete)
+				events.POST("/:id/groups/:group_id/members", manage, r.groupHandler.AddMembers)
+				events.DELETE("/:id/groups/:group_id/members/:participant_id", manage, r.groupHandler.RemoveMember)
 
 				// Locations for event (all participants)
 				events.GET("/:id/locations", r.locationHandler.GetEventLocations)
+				events.GET("/:id/locations/live", r.locationHandler.GetLiveEventLocations)
+
+				// Presença no WebSocket
+				events.GET("/:id/presence", r.websocketHandler.GetPresence)
+
+				// Anexos (agenda, mapa...)
+				events.POST("/:id/attachments", manage, r.eventHandler.UploadAttachment)
+				events.GET("/:id/attachments", r.eventHandler.ListAttachments)
+				events.GET("/:id/attachments/:attachment_id", r.eventHandler.DownloadAttachment)
+				events.DELETE("/:id/attachments/:attachment_id", manage, r.eventHandler.DeleteAttachment)
 			}
 
 			// Participants
@@ -139,16 +250,49 @@ func (r *Router) Setup() *gin.Engine {
 			{
 				participants.GET("/:id", r.participantHandler.GetByID)
 				participants.PUT("/:id", r.participantHandler.Update)
+				participants.PATCH("/:id", r.participantHandler.Update)
 				participants.DELETE("/:id", r.participantHandler.Delete)
 				participants.POST("/:id/confirm", r.participantHandler.Confirm)
 				participants.POST("/:id/check-in", r.participantHandler.CheckIn)
+				participants.POST("/:id/resend-confirmation", idempotent,
+					r.rateLimit("resend_confirmation", r.config.RateLimit.ResendRequestsPerSecond, r.config.RateLimit.ResendBurst, middleware.ByParam("id")),
+					r.participantHandler.ResendConfirmation)
+				participants.POST("/:id/invite-link", r.participantHandler.GenerateInviteLink)
+				participants.GET("/:id/history", r.participantHandler.GetStatusHistory)
+				participants.POST("/:id/reconcile", middleware.RequireOwnerOrAdmin(), r.participantHandler.Reconcile)
 
 				// Locations
-				participants.POST("/:id/locations", r.locationHandler.CreateLocation)
+				participants.POST("/:id/locations", idempotent, r.locationHandler.CreateLocation)
+				participants.POST("/:id/locations/batch", idempotent, r.locationHandler.CreateLocationsBatch)
 				participants.GET("/:id/locations", r.locationHandler.GetLocationHistory)
 				participants.GET("/:id/locations/latest", r.locationHandler.GetLatestLocation)
 			}
 
+			// Admin (visão operacional de todas as entidades)
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireRole(domain.UserRoleSuperAdmin))
+			{
+				admin.POST("/users/:id/deactivate", r.authHandler.DeactivateUser)
+				admin.POST("/users/:id/impersonate", r.authHandler.Impersonate)
+				admin.GET("/schedulers/due", r.schedulerHandler.ListDue)
+			}
+
+			// Busca global (eventos e participantes)
+			protected.GET("/search", r.searchHandler.Global)
+
+			// Visão do participante
+			me := protected.Group("/me")
+			{
+				me.GET("/events", r.eventHandler.ListMine)
+			}
+
+			// Schedulers (disparo manual para testes e recuperação)
+			schedulers := protected.Group("/schedulers")
+			schedulers.Use(middleware.RequireOwnerOrAdmin())
+			{
+				schedulers.POST("/:id/run", r.schedulerHandler.RunNow)
+			}
+
 			// ETA
 			eta := protected.Group("/eta")
 			{
@@ -165,8 +309,11 @@ func (r *Router) Setup() *gin.Engine {
 			}
 		}
 
-		// WebSocket endpoint (fora do protected, autenticação via query param)
+		// WebSocket endpoint (fora do protected, autenticação via header ou query param "token")
 		v1.GET("/ws/:event", r.websocketHandler.HandleConnection)
+
+		// Server-sent events, para redes que bloqueiam o WebSocket (mesma autenticação)
+		v1.GET("/events/:id/stream", r.websocketHandler.HandleStream)
 	}
 
 	return r.engine
@@ -176,3 +323,17 @@ func (r *Router) Setup() *gin.Engine {
 func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
 }
+
+// rateLimit builds a Redis rate limit middleware, or a no-op when rate limiting is disabled
+func (r *Router) rateLimit(name string, rate float64, burst int, key func(*gin.Context) string) gin.HandlerFunc {
+	if r.rateLimiter == nil || !r.config.RateLimit.Enabled || rate <= 0 || burst <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return middleware.RedisRateLimitMiddleware(r.rateLimiter, middleware.RateLimitRule{
+		Name:              name,
+		RequestsPerSecond: rate,
+		BurstSize:         burst,
+		Key:               key,
+	}, r.logger)
+}
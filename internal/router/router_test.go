@@ -0,0 +1,31 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"event-coming/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRouter_Setup_RegistersRoutesWithoutConflicts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := NewRouter(&config.Config{}, zap.NewNop(), nil, nil, nil, nil, nil,
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	// gin panics when the same method and path are registered twice
+	var engine *gin.Engine
+	require.NotPanics(t, func() { engine = r.Setup() })
+
+	routes := make(map[string]bool)
+	for _, route := range engine.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	assert.True(t, routes[http.MethodGet+" /api/v1/events/:id/locations"], "participant location history")
+	assert.True(t, routes[http.MethodGet+" /api/v1/events/:id/venues"], "event venues")
+}
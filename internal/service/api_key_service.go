@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// apiKeyDisplayLength is how many characters of the key (prefix included) are kept for display
+const apiKeyDisplayLength = 11
+
+// apiKeyTouchInterval limits how often last_used_at is written for a busy key
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService manages the API keys of an entity and authenticates them
+type APIKeyService struct {
+	repo     repository.APIKeyRepository
+	userRepo repository.UserRepository
+	logger   *zap.Logger
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo repository.APIKeyRepository, userRepo repository.UserRepository, logger *zap.Logger) *APIKeyService {
+	return &APIKeyService{repo: repo, userRepo: userRepo, logger: logger}
+}
+
+// Create issues a key for the entity. The raw key is only returned here.
+func (s *APIKeyService) Create(ctx context.Context, entID, createdBy uuid.UUID, req *dto.CreateAPIKeyRequest) (*dto.APIKeyResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", domain.ErrInvalidInput)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	rawKey := domain.APIKeyPrefix + hex.EncodeToString(buf)
+
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		EntityID:  entID,
+		Name:      req.Name,
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		KeyHash:   domain.HashAPIKey(rawKey), // Salvamos o hash, não a chave
+		Role:      req.Role,
+		CreatedBy: createdBy,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	resp := dto.ToAPIKeyResponse(key)
+	resp.Key = rawKey
+	return resp, nil
+}
+
+// List returns the keys of an entity
+func (s *APIKeyService) List(ctx context.Context, entID uuid.UUID) ([]*dto.APIKeyResponse, error) {
+	keys, err := s.repo.ListByEntity(ctx, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = dto.ToAPIKeyResponse(key)
+	}
+	return responses, nil
+}
+
+// Revoke revokes a key of the entity
+func (s *APIKeyService) Revoke(ctx context.Context, entID, keyID uuid.UUID) error {
+	return s.repo.Revoke(ctx, keyID, entID)
+}
+
+// Authenticate returns the active key matching the raw key, or domain.ErrInvalidToken
+// when there is none, it expired or its creator, on whose behalf it acts, is no longer an
+// active member of the key's entity. Other errors are lookup failures.
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (*domain.APIKey, error) {
+	key, err := s.repo.GetByHash(ctx, domain.HashAPIKey(rawKey))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if key.Expired(time.Now()) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if err := s.checkCreator(ctx, key); err != nil {
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+			s.logger.Warn("Failed to record API key usage",
+				zap.String("api_key_id", key.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return key, nil
+}
+
+// checkCreator requires the key's creator to be active and still a member of its entity.
+// The key acts with the lower of its own role and the creator's current one, so demoting
+// the creator also limits the keys they created
+func (s *APIKeyService) checkCreator(ctx context.Context, key *domain.APIKey) error {
+	creator, err := s.userRepo.GetByID(ctx, key.CreatedBy)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+	if creator == nil || !creator.Active {
+		return domain.ErrInvalidToken
+	}
+
+	memberships, err := s.userRepo.GetUserEntities(ctx, key.CreatedBy)
+	if err != nil {
+		return err
+	}
+	for _, m := range memberships {
+		if m.EntityID != key.EntityID {
+			continue
+		}
+		if m.Role.Level() < key.Role.Level() {
+			key.Role = m.Role
+		}
+		return nil
+	}
+	return domain.ErrInvalidToken
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type apiKeyServiceDeps struct {
+	repo     *mocks.MockAPIKeyRepository
+	userRepo *mocks.MockUserRepository
+}
+
+func newTestAPIKeyService() (*APIKeyService, *apiKeyServiceDeps) {
+	deps := &apiKeyServiceDeps{
+		repo:     new(mocks.MockAPIKeyRepository),
+		userRepo: new(mocks.MockUserRepository),
+	}
+	return NewAPIKeyService(deps.repo, deps.userRepo, zap.NewNop()), deps
+}
+
+// newTestAPIKey devolve uma chave ativa da entidade de teste criada pelo usuário de teste
+func newTestAPIKey(rawKey string) *domain.APIKey {
+	return &domain.APIKey{
+		ID:        testutil.TestUserID,
+		EntityID:  testutil.TestEntityID,
+		Name:      "ERP",
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		KeyHash:   domain.HashAPIKey(rawKey),
+		Role:      domain.UserRoleEntityManager,
+		CreatedBy: testutil.TestUserID,
+	}
+}
+
+func TestAPIKeyService_Create_StoresOnlyTheHash(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestAPIKeyService()
+
+	var stored *domain.APIKey
+	deps.repo.On("Create", ctx, mock.AnythingOfType("*domain.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.APIKey) }).
+		Return(nil)
+
+	resp, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, &dto.CreateAPIKeyRequest{
+		Name: "ERP",
+		Role: domain.UserRoleEntityManager,
+	})
+	require.NoError(t, err)
+
+	// A chave só aparece na resposta da criação; o banco guarda o hash
+	require.True(t, strings.HasPrefix(resp.Key, domain.APIKeyPrefix))
+	require.NotNil(t, stored)
+	assert.Equal(t, domain.HashAPIKey(resp.Key), stored.KeyHash)
+	assert.NotContains(t, stored.KeyHash, resp.Key)
+	assert.Equal(t, resp.Key[:apiKeyDisplayLength], stored.Prefix)
+	assert.Equal(t, testutil.TestUserID, stored.CreatedBy)
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	const rawKey = "sk_0123456789abcdef"
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		key      *domain.APIKey
+		lookup   error
+		entities []*domain.UserEntity
+		wantErr  error
+		wantRole domain.UserRole
+	}{
+		{
+			name:     "valid key",
+			key:      newTestAPIKey(rawKey),
+			entities: []*domain.UserEntity{{UserID: testutil.TestUserID, EntityID: testutil.TestEntityID, Role: domain.UserRoleEntityAdmin}},
+			wantRole: domain.UserRoleEntityManager,
+		},
+		{
+			// O criador rebaixado a viewer limita a chave de manager
+			name:     "creator demoted below the key role",
+			key:      newTestAPIKey(rawKey),
+			entities: []*domain.UserEntity{{UserID: testutil.TestUserID, EntityID: testutil.TestEntityID, Role: domain.UserRoleEntityViewer}},
+			wantRole: domain.UserRoleEntityViewer,
+		},
+		{
+			// A busca ignora chaves revogadas
+			name:    "revoked key",
+			lookup:  domain.ErrNotFound,
+			wantErr: domain.ErrInvalidToken,
+		},
+		{
+			name: "expired key",
+			key: func() *domain.APIKey {
+				k := newTestAPIKey(rawKey)
+				k.ExpiresAt = &past
+				return k
+			}(),
+			wantErr: domain.ErrInvalidToken,
+		},
+		{
+			name:     "creator left the entity",
+			key:      newTestAPIKey(rawKey),
+			entities: []*domain.UserEntity{},
+			wantErr:  domain.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestAPIKeyService()
+
+			deps.repo.On("GetByHash", ctx, domain.HashAPIKey(rawKey)).Return(tt.key, tt.lookup)
+			deps.repo.On("TouchLastUsed", ctx, mock.Anything).Return(nil)
+			deps.userRepo.On("GetByID", ctx, testutil.TestUserID).Return(testutil.NewTestUser(), nil)
+			deps.userRepo.On("GetUserEntities", ctx, testutil.TestUserID).Return(tt.entities, nil)
+
+			key, err := svc.Authenticate(ctx, rawKey)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, key)
+				deps.repo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testutil.TestEntityID, key.EntityID)
+			assert.Equal(t, tt.wantRole, key.Role)
+			deps.repo.AssertCalled(t, "TouchLastUsed", ctx, key.ID)
+		})
+	}
+}
+
+func TestAPIKeyService_Authenticate_LookupFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestAPIKeyService()
+
+	lookupErr := errors.New("connection refused")
+	deps.repo.On("GetByHash", ctx, mock.Anything).Return(nil, lookupErr)
+
+	// Falha de banco não vira 401: o middleware responde 500
+	_, err := svc.Authenticate(ctx, "sk_0123456789abcdef")
+	assert.ErrorIs(t, err, lookupErr)
+	assert.NotErrorIs(t, err, domain.ErrInvalidToken)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+	"event-coming/pkg/pagination"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// auditIgnoredFields are not included in diffs
+var auditIgnoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// AuditService records entity-scoped mutations
+type AuditService struct {
+	repo   repository.AuditLogRepository
+	logger *zap.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repository.AuditLogRepository, logger *zap.Logger) *AuditService {
+	return &AuditService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record writes an audit entry with the diff between before and after (either may be nil).
+// The actor (and the impersonating super admin, if any) is taken from the context. Writes
+// are best effort: failures are only logged.
+func (s *AuditService) Record(
+	ctx context.Context,
+	entID uuid.UUID,
+	action domain.AuditAction,
+	targetType domain.AuditTargetType,
+	targetID uuid.UUID,
+	before, after interface{},
+) {
+	if s == nil {
+		return
+	}
+
+	if err := s.Write(ctx, entID, action, targetType, targetID, before, after); err != nil {
+		s.logger.Warn("Failed to write audit log",
+			zap.String("entity_id", entID.String()),
+			zap.String("action", string(action)),
+			zap.String("target_id", targetID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// Write is Record for entries the operation can't go on without: the write error is
+// returned instead of logged, and a nil service is an error rather than a no-op
+func (s *AuditService) Write(
+	ctx context.Context,
+	entID uuid.UUID,
+	action domain.AuditAction,
+	targetType domain.AuditTargetType,
+	targetID uuid.UUID,
+	before, after interface{},
+) error {
+	if s == nil {
+		return errors.New("audit log is not configured")
+	}
+
+	entry := &domain.AuditLog{
+		ID:                 uuid.New(),
+		EntityID:           entID,
+		ActorUserID:        domain.ActorFromContext(ctx),
+		ImpersonatorUserID: domain.ImpersonatorFromContext(ctx),
+		Action:             action,
+		TargetType:         targetType,
+		TargetID:           targetID,
+		Diff:               auditDiff(before, after),
+	}
+
+	return s.repo.Create(ctx, entry)
+}
+
+// List returns the audit log of an entity, optionally filtered by action
+func (s *AuditService) List(ctx context.Context, entID uuid.UUID, action *domain.AuditAction, page, perPage int) ([]*dto.AuditLogResponse, int64, error) {
+	page, perPage = pagination.Normalize(page, perPage)
+
+	entries, total, err := s.repo.List(ctx, &domain.AuditLogQuery{
+		EntityID: entID,
+		Action:   action,
+		Page:     page,
+		PerPage:  perPage,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return dto.ToAuditLogResponseList(entries), total, nil
+}
+
+// auditDiff returns {"field": {"from": x, "to": y}} for every changed field
+func auditDiff(before, after interface{}) map[string]any {
+	from := auditFields(before)
+	to := auditFields(after)
+
+	diff := make(map[string]any)
+	for key, value := range to {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if old, ok := from[key]; !ok || !reflect.DeepEqual(old, value) {
+			diff[key] = map[string]any{"from": from[key], "to": value}
+		}
+	}
+	for key, value := range from {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if _, ok := to[key]; !ok {
+			diff[key] = map[string]any{"from": value, "to": nil}
+		}
+	}
+
+	return diff
+}
+
+// auditFields converts a value to its JSON field map
+func auditFields(v interface{}) map[string]any {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestAuditedParticipantService() (*ParticipantService, *mocks.MockParticipantRepository, *mocks.MockAuditLogRepository) {
+	participantRepo := new(mocks.MockParticipantRepository)
+	auditRepo := new(mocks.MockAuditLogRepository)
+	audit := NewAuditService(auditRepo, zap.NewNop())
+	eventRepo := new(mocks.MockEventRepository)
+	eventRepo.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(testutil.NewTestEvent(), nil).Maybe()
+	return NewParticipantService(participantRepo, eventRepo, nil, nil, audit, nil, nil, nil, nil, nil, nil, nil), participantRepo, auditRepo
+}
+
+func TestParticipantService_UpdateStatus_RecordsAuditLog(t *testing.T) {
+	actorID := uuid.New()
+	ctx := domain.WithActor(context.Background(), actorID)
+	svc, participantRepo, auditRepo := newTestAuditedParticipantService()
+
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusPending
+	participantRepo.On("GetByID", ctx, participant.ID, participant.EntityID).Return(participant, nil).Once()
+	participantRepo.On("UpdateStatus", ctx, participant.ID, participant.EntityID, domain.ParticipantStatusConfirmed).Return(nil)
+	participantRepo.On("GetByID", ctx, participant.ID, participant.EntityID).
+		Return(withStatus(participant, domain.ParticipantStatusConfirmed), nil)
+
+	var entry *domain.AuditLog
+	auditRepo.On("Create", ctx, mock.AnythingOfType("*domain.AuditLog")).
+		Run(func(args mock.Arguments) { entry = args.Get(1).(*domain.AuditLog) }).
+		Return(nil)
+
+	require.NoError(t, svc.UpdateStatus(ctx, participant.EntityID, participant.ID, domain.ParticipantStatusConfirmed))
+
+	require.NotNil(t, entry)
+	assert.Equal(t, participant.EntityID, entry.EntityID)
+	require.NotNil(t, entry.ActorUserID)
+	assert.Equal(t, actorID, *entry.ActorUserID)
+	assert.Equal(t, domain.AuditActionStatusChange, entry.Action)
+	assert.Equal(t, domain.AuditTargetParticipant, entry.TargetType)
+	assert.Equal(t, participant.ID, entry.TargetID)
+	assert.Equal(t, map[string]any{
+		"status": map[string]any{
+			"from": string(domain.ParticipantStatusPending),
+			"to":   string(domain.ParticipantStatusConfirmed),
+		},
+	}, entry.Diff)
+}
+
+func TestParticipantService_UpdateStatus_AuditFailureDoesNotFailRequest(t *testing.T) {
+	ctx := context.Background()
+	svc, participantRepo, auditRepo := newTestAuditedParticipantService()
+
+	participant := testutil.NewTestParticipant()
+	participantRepo.On("GetByID", ctx, participant.ID, participant.EntityID).Return(participant, nil)
+	participantRepo.On("UpdateStatus", ctx, participant.ID, participant.EntityID, domain.ParticipantStatusDenied).Return(nil)
+	auditRepo.On("Create", ctx, mock.AnythingOfType("*domain.AuditLog")).Return(errors.New("database down"))
+
+	require.NoError(t, svc.UpdateStatus(ctx, participant.EntityID, participant.ID, domain.ParticipantStatusDenied))
+	auditRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestAuditDiff(t *testing.T) {
+	type target struct {
+		Name      string `json:"name"`
+		Note      string `json:"note,omitempty"`
+		UpdatedAt int    `json:"updated_at"`
+	}
+
+	assert.Equal(t, map[string]any{
+		"name": map[string]any{"from": "old", "to": "new"},
+		"note": map[string]any{"from": "removed", "to": nil},
+	}, auditDiff(&target{Name: "old", Note: "removed", UpdatedAt: 1}, &target{Name: "new", UpdatedAt: 2}))
+
+	// Criação: todos os campos vêm de nil
+	assert.Equal(t, map[string]any{
+		"name": map[string]any{"from": nil, "to": "new"},
+	}, auditDiff(nil, &target{Name: "new"}))
+
+	assert.Empty(t, auditDiff(&target{Name: "same"}, &target{Name: "same"}))
+}
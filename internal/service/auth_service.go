@@ -5,8 +5,10 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
+	"event-coming/internal/cache"
 	"event-coming/internal/config"
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
@@ -32,6 +34,8 @@ type AuthService interface {
 	Logout(ctx context.Context, req dto.LogoutRequest) error
 	ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) (*dto.ForgotPasswordResponse, error)
 	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) (*dto.ResetPasswordResponse, error)
+	DeactivateUser(ctx context.Context, adminUserID, targetUserID uuid.UUID) error
+	Impersonate(ctx context.Context, adminUserID, targetUserID uuid.UUID) (*dto.ImpersonateResponse, error)
 }
 
 type authServiceImpl struct {
@@ -39,6 +43,8 @@ type authServiceImpl struct {
 	tokenRepo         repository.RefreshTokenRepository
 	passwordResetRepo repository.PasswordResetTokenRepository
 	entityRepo        repository.EntityRepository
+	denylist          *cache.TokenDenylist
+	audit             *AuditService
 	config            *config.JWTConfig
 }
 
@@ -47,6 +53,8 @@ func NewAuthService(
 	tokenRepo repository.RefreshTokenRepository,
 	passwordResetRepo repository.PasswordResetTokenRepository,
 	entityRepo repository.EntityRepository,
+	denylist *cache.TokenDenylist,
+	audit *AuditService,
 	config *config.JWTConfig,
 ) AuthService {
 	return &authServiceImpl{
@@ -54,6 +62,8 @@ func NewAuthService(
 		tokenRepo:         tokenRepo,
 		passwordResetRepo: passwordResetRepo,
 		entityRepo:        entityRepo,
+		denylist:          denylist,
+		audit:             audit,
 		config:            config,
 	}
 }
@@ -232,12 +242,19 @@ func (s *authServiceImpl) Refresh(ctx context.Context, req dto.RefreshRequest) (
 // ==================== HELPERS ====================
 
 func (s *authServiceImpl) generateAccessToken(user *domain.User) (string, error) {
+	return s.signClaims(s.accessTokenClaims(user, s.config.AccessExpiresIn))
+}
+
+// accessTokenClaims monta as claims do access token do usuário, com a entidade e o papel
+// da sua primeira associação
+func (s *authServiceImpl) accessTokenClaims(user *domain.User, expiresIn time.Duration) jwt.MapClaims {
 	claims := jwt.MapClaims{
+		"jti":     uuid.New().String(),
 		"sub":     user.ID.String(),
 		"user_id": user.ID.String(),
 		"email":   user.Email,
 		"name":    user.Name,
-		"exp":     time.Now().Add(s.config.AccessExpiresIn).Unix(),
+		"exp":     time.Now().Add(expiresIn).Unix(),
 		"iat":     time.Now().Unix(),
 	}
 
@@ -250,8 +267,17 @@ func (s *authServiceImpl) generateAccessToken(user *domain.User) (string, error)
 		claims["role"] = string(primaryEntity.Role)
 	}
 
+	return claims
+}
+
+// signClaims assina as claims com a chave ativa, informando o kid no header
+func (s *authServiceImpl) signClaims(claims jwt.MapClaims) (string, error) {
+	kid, secret := s.config.SigningKey()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.AccessSecret))
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString([]byte(secret))
 }
 
 func (s *authServiceImpl) generateRefreshToken(ctx context.Context, user *domain.User) (string, error) {
@@ -281,6 +307,135 @@ func (s *authServiceImpl) hashToken(token string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// DeactivateUser desativa o usuário informado e revoga suas sessões, como no reset de
+// senha. Apenas super admins podem desativar usuários, e não a si mesmos
+func (s *authServiceImpl) DeactivateUser(ctx context.Context, adminUserID, targetUserID uuid.UUID) error {
+	if adminUserID == targetUserID {
+		// Um admin não pode se desativar
+		return domain.ErrInvalidInput
+	}
+
+	isAdmin, err := s.isSuperAdmin(ctx, adminUserID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return domain.ErrForbidden
+	}
+
+	target, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return domain.ErrNotFound
+	}
+
+	if target.Active {
+		target.Active = false
+		if err := s.userRepo.Update(ctx, target); err != nil {
+			return fmt.Errorf("failed to deactivate user: %w", err)
+		}
+	}
+
+	s.revokeSessions(ctx, targetUserID)
+
+	return nil
+}
+
+// Impersonate emite para o super admin um access token de curta duração do usuário alvo.
+// O token tem sub do alvo e a claim "act" com o admin (RFC 8693), para que as ações
+// feitas com ele sejam atribuídas aos dois. Não há refresh token, e outros super admins
+// não podem ser impersonados. A emissão só é concluída se a auditoria for gravada
+func (s *authServiceImpl) Impersonate(ctx context.Context, adminUserID, targetUserID uuid.UUID) (*dto.ImpersonateResponse, error) {
+	if adminUserID == targetUserID {
+		// Um admin não pode impersonar a si mesmo
+		return nil, domain.ErrInvalidInput
+	}
+
+	isAdmin, err := s.isSuperAdmin(ctx, adminUserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, domain.ErrForbidden
+	}
+
+	target, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, domain.ErrNotFound
+	}
+	if !target.Active {
+		return nil, fmt.Errorf("%w: user is deactivated", domain.ErrInvalidInput)
+	}
+
+	memberships, err := s.userRepo.GetUserEntities(ctx, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range memberships {
+		if m.Role == domain.UserRoleSuperAdmin {
+			return nil, domain.ErrForbidden
+		}
+	}
+	if len(memberships) == 0 {
+		return nil, fmt.Errorf("%w: user has no entity", domain.ErrInvalidInput)
+	}
+
+	expiresAt := time.Now().Add(s.config.ImpersonationExpiresIn)
+	claims := s.accessTokenClaims(target, s.config.ImpersonationExpiresIn)
+	claims["exp"] = expiresAt.Unix()
+	claims["act"] = map[string]interface{}{"sub": adminUserID.String()}
+
+	accessToken, err := s.signClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// A entrada fica na entidade principal do alvo, que é a do token
+	auditCtx := domain.WithActor(ctx, adminUserID)
+	if err := s.audit.Write(auditCtx, memberships[0].EntityID, domain.AuditActionImpersonate, domain.AuditTargetUser, targetUserID, nil, map[string]interface{}{
+		"token_id":   claims["jti"],
+		"expires_at": expiresAt.UTC(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to audit impersonation: %w", err)
+	}
+
+	return &dto.ImpersonateResponse{
+		AccessToken:    accessToken,
+		ExpiresIn:      int64(s.config.ImpersonationExpiresIn.Seconds()),
+		UserID:         targetUserID.String(),
+		ImpersonatorID: adminUserID.String(),
+	}, nil
+}
+
+// revokeSessions revoga os refresh tokens do usuário e invalida os access tokens já
+// emitidos. Usado no reset de senha e na desativação
+func (s *authServiceImpl) revokeSessions(ctx context.Context, userID uuid.UUID) {
+	_ = s.tokenRepo.RevokeAllByUserID(ctx, userID)
+
+	if s.denylist != nil {
+		_ = s.denylist.RevokeUser(ctx, userID, s.config.AccessExpiresIn)
+	}
+}
+
+// isSuperAdmin indica se o usuário é super admin em alguma das suas entidades
+func (s *authServiceImpl) isSuperAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	memberships, err := s.userRepo.GetUserEntities(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, m := range memberships {
+		if m.Role == domain.UserRoleSuperAdmin {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // ==================== LOGOUT ====================
 
 func (s *authServiceImpl) Logout(ctx context.Context, req dto.LogoutRequest) error {
@@ -292,6 +447,11 @@ func (s *authServiceImpl) Logout(ctx context.Context, req dto.LogoutRequest) err
 		return ErrInvalidToken
 	}
 
+	// Invalidar também o access token atual até ele expirar
+	if s.denylist != nil && req.AccessTokenID != "" {
+		_ = s.denylist.Revoke(ctx, req.AccessTokenID, time.Until(req.AccessTokenExpiresAt))
+	}
+
 	return nil
 }
 
@@ -370,8 +530,8 @@ func (s *authServiceImpl) ResetPassword(ctx context.Context, req dto.ResetPasswo
 	// 6. Marcar token como usado
 	_ = s.passwordResetRepo.MarkAsUsed(ctx, resetToken.ID)
 
-	// 7. Revogar todos os refresh tokens do usuário (força re-login)
-	_ = s.tokenRepo.RevokeAllByUserID(ctx, user.ID)
+	// 7. Revogar refresh e access tokens do usuário (força re-login)
+	s.revokeSessions(ctx, user.ID)
 
 	return &dto.ResetPasswordResponse{
 		Message: "Password has been reset successfully. Please login with your new password.",
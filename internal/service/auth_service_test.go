@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testAuthSecret = "test-access-secret"
+
+func newTestAuthService() (AuthService, *mocks.MockUserRepository, *mocks.MockAuditLogRepository) {
+	userRepo := new(mocks.MockUserRepository)
+	auditRepo := new(mocks.MockAuditLogRepository)
+	cfg := &config.JWTConfig{
+		AccessSecret:           testAuthSecret,
+		AccessExpiresIn:        15 * time.Minute,
+		RefreshExpiresIn:       7 * 24 * time.Hour,
+		ImpersonationExpiresIn: 5 * time.Minute,
+	}
+	svc := NewAuthService(userRepo, nil, nil, nil, nil, NewAuditService(auditRepo, zap.NewNop()), cfg)
+	return svc, userRepo, auditRepo
+}
+
+func TestAuthService_Impersonate_IssuesShortLivedTokenWithActClaim(t *testing.T) {
+	ctx := context.Background()
+	svc, userRepo, auditRepo := newTestAuthService()
+
+	adminID := uuid.New()
+	target := testutil.NewTestUser()
+	userRepo.On("GetUserEntities", mock.Anything, adminID).
+		Return([]*domain.UserEntity{{UserID: adminID, EntityID: uuid.New(), Role: domain.UserRoleSuperAdmin}}, nil)
+	userRepo.On("GetByID", ctx, target.ID).Return(target, nil)
+	userRepo.On("GetUserEntities", mock.Anything, target.ID).
+		Return([]*domain.UserEntity{{UserID: target.ID, EntityID: testutil.TestEntityID, Role: domain.UserRoleEntityManager}}, nil)
+
+	var entry *domain.AuditLog
+	auditRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.AuditLog")).
+		Run(func(args mock.Arguments) { entry = args.Get(1).(*domain.AuditLog) }).
+		Return(nil)
+
+	result, err := svc.Impersonate(ctx, adminID, target.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), result.ExpiresIn)
+	assert.Equal(t, target.ID.String(), result.UserID)
+	assert.Equal(t, adminID.String(), result.ImpersonatorID)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(result.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(testAuthSecret), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, target.ID.String(), claims["sub"])
+	assert.Equal(t, target.ID.String(), claims["user_id"])
+	assert.Equal(t, testutil.TestEntityID.String(), claims["entity_id"])
+	assert.Equal(t, string(domain.UserRoleEntityManager), claims["role"])
+	assert.Equal(t, map[string]interface{}{"sub": adminID.String()}, claims["act"])
+
+	exp, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), exp.Time, 5*time.Second)
+
+	require.NotNil(t, entry, "the impersonation is audited")
+	assert.Equal(t, testutil.TestEntityID, entry.EntityID)
+	require.NotNil(t, entry.ActorUserID)
+	assert.Equal(t, adminID, *entry.ActorUserID)
+	assert.Equal(t, domain.AuditActionImpersonate, entry.Action)
+	assert.Equal(t, domain.AuditTargetUser, entry.TargetType)
+	assert.Equal(t, target.ID, entry.TargetID)
+	require.Contains(t, entry.Diff, "token_id")
+	assert.Equal(t, map[string]any{"from": nil, "to": claims["jti"]}, entry.Diff["token_id"])
+}
+
+func TestAuthService_Impersonate_DeniesSuperAdminTarget(t *testing.T) {
+	ctx := context.Background()
+	svc, userRepo, auditRepo := newTestAuthService()
+
+	adminID := uuid.New()
+	target := testutil.NewTestUser()
+	userRepo.On("GetUserEntities", ctx, adminID).
+		Return([]*domain.UserEntity{{UserID: adminID, EntityID: uuid.New(), Role: domain.UserRoleSuperAdmin}}, nil)
+	userRepo.On("GetByID", ctx, target.ID).Return(target, nil)
+	userRepo.On("GetUserEntities", ctx, target.ID).
+		Return([]*domain.UserEntity{{UserID: target.ID, EntityID: testutil.TestEntityID, Role: domain.UserRoleSuperAdmin}}, nil)
+
+	_, err := svc.Impersonate(ctx, adminID, target.ID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	auditRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_Impersonate_RequiresSuperAdmin(t *testing.T) {
+	ctx := context.Background()
+	svc, userRepo, _ := newTestAuthService()
+
+	adminID := uuid.New()
+	userRepo.On("GetUserEntities", ctx, adminID).
+		Return([]*domain.UserEntity{{UserID: adminID, EntityID: testutil.TestEntityID, Role: domain.UserRoleEntityOwner}}, nil)
+
+	_, err := svc.Impersonate(ctx, adminID, testutil.TestUserID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	userRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_Impersonate_FailsWhenAuditFails(t *testing.T) {
+	ctx := context.Background()
+	svc, userRepo, auditRepo := newTestAuthService()
+
+	adminID := uuid.New()
+	target := testutil.NewTestUser()
+	userRepo.On("GetUserEntities", mock.Anything, adminID).
+		Return([]*domain.UserEntity{{UserID: adminID, EntityID: uuid.New(), Role: domain.UserRoleSuperAdmin}}, nil)
+	userRepo.On("GetByID", ctx, target.ID).Return(target, nil)
+	userRepo.On("GetUserEntities", mock.Anything, target.ID).
+		Return([]*domain.UserEntity{{UserID: target.ID, EntityID: testutil.TestEntityID, Role: domain.UserRoleEntityViewer}}, nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	result, err := svc.Impersonate(ctx, adminID, target.ID)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, result, "no token is handed out without an audit entry")
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// fallbackErrorCodes são códigos de erro do WhatsApp em que a mensagem não vai chegar
+// pelo canal e vale tentar outro (ex.: número sem WhatsApp, janela de 24h expirada)
+var fallbackErrorCodes = map[int]bool{
+	131026: true, // Message undeliverable
+	131047: true, // Re-engagement message (fora da janela de 24h)
+	131049: true, // Meta chose not to deliver
+	131051: true, // Unsupported message type
+}
+
+// FallbackNotifier envia uma notificação por um canal alternativo
+type FallbackNotifier interface {
+	Notify(ctx context.Context, delivery *domain.NotificationDelivery) error
+}
+
+// DeliveryTrackingService atualiza o log de entregas a partir dos callbacks de status
+type DeliveryTrackingService struct {
+	deliveryRepo repository.NotificationDeliveryRepository
+	fallback     FallbackNotifier
+	logger       *zap.Logger
+}
+
+// NewDeliveryTrackingService cria um novo serviço de acompanhamento de entregas.
+// fallback é opcional (nil desativa o canal alternativo).
+func NewDeliveryTrackingService(
+	deliveryRepo repository.NotificationDeliveryRepository,
+	fallback FallbackNotifier,
+	logger *zap.Logger,
+) *DeliveryTrackingService {
+	return &DeliveryTrackingService{
+		deliveryRepo: deliveryRepo,
+		fallback:     fallback,
+		logger:       logger,
+	}
+}
+
+// deliveryStatusRank ordena os status para ignorar callbacks fora de ordem
+var deliveryStatusRank = map[domain.DeliveryStatus]int{
+	domain.DeliveryStatusSent:      1,
+	domain.DeliveryStatusDelivered: 2,
+	domain.DeliveryStatusRead:      3,
+	domain.DeliveryStatusFailed:    4,
+}
+
+// UpdateStatus aplica um status recebido do provedor à entrega correspondente
+func (s *DeliveryTrackingService) UpdateStatus(
+	ctx context.Context,
+	providerMessageID string,
+	status domain.DeliveryStatus,
+	errorCode *int,
+	errorMessage *string,
+) error {
+	if _, ok := deliveryStatusRank[status]; !ok {
+		return domain.ErrInvalidInput
+	}
+
+	delivery, err := s.deliveryRepo.GetByProviderMessageID(ctx, providerMessageID)
+	if err != nil {
+		return err
+	}
+
+	// WhatsApp pode entregar "delivered" depois de "read"; não regredir
+	if deliveryStatusRank[status] <= deliveryStatusRank[delivery.Status] {
+		return nil
+	}
+
+	delivery.Status = status
+	delivery.ErrorCode = errorCode
+	delivery.ErrorMessage = errorMessage
+
+	if err := s.deliveryRepo.UpdateStatus(ctx, delivery); err != nil {
+		return err
+	}
+
+	if status == domain.DeliveryStatusFailed && errorCode != nil && fallbackErrorCodes[*errorCode] {
+		s.triggerFallback(ctx, delivery)
+	}
+
+	return nil
+}
+
+// triggerFallback tenta o canal alternativo, se configurado
+func (s *DeliveryTrackingService) triggerFallback(ctx context.Context, delivery *domain.NotificationDelivery) {
+	if s.fallback == nil {
+		s.logger.Info("Notification failed and no fallback channel configured",
+			zap.String("message_id", delivery.ProviderMessageID),
+			zap.Intp("error_code", delivery.ErrorCode),
+		)
+		return
+	}
+
+	if err := s.fallback.Notify(ctx, delivery); err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Error("Fallback notification failed",
+			zap.String("message_id", delivery.ProviderMessageID),
+			zap.Error(err),
+		)
+	}
+}
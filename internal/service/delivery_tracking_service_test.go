@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingFallback registra as entregas repassadas ao canal alternativo
+type recordingFallback struct {
+	notified []*domain.NotificationDelivery
+}
+
+func (f *recordingFallback) Notify(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	f.notified = append(f.notified, delivery)
+	return nil
+}
+
+func newTestDelivery(status domain.DeliveryStatus) *domain.NotificationDelivery {
+	return &domain.NotificationDelivery{
+		ID:                uuid.New(),
+		Recipient:         "5511999999999",
+		ProviderMessageID: "wamid.1",
+		Status:            status,
+	}
+}
+
+func TestDeliveryTrackingService_UpdateStatus_AdvancesStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.MockNotificationDeliveryRepository)
+	svc := NewDeliveryTrackingService(repo, nil, zap.NewNop())
+
+	delivery := newTestDelivery(domain.DeliveryStatusSent)
+	repo.On("GetByProviderMessageID", ctx, "wamid.1").Return(delivery, nil)
+	repo.On("UpdateStatus", ctx, delivery).Return(nil)
+
+	require.NoError(t, svc.UpdateStatus(ctx, "wamid.1", domain.DeliveryStatusDelivered, nil, nil))
+	assert.Equal(t, domain.DeliveryStatusDelivered, delivery.Status)
+	repo.AssertNumberOfCalls(t, "UpdateStatus", 1)
+}
+
+func TestDeliveryTrackingService_UpdateStatus_IgnoresOutOfOrderCallbacks(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.MockNotificationDeliveryRepository)
+	svc := NewDeliveryTrackingService(repo, nil, zap.NewNop())
+
+	delivery := newTestDelivery(domain.DeliveryStatusRead)
+	repo.On("GetByProviderMessageID", ctx, "wamid.1").Return(delivery, nil)
+
+	require.NoError(t, svc.UpdateStatus(ctx, "wamid.1", domain.DeliveryStatusDelivered, nil, nil))
+	assert.Equal(t, domain.DeliveryStatusRead, delivery.Status)
+	repo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+}
+
+func TestDeliveryTrackingService_UpdateStatus_FailedTriggersFallback(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.MockNotificationDeliveryRepository)
+	fallback := &recordingFallback{}
+	svc := NewDeliveryTrackingService(repo, fallback, zap.NewNop())
+
+	delivery := newTestDelivery(domain.DeliveryStatusSent)
+	repo.On("GetByProviderMessageID", ctx, "wamid.1").Return(delivery, nil)
+	repo.On("UpdateStatus", ctx, delivery).Return(nil)
+
+	code := 131026
+	title := "Message undeliverable"
+	require.NoError(t, svc.UpdateStatus(ctx, "wamid.1", domain.DeliveryStatusFailed, &code, &title))
+
+	assert.Equal(t, domain.DeliveryStatusFailed, delivery.Status)
+	assert.Equal(t, &code, delivery.ErrorCode)
+	require.Len(t, fallback.notified, 1)
+	assert.Equal(t, delivery, fallback.notified[0])
+}
+
+func TestDeliveryTrackingService_UpdateStatus_FailedWithOtherCodeSkipsFallback(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.MockNotificationDeliveryRepository)
+	fallback := &recordingFallback{}
+	svc := NewDeliveryTrackingService(repo, fallback, zap.NewNop())
+
+	delivery := newTestDelivery(domain.DeliveryStatusSent)
+	repo.On("GetByProviderMessageID", ctx, "wamid.1").Return(delivery, nil)
+	repo.On("UpdateStatus", ctx, delivery).Return(nil)
+
+	code := 130429 // Rate limit
+	require.NoError(t, svc.UpdateStatus(ctx, "wamid.1", domain.DeliveryStatusFailed, &code, nil))
+
+	assert.Equal(t, domain.DeliveryStatusFailed, delivery.Status)
+	assert.Empty(t, fallback.notified)
+}
+
+func TestDeliveryTrackingService_UpdateStatus_RejectsUnknownStatus(t *testing.T) {
+	repo := new(mocks.MockNotificationDeliveryRepository)
+	svc := NewDeliveryTrackingService(repo, nil, zap.NewNop())
+
+	err := svc.UpdateStatus(context.Background(), "wamid.1", domain.DeliveryStatus("deleted"), nil, nil)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
+	"event-coming/internal/config"
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
 	"event-coming/internal/repository"
+	"event-coming/pkg/pagination"
 
 	"github.com/google/uuid"
 )
@@ -13,12 +18,25 @@ import (
 // EntityService handles entity business logic
 type EntityService struct {
 	entityRepo repository.EntityRepository
+	userRepo   repository.UserRepository
+	transactor repository.Transactor
+	audit      *AuditService
+	maxDepth   int
 }
 
 // NewEntityService creates a new entity service
-func NewEntityService(entityRepo repository.EntityRepository) *EntityService {
+func NewEntityService(entityRepo repository.EntityRepository, userRepo repository.UserRepository, transactor repository.Transactor, audit *AuditService, cfg *config.EntityConfig) *EntityService {
+	maxDepth := cfg.MaxHierarchyDepth
+	if maxDepth <= 0 {
+		maxDepth = 10
+	}
+
 	return &EntityService{
 		entityRepo: entityRepo,
+		userRepo:   userRepo,
+		transactor: transactor,
+		audit:      audit,
+		maxDepth:   maxDepth,
 	}
 }
 
@@ -35,6 +53,14 @@ func (s *EntityService) Create(ctx context.Context, req *dto.CreateEntityRequest
 		}
 	}
 
+	phoneRegion, err := normalizePhoneRegion(req.PhoneRegion)
+	if err != nil {
+		return nil, err
+	}
+	if phoneRegion != nil && *phoneRegion == "" {
+		phoneRegion = nil
+	}
+
 	// Validate parent if provided
 	if req.ParentID != nil {
 		parent, err := s.entityRepo.GetByID(ctx, *req.ParentID)
@@ -56,12 +82,24 @@ func (s *EntityService) Create(ctx context.Context, req *dto.CreateEntityRequest
 		Document:    req.Document,
 		Active:      true,
 		Metadata:    req.Metadata,
+
+		ConfirmationOffsetMinutes: req.ConfirmationOffsetMinutes,
+		ReminderOffsetMinutes:     req.ReminderOffsetMinutes,
+		LocationOffsetMinutes:     req.LocationOffsetMinutes,
+		QuietHoursStart:           req.QuietHoursStart,
+		QuietHoursEnd:             req.QuietHoursEnd,
+		EventTypes:                req.EventTypes,
+		ReminderDigest:            req.ReminderDigest != nil && *req.ReminderDigest,
+		AutoCompleteEvents:        req.AutoCompleteEvents != nil && *req.AutoCompleteEvents,
+		PhoneRegion:               phoneRegion,
 	}
 
 	if err := s.entityRepo.Create(ctx, entity); err != nil {
 		return nil, err
 	}
 
+	s.audit.Record(ctx, entity.ID, domain.AuditActionCreate, domain.AuditTargetEntity, entity.ID, nil, entity)
+
 	return dto.ToEntityResponse(entity), nil
 }
 
@@ -100,6 +138,11 @@ func (s *EntityService) Update(ctx context.Context, id uuid.UUID, req *dto.Updat
 		}
 	}
 
+	phoneRegion, err := normalizePhoneRegion(req.PhoneRegion)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate parent if provided
 	if req.ParentID != nil {
 		if *req.ParentID == id {
@@ -112,6 +155,11 @@ func (s *EntityService) Update(ctx context.Context, id uuid.UUID, req *dto.Updat
 		if parent == nil {
 			return nil, domain.ErrNotFound
 		}
+
+		// The new parent can't be a descendant of the entity (A -> B -> A)
+		if err := s.checkHierarchyCycle(ctx, id, parent); err != nil {
+			return nil, err
+		}
 	}
 
 	input := &domain.UpdateEntityInput{
@@ -123,6 +171,16 @@ func (s *EntityService) Update(ctx context.Context, id uuid.UUID, req *dto.Updat
 		Document:    req.Document,
 		IsActive:    req.IsActive,
 		Metadata:    req.Metadata,
+
+		ConfirmationOffsetMinutes: req.ConfirmationOffsetMinutes,
+		ReminderOffsetMinutes:     req.ReminderOffsetMinutes,
+		LocationOffsetMinutes:     req.LocationOffsetMinutes,
+		QuietHoursStart:           req.QuietHoursStart,
+		QuietHoursEnd:             req.QuietHoursEnd,
+		EventTypes:                req.EventTypes,
+		ReminderDigest:            req.ReminderDigest,
+		AutoCompleteEvents:        req.AutoCompleteEvents,
+		PhoneRegion:               phoneRegion,
 	}
 
 	if err := s.entityRepo.Update(ctx, id, input); err != nil {
@@ -135,9 +193,41 @@ func (s *EntityService) Update(ctx context.Context, id uuid.UUID, req *dto.Updat
 		return nil, err
 	}
 
+	s.audit.Record(ctx, id, domain.AuditActionUpdate, domain.AuditTargetEntity, id, existing, updated)
+
 	return dto.ToEntityResponse(updated), nil
 }
 
+// checkHierarchyCycle walks up the parent chain of newParent and returns
+// domain.ErrInvalidInput if it reaches the entity being updated
+func (s *EntityService) checkHierarchyCycle(ctx context.Context, id uuid.UUID, newParent *domain.Entity) error {
+	visited := map[uuid.UUID]bool{newParent.ID: true}
+
+	current := newParent
+	for current.ParentID != nil {
+		parentID := *current.ParentID
+		if parentID == id {
+			return domain.ErrInvalidInput
+		}
+		// Existing cycle in the data: stop walking instead of looping forever
+		if visited[parentID] {
+			return domain.ErrInvalidInput
+		}
+		visited[parentID] = true
+
+		next, err := s.entityRepo.GetByID(ctx, parentID)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		current = next
+	}
+
+	return nil
+}
+
 // Delete deletes an entity
 func (s *EntityService) Delete(ctx context.Context, id uuid.UUID) error {
 	existing, err := s.entityRepo.GetByID(ctx, id)
@@ -148,17 +238,17 @@ func (s *EntityService) Delete(ctx context.Context, id uuid.UUID) error {
 		return domain.ErrNotFound
 	}
 
-	return s.entityRepo.Delete(ctx, id)
+	if err := s.entityRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, id, domain.AuditActionDelete, domain.AuditTargetEntity, id, existing, nil)
+	return nil
 }
 
 // List lists entities with pagination
 func (s *EntityService) List(ctx context.Context, page, perPage int) ([]*dto.EntityResponse, int64, error) {
-	if page < 1 {
-		page = 1
-	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 20
-	}
+	page, perPage = pagination.Normalize(page, perPage)
 
 	entities, total, err := s.entityRepo.List(ctx, page, perPage)
 	if err != nil {
@@ -170,12 +260,7 @@ func (s *EntityService) List(ctx context.Context, page, perPage int) ([]*dto.Ent
 
 // ListByParent lists entities by parent ID
 func (s *EntityService) ListByParent(ctx context.Context, parentID uuid.UUID, page, perPage int) ([]*dto.EntityResponse, int64, error) {
-	if page < 1 {
-		page = 1
-	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 20
-	}
+	page, perPage = pagination.Normalize(page, perPage)
 
 	entities, total, err := s.entityRepo.ListByParent(ctx, parentID, page, perPage)
 	if err != nil {
@@ -185,6 +270,25 @@ func (s *EntityService) ListByParent(ctx context.Context, parentID uuid.UUID, pa
 	return dto.ToEntityResponseList(entities), total, nil
 }
 
+// ListDescendants returns the full subtree under an entity, with each entity's depth.
+// Traversal stops at the configured max hierarchy depth.
+func (s *EntityService) ListDescendants(ctx context.Context, rootID uuid.UUID) ([]*dto.EntityDescendantResponse, error) {
+	root, err := s.entityRepo.GetByID(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	descendants, err := s.entityRepo.ListDescendants(ctx, rootID, s.maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToEntityDescendantResponseList(descendants), nil
+}
+
 // GetByDocument retrieves an entity by document
 func (s *EntityService) GetByDocument(ctx context.Context, document string) (*dto.EntityResponse, error) {
 	entity, err := s.entityRepo.GetByDocument(ctx, document)
@@ -197,3 +301,139 @@ func (s *EntityService) GetByDocument(ctx context.Context, document string) (*dt
 
 	return dto.ToEntityResponse(entity), nil
 }
+
+// ==================== MEMBERS ====================
+
+// authorizeMembershipChange ensures the actor is an owner or admin of the entity.
+// Admins can't grant, change or remove the owner role.
+func (s *EntityService) authorizeMembershipChange(ctx context.Context, actorID, entID uuid.UUID, roles ...domain.UserRole) error {
+	actor, err := s.userRepo.GetEntityMembership(ctx, actorID, entID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrForbidden
+		}
+		return err
+	}
+
+	switch actor.Role {
+	case domain.UserRoleEntityOwner:
+		return nil
+	case domain.UserRoleEntityAdmin:
+		for _, role := range roles {
+			if role == domain.UserRoleEntityOwner {
+				return domain.ErrForbidden
+			}
+		}
+		return nil
+	default:
+		return domain.ErrForbidden
+	}
+}
+
+// withoutOwner runs change (a demotion or removal of an owner) in a transaction that
+// first locks the entity's owner rows, so concurrent changes can't both see a second
+// owner and leave the entity with none. Returns domain.ErrLastOwner if there is a single owner
+func (s *EntityService) withoutOwner(ctx context.Context, entID uuid.UUID, change func(ctx context.Context) error) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		owners, err := s.userRepo.LockEntityUsersByRole(ctx, entID, domain.UserRoleEntityOwner)
+		if err != nil {
+			return err
+		}
+		if owners <= 1 {
+			return domain.ErrLastOwner
+		}
+		return change(ctx)
+	})
+}
+
+// AddMember adds a user to an entity with the given role
+func (s *EntityService) AddMember(ctx context.Context, actorID, entID, userID uuid.UUID, role domain.UserRole) (*dto.MemberResponse, error) {
+	if err := s.authorizeMembershipChange(ctx, actorID, entID, role); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetEntityMembership(ctx, userID, entID); err == nil {
+		return nil, domain.ErrConflict
+	} else if err != domain.ErrNotFound {
+		return nil, err
+	}
+
+	membership := &domain.UserEntity{
+		UserID:   userID,
+		EntityID: entID,
+		Role:     role,
+	}
+	if err := s.userRepo.AddToEntity(ctx, membership); err != nil {
+		return nil, err
+	}
+
+	return dto.ToMemberResponse(membership), nil
+}
+
+// UpdateMemberRole changes a member's role. The last owner can't be demoted.
+func (s *EntityService) UpdateMemberRole(ctx context.Context, actorID, entID, userID uuid.UUID, role domain.UserRole) (*dto.MemberResponse, error) {
+	membership, err := s.userRepo.GetEntityMembership(ctx, userID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeMembershipChange(ctx, actorID, entID, membership.Role, role); err != nil {
+		return nil, err
+	}
+
+	if membership.Role == role {
+		return dto.ToMemberResponse(membership), nil
+	}
+
+	update := func(ctx context.Context) error {
+		return s.userRepo.UpdateEntityRole(ctx, userID, entID, role)
+	}
+	if membership.Role == domain.UserRoleEntityOwner {
+		err = s.withoutOwner(ctx, entID, update)
+	} else {
+		err = update(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	membership.Role = role
+	return dto.ToMemberResponse(membership), nil
+}
+
+// RemoveMember removes a user from an entity. The last owner can't be removed.
+func (s *EntityService) RemoveMember(ctx context.Context, actorID, entID, userID uuid.UUID) error {
+	membership, err := s.userRepo.GetEntityMembership(ctx, userID, entID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorizeMembershipChange(ctx, actorID, entID, membership.Role); err != nil {
+		return err
+	}
+
+	remove := func(ctx context.Context) error {
+		return s.userRepo.RemoveFromEntity(ctx, userID, entID)
+	}
+	if membership.Role == domain.UserRoleEntityOwner {
+		return s.withoutOwner(ctx, entID, remove)
+	}
+	return remove(ctx)
+}
+
+// normalizePhoneRegion upper-cases the entity's phone region and rejects regions
+// NormalizePhoneNumberForRegion doesn't know. Empty is kept, meaning the application default
+func normalizePhoneRegion(region *string) (*string, error) {
+	if region == nil {
+		return nil, nil
+	}
+	normalized := strings.ToUpper(strings.TrimSpace(*region))
+	if !domain.IsSupportedPhoneRegion(normalized) {
+		return nil, fmt.Errorf("%w: unsupported phone_region %q", domain.ErrInvalidInput, *region)
+	}
+	return &normalized, nil
+}
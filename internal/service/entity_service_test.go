@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type entityServiceDeps struct {
+	entityRepo *mocks.MockEntityRepository
+	userRepo   *mocks.MockUserRepository
+	transactor *recordingTransactor
+}
+
+func newTestEntityService() (*EntityService, *entityServiceDeps) {
+	deps := &entityServiceDeps{
+		entityRepo: new(mocks.MockEntityRepository),
+		userRepo:   new(mocks.MockUserRepository),
+		transactor: &recordingTransactor{},
+	}
+	return NewEntityService(deps.entityRepo, deps.userRepo, deps.transactor, nil, &config.EntityConfig{}), deps
+}
+
+// withMembership configura o papel de um usuário na entidade de teste
+func (d *entityServiceDeps) withMembership(userID uuid.UUID, role domain.UserRole) {
+	d.userRepo.On("GetEntityMembership", mock.Anything, userID, testutil.TestEntityID).
+		Return(&domain.UserEntity{UserID: userID, EntityID: testutil.TestEntityID, Role: role}, nil)
+}
+
+func TestEntityService_UpdateMemberRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		actorRole domain.UserRole
+		from      domain.UserRole
+		to        domain.UserRole
+		wantErr   error
+	}{
+		{name: "owner promotes viewer to admin", actorRole: domain.UserRoleEntityOwner, from: domain.UserRoleEntityViewer, to: domain.UserRoleEntityAdmin},
+		{name: "owner promotes admin to owner", actorRole: domain.UserRoleEntityOwner, from: domain.UserRoleEntityAdmin, to: domain.UserRoleEntityOwner},
+		{name: "admin demotes manager", actorRole: domain.UserRoleEntityAdmin, from: domain.UserRoleEntityManager, to: domain.UserRoleEntityViewer},
+		{name: "admin can't grant owner", actorRole: domain.UserRoleEntityAdmin, from: domain.UserRoleEntityManager, to: domain.UserRoleEntityOwner, wantErr: domain.ErrForbidden},
+		{name: "admin can't demote owner", actorRole: domain.UserRoleEntityAdmin, from: domain.UserRoleEntityOwner, to: domain.UserRoleEntityViewer, wantErr: domain.ErrForbidden},
+		{name: "manager can't change roles", actorRole: domain.UserRoleEntityManager, from: domain.UserRoleEntityViewer, to: domain.UserRoleEntityManager, wantErr: domain.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestEntityService()
+
+			actorID, userID := uuid.New(), uuid.New()
+			deps.withMembership(actorID, tt.actorRole)
+			deps.withMembership(userID, tt.from)
+			deps.userRepo.On("UpdateEntityRole", ctx, userID, testutil.TestEntityID, tt.to).Return(nil)
+
+			resp, err := svc.UpdateMemberRole(ctx, actorID, testutil.TestEntityID, userID, tt.to)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				deps.userRepo.AssertNotCalled(t, "UpdateEntityRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, resp.Role)
+			deps.userRepo.AssertCalled(t, "UpdateEntityRole", ctx, userID, testutil.TestEntityID, tt.to)
+		})
+	}
+}
+
+func TestEntityService_UpdateMemberRole_LastOwnerGuard(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEntityService()
+
+	ownerID := uuid.New()
+	deps.withMembership(ownerID, domain.UserRoleEntityOwner)
+	deps.userRepo.On("LockEntityUsersByRole", inTx, testutil.TestEntityID, domain.UserRoleEntityOwner).Return(int64(1), nil).Once()
+
+	_, err := svc.UpdateMemberRole(ctx, ownerID, testutil.TestEntityID, ownerID, domain.UserRoleEntityAdmin)
+	assert.ErrorIs(t, err, domain.ErrLastOwner)
+	deps.userRepo.AssertNotCalled(t, "UpdateEntityRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Com outro owner a troca é permitida
+	deps.userRepo.On("LockEntityUsersByRole", inTx, testutil.TestEntityID, domain.UserRoleEntityOwner).Return(int64(2), nil)
+	deps.userRepo.On("UpdateEntityRole", inTx, ownerID, testutil.TestEntityID, domain.UserRoleEntityAdmin).Return(nil)
+
+	_, err = svc.UpdateMemberRole(ctx, ownerID, testutil.TestEntityID, ownerID, domain.UserRoleEntityAdmin)
+	require.NoError(t, err)
+
+	// A contagem e a troca rodam na mesma transação
+	assert.Equal(t, 1, deps.transactor.rolledBack)
+	assert.Equal(t, 1, deps.transactor.committed)
+}
+
+func TestEntityService_RemoveMember_LastOwnerGuard(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEntityService()
+
+	ownerID := uuid.New()
+	deps.withMembership(ownerID, domain.UserRoleEntityOwner)
+	deps.userRepo.On("LockEntityUsersByRole", inTx, testutil.TestEntityID, domain.UserRoleEntityOwner).Return(int64(1), nil)
+
+	err := svc.RemoveMember(ctx, ownerID, testutil.TestEntityID, ownerID)
+	assert.ErrorIs(t, err, domain.ErrLastOwner)
+	deps.userRepo.AssertNotCalled(t, "RemoveFromEntity", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEntityService_RemoveMember(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEntityService()
+
+	actorID, userID := uuid.New(), uuid.New()
+	deps.withMembership(actorID, domain.UserRoleEntityAdmin)
+	deps.withMembership(userID, domain.UserRoleEntityViewer)
+	deps.userRepo.On("RemoveFromEntity", ctx, userID, testutil.TestEntityID).Return(nil)
+
+	require.NoError(t, svc.RemoveMember(ctx, actorID, testutil.TestEntityID, userID))
+	deps.userRepo.AssertNotCalled(t, "LockEntityUsersByRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEntityService_AddMember(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEntityService()
+
+	actorID := uuid.New()
+	user := testutil.NewTestUser()
+	deps.withMembership(actorID, domain.UserRoleEntityOwner)
+	deps.userRepo.On("GetByID", ctx, user.ID).Return(user, nil)
+	deps.userRepo.On("GetEntityMembership", mock.Anything, user.ID, testutil.TestEntityID).Return(nil, domain.ErrNotFound)
+	deps.userRepo.On("AddToEntity", ctx, mock.AnythingOfType("*domain.UserEntity")).Return(nil)
+
+	resp, err := svc.AddMember(ctx, actorID, testutil.TestEntityID, user.ID, domain.UserRoleEntityManager)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserRoleEntityManager, resp.Role)
+
+	// Um viewer não adiciona membros
+	viewerID := uuid.New()
+	deps.withMembership(viewerID, domain.UserRoleEntityViewer)
+	_, err = svc.AddMember(ctx, viewerID, testutil.TestEntityID, user.ID, domain.UserRoleEntityViewer)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+// newTestEntityChain cria entidades em que cada uma é filha da anterior (root -> ... -> leaf)
+func newTestEntityChain(deps *entityServiceDeps, n int) []*domain.Entity {
+	chain := make([]*domain.Entity, n)
+	for i := range chain {
+		entity := testutil.NewTestEntity()
+		entity.ID = uuid.New()
+		if i > 0 {
+			parentID := chain[i-1].ID
+			entity.ParentID = &parentID
+		}
+		chain[i] = entity
+		deps.entityRepo.On("GetByID", mock.Anything, entity.ID).Return(entity, nil)
+	}
+	return chain
+}
+
+func TestEntityService_Update_RejectsHierarchyCycle(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEntityService()
+
+	// A -> B -> C: tornar C o pai de A fecharia o ciclo
+	chain := newTestEntityChain(deps, 3)
+	root, leaf := chain[0], chain[2]
+
+	_, err := svc.Update(ctx, root.ID, &dto.UpdateEntityRequest{ParentID: &leaf.ID})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	deps.entityRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = svc.Update(ctx, root.ID, &dto.UpdateEntityRequest{ParentID: &root.ID})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestEntityService_Update_ValidReparent(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEntityService()
+
+	// A -> B -> C: C pode passar a ser filha direta de A
+	chain := newTestEntityChain(deps, 3)
+	root, leaf := chain[0], chain[2]
+	deps.entityRepo.On("Update", ctx, leaf.ID, mock.AnythingOfType("*domain.UpdateEntityInput")).Return(nil)
+
+	_, err := svc.Update(ctx, leaf.ID, &dto.UpdateEntityRequest{ParentID: &root.ID})
+	require.NoError(t, err)
+	deps.entityRepo.AssertCalled(t, "Update", ctx, leaf.ID, mock.AnythingOfType("*domain.UpdateEntityInput"))
+}
+
+func TestEntityService_ListDescendants_MultiLevelTree(t *testing.T) {
+	ctx := context.Background()
+	deps := &entityServiceDeps{
+		entityRepo: new(mocks.MockEntityRepository),
+		userRepo:   new(mocks.MockUserRepository),
+		transactor: &recordingTransactor{},
+	}
+	svc := NewEntityService(deps.entityRepo, deps.userRepo, deps.transactor, nil, &config.EntityConfig{MaxHierarchyDepth: 3})
+
+	// root -> child -> grandchild -> great-grandchild
+	chain := newTestEntityChain(deps, 4)
+	root := chain[0]
+	descendants := make([]*domain.EntityDescendant, 0, len(chain)-1)
+	for depth, entity := range chain[1:] {
+		descendants = append(descendants, &domain.EntityDescendant{Entity: *entity, Depth: depth + 1})
+	}
+	deps.entityRepo.On("ListDescendants", ctx, root.ID, 3).Return(descendants, nil)
+
+	resp, err := svc.ListDescendants(ctx, root.ID)
+	require.NoError(t, err)
+	require.Len(t, resp, 3)
+	for i, d := range resp {
+		assert.Equal(t, chain[i+1].ID, d.ID)
+		assert.Equal(t, i+1, d.Depth)
+	}
+}
+
+func TestEntityService_ListDescendants_DefaultMaxDepth(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEntityService()
+
+	root := newTestEntityChain(deps, 1)[0]
+	deps.entityRepo.On("ListDescendants", ctx, root.ID, 10).Return([]*domain.EntityDescendant{}, nil)
+
+	resp, err := svc.ListDescendants(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Empty(t, resp)
+}
+
+func TestEntityService_ListDescendants_RootNotFound(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEntityService()
+
+	rootID := uuid.New()
+	deps.entityRepo.On("GetByID", ctx, rootID).Return(nil, nil)
+
+	_, err := svc.ListDescendants(ctx, rootID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	deps.entityRepo.AssertNotCalled(t, "ListDescendants", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"event-coming/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+// ETAPublisher publica o ETA recalculado de um participante no canal do evento (WebSocket)
+type ETAPublisher interface {
+	PublishETAUpdate(ctx context.Context, entityID, eventID string, data *websocket.ETAUpdateData) error
+}
+
+// EventUpdatePublisher publica os totais de participantes por status no canal do evento (WebSocket)
+type EventUpdatePublisher interface {
+	PublishEventUpdate(ctx context.Context, entityID, eventID string, data *websocket.EventUpdateData) error
+}
+
+// etaDebounceIdle é quanto tempo sem atualizações até o último ETA de um participante ser esquecido
+const etaDebounceIdle = time.Hour
+
+// lastETA é o último ETA publicado de um participante
+type lastETA struct {
+	minutes int
+	at      time.Time
+}
+
+// etaDebouncer evita publicar um eta_update a cada ponto de GPS: só publica o primeiro ETA
+// do participante, ou quando ele muda pelo menos threshold minutos e já passou interval
+// desde a última publicação
+type etaDebouncer struct {
+	mu        sync.Mutex
+	last      map[uuid.UUID]lastETA
+	threshold int
+	interval  time.Duration
+}
+
+func newETADebouncer(threshold int, interval time.Duration) *etaDebouncer {
+	return &etaDebouncer{
+		last:      make(map[uuid.UUID]lastETA),
+		threshold: threshold,
+		interval:  interval,
+	}
+}
+
+// ShouldPublish reports whether the participant's new ETA must be published, and records it if so
+func (d *etaDebouncer) ShouldPublish(participantID uuid.UUID, minutes int, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	prev, ok := d.last[participantID]
+	if ok && now.Sub(prev.at) < etaDebounceIdle {
+		delta := minutes - prev.minutes
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta < d.threshold || now.Sub(prev.at) < d.interval {
+			return false
+		}
+	}
+
+	d.last[participantID] = lastETA{minutes: minutes, at: now}
+	d.prune(now)
+	return true
+}
+
+// prune remove os participantes sem publicação há mais de etaDebounceIdle
+func (d *etaDebouncer) prune(now time.Time) {
+	for id, prev := range d.last {
+		if now.Sub(prev.at) >= etaDebounceIdle {
+			delete(d.last, id)
+		}
+	}
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+	"event-coming/internal/websocket"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -15,16 +20,45 @@ import (
 
 // EventCacheService gerencia dados em cache do Redis
 type EventCacheService struct {
-	redisClient *redis.Client
+	redisClient     *redis.Client
+	eventRepo       repository.EventRepository
+	participantRepo repository.ParticipantRepository
+	cfg             *config.CacheConfig
 }
 
 // NewEventCacheService cria um novo serviço de cache de eventos
-func NewEventCacheService(redisClient *redis.Client) *EventCacheService {
+func NewEventCacheService(
+	redisClient *redis.Client,
+	eventRepo repository.EventRepository,
+	participantRepo repository.ParticipantRepository,
+	cfg *config.CacheConfig,
+) *EventCacheService {
 	return &EventCacheService{
-		redisClient: redisClient,
+		redisClient:     redisClient,
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+		cfg:             cfg,
 	}
 }
 
+// warmPageSize é o tamanho da página usada ao carregar participantes no Warm
+const warmPageSize = 500
+
+// ttlForEvent calcula o TTL de uma chave com base no fim do evento.
+// As chaves sobrevivem AfterEventTTL após o término (para consulta do evento
+// concluído) e nunca ficam abaixo de MinTTL. Sem data de fim, usa DefaultTTL.
+func (s *EventCacheService) ttlForEvent(eventEndTime *time.Time) time.Duration {
+	if eventEndTime == nil {
+		return s.cfg.DefaultTTL
+	}
+
+	ttl := time.Until(*eventEndTime) + s.cfg.AfterEventTTL
+	if ttl < s.cfg.MinTTL {
+		ttl = s.cfg.MinTTL
+	}
+	return ttl
+}
+
 // GetEventCacheData busca todas as informações em cache de um evento
 func (s *EventCacheService) GetEventCacheData(ctx context.Context, entID, eventID uuid.UUID) (*dto.EventCacheResponse, error) {
 	data := &dto.EventCacheResponse{
@@ -43,185 +77,387 @@ func (s *EventCacheService) GetEventCacheData(ctx context.Context, entID, eventI
 	data.Locations = locations
 	data.TotalLocations = len(locations)
 
-	// Buscar confirmações
-	confirmations, err := s.getConfirmations(ctx, entID, eventID)
+	// Buscar confirmações (aquecendo o cache frio)
+	confirmations, err := s.loadConfirmations(ctx, entID, eventID, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get confirmations: %w", err)
+		return nil, err
 	}
 	data.Confirmations = confirmations
 
 	// Contar status
+	counts := countStatuses(confirmations)
+	data.TotalConfirmed = counts.Confirmed
+	data.TotalPending = counts.Pending
+	data.TotalDenied = counts.Denied
+	data.TotalNoShow = counts.NoShow
+
+	return data, nil
+}
+
+// GetStatusCounts conta os participantes do evento (já carregado por quem chama) por status
+// a partir das confirmações em cache, sem listar os participantes no banco (exceto para
+// aquecer um cache frio). Com capacidade, as vagas ocupadas vêm da mesma contagem no banco
+// que a verificação de lotação usa, para Remaining nunca divergir de quem é recusado
+func (s *EventCacheService) GetStatusCounts(ctx context.Context, event *domain.Event) (*websocket.EventUpdateData, error) {
+	confirmations, err := s.loadConfirmations(ctx, event.EntityID, event.ID, event)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := countStatuses(confirmations)
+
+	if event.Capacity != nil {
+		byStatus, err := s.participantRepo.CountByEventStatus(ctx, event.ID, event.EntityID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count taken seats: %w", err)
+		}
+		taken := byStatus[domain.ParticipantStatusConfirmed] + byStatus[domain.ParticipantStatusCheckedIn]
+		remaining := max(*event.Capacity-taken, 0)
+		counts.Capacity = event.Capacity
+		counts.Remaining = &remaining
+	}
+
+	return counts, nil
+}
+
+// loadConfirmations lê as confirmações em cache do evento, aquecendo antes o cache que o
+// Warm ainda não carregou. Sem o marcador de Warm o cache pode ter só as confirmações
+// gravadas uma a uma pelo SetConfirmation, e contá-las daria totais parciais. event é
+// opcional: sem ele, o aquecimento carrega o evento
+func (s *EventCacheService) loadConfirmations(ctx context.Context, entID, eventID uuid.UUID, event *domain.Event) ([]dto.ParticipantConfirmationData, error) {
+	warmed, err := s.redisClient.Exists(ctx, warmedKey(entID, eventID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check warmed marker: %w", err)
+	}
+	if warmed == 0 {
+		if event == nil {
+			if event, err = s.eventRepo.GetByID(ctx, eventID, entID); err != nil {
+				return nil, fmt.Errorf("failed to warm cache: %w", err)
+			}
+		}
+		if err := s.warm(ctx, event); err != nil {
+			return nil, fmt.Errorf("failed to warm cache: %w", err)
+		}
+	}
+
+	confirmations, err := s.getConfirmations(ctx, entID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get confirmations: %w", err)
+	}
+	return confirmations, nil
+}
+
+// countStatuses totaliza as confirmações por status; confirmados incluem os que fizeram check-in
+func countStatuses(confirmations []dto.ParticipantConfirmationData) *websocket.EventUpdateData {
+	counts := &websocket.EventUpdateData{Total: len(confirmations)}
 	for _, c := range confirmations {
 		switch c.Status {
-		case domain.ParticipantStatusConfirmed, domain.ParticipantStatusCheckedIn:
-			data.TotalConfirmed++
+		case domain.ParticipantStatusConfirmed:
+			counts.Confirmed++
+		case domain.ParticipantStatusCheckedIn:
+			counts.Confirmed++
+			counts.CheckedIn++
 		case domain.ParticipantStatusPending:
-			data.TotalPending++
+			counts.Pending++
 		case domain.ParticipantStatusDenied:
-			data.TotalDenied++
+			counts.Denied++
+		case domain.ParticipantStatusNoShow:
+			counts.NoShow++
 		}
 	}
-
-	return data, nil
+	return counts
 }
 
 // getLocations busca todas as localizações de participantes de um evento
 func (s *EventCacheService) getLocations(ctx context.Context, entID, eventID uuid.UUID) ([]dto.ParticipantLocationData, error) {
-	// Pattern: location:latest:{eventID}:*
-	pattern := fmt.Sprintf("location:latest:%s:*", eventID)
+	values, err := s.getIndexedValues(ctx, cache.LocationIndexKey(eventID), locationKeyPrefix(eventID))
+	if err != nil {
+		return nil, err
+	}
 
 	var locations []dto.ParticipantLocationData
-	var cursor uint64
-
-	for {
-		keys, nextCursor, err := s.redisClient.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan keys: %w", err)
+	for _, str := range values {
+		var loc domain.Location
+		if err := json.Unmarshal([]byte(str), &loc); err != nil {
+			continue
 		}
 
-		if len(keys) > 0 {
-			// Buscar valores em batch
-			values, err := s.redisClient.MGet(ctx, keys...).Result()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get values: %w", err)
-			}
+		locations = append(locations, dto.ParticipantLocationData{
+			ParticipantID:   loc.ParticipantID,
+			ParticipantName: "", // Será preenchido se disponível
+			Latitude:        loc.Latitude,
+			Longitude:       loc.Longitude,
+			Accuracy:        loc.Accuracy,
+			Speed:           loc.Speed,
+			Heading:         loc.Heading,
+			UpdatedAt:       loc.Timestamp,
+		})
+	}
 
-			for _, val := range values {
-				if val == nil {
-					continue
-				}
+	return locations, nil
+}
 
-				str, ok := val.(string)
-				if !ok {
-					continue
-				}
+// getConfirmations busca todas as confirmações de participantes de um evento
+func (s *EventCacheService) getConfirmations(ctx context.Context, entID, eventID uuid.UUID) ([]dto.ParticipantConfirmationData, error) {
+	values, err := s.getIndexedValues(ctx, confirmationIndexKey(entID, eventID), confirmationKeyPrefix(entID, eventID))
+	if err != nil {
+		return nil, err
+	}
 
-				var loc domain.Location
-				if err := json.Unmarshal([]byte(str), &loc); err != nil {
-					continue
-				}
+	var confirmations []dto.ParticipantConfirmationData
+	for _, str := range values {
+		var conf dto.ParticipantConfirmationData
+		if err := json.Unmarshal([]byte(str), &conf); err != nil {
+			continue
+		}
+		confirmations = append(confirmations, conf)
+	}
 
-				locations = append(locations, dto.ParticipantLocationData{
-					ParticipantID:   loc.ParticipantID,
-					ParticipantName: "", // Será preenchido se disponível
-					Latitude:        loc.Latitude,
-					Longitude:       loc.Longitude,
-					Accuracy:        loc.Accuracy,
-					Speed:           loc.Speed,
-					Heading:         loc.Heading,
-					UpdatedAt:       loc.Timestamp,
-				})
-			}
+	return confirmations, nil
+}
+
+// getIndexedValues lê os valores das chaves {prefix}{participantID} a partir do
+// Set de índice do evento. Membros cujas chaves expiraram são removidos do índice.
+// Se o índice ainda não existe (dados anteriores ao índice), ele é populado via SCAN
+// uma única vez por evento (veja rebuildIndex).
+func (s *EventCacheService) getIndexedValues(ctx context.Context, indexKey, prefix string) ([]string, error) {
+	members, err := s.redisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if len(members) == 0 {
+		members, err = s.rebuildIndex(ctx, indexKey, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if len(members) == 0 {
+			return nil, nil
 		}
+	}
 
-		cursor = nextCursor
-		if cursor == 0 {
-			break
+	keys := make([]string, len(members))
+	for i, m := range members {
+		keys[i] = prefix + m
+	}
+
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get values: %w", err)
+	}
+
+	var result []string
+	var stale []interface{}
+	for i, val := range values {
+		str, ok := val.(string)
+		if !ok {
+			stale = append(stale, members[i])
+			continue
 		}
+		result = append(result, str)
 	}
 
-	return locations, nil
+	if len(stale) > 0 {
+		s.redisClient.SRem(ctx, indexKey, stale...)
+	}
+
+	return result, nil
 }
 
-// getConfirmations busca todas as confirmações de participantes de um evento
-func (s *EventCacheService) getConfirmations(ctx context.Context, entID, eventID uuid.UUID) ([]dto.ParticipantConfirmationData, error) {
-	// Pattern: confirmation:{entID}:{eventID}:*
-	pattern := fmt.Sprintf("confirmation:%s:%s:*", entID, eventID)
+// rebuildIndex popula o índice a partir das chaves existentes (migração lazy). Roda uma
+// vez por índice: o marcador {indexKey}:built é gravado antes do SCAN, então um índice
+// vazio com o marcador é um evento sem dados em cache e não varre o keyspace de novo.
+// O marcador também impede que leituras concorrentes façam o mesmo SCAN
+func (s *EventCacheService) rebuildIndex(ctx context.Context, indexKey, prefix string) ([]string, error) {
+	builtKey := indexBuiltKey(indexKey)
+	first, err := s.redisClient.SetNX(ctx, builtKey, 1, s.cfg.DefaultTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark index as built: %w", err)
+	}
+	if !first {
+		return nil, nil
+	}
 
-	var confirmations []dto.ParticipantConfirmationData
+	var members []string
 	var cursor uint64
 
 	for {
-		keys, nextCursor, err := s.redisClient.Scan(ctx, cursor, pattern, 100).Result()
+		keys, nextCursor, err := s.redisClient.Scan(ctx, cursor, prefix+"*", 100).Result()
 		if err != nil {
+			// Libera o marcador para a próxima leitura tentar de novo
+			s.redisClient.Del(ctx, builtKey)
 			return nil, fmt.Errorf("failed to scan keys: %w", err)
 		}
 
-		if len(keys) > 0 {
-			values, err := s.redisClient.MGet(ctx, keys...).Result()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get values: %w", err)
-			}
+		for _, key := range keys {
+			members = append(members, strings.TrimPrefix(key, prefix))
+		}
 
-			for _, val := range values {
-				if val == nil {
-					continue
-				}
+		cursor = nextCursor
+		if cursor == 0 {
+			break
+		}
+	}
 
-				str, ok := val.(string)
-				if !ok {
-					continue
-				}
+	if len(members) > 0 {
+		if err := s.addToIndex(ctx, indexKey, s.cfg.DefaultTTL, members...); err != nil {
+			s.redisClient.Del(ctx, builtKey)
+			return nil, err
+		}
+	}
 
-				var conf dto.ParticipantConfirmationData
-				if err := json.Unmarshal([]byte(str), &conf); err != nil {
-					continue
-				}
+	return members, nil
+}
 
-				confirmations = append(confirmations, conf)
+// indexBuiltKey retorna a chave do marcador de migração do índice (veja rebuildIndex)
+func indexBuiltKey(indexKey string) string {
+	return indexKey + ":built"
+}
+
+// addToIndex adiciona participantes ao índice, apenas estendendo o TTL
+func (s *EventCacheService) addToIndex(ctx context.Context, indexKey string, ttl time.Duration, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+
+	pipe := s.redisClient.Pipeline()
+	pipe.SAdd(ctx, indexKey, args...)
+	pipe.ExpireNX(ctx, indexKey, ttl)
+	pipe.ExpireGT(ctx, indexKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update index: %w", err)
+	}
+
+	return nil
+}
+
+// Warm carrega os participantes do evento do banco e popula as chaves de confirmação.
+// Ao terminar grava o marcador {index}:warmed, com o mesmo TTL das chaves; enquanto ele
+// existir as leituras confiam no cache. Usado na ativação do evento e quando uma leitura
+// não encontra o marcador.
+func (s *EventCacheService) Warm(ctx context.Context, entID, eventID uuid.UUID) error {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return err
+	}
+	return s.warm(ctx, event)
+}
+
+// warm é o Warm com o evento já carregado
+func (s *EventCacheService) warm(ctx context.Context, event *domain.Event) error {
+	entID, eventID := event.EntityID, event.ID
+	ttl := s.ttlForEvent(event.EndTime)
+	for page := 1; ; page++ {
+		participants, total, err := s.participantRepo.ListByEvent(ctx, eventID, entID, page, warmPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to list participants: %w", err)
+		}
+
+		if len(participants) > 0 {
+			members := make([]string, len(participants))
+			pipe := s.redisClient.Pipeline()
+			for i, p := range participants {
+				jsonData, err := json.Marshal(newConfirmationData(p))
+				if err != nil {
+					return fmt.Errorf("failed to marshal confirmation: %w", err)
+				}
+				pipe.Set(ctx, confirmationKey(entID, eventID, p.ID), jsonData, ttl)
+				members[i] = p.ID.String()
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("failed to warm confirmations: %w", err)
+			}
+			if err := s.addToIndex(ctx, confirmationIndexKey(entID, eventID), ttl, members...); err != nil {
+				return err
 			}
 		}
 
-		cursor = nextCursor
-		if cursor == 0 {
+		if int64(page*warmPageSize) >= total || len(participants) == 0 {
 			break
 		}
 	}
 
-	return confirmations, nil
+	if err := s.redisClient.Set(ctx, warmedKey(entID, eventID), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to mark cache as warmed: %w", err)
+	}
+	return nil
 }
 
-// SetConfirmation salva uma confirmação no cache
-func (s *EventCacheService) SetConfirmation(ctx context.Context, entID, eventID uuid.UUID, participant *domain.Participant) error {
-	key := fmt.Sprintf("confirmation:%s:%s:%s", entID, eventID, participant.ID)
-
-	data := dto.ParticipantConfirmationData{
-		ParticipantID: participant.ID,
-		Status:        participant.Status,
-		ConfirmedAt:   participant.ConfirmedAt,
-		CheckedInAt:   participant.CheckedInAt,
-		UpdatedAt:     time.Now(),
-	}
+// SetConfirmation salva uma confirmação no cache.
+// O TTL é derivado de eventEndTime (veja ttlForEvent).
+func (s *EventCacheService) SetConfirmation(ctx context.Context, entID, eventID uuid.UUID, participant *domain.Participant, eventEndTime *time.Time) error {
+	key := confirmationKey(entID, eventID, participant.ID)
 
-	jsonData, err := json.Marshal(data)
+	jsonData, err := json.Marshal(newConfirmationData(participant))
 	if err != nil {
 		return fmt.Errorf("failed to marshal confirmation: %w", err)
 	}
 
-	// TTL de 24 horas
-	if err := s.redisClient.Set(ctx, key, jsonData, 24*time.Hour).Err(); err != nil {
+	ttl := s.ttlForEvent(eventEndTime)
+	pipe := s.redisClient.Pipeline()
+	pipe.Set(ctx, key, jsonData, ttl)
+	// O marcador de Warm não pode sobreviver a uma chave gravada com TTL menor (fim do
+	// evento antecipado), senão o cache seria confiado com a confirmação já expirada
+	pipe.ExpireLT(ctx, warmedKey(entID, eventID), ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to set confirmation: %w", err)
 	}
 
-	return nil
+	return s.addToIndex(ctx, confirmationIndexKey(entID, eventID), ttl, participant.ID.String())
 }
 
-// DeleteConfirmation remove uma confirmação do cache
-func (s *EventCacheService) DeleteConfirmation(ctx context.Context, entID, eventID, participantID uuid.UUID) error {
-	key := fmt.Sprintf("confirmation:%s:%s:%s", entID, eventID, participantID)
-	return s.redisClient.Del(ctx, key).Err()
+// confirmationKey retorna a chave de confirmação de um participante
+func confirmationKey(entID, eventID, participantID uuid.UUID) string {
+	return confirmationKeyPrefix(entID, eventID) + participantID.String()
 }
 
-// GetLocationsSummary retorna um resumo rápido das localizações
-func (s *EventCacheService) GetLocationsSummary(ctx context.Context, eventID uuid.UUID) (int, error) {
-	pattern := fmt.Sprintf("location:latest:%s:*", eventID)
+// confirmationKeyPrefix retorna o prefixo das chaves de confirmação de um evento
+func confirmationKeyPrefix(entID, eventID uuid.UUID) string {
+	return fmt.Sprintf("confirmation:%s:%s:", entID, eventID)
+}
 
-	var count int
-	var cursor uint64
+// confirmationIndexKey retorna a chave do Set com os participantes que têm confirmação em cache
+func confirmationIndexKey(entID, eventID uuid.UUID) string {
+	return fmt.Sprintf("confirmation:index:%s:%s", entID, eventID)
+}
 
-	for {
-		keys, nextCursor, err := s.redisClient.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			return 0, err
-		}
+// warmedKey retorna a chave do marcador gravado pelo Warm (veja loadConfirmations)
+func warmedKey(entID, eventID uuid.UUID) string {
+	return confirmationIndexKey(entID, eventID) + ":warmed"
+}
 
-		count += len(keys)
-		cursor = nextCursor
+// locationKeyPrefix retorna o prefixo das chaves de última localização de um evento
+func locationKeyPrefix(eventID uuid.UUID) string {
+	return fmt.Sprintf("location:latest:%s:", eventID)
+}
 
-		if cursor == 0 {
-			break
-		}
+// newConfirmationData monta os dados de confirmação em cache de um participante
+func newConfirmationData(participant *domain.Participant) dto.ParticipantConfirmationData {
+	return dto.ParticipantConfirmationData{
+		ParticipantID: participant.ID,
+		Status:        participant.Status,
+		ConfirmedAt:   participant.ConfirmedAt,
+		CheckedInAt:   participant.CheckedInAt,
+		UpdatedAt:     time.Now(),
+	}
+}
+
+// DeleteConfirmation remove uma confirmação do cache
+func (s *EventCacheService) DeleteConfirmation(ctx context.Context, entID, eventID, participantID uuid.UUID) error {
+	pipe := s.redisClient.Pipeline()
+	pipe.Del(ctx, confirmationKey(entID, eventID, participantID))
+	pipe.SRem(ctx, confirmationIndexKey(entID, eventID), participantID.String())
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetLocationsSummary retorna um resumo rápido das localizações
+func (s *EventCacheService) GetLocationsSummary(ctx context.Context, eventID uuid.UUID) (int, error) {
+	values, err := s.getIndexedValues(ctx, cache.LocationIndexKey(eventID), locationKeyPrefix(eventID))
+	if err != nil {
+		return 0, err
 	}
 
-	return count, nil
+	return len(values), nil
 }
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventCacheServiceDeps struct {
+	eventRepo       *mocks.MockEventRepository
+	participantRepo *mocks.MockParticipantRepository
+	redis           *miniredis.Miniredis
+	client          *redis.Client
+}
+
+func newTestEventCacheService(t *testing.T) (*EventCacheService, *eventCacheServiceDeps) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.CacheConfig{
+		DefaultTTL:    24 * time.Hour,
+		MinTTL:        time.Hour,
+		AfterEventTTL: 2 * time.Hour,
+	}
+	deps := &eventCacheServiceDeps{
+		eventRepo:       new(mocks.MockEventRepository),
+		participantRepo: new(mocks.MockParticipantRepository),
+		redis:           mr,
+		client:          client,
+	}
+	return NewEventCacheService(client, deps.eventRepo, deps.participantRepo, cfg), deps
+}
+
+// markWarmed grava o marcador de Warm, para ler só o que o teste gravou no cache
+func markWarmed(t *testing.T, deps *eventCacheServiceDeps, entID, eventID uuid.UUID) {
+	t.Helper()
+	require.NoError(t, deps.redis.Set(warmedKey(entID, eventID), "1"))
+}
+
+func TestEventCacheService_SetConfirmation_TTLFromEventEnd(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+	participant := testutil.NewTestParticipant()
+
+	endTime := time.Now().Add(10 * time.Hour)
+	require.NoError(t, svc.SetConfirmation(ctx, testutil.TestEntityID, testutil.TestEventID, participant, &endTime))
+
+	ttl := deps.redis.TTL(confirmationKey(testutil.TestEntityID, testutil.TestEventID, participant.ID))
+	assert.InDelta(t, (12 * time.Hour).Seconds(), ttl.Seconds(), 5)
+}
+
+func TestEventCacheService_SetConfirmation_SoonEndingEventUsesMinTTL(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+	participant := testutil.NewTestParticipant()
+
+	// O evento já terminou: o TTL fica no mínimo configurado
+	endTime := time.Now().Add(-5 * time.Hour)
+	require.NoError(t, svc.SetConfirmation(ctx, testutil.TestEntityID, testutil.TestEventID, participant, &endTime))
+
+	assert.Equal(t, time.Hour, deps.redis.TTL(confirmationKey(testutil.TestEntityID, testutil.TestEventID, participant.ID)))
+}
+
+func TestEventCacheService_SetConfirmation_DefaultTTLWithoutEndTime(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+	participant := testutil.NewTestParticipant()
+
+	require.NoError(t, svc.SetConfirmation(ctx, testutil.TestEntityID, testutil.TestEventID, participant, nil))
+
+	assert.Equal(t, 24*time.Hour, deps.redis.TTL(confirmationKey(testutil.TestEntityID, testutil.TestEventID, participant.ID)))
+}
+
+func TestEventCacheService_GetEventCacheData_AfterEventEndsUntilExpiry(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusConfirmed
+
+	event := testutil.NewTestEvent()
+	endTime := time.Now().Add(-time.Minute)
+	event.EndTime = &endTime
+	deps.eventRepo.On("GetByID", ctx, testutil.TestEventID, testutil.TestEntityID).Return(event, nil)
+	deps.participantRepo.On("ListByEvent", ctx, testutil.TestEventID, testutil.TestEntityID, 1, warmPageSize).
+		Return([]*domain.Participant{participant}, int64(1), nil).Once()
+
+	data, err := svc.GetEventCacheData(ctx, testutil.TestEntityID, testutil.TestEventID)
+	require.NoError(t, err)
+	require.Len(t, data.Confirmations, 1)
+	assert.Equal(t, participant.ID, data.Confirmations[0].ParticipantID)
+	assert.Equal(t, 1, data.TotalConfirmed)
+
+	// Depois do TTL o cache (marcador incluído) está frio e é aquecido a partir do banco
+	deps.redis.FastForward(2 * time.Hour)
+	deps.participantRepo.On("ListByEvent", ctx, testutil.TestEventID, testutil.TestEntityID, 1, warmPageSize).
+		Return([]*domain.Participant{}, int64(0), nil).Once()
+
+	data, err = svc.GetEventCacheData(ctx, testutil.TestEntityID, testutil.TestEventID)
+	require.NoError(t, err)
+	assert.Empty(t, data.Confirmations)
+}
+
+func TestEventCacheService_Warm_SeedsConfirmationsFromDatabase(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+
+	event := testutil.NewTestEvent()
+	statuses := []domain.ParticipantStatus{
+		domain.ParticipantStatusConfirmed,
+		domain.ParticipantStatusCheckedIn,
+		domain.ParticipantStatusPending,
+		domain.ParticipantStatusPending,
+		domain.ParticipantStatusDenied,
+	}
+	participants := newTestEventParticipants(len(statuses))
+	for i, status := range statuses {
+		participants[i].Status = status
+	}
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListByEvent", ctx, event.ID, event.EntityID, 1, warmPageSize).
+		Return(participants, int64(len(participants)), nil)
+
+	require.NoError(t, svc.Warm(ctx, event.EntityID, event.ID))
+
+	data, err := svc.GetEventCacheData(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Len(t, data.Confirmations, 5)
+	assert.Equal(t, 2, data.TotalConfirmed)
+	assert.Equal(t, 2, data.TotalPending)
+	assert.Equal(t, 1, data.TotalDenied)
+
+	// O cache já está quente, então a leitura não volta ao banco
+	deps.participantRepo.AssertNumberOfCalls(t, "ListByEvent", 1)
+}
+
+func TestEventCacheService_GetEventCacheData_WarmsColdCache(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(2)
+	participants[0].Status = domain.ParticipantStatusConfirmed
+	participants[1].Status = domain.ParticipantStatusDenied
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListByEvent", ctx, event.ID, event.EntityID, 1, warmPageSize).
+		Return(participants, int64(len(participants)), nil)
+
+	data, err := svc.GetEventCacheData(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Len(t, data.Confirmations, 2)
+	assert.Equal(t, 1, data.TotalConfirmed)
+	assert.Equal(t, 1, data.TotalDenied)
+}
+
+func TestEventCacheService_GetStatusCounts_WarmsColdCacheWithSingleKey(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(3)
+	participants[0].Status = domain.ParticipantStatusConfirmed
+	participants[1].Status = domain.ParticipantStatusConfirmed
+
+	// Uma mudança de status grava só a sua chave num cache que nunca foi aquecido
+	require.NoError(t, svc.SetConfirmation(ctx, event.EntityID, event.ID, participants[0], event.EndTime))
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListByEvent", ctx, event.ID, event.EntityID, 1, warmPageSize).
+		Return(participants, int64(len(participants)), nil)
+
+	counts, err := svc.GetStatusCounts(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, 3, counts.Total)
+	assert.Equal(t, 2, counts.Confirmed)
+	assert.Equal(t, 1, counts.Pending)
+	assert.True(t, deps.redis.Exists(warmedKey(event.EntityID, event.ID)))
+
+	// Com o marcador gravado a próxima leitura não volta ao banco
+	_, err = svc.GetStatusCounts(ctx, event)
+	require.NoError(t, err)
+	deps.participantRepo.AssertNumberOfCalls(t, "ListByEvent", 1)
+}
+
+func TestEventCacheService_Warm_EventWithoutParticipantsIsNotWarmedAgain(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+
+	event := testutil.NewTestEvent()
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListByEvent", ctx, event.ID, event.EntityID, 1, warmPageSize).
+		Return([]*domain.Participant{}, int64(0), nil)
+
+	for i := 0; i < 2; i++ {
+		data, err := svc.GetEventCacheData(ctx, event.EntityID, event.ID)
+		require.NoError(t, err)
+		assert.Empty(t, data.Confirmations)
+	}
+	deps.participantRepo.AssertNumberOfCalls(t, "ListByEvent", 1)
+}
+
+func TestEventCacheService_Warm_PagesThroughParticipants(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+
+	event := testutil.NewTestEvent()
+	firstPage := newTestEventParticipants(warmPageSize)
+	secondPage := newTestEventParticipants(3)
+	total := int64(len(firstPage) + len(secondPage))
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListByEvent", ctx, event.ID, event.EntityID, 1, warmPageSize).Return(firstPage, total, nil)
+	deps.participantRepo.On("ListByEvent", ctx, event.ID, event.EntityID, 2, warmPageSize).Return(secondPage, total, nil)
+
+	require.NoError(t, svc.Warm(ctx, event.EntityID, event.ID))
+
+	data, err := svc.GetEventCacheData(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Len(t, data.Confirmations, int(total))
+}
+
+// scanParticipantIDs lista os participantes com chave {prefix}{participantID}, como a
+// leitura por SCAN fazia antes do índice
+func scanParticipantIDs(mr *miniredis.Miniredis, prefix string) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, key := range mr.Keys() {
+		if id, err := uuid.Parse(strings.TrimPrefix(key, prefix)); err == nil && strings.HasPrefix(key, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestEventCacheService_GetEventCacheData_IndexMatchesScan(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+	buffer := cache.NewLocationBuffer(deps.client)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(4)
+	for i, p := range participants {
+		require.NoError(t, svc.SetConfirmation(ctx, event.EntityID, event.ID, p, event.EndTime))
+		if i%2 == 0 {
+			loc := newTestParticipantLocation(p.ID, -23.55, -46.63)
+			require.NoError(t, buffer.SetLatestLocation(ctx, loc, time.Now().Add(time.Hour)))
+		}
+	}
+
+	// Chaves de outro evento não entram no resultado
+	other := testutil.NewTestParticipant()
+	other.ID = uuid.New()
+	require.NoError(t, svc.SetConfirmation(ctx, event.EntityID, uuid.New(), other, event.EndTime))
+	markWarmed(t, deps, event.EntityID, event.ID)
+
+	data, err := svc.GetEventCacheData(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+
+	var confirmed, located []uuid.UUID
+	for _, c := range data.Confirmations {
+		confirmed = append(confirmed, c.ParticipantID)
+	}
+	for _, l := range data.Locations {
+		located = append(located, l.ParticipantID)
+	}
+	assert.ElementsMatch(t, scanParticipantIDs(deps.redis, confirmationKeyPrefix(event.EntityID, event.ID)), confirmed)
+	assert.ElementsMatch(t, scanParticipantIDs(deps.redis, locationKeyPrefix(event.ID)), located)
+	assert.Len(t, confirmed, 4)
+	assert.Len(t, located, 2)
+
+	count, err := svc.GetLocationsSummary(ctx, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestEventCacheService_GetEventCacheData_RebuildsIndexForLegacyKeys(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+
+	// Confirmações gravadas antes do índice existir
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(3)
+	for _, p := range participants {
+		require.NoError(t, svc.SetConfirmation(ctx, event.EntityID, event.ID, p, event.EndTime))
+	}
+	indexKey := confirmationIndexKey(event.EntityID, event.ID)
+	deps.redis.Del(indexKey)
+	markWarmed(t, deps, event.EntityID, event.ID)
+
+	data, err := svc.GetEventCacheData(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Len(t, data.Confirmations, 3)
+
+	members, err := deps.redis.SMembers(indexKey)
+	require.NoError(t, err)
+	assert.Len(t, members, 3)
+	assert.True(t, deps.redis.Exists(indexBuiltKey(indexKey)))
+}
+
+func TestEventCacheService_GetLocationsSummary_DropsExpiredIndexMembers(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventCacheService(t)
+	buffer := cache.NewLocationBuffer(deps.client)
+
+	participants := newTestEventParticipants(2)
+	for _, p := range participants {
+		loc := newTestParticipantLocation(p.ID, -23.55, -46.63)
+		require.NoError(t, buffer.SetLatestLocation(ctx, loc, time.Now().Add(time.Hour)))
+	}
+	deps.redis.Del(locationKeyPrefix(testutil.TestEventID) + participants[0].ID.String())
+
+	count, err := svc.GetLocationsSummary(ctx, testutil.TestEventID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	members, err := deps.redis.SMembers(cache.LocationIndexKey(testutil.TestEventID))
+	require.NoError(t, err)
+	assert.Equal(t, []string{participants[1].ID.String()}, members)
+}
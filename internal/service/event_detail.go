@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/pkg/pagination"
+
+	"github.com/google/uuid"
+)
+
+// GetDetail monta numa chamada o que o app carrega ao abrir um evento: o evento, a
+// primeira página de participantes, os números por status e quantos participantes têm
+// uma localização recente (não stale)
+func (s *EventService) GetDetail(ctx context.Context, entID, eventID uuid.UUID) (*dto.EventDetailResponse, error) {
+	event, err := s.GetByID(ctx, entID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	page, perPage := pagination.Normalize(1, 0)
+	participants, total, err := s.participants.ListByEvent(ctx, entID, eventID, nil, nil, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := s.participantRepo.ListAllByEvent(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	live, _, err := s.locations.GetLiveEventLocations(ctx, entID, eventID, false, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get live locations: %w", err)
+	}
+
+	return &dto.EventDetailResponse{
+		Event:             event,
+		Participants:      participants,
+		ParticipantsTotal: total,
+		Stats:             domain.NewEventStats(all),
+		LiveLocations:     liveLocationCount(live),
+	}, nil
+}
+
+// liveLocationCount conta os participantes do snapshot com uma localização que ainda não
+// ficou stale
+func liveLocationCount(snapshot *dto.LiveEventLocationsResponse) int {
+	count := 0
+	for _, p := range snapshot.Participants {
+		if p.Location == nil || (p.Location.IsStale != nil && *p.Location.IsStale) {
+			continue
+		}
+		count++
+	}
+	return count
+}
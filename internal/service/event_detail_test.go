@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestDetailEventService liga ao EventService os serviços de participantes e de
+// localização usados por GetDetail, sobre os mesmos mocks
+func newTestDetailEventService() (*EventService, *eventServiceDeps) {
+	svc, deps := newTestEventService()
+	svc.participants = NewParticipantService(deps.participantRepo, deps.eventRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	svc.locations = NewLocationService(deps.locationRepo, deps.participantRepo, deps.eventRepo, nil, nil, &config.LocationConfig{StaleAfter: 5 * time.Minute}, zap.NewNop())
+	return svc, deps
+}
+
+func newTestDetailParticipant(event *domain.Event, status domain.ParticipantStatus) *domain.Participant {
+	p := testutil.NewTestParticipant()
+	p.ID = uuid.New()
+	p.EventID = event.ID
+	p.EntityID = event.EntityID
+	p.Status = status
+	return p
+}
+
+func TestEventService_GetDetail_BundlesEventParticipantsStatsAndLocations(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestDetailEventService()
+	event := testutil.NewTestEvent()
+
+	confirmed := newTestDetailParticipant(event, domain.ParticipantStatusConfirmed)
+	checkedIn := newTestDetailParticipant(event, domain.ParticipantStatusCheckedIn)
+	pending := newTestDetailParticipant(event, domain.ParticipantStatusPending)
+	all := []*domain.Participant{confirmed, checkedIn, pending}
+
+	// Uma localização recente e uma que já passou do limite de stale
+	fresh := testutil.NewTestLocation()
+	fresh.ParticipantID = confirmed.ID
+	fresh.Timestamp = time.Now().Add(-time.Minute)
+	stale := testutil.NewTestLocation()
+	stale.ID = uuid.New()
+	stale.ParticipantID = checkedIn.ID
+	stale.Timestamp = time.Now().Add(-time.Hour)
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListByEvent", ctx, event.ID, event.EntityID, 1, mock.Anything).Return(all[:2], int64(3), nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(all, nil)
+	deps.locationRepo.On("GetLatestByEvent", ctx, event.ID, event.EntityID).Return([]*domain.Location{fresh, stale}, nil)
+
+	detail, err := svc.GetDetail(ctx, event.EntityID, event.ID)
+
+	require.NoError(t, err)
+	require.NotNil(t, detail.Event)
+	assert.Equal(t, event.ID, detail.Event.ID)
+	require.Len(t, detail.Participants, 2)
+	assert.Equal(t, confirmed.ID, detail.Participants[0].ID)
+	assert.Equal(t, int64(3), detail.ParticipantsTotal)
+	assert.Equal(t, domain.EventStats{Total: 3, Pending: 1, Confirmed: 1, CheckedIn: 1}, detail.Stats)
+	// Só a localização recente conta como ao vivo
+	assert.Equal(t, 1, detail.LiveLocations)
+	deps.participantRepo.AssertExpectations(t)
+	deps.locationRepo.AssertExpectations(t)
+}
+
+func TestEventService_GetDetail_EventNotFound(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestDetailEventService()
+	entID, eventID := uuid.New(), uuid.New()
+
+	deps.eventRepo.On("GetByID", ctx, eventID, entID).Return(nil, domain.ErrNotFound)
+
+	detail, err := svc.GetDetail(ctx, entID, eventID)
+
+	assert.Nil(t, detail)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+	deps.participantRepo.AssertNotCalled(t, "ListAllByEvent", mock.Anything, mock.Anything, mock.Anything)
+}
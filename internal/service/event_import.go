@@ -0,0 +1,481 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Limites dos campos do evento (os mesmos validados em CreateEventRequest)
+const (
+	importNameMaxLen        = 200
+	importDescriptionMaxLen = 1000
+	importAddressMaxLen     = 500
+	importRRuleMaxLen       = 500
+	importDefaultName       = "Imported event"
+)
+
+// ImportICS cria um evento em rascunho para cada VEVENT de um VCALENDAR (RFC 5545). A
+// RRULE vira o rrule_string e o TZID do DTSTART (ou o X-WR-TIMEZONE do calendário) o
+// fuso do evento; um fuso que não é IANA é trocado pelo do calendário ou pelo padrão, e o
+// evento aparece em adjusted. VEVENTs cujo UID já foi importado pela entidade são
+// pulados, assim como as exceções de uma ocorrência (RECURRENCE-ID). Calendários com mais
+// VEVENTs que o limite retornam ErrBatchTooLarge. Os eventos são criados juntos ou nenhum
+// é persistido
+func (s *EventService) ImportICS(ctx context.Context, entID, userID uuid.UUID, r io.Reader) (*dto.EventImportResponse, error) {
+	calendar, err := parseICS(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if limit := s.eventConfig.ImportMaxEvents; limit > 0 && len(calendar.events) > limit {
+		return nil, fmt.Errorf("%d events, limit is %d: %w", len(calendar.events), limit, domain.ErrBatchTooLarge)
+	}
+
+	result := &dto.EventImportResponse{Events: []*dto.EventResponse{}, Adjusted: []*dto.EventImportAdjustment{}}
+	var adjusted []*dto.EventImportAdjustment
+	var events []*domain.Event
+	seen := make(map[string]struct{}, len(calendar.events))
+
+	for _, vevent := range calendar.events {
+		if vevent.get("RECURRENCE-ID") != nil {
+			result.Skipped++
+			continue
+		}
+
+		event, adjustments, err := s.eventFromVEvent(entID, userID, calendar, vevent)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+		}
+
+		uid := *event.ImportUID
+		if _, dup := seen[uid]; dup {
+			result.Skipped++
+			continue
+		}
+		seen[uid] = struct{}{}
+
+		_, err = s.eventRepo.GetByImportUID(ctx, entID, uid)
+		if err == nil {
+			result.Skipped++
+			continue
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("failed to check imported event: %w", err)
+		}
+
+		if !event.HasCoordinates() {
+			if lat, lng, ok := s.geocode(ctx, event.LocationAddress); ok {
+				event.LocationLat, event.LocationLng = lat, lng
+			}
+		}
+		events = append(events, event)
+		for _, reason := range adjustments {
+			adjusted = append(adjusted, &dto.EventImportAdjustment{UID: uid, Reason: reason})
+		}
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, event := range events {
+			if err := s.eventRepo.Create(ctx, event); err != nil {
+				return fmt.Errorf("failed to create event: %w", err)
+			}
+			if _, err := s.createDefaultSchedulers(ctx, entID, event); err != nil {
+				return fmt.Errorf("failed to create schedulers: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("ICS import rolled back",
+			zap.String("entity_id", entID.String()),
+			zap.Int("events", len(events)),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	for _, event := range events {
+		s.audit.Record(ctx, entID, domain.AuditActionCreate, domain.AuditTargetEvent, event.ID, nil, event)
+		s.webhooks.Dispatch(ctx, entID, domain.WebhookEventEventCreated, event)
+		result.Events = append(result.Events, dto.ToEventResponse(event))
+	}
+	result.Imported = len(events)
+	result.Adjusted = append(result.Adjusted, adjusted...)
+
+	return result, nil
+}
+
+// eventFromVEvent monta o evento em rascunho a partir de um VEVENT. Também retorna o que
+// precisou ser alterado para importá-lo
+func (s *EventService) eventFromVEvent(entID, userID uuid.UUID, calendar *icsCalendar, vevent icsComponent) (*domain.Event, []string, error) {
+	uid := vevent.text("UID")
+	if uid == "" {
+		return nil, nil, errors.New("VEVENT without UID")
+	}
+
+	dtstart := vevent.get("DTSTART")
+	if dtstart == nil {
+		return nil, nil, fmt.Errorf("VEVENT %q without DTSTART", uid)
+	}
+
+	var adjustments []string
+	timezone, loc, unknown := s.importTimezone(dtstart.params["TZID"], calendar.timezone)
+	if unknown != "" {
+		adjustments = append(adjustments, fmt.Sprintf("unknown timezone %q, using %q", unknown, timezone))
+	}
+
+	start, err := dtstart.time(loc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("VEVENT %q DTSTART: %w", uid, err)
+	}
+
+	event := &domain.Event{
+		ID:        uuid.New(),
+		EntityID:  entID,
+		Name:      truncateRunes(vevent.text("SUMMARY"), importNameMaxLen),
+		Type:      domain.EventTypeDemand,
+		Status:    domain.EventStatusDraft,
+		StartTime: start,
+		Timezone:  timezone,
+		Locale:    s.eventConfig.DefaultLocale,
+		CreatedBy: userID,
+		ImportUID: &uid,
+	}
+	if event.Name == "" {
+		event.Name = importDefaultName
+	}
+
+	if dtend := vevent.get("DTEND"); dtend != nil {
+		end, err := dtend.time(loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("VEVENT %q DTEND: %w", uid, err)
+		}
+		if end.After(start) {
+			event.EndTime = &end
+		}
+	}
+
+	if description := truncateRunes(vevent.text("DESCRIPTION"), importDescriptionMaxLen); description != "" {
+		event.Description = &description
+	}
+	if address := truncateRunes(vevent.text("LOCATION"), importAddressMaxLen); address != "" {
+		event.LocationAddress = &address
+	}
+	if geo := vevent.get("GEO"); geo != nil {
+		if lat, lng, ok := parseICSGeo(geo.value); ok {
+			event.LocationLat, event.LocationLng = lat, lng
+		}
+	}
+
+	if rule := vevent.get("RRULE"); rule != nil {
+		rrule := "RRULE:" + strings.TrimSpace(rule.value)
+		if len(rrule) > importRRuleMaxLen {
+			return nil, nil, fmt.Errorf("VEVENT %q RRULE is longer than %d characters", uid, importRRuleMaxLen)
+		}
+		event.RRuleString = &rrule
+		event.Type = domain.EventTypePeriodic
+
+		for _, exdate := range vevent.all("EXDATE") {
+			exLoc := loc
+			if tzid := exdate.params["TZID"]; tzid != "" {
+				if l, err := loadICSLocation(tzid); err == nil {
+					exLoc = l
+				} else {
+					adjustments = append(adjustments, fmt.Sprintf("unknown EXDATE timezone %q, using %q", tzid, timezone))
+				}
+			}
+			for _, value := range strings.Split(exdate.value, ",") {
+				t, err := parseICSTime(value, exdate.params["VALUE"], exLoc)
+				if err != nil {
+					return nil, nil, fmt.Errorf("VEVENT %q EXDATE: %w", uid, err)
+				}
+				event.ExDates = append(event.ExDates, t)
+			}
+		}
+	}
+
+	return event, adjustments, nil
+}
+
+// importTimezone escolhe o fuso do evento: o TZID do DTSTART, o X-WR-TIMEZONE do
+// calendário ou o padrão, nessa ordem. Fusos que não são IANA (ex.: os nomes do Windows
+// usados pelo Outlook) são pulados; o primeiro deles volta em unknown
+func (s *EventService) importTimezone(tzid, calendarTimezone string) (timezone string, loc *time.Location, unknown string) {
+	for _, name := range []string{tzid, calendarTimezone, s.eventConfig.DefaultTimezone} {
+		if name == "" {
+			continue
+		}
+		if l, err := loadICSLocation(name); err == nil {
+			return name, l, unknown
+		}
+		if unknown == "" {
+			unknown = name
+		}
+	}
+	return "UTC", time.UTC, unknown
+}
+
+// icsCalendar é o conteúdo de um VCALENDAR usado na importação
+type icsCalendar struct {
+	timezone string // X-WR-TIMEZONE, fuso padrão dos horários sem TZID
+	events   []icsComponent
+}
+
+// icsComponent são as propriedades de um componente (VEVENT), na ordem do arquivo
+type icsComponent []*icsProperty
+
+func (c icsComponent) get(name string) *icsProperty {
+	for _, p := range c {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func (c icsComponent) all(name string) []*icsProperty {
+	var props []*icsProperty
+	for _, p := range c {
+		if p.name == name {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// text retorna o valor de texto da propriedade, sem os escapes do ICS
+func (c icsComponent) text(name string) string {
+	p := c.get(name)
+	if p == nil {
+		return ""
+	}
+	return strings.TrimSpace(unescapeICSText(p.value))
+}
+
+// icsProperty é uma linha de conteúdo: NOME;PARAM=valor:valor
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// time interpreta a propriedade como DATE ou DATE-TIME; horários sem fuso (floating)
+// ficam em loc
+func (p *icsProperty) time(loc *time.Location) (time.Time, error) {
+	return parseICSTime(p.value, p.params["VALUE"], loc)
+}
+
+// parseICS lê um VCALENDAR, desdobrando as linhas continuadas, e retorna os VEVENTs de
+// primeiro nível. Componentes aninhados (VALARM, VTIMEZONE...) são ignorados
+func parseICS(r io.Reader) (*icsCalendar, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &icsCalendar{}
+	var stack []string
+	var current icsComponent
+	foundCalendar := false
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseICSProperty(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			component := strings.ToUpper(prop.value)
+			if len(stack) == 0 && component != "VCALENDAR" {
+				return nil, fmt.Errorf("expected BEGIN:VCALENDAR, got BEGIN:%s", component)
+			}
+			if component == "VCALENDAR" {
+				foundCalendar = true
+			}
+			if component == "VEVENT" && len(stack) == 1 {
+				current = icsComponent{}
+			}
+			stack = append(stack, component)
+			continue
+		case "END":
+			component := strings.ToUpper(prop.value)
+			if len(stack) == 0 || stack[len(stack)-1] != component {
+				return nil, fmt.Errorf("unexpected END:%s", component)
+			}
+			stack = stack[:len(stack)-1]
+			if component == "VEVENT" && len(stack) == 1 {
+				calendar.events = append(calendar.events, current)
+				current = nil
+			}
+			continue
+		}
+
+		switch {
+		case len(stack) == 1 && prop.name == "X-WR-TIMEZONE":
+			calendar.timezone = strings.TrimSpace(prop.value)
+		case len(stack) == 2 && stack[1] == "VEVENT":
+			current = append(current, prop)
+		}
+	}
+
+	if !foundCalendar {
+		return nil, errors.New("no VCALENDAR found")
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1])
+	}
+	return calendar, nil
+}
+
+// unfoldICSLines junta as linhas continuadas (que começam com espaço ou tab) à anterior
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseICSProperty separa nome, parâmetros e valor de uma linha de conteúdo. Os dois
+// pontos dentro de um parâmetro entre aspas não encerram o nome
+func parseICSProperty(line string) (*icsProperty, error) {
+	inQuotes := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return nil, fmt.Errorf("invalid content line %q", line)
+	}
+
+	parts := splitICSParams(line[:colon])
+	prop := &icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+// splitICSParams separa o nome da propriedade dos parâmetros por ';', fora das aspas
+func splitICSParams(s string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, c := range s {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ';' && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseICSTime interpreta um DATE (20260614) ou DATE-TIME, em UTC (20260614T170000Z) ou
+// no fuso loc (20260614T140000)
+func parseICSTime(value, valueType string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.EqualFold(valueType, "DATE") || len(value) == len("20060102"):
+		return time.ParseInLocation("20060102", value, loc)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	default:
+		return time.ParseInLocation("20060102T150405", value, loc)
+	}
+}
+
+// loadICSLocation carrega um fuso IANA; vazio é UTC
+func loadICSLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// parseICSGeo interpreta GEO:latitude;longitude
+func parseICSGeo(value string) (lat, lng float64, ok bool) {
+	latStr, lngStr, found := strings.Cut(value, ";")
+	if !found {
+		return 0, 0, false
+	}
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lng, errLng := strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// unescapeICSText desfaz os escapes de um valor TEXT (\n, \, \; \\)
+func unescapeICSText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// truncateRunes corta s em no máximo max caracteres
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max]))
+}
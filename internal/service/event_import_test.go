@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testRecurringICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//EN\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:America/Sao_Paulo\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:19700101T000000\r\n" +
+	"TZOFFSETFROM:-0300\r\n" +
+	"TZOFFSETTO:-0300\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:culto-domingo@example.com\r\n" +
+	"SUMMARY:Culto de domingo\r\n" +
+	"DESCRIPTION:Culto semanal\\, aberto a todos\\nTraga um amigo\r\n" +
+	"LOCATION:Rua das Flores\\, 100\r\n" +
+	"GEO:-23.55;-46.63\r\n" +
+	"DTSTART;TZID=America/Sao_Paulo:20300106T190000\r\n" +
+	"DTEND;TZID=America/Sao_Paulo:20300106T210000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=SU;\r\n" +
+	" COUNT=10\r\n" +
+	"EXDATE;TZID=America/Sao_Paulo:20300113T190000\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:culto-domingo@example.com\r\n" +
+	"RECURRENCE-ID;TZID=America/Sao_Paulo:20300120T190000\r\n" +
+	"SUMMARY:Culto de domingo (especial)\r\n" +
+	"DTSTART;TZID=America/Sao_Paulo:20300120T180000\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestEventService_ImportICS_CapturesRecurrenceAndTimezone(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	deps.eventRepo.On("GetByImportUID", ctx, testutil.TestEntityID, "culto-domingo@example.com").Return(nil, domain.ErrNotFound)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+
+	result, err := svc.ImportICS(ctx, testutil.TestEntityID, testutil.TestUserID, strings.NewReader(testRecurringICS))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 1, result.Skipped, "the RECURRENCE-ID exception is skipped")
+	require.Len(t, result.Events, 1)
+
+	event := result.Events[0]
+	require.NotNil(t, event.RRuleString)
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;BYDAY=SU;COUNT=10", *event.RRuleString)
+	assert.Equal(t, domain.EventTypePeriodic, event.Type)
+	assert.Equal(t, domain.EventStatusDraft, event.Status)
+	assert.Equal(t, "America/Sao_Paulo", event.Timezone)
+	assert.Equal(t, "Culto de domingo", event.Name)
+	require.NotNil(t, event.Description)
+	assert.Equal(t, "Culto semanal, aberto a todos\nTraga um amigo", *event.Description)
+	require.NotNil(t, event.LocationAddress)
+	assert.Equal(t, "Rua das Flores, 100", *event.LocationAddress)
+	assert.Equal(t, -23.55, event.LocationLat)
+	assert.Equal(t, -46.63, event.LocationLng)
+	require.NotNil(t, event.ImportUID)
+	assert.Equal(t, "culto-domingo@example.com", *event.ImportUID)
+
+	// 19:00 em São Paulo (-03) é 22:00 UTC
+	assert.True(t, event.StartTime.Equal(time.Date(2030, 1, 6, 22, 0, 0, 0, time.UTC)), "start_time = %s", event.StartTime)
+	require.NotNil(t, event.EndTime)
+	assert.True(t, event.EndTime.Equal(time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC)), "end_time = %s", event.EndTime)
+	require.Len(t, event.ExDates, 1)
+	assert.True(t, event.ExDates[0].Equal(time.Date(2030, 1, 13, 22, 0, 0, 0, time.UTC)))
+
+	deps.eventRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestEventService_ImportICS_SkipsAlreadyImportedUID(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	existing := testutil.NewTestEvent()
+	deps.eventRepo.On("GetByImportUID", ctx, testutil.TestEntityID, "culto-domingo@example.com").Return(existing, nil)
+
+	result, err := svc.ImportICS(ctx, testutil.TestEntityID, testutil.TestUserID, strings.NewReader(testRecurringICS))
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, result.Imported)
+	assert.Equal(t, 2, result.Skipped)
+	assert.Empty(t, result.Events)
+	deps.eventRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestEventService_ImportICS_UsesCalendarTimezoneForFloatingTimes(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	ics := "BEGIN:VCALENDAR\n" +
+		"X-WR-TIMEZONE:Europe/Lisbon\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:reuniao-1\n" +
+		"SUMMARY:Reunião\n" +
+		"DTSTART:20300715T100000\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+
+	deps.eventRepo.On("GetByImportUID", ctx, testutil.TestEntityID, "reuniao-1").Return(nil, domain.ErrNotFound)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+
+	result, err := svc.ImportICS(ctx, testutil.TestEntityID, testutil.TestUserID, strings.NewReader(ics))
+	require.NoError(t, err)
+	require.Len(t, result.Events, 1)
+
+	event := result.Events[0]
+	assert.Equal(t, "Europe/Lisbon", event.Timezone)
+	assert.Equal(t, domain.EventTypeDemand, event.Type)
+	assert.Nil(t, event.RRuleString)
+	// Lisboa está em UTC+1 no verão
+	assert.True(t, event.StartTime.Equal(time.Date(2030, 7, 15, 9, 0, 0, 0, time.UTC)), "start_time = %s", event.StartTime)
+}
+
+func TestEventService_ImportICS_RejectsInvalidCalendar(t *testing.T) {
+	ctx := context.Background()
+
+	cases := map[string]string{
+		"not a calendar":  "hello world\n",
+		"missing end":     "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nDTSTART:20300101T100000Z\n",
+		"missing dtstart": "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nSUMMARY:Sem início\nEND:VEVENT\nEND:VCALENDAR\n",
+		"missing uid":     "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20300101T100000Z\nEND:VEVENT\nEND:VCALENDAR\n",
+	}
+	for name, ics := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, deps := newTestEventService()
+
+			_, err := svc.ImportICS(ctx, testutil.TestEntityID, testutil.TestUserID, strings.NewReader(ics))
+			assert.ErrorIs(t, err, domain.ErrInvalidInput)
+			deps.eventRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestEventService_ImportICS_UnknownTimezoneFallsBackAndIsReported(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	// Outlook usa os nomes de fuso do Windows no TZID
+	ics := "BEGIN:VCALENDAR\n" +
+		"X-WR-TIMEZONE:Europe/Lisbon\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:reuniao-1\n" +
+		"DTSTART;TZID=GMT Standard Time:20300715T100000\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"UID:reuniao-2\n" +
+		"DTSTART;TZID=Europe/Lisbon:20300716T100000\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+
+	deps.eventRepo.On("GetByImportUID", ctx, testutil.TestEntityID, mock.Anything).Return(nil, domain.ErrNotFound)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+
+	result, err := svc.ImportICS(ctx, testutil.TestEntityID, testutil.TestUserID, strings.NewReader(ics))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+
+	// O fuso desconhecido é trocado pelo do calendário, e só esse evento é reportado
+	event := result.Events[0]
+	assert.Equal(t, "Europe/Lisbon", event.Timezone)
+	assert.True(t, event.StartTime.Equal(time.Date(2030, 7, 15, 9, 0, 0, 0, time.UTC)), "start_time = %s", event.StartTime)
+	require.Len(t, result.Adjusted, 1)
+	assert.Equal(t, "reuniao-1", result.Adjusted[0].UID)
+	assert.Contains(t, result.Adjusted[0].Reason, "GMT Standard Time")
+}
+
+func TestEventService_ImportICS_RejectsTooManyEvents(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.eventConfig = &config.EventConfig{ImportMaxEvents: 1}
+
+	_, err := svc.ImportICS(ctx, testutil.TestEntityID, testutil.TestUserID, strings.NewReader(testRecurringICS))
+	assert.ErrorIs(t, err, domain.ErrBatchTooLarge)
+	deps.eventRepo.AssertNotCalled(t, "GetByImportUID", mock.Anything, mock.Anything, mock.Anything)
+}
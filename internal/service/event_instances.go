@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/pkg/rrule"
+
+	"github.com/google/uuid"
+)
+
+// maxInstanceHorizon limita até quando as ocorrências de um evento recorrente são geradas
+const maxInstanceHorizon = 366 * 24 * time.Hour
+
+// ListInstances lista as ocorrências já geradas de um evento, incluindo as canceladas
+func (s *EventService) ListInstances(ctx context.Context, entID, eventID uuid.UUID) ([]*dto.EventInstanceResponse, error) {
+	if _, err := s.eventRepo.GetByID(ctx, eventID, entID); err != nil {
+		return nil, err
+	}
+
+	instances, err := s.eventRepo.ListInstances(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	return toInstanceResponses(instances), nil
+}
+
+// GenerateInstances cria as ocorrências do evento até until que ainda não existem. As
+// excluídas (ex_dates) não são criadas e as já existentes, editadas ou não, são mantidas.
+// Os participantes da série são inscritos nas ocorrências futuras em que ainda não estão
+func (s *EventService) GenerateInstances(ctx context.Context, entID, eventID uuid.UUID, until time.Time) ([]*dto.EventInstanceResponse, error) {
+	if until.After(time.Now().Add(maxInstanceHorizon)) {
+		return nil, fmt.Errorf("until must be within %s: %w", maxInstanceHorizon, domain.ErrInvalidInput)
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := s.occurrences(event, until)
+	if err != nil {
+		return nil, err
+	}
+	occurrences = rrule.Exclude(occurrences, event.ExDates)
+
+	existing, err := s.eventRepo.ListInstances(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	byOccurrence := make(map[time.Time]*domain.EventInstance, len(existing))
+	for _, instance := range existing {
+		byOccurrence[instance.InstanceDate.UTC()] = instance
+	}
+
+	for _, occurrence := range occurrences {
+		if _, ok := byOccurrence[occurrence.UTC()]; ok {
+			continue
+		}
+
+		instance := newEventInstance(event, occurrence)
+		if err := s.eventRepo.CreateInstance(ctx, instance); err != nil {
+			return nil, fmt.Errorf("failed to create instance: %w", err)
+		}
+	}
+
+	instances, err := s.eventRepo.ListInstances(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	if err := s.seedSeriesParticipants(ctx, event, instances, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return toInstanceResponses(instances), nil
+}
+
+// seedSeriesParticipants inscreve cada participante da série nas ocorrências que começam
+// depois de now e não foram canceladas, como um novo participante pendente da instância.
+// Quem já tem inscrição na ocorrência não é duplicado, e quem recusou a série não é inscrito
+func (s *EventService) seedSeriesParticipants(ctx context.Context, event *domain.Event, instances []*domain.EventInstance, now time.Time) error {
+	participants, err := s.participantRepo.ListAllByEvent(ctx, event.ID, event.EntityID)
+	if err != nil {
+		return fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	for _, seeded := range seriesInstanceParticipants(participants, instances, now) {
+		if err := s.participantRepo.Create(ctx, seeded); err != nil {
+			return fmt.Errorf("failed to add series participant to instance: %w", err)
+		}
+		s.syncParticipantsCache(ctx, event, seeded)
+	}
+	return nil
+}
+
+// seriesInstanceParticipants monta as inscrições que faltam dos participantes da série nas
+// ocorrências futuras. Inscrições já copiadas são reconhecidas pelo series_participant_id
+func seriesInstanceParticipants(participants []*domain.Participant, instances []*domain.EventInstance, now time.Time) []*domain.Participant {
+	type enrollment struct{ series, instance uuid.UUID }
+
+	var series []*domain.Participant
+	enrolled := make(map[enrollment]struct{})
+	for _, p := range participants {
+		switch {
+		case p.Series && p.Status != domain.ParticipantStatusDenied:
+			series = append(series, p)
+		case p.SeriesParticipantID != nil && p.InstanceID != nil:
+			enrolled[enrollment{*p.SeriesParticipantID, *p.InstanceID}] = struct{}{}
+		}
+	}
+
+	var seeded []*domain.Participant
+	for _, instance := range instances {
+		if instance.Status == domain.EventStatusCancelled || !instance.StartTime.After(now) {
+			continue
+		}
+		for _, p := range series {
+			if _, ok := enrolled[enrollment{p.ID, instance.ID}]; ok {
+				continue
+			}
+			instanceID := instance.ID
+			seriesID := p.ID
+			seeded = append(seeded, &domain.Participant{
+				ID:                  uuid.New(),
+				EventID:             p.EventID,
+				InstanceID:          &instanceID,
+				EntityID:            p.EntityID,
+				RefEntityID:         p.RefEntityID,
+				PhoneNumber:         p.PhoneNumber,
+				Status:              domain.ParticipantStatusPending,
+				LocationConsent:     p.LocationConsent,
+				LocationConsentAt:   p.LocationConsentAt,
+				Metadata:            p.Metadata,
+				Notes:               p.Notes,
+				SeriesParticipantID: &seriesID,
+			})
+		}
+	}
+	return seeded
+}
+
+// CancelInstance cancela uma única ocorrência: ela entra nas ex_dates do evento, para não
+// ser gerada de novo, e a instância fica registrada como cancelada
+func (s *EventService) CancelInstance(ctx context.Context, entID, eventID uuid.UUID, occurrence time.Time) (*dto.EventInstanceResponse, error) {
+	event, instance, err := s.loadOccurrence(ctx, entID, eventID, occurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	if !event.ExDates.Contains(occurrence) {
+		exDates := append(append(domain.ExDates{}, event.ExDates...), occurrence.UTC())
+		if err := s.eventRepo.Update(ctx, eventID, entID, &domain.UpdateEventInput{ExDates: exDates}); err != nil {
+			return nil, err
+		}
+	}
+
+	var before *domain.EventInstance
+	if instance != nil {
+		copied := *instance
+		before = &copied
+	} else {
+		instance = newEventInstance(event, occurrence)
+	}
+	instance.Status = domain.EventStatusCancelled
+	instance.Overridden = true
+
+	if err := s.saveInstance(ctx, instance, before == nil); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, entID, domain.AuditActionUpdate, domain.AuditTargetEvent, eventID, before, instance)
+	return dto.ToEventInstanceResponse(instance), nil
+}
+
+// OverrideInstance altera horário, local ou status de uma única ocorrência sem mexer na série.
+// A ocorrência fica marcada como editada e a regeneração não a sobrescreve
+func (s *EventService) OverrideInstance(ctx context.Context, entID, eventID uuid.UUID, req *dto.OverrideInstanceRequest) (*dto.EventInstanceResponse, error) {
+	event, instance, err := s.loadOccurrence(ctx, entID, eventID, req.Occurrence)
+	if err != nil {
+		return nil, err
+	}
+	if event.ExDates.Contains(req.Occurrence) {
+		return nil, fmt.Errorf("occurrence was cancelled: %w", domain.ErrConflict)
+	}
+
+	var before *domain.EventInstance
+	if instance != nil {
+		copied := *instance
+		before = &copied
+	} else {
+		instance = newEventInstance(event, req.Occurrence)
+	}
+
+	if req.StartTime != nil {
+		instance.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		instance.EndTime = req.EndTime
+	}
+	if instance.EndTime != nil && !instance.EndTime.After(instance.StartTime) {
+		return nil, fmt.Errorf("end_time must be after start_time: %w", domain.ErrInvalidInput)
+	}
+	if req.LocationLat != nil {
+		instance.LocationLat = req.LocationLat
+	}
+	if req.LocationLng != nil {
+		instance.LocationLng = req.LocationLng
+	}
+	if req.LocationAddress != nil {
+		instance.LocationAddress = req.LocationAddress
+	}
+	if req.Status != nil {
+		instance.Status = *req.Status
+	}
+	instance.Overridden = true
+
+	if err := s.saveInstance(ctx, instance, before == nil); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, entID, domain.AuditActionUpdate, domain.AuditTargetEvent, eventID, before, instance)
+	return dto.ToEventInstanceResponse(instance), nil
+}
+
+// loadOccurrence carrega o evento e a instância da ocorrência, se já gerada. Retorna
+// ErrNotFound se o horário não for uma ocorrência da recorrência do evento e
+// ErrInvalidInput se estiver além de maxInstanceHorizon, o mesmo limite da geração
+func (s *EventService) loadOccurrence(ctx context.Context, entID, eventID uuid.UUID, occurrence time.Time) (*domain.Event, *domain.EventInstance, error) {
+	if occurrence.After(time.Now().Add(maxInstanceHorizon)) {
+		return nil, nil, fmt.Errorf("occurrence must be within %s: %w", maxInstanceHorizon, domain.ErrInvalidInput)
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	occurrences, err := s.occurrences(event, occurrence.Add(time.Second))
+	if err != nil {
+		return nil, nil, err
+	}
+	found := false
+	for _, t := range occurrences {
+		if t.Equal(occurrence) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("not an occurrence of the event: %w", domain.ErrNotFound)
+	}
+
+	instances, err := s.eventRepo.ListInstances(ctx, eventID, entID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	for _, instance := range instances {
+		if instance.InstanceDate.Equal(occurrence) {
+			return event, instance, nil
+		}
+	}
+
+	return event, nil, nil
+}
+
+// occurrences expande a recorrência do evento até until, sem aplicar as ex_dates
+func (s *EventService) occurrences(event *domain.Event, until time.Time) ([]time.Time, error) {
+	if event.RRuleString == nil || *event.RRuleString == "" {
+		return nil, domain.ErrEventNotRecurring
+	}
+
+	occurrences, err := rrule.NewParser().GenerateInstances(event.StartTime, *event.RRuleString, until)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, domain.ErrInvalidInput)
+	}
+	return occurrences, nil
+}
+
+func (s *EventService) saveInstance(ctx context.Context, instance *domain.EventInstance, isNew bool) error {
+	if isNew {
+		if err := s.eventRepo.CreateInstance(ctx, instance); err != nil {
+			return fmt.Errorf("failed to create instance: %w", err)
+		}
+		return nil
+	}
+	if err := s.eventRepo.UpdateInstance(ctx, instance); err != nil {
+		return fmt.Errorf("failed to update instance: %w", err)
+	}
+	return nil
+}
+
+// newEventInstance monta a instância padrão de uma ocorrência, com a duração do evento
+func newEventInstance(event *domain.Event, occurrence time.Time) *domain.EventInstance {
+	instance := &domain.EventInstance{
+		ID:           uuid.New(),
+		EventID:      event.ID,
+		EntityID:     event.EntityID,
+		InstanceDate: occurrence.UTC(),
+		Status:       domain.EventStatusScheduled,
+		StartTime:    occurrence,
+	}
+	if event.EndTime != nil {
+		end := occurrence.Add(event.EndTime.Sub(event.StartTime))
+		instance.EndTime = &end
+	}
+	return instance
+}
+
+func toInstanceResponses(instances []*domain.EventInstance) []*dto.EventInstanceResponse {
+	responses := make([]*dto.EventInstanceResponse, len(instances))
+	for i, instance := range instances {
+		responses[i] = dto.ToEventInstanceResponse(instance)
+	}
+	return responses
+}
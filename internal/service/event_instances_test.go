@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRecurringEvent devolve um evento diário começando amanhã às 10h (UTC)
+func newTestRecurringEvent() *domain.Event {
+	rule := "RRULE:FREQ=DAILY"
+	event := testutil.NewTestEvent()
+	event.StartTime = time.Now().UTC().Truncate(24 * time.Hour).Add(34 * time.Hour)
+	end := event.StartTime.Add(2 * time.Hour)
+	event.EndTime = &end
+	event.RRuleString = &rule
+	return event
+}
+
+// nthOccurrence devolve o início da ocorrência do dia day da série
+func nthOccurrence(event *domain.Event, day int) time.Time {
+	return event.StartTime.AddDate(0, 0, day)
+}
+
+// captureCreatedInstances guarda as instâncias criadas no repositório
+func (d *eventServiceDeps) captureCreatedInstances(ctx context.Context) *[]*domain.EventInstance {
+	var created []*domain.EventInstance
+	d.eventRepo.On("CreateInstance", ctx, mock.AnythingOfType("*domain.EventInstance")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(1).(*domain.EventInstance)) }).
+		Return(nil)
+	return &created
+}
+
+func TestEventService_GenerateInstances_PreservesOverridesAndHonorsExclusions(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := newTestRecurringEvent()
+	event.ExDates = domain.ExDates{nthOccurrence(event, 1)}
+
+	// Ocorrência do dia 2 já gerada e movida para a tarde
+	moved := newEventInstance(event, nthOccurrence(event, 2))
+	moved.StartTime = moved.StartTime.Add(4 * time.Hour)
+	moved.Overridden = true
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("ListInstances", ctx, event.ID, event.EntityID).Return([]*domain.EventInstance{moved}, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return([]*domain.Participant{}, nil)
+	created := deps.captureCreatedInstances(ctx)
+
+	_, err := svc.GenerateInstances(ctx, event.EntityID, event.ID, nthOccurrence(event, 5).Add(-time.Second))
+	require.NoError(t, err)
+
+	var dates []time.Time
+	for _, instance := range *created {
+		dates = append(dates, instance.InstanceDate)
+	}
+	assert.Equal(t, []time.Time{nthOccurrence(event, 0), nthOccurrence(event, 3), nthOccurrence(event, 4)}, dates)
+	deps.eventRepo.AssertNotCalled(t, "UpdateInstance", mock.Anything, mock.Anything)
+	assert.Equal(t, nthOccurrence(event, 2).Add(4*time.Hour), moved.StartTime)
+}
+
+func TestEventService_GenerateInstances_SeedsSeriesParticipants(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	eventCache, cacheDeps := newTestEventCacheService(t)
+	svc.eventCache = eventCache
+
+	event := newTestRecurringEvent()
+	upcoming := newEventInstance(event, nthOccurrence(event, 0))
+	enrolledIn := newEventInstance(event, nthOccurrence(event, 1))
+	cancelled := newEventInstance(event, nthOccurrence(event, 2))
+	cancelled.Status = domain.EventStatusCancelled
+	past := newEventInstance(event, nthOccurrence(event, -3))
+	instances := []*domain.EventInstance{past, upcoming, enrolledIn, cancelled}
+
+	series := testutil.NewTestParticipant()
+	series.ID = uuid.New()
+	series.Series = true
+	series.PhoneNumber = "5511999990001"
+	// Quem recusou a série não é inscrito nas ocorrências
+	declined := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusDenied)
+	declined.ID = uuid.New()
+	declined.Series = true
+	// Já inscrito na ocorrência do dia 1, com status próprio
+	copied := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	copied.ID = uuid.New()
+	copied.InstanceID = &enrolledIn.ID
+	copied.SeriesParticipantID = &series.ID
+	// Participante avulso, só do evento
+	single := testutil.NewTestParticipant()
+	single.ID = uuid.New()
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("ListInstances", ctx, event.ID, event.EntityID).Return(instances, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.Participant{series, declined, copied, single}, nil)
+	var seeded []*domain.Participant
+	deps.participantRepo.On("Create", ctx, mock.AnythingOfType("*domain.Participant")).
+		Run(func(args mock.Arguments) { seeded = append(seeded, args.Get(1).(*domain.Participant)) }).
+		Return(nil)
+
+	_, err := svc.GenerateInstances(ctx, event.EntityID, event.ID, nthOccurrence(event, 3).Add(-time.Second))
+	require.NoError(t, err)
+
+	// Só a ocorrência futura, não cancelada e sem a inscrição recebe o participante da série
+	require.Len(t, seeded, 1)
+	got := seeded[0]
+	require.NotNil(t, got.InstanceID)
+	assert.Equal(t, upcoming.ID, *got.InstanceID)
+	require.NotNil(t, got.SeriesParticipantID)
+	assert.Equal(t, series.ID, *got.SeriesParticipantID)
+	assert.Equal(t, domain.ParticipantStatusPending, got.Status)
+	assert.Equal(t, series.PhoneNumber, got.PhoneNumber)
+	assert.False(t, got.Series)
+	assert.NotEqual(t, series.ID, got.ID)
+	assert.True(t, cacheDeps.redis.Exists(confirmationKey(event.EntityID, event.ID, got.ID)))
+
+	// A inscrição copiada mantém o próprio status
+	assert.Equal(t, domain.ParticipantStatusConfirmed, copied.Status)
+}
+
+func TestEventService_CancelInstance_ExcludesOccurrenceFromRegeneration(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := newTestRecurringEvent()
+	cancelled := nthOccurrence(event, 1)
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("ListInstances", ctx, event.ID, event.EntityID).Return([]*domain.EventInstance{}, nil)
+	deps.eventRepo.On("Update", ctx, event.ID, event.EntityID, mock.AnythingOfType("*domain.UpdateEventInput")).
+		Run(func(args mock.Arguments) { event.ExDates = args.Get(3).(*domain.UpdateEventInput).ExDates }).
+		Return(nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return([]*domain.Participant{}, nil)
+	created := deps.captureCreatedInstances(ctx)
+
+	resp, err := svc.CancelInstance(ctx, event.EntityID, event.ID, cancelled)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventStatusCancelled, resp.Status)
+	assert.True(t, event.ExDates.Contains(cancelled))
+	require.Len(t, *created, 1)
+
+	// A regeneração não recria a ocorrência cancelada
+	_, err = svc.GenerateInstances(ctx, event.EntityID, event.ID, nthOccurrence(event, 3).Add(-time.Second))
+	require.NoError(t, err)
+	require.Len(t, *created, 3)
+	for _, instance := range (*created)[1:] {
+		assert.False(t, instance.InstanceDate.Equal(cancelled))
+	}
+}
+
+func TestEventService_OverrideInstance_MarksExistingInstanceOverridden(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := newTestRecurringEvent()
+	instance := newEventInstance(event, nthOccurrence(event, 2))
+	address := "Rua Nova, 100"
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("ListInstances", ctx, event.ID, event.EntityID).Return([]*domain.EventInstance{instance}, nil)
+	deps.eventRepo.On("UpdateInstance", ctx, instance).Return(nil)
+
+	resp, err := svc.OverrideInstance(ctx, event.EntityID, event.ID, &dto.OverrideInstanceRequest{
+		Occurrence:      nthOccurrence(event, 2),
+		LocationAddress: &address,
+	})
+	require.NoError(t, err)
+	assert.True(t, instance.Overridden)
+	assert.Equal(t, address, *instance.LocationAddress)
+	assert.Equal(t, nthOccurrence(event, 2), resp.StartTime)
+	deps.eventRepo.AssertNotCalled(t, "CreateInstance", mock.Anything, mock.Anything)
+}
+
+func TestEventService_CancelInstance_RejectsOccurrencesBeyondHorizon(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := newTestRecurringEvent()
+
+	_, err := svc.CancelInstance(ctx, event.EntityID, event.ID, event.StartTime.Add(maxInstanceHorizon))
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	deps.eventRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+}
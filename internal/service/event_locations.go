@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// ListLocations lista os locais do evento em ordem; o primeiro é o principal. Evento sem
+// lista, criado com um único local, aparece com esse local como principal
+func (s *EventService) ListLocations(ctx context.Context, entID, eventID uuid.UUID) ([]*dto.EventLocationResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := *event
+	view.SetLocations(currentLocations(event))
+	return dto.ToEventLocationResponses(&view), nil
+}
+
+// AddLocation adiciona um local ao evento, no fim da lista ou na posição informada. Na
+// posição 0 ele se torna o principal e é espelhado no local único do evento
+func (s *EventService) AddLocation(ctx context.Context, entID, eventID uuid.UUID, req *dto.AddEventLocationRequest) ([]*dto.EventLocationResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := currentLocations(event)
+	if len(locations) >= domain.MaxEventLocations {
+		return nil, fmt.Errorf("an event has at most %d locations: %w", domain.MaxEventLocations, domain.ErrInvalidInput)
+	}
+
+	location, err := s.newEventLocation(ctx, &req.EventLocationRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	position := len(locations)
+	if req.Position != nil && *req.Position < position {
+		position = *req.Position
+	}
+	return s.saveLocations(ctx, event, slices.Insert(locations, position, location), event.TargetLocationID)
+}
+
+// UpdateLocation altera os campos informados de um local do evento e, com position, o
+// move na lista. Endereço novo sem coordenadas é geocodificado
+func (s *EventService) UpdateLocation(ctx context.Context, entID, eventID, locationID uuid.UUID, req *dto.UpdateEventLocationRequest) ([]*dto.EventLocationResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := currentLocations(event)
+	index := slices.IndexFunc(locations, func(l domain.EventLocation) bool { return l.ID == locationID })
+	if index < 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	location := locations[index]
+	if req.Label != nil {
+		location.Label = *req.Label
+	}
+	if req.Lat != nil {
+		location.Lat = *req.Lat
+	}
+	if req.Lng != nil {
+		location.Lng = *req.Lng
+	}
+	if req.Address != nil {
+		changed := location.Address == nil || *location.Address != *req.Address
+		location.Address = req.Address
+		if changed && req.Lat == nil && req.Lng == nil {
+			lat, lng, ok := s.geocode(ctx, req.Address)
+			if !ok {
+				return nil, fmt.Errorf("could not resolve the coordinates of the location address: %w", domain.ErrInvalidInput)
+			}
+			location.Lat, location.Lng = lat, lng
+		}
+	}
+
+	locations = slices.Delete(locations, index, index+1)
+	position := index
+	if req.Position != nil {
+		position = min(*req.Position, len(locations))
+	}
+	return s.saveLocations(ctx, event, slices.Insert(locations, position, location), event.TargetLocationID)
+}
+
+// RemoveLocation tira um local do evento. Sem o principal, o seguinte passa a ser o
+// principal; sem o alvo, geofence e ETA voltam para o principal. Removido o último, o
+// evento fica com o local único que tinha
+func (s *EventService) RemoveLocation(ctx context.Context, entID, eventID, locationID uuid.UUID) error {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return err
+	}
+
+	locations := currentLocations(event)
+	index := slices.IndexFunc(locations, func(l domain.EventLocation) bool { return l.ID == locationID })
+	if index < 0 {
+		return domain.ErrNotFound
+	}
+
+	_, err = s.saveLocations(ctx, event, slices.Delete(locations, index, index+1), event.TargetLocationID)
+	return err
+}
+
+// SetTargetLocation escolhe o local que geofence e ETA usam; nil volta para o principal
+func (s *EventService) SetTargetLocation(ctx context.Context, entID, eventID uuid.UUID, locationID *uuid.UUID) ([]*dto.EventLocationResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := currentLocations(event)
+	if locationID != nil && locations.Find(*locationID) == nil {
+		return nil, domain.ErrNotFound
+	}
+	return s.saveLocations(ctx, event, locations, locationID)
+}
+
+// Destination retorna as coordenadas usadas no ETA: as do local informado ou, sem ele,
+// as do alvo do evento (o principal, se nenhum foi escolhido)
+func (s *EventService) Destination(ctx context.Context, entID, eventID uuid.UUID, locationID *uuid.UUID) (lat, lng float64, err error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if locationID != nil {
+		location := currentLocations(event).Find(*locationID)
+		if location == nil {
+			return 0, 0, domain.ErrNotFound
+		}
+		return location.Lat, location.Lng, nil
+	}
+
+	if !event.HasCoordinates() {
+		return 0, 0, fmt.Errorf("event does not have a location defined: %w", domain.ErrInvalidInput)
+	}
+	lat, lng = event.TargetCoordinates()
+	return lat, lng, nil
+}
+
+// newEventLocation monta um local com id novo. Sem coordenadas, vêm do geocoding do
+// endereço; um local sem coordenadas não serve para geofence nem ETA e é rejeitado
+func (s *EventService) newEventLocation(ctx context.Context, req *dto.EventLocationRequest) (domain.EventLocation, error) {
+	location := domain.EventLocation{
+		ID:      uuid.New(),
+		Label:   req.Label,
+		Address: req.Address,
+	}
+
+	switch {
+	case req.Lat != nil && req.Lng != nil:
+		location.Lat, location.Lng = *req.Lat, *req.Lng
+	case req.Lat != nil || req.Lng != nil:
+		return location, fmt.Errorf("lat and lng must be informed together: %w", domain.ErrInvalidInput)
+	default:
+		lat, lng, ok := s.geocode(ctx, req.Address)
+		if !ok {
+			return location, fmt.Errorf("could not resolve the coordinates of the location address: %w", domain.ErrInvalidInput)
+		}
+		location.Lat, location.Lng = lat, lng
+	}
+	return location, nil
+}
+
+// newEventLocations monta os locais informados na criação do evento
+func (s *EventService) newEventLocations(ctx context.Context, reqs []dto.EventLocationRequest) (domain.EventLocations, error) {
+	locations := make(domain.EventLocations, len(reqs))
+	for i := range reqs {
+		location, err := s.newEventLocation(ctx, &reqs[i])
+		if err != nil {
+			return nil, err
+		}
+		locations[i] = location
+	}
+	return locations, nil
+}
+
+// currentLocations retorna uma cópia dos locais do evento. Evento criado com um único
+// local ganha esse local como principal, rotulado com o nome do evento e com id derivado
+// do evento (estável entre leituras), para que adicionar outro não o substitua
+func currentLocations(event *domain.Event) domain.EventLocations {
+	if len(event.Locations) > 0 {
+		return slices.Clone(event.Locations)
+	}
+	if !event.HasCoordinates() && event.LocationAddress == nil {
+		return domain.EventLocations{}
+	}
+	return domain.EventLocations{{
+		ID:      uuid.NewSHA1(event.ID, []byte("location")),
+		Label:   event.Name,
+		Lat:     event.LocationLat,
+		Lng:     event.LocationLng,
+		Address: event.LocationAddress,
+	}}
+}
+
+// saveLocations grava a nova lista de locais e o alvo, espelhando o principal no local
+// único do evento
+func (s *EventService) saveLocations(ctx context.Context, existing *domain.Event, locations domain.EventLocations, target *uuid.UUID) ([]*dto.EventLocationResponse, error) {
+	updated := *existing
+	updated.TargetLocationID = target
+	updated.SetLocations(locations)
+
+	input := &domain.UpdateEventInput{
+		Locations:       updated.Locations,
+		LocationLat:     &updated.LocationLat,
+		LocationLng:     &updated.LocationLng,
+		ExpectedVersion: &existing.Version,
+	}
+	if updated.LocationAddress != nil {
+		input.LocationAddress = updated.LocationAddress
+	} else {
+		input.ClearLocationAddress = true
+	}
+	if updated.TargetLocationID != nil {
+		input.TargetLocationID = updated.TargetLocationID
+	} else {
+		input.ClearTargetLocation = existing.TargetLocationID != nil
+	}
+
+	if err := s.eventRepo.Update(ctx, existing.ID, existing.EntityID, input); err != nil {
+		return nil, fmt.Errorf("failed to update event locations: %w", err)
+	}
+	updated.Version++
+
+	s.audit.Record(ctx, existing.EntityID, domain.AuditActionUpdate, domain.AuditTargetEvent, existing.ID, existing, &updated)
+	return dto.ToEventLocationResponses(&updated), nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// captureEventUpdate grava o input passado ao Update do repositório de eventos
+func captureEventUpdate(deps *eventServiceDeps, event *domain.Event) *domain.UpdateEventInput {
+	var input domain.UpdateEventInput
+	deps.eventRepo.On("Update", mock.Anything, event.ID, event.EntityID, mock.AnythingOfType("*domain.UpdateEventInput")).
+		Run(func(args mock.Arguments) { input = *args.Get(3).(*domain.UpdateEventInput) }).
+		Return(nil)
+	return &input
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestEventService_AddLocation_KeepsSingleLocationAsPrimary(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := testutil.NewTestEvent()
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	input := captureEventUpdate(deps, event)
+
+	locations, err := svc.AddLocation(ctx, event.EntityID, event.ID, &dto.AddEventLocationRequest{
+		EventLocationRequest: dto.EventLocationRequest{Label: "Festa", Lat: floatPtr(-23.60), Lng: floatPtr(-46.70)},
+	})
+	require.NoError(t, err)
+
+	// O local único do evento vira o principal e continua espelhado
+	require.Len(t, locations, 2)
+	assert.True(t, locations[0].Primary)
+	assert.True(t, locations[0].Target)
+	assert.Equal(t, event.Name, locations[0].Label)
+	assert.Equal(t, "Festa", locations[1].Label)
+
+	require.Len(t, input.Locations, 2)
+	assert.Equal(t, event.LocationLat, *input.LocationLat)
+	assert.Equal(t, event.LocationLng, *input.LocationLng)
+	require.NotNil(t, input.ExpectedVersion)
+	assert.Equal(t, event.Version, *input.ExpectedVersion)
+
+	// O id do local principal derivado é estável entre leituras
+	listed, err := svc.ListLocations(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, locations[0].ID, listed[0].ID)
+}
+
+func TestEventService_AddLocation_AtFirstPositionMirrorsNewPrimary(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := testutil.NewTestEvent()
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	input := captureEventUpdate(deps, event)
+
+	address := "Praça da Sé"
+	position := 0
+	locations, err := svc.AddLocation(ctx, event.EntityID, event.ID, &dto.AddEventLocationRequest{
+		EventLocationRequest: dto.EventLocationRequest{Label: "Ponto de encontro", Lat: floatPtr(-23.55), Lng: floatPtr(-46.63), Address: &address},
+		Position:             &position,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, locations, 2)
+	assert.Equal(t, "Ponto de encontro", locations[0].Label)
+	assert.Equal(t, -23.55, *input.LocationLat)
+	assert.Equal(t, -46.63, *input.LocationLng)
+	require.NotNil(t, input.LocationAddress)
+	assert.Equal(t, address, *input.LocationAddress)
+}
+
+func TestEventService_RemoveLocation_PromotesNextAndDropsTarget(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	meetingPoint := domain.EventLocation{ID: uuid.New(), Label: "Ponto de encontro", Lat: -23.55, Lng: -46.63}
+	destination := domain.EventLocation{ID: uuid.New(), Label: "Destino", Lat: -22.90, Lng: -43.20}
+	event := testutil.NewTestEvent()
+	event.SetLocations(domain.EventLocations{meetingPoint, destination})
+	event.TargetLocationID = &meetingPoint.ID
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	input := captureEventUpdate(deps, event)
+
+	require.NoError(t, svc.RemoveLocation(ctx, event.EntityID, event.ID, meetingPoint.ID))
+
+	assert.Equal(t, domain.EventLocations{destination}, input.Locations)
+	assert.Equal(t, -22.90, *input.LocationLat)
+	assert.Equal(t, -43.20, *input.LocationLng)
+	assert.True(t, input.ClearLocationAddress)
+	assert.Nil(t, input.TargetLocationID)
+	assert.True(t, input.ClearTargetLocation, "the removed target falls back to the primary")
+}
+
+func TestEventService_SetTargetLocation(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	meetingPoint := domain.EventLocation{ID: uuid.New(), Label: "Ponto de encontro", Lat: -23.55, Lng: -46.63}
+	destination := domain.EventLocation{ID: uuid.New(), Label: "Destino", Lat: -22.90, Lng: -43.20}
+	event := testutil.NewTestEvent()
+	event.SetLocations(domain.EventLocations{meetingPoint, destination})
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	input := captureEventUpdate(deps, event)
+
+	unknown := uuid.New()
+	_, err := svc.SetTargetLocation(ctx, event.EntityID, event.ID, &unknown)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	locations, err := svc.SetTargetLocation(ctx, event.EntityID, event.ID, &destination.ID)
+	require.NoError(t, err)
+	assert.False(t, locations[0].Target)
+	assert.True(t, locations[1].Target)
+	require.NotNil(t, input.TargetLocationID)
+	assert.Equal(t, destination.ID, *input.TargetLocationID)
+	assert.Equal(t, -23.55, *input.LocationLat, "the target doesn't change the primary")
+}
+
+func TestEventService_Update_SingleLocationUpdatesPrimary(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	meetingPoint := domain.EventLocation{ID: uuid.New(), Label: "Ponto de encontro", Lat: -23.55, Lng: -46.63}
+	destination := domain.EventLocation{ID: uuid.New(), Label: "Destino", Lat: -22.90, Lng: -43.20}
+	event := testutil.NewTestEvent()
+	event.SetLocations(domain.EventLocations{meetingPoint, destination})
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	input := captureEventUpdate(deps, event)
+
+	_, err := svc.Update(ctx, event.EntityID, event.ID, &dto.UpdateEventRequest{LocationLat: floatPtr(-23.56), LocationLng: floatPtr(-46.64)})
+	require.NoError(t, err)
+
+	require.Len(t, input.Locations, 2)
+	assert.Equal(t, -23.56, input.Locations[0].Lat)
+	assert.Equal(t, -46.64, input.Locations[0].Lng)
+	assert.Equal(t, destination, input.Locations[1])
+	assert.Equal(t, -23.55, event.Locations[0].Lat, "the loaded event isn't modified")
+}
+
+func TestEventService_Create_MirrorsPrimaryLocation(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	var created *domain.Event
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*domain.Event) }).
+		Return(nil)
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+	// A resposta vem da linha gravada
+	load := deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID)
+	load.Run(func(mock.Arguments) { load.ReturnArguments = mock.Arguments{created, nil} })
+
+	address := "Igreja Matriz"
+	req := newTestCreateEventRequest()
+	req.LocationLat, req.LocationLng = 0, 0
+	req.Locations = []dto.EventLocationRequest{
+		{Label: "Cerimônia", Lat: floatPtr(-23.60), Lng: floatPtr(-46.70), Address: &address},
+		{Label: "Festa", Lat: floatPtr(-23.65), Lng: floatPtr(-46.75)},
+	}
+
+	resp, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	require.Len(t, created.Locations, 2)
+	assert.Equal(t, -23.60, created.LocationLat)
+	assert.Equal(t, -46.70, created.LocationLng)
+	require.NotNil(t, created.LocationAddress)
+	assert.Equal(t, address, *created.LocationAddress)
+
+	require.Len(t, resp.Locations, 2)
+	assert.True(t, resp.Locations[0].Primary)
+	assert.Equal(t, "Festa", resp.Locations[1].Label)
+}
+
+func TestEventService_AddLocation_RejectsLocationWithoutCoordinates(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := testutil.NewTestEvent()
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+
+	// Sem geocoder configurado o endereço não vira coordenadas
+	address := "Rua Desconhecida, 1"
+	_, err := svc.AddLocation(ctx, event.EntityID, event.ID, &dto.AddEventLocationRequest{
+		EventLocationRequest: dto.EventLocationRequest{Label: "Festa", Address: &address},
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	deps.eventRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// CanManageEvent retorna true se o usuário criou o evento ou é um de seus co-organizadores.
+// Admins da entidade gerenciam todos os eventos pelo papel e não passam por aqui
+func (s *EventService) CanManageEvent(ctx context.Context, entID, eventID, userID uuid.UUID) (bool, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return false, err
+	}
+	if event.CreatedBy == userID {
+		return true, nil
+	}
+
+	isOrganizer, err := s.organizerRepo.IsOrganizer(ctx, eventID, userID, entID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check event organizer: %w", err)
+	}
+	return isOrganizer, nil
+}
+
+// ListOrganizers lista os co-organizadores do evento
+func (s *EventService) ListOrganizers(ctx context.Context, entID, eventID uuid.UUID) ([]*dto.EventOrganizerResponse, error) {
+	if _, err := s.eventRepo.GetByID(ctx, eventID, entID); err != nil {
+		return nil, err
+	}
+
+	organizers, err := s.organizerRepo.ListByEvent(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event organizers: %w", err)
+	}
+
+	responses := make([]*dto.EventOrganizerResponse, len(organizers))
+	for i, o := range organizers {
+		responses[i] = dto.ToEventOrganizerResponse(o)
+	}
+	return responses, nil
+}
+
+// AddOrganizer torna um membro da entidade co-organizador do evento. Usuários de fora da
+// entidade e o próprio criador retornam domain.ErrInvalidInput
+func (s *EventService) AddOrganizer(ctx context.Context, entID, eventID, actorID, userID uuid.UUID) ([]*dto.EventOrganizerResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+	if event.CreatedBy == userID {
+		return nil, fmt.Errorf("user already owns the event: %w", domain.ErrInvalidInput)
+	}
+
+	if _, err := s.userRepo.GetEntityMembership(ctx, userID, entID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("user is not a member of the entity: %w", domain.ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("failed to check entity membership: %w", err)
+	}
+
+	organizer := &domain.EventOrganizer{
+		EventID:  eventID,
+		UserID:   userID,
+		EntityID: entID,
+		AddedBy:  actorID,
+	}
+	if err := s.organizerRepo.Add(ctx, organizer); err != nil {
+		return nil, fmt.Errorf("failed to add event organizer: %w", err)
+	}
+
+	s.audit.Record(ctx, entID, domain.AuditActionUpdate, domain.AuditTargetEvent, eventID, nil, organizer)
+	return s.ListOrganizers(ctx, entID, eventID)
+}
+
+// RemoveOrganizer tira o co-organizador do evento
+func (s *EventService) RemoveOrganizer(ctx context.Context, entID, eventID, userID uuid.UUID) error {
+	if _, err := s.eventRepo.GetByID(ctx, eventID, entID); err != nil {
+		return err
+	}
+
+	if err := s.organizerRepo.Remove(ctx, eventID, userID, entID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, entID, domain.AuditActionUpdate, domain.AuditTargetEvent, eventID, &domain.EventOrganizer{EventID: eventID, UserID: userID, EntityID: entID}, nil)
+	return nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventService_CoOrganizerUpdatesEventTheyDontOwn(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	// Evento criado por outro usuário
+	event := testutil.NewTestEvent()
+	event.CreatedBy = uuid.New()
+	coOrganizer := testutil.TestUserID
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.organizerRepo.On("IsOrganizer", ctx, event.ID, coOrganizer, event.EntityID).Return(true, nil)
+	deps.eventRepo.On("Update", ctx, event.ID, event.EntityID, mock.AnythingOfType("*domain.UpdateEventInput")).Return(nil)
+
+	allowed, err := svc.CanManageEvent(ctx, event.EntityID, event.ID, coOrganizer)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	name := "Novo nome"
+	_, err = svc.Update(ctx, event.EntityID, event.ID, &dto.UpdateEventRequest{Name: &name})
+	require.NoError(t, err)
+	deps.eventRepo.AssertCalled(t, "Update", ctx, event.ID, event.EntityID, mock.AnythingOfType("*domain.UpdateEventInput"))
+}
+
+func TestEventService_CanManageEvent(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := testutil.NewTestEvent()
+	event.CreatedBy = uuid.New()
+	stranger := uuid.New()
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.organizerRepo.On("IsOrganizer", ctx, event.ID, stranger, event.EntityID).Return(false, nil)
+
+	// O criador não precisa ser co-organizador
+	allowed, err := svc.CanManageEvent(ctx, event.EntityID, event.ID, event.CreatedBy)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	deps.organizerRepo.AssertNotCalled(t, "IsOrganizer", mock.Anything, mock.Anything, event.CreatedBy, mock.Anything)
+
+	allowed, err = svc.CanManageEvent(ctx, event.EntityID, event.ID, stranger)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestEventService_AddOrganizer(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := testutil.NewTestEvent()
+	event.CreatedBy = uuid.New()
+	member, outsider := uuid.New(), uuid.New()
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.userRepo.On("GetEntityMembership", ctx, member, event.EntityID).Return(&domain.UserEntity{UserID: member, EntityID: event.EntityID}, nil)
+	deps.userRepo.On("GetEntityMembership", ctx, outsider, event.EntityID).Return(nil, domain.ErrNotFound)
+	deps.organizerRepo.On("Add", ctx, mock.AnythingOfType("*domain.EventOrganizer")).Return(nil)
+	deps.organizerRepo.On("ListByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.EventOrganizer{{EventID: event.ID, UserID: member, EntityID: event.EntityID, AddedBy: event.CreatedBy}}, nil)
+
+	organizers, err := svc.AddOrganizer(ctx, event.EntityID, event.ID, event.CreatedBy, member)
+	require.NoError(t, err)
+	require.Len(t, organizers, 1)
+	assert.Equal(t, member, organizers[0].UserID)
+
+	// Usuários de fora da entidade e o próprio criador são recusados
+	_, err = svc.AddOrganizer(ctx, event.EntityID, event.ID, event.CreatedBy, outsider)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	_, err = svc.AddOrganizer(ctx, event.EntityID, event.ID, event.CreatedBy, event.CreatedBy)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	deps.organizerRepo.AssertNumberOfCalls(t, "Add", 1)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// GenerateReport monta o relatório consolidado do evento a partir dos participantes, do log
+// de notificações e das localizações. Os números por status são os mesmos de EventStats
+func (s *EventService) GenerateReport(ctx context.Context, entID, eventID uuid.UUID) (*dto.EventReportResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants, err := s.participantRepo.ListAllByEvent(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	deliveries, err := s.deliveryRepo.CountByEventStatus(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notification deliveries: %w", err)
+	}
+
+	firstLocations, err := s.locationRepo.GetFirstByEvent(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get locations: %w", err)
+	}
+
+	stats := domain.NewEventStats(participants)
+	report := &dto.EventReportResponse{
+		EventID:        event.ID,
+		EventName:      event.Name,
+		Status:         event.Status,
+		StartTime:      event.StartTime,
+		StartTimeLocal: dto.FormatEventTime(event, event.StartTime),
+		GeneratedAt:    time.Now(),
+		Stats:          stats,
+		Funnel: dto.ReportFunnel{
+			Invited:   stats.Total,
+			Responded: stats.Total - stats.Pending,
+			Confirmed: stats.Confirmed + stats.CheckedIn + stats.NoShow,
+			CheckedIn: stats.CheckedIn,
+		},
+		NoShows: dto.ReportNoShows{
+			Count:                   stats.NoShow,
+			ConfirmedWithoutCheckIn: stats.Confirmed,
+			ParticipantIDs:          []uuid.UUID{},
+		},
+		Notifications: deliveryReport(deliveries),
+		CheckIns:      checkInReport(event, participants),
+		ETAAccuracy:   etaAccuracyReport(event, participants, firstLocations),
+	}
+
+	for _, p := range participants {
+		if p.InviteViewedAt != nil {
+			report.Funnel.InviteViewed++
+		}
+		if p.Status == domain.ParticipantStatusNoShow {
+			report.NoShows.ParticipantIDs = append(report.NoShows.ParticipantIDs, p.ID)
+		}
+	}
+
+	return report, nil
+}
+
+// checkInReport resume os check-ins feitos em relação ao início do evento
+func checkInReport(event *domain.Event, participants []*domain.Participant) dto.ReportCheckIns {
+	var report dto.ReportCheckIns
+	var totalMinutes float64
+
+	for _, p := range participants {
+		if p.Status != domain.ParticipantStatusCheckedIn || p.CheckedInAt == nil {
+			continue
+		}
+		at := *p.CheckedInAt
+
+		report.Count++
+		if at.After(event.StartTime) {
+			report.Late++
+		} else {
+			report.OnTime++
+		}
+		if report.First == nil || at.Before(*report.First) {
+			report.First = &at
+		}
+		if report.Last == nil || at.After(*report.Last) {
+			report.Last = &at
+		}
+		totalMinutes += at.Sub(event.StartTime).Minutes()
+	}
+
+	if report.Count > 0 {
+		avg := roundMinutes(totalMinutes / float64(report.Count))
+		report.AverageMinutesFromStart = &avg
+	}
+	return report
+}
+
+// deliveryReport calcula as taxas de entrega a partir das contagens por status
+func deliveryReport(counts map[domain.DeliveryStatus]int64) dto.ReportNotifications {
+	report := dto.ReportNotifications{
+		Sent:      counts[domain.DeliveryStatusSent],
+		Delivered: counts[domain.DeliveryStatusDelivered],
+		Read:      counts[domain.DeliveryStatusRead],
+		Failed:    counts[domain.DeliveryStatusFailed],
+	}
+	report.Total = report.Sent + report.Delivered + report.Read + report.Failed
+
+	if report.Total > 0 {
+		total := float64(report.Total)
+		report.DeliveryRate = float64(report.Delivered+report.Read) / total
+		report.ReadRate = float64(report.Read) / total
+		report.FailureRate = float64(report.Failed) / total
+	}
+	return report
+}
+
+// etaAccuracyReport compara, para quem fez check-in, a chegada prevista a partir da primeira
+// localização (mesma estimativa das atualizações de ETA) com o horário real do check-in
+func etaAccuracyReport(event *domain.Event, participants []*domain.Participant, firstLocations []*domain.Location) dto.ReportETAAccuracy {
+	var report dto.ReportETAAccuracy
+	if !event.HasCoordinates() {
+		return report
+	}
+
+	byParticipant := make(map[uuid.UUID]*domain.Location, len(firstLocations))
+	for _, loc := range firstLocations {
+		byParticipant[loc.ParticipantID] = loc
+	}
+
+	var totalError float64
+	for _, p := range participants {
+		if p.CheckedInAt == nil {
+			continue
+		}
+		loc, ok := byParticipant[p.ID]
+		if !ok || loc.Timestamp.After(*p.CheckedInAt) {
+			continue
+		}
+
+		_, etaMinutes := estimateArrival(loc, event)
+		predicted := loc.Timestamp.Add(time.Duration(etaMinutes) * time.Minute)
+
+		report.Samples++
+		totalError += math.Abs(p.CheckedInAt.Sub(predicted).Minutes())
+	}
+
+	if report.Samples > 0 {
+		avg := roundMinutes(totalError / float64(report.Samples))
+		report.AverageErrorMinutes = &avg
+	}
+	return report
+}
+
+// roundMinutes arredonda para duas casas
+func roundMinutes(minutes float64) float64 {
+	return math.Round(minutes*100) / 100
+}
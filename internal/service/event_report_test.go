@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestReportParticipants devolve um participante por status da lista
+func newTestReportParticipants(statuses ...domain.ParticipantStatus) []*domain.Participant {
+	participants := make([]*domain.Participant, len(statuses))
+	for i, status := range statuses {
+		p := testutil.NewTestParticipant()
+		p.ID = uuid.New()
+		p.Status = status
+		participants[i] = p
+	}
+	return participants
+}
+
+func TestEventService_GenerateReport_ReconcilesWithEventStats(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := testutil.NewTestEvent()
+	event.StartTime = time.Now().Add(-3 * time.Hour)
+	participants := newTestReportParticipants(
+		domain.ParticipantStatusPending,
+		domain.ParticipantStatusConfirmed,
+		domain.ParticipantStatusDenied,
+		domain.ParticipantStatusCheckedIn,
+		domain.ParticipantStatusCheckedIn,
+		domain.ParticipantStatusNoShow,
+	)
+	early := event.StartTime.Add(-10 * time.Minute)
+	late := event.StartTime.Add(20 * time.Minute)
+	participants[3].CheckedInAt = &early
+	participants[4].CheckedInAt = &late
+
+	counts := map[domain.ParticipantStatus]int{}
+	for _, p := range participants {
+		counts[p.Status]++
+	}
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(participants, nil)
+	deps.deliveryRepo.On("CountByEventStatus", ctx, event.ID, event.EntityID).Return(map[domain.DeliveryStatus]int64{
+		domain.DeliveryStatusSent:      1,
+		domain.DeliveryStatusDelivered: 4,
+		domain.DeliveryStatusRead:      4,
+		domain.DeliveryStatusFailed:    1,
+	}, nil)
+	deps.locationRepo.On("GetFirstByEvent", ctx, event.ID, event.EntityID).Return([]*domain.Location{}, nil)
+
+	report, err := svc.GenerateReport(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+
+	// Os números do relatório batem com o EventStats das contagens por status
+	stats := domain.NewEventStatsFromCounts(counts)
+	assert.Equal(t, stats, report.Stats)
+	assert.Equal(t, stats.Total, report.Funnel.Invited)
+	assert.Equal(t, stats.Total-stats.Pending, report.Funnel.Responded)
+	assert.Equal(t, stats.Confirmed+stats.CheckedIn+stats.NoShow, report.Funnel.Confirmed)
+	assert.Equal(t, stats.CheckedIn, report.Funnel.CheckedIn)
+	assert.Equal(t, stats.CheckedIn, report.CheckIns.Count)
+	assert.Equal(t, stats.NoShow, report.NoShows.Count)
+	assert.Equal(t, []uuid.UUID{participants[5].ID}, report.NoShows.ParticipantIDs)
+	assert.Equal(t, stats.Confirmed, report.NoShows.ConfirmedWithoutCheckIn)
+
+	assert.Equal(t, 1, report.CheckIns.OnTime)
+	assert.Equal(t, 1, report.CheckIns.Late)
+	require.NotNil(t, report.CheckIns.AverageMinutesFromStart)
+	assert.Equal(t, 5.0, *report.CheckIns.AverageMinutesFromStart)
+
+	assert.Equal(t, int64(10), report.Notifications.Total)
+	assert.InDelta(t, 0.8, report.Notifications.DeliveryRate, 1e-9)
+	assert.InDelta(t, 0.4, report.Notifications.ReadRate, 1e-9)
+	assert.InDelta(t, 0.1, report.Notifications.FailureRate, 1e-9)
+
+	var csv bytes.Buffer
+	require.NoError(t, report.WriteCSV(&csv))
+	assert.Contains(t, csv.String(), "stats,total,6\n")
+	assert.Contains(t, csv.String(), "no_shows,participant_id,"+participants[5].ID.String()+"\n")
+	assert.True(t, strings.HasPrefix(csv.String(), "section,metric,value\n"))
+}
+
+func TestEventService_GenerateReport_ETAAccuracy(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	event := testutil.NewTestEvent()
+	event.LocationLat = -23.55
+	event.LocationLng = -46.63
+	participants := newTestReportParticipants(domain.ParticipantStatusCheckedIn, domain.ParticipantStatusCheckedIn)
+
+	// ~10 km a 30 km/h: chegada prevista 20 minutos depois da primeira localização
+	first := newTestParticipantLocation(participants[0].ID, -23.64, -46.63)
+	first.Timestamp = time.Now().Add(-time.Hour)
+	_, etaMinutes := estimateArrival(first, event)
+	checkedIn := first.Timestamp.Add(time.Duration(etaMinutes+6) * time.Minute)
+	participants[0].CheckedInAt = &checkedIn
+	// Sem localização: fica fora da amostra
+	participants[1].CheckedInAt = &checkedIn
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(participants, nil)
+	deps.deliveryRepo.On("CountByEventStatus", ctx, event.ID, event.EntityID).Return(map[domain.DeliveryStatus]int64{}, nil)
+	deps.locationRepo.On("GetFirstByEvent", ctx, event.ID, event.EntityID).Return([]*domain.Location{first}, nil)
+
+	report, err := svc.GenerateReport(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.ETAAccuracy.Samples)
+	require.NotNil(t, report.ETAAccuracy.AverageErrorMinutes)
+	assert.Equal(t, 6.0, *report.ETAAccuracy.AverageErrorMinutes)
+	assert.Zero(t, report.Notifications.DeliveryRate)
+}
@@ -1,34 +1,110 @@
 package service
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"event-coming/internal/config"
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
+	"event-coming/internal/geocoding"
 	"event-coming/internal/repository"
+	"event-coming/internal/storage"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 // EventService gerencia operações de eventos
 type EventService struct {
-	eventRepo       repository.EventRepository
-	schedulerRepo   repository.SchedulerRepository
-	participantRepo repository.ParticipantRepository
+	eventRepo        repository.EventRepository
+	entityRepo       repository.EntityRepository
+	userRepo         repository.UserRepository
+	schedulerRepo    repository.SchedulerRepository
+	participantRepo  repository.ParticipantRepository
+	attachmentRepo   repository.AttachmentRepository
+	groupRepo        repository.ParticipantGroupRepository
+	organizerRepo    repository.EventOrganizerRepository
+	locationRepo     repository.LocationRepository
+	deliveryRepo     repository.NotificationDeliveryRepository
+	transactor       repository.Transactor
+	eventCache       *EventCacheService
+	audit            *AuditService
+	webhooks         *WebhookDispatcher
+	participants     *ParticipantService
+	locations        *LocationService
+	redaction        *ParticipantRedactionService
+	eventConfig      *config.EventConfig
+	scheduling       *config.SchedulingConfig
+	storage          storage.Storage
+	attachmentLimits *config.AttachmentConfig
+	geocoder         geocoding.Geocoder
+	phones           *PhoneNormalizer
+	logger           *zap.Logger
 }
 
 // NewEventService cria um novo serviço de eventos
 func NewEventService(
 	eventRepo repository.EventRepository,
+	entityRepo repository.EntityRepository,
+	userRepo repository.UserRepository,
 	schedulerRepo repository.SchedulerRepository,
 	participantRepo repository.ParticipantRepository,
+	attachmentRepo repository.AttachmentRepository,
+	groupRepo repository.ParticipantGroupRepository,
+	organizerRepo repository.EventOrganizerRepository,
+	locationRepo repository.LocationRepository,
+	deliveryRepo repository.NotificationDeliveryRepository,
+	transactor repository.Transactor,
+	eventCache *EventCacheService,
+	audit *AuditService,
+	webhooks *WebhookDispatcher,
+	participants *ParticipantService,
+	locations *LocationService,
+	redaction *ParticipantRedactionService,
+	eventConfig *config.EventConfig,
+	scheduling *config.SchedulingConfig,
+	storage storage.Storage,
+	attachmentLimits *config.AttachmentConfig,
+	geocoder geocoding.Geocoder,
+	phones *PhoneNormalizer,
+	logger *zap.Logger,
 ) *EventService {
 	return &EventService{
-		eventRepo:       eventRepo,
-		schedulerRepo:   schedulerRepo,
-		participantRepo: participantRepo,
+		eventRepo:        eventRepo,
+		entityRepo:       entityRepo,
+		userRepo:         userRepo,
+		schedulerRepo:    schedulerRepo,
+		participantRepo:  participantRepo,
+		attachmentRepo:   attachmentRepo,
+		groupRepo:        groupRepo,
+		organizerRepo:    organizerRepo,
+		locationRepo:     locationRepo,
+		deliveryRepo:     deliveryRepo,
+		transactor:       transactor,
+		eventCache:       eventCache,
+		audit:            audit,
+		webhooks:         webhooks,
+		participants:     participants,
+		locations:        locations,
+		redaction:        redaction,
+		eventConfig:      eventConfig,
+		scheduling:       scheduling,
+		storage:          storage,
+		attachmentLimits: attachmentLimits,
+		geocoder:         geocoder,
+		phones:           phones,
+		logger:           logger,
 	}
 }
 
@@ -39,6 +115,20 @@ func (s *EventService) Create(ctx context.Context, entID, userID uuid.UUID, req
 		return nil, err
 	}
 
+	if err := s.validateEventType(ctx, entID, req.Type); err != nil {
+		return nil, err
+	}
+
+	if err := req.ResponseOptions.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !req.AllowDuplicate {
+		if err := s.checkDuplicate(ctx, entID, req.Name, req.StartTime); err != nil {
+			return nil, err
+		}
+	}
+
 	// Criar evento
 	event := &domain.Event{
 		ID:                   uuid.New(),
@@ -52,57 +142,255 @@ func (s *EventService) Create(ctx context.Context, entID, userID uuid.UUID, req
 		LocationAddress:      req.LocationAddress,
 		StartTime:            req.StartTime,
 		EndTime:              req.EndTime,
+		Timezone:             req.Timezone,
+		Locale:               req.Locale,
 		RRuleString:          req.RRuleString,
 		ConfirmationDeadline: req.ConfirmationDeadline,
+		ResponseOptions:      req.ResponseOptions,
+		MarkNoShows:          req.MarkNoShows,
+		Features:             req.Features,
 		CreatedBy:            userID,
-	}
 
-	if err := s.eventRepo.Create(ctx, event); err != nil {
-		return nil, fmt.Errorf("failed to create event: %w", err)
+		CheckInWindowBeforeMinutes: req.CheckInWindowBeforeMinutes,
+		CheckInWindowAfterMinutes:  req.CheckInWindowAfterMinutes,
+		Capacity:                   req.Capacity,
+	}
+	if event.Timezone == "" {
+		event.Timezone = s.eventConfig.DefaultTimezone
+	}
+	if event.Locale == "" {
+		event.Locale = s.eventConfig.DefaultLocale
+	}
+	if len(req.Locations) > 0 {
+		locations, err := s.newEventLocations(ctx, req.Locations)
+		if err != nil {
+			return nil, err
+		}
+		event.SetLocations(locations)
+	}
+	if !event.HasCoordinates() {
+		if lat, lng, ok := s.geocode(ctx, event.LocationAddress); ok {
+			event.LocationLat, event.LocationLng = lat, lng
+		}
 	}
 
-	response := dto.ToEventResponse(event)
+	var schedulersCreated int
+	var participants []*domain.Participant
 
-	// Criar schedulers
-	schedulersCreated := 0
-	if req.Scheduler != nil {
-		count, err := s.createSchedulers(ctx, entID, event, req.Scheduler)
+	// Evento, schedulers e participants são criados juntos ou nada é persistido
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.eventRepo.Create(ctx, event); err != nil {
+			return fmt.Errorf("failed to create event: %w", err)
+		}
+
+		// Criar schedulers
+		var count int
+		var err error
+		if req.Scheduler != nil {
+			count, err = s.createSchedulers(ctx, entID, event, req.Scheduler)
+		} else {
+			count, err = s.createDefaultSchedulers(ctx, entID, event)
+		}
 		if err != nil {
-			fmt.Printf("Warning: failed to create some schedulers: %v\n", err)
+			return fmt.Errorf("failed to create schedulers: %w", err)
 		}
 		schedulersCreated = count
-	} else {
-		count, _ := s.createDefaultSchedulers(ctx, entID, event)
-		schedulersCreated = count
+
+		// Criar participants
+		if len(req.Participants) > 0 {
+			participants, err = s.createParticipants(ctx, entID, event.ID, req.Participants)
+			if err != nil {
+				return fmt.Errorf("failed to create participants: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("Event creation rolled back",
+			zap.String("event_id", event.ID.String()),
+			zap.Error(err),
+		)
+		return nil, err
 	}
-	response.SchedulersCreated = schedulersCreated
 
-	// Criar participants
-	if len(req.Participants) > 0 {
-		participants, _ := s.createParticipants(ctx, entID, event.ID, req.Participants)
-		response.Participants = participants
+	// A resposta vem da linha gravada, com created_at/updated_at e os defaults do banco
+	persisted, err := s.eventRepo.GetByID(ctx, event.ID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load created event: %w", err)
 	}
 
+	s.audit.Record(ctx, entID, domain.AuditActionCreate, domain.AuditTargetEvent, persisted.ID, nil, persisted)
+	s.webhooks.Dispatch(ctx, entID, domain.WebhookEventEventCreated, persisted)
+
+	// Só depois do commit: um rollback não pode deixar confirmações no cache
+	s.syncParticipantsCache(ctx, persisted, participants...)
+
+	response := dto.ToEventResponse(persisted)
+	response.SchedulersCreated = schedulersCreated
+	for _, p := range participants {
+		response.Participants = append(response.Participants, dto.ToParticipantResponse(p))
+	}
 	return response, nil
 }
 
-// createSchedulers cria schedulers baseado na configuração
+// checkDuplicate rejeita um evento com mesmo nome (normalizado) de outro evento da
+// entidade que começa dentro da janela configurada, como um duplo clique na UI
+func (s *EventService) checkDuplicate(ctx context.Context, entID uuid.UUID, name string, startTime time.Time) error {
+	window := s.eventConfig.DuplicateWindow
+	if window <= 0 {
+		return nil
+	}
+
+	existing, err := s.eventRepo.FindDuplicate(ctx, entID, name, startTime.Add(-window), startTime.Add(window))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check duplicate event: %w", err)
+	}
+
+	return &domain.DuplicateEventError{ExistingEventID: existing.ID}
+}
+
+// validateEventType rejeita tipos personalizados que a entidade não configurou; os tipos
+// embutidos (demand, periodic) não precisam carregar a entidade
+func (s *EventService) validateEventType(ctx context.Context, entID uuid.UUID, eventType domain.EventType) error {
+	if eventType.IsBuiltin() {
+		return nil
+	}
+	entity, err := s.entityRepo.GetByID(ctx, entID)
+	if err != nil {
+		return fmt.Errorf("failed to load entity event types: %w", err)
+	}
+	if !entity.AllowsEventType(eventType) {
+		return domain.ErrUnknownEventType
+	}
+	return nil
+}
+
+// schedulerOffsets retorna a antecedência padrão dos schedulers configurada na entidade.
+// Se a entidade não puder ser carregada, usa o padrão do sistema em vez de falhar a criação do evento
+func (s *EventService) schedulerOffsets(ctx context.Context, entID uuid.UUID) domain.SchedulerOffsets {
+	entity, err := s.entityRepo.GetByID(ctx, entID)
+	if err != nil {
+		s.logger.Warn("Failed to load entity scheduler offsets, using defaults",
+			zap.String("entity_id", entID.String()),
+			zap.Error(err),
+		)
+	}
+	return entity.SchedulerOffsets()
+}
+
+// createSchedulers cria schedulers baseado na configuração; os horários explícitos do
+// evento têm precedência sobre a antecedência padrão da entidade
 func (s *EventService) createSchedulers(ctx context.Context, entID uuid.UUID, event *domain.Event, config *dto.SchedulerConfig) (int, error) {
 	var count int
-	var lastErr error
+	for _, planned := range s.planSchedulers(ctx, entID, event, config, time.Now()) {
+		if planned.SkipReason != "" {
+			s.logger.Warn("Scheduler skipped",
+				zap.String("event_id", event.ID.String()),
+				zap.String("action", string(planned.Action)),
+				zap.Time("computed_at", planned.ComputedAt),
+				zap.String("reason", planned.SkipReason),
+			)
+			continue
+		}
+		if planned.FiredLate {
+			s.logger.Info("Scheduler time already passed, firing once now",
+				zap.String("event_id", event.ID.String()),
+				zap.String("action", string(planned.Action)),
+				zap.Time("computed_at", planned.ComputedAt),
+			)
+		}
 
-	// Scheduler de confirmação
-	if config.SendConfirmation {
-		scheduledAt := event.StartTime.Add(-24 * time.Hour)
-		if config.ConfirmationTime != nil {
-			scheduledAt = *config.ConfirmationTime
+		if err := s.schedulerRepo.Create(ctx, planned.Scheduler); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Motivos para um scheduler calculado não ser criado
+const (
+	skipReasonInPast          = "scheduled_time_in_past"
+	skipReasonReminderTooLate = "reminder_lead_below_minimum"
+)
+
+// plannedScheduler é um scheduler calculado para o evento, já com as guardas de horário aplicadas
+type plannedScheduler struct {
+	*domain.Scheduler
+	ComputedAt time.Time // Horário calculado antes das guardas
+	FiredLate  bool      // Horário já tinha passado e a task roda uma vez, imediatamente
+	SkipReason string    // Preenchido quando o scheduler não deve ser criado
+}
+
+// planSchedulers calcula os schedulers do evento e aplica as guardas de horário
+func (s *EventService) planSchedulers(ctx context.Context, entID uuid.UUID, event *domain.Event, schedulerConfig *dto.SchedulerConfig, now time.Time) []*plannedScheduler {
+	schedulers := buildSchedulers(entID, event, schedulerConfig, s.schedulerOffsets(ctx, entID))
+	return applySchedulingGuards(schedulers, event, s.scheduling, now)
+}
+
+// applySchedulingGuards evita que um evento criado em cima da hora dispare em massa
+// schedulers com horário no passado: lembretes respeitam a antecedência mínima/máxima e
+// horários vencidos são pulados ou disparados uma única vez, conforme a política.
+// O fechamento e o resumo final do organizador nunca são afetados
+func applySchedulingGuards(schedulers []*domain.Scheduler, event *domain.Event, guards *config.SchedulingConfig, now time.Time) []*plannedScheduler {
+	planned := make([]*plannedScheduler, len(schedulers))
+	for i, scheduler := range schedulers {
+		p := &plannedScheduler{Scheduler: scheduler, ComputedAt: scheduler.ScheduledAt}
+		planned[i] = p
+
+		if guards == nil || scheduler.Action == domain.SchedulerActionClosure || scheduler.Action == domain.SchedulerActionOrganizerWrapUp {
+			continue
 		}
 
-		scheduler := &domain.Scheduler{
+		if scheduler.Action == domain.SchedulerActionReminder {
+			lead := event.StartTime.Sub(scheduler.ScheduledAt)
+			if lead < guards.MinReminderLead {
+				p.SkipReason = skipReasonReminderTooLate
+				continue
+			}
+			if guards.MaxReminderLead > 0 && lead > guards.MaxReminderLead {
+				scheduler.ScheduledAt = event.StartTime.Add(-guards.MaxReminderLead)
+			}
+		}
+
+		if !scheduler.ScheduledAt.Before(now) {
+			continue
+		}
+
+		if guards.PastPolicy == config.PastSchedulerFireOnce {
+			scheduler.ScheduledAt = now
+			scheduler.MaxRetries = 1
+			scheduler.Metadata["fired_late"] = true
+			p.FiredLate = true
+			continue
+		}
+		p.SkipReason = skipReasonInPast
+	}
+	return planned
+}
+
+// buildSchedulers calcula os schedulers do evento sem persistir. É a mesma conta usada
+// na criação e no preview, para que os dois nunca divirjam
+func buildSchedulers(entID uuid.UUID, event *domain.Event, config *dto.SchedulerConfig, offsets domain.SchedulerOffsets) []*domain.Scheduler {
+	var schedulers []*domain.Scheduler
+
+	// Ações cujo recurso está desligado no evento não geram scheduler
+	add := func(scheduler *domain.Scheduler) {
+		if event.Features.Allows(scheduler.Action) {
+			schedulers = append(schedulers, scheduler)
+		}
+	}
+
+	newScheduler := func(action domain.SchedulerAction, scheduledAt time.Time) *domain.Scheduler {
+		return &domain.Scheduler{
 			ID:          uuid.New(),
 			EntityID:    entID,
 			EventID:     event.ID,
-			Action:      domain.SchedulerActionConfirmation,
+			Action:      action,
 			Status:      domain.SchedulerStatusPending,
 			ScheduledAt: scheduledAt,
 			MaxRetries:  3,
@@ -110,131 +398,156 @@ func (s *EventService) createSchedulers(ctx context.Context, entID uuid.UUID, ev
 				"event_name": event.Name,
 			},
 		}
+	}
 
-		if err := s.schedulerRepo.Create(ctx, scheduler); err != nil {
-			lastErr = err
-		} else {
-			count++
+	// Scheduler de confirmação
+	if config.SendConfirmation {
+		scheduledAt := event.StartTime.Add(-offsets.Confirmation)
+		if config.ConfirmationTime != nil {
+			scheduledAt = *config.ConfirmationTime
 		}
+		add(newScheduler(domain.SchedulerActionConfirmation, scheduledAt))
 	}
 
 	// Scheduler de lembrete
 	if config.SendReminder {
-		scheduledAt := event.StartTime.Add(-2 * time.Hour)
+		scheduledAt := event.StartTime.Add(-offsets.Reminder)
 		if config.ReminderTime != nil {
 			scheduledAt = *config.ReminderTime
 		} else if config.ReminderBeforeHours != nil {
 			scheduledAt = event.StartTime.Add(-time.Duration(*config.ReminderBeforeHours) * time.Hour)
 		}
-
-		scheduler := &domain.Scheduler{
-			ID:          uuid.New(),
-			EntityID:    entID,
-			EventID:     event.ID,
-			Action:      domain.SchedulerActionReminder,
-			Status:      domain.SchedulerStatusPending,
-			ScheduledAt: scheduledAt,
-			MaxRetries:  3,
-			Metadata: map[string]interface{}{
-				"event_name": event.Name,
-			},
-		}
-
-		if err := s.schedulerRepo.Create(ctx, scheduler); err != nil {
-			lastErr = err
-		} else {
-			count++
-		}
+		add(newScheduler(domain.SchedulerActionReminder, scheduledAt))
 	}
 
 	// Scheduler de rastreamento de localização
 	if config.TrackLocation {
-		scheduledAt := event.StartTime.Add(-1 * time.Hour)
+		scheduledAt := event.StartTime.Add(-offsets.Location)
 		if config.LocationTrackingTime != nil {
 			scheduledAt = *config.LocationTrackingTime
 		}
-
-		scheduler := &domain.Scheduler{
-			ID:          uuid.New(),
-			EntityID:    entID,
-			EventID:     event.ID,
-			Action:      domain.SchedulerActionLocation,
-			Status:      domain.SchedulerStatusPending,
-			ScheduledAt: scheduledAt,
-			MaxRetries:  3,
-			Metadata: map[string]interface{}{
-				"event_name":   event.Name,
-				"location_lat": event.LocationLat,
-				"location_lng": event.LocationLng,
-			},
-		}
-
-		if err := s.schedulerRepo.Create(ctx, scheduler); err != nil {
-			lastErr = err
-		} else {
-			count++
-		}
+		scheduler := newScheduler(domain.SchedulerActionLocation, scheduledAt)
+		scheduler.Metadata["location_lat"] = event.LocationLat
+		scheduler.Metadata["location_lng"] = event.LocationLng
+		add(scheduler)
 	}
 
 	// Scheduler de fechamento (sempre criar)
-	closureScheduler := &domain.Scheduler{
-		ID:          uuid.New(),
-		EntityID:    entID,
-		EventID:     event.ID,
-		Action:      domain.SchedulerActionClosure,
-		Status:      domain.SchedulerStatusPending,
-		ScheduledAt: event.StartTime,
-		MaxRetries:  3,
-		Metadata: map[string]interface{}{
-			"event_name": event.Name,
-		},
-	}
+	closureAt := event.StartTime
 	if event.EndTime != nil {
-		closureScheduler.ScheduledAt = *event.EndTime
+		closureAt = *event.EndTime
 	}
+	add(newScheduler(domain.SchedulerActionClosure, closureAt))
 
-	if err := s.schedulerRepo.Create(ctx, closureScheduler); err != nil {
-		lastErr = err
-	} else {
-		count++
+	// Resumos para o organizador (opt-in); sem antecedência explícita, usa a do lembrete
+	if config.NotifyOrganizer {
+		summaryAt := event.StartTime.Add(-offsets.Reminder)
+		if config.OrganizerSummaryBeforeHours != nil {
+			summaryAt = event.StartTime.Add(-time.Duration(*config.OrganizerSummaryBeforeHours) * time.Hour)
+		}
+		add(newScheduler(domain.SchedulerActionOrganizerSummary, summaryAt))
+		add(newScheduler(domain.SchedulerActionOrganizerWrapUp, closureAt.Add(organizerWrapUpDelay)))
 	}
 
-	return count, lastErr
+	return schedulers
 }
 
-// createDefaultSchedulers cria schedulers padrão para um evento
-func (s *EventService) createDefaultSchedulers(ctx context.Context, entID uuid.UUID, event *domain.Event) (int, error) {
-	config := &dto.SchedulerConfig{
+// organizerWrapUpDelay é quanto depois do fechamento o organizador recebe o resumo final,
+// para que os no_show marcados no fechamento já entrem na contagem
+const organizerWrapUpDelay = 15 * time.Minute
+
+// defaultSchedulerConfig é a configuração usada quando o evento não informa a sua
+func defaultSchedulerConfig() *dto.SchedulerConfig {
+	return &dto.SchedulerConfig{
 		SendConfirmation: true,
 		SendReminder:     true,
 		TrackLocation:    true,
 	}
-	return s.createSchedulers(ctx, entID, event, config)
+}
+
+// createDefaultSchedulers cria schedulers padrão para um evento
+func (s *EventService) createDefaultSchedulers(ctx context.Context, entID uuid.UUID, event *domain.Event) (int, error) {
+	return s.createSchedulers(ctx, entID, event, defaultSchedulerConfig())
+}
+
+// PreviewSchedule calcula quando cada scheduler dispararia para o evento informado, sem
+// persistir nada, para o organizador conferir horários (fuso, horário de verão, horários no passado)
+func (s *EventService) PreviewSchedule(ctx context.Context, entID uuid.UUID, req *dto.CreateEventRequest) ([]dto.ScheduledTaskPreview, error) {
+	if err := s.validateEventTimes(req.StartTime, req.EndTime, req.ConfirmationDeadline); err != nil {
+		return nil, err
+	}
+
+	event := &domain.Event{
+		EntityID:    entID,
+		Name:        req.Name,
+		LocationLat: req.LocationLat,
+		LocationLng: req.LocationLng,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Features:    req.Features,
+	}
+
+	schedulerConfig := req.Scheduler
+	if schedulerConfig == nil {
+		schedulerConfig = defaultSchedulerConfig()
+	}
+
+	now := time.Now()
+	planned := s.planSchedulers(ctx, entID, event, schedulerConfig, now)
+	previews := make([]dto.ScheduledTaskPreview, len(planned))
+	for i, p := range planned {
+		previews[i] = dto.ScheduledTaskPreview{
+			Action:      p.Action,
+			ScheduledAt: p.ScheduledAt,
+			InPast:      p.ComputedAt.Before(now),
+			FiredLate:   p.FiredLate,
+			Skipped:     p.SkipReason != "",
+			SkipReason:  p.SkipReason,
+		}
+	}
+	return previews, nil
 }
 
 // createParticipants cria participants para o evento
-func (s *EventService) createParticipants(ctx context.Context, entID, eventID uuid.UUID, inputs []dto.ParticipantInput) ([]*dto.ParticipantResponse, error) {
-	var participants []*dto.ParticipantResponse
-	var lastErr error
+func (s *EventService) createParticipants(ctx context.Context, entID, eventID uuid.UUID, inputs []dto.ParticipantInput) ([]*domain.Participant, error) {
+	var participants []*domain.Participant
+	region := s.phones.Region(ctx, entID)
 
 	for _, input := range inputs {
 		participant := &domain.Participant{
-			ID:       uuid.New(),
-			EventID:  eventID,
-			EntityID: entID,
-			Status:   domain.ParticipantStatusPending,
-			Metadata: input.Metadata,
+			ID:          uuid.New(),
+			EventID:     eventID,
+			EntityID:    entID,
+			Status:      domain.ParticipantStatusPending,
+			Metadata:    input.Metadata,
+			PhoneNumber: domain.NormalizePhoneNumberForRegion(input.PhoneNumber, region),
 		}
 
 		if err := s.participantRepo.Create(ctx, participant); err != nil {
-			lastErr = err
-			continue
+			return participants, err
 		}
 
-		participants = append(participants, dto.ToParticipantResponse(participant))
+		participants = append(participants, participant)
 	}
 
-	return participants, lastErr
+	return participants, nil
+}
+
+// syncParticipantsCache grava no cache do evento a confirmação dos participantes criados
+// fora do ParticipantService, que faz o mesmo a cada mudança (best effort)
+func (s *EventService) syncParticipantsCache(ctx context.Context, event *domain.Event, participants ...*domain.Participant) {
+	if s.eventCache == nil {
+		return
+	}
+	for _, p := range participants {
+		if err := s.eventCache.SetConfirmation(ctx, event.EntityID, event.ID, p, event.EndTime); err != nil {
+			s.logger.Warn("Failed to cache participant confirmation",
+				zap.String("event_id", event.ID.String()),
+				zap.String("participant_id", p.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
 // GetByID busca um evento por ID
@@ -266,13 +579,48 @@ func (s *EventService) GetByIDWithParticipants(ctx context.Context, entID, event
 	return response, nil
 }
 
+// ParticipantForUser resolve o participante do evento que corresponde ao usuário, pelo
+// telefone do cadastro. Sem telefone ou sem participação no evento retorna ErrNotFound
+func (s *EventService) ParticipantForUser(ctx context.Context, entID, eventID, userID uuid.UUID) (*domain.Participant, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Phone == nil || *user.Phone == "" {
+		return nil, domain.ErrNotFound
+	}
+	return s.participantRepo.GetByPhoneNumber(ctx, *user.Phone, eventID, entID)
+}
+
+// geocode resolve as coordenadas do endereço do evento. Sem geocoder configurado, sem
+// endereço ou se a busca falhar retorna ok=false e o evento segue sem coordenadas
+func (s *EventService) geocode(ctx context.Context, address *string) (lat, lng float64, ok bool) {
+	if s.geocoder == nil || address == nil || strings.TrimSpace(*address) == "" {
+		return 0, 0, false
+	}
+
+	lat, lng, err := s.geocoder.Geocode(ctx, *address)
+	if err != nil {
+		s.logger.Warn("Failed to geocode event address",
+			zap.String("address", *address),
+			zap.Error(err),
+		)
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
 // Update atualiza um evento
 func (s *EventService) Update(ctx context.Context, entID, eventID uuid.UUID, req *dto.UpdateEventRequest) (*dto.EventResponse, error) {
-	_, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	existing, err := s.eventRepo.GetByID(ctx, eventID, entID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := req.ResponseOptions.Validate(); err != nil {
+		return nil, err
+	}
+
 	input := &domain.UpdateEventInput{
 		Name:                 req.Name,
 		Description:          req.Description,
@@ -282,7 +630,58 @@ func (s *EventService) Update(ctx context.Context, entID, eventID uuid.UUID, req
 		LocationAddress:      req.LocationAddress,
 		StartTime:            req.StartTime,
 		EndTime:              req.EndTime,
+		Timezone:             req.Timezone,
+		Locale:               req.Locale,
 		ConfirmationDeadline: req.ConfirmationDeadline,
+		ResponseOptions:      req.ResponseOptions,
+		MarkNoShows:          req.MarkNoShows,
+		ExpectedVersion:      req.Version,
+
+		ClearDescription:          req.IsNull("description"),
+		ClearLocationAddress:      req.IsNull("location_address"),
+		ClearEndTime:              req.IsNull("end_time"),
+		ClearConfirmationDeadline: req.IsNull("confirmation_deadline"),
+
+		CheckInWindowBeforeMinutes: req.CheckInWindowBeforeMinutes,
+		CheckInWindowAfterMinutes:  req.CheckInWindowAfterMinutes,
+		ClearCheckInWindowBefore:   req.IsNull("check_in_window_before_minutes"),
+		ClearCheckInWindowAfter:    req.IsNull("check_in_window_after_minutes"),
+
+		Capacity:      req.Capacity,
+		ClearCapacity: req.IsNull("capacity"),
+	}
+
+	if req.Features != nil {
+		features := existing.Features.Merge(*req.Features)
+		input.Features = &features
+	}
+
+	// Endereço novo sem coordenadas: as antigas não valem mais para o novo endereço. Se
+	// não der para geocodificar, o evento fica sem coordenadas (0, 0) em vez de apontar
+	// para o endereço anterior
+	if req.LocationAddress != nil && req.LocationLat == nil && req.LocationLng == nil &&
+		(existing.LocationAddress == nil || *existing.LocationAddress != *req.LocationAddress) {
+		lat, lng, _ := s.geocode(ctx, req.LocationAddress)
+		input.LocationLat, input.LocationLng = &lat, &lng
+	}
+
+	// Com vários locais, o local único é o principal: mudá-lo muda o primeiro da lista
+	if len(existing.Locations) > 0 && (input.LocationLat != nil || input.LocationLng != nil ||
+		input.LocationAddress != nil || input.ClearLocationAddress) {
+		locations := slices.Clone(existing.Locations)
+		primary := &locations[0]
+		if input.LocationLat != nil {
+			primary.Lat = *input.LocationLat
+		}
+		if input.LocationLng != nil {
+			primary.Lng = *input.LocationLng
+		}
+		if input.ClearLocationAddress {
+			primary.Address = nil
+		} else if input.LocationAddress != nil {
+			primary.Address = input.LocationAddress
+		}
+		input.Locations = locations
 	}
 
 	if err := s.eventRepo.Update(ctx, eventID, entID, input); err != nil {
@@ -294,12 +693,29 @@ func (s *EventService) Update(ctx context.Context, entID, eventID uuid.UUID, req
 		return nil, err
 	}
 
+	action := domain.AuditActionUpdate
+	if updated.Status != existing.Status {
+		action = domain.AuditActionStatusChange
+	}
+	s.audit.Record(ctx, entID, action, domain.AuditTargetEvent, eventID, existing, updated)
+	s.webhooks.DispatchEventStatus(ctx, existing, updated)
+
 	return dto.ToEventResponse(updated), nil
 }
 
 // Delete remove um evento
 func (s *EventService) Delete(ctx context.Context, entID, eventID uuid.UUID) error {
-	return s.eventRepo.Delete(ctx, eventID, entID)
+	existing, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.eventRepo.Delete(ctx, eventID, entID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, entID, domain.AuditActionDelete, domain.AuditTargetEvent, eventID, existing, nil)
+	return nil
 }
 
 // List lista eventos de uma organização
@@ -317,6 +733,49 @@ func (s *EventService) List(ctx context.Context, entID uuid.UUID, page, perPage
 	return responses, total, nil
 }
 
+// ListForParticipant lista os eventos, de qualquer entidade, em que o telefone é
+// participante, com o status da participação em cada um
+func (s *EventService) ListForParticipant(ctx context.Context, phone string, page, perPage int) ([]*dto.AttendeeEventResponse, int64, error) {
+	// Mesma normalização da gravação dos participantes
+	phone = s.phones.NormalizeDefault(phone)
+	if phone == "" {
+		return nil, 0, domain.ErrInvalidInput
+	}
+
+	events, total, err := s.eventRepo.ListByParticipantPhone(ctx, phone, page, perPage)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list participant events: %w", err)
+	}
+
+	responses := make([]*dto.AttendeeEventResponse, len(events))
+	for i, e := range events {
+		responses[i] = &dto.AttendeeEventResponse{
+			EventResponse:     dto.ToEventResponse(&e.Event),
+			ParticipantID:     e.ParticipantID,
+			ParticipantStatus: e.ParticipantStatus,
+		}
+	}
+
+	return responses, total, nil
+}
+
+// ListForUser lista os eventos em que o usuário participa, pelo telefone do seu cadastro.
+// Só telefone verificado vale: sem ele qualquer um veria os eventos de um número alheio
+func (s *EventService) ListForUser(ctx context.Context, userID uuid.UUID, page, perPage int) ([]*dto.AttendeeEventResponse, int64, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if user == nil {
+		return nil, 0, domain.ErrNotFound
+	}
+	if !user.PhoneVerified || user.Phone == nil || domain.NormalizePhoneNumber(*user.Phone) == "" {
+		return []*dto.AttendeeEventResponse{}, 0, nil
+	}
+
+	return s.ListForParticipant(ctx, *user.Phone, page, perPage)
+}
+
 // ListByStatus lista eventos por status
 func (s *EventService) ListByStatus(ctx context.Context, entID uuid.UUID, status domain.EventStatus, page, perPage int) ([]*dto.EventResponse, int64, error) {
 	events, total, err := s.eventRepo.ListByStatus(ctx, entID, status, page, perPage)
@@ -335,7 +794,17 @@ func (s *EventService) ListByStatus(ctx context.Context, entID uuid.UUID, status
 // Activate ativa um evento
 func (s *EventService) Activate(ctx context.Context, entID, eventID uuid.UUID) (*dto.EventResponse, error) {
 	status := domain.EventStatusActive
-	return s.Update(ctx, entID, eventID, &dto.UpdateEventRequest{Status: &status})
+	resp, err := s.Update(ctx, entID, eventID, &dto.UpdateEventRequest{Status: &status})
+	if err != nil {
+		return nil, err
+	}
+
+	// Pré-carrega o cache do dashboard (best effort, o cache também é aquecido na primeira leitura)
+	if s.eventCache != nil {
+		_ = s.eventCache.Warm(ctx, entID, eventID)
+	}
+
+	return resp, nil
 }
 
 // Cancel cancela um evento
@@ -350,27 +819,199 @@ func (s *EventService) Complete(ctx context.Context, entID, eventID uuid.UUID) (
 	return s.Update(ctx, entID, eventID, &dto.UpdateEventRequest{Status: &status})
 }
 
+// RedactParticipants apaga, de forma irreversível, os dados pessoais dos participantes de
+// um evento concluído ou cancelado, mantendo status e contagens para os relatórios
+func (s *EventService) RedactParticipants(ctx context.Context, entID, eventID uuid.UUID) (*dto.ParticipantRedactionResponse, error) {
+	return s.redaction.Redact(ctx, entID, eventID)
+}
+
+// AddAttachment valida e armazena um arquivo anexado ao evento. O content type é
+// detectado a partir do conteúdo; o informado pelo cliente é ignorado.
+func (s *EventService) AddAttachment(ctx context.Context, entID, eventID, userID uuid.UUID, filename string, size int64, r io.Reader) (*dto.AttachmentResponse, error) {
+	if size > s.attachmentLimits.MaxSize {
+		return nil, domain.ErrAttachmentTooLarge
+	}
+
+	if _, err := s.eventRepo.GetByID(ctx, eventID, entID); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !slices.Contains(s.attachmentLimits.AllowedContentTypes, contentType) {
+		return nil, domain.ErrUnsupportedContentType
+	}
+
+	attachment := &domain.Attachment{
+		ID:          uuid.New(),
+		EntityID:    entID,
+		EventID:     eventID,
+		Filename:    filepath.Base(filename),
+		ContentType: contentType,
+		Size:        size,
+		UploadedBy:  userID,
+	}
+	attachment.StorageKey = path.Join("events", eventID.String(), attachment.ID.String())
+
+	if err := s.storage.Put(ctx, attachment.StorageKey, br, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		// Não deixar o arquivo órfão no storage
+		if delErr := s.storage.Delete(context.WithoutCancel(ctx), attachment.StorageKey); delErr != nil {
+			s.logger.Warn("Failed to remove orphaned attachment",
+				zap.String("storage_key", attachment.StorageKey),
+				zap.Error(delErr),
+			)
+		}
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	s.audit.Record(ctx, entID, domain.AuditActionCreate, domain.AuditTargetAttachment, attachment.ID, nil, attachment)
+
+	return dto.ToAttachmentResponse(attachment), nil
+}
+
+// MaxAttachmentSize retorna o tamanho máximo aceito para anexos, em bytes
+func (s *EventService) MaxAttachmentSize() int64 {
+	return s.attachmentLimits.MaxSize
+}
+
+// ListAttachments lista os anexos de um evento
+func (s *EventService) ListAttachments(ctx context.Context, entID, eventID uuid.UUID) ([]*dto.AttachmentResponse, error) {
+	if _, err := s.eventRepo.GetByID(ctx, eventID, entID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.ListByEvent(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	responses := make([]*dto.AttachmentResponse, len(attachments))
+	for i, a := range attachments {
+		responses[i] = dto.ToAttachmentResponse(a)
+	}
+	return responses, nil
+}
+
+// OpenAttachment retorna o anexo e um reader do seu conteúdo; o chamador deve fechá-lo
+func (s *EventService) OpenAttachment(ctx context.Context, entID, eventID, attachmentID uuid.UUID) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.attachmentRepo.GetByID(ctx, attachmentID, eventID, entID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, domain.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	return attachment, content, nil
+}
+
+// RemoveAttachment remove um anexo do evento e seu conteúdo do storage
+func (s *EventService) RemoveAttachment(ctx context.Context, entID, eventID, attachmentID uuid.UUID) error {
+	attachment, err := s.attachmentRepo.GetByID(ctx, attachmentID, eventID, entID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, attachmentID, entID); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	// O registro já foi removido; falha no storage só deixa um arquivo órfão
+	if err := s.storage.Delete(ctx, attachment.StorageKey); err != nil {
+		s.logger.Warn("Failed to remove attachment from storage",
+			zap.String("attachment_id", attachmentID.String()),
+			zap.String("storage_key", attachment.StorageKey),
+			zap.Error(err),
+		)
+	}
+
+	s.audit.Record(ctx, entID, domain.AuditActionDelete, domain.AuditTargetAttachment, attachmentID, attachment, nil)
+	return nil
+}
+
+// ScheduleBroadcast agenda um broadcast aos confirmados do evento ou, com group_id, só aos
+// confirmados do grupo. O worker envia a mensagem no idioma do evento
+func (s *EventService) ScheduleBroadcast(ctx context.Context, entID, eventID uuid.UUID, req *dto.ScheduleBroadcastRequest) (*domain.Scheduler, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.Features.NotificationsEnabled() {
+		return nil, domain.ErrFeatureDisabled
+	}
+
+	metadata := map[string]interface{}{
+		domain.BroadcastMetadataMessage: req.Message,
+	}
+	if req.GroupID != nil {
+		if _, err := s.groupRepo.GetByID(ctx, *req.GroupID, eventID, entID); err != nil {
+			return nil, err
+		}
+		metadata[domain.BroadcastMetadataGroupID] = req.GroupID.String()
+	}
+
+	scheduledAt := time.Now()
+	if req.ScheduledAt != nil && req.ScheduledAt.After(scheduledAt) {
+		scheduledAt = *req.ScheduledAt
+	}
+
+	scheduler := &domain.Scheduler{
+		ID:          uuid.New(),
+		EntityID:    entID,
+		EventID:     eventID,
+		Action:      domain.SchedulerActionBroadcast,
+		Status:      domain.SchedulerStatusPending,
+		ScheduledAt: scheduledAt,
+		MaxRetries:  3,
+		Metadata:    metadata,
+	}
+	if err := s.schedulerRepo.Create(ctx, scheduler); err != nil {
+		return nil, fmt.Errorf("failed to schedule broadcast: %w", err)
+	}
+
+	s.logger.Info("Broadcast scheduled",
+		zap.String("event_id", eventID.String()),
+		zap.String("scheduler_id", scheduler.ID.String()),
+		zap.Time("scheduled_at", scheduledAt),
+	)
+
+	return scheduler, nil
+}
+
 // validateEventTimes validates event time constraints
 func (s *EventService) validateEventTimes(startTime time.Time, endTime, confirmationDeadline *time.Time) error {
 	now := time.Now()
 
 	// StartTime must be in the future
 	if startTime.Before(now) {
-		return fmt.Errorf("start_time must be in the future")
+		return fmt.Errorf("start_time must be in the future: %w", domain.ErrInvalidInput)
 	}
 
 	// EndTime must be after StartTime if provided
 	if endTime != nil && !endTime.After(startTime) {
-		return fmt.Errorf("end_time must be after start_time")
+		return fmt.Errorf("end_time must be after start_time: %w", domain.ErrInvalidInput)
 	}
 
 	// ConfirmationDeadline must be before StartTime if provided
 	if confirmationDeadline != nil {
 		if confirmationDeadline.After(startTime) {
-			return fmt.Errorf("confirmation_deadline must be before start_time")
+			return fmt.Errorf("confirmation_deadline must be before start_time: %w", domain.ErrInvalidInput)
 		}
 		if confirmationDeadline.Before(now) {
-			return fmt.Errorf("confirmation_deadline must be in the future")
+			return fmt.Errorf("confirmation_deadline must be in the future: %w", domain.ErrInvalidInput)
 		}
 	}
 
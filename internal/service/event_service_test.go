@@ -0,0 +1,973 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/geocoding"
+	"event-coming/internal/storage"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type txContextKey struct{}
+
+// recordingTransactor marca o contexto da transação e registra commits e rollbacks
+type recordingTransactor struct {
+	committed  int
+	rolledBack int
+}
+
+func (r *recordingTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(context.WithValue(ctx, txContextKey{}, true)); err != nil {
+		r.rolledBack++
+		return err
+	}
+	r.committed++
+	return nil
+}
+
+// inTx casa com contextos dentro de uma transação do recordingTransactor
+var inTx = mock.MatchedBy(func(ctx context.Context) bool {
+	return ctx.Value(txContextKey{}) == true
+})
+
+type eventServiceDeps struct {
+	eventRepo       *mocks.MockEventRepository
+	entityRepo      *mocks.MockEntityRepository
+	userRepo        *mocks.MockUserRepository
+	schedulerRepo   *mocks.MockSchedulerRepository
+	participantRepo *mocks.MockParticipantRepository
+	attachmentRepo  *mocks.MockAttachmentRepository
+	groupRepo       *mocks.MockParticipantGroupRepository
+	organizerRepo   *mocks.MockEventOrganizerRepository
+	locationRepo    *mocks.MockLocationRepository
+	deliveryRepo    *mocks.MockNotificationDeliveryRepository
+	transactor      *recordingTransactor
+	storage         *storage.MemoryStorage
+	logs            *observer.ObservedLogs
+}
+
+func newTestEventService() (*EventService, *eventServiceDeps) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	deps := &eventServiceDeps{
+		eventRepo:       new(mocks.MockEventRepository),
+		entityRepo:      new(mocks.MockEntityRepository),
+		userRepo:        new(mocks.MockUserRepository),
+		schedulerRepo:   new(mocks.MockSchedulerRepository),
+		participantRepo: new(mocks.MockParticipantRepository),
+		attachmentRepo:  new(mocks.MockAttachmentRepository),
+		groupRepo:       new(mocks.MockParticipantGroupRepository),
+		organizerRepo:   new(mocks.MockEventOrganizerRepository),
+		locationRepo:    new(mocks.MockLocationRepository),
+		deliveryRepo:    new(mocks.MockNotificationDeliveryRepository),
+		transactor:      &recordingTransactor{},
+		storage:         storage.NewMemoryStorage(),
+		logs:            logs,
+	}
+	attachments := &config.AttachmentConfig{MaxSize: 1024, AllowedContentTypes: []string{"application/pdf", "image/png"}}
+	svc := NewEventService(deps.eventRepo, deps.entityRepo, deps.userRepo, deps.schedulerRepo, deps.participantRepo, deps.attachmentRepo, deps.groupRepo, deps.organizerRepo, deps.locationRepo, deps.deliveryRepo, deps.transactor, nil, nil, nil, nil, nil, nil, &config.EventConfig{}, nil, deps.storage, attachments, nil, nil, zap.New(core))
+	return svc, deps
+}
+
+func newTestCreateEventRequest() *dto.CreateEventRequest {
+	start := time.Now().Add(48 * time.Hour)
+	end := start.Add(2 * time.Hour)
+	return &dto.CreateEventRequest{
+		Name:        "Test Event",
+		Type:        domain.EventTypeDemand,
+		LocationLat: -23.55,
+		LocationLng: -46.63,
+		StartTime:   start,
+		EndTime:     &end,
+	}
+}
+
+func TestEventService_Create_CommitsEverythingInOneTransaction(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	eventCache, cacheDeps := newTestEventCacheService(t)
+	svc.eventCache = eventCache
+
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+	deps.participantRepo.On("Create", inTx, mock.AnythingOfType("*domain.Participant")).Return(nil)
+
+	// A resposta vem da linha gravada
+	persisted := testutil.NewTestEvent()
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(persisted, nil)
+
+	req := newTestCreateEventRequest()
+	req.Participants = []dto.ParticipantInput{{Name: "Maria", PhoneNumber: "+5511999999999"}}
+
+	resp, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+	assert.Equal(t, persisted.ID, resp.ID)
+	assert.Equal(t, persisted.CreatedAt, resp.CreatedAt)
+	assert.Equal(t, 4, resp.SchedulersCreated)
+	assert.Len(t, resp.Participants, 1)
+	assert.Equal(t, 1, deps.transactor.committed)
+	assert.Zero(t, deps.transactor.rolledBack)
+
+	// O participante criado com o evento entra no cache de confirmações depois do commit
+	assert.True(t, cacheDeps.redis.Exists(confirmationKey(persisted.EntityID, persisted.ID, resp.Participants[0].ID)))
+}
+
+func TestEventService_Create_SchedulerFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(errors.New("database down")).Once()
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+
+	resp, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, newTestCreateEventRequest())
+	require.Error(t, err)
+	assert.Nil(t, resp)
+
+	// O evento foi criado dentro da transação desfeita, então não é persistido
+	deps.eventRepo.AssertCalled(t, "Create", inTx, mock.AnythingOfType("*domain.Event"))
+	assert.Zero(t, deps.transactor.committed)
+	assert.Equal(t, 1, deps.transactor.rolledBack)
+
+	entries := deps.logs.FilterMessage("Event creation rolled back").All()
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].ContextMap()["error"], "database down")
+}
+
+func TestEventService_Create_ParticipantFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+	deps.participantRepo.On("Create", inTx, mock.AnythingOfType("*domain.Participant")).Return(nil).Once()
+	deps.participantRepo.On("Create", inTx, mock.AnythingOfType("*domain.Participant")).Return(errors.New("database down"))
+
+	req := newTestCreateEventRequest()
+	req.Participants = []dto.ParticipantInput{
+		{Name: "Maria", PhoneNumber: "+5511999999999"},
+		{Name: "João", PhoneNumber: "+5511888888888"},
+	}
+
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.Error(t, err)
+	assert.Zero(t, deps.transactor.committed)
+	assert.Equal(t, 1, deps.transactor.rolledBack)
+	assert.Len(t, deps.logs.FilterMessage("Event creation rolled back").All(), 1)
+}
+
+// captureSchedulers grava os schedulers criados por ação
+func captureSchedulers(deps *eventServiceDeps) map[domain.SchedulerAction]*domain.Scheduler {
+	created := map[domain.SchedulerAction]*domain.Scheduler{}
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).
+		Run(func(args mock.Arguments) {
+			scheduler := args.Get(1).(*domain.Scheduler)
+			created[scheduler.Action] = scheduler
+		}).
+		Return(nil)
+	return created
+}
+
+func TestEventService_Create_UsesEntitySchedulerOffsets(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	confirmation, reminder, location := 48*60, 30, 15
+	entity := testutil.NewTestEntity()
+	entity.ConfirmationOffsetMinutes = &confirmation
+	entity.ReminderOffsetMinutes = &reminder
+	entity.LocationOffsetMinutes = &location
+
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(entity, nil)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	created := captureSchedulers(deps)
+
+	req := newTestCreateEventRequest()
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+
+	assert.Equal(t, req.StartTime.Add(-48*time.Hour), created[domain.SchedulerActionConfirmation].ScheduledAt)
+	assert.Equal(t, req.StartTime.Add(-30*time.Minute), created[domain.SchedulerActionReminder].ScheduledAt)
+	assert.Equal(t, req.StartTime.Add(-15*time.Minute), created[domain.SchedulerActionLocation].ScheduledAt)
+}
+
+func TestEventService_Create_EventSchedulerTimesOverrideEntityOffsets(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	reminder := 30
+	entity := testutil.NewTestEntity()
+	entity.ReminderOffsetMinutes = &reminder
+
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(entity, nil)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	created := captureSchedulers(deps)
+
+	req := newTestCreateEventRequest()
+	confirmationAt := req.StartTime.Add(-72 * time.Hour)
+	beforeHours := 5
+	req.Scheduler = &dto.SchedulerConfig{
+		SendConfirmation:    true,
+		ConfirmationTime:    &confirmationAt,
+		SendReminder:        true,
+		ReminderBeforeHours: &beforeHours,
+	}
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+
+	assert.Equal(t, confirmationAt, created[domain.SchedulerActionConfirmation].ScheduledAt)
+	assert.Equal(t, req.StartTime.Add(-5*time.Hour), created[domain.SchedulerActionReminder].ScheduledAt)
+	assert.NotContains(t, created, domain.SchedulerActionLocation)
+}
+
+func TestEventService_Create_FallsBackToDefaultOffsetsWhenEntityFails(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(nil, errors.New("database down"))
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	created := captureSchedulers(deps)
+
+	req := newTestCreateEventRequest()
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+
+	assert.Equal(t, req.StartTime.Add(-domain.DefaultConfirmationOffset), created[domain.SchedulerActionConfirmation].ScheduledAt)
+	assert.Equal(t, req.StartTime.Add(-domain.DefaultReminderOffset), created[domain.SchedulerActionReminder].ScheduledAt)
+	assert.Equal(t, req.StartTime.Add(-domain.DefaultLocationOffset), created[domain.SchedulerActionLocation].ScheduledAt)
+	assert.Len(t, deps.logs.FilterMessage("Failed to load entity scheduler offsets, using defaults").All(), 1)
+}
+
+func TestEventService_PreviewSchedule_MatchesCreatedSchedulers(t *testing.T) {
+	ctx := context.Background()
+
+	reminder := 45
+	entity := testutil.NewTestEntity()
+	entity.ReminderOffsetMinutes = &reminder
+
+	req := newTestCreateEventRequest()
+	beforeHours := 3
+	req.Scheduler = &dto.SchedulerConfig{SendConfirmation: true, SendReminder: true, ReminderBeforeHours: &beforeHours, TrackLocation: true}
+
+	for _, config := range []*dto.SchedulerConfig{nil, req.Scheduler} {
+		req.Scheduler = config
+
+		previewSvc, previewDeps := newTestEventService()
+		previewDeps.entityRepo.On("GetByID", ctx, testutil.TestEntityID).Return(entity, nil)
+		previews, err := previewSvc.PreviewSchedule(ctx, testutil.TestEntityID, req)
+		require.NoError(t, err)
+
+		createSvc, createDeps := newTestEventService()
+		createDeps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(entity, nil)
+		createDeps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+		createDeps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+		created := captureSchedulers(createDeps)
+		_, err = createSvc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+		require.NoError(t, err)
+
+		require.Len(t, previews, len(created))
+		for _, preview := range previews {
+			require.Contains(t, created, preview.Action)
+			assert.Equal(t, created[preview.Action].ScheduledAt, preview.ScheduledAt, preview.Action)
+			assert.False(t, preview.InPast)
+		}
+		previewDeps.schedulerRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		previewDeps.eventRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	}
+}
+
+func TestEventService_PreviewSchedule_FlagsPastTimes(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	deps.entityRepo.On("GetByID", ctx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+
+	// Começa em 1h: a confirmação padrão (24h antes) já passou
+	req := newTestCreateEventRequest()
+	req.StartTime = time.Now().Add(time.Hour)
+	end := req.StartTime.Add(time.Hour)
+	req.EndTime = &end
+
+	previews, err := svc.PreviewSchedule(ctx, testutil.TestEntityID, req)
+	require.NoError(t, err)
+
+	inPast := map[domain.SchedulerAction]bool{}
+	for _, preview := range previews {
+		inPast[preview.Action] = preview.InPast
+	}
+	assert.True(t, inPast[domain.SchedulerActionConfirmation])
+	assert.False(t, inPast[domain.SchedulerActionClosure])
+}
+
+func TestEventService_PreviewSchedule_SkipsDisabledFeatures(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	deps.entityRepo.On("GetByID", ctx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+
+	off := false
+	req := newTestCreateEventRequest()
+	req.Scheduler = &dto.SchedulerConfig{SendConfirmation: true, SendReminder: true, TrackLocation: true, NotifyOrganizer: true}
+
+	tests := []struct {
+		name     string
+		features domain.EventFeatures
+		want     []domain.SchedulerAction
+	}{
+		{
+			name:     "location tracking off",
+			features: domain.EventFeatures{LocationTracking: &off},
+			want: []domain.SchedulerAction{
+				domain.SchedulerActionConfirmation, domain.SchedulerActionReminder, domain.SchedulerActionClosure,
+				domain.SchedulerActionOrganizerSummary, domain.SchedulerActionOrganizerWrapUp,
+			},
+		},
+		{
+			// Sem notificações só sobra o fechamento
+			name:     "notifications off",
+			features: domain.EventFeatures{Notifications: &off},
+			want:     []domain.SchedulerAction{domain.SchedulerActionClosure},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req.Features = tt.features
+			previews, err := svc.PreviewSchedule(ctx, testutil.TestEntityID, req)
+			require.NoError(t, err)
+
+			var actions []domain.SchedulerAction
+			for _, preview := range previews {
+				actions = append(actions, preview.Action)
+			}
+			assert.ElementsMatch(t, tt.want, actions)
+		})
+	}
+}
+
+func TestEventService_PreviewSchedule_RejectsInvalidTimes(t *testing.T) {
+	svc, _ := newTestEventService()
+
+	req := newTestCreateEventRequest()
+	req.StartTime = time.Now().Add(-time.Hour)
+
+	_, err := svc.PreviewSchedule(context.Background(), testutil.TestEntityID, req)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestEventService_ListForParticipant_ReturnsParticipationsAcrossEntities(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	own := testutil.NewTestEvent()
+	other := testutil.NewTestEvent()
+	other.ID = uuid.New()
+	other.EntityID = uuid.New()
+	ownParticipant, otherParticipant := uuid.New(), uuid.New()
+
+	deps.eventRepo.On("ListByParticipantPhone", ctx, "5511999999999", 1, 20).Return([]*domain.ParticipantEvent{
+		{Event: *own, ParticipantID: ownParticipant, ParticipantStatus: domain.ParticipantStatusConfirmed},
+		{Event: *other, ParticipantID: otherParticipant, ParticipantStatus: domain.ParticipantStatusPending},
+	}, int64(2), nil)
+
+	events, total, err := svc.ListForParticipant(ctx, "+55 (11) 99999-9999", 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, events, 2)
+	assert.Equal(t, own.EntityID, events[0].EntityID)
+	assert.Equal(t, ownParticipant, events[0].ParticipantID)
+	assert.Equal(t, domain.ParticipantStatusConfirmed, events[0].ParticipantStatus)
+	assert.Equal(t, other.EntityID, events[1].EntityID)
+	assert.Equal(t, otherParticipant, events[1].ParticipantID)
+	assert.Equal(t, domain.ParticipantStatusPending, events[1].ParticipantStatus)
+}
+
+func TestEventService_ListForParticipant_RejectsEmptyPhone(t *testing.T) {
+	svc, deps := newTestEventService()
+
+	_, _, err := svc.ListForParticipant(context.Background(), " - ", 1, 20)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	deps.eventRepo.AssertNotCalled(t, "ListByParticipantPhone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEventService_ListForUser_WithoutPhoneIsEmpty(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	user := testutil.NewTestUser()
+	user.Phone = nil
+	deps.userRepo.On("GetByID", ctx, user.ID).Return(user, nil)
+
+	events, total, err := svc.ListForUser(ctx, user.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Zero(t, total)
+	deps.eventRepo.AssertNotCalled(t, "ListByParticipantPhone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEventService_ListForUser_UnverifiedPhoneIsEmpty(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	user := testutil.NewTestUser()
+	phone := "+5511999999999"
+	user.Phone = &phone
+	user.PhoneVerified = false
+	deps.userRepo.On("GetByID", ctx, user.ID).Return(user, nil)
+
+	events, total, err := svc.ListForUser(ctx, user.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Zero(t, total)
+	deps.eventRepo.AssertNotCalled(t, "ListByParticipantPhone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEventService_ListForUser_VerifiedPhone(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	user := testutil.NewTestUser()
+	phone := "+5511999999999"
+	user.Phone = &phone
+	user.PhoneVerified = true
+	event := testutil.NewTestEvent()
+	deps.userRepo.On("GetByID", ctx, user.ID).Return(user, nil)
+	deps.eventRepo.On("ListByParticipantPhone", ctx, "5511999999999", 1, 20).Return([]*domain.ParticipantEvent{
+		{Event: *event, ParticipantID: uuid.New(), ParticipantStatus: domain.ParticipantStatusConfirmed},
+	}, int64(1), nil)
+
+	events, total, err := svc.ListForUser(ctx, user.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, events, 1)
+	assert.Equal(t, event.ID, events[0].ID)
+}
+
+// newImminentEventRequest cria um evento que começa em 30 minutos, depois do horário
+// padrão de confirmação, lembrete e localização
+func newImminentEventRequest() *dto.CreateEventRequest {
+	req := newTestCreateEventRequest()
+	req.StartTime = time.Now().Add(30 * time.Minute)
+	end := req.StartTime.Add(2 * time.Hour)
+	req.EndTime = &end
+	return req
+}
+
+func TestEventService_Create_SkipsPastSchedulersOfImminentEvent(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.scheduling = &config.SchedulingConfig{PastPolicy: config.PastSchedulerSkip, MinReminderLead: 15 * time.Minute}
+
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	created := captureSchedulers(deps)
+
+	resp, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, newImminentEventRequest())
+	require.NoError(t, err)
+
+	// Só o fechamento, que é depois do fim do evento, é criado
+	assert.Equal(t, 1, resp.SchedulersCreated)
+	assert.Contains(t, created, domain.SchedulerActionClosure)
+	assert.NotContains(t, created, domain.SchedulerActionConfirmation)
+	assert.NotContains(t, created, domain.SchedulerActionReminder)
+	assert.NotContains(t, created, domain.SchedulerActionLocation)
+
+	skipped := deps.logs.FilterMessage("Scheduler skipped").All()
+	require.Len(t, skipped, 3)
+	for _, entry := range skipped {
+		assert.Equal(t, skipReasonInPast, entry.ContextMap()["reason"])
+	}
+}
+
+func TestEventService_Create_FiresPastSchedulersOnceWhenConfigured(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.scheduling = &config.SchedulingConfig{PastPolicy: config.PastSchedulerFireOnce, MinReminderLead: 15 * time.Minute}
+
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	created := captureSchedulers(deps)
+
+	before := time.Now()
+	resp, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, newImminentEventRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 4, resp.SchedulersCreated)
+
+	for _, action := range []domain.SchedulerAction{domain.SchedulerActionConfirmation, domain.SchedulerActionReminder, domain.SchedulerActionLocation} {
+		scheduler := created[action]
+		require.NotNil(t, scheduler, action)
+		assert.False(t, scheduler.ScheduledAt.Before(before), action)
+		assert.Equal(t, 1, scheduler.MaxRetries, action)
+		assert.Equal(t, true, scheduler.Metadata["fired_late"], action)
+	}
+	assert.NotContains(t, created[domain.SchedulerActionClosure].Metadata, "fired_late")
+	assert.Empty(t, deps.logs.FilterMessage("Scheduler skipped").All())
+}
+
+func TestEventService_Create_EnforcesReminderLeadLimits(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.scheduling = &config.SchedulingConfig{
+		PastPolicy:      config.PastSchedulerSkip,
+		MinReminderLead: 15 * time.Minute,
+		MaxReminderLead: 72 * time.Hour,
+	}
+
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	// Lembrete com menos antecedência que o mínimo é pulado
+	created := captureSchedulers(deps)
+	req := newTestCreateEventRequest()
+	tooLate := req.StartTime.Add(-5 * time.Minute)
+	req.Scheduler = &dto.SchedulerConfig{SendReminder: true, ReminderTime: &tooLate}
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+	assert.NotContains(t, created, domain.SchedulerActionReminder)
+	skipped := deps.logs.FilterMessage("Scheduler skipped").All()
+	require.Len(t, skipped, 1)
+	assert.Equal(t, skipReasonReminderTooLate, skipped[0].ContextMap()["reason"])
+
+	// Lembrete antes do máximo é trazido para o limite
+	req = newTestCreateEventRequest()
+	req.StartTime = time.Now().Add(10 * 24 * time.Hour)
+	end := req.StartTime.Add(2 * time.Hour)
+	req.EndTime = &end
+	tooEarly := req.StartTime.Add(-8 * 24 * time.Hour)
+	req.Scheduler = &dto.SchedulerConfig{SendReminder: true, ReminderTime: &tooEarly}
+	_, err = svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+	assert.Equal(t, req.StartTime.Add(-72*time.Hour), created[domain.SchedulerActionReminder].ScheduledAt)
+}
+
+// pngHeader é o início de um PNG, suficiente para a detecção de content type
+const pngHeader = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func TestEventService_AddAttachment_StoresDetectedContentType(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	var created *domain.Attachment
+	deps.eventRepo.On("GetByID", ctx, testutil.TestEventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	deps.attachmentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Attachment")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*domain.Attachment) }).
+		Return(nil)
+
+	resp, err := svc.AddAttachment(ctx, testutil.TestEntityID, testutil.TestEventID, testutil.TestUserID,
+		"../mapa.png", int64(len(pngHeader)), strings.NewReader(pngHeader))
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	assert.Equal(t, "mapa.png", resp.Filename)
+	assert.Equal(t, "image/png", created.ContentType)
+
+	stored, err := deps.storage.Get(ctx, created.StorageKey)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(stored)
+	assert.Equal(t, pngHeader, string(data))
+}
+
+func TestEventService_AddAttachment_EnforcesLimits(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	deps.eventRepo.On("GetByID", ctx, testutil.TestEventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	_, err := svc.AddAttachment(ctx, testutil.TestEntityID, testutil.TestEventID, testutil.TestUserID,
+		"grande.png", 2048, strings.NewReader(pngHeader))
+	assert.ErrorIs(t, err, domain.ErrAttachmentTooLarge)
+
+	// O content type vem do conteúdo, não da extensão
+	_, err = svc.AddAttachment(ctx, testutil.TestEntityID, testutil.TestEventID, testutil.TestUserID,
+		"script.png", 20, strings.NewReader("<html><script></script>"))
+	assert.ErrorIs(t, err, domain.ErrUnsupportedContentType)
+
+	deps.attachmentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestEventService_AddAttachment_RemovesStoredFileWhenCreateFails(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	var key string
+	deps.eventRepo.On("GetByID", ctx, testutil.TestEventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	deps.attachmentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Attachment")).
+		Run(func(args mock.Arguments) { key = args.Get(1).(*domain.Attachment).StorageKey }).
+		Return(errors.New("database down"))
+
+	_, err := svc.AddAttachment(ctx, testutil.TestEntityID, testutil.TestEventID, testutil.TestUserID,
+		"mapa.png", int64(len(pngHeader)), strings.NewReader(pngHeader))
+	require.Error(t, err)
+
+	_, err = deps.storage.Get(ctx, key)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+}
+
+func TestEventService_RemoveAttachment_DeletesRecordAndContents(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	attachment := &domain.Attachment{
+		ID:         uuid.New(),
+		EntityID:   testutil.TestEntityID,
+		EventID:    testutil.TestEventID,
+		StorageKey: "events/" + testutil.TestEventID.String() + "/agenda",
+	}
+	require.NoError(t, deps.storage.Put(ctx, attachment.StorageKey, strings.NewReader(pngHeader), int64(len(pngHeader)), "image/png"))
+	deps.attachmentRepo.On("GetByID", ctx, attachment.ID, testutil.TestEventID, testutil.TestEntityID).Return(attachment, nil)
+	deps.attachmentRepo.On("Delete", ctx, attachment.ID, testutil.TestEntityID).Return(nil)
+
+	require.NoError(t, svc.RemoveAttachment(ctx, testutil.TestEntityID, testutil.TestEventID, attachment.ID))
+
+	_, err := deps.storage.Get(ctx, attachment.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	deps.attachmentRepo.AssertExpectations(t)
+}
+
+func TestEventService_Create_RejectsNearDuplicate(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.eventConfig = &config.EventConfig{DuplicateWindow: time.Hour}
+
+	existing := testutil.NewTestEvent()
+	req := newTestCreateEventRequest()
+	deps.eventRepo.On("FindDuplicate", ctx, testutil.TestEntityID, req.Name, req.StartTime.Add(-time.Hour), req.StartTime.Add(time.Hour)).
+		Return(existing, nil)
+
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.ErrorIs(t, err, domain.ErrDuplicateEvent)
+	var duplicate *domain.DuplicateEventError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, existing.ID, duplicate.ExistingEventID)
+	deps.eventRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestEventService_Create_AllowDuplicateSkipsCheck(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.eventConfig = &config.EventConfig{DuplicateWindow: time.Hour}
+
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+
+	req := newTestCreateEventRequest()
+	req.AllowDuplicate = true
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+	deps.eventRepo.AssertNotCalled(t, "FindDuplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEventService_Create_WithoutDuplicateCreatesEvent(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.eventConfig = &config.EventConfig{DuplicateWindow: time.Hour}
+
+	deps.eventRepo.On("FindDuplicate", ctx, testutil.TestEntityID, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, domain.ErrNotFound)
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, newTestCreateEventRequest())
+	require.NoError(t, err)
+	deps.eventRepo.AssertCalled(t, "Create", inTx, mock.AnythingOfType("*domain.Event"))
+}
+
+func TestEventService_Create_AppliesDefaultTimezoneAndLocale(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.eventConfig = &config.EventConfig{DefaultTimezone: "America/Sao_Paulo", DefaultLocale: "pt"}
+
+	var created []*domain.Event
+	deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(1).(*domain.Event)) }).
+		Return(nil)
+	deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	deps.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, newTestCreateEventRequest())
+	require.NoError(t, err)
+
+	// O evento informa o próprio fuso e idioma
+	req := newTestCreateEventRequest()
+	req.Timezone = "America/New_York"
+	req.Locale = "en"
+	_, err = svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+
+	require.Len(t, created, 2)
+	assert.Equal(t, "America/Sao_Paulo", created[0].Timezone)
+	assert.Equal(t, "pt", created[0].Locale)
+	assert.Equal(t, "America/New_York", created[1].Timezone)
+	assert.Equal(t, "en", created[1].Locale)
+}
+
+// fakeGeocoder resolve endereços a partir de um mapa e conta as consultas
+type fakeGeocoder struct {
+	coordinates map[string][2]float64
+	calls       int
+}
+
+func (g *fakeGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	g.calls++
+	c, ok := g.coordinates[address]
+	if !ok {
+		return 0, 0, geocoding.ErrNoResults
+	}
+	return c[0], c[1], nil
+}
+
+// captureCreatedEvents registra os eventos passados ao Create do repositório
+func (d *eventServiceDeps) captureCreatedEvents(ctx context.Context) *[]*domain.Event {
+	var created []*domain.Event
+	d.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+	d.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(1).(*domain.Event)) }).
+		Return(nil)
+	d.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	d.schedulerRepo.On("Create", inTx, mock.AnythingOfType("*domain.Scheduler")).Return(nil)
+	return &created
+}
+
+func TestEventService_Create_GeocodesAddressWithoutCoordinates(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	geocoder := &fakeGeocoder{coordinates: map[string][2]float64{"Av. Paulista, 1000": {-23.56, -46.65}}}
+	svc.geocoder = geocoder
+	created := deps.captureCreatedEvents(ctx)
+
+	req := newTestCreateEventRequest()
+	req.LocationLat, req.LocationLng = 0, 0
+	address := "Av. Paulista, 1000"
+	req.LocationAddress = &address
+
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+
+	require.Len(t, *created, 1)
+	assert.Equal(t, -23.56, (*created)[0].LocationLat)
+	assert.Equal(t, -46.65, (*created)[0].LocationLng)
+	assert.Equal(t, 1, geocoder.calls)
+}
+
+func TestEventService_Create_KeepsGivenCoordinates(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	geocoder := &fakeGeocoder{coordinates: map[string][2]float64{"Av. Paulista, 1000": {-23.56, -46.65}}}
+	svc.geocoder = geocoder
+	created := deps.captureCreatedEvents(ctx)
+
+	req := newTestCreateEventRequest()
+	address := "Av. Paulista, 1000"
+	req.LocationAddress = &address
+
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+
+	require.Len(t, *created, 1)
+	assert.Equal(t, -23.55, (*created)[0].LocationLat)
+	assert.Equal(t, -46.63, (*created)[0].LocationLng)
+	assert.Zero(t, geocoder.calls)
+}
+
+func TestEventService_Create_GeocodingFailureLeavesNoCoordinates(t *testing.T) {
+	tests := []struct {
+		name     string
+		geocoder geocoding.Geocoder
+	}{
+		{name: "address not found", geocoder: &fakeGeocoder{}},
+		{name: "no provider configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestEventService()
+			svc.geocoder = tt.geocoder
+			created := deps.captureCreatedEvents(ctx)
+
+			req := newTestCreateEventRequest()
+			req.LocationLat, req.LocationLng = 0, 0
+			address := "Rua que não existe, 0"
+			req.LocationAddress = &address
+
+			_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+			require.NoError(t, err)
+
+			require.Len(t, *created, 1)
+			assert.False(t, (*created)[0].HasCoordinates())
+		})
+	}
+}
+
+func TestEventService_Update_GeocodesNewAddress(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+	svc.geocoder = &fakeGeocoder{coordinates: map[string][2]float64{"Av. Paulista, 1000": {-23.56, -46.65}}}
+
+	existing := testutil.NewTestEvent()
+	var input *domain.UpdateEventInput
+	deps.eventRepo.On("GetByID", ctx, existing.ID, testutil.TestEntityID).Return(existing, nil)
+	deps.eventRepo.On("Update", ctx, existing.ID, testutil.TestEntityID, mock.AnythingOfType("*domain.UpdateEventInput")).
+		Run(func(args mock.Arguments) { input = args.Get(3).(*domain.UpdateEventInput) }).
+		Return(nil)
+
+	address := "Av. Paulista, 1000"
+	_, err := svc.Update(ctx, testutil.TestEntityID, existing.ID, &dto.UpdateEventRequest{LocationAddress: &address})
+	require.NoError(t, err)
+
+	require.NotNil(t, input)
+	require.NotNil(t, input.LocationLat)
+	require.NotNil(t, input.LocationLng)
+	assert.Equal(t, -23.56, *input.LocationLat)
+	assert.Equal(t, -46.65, *input.LocationLng)
+}
+
+func TestEventService_Update_ClearsFieldsSentAsNull(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantClear bool
+	}{
+		{name: "null limpa", body: `{"name": "Novo nome", "end_time": null, "description": null}`, wantClear: true},
+		{name: "omitido mantém", body: `{"name": "Novo nome"}`, wantClear: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestEventService()
+
+			existing := testutil.NewTestEvent()
+			var input *domain.UpdateEventInput
+			deps.eventRepo.On("GetByID", ctx, existing.ID, testutil.TestEntityID).Return(existing, nil)
+			deps.eventRepo.On("Update", ctx, existing.ID, testutil.TestEntityID, mock.AnythingOfType("*domain.UpdateEventInput")).
+				Run(func(args mock.Arguments) { input = args.Get(3).(*domain.UpdateEventInput) }).
+				Return(nil)
+
+			var req dto.UpdateEventRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &req))
+			_, err := svc.Update(ctx, testutil.TestEntityID, existing.ID, &req)
+			require.NoError(t, err)
+
+			require.NotNil(t, input)
+			require.NotNil(t, input.Name)
+			assert.Equal(t, "Novo nome", *input.Name)
+			assert.Equal(t, tt.wantClear, input.ClearEndTime)
+			assert.Equal(t, tt.wantClear, input.ClearDescription)
+			assert.Nil(t, input.EndTime)
+			assert.Nil(t, input.Description)
+			// Campos não enviados nunca são limpos
+			assert.False(t, input.ClearLocationAddress)
+			assert.False(t, input.ClearConfirmationDeadline)
+		})
+	}
+}
+
+func TestEventService_Create_AllowsConfiguredCustomType(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	entity := testutil.NewTestEntity()
+	entity.EventTypes = domain.EventTypes{"delivery", "appointment"}
+	deps.entityRepo.On("GetByID", ctx, testutil.TestEntityID).Return(entity, nil)
+	created := deps.captureCreatedEvents(ctx)
+
+	req := newTestCreateEventRequest()
+	req.Type = "delivery"
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	require.NoError(t, err)
+
+	require.Len(t, *created, 1)
+	assert.Equal(t, domain.EventType("delivery"), (*created)[0].Type)
+}
+
+func TestEventService_Create_RejectsUnknownType(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestEventService()
+
+	entity := testutil.NewTestEntity()
+	entity.EventTypes = domain.EventTypes{"delivery"}
+	deps.entityRepo.On("GetByID", ctx, testutil.TestEntityID).Return(entity, nil)
+
+	req := newTestCreateEventRequest()
+	req.Type = "appointment"
+	_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+	assert.ErrorIs(t, err, domain.ErrUnknownEventType)
+	deps.eventRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	assert.Zero(t, deps.transactor.committed)
+}
+
+func TestEventService_Create_OrganizerSummariesAreOptIn(t *testing.T) {
+	ctx := context.Background()
+	hours := 6
+
+	tests := []struct {
+		name      string
+		scheduler *dto.SchedulerConfig
+		wantAt    func(req *dto.CreateEventRequest) time.Time
+	}{
+		{name: "not requested"},
+		{
+			name:      "default lead",
+			scheduler: &dto.SchedulerConfig{NotifyOrganizer: true},
+			wantAt:    func(req *dto.CreateEventRequest) time.Time { return req.StartTime.Add(-domain.DefaultReminderOffset) },
+		},
+		{
+			name:      "explicit lead",
+			scheduler: &dto.SchedulerConfig{NotifyOrganizer: true, OrganizerSummaryBeforeHours: &hours},
+			wantAt:    func(req *dto.CreateEventRequest) time.Time { return req.StartTime.Add(-6 * time.Hour) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := newTestEventService()
+			deps.entityRepo.On("GetByID", inTx, testutil.TestEntityID).Return(testutil.NewTestEntity(), nil)
+			deps.eventRepo.On("Create", inTx, mock.AnythingOfType("*domain.Event")).Return(nil)
+			deps.eventRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID"), testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+			created := captureSchedulers(deps)
+
+			req := newTestCreateEventRequest()
+			req.Scheduler = tt.scheduler
+			_, err := svc.Create(ctx, testutil.TestEntityID, testutil.TestUserID, req)
+			require.NoError(t, err)
+
+			if tt.wantAt == nil {
+				assert.NotContains(t, created, domain.SchedulerActionOrganizerSummary)
+				assert.NotContains(t, created, domain.SchedulerActionOrganizerWrapUp)
+				return
+			}
+			require.Contains(t, created, domain.SchedulerActionOrganizerSummary)
+			assert.Equal(t, tt.wantAt(req), created[domain.SchedulerActionOrganizerSummary].ScheduledAt)
+			require.Contains(t, created, domain.SchedulerActionOrganizerWrapUp)
+			assert.Equal(t, req.EndTime.Add(organizerWrapUpDelay), created[domain.SchedulerActionOrganizerWrapUp].ScheduledAt)
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/whatsapp"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// processedMessageTTL is how long processed provider message ids are remembered.
+// WhatsApp retries failed deliveries for up to a few days.
+const processedMessageTTL = 72 * time.Hour
+
+// declineReasonTTL is how long after a "no" the next free-text message is taken as the reason
+const declineReasonTTL = time.Hour
+
+// maxDeclineReasonLength matches the size of the participants.decline_reason column
+const maxDeclineReasonLength = 500
+
+// ErrUnrecognizedReply is returned when a known participant sends a reply
+// that couldn't be interpreted
+var ErrUnrecognizedReply = errors.New("unrecognized reply")
+
+// MessageSender sends free-text replies back to a participant
+type MessageSender interface {
+	SendTextMessage(ctx context.Context, phoneNumber, message string) error
+}
+
+// participantStatusLabels are the status names shown to participants
+var participantStatusLabels = map[domain.ParticipantStatus]string{
+	domain.ParticipantStatusPending:   "Aguardando sua resposta",
+	domain.ParticipantStatusConfirmed: "Confirmado",
+	domain.ParticipantStatusDenied:    "Recusado",
+	domain.ParticipantStatusCheckedIn: "Check-in feito",
+	domain.ParticipantStatusNoShow:    "Ausente",
+}
+
+// InboundMessageService applies participant replies (confirmations, response
+// options and locations) independently of the messaging provider
+type InboundMessageService struct {
+	participantService *ParticipantService
+	locationService    *LocationService
+	processed          *cache.IdempotencyStore
+	declineReasons     *cache.IdempotencyStore
+	keywords           *KeywordMatcher
+	sender             MessageSender
+	logger             *zap.Logger
+}
+
+// NewInboundMessageService creates a new inbound message service.
+// processed may be nil to disable redelivery detection, declineReasons may be nil
+// to disable asking for a reason after a denial and sender may be nil to disable
+// replies (e.g. the "status" command).
+func NewInboundMessageService(
+	participantService *ParticipantService,
+	locationService *LocationService,
+	processed *cache.IdempotencyStore,
+	declineReasons *cache.IdempotencyStore,
+	keywords *KeywordMatcher,
+	sender MessageSender,
+	logger *zap.Logger,
+) *InboundMessageService {
+	return &InboundMessageService{
+		participantService: participantService,
+		locationService:    locationService,
+		processed:          processed,
+		declineReasons:     declineReasons,
+		keywords:           keywords,
+		sender:             sender,
+		logger:             logger,
+	}
+}
+
+// Handle processes a single inbound message. Redelivered messages are skipped.
+// Returns domain.ErrNotFound when the sender is not a participant of an active
+// event and ErrUnrecognizedReply when a text reply couldn't be understood.
+// When handling fails the message id is released, so a redelivery is processed again.
+func (s *InboundMessageService) Handle(ctx context.Context, msg *domain.InboundMessage) error {
+	if !s.claim(ctx, msg) {
+		s.logger.Info("Skipping already processed message",
+			zap.String("provider", msg.Provider),
+			zap.String("message_id", msg.ProviderMessageID),
+		)
+		return nil
+	}
+
+	err := s.handle(domain.WithStatusSource(ctx, domain.StatusSourceWhatsApp), msg)
+	if err != nil && releasesClaim(err) {
+		s.release(ctx, msg)
+	}
+	return err
+}
+
+// handle dispatches the message by type
+func (s *InboundMessageService) handle(ctx context.Context, msg *domain.InboundMessage) error {
+	switch msg.Type {
+	case domain.InboundMessageLocation:
+		return s.handleLocation(ctx, msg)
+	case domain.InboundMessageReply:
+		return s.handleReply(ctx, msg)
+	case domain.InboundMessageText:
+		return s.handleText(ctx, msg)
+	}
+	return nil
+}
+
+// releasesClaim reports whether a failed message should be processed again on
+// redelivery. Messages from unknown numbers, unrecognized replies (already
+// answered with help) and locations without consent are final.
+func releasesClaim(err error) bool {
+	return !errors.Is(err, domain.ErrNotFound) && !errors.Is(err, ErrUnrecognizedReply) &&
+		!errors.Is(err, domain.ErrLocationConsentRequired)
+}
+
+// claim records the message id as processed and reports whether it is new.
+// If Redis is unavailable the message is processed anyway.
+func (s *InboundMessageService) claim(ctx context.Context, msg *domain.InboundMessage) bool {
+	if s.processed == nil || msg.ProviderMessageID == "" {
+		return true
+	}
+
+	ok, err := s.processed.Claim(ctx, msg.Provider+":"+msg.ProviderMessageID, processedMessageTTL)
+	if err != nil {
+		s.logger.Warn("Failed to check message idempotency",
+			zap.String("message_id", msg.ProviderMessageID),
+			zap.Error(err),
+		)
+		return true
+	}
+	if !ok || msg.Provider != whatsapp.Provider {
+		return ok
+	}
+
+	// WhatsApp message ids were claimed without the provider prefix before other
+	// providers existed; a redelivery of one of those must still be skipped
+	if legacy, err := s.processed.Exists(ctx, msg.ProviderMessageID); err == nil && legacy {
+		return false
+	}
+	return true
+}
+
+// release forgets a claimed message id after handling failed
+func (s *InboundMessageService) release(ctx context.Context, msg *domain.InboundMessage) {
+	if s.processed == nil || msg.ProviderMessageID == "" {
+		return
+	}
+
+	if err := s.processed.Release(ctx, msg.Provider+":"+msg.ProviderMessageID); err != nil {
+		s.logger.Warn("Failed to release message idempotency key",
+			zap.String("message_id", msg.ProviderMessageID),
+			zap.Error(err),
+		)
+	}
+}
+
+// handleLocation records a location shared by a participant
+func (s *InboundMessageService) handleLocation(ctx context.Context, msg *domain.InboundMessage) error {
+	if msg.Location == nil {
+		return domain.ErrInvalidInput
+	}
+
+	s.logger.Info("Received location",
+		zap.String("provider", msg.Provider),
+		zap.String("phone", msg.From),
+		zap.Float64("lat", msg.Location.Latitude),
+		zap.Float64("lng", msg.Location.Longitude),
+	)
+
+	participant, err := s.participantService.GetByPhoneNumber(ctx, msg.From)
+	if err != nil {
+		return err
+	}
+
+	timestamp := msg.Timestamp
+	_, err = s.locationService.CreateLocation(ctx, participant.ID, participant.EntityID, &dto.CreateLocationRequest{
+		Latitude:  msg.Location.Latitude,
+		Longitude: msg.Location.Longitude,
+		Timestamp: &timestamp,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Location saved successfully",
+		zap.String("phone", msg.From),
+		zap.String("participant_id", participant.ID.String()),
+	)
+	return nil
+}
+
+// handleReply applies a button/list reply: an event-specific response option
+// if the event defines one, otherwise a yes/no confirmation
+func (s *InboundMessageService) handleReply(ctx context.Context, msg *domain.InboundMessage) error {
+	s.logger.Info("Received reply",
+		zap.String("provider", msg.Provider),
+		zap.String("phone", msg.From),
+		zap.String("payload", msg.ReplyID),
+	)
+
+	participant, err := s.participantService.GetByPhoneNumber(ctx, msg.From)
+	if err != nil {
+		return err
+	}
+
+	option, err := s.participantService.ApplyResponseOption(ctx, participant, msg.ReplyID)
+	if err == nil {
+		s.logger.Info("Participant response option applied",
+			zap.String("participant_id", participant.ID.String()),
+			zap.String("option_id", msg.ReplyID),
+			zap.String("option_title", option.Title),
+		)
+		return nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	return s.applyConfirmation(ctx, participant, msg.From, msg.ReplyID)
+}
+
+// handleText interprets free-text replies ("Sim!", "YES please", "não vou"...)
+func (s *InboundMessageService) handleText(ctx context.Context, msg *domain.InboundMessage) error {
+	s.logger.Info("Received text message",
+		zap.String("provider", msg.Provider),
+		zap.String("phone", msg.From),
+		zap.String("text", msg.Text),
+	)
+
+	participant, err := s.participantService.GetByPhoneNumber(ctx, msg.From)
+	if err != nil {
+		return err
+	}
+
+	intent := s.keywords.Match(msg.Text)
+
+	// Depois de um "não", a próxima mensagem livre é o motivo. Uma nova recusa
+	// ("não posso, estou viajando") também conta como motivo; outros comandos não
+	if intent == ReplyUnknown || intent == ReplyDeny {
+		captured, err := s.captureDeclineReason(ctx, msg.From, msg.Text)
+		if err != nil {
+			return err
+		}
+		if captured {
+			return nil
+		}
+	}
+
+	switch intent {
+	case ReplyUnknown:
+		return ErrUnrecognizedReply
+	case ReplyStatus:
+		return s.replyEventStatus(ctx, msg.From)
+	case ReplyLocationOptIn, ReplyLocationOptOut:
+		return s.applyLocationConsent(ctx, participant, msg.From, intent == ReplyLocationOptIn)
+	}
+
+	return s.applyConfirmation(ctx, participant, msg.From, string(intent))
+}
+
+// replyEventStatus answers a "status" message with the sender's events and their status in each
+func (s *InboundMessageService) replyEventStatus(ctx context.Context, phone string) error {
+	events, err := s.participantService.ListEventsByPhone(ctx, phone)
+	if err != nil {
+		return err
+	}
+
+	if s.sender == nil {
+		return nil
+	}
+
+	if len(events) == 0 {
+		return s.sender.SendTextMessage(ctx, phone, "📅 Você não tem eventos ativos no momento.")
+	}
+
+	var b strings.Builder
+	b.WriteString("📅 *Seus eventos*\n")
+	for _, e := range events {
+		fmt.Fprintf(&b, "\n• *%s* — %s\n  %s", e.EventName, e.StartTimeLocal, participantStatusLabels[e.Status])
+	}
+
+	return s.sender.SendTextMessage(ctx, phone, b.String())
+}
+
+// applyLocationConsent grants or revokes the participant's location sharing consent
+// and acknowledges it to the sender
+func (s *InboundMessageService) applyLocationConsent(ctx context.Context, participant *domain.Participant, phone string, consent bool) error {
+	var err error
+	if consent {
+		err = s.participantService.GrantLocationConsent(ctx, participant.EntityID, participant.ID)
+	} else {
+		err = s.participantService.RevokeLocationConsent(ctx, participant.EntityID, participant.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Participant location consent updated",
+		zap.String("participant_id", participant.ID.String()),
+		zap.Bool("consent", consent),
+	)
+
+	if s.sender == nil {
+		return nil
+	}
+
+	message := "📍 Compartilhamento de localização ativado. Envie *PARAR LOCALIZAÇÃO* para desativar."
+	if !consent {
+		message = "📍 Compartilhamento de localização desativado. Envie *COMPARTILHAR LOCALIZAÇÃO* para ativar novamente."
+	}
+	return s.sender.SendTextMessage(ctx, phone, message)
+}
+
+// applyConfirmation maps a confirmation payload to a participant status.
+// After a denial the participant is asked for the reason
+func (s *InboundMessageService) applyConfirmation(ctx context.Context, participant *domain.Participant, phone, payload string) error {
+	var newStatus domain.ParticipantStatus
+	switch payload {
+	case "confirm_yes", "CONFIRM_YES", "yes", "1":
+		newStatus = domain.ParticipantStatusConfirmed
+	case "confirm_no", "CONFIRM_NO", "no", "2":
+		newStatus = domain.ParticipantStatusDenied
+	default:
+		s.logger.Warn("Unknown confirmation payload",
+			zap.String("participant_id", participant.ID.String()),
+			zap.String("payload", payload),
+		)
+		return ErrUnrecognizedReply
+	}
+
+	err := s.participantService.UpdateStatus(ctx, participant.EntityID, participant.ID, newStatus)
+	if errors.Is(err, domain.ErrEventFull) {
+		// Not a delivery failure: answering with an error would only make the provider redeliver
+		s.logger.Info("Participant confirmation rejected, event is full",
+			zap.String("participant_id", participant.ID.String()),
+		)
+		if s.sender == nil {
+			return nil
+		}
+		return s.sender.SendTextMessage(ctx, phone, "😕 As vagas deste evento já foram preenchidas, não foi possível confirmar sua presença.")
+	}
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Participant confirmation processed",
+		zap.String("participant_id", participant.ID.String()),
+		zap.String("status", string(newStatus)),
+	)
+
+	if newStatus == domain.ParticipantStatusDenied {
+		return s.askDeclineReason(ctx, participant, phone)
+	}
+
+	// Mudou de ideia antes de dar o motivo: a próxima mensagem não é mais uma justificativa
+	if s.declineReasons != nil {
+		if err := s.declineReasons.Release(ctx, phone); err != nil {
+			s.logger.Warn("Failed to clear pending decline reason",
+				zap.String("phone", phone),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// askDeclineReason remembers which participation the sender just declined and asks why
+func (s *InboundMessageService) askDeclineReason(ctx context.Context, participant *domain.Participant, phone string) error {
+	if s.declineReasons == nil || s.sender == nil {
+		return nil
+	}
+
+	value := participant.EntityID.String() + ":" + participant.ID.String()
+	if err := s.declineReasons.Store(ctx, phone, []byte(value), declineReasonTTL); err != nil {
+		s.logger.Warn("Failed to store pending decline reason",
+			zap.String("participant_id", participant.ID.String()),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	return s.sender.SendTextMessage(ctx, phone, "Que pena! 😕 Se quiser, conte o motivo da recusa respondendo esta mensagem.")
+}
+
+// captureDeclineReason stores text as the reason of the sender's last denial, if one
+// is awaiting a reason. Reports whether the message was consumed
+func (s *InboundMessageService) captureDeclineReason(ctx context.Context, phone, text string) (bool, error) {
+	if s.declineReasons == nil {
+		return false, nil
+	}
+
+	value, ok, err := s.declineReasons.Load(ctx, phone)
+	if err != nil {
+		s.logger.Warn("Failed to load pending decline reason",
+			zap.String("phone", phone),
+			zap.Error(err),
+		)
+		return false, nil
+	}
+	if !ok {
+		return false, nil
+	}
+
+	entIDStr, participantIDStr, _ := strings.Cut(string(value), ":")
+	entID, err := uuid.Parse(entIDStr)
+	if err != nil {
+		return false, nil
+	}
+	participantID, err := uuid.Parse(participantIDStr)
+	if err != nil {
+		return false, nil
+	}
+
+	reason := strings.TrimSpace(text)
+	if runes := []rune(reason); len(runes) > maxDeclineReasonLength {
+		reason = string(runes[:maxDeclineReasonLength])
+	}
+
+	if err := s.participantService.SetDeclineReason(ctx, entID, participantID, &reason); err != nil {
+		return false, err
+	}
+	if err := s.declineReasons.Release(ctx, phone); err != nil {
+		s.logger.Warn("Failed to clear pending decline reason",
+			zap.String("phone", phone),
+			zap.Error(err),
+		)
+	}
+
+	s.logger.Info("Participant decline reason captured",
+		zap.String("participant_id", participantID.String()),
+	)
+
+	if s.sender == nil {
+		return true, nil
+	}
+	return true, s.sender.SendTextMessage(ctx, phone, "Obrigado por nos contar! 🙏")
+}
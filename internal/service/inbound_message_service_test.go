@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type inboundMessageServiceDeps struct {
+	participantRepo *mocks.MockParticipantRepository
+	eventRepo       *mocks.MockEventRepository
+	transactor      *mocks.MockTransactor
+	locationRepo    *mocks.MockLocationRepository
+	processed       *cache.IdempotencyStore
+	declineReasons  *cache.IdempotencyStore
+	sender          *recordingSender
+}
+
+// recordingSender guarda as mensagens enviadas aos participantes
+type recordingSender struct {
+	messages map[string][]string
+}
+
+func (s *recordingSender) SendTextMessage(ctx context.Context, phoneNumber, message string) error {
+	s.messages[phoneNumber] = append(s.messages[phoneNumber], message)
+	return nil
+}
+
+func newTestInboundMessageService(t *testing.T) (*InboundMessageService, *inboundMessageServiceDeps) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	deps := &inboundMessageServiceDeps{
+		participantRepo: new(mocks.MockParticipantRepository),
+		eventRepo:       new(mocks.MockEventRepository),
+		transactor:      new(mocks.MockTransactor),
+		locationRepo:    new(mocks.MockLocationRepository),
+		processed:       cache.NewIdempotencyStore(client, "inbound:processed:"),
+		declineReasons:  cache.NewIdempotencyStore(client, "inbound:decline_reason:"),
+		sender:          &recordingSender{messages: map[string][]string{}},
+	}
+	participantService := NewParticipantService(deps.participantRepo, deps.eventRepo, deps.transactor, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	locationService := NewLocationService(deps.locationRepo, deps.participantRepo, deps.eventRepo, nil, nil, &config.LocationConfig{}, zap.NewNop())
+	svc := NewInboundMessageService(participantService, locationService, deps.processed, deps.declineReasons,
+		NewKeywordMatcher(DefaultKeywordSets), deps.sender, zap.NewNop())
+	return svc, deps
+}
+
+func newTestInboundMessage(provider, id string, msgType domain.InboundMessageType) *domain.InboundMessage {
+	return &domain.InboundMessage{
+		Provider:          provider,
+		ProviderMessageID: id,
+		From:              "5511999999999",
+		Type:              msgType,
+		Timestamp:         time.Now(),
+	}
+}
+
+func TestInboundMessageService_Handle_SameConfirmationForEveryProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     *domain.InboundMessage
+		want    domain.ParticipantStatus
+		replyID string
+		text    string
+	}{
+		{name: "whatsapp button", msg: newTestInboundMessage("whatsapp", "wamid.1", domain.InboundMessageReply), replyID: "confirm_yes", want: domain.ParticipantStatusConfirmed},
+		{name: "telegram button", msg: newTestInboundMessage("telegram", "tg.1", domain.InboundMessageReply), replyID: "confirm_yes", want: domain.ParticipantStatusConfirmed},
+		{name: "whatsapp text", msg: newTestInboundMessage("whatsapp", "wamid.2", domain.InboundMessageText), text: "não vou", want: domain.ParticipantStatusDenied},
+		{name: "sms text", msg: newTestInboundMessage("sms", "sms.2", domain.InboundMessageText), text: "não vou", want: domain.ParticipantStatusDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestInboundMessageService(t)
+
+			participant := testutil.NewTestParticipant()
+			event := testutil.NewTestEvent()
+			deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, tt.msg.From).Return(participant, nil)
+			deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+			deps.participantRepo.On("UpdateStatus", mock.Anything, participant.ID, participant.EntityID, tt.want).Return(nil)
+			deps.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+
+			tt.msg.ReplyID = tt.replyID
+			tt.msg.Text = tt.text
+			require.NoError(t, svc.Handle(ctx, tt.msg))
+			deps.participantRepo.AssertCalled(t, "UpdateStatus", mock.Anything, participant.ID, participant.EntityID, tt.want)
+		})
+	}
+}
+
+func TestInboundMessageService_Handle_SkipsRedeliveryPerProvider(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	participant := testutil.NewTestParticipant()
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(testutil.NewTestEvent(), nil)
+	deps.participantRepo.On("UpdateStatus", mock.Anything, participant.ID, participant.EntityID, domain.ParticipantStatusConfirmed).Return(nil)
+	deps.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+
+	msg := newTestInboundMessage("whatsapp", "id-1", domain.InboundMessageText)
+	msg.Text = "sim"
+	require.NoError(t, svc.Handle(ctx, msg))
+	require.NoError(t, svc.Handle(ctx, msg))
+
+	// O mesmo id vindo de outro provedor é outra mensagem
+	other := *msg
+	other.Provider = "telegram"
+	require.NoError(t, svc.Handle(ctx, &other))
+
+	deps.participantRepo.AssertNumberOfCalls(t, "UpdateStatus", 2)
+}
+
+func TestInboundMessageService_Handle_SkipsLegacyWhatsAppClaim(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	// Ids reivindicados antes do prefixo do provedor existir
+	_, err := deps.processed.Claim(ctx, "wamid.legacy", time.Hour)
+	require.NoError(t, err)
+
+	msg := newTestInboundMessage("whatsapp", "wamid.legacy", domain.InboundMessageText)
+	msg.Text = "sim"
+	require.NoError(t, svc.Handle(ctx, msg))
+
+	deps.participantRepo.AssertNotCalled(t, "GetActiveByPhoneNumber", mock.Anything, mock.Anything)
+}
+
+func TestInboundMessageService_Handle_UnrecognizedReplyKeepsClaim(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	participant := testutil.NewTestParticipant()
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+
+	msg := newTestInboundMessage("whatsapp", "wamid.text", domain.InboundMessageText)
+	msg.Text = "qual o endereço?"
+	assert.ErrorIs(t, svc.Handle(ctx, msg), ErrUnrecognizedReply)
+	require.NoError(t, svc.Handle(ctx, msg))
+
+	deps.participantRepo.AssertNumberOfCalls(t, "GetActiveByPhoneNumber", 1)
+}
+
+func TestInboundMessageService_Handle_StatusListsActiveEvents(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	participant := testutil.NewTestParticipant()
+	first := testutil.NewTestEvent()
+	first.Name = "Culto de domingo"
+	first.Timezone = "America/Sao_Paulo"
+	second := testutil.NewTestEvent()
+	second.Name = "Ensaio do coral"
+	second.Timezone = "America/New_York"
+	second.Locale = "en"
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	deps.eventRepo.On("ListActiveByParticipantPhone", mock.Anything, "5511999999999").Return([]*domain.ParticipantEvent{
+		{Event: *first, ParticipantID: participant.ID, ParticipantStatus: domain.ParticipantStatusConfirmed},
+		{Event: *second, ParticipantID: uuid.New(), ParticipantStatus: domain.ParticipantStatusPending},
+	}, nil)
+
+	msg := newTestInboundMessage("whatsapp", "wamid.status", domain.InboundMessageText)
+	msg.Text = "status"
+	require.NoError(t, svc.Handle(ctx, msg))
+
+	// Uma única consulta, sem buscar cada evento em seguida
+	deps.eventRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+	deps.participantRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	require.Len(t, deps.sender.messages["5511999999999"], 1)
+	reply := deps.sender.messages["5511999999999"][0]
+	assert.Contains(t, reply, "Culto de domingo")
+	assert.Contains(t, reply, dto.FormatEventTime(first, first.StartTime))
+	assert.Contains(t, reply, participantStatusLabels[domain.ParticipantStatusConfirmed])
+	assert.Contains(t, reply, "Ensaio do coral")
+	assert.Contains(t, reply, dto.FormatEventTime(second, second.StartTime))
+	assert.Contains(t, reply, participantStatusLabels[domain.ParticipantStatusPending])
+}
+
+func TestInboundMessageService_Handle_StatusWithoutActiveEvents(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	participant := testutil.NewTestParticipant()
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	deps.eventRepo.On("ListActiveByParticipantPhone", mock.Anything, "5511999999999").Return([]*domain.ParticipantEvent{}, nil)
+
+	msg := newTestInboundMessage("whatsapp", "wamid.status", domain.InboundMessageText)
+	msg.Text = "status"
+	require.NoError(t, svc.Handle(ctx, msg))
+
+	assert.Equal(t, []string{"📅 Você não tem eventos ativos no momento."}, deps.sender.messages["5511999999999"])
+}
+
+func TestInboundMessageService_Handle_LocationConsentKeywords(t *testing.T) {
+	tests := []struct {
+		text    string
+		consent bool
+	}{
+		{text: "Compartilhar localização", consent: true},
+		{text: "share my location", consent: true},
+		{text: "parar localização", consent: false},
+		{text: "stop sharing location", consent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestInboundMessageService(t)
+
+			participant := testutil.NewTestParticipant()
+			deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+			deps.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+			deps.participantRepo.On("SetLocationConsent", mock.Anything, participant.ID, participant.EntityID, tt.consent).Return(nil)
+
+			msg := newTestInboundMessage("whatsapp", "wamid.consent", domain.InboundMessageText)
+			msg.Text = tt.text
+			require.NoError(t, svc.Handle(ctx, msg))
+
+			deps.participantRepo.AssertCalled(t, "SetLocationConsent", mock.Anything, participant.ID, participant.EntityID, tt.consent)
+			deps.participantRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			assert.Len(t, deps.sender.messages["5511999999999"], 1)
+		})
+	}
+}
+
+func TestInboundMessageService_Handle_LocationWithoutConsentKeepsClaim(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	participant := testutil.NewTestParticipant()
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	deps.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+
+	msg := newTestInboundMessage("whatsapp", "wamid.location", domain.InboundMessageLocation)
+	msg.Location = &domain.InboundLocation{Latitude: -23.55, Longitude: -46.63}
+	assert.ErrorIs(t, svc.Handle(ctx, msg), domain.ErrLocationConsentRequired)
+	require.NoError(t, svc.Handle(ctx, msg))
+
+	deps.locationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	deps.participantRepo.AssertNumberOfCalls(t, "GetActiveByPhoneNumber", 1)
+}
+
+func TestInboundMessageService_Handle_CapturesDeclineReasonAfterDenial(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	participant := testutil.NewTestParticipant()
+	event := testutil.NewTestEvent()
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+	deps.participantRepo.On("UpdateStatus", mock.Anything, participant.ID, participant.EntityID, domain.ParticipantStatusDenied).Return(nil)
+	deps.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+	deps.participantRepo.On("SetDeclineReason", mock.Anything, participant.ID, participant.EntityID, mock.MatchedBy(func(reason *string) bool {
+		return reason != nil && *reason == "Tenho consulta médica"
+	})).Return(nil)
+
+	deny := newTestInboundMessage("whatsapp", "wamid.1", domain.InboundMessageText)
+	deny.Text = "não vou"
+	require.NoError(t, svc.Handle(ctx, deny))
+
+	// A recusa pede o motivo
+	require.Len(t, deps.sender.messages[deny.From], 1)
+	deps.participantRepo.AssertNotCalled(t, "SetDeclineReason", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	reason := newTestInboundMessage("whatsapp", "wamid.2", domain.InboundMessageText)
+	reason.Text = "  Tenho consulta médica "
+	require.NoError(t, svc.Handle(ctx, reason))
+	deps.participantRepo.AssertNumberOfCalls(t, "SetDeclineReason", 1)
+	deps.participantRepo.AssertNumberOfCalls(t, "UpdateStatus", 1)
+	assert.Len(t, deps.sender.messages[deny.From], 2)
+
+	// Motivo já registrado: uma mensagem nova não é mais tratada como justificativa
+	other := newTestInboundMessage("whatsapp", "wamid.3", domain.InboundMessageText)
+	other.Text = "Tenho consulta médica"
+	assert.ErrorIs(t, svc.Handle(ctx, other), ErrUnrecognizedReply)
+	deps.participantRepo.AssertNumberOfCalls(t, "SetDeclineReason", 1)
+}
+
+func TestInboundMessageService_Handle_RepliesWhenEventIsFull(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusPending
+	event := testutil.NewTestEvent()
+	capacity := 2
+	event.Capacity = &capacity
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	deps.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+	deps.transactor.On("WithinTransaction", mock.Anything).Return(nil)
+	deps.eventRepo.On("GetForUpdate", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+	deps.participantRepo.On("CountByEventStatus", mock.Anything, participant.EventID, participant.EntityID).
+		Return(map[domain.ParticipantStatus]int{domain.ParticipantStatusConfirmed: 1, domain.ParticipantStatusCheckedIn: 1}, nil)
+
+	msg := newTestInboundMessage("whatsapp", "wamid.1", domain.InboundMessageText)
+	msg.Text = "sim"
+
+	// Lotado não é falha de entrega: o participante é avisado e o webhook não é reenviado
+	require.NoError(t, svc.Handle(ctx, msg))
+	deps.participantRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	require.Len(t, deps.sender.messages[msg.From], 1)
+	assert.Contains(t, deps.sender.messages[msg.From][0], "vagas")
+}
+
+func TestInboundMessageService_Handle_ConfirmationClearsPendingDeclineReason(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+
+	participant := testutil.NewTestParticipant()
+	event := testutil.NewTestEvent()
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, "5511999999999").Return(participant, nil)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(event, nil)
+	deps.participantRepo.On("UpdateStatus", mock.Anything, participant.ID, participant.EntityID, mock.Anything).Return(nil)
+	deps.participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil)
+
+	for i, text := range []string{"não vou", "sim"} {
+		msg := newTestInboundMessage("whatsapp", "wamid."+string(rune('1'+i)), domain.InboundMessageText)
+		msg.Text = text
+		require.NoError(t, svc.Handle(ctx, msg))
+	}
+
+	// Mudou de ideia: o texto seguinte não vira motivo de recusa
+	msg := newTestInboundMessage("whatsapp", "wamid.3", domain.InboundMessageText)
+	msg.Text = "até lá"
+	assert.ErrorIs(t, svc.Handle(ctx, msg), ErrUnrecognizedReply)
+	deps.participantRepo.AssertNotCalled(t, "SetDeclineReason", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -1,24 +1,40 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"slices"
 	"time"
 
 	"event-coming/internal/cache"
+	"event-coming/internal/config"
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
 	"event-coming/internal/repository"
+	"event-coming/internal/service/eta"
+	"event-coming/internal/websocket"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// maxLocationClockSkew é quanto o timestamp de uma localização pode estar à frente do
+// relógio do servidor
+const maxLocationClockSkew = time.Minute
+
 // LocationService handles location business logic
 type LocationService struct {
 	locationRepo    repository.LocationRepository
 	participantRepo repository.ParticipantRepository
 	eventRepo       repository.EventRepository
 	locationBuffer  *cache.LocationBuffer
+	etaPublisher    ETAPublisher
+	etaDebouncer    *etaDebouncer
+	staleAfter      time.Duration
+	minInterval     time.Duration
+	maxSpeed        float64
+	maxBatchSize    int
 	logger          *zap.Logger
 }
 
@@ -28,6 +44,8 @@ func NewLocationService(
 	participantRepo repository.ParticipantRepository,
 	eventRepo repository.EventRepository,
 	locationBuffer *cache.LocationBuffer,
+	etaPublisher ETAPublisher,
+	locationConfig *config.LocationConfig,
 	logger *zap.Logger,
 ) *LocationService {
 	return &LocationService{
@@ -35,6 +53,12 @@ func NewLocationService(
 		participantRepo: participantRepo,
 		eventRepo:       eventRepo,
 		locationBuffer:  locationBuffer,
+		etaPublisher:    etaPublisher,
+		etaDebouncer:    newETADebouncer(locationConfig.ETAUpdateThreshold, locationConfig.ETAUpdateInterval),
+		staleAfter:      locationConfig.StaleAfter,
+		minInterval:     locationConfig.MinUpdateInterval,
+		maxSpeed:        locationConfig.MaxSpeed,
+		maxBatchSize:    locationConfig.MaxBatchSize,
 		logger:          logger,
 	}
 }
@@ -46,13 +70,157 @@ func (s *LocationService) CreateLocation(
 	entityID uuid.UUID,
 	req *dto.CreateLocationRequest,
 ) (*dto.LocationResponse, error) {
+	participant, event, err := s.trackedParticipant(ctx, participantID, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	location, err := newLocation(participant, req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := s.cachedLatest(ctx, location)
+	if s.throttled(location, latest) {
+		resp := dto.ToLocationResponse(location)
+		resp.Throttled = true
+		return resp, nil
+	}
+
+	// An out-of-order point is kept as history but doesn't replace the latest position
+	current := latest == nil || !location.Timestamp.Before(latest.Timestamp)
+
+	if current && s.implausibleJump(latest, location) {
+		return nil, fmt.Errorf("%w: too far from the previous position", domain.ErrInvalidInput)
+	}
+
+	if current {
+		s.cacheLatest(ctx, event, location)
+	}
+
+	// Save to database
+	if err := s.locationRepo.Create(ctx, location); err != nil {
+		return nil, err
+	}
+
+	if current && event != nil {
+		s.publishETA(ctx, event, location)
+	}
+
+	return dto.ToLocationResponse(location), nil
+}
+
+// CreateLocationsBatch saves the points a participant's app buffered while offline. Points
+// are checked in timestamp order: each one is throttled (by timestamp, since they all arrive
+// together) and jump-checked against the previous accepted point, or the cached latest
+// position when it's older. Invalid points are reported by index without failing the others,
+// and only the newest accepted point can replace the latest position
+func (s *LocationService) CreateLocationsBatch(
+	ctx context.Context,
+	participantID uuid.UUID,
+	entityID uuid.UUID,
+	reqs []dto.CreateLocationRequest,
+) (*dto.BatchCreateLocationsResponse, error) {
+	if s.maxBatchSize > 0 && len(reqs) > s.maxBatchSize {
+		return nil, fmt.Errorf("%d locations, limit is %d: %w", len(reqs), s.maxBatchSize, domain.ErrBatchTooLarge)
+	}
+
+	participant, event, err := s.trackedParticipant(ctx, participantID, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.BatchCreateLocationsResponse{Locations: []*dto.LocationResponse{}}
+
+	type indexedLocation struct {
+		index    int
+		location *domain.Location
+	}
+	now := time.Now()
+	points := make([]indexedLocation, 0, len(reqs))
+	for i := range reqs {
+		location, err := newLocation(participant, &reqs[i], now)
+		if err != nil {
+			result.Rejected++
+			result.Errors = append(result.Errors, fmt.Sprintf("location[%d]: %v", i, err))
+			continue
+		}
+		points = append(points, indexedLocation{index: i, location: location})
+	}
+	slices.SortStableFunc(points, func(a, b indexedLocation) int {
+		return a.location.Timestamp.Compare(b.location.Timestamp)
+	})
+
+	var latest *domain.Location
+	if len(points) > 0 {
+		latest = s.cachedLatest(ctx, points[0].location)
+	}
+
+	// Reference for the checks: the cached position only if the batch continues from it
+	previous := latest
+	if previous != nil && len(points) > 0 && previous.Timestamp.After(points[0].location.Timestamp) {
+		previous = nil
+	}
+
+	accepted := make([]*domain.Location, 0, len(points))
+	for _, p := range points {
+		if previous != nil && p.location.Timestamp.Sub(previous.Timestamp) < s.minInterval {
+			result.Throttled++
+			continue
+		}
+		if s.implausibleJump(previous, p.location) {
+			result.Rejected++
+			result.Errors = append(result.Errors, fmt.Sprintf("location[%d]: %v: too far from the previous position", p.index, domain.ErrInvalidInput))
+			continue
+		}
+		accepted = append(accepted, p.location)
+		previous = p.location
+	}
+
+	if len(accepted) == 0 {
+		return result, nil
+	}
+
+	if err := s.locationRepo.BatchCreate(ctx, accepted); err != nil {
+		return nil, err
+	}
+
+	newest := accepted[len(accepted)-1]
+	if latest == nil || !newest.Timestamp.Before(latest.Timestamp) {
+		s.cacheLatest(ctx, event, newest)
+		if event != nil {
+			s.publishETA(ctx, event, newest)
+		}
+	}
+
+	result.Accepted = len(accepted)
+	for _, location := range accepted {
+		result.Locations = append(result.Locations, dto.ToLocationResponse(location))
+	}
+	return result, nil
+}
+
+// trackedParticipant loads a participant allowed to send locations: one who consented to
+// location sharing, in an event with location tracking enabled. The event is nil if it
+// can't be loaded
+func (s *LocationService) trackedParticipant(ctx context.Context, participantID, entityID uuid.UUID) (*domain.Participant, *domain.Event, error) {
 	// Get participant to validate and get event info
 	participant, err := s.participantRepo.GetByID(ctx, participantID, entityID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if participant == nil {
-		return nil, domain.ErrNotFound
+		return nil, nil, domain.ErrNotFound
+	}
+
+	// Only store GPS data for participants who opted in to location sharing
+	if !participant.LocationConsent {
+		s.logger.Warn("Rejected location from participant without consent",
+			zap.String("participant_id", participantID.String()),
+			zap.String("event_id", participant.EventID.String()),
+		)
+		return nil, nil, domain.ErrLocationConsentRequired
 	}
 
 	// Get event to use endTime for cache TTL
@@ -61,16 +229,37 @@ func (s *LocationService) CreateLocation(
 		s.logger.Warn("Failed to get event for cache TTL", zap.Error(err))
 	}
 
-	timestamp := time.Now()
+	// Events with location tracking disabled don't store GPS data at all
+	if event != nil && !event.Features.LocationTrackingEnabled() {
+		s.logger.Warn("Rejected location for event with tracking disabled",
+			zap.String("participant_id", participantID.String()),
+			zap.String("event_id", participant.EventID.String()),
+		)
+		return nil, nil, domain.ErrFeatureDisabled
+	}
+
+	return participant, event, nil
+}
+
+// newLocation builds the participant's location from the request, received at now. Points
+// without a timestamp are taken as measured on arrival
+func newLocation(participant *domain.Participant, req *dto.CreateLocationRequest, now time.Time) (*domain.Location, error) {
+	if req.Latitude < -90 || req.Latitude > 90 || req.Longitude < -180 || req.Longitude > 180 {
+		return nil, fmt.Errorf("%w: coordinates out of range", domain.ErrInvalidInput)
+	}
+	if req.Timestamp != nil && req.Timestamp.After(now.Add(maxLocationClockSkew)) {
+		return nil, fmt.Errorf("%w: timestamp is in the future", domain.ErrInvalidInput)
+	}
+	timestamp := now
 	if req.Timestamp != nil {
 		timestamp = *req.Timestamp
 	}
 
-	location := &domain.Location{
+	return &domain.Location{
 		ID:            uuid.New(),
-		ParticipantID: participantID,
+		ParticipantID: participant.ID,
 		EventID:       participant.EventID,
-		EntityID:      entityID,
+		EntityID:      participant.EntityID,
 		Latitude:      req.Latitude,
 		Longitude:     req.Longitude,
 		Accuracy:      req.Accuracy,
@@ -78,29 +267,113 @@ func (s *LocationService) CreateLocation(
 		Speed:         req.Speed,
 		Heading:       req.Heading,
 		Timestamp:     timestamp,
+		CreatedAt:     now,
+	}, nil
+}
+
+// cacheLatest saves the location as the participant's latest position in Redis, with a TTL
+// based on the event end time
+func (s *LocationService) cacheLatest(ctx context.Context, event *domain.Event, location *domain.Location) {
+	if s.locationBuffer == nil {
+		return
 	}
 
-	// Save to Redis cache with TTL based on event end time
-	if s.locationBuffer != nil {
-		if event != nil && event.EndTime != nil {
-			// Use event end time for TTL
-			if err := s.locationBuffer.SetLatestLocation(ctx, location, *event.EndTime); err != nil {
-				s.logger.Warn("Failed to set latest location in cache", zap.Error(err))
-			}
-		} else {
-			// Fallback to default 24h TTL
-			if err := s.locationBuffer.Push(ctx, location); err != nil {
-				s.logger.Warn("Failed to push location to buffer", zap.Error(err))
-			}
+	if event != nil && event.EndTime != nil {
+		// Use event end time for TTL
+		if err := s.locationBuffer.SetLatestLocation(ctx, location, *event.EndTime); err != nil {
+			s.logger.Warn("Failed to set latest location in cache", zap.Error(err))
 		}
+		return
 	}
 
-	// Save to database
-	if err := s.locationRepo.Create(ctx, location); err != nil {
-		return nil, err
+	// Fallback to default 24h TTL
+	if err := s.locationBuffer.Push(ctx, location); err != nil {
+		s.logger.Warn("Failed to push location to buffer", zap.Error(err))
 	}
+}
 
-	return dto.ToLocationResponse(location), nil
+// cachedLatest returns the participant's latest accepted location from the Redis buffer,
+// or nil if there is none or Redis can't be read
+func (s *LocationService) cachedLatest(ctx context.Context, location *domain.Location) *domain.Location {
+	if s.locationBuffer == nil {
+		return nil
+	}
+
+	latest, err := s.locationBuffer.GetLatestLocation(ctx, location.EventID, location.ParticipantID)
+	if err != nil {
+		s.logger.Warn("Failed to get latest location", zap.Error(err))
+		return nil
+	}
+	return latest
+}
+
+// throttled reports whether the location was received less than minInterval after the
+// latest accepted one in the Redis buffer. Both are compared by server receive time
+// (CreatedAt), so a client clock can't get around the limit. Without a cached position
+// (or if Redis can't be read) the location is accepted
+func (s *LocationService) throttled(location, latest *domain.Location) bool {
+	if s.minInterval <= 0 || latest == nil {
+		return false
+	}
+	if location.CreatedAt.Sub(latest.CreatedAt) >= s.minInterval {
+		return false
+	}
+
+	s.logger.Debug("Location update throttled",
+		zap.String("participant_id", location.ParticipantID.String()),
+		zap.Time("received_at", location.CreatedAt),
+		zap.Time("latest_received_at", latest.CreatedAt),
+	)
+	return true
+}
+
+// implausibleJump reports whether reaching the location from the previous one (measured
+// before it) would take a speed above maxSpeed, i.e. a GPS glitch. Disabled with maxSpeed 0
+func (s *LocationService) implausibleJump(previous, location *domain.Location) bool {
+	if s.maxSpeed <= 0 || previous == nil {
+		return false
+	}
+
+	distance := eta.CalculateHaversineDistance(previous.Latitude, previous.Longitude, location.Latitude, location.Longitude)
+	elapsed := location.Timestamp.Sub(previous.Timestamp).Seconds()
+	if elapsed <= 0 {
+		// Same instant: only a point at the same place is plausible
+		return distance > 0
+	}
+	if distance/elapsed <= s.maxSpeed {
+		return false
+	}
+
+	s.logger.Debug("Location jump rejected",
+		zap.String("participant_id", location.ParticipantID.String()),
+		zap.Float64("distance_meters", distance),
+		zap.Float64("elapsed_seconds", elapsed),
+	)
+	return true
+}
+
+// publishETA recalcula o ETA do participante a partir da nova localização e o publica no
+// canal do evento, respeitando o debounce para não gerar uma mensagem a cada ponto de GPS
+func (s *LocationService) publishETA(ctx context.Context, event *domain.Event, location *domain.Location) {
+	if s.etaPublisher == nil || !event.HasCoordinates() {
+		return
+	}
+
+	distance, etaMinutes := estimateArrival(location, event)
+	if !s.etaDebouncer.ShouldPublish(location.ParticipantID, etaMinutes, time.Now()) {
+		return
+	}
+
+	if err := s.etaPublisher.PublishETAUpdate(ctx, location.EntityID.String(), location.EventID.String(), &websocket.ETAUpdateData{
+		ParticipantID:  location.ParticipantID.String(),
+		ETAMinutes:     etaMinutes,
+		DistanceMeters: distance,
+	}); err != nil {
+		s.logger.Warn("Failed to publish ETA update",
+			zap.String("participant_id", location.ParticipantID.String()),
+			zap.Error(err),
+		)
+	}
 }
 
 // GetLatestLocation gets the latest location for a participant
@@ -149,11 +422,44 @@ func (s *LocationService) GetLocationHistory(
 }
 
 // GetEventLocations gets latest locations for all participants in an event
-// First tries Redis cache, then falls back to database
+// First tries Redis cache, then falls back to database. With byDistance, the closest
+// participants come first.
 func (s *LocationService) GetEventLocations(
 	ctx context.Context,
 	eventID uuid.UUID,
 	entityID uuid.UUID,
+	byDistance bool,
+) ([]*dto.LocationResponse, error) {
+	responses, err := s.latestEventLocations(ctx, eventID, entityID)
+	if err != nil || !byDistance {
+		return responses, err
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, eventID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.HasCoordinates() {
+		return responses, nil
+	}
+
+	lat, lng := event.TargetCoordinates()
+	for _, r := range responses {
+		distance := eta.CalculateHaversineDistance(r.Latitude, r.Longitude, lat, lng)
+		r.DistanceMeters = &distance
+	}
+	slices.SortStableFunc(responses, func(a, b *dto.LocationResponse) int {
+		return compareDistance(a.DistanceMeters, b.DistanceMeters)
+	})
+	return responses, nil
+}
+
+// latestEventLocations returns the latest location of each participant, from the cache
+// when available
+func (s *LocationService) latestEventLocations(
+	ctx context.Context,
+	eventID uuid.UUID,
+	entityID uuid.UUID,
 ) ([]*dto.LocationResponse, error) {
 	// Try to get participant IDs for this event to check cache
 	if s.locationBuffer != nil {
@@ -168,7 +474,7 @@ func (s *LocationService) GetEventLocations(
 			if err != nil {
 				s.logger.Warn("Failed to get locations from cache", zap.Error(err))
 			} else if len(cachedLocations) > 0 {
-				return dto.ToLocationResponseList(cachedLocations), nil
+				return s.toLiveLocationResponses(cachedLocations), nil
 			}
 		}
 	}
@@ -178,5 +484,156 @@ func (s *LocationService) GetEventLocations(
 	if err != nil {
 		return nil, err
 	}
-	return dto.ToLocationResponseList(locations), nil
+	return s.toLiveLocationResponses(locations), nil
+}
+
+// toLiveLocationResponses converte as últimas posições marcando as que estão stale
+func (s *LocationService) toLiveLocationResponses(locations []*domain.Location) []*dto.LocationResponse {
+	now := time.Now()
+	responses := make([]*dto.LocationResponse, len(locations))
+	for i, loc := range locations {
+		responses[i] = dto.ToLocationResponseWithStaleness(loc, now, s.staleAfter)
+	}
+	return responses
+}
+
+// GetLiveEventLocations builds a live snapshot of every participant in an event. Latest
+// positions come from the Redis buffer, falling back to the database for participants
+// without a buffered location. With byDistance, the closest participants come first and
+// those without a distance keep their order at the end. A perPage above zero returns
+// only that page of the snapshot; the total number of participants is returned either way.
+func (s *LocationService) GetLiveEventLocations(
+	ctx context.Context,
+	entID uuid.UUID,
+	eventID uuid.UUID,
+	byDistance bool,
+	page, perPage int,
+) (*dto.LiveEventLocationsResponse, int64, error) {
+	participants, err := s.participantRepo.ListAllByEvent(ctx, eventID, entID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	snapshot, err := s.liveSnapshot(ctx, entID, eventID, participants)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if byDistance {
+		slices.SortStableFunc(snapshot.Participants, func(a, b *dto.LiveParticipantLocation) int {
+			return compareDistance(a.DistanceMeters, b.DistanceMeters)
+		})
+	}
+
+	total := int64(len(snapshot.Participants))
+	if perPage > 0 {
+		start := min((page-1)*perPage, len(snapshot.Participants))
+		end := min(start+perPage, len(snapshot.Participants))
+		snapshot.Participants = snapshot.Participants[start:end]
+	}
+	return snapshot, total, nil
+}
+
+// liveSnapshot builds the live snapshot for the given participants of an event
+func (s *LocationService) liveSnapshot(
+	ctx context.Context,
+	entID uuid.UUID,
+	eventID uuid.UUID,
+	participants []*domain.Participant,
+) (*dto.LiveEventLocationsResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	participantIDs := make([]uuid.UUID, len(participants))
+	for i, p := range participants {
+		participantIDs[i] = p.ID
+	}
+
+	// Freshest data: Redis buffer
+	latest := make(map[uuid.UUID]*domain.Location, len(participants))
+	sources := make(map[uuid.UUID]string, len(participants))
+	if s.locationBuffer != nil && len(participantIDs) > 0 {
+		cachedLocations, err := s.locationBuffer.GetLatestLocationsForEvent(ctx, eventID, participantIDs)
+		if err != nil {
+			s.logger.Warn("Failed to get live locations from cache", zap.Error(err))
+		}
+		for _, loc := range cachedLocations {
+			latest[loc.ParticipantID] = loc
+			sources[loc.ParticipantID] = dto.LocationSourceCache
+		}
+	}
+
+	// Fallback to database for participants missing from the buffer
+	if len(latest) < len(participants) {
+		dbLocations, err := s.locationRepo.GetLatestByEvent(ctx, eventID, entID)
+		if err != nil {
+			return nil, err
+		}
+		for _, loc := range dbLocations {
+			if _, ok := latest[loc.ParticipantID]; ok {
+				continue
+			}
+			latest[loc.ParticipantID] = loc
+			sources[loc.ParticipantID] = dto.LocationSourceDatabase
+		}
+	}
+
+	snapshot := &dto.LiveEventLocationsResponse{
+		EventID:      eventID,
+		EntityID:     entID,
+		Participants: make([]*dto.LiveParticipantLocation, 0, len(participants)),
+		FetchedAt:    time.Now(),
+	}
+
+	for _, p := range participants {
+		item := &dto.LiveParticipantLocation{
+			ParticipantID: p.ID,
+			Status:        p.Status,
+		}
+
+		if loc, ok := latest[p.ID]; ok {
+			item.Location = dto.ToLocationResponseWithStaleness(loc, snapshot.FetchedAt, s.staleAfter)
+			item.Source = sources[p.ID]
+			if event.HasCoordinates() {
+				distance, etaMinutes := estimateArrival(loc, event)
+				item.DistanceMeters = &distance
+				item.ETAMinutes = &etaMinutes
+			}
+			snapshot.TotalTracked++
+		}
+
+		snapshot.Participants = append(snapshot.Participants, item)
+	}
+
+	return snapshot, nil
+}
+
+// compareDistance orders distances ascending, with unknown (nil) distances last
+func compareDistance(a, b *float64) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return cmp.Compare(*a, *b)
+}
+
+// estimateArrival returns the straight-line distance (meters) and ETA (minutes)
+// from a location to the event's target location (see Event.TargetCoordinates).
+// Uses the reported speed when available, otherwise assumes an average of 30 km/h.
+func estimateArrival(loc *domain.Location, event *domain.Event) (float64, int) {
+	lat, lng := event.TargetCoordinates()
+	distance := eta.CalculateHaversineDistance(loc.Latitude, loc.Longitude, lat, lng)
+
+	velocity := 30000.0 / 3600.0
+	if loc.Speed != nil && *loc.Speed > 0 {
+		velocity = *loc.Speed
+	}
+
+	return distance, eta.NewVelocityCalculator().CalculateETA(distance, velocity)
 }
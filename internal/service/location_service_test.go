@@ -0,0 +1,590 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+	"event-coming/internal/websocket"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type locationServiceDeps struct {
+	locationRepo    *mocks.MockLocationRepository
+	participantRepo *mocks.MockParticipantRepository
+	eventRepo       *mocks.MockEventRepository
+	buffer          *cache.LocationBuffer
+}
+
+func newTestLocationService(t *testing.T) (*LocationService, *locationServiceDeps) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	deps := &locationServiceDeps{
+		locationRepo:    new(mocks.MockLocationRepository),
+		participantRepo: new(mocks.MockParticipantRepository),
+		eventRepo:       new(mocks.MockEventRepository),
+		buffer:          cache.NewLocationBuffer(client),
+	}
+	svc := NewLocationService(deps.locationRepo, deps.participantRepo, deps.eventRepo, deps.buffer, nil, &config.LocationConfig{StaleAfter: 5 * time.Minute}, zap.NewNop())
+	return svc, deps
+}
+
+func newTestEventParticipants(n int) []*domain.Participant {
+	participants := make([]*domain.Participant, n)
+	for i := range participants {
+		p := testutil.NewTestParticipant()
+		p.ID = uuid.New()
+		participants[i] = p
+	}
+	return participants
+}
+
+func newTestParticipantLocation(participantID uuid.UUID, lat, lng float64) *domain.Location {
+	loc := testutil.NewTestLocation()
+	loc.ID = uuid.New()
+	loc.ParticipantID = participantID
+	loc.Latitude = lat
+	loc.Longitude = lng
+	return loc
+}
+
+func TestLocationService_GetLiveEventLocations_MergesBufferAndDatabase(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(3)
+	buffered, dbOnly, untracked := participants[0], participants[1], participants[2]
+
+	cached := newTestParticipantLocation(buffered.ID, -23.55, -46.63)
+	require.NoError(t, deps.buffer.SetLatestLocation(ctx, cached, time.Now().Add(time.Hour)))
+
+	// The database has an older position for the buffered participant, which must be ignored
+	staleDB := newTestParticipantLocation(buffered.ID, -23.60, -46.70)
+	fromDB := newTestParticipantLocation(dbOnly.ID, -23.58, -46.66)
+
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(participants, nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.locationRepo.On("GetLatestByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.Location{staleDB, fromDB}, nil)
+
+	snapshot, total, err := svc.GetLiveEventLocations(ctx, event.EntityID, event.ID, false, 0, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, 2, snapshot.TotalTracked)
+	require.Len(t, snapshot.Participants, 3)
+
+	got := snapshot.Participants[0]
+	assert.Equal(t, buffered.ID, got.ParticipantID)
+	assert.Equal(t, dto.LocationSourceCache, got.Source)
+	require.NotNil(t, got.Location)
+	assert.Equal(t, cached.Latitude, got.Location.Latitude)
+	assert.NotNil(t, got.DistanceMeters)
+	assert.NotNil(t, got.ETAMinutes)
+
+	got = snapshot.Participants[1]
+	assert.Equal(t, dbOnly.ID, got.ParticipantID)
+	assert.Equal(t, dto.LocationSourceDatabase, got.Source)
+	require.NotNil(t, got.Location)
+	assert.Equal(t, fromDB.Latitude, got.Location.Latitude)
+
+	got = snapshot.Participants[2]
+	assert.Equal(t, untracked.ID, got.ParticipantID)
+	assert.Nil(t, got.Location)
+	assert.Nil(t, got.DistanceMeters)
+	assert.Empty(t, got.Source)
+}
+
+func TestLocationService_GetLiveEventLocations_SkipsDatabaseWhenAllBuffered(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(2)
+	for _, p := range participants {
+		loc := newTestParticipantLocation(p.ID, -23.55, -46.63)
+		require.NoError(t, deps.buffer.SetLatestLocation(ctx, loc, time.Now().Add(time.Hour)))
+	}
+
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(participants, nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+
+	snapshot, _, err := svc.GetLiveEventLocations(ctx, event.EntityID, event.ID, false, 0, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, snapshot.TotalTracked)
+	deps.locationRepo.AssertNotCalled(t, "GetLatestByEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLocationService_GetLiveEventLocations_Paginates(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(5)
+
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(participants, nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.locationRepo.On("GetLatestByEvent", ctx, event.ID, event.EntityID).Return([]*domain.Location{}, nil)
+
+	snapshot, total, err := svc.GetLiveEventLocations(ctx, event.EntityID, event.ID, false, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	require.Len(t, snapshot.Participants, 2)
+	assert.Equal(t, participants[2].ID, snapshot.Participants[0].ParticipantID)
+	assert.Equal(t, participants[3].ID, snapshot.Participants[1].ParticipantID)
+
+	snapshot, _, err = svc.GetLiveEventLocations(ctx, event.EntityID, event.ID, false, 4, 2)
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.Participants)
+}
+
+func TestLocationService_GetLiveEventLocations_SortsByDistance(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(4)
+	far, untracked, near, middle := participants[0], participants[1], participants[2], participants[3]
+
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(participants, nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.locationRepo.On("GetLatestByEvent", ctx, event.ID, event.EntityID).Return([]*domain.Location{
+		newTestParticipantLocation(far.ID, -23.70, -46.80),
+		newTestParticipantLocation(near.ID, -23.5506, -46.6334),
+		newTestParticipantLocation(middle.ID, -23.60, -46.66),
+	}, nil)
+
+	snapshot, _, err := svc.GetLiveEventLocations(ctx, event.EntityID, event.ID, true, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Participants, 4)
+
+	// Participants without a position go last
+	assert.Equal(t, near.ID, snapshot.Participants[0].ParticipantID)
+	assert.Equal(t, middle.ID, snapshot.Participants[1].ParticipantID)
+	assert.Equal(t, far.ID, snapshot.Participants[2].ParticipantID)
+	assert.Equal(t, untracked.ID, snapshot.Participants[3].ParticipantID)
+
+	// Sorting happens before paging
+	snapshot, _, err = svc.GetLiveEventLocations(ctx, event.EntityID, event.ID, true, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Participants, 1)
+	assert.Equal(t, near.ID, snapshot.Participants[0].ParticipantID)
+}
+
+func TestLocationService_GetEventLocations_SortsByDistance(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(3)
+	far := newTestParticipantLocation(participants[0].ID, -23.70, -46.80)
+	near := newTestParticipantLocation(participants[1].ID, -23.5506, -46.6334)
+	middle := newTestParticipantLocation(participants[2].ID, -23.60, -46.66)
+
+	deps.participantRepo.On("ListByEvent", ctx, event.ID, event.EntityID, 1, 1000).Return(participants, int64(3), nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.locationRepo.On("GetLatestByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.Location{far, near, middle}, nil)
+
+	locations, err := svc.GetEventLocations(ctx, event.ID, event.EntityID, true)
+	require.NoError(t, err)
+	require.Len(t, locations, 3)
+	assert.Equal(t, near.ID, locations[0].ID)
+	assert.Equal(t, middle.ID, locations[1].ID)
+	assert.Equal(t, far.ID, locations[2].ID)
+	for _, loc := range locations {
+		assert.NotNil(t, loc.DistanceMeters)
+	}
+
+	// The default keeps insertion order and skips the event lookup
+	locations, err = svc.GetEventLocations(ctx, event.ID, event.EntityID, false)
+	require.NoError(t, err)
+	assert.Equal(t, far.ID, locations[0].ID)
+	assert.Nil(t, locations[0].DistanceMeters)
+	deps.eventRepo.AssertNumberOfCalls(t, "GetByID", 1)
+}
+
+func TestLocationService_GetLiveEventLocations_EventNotFound(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+
+	deps.participantRepo.On("ListAllByEvent", ctx, testutil.TestEventID, testutil.TestEntityID).
+		Return([]*domain.Participant{}, nil)
+	deps.eventRepo.On("GetByID", ctx, testutil.TestEventID, testutil.TestEntityID).Return(nil, domain.ErrNotFound)
+
+	_, _, err := svc.GetLiveEventLocations(ctx, testutil.TestEntityID, testutil.TestEventID, false, 0, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestLocationService_CreateLocation_RequiresConsent(t *testing.T) {
+	tests := []struct {
+		name    string
+		consent bool
+		wantErr error
+	}{
+		{name: "without consent", consent: false, wantErr: domain.ErrLocationConsentRequired},
+		{name: "with consent", consent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestLocationService(t)
+
+			participant := testutil.NewTestParticipant()
+			participant.LocationConsent = tt.consent
+			deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+			deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+			deps.locationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Location")).Return(nil)
+
+			_, err := svc.CreateLocation(ctx, participant.ID, testutil.TestEntityID, &dto.CreateLocationRequest{Latitude: -23.55, Longitude: -46.63})
+
+			latest, cacheErr := deps.buffer.GetLatestLocation(ctx, participant.EventID, participant.ID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				deps.locationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				assert.Nil(t, latest)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, cacheErr)
+			deps.locationRepo.AssertNumberOfCalls(t, "Create", 1)
+			assert.NotNil(t, latest)
+		})
+	}
+}
+
+func TestLocationService_GetLiveEventLocations_FlagsStalePositions(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+
+	event := testutil.NewTestEvent()
+	participants := newTestEventParticipants(2)
+	fresh := newTestParticipantLocation(participants[0].ID, -23.55, -46.63)
+	fresh.Timestamp = time.Now().Add(-time.Minute)
+	stale := newTestParticipantLocation(participants[1].ID, -23.58, -46.66)
+	stale.Timestamp = time.Now().Add(-20 * time.Minute)
+
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(participants, nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.locationRepo.On("GetLatestByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.Location{fresh, stale}, nil)
+
+	snapshot, _, err := svc.GetLiveEventLocations(ctx, event.EntityID, event.ID, false, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Participants, 2)
+
+	got := snapshot.Participants[0].Location
+	require.NotNil(t, got)
+	require.NotNil(t, got.IsStale)
+	assert.False(t, *got.IsStale)
+	assert.InDelta(t, 60, *got.StaleSeconds, 2)
+
+	got = snapshot.Participants[1].Location
+	require.NotNil(t, got)
+	require.NotNil(t, got.IsStale)
+	assert.True(t, *got.IsStale)
+	assert.InDelta(t, 1200, *got.StaleSeconds, 2)
+}
+
+// recordingETAPublisher guarda os eta_update publicados
+type recordingETAPublisher struct {
+	published []*websocket.ETAUpdateData
+}
+
+func (p *recordingETAPublisher) PublishETAUpdate(ctx context.Context, entityID, eventID string, data *websocket.ETAUpdateData) error {
+	p.published = append(p.published, data)
+	return nil
+}
+
+func TestLocationService_CreateLocation_PublishesETAWhenItChanges(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+	publisher := &recordingETAPublisher{}
+	svc.etaPublisher = publisher
+	svc.etaDebouncer = newETADebouncer(3, 0)
+
+	participant := testutil.NewTestParticipant()
+	participant.LocationConsent = true
+	event := testutil.NewTestEvent()
+	event.LocationLat = -23.55
+	event.LocationLng = -46.63
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(event, nil)
+	deps.locationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Location")).Return(nil)
+
+	// ~10 km, ~1 km a menos (abaixo do limite) e ~5 km do evento
+	for _, lat := range []float64{-23.64, -23.631, -23.595} {
+		_, err := svc.CreateLocation(ctx, participant.ID, testutil.TestEntityID, &dto.CreateLocationRequest{Latitude: lat, Longitude: -46.63})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, publisher.published, 2)
+	assert.Equal(t, participant.ID.String(), publisher.published[0].ParticipantID)
+	assert.Greater(t, publisher.published[0].ETAMinutes, publisher.published[1].ETAMinutes+3)
+}
+
+// newTestTrackedParticipant devolve um participante com consentimento de localização, já
+// preparado nos mocks junto com o evento
+func newTestTrackedParticipant(ctx context.Context, deps *locationServiceDeps) *domain.Participant {
+	participant := testutil.NewTestParticipant()
+	participant.LocationConsent = true
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	deps.locationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Location")).Return(nil)
+	return participant
+}
+
+func TestLocationService_CreateLocation_ThrottlesByReceiveTime(t *testing.T) {
+	tests := []struct {
+		name          string
+		previousAge   time.Duration
+		wantThrottled bool
+	}{
+		{name: "too soon", previousAge: 2 * time.Second, wantThrottled: true},
+		{name: "after the interval", previousAge: 6 * time.Second},
+		{name: "no previous position"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestLocationService(t)
+			svc.minInterval = 5 * time.Second
+			participant := newTestTrackedParticipant(ctx, deps)
+
+			if tt.previousAge > 0 {
+				previous := newTestParticipantLocation(participant.ID, -23.56, -46.64)
+				previous.EventID = participant.EventID
+				previous.CreatedAt = time.Now().Add(-tt.previousAge)
+				// O relógio do cliente não conta: o timestamp anterior é bem antigo
+				previous.Timestamp = time.Now().Add(-time.Hour)
+				require.NoError(t, deps.buffer.Push(ctx, previous))
+			}
+
+			resp, err := svc.CreateLocation(ctx, participant.ID, testutil.TestEntityID, &dto.CreateLocationRequest{Latitude: -23.55, Longitude: -46.63})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantThrottled, resp.Throttled)
+
+			latest, err := deps.buffer.GetLatestLocation(ctx, participant.EventID, participant.ID)
+			require.NoError(t, err)
+			require.NotNil(t, latest)
+			if tt.wantThrottled {
+				deps.locationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				assert.Equal(t, -23.56, latest.Latitude)
+				return
+			}
+			deps.locationRepo.AssertNumberOfCalls(t, "Create", 1)
+			assert.Equal(t, -23.55, latest.Latitude)
+		})
+	}
+}
+
+func TestLocationService_CreateLocation_RejectsFutureTimestamp(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+	participant := newTestTrackedParticipant(ctx, deps)
+
+	future := time.Now().Add(10 * time.Minute)
+	_, err := svc.CreateLocation(ctx, participant.ID, testutil.TestEntityID, &dto.CreateLocationRequest{Latitude: -23.55, Longitude: -46.63, Timestamp: &future})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	deps.locationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLocationService_CreateLocation_OutOfOrderPointKeepsLatest(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+	svc.minInterval = 5 * time.Second
+	participant := newTestTrackedParticipant(ctx, deps)
+
+	previous := newTestParticipantLocation(participant.ID, -23.56, -46.64)
+	previous.EventID = participant.EventID
+	previous.CreatedAt = time.Now().Add(-time.Minute)
+	previous.Timestamp = time.Now().Add(-time.Minute)
+	require.NoError(t, deps.buffer.Push(ctx, previous))
+
+	// Recebido agora, mas medido antes da posição atual
+	older := time.Now().Add(-10 * time.Minute)
+	resp, err := svc.CreateLocation(ctx, participant.ID, testutil.TestEntityID, &dto.CreateLocationRequest{Latitude: -23.55, Longitude: -46.63, Timestamp: &older})
+	require.NoError(t, err)
+	assert.False(t, resp.Throttled)
+
+	// Guardado como histórico, sem substituir a posição mais recente
+	deps.locationRepo.AssertNumberOfCalls(t, "Create", 1)
+	latest, err := deps.buffer.GetLatestLocation(ctx, participant.EventID, participant.ID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, -23.56, latest.Latitude)
+}
+
+func TestLocationService_CreateLocation_TrackingDisabled(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+
+	off := false
+	event := testutil.NewTestEvent()
+	event.Features.LocationTracking = &off
+	participant := testutil.NewTestParticipant()
+	participant.LocationConsent = true
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(event, nil)
+
+	_, err := svc.CreateLocation(ctx, participant.ID, testutil.TestEntityID, &dto.CreateLocationRequest{Latitude: -23.55, Longitude: -46.63})
+	assert.ErrorIs(t, err, domain.ErrFeatureDisabled)
+
+	// Nada é gravado nem vai para o buffer
+	deps.locationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	latest, err := deps.buffer.GetLatestLocation(ctx, participant.EventID, participant.ID)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+}
+
+// newTestBatchParticipant prepara nos mocks um participante com consentimento e captura os
+// pontos gravados em lote
+func newTestBatchParticipant(ctx context.Context, deps *locationServiceDeps) (*domain.Participant, *[]*domain.Location) {
+	participant := testutil.NewTestParticipant()
+	participant.LocationConsent = true
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	var stored []*domain.Location
+	deps.locationRepo.On("BatchCreate", ctx, mock.AnythingOfType("[]*domain.Location")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).([]*domain.Location) }).
+		Return(nil)
+	return participant, &stored
+}
+
+func TestLocationService_CreateLocationsBatch_OrdersOutOfOrderPoints(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+	svc.minInterval = 5 * time.Second
+	participant, stored := newTestBatchParticipant(ctx, deps)
+
+	base := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	at := func(offset time.Duration) *time.Time {
+		ts := base.Add(offset)
+		return &ts
+	}
+
+	result, err := svc.CreateLocationsBatch(ctx, participant.ID, testutil.TestEntityID, []dto.CreateLocationRequest{
+		{Latitude: -23.553, Longitude: -46.63, Timestamp: at(2 * time.Minute)},
+		{Latitude: -23.551, Longitude: -46.63, Timestamp: at(0)},
+		// 2s depois do anterior (em timestamp): descartado pelo throttle
+		{Latitude: -23.5511, Longitude: -46.63, Timestamp: at(2 * time.Second)},
+		{Latitude: -23.552, Longitude: -46.63, Timestamp: at(time.Minute)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.Accepted)
+	assert.Equal(t, 1, result.Throttled)
+	assert.Zero(t, result.Rejected)
+	require.Len(t, *stored, 3)
+	for i, lat := range []float64{-23.551, -23.552, -23.553} {
+		assert.Equal(t, lat, (*stored)[i].Latitude, "stored in timestamp order")
+		assert.Equal(t, lat, result.Locations[i].Latitude)
+	}
+
+	// O cache fica só com o ponto mais novo, ainda que não seja o último do request
+	latest, err := deps.buffer.GetLatestLocation(ctx, participant.EventID, participant.ID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, -23.553, latest.Latitude)
+	assert.True(t, latest.Timestamp.Equal(*at(2 * time.Minute)))
+}
+
+func TestLocationService_CreateLocationsBatch_KeepsValidPointsOfMixedBatch(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+	svc.maxSpeed = 50 // 180 km/h
+	participant, stored := newTestBatchParticipant(ctx, deps)
+
+	base := time.Now().Add(-10 * time.Minute)
+	at := func(offset time.Duration) *time.Time {
+		ts := base.Add(offset)
+		return &ts
+	}
+	future := time.Now().Add(time.Hour)
+
+	result, err := svc.CreateLocationsBatch(ctx, participant.ID, testutil.TestEntityID, []dto.CreateLocationRequest{
+		{Latitude: -23.55, Longitude: -46.63, Timestamp: at(0)},
+		{Latitude: -23.55, Longitude: -46.63, Timestamp: &future},
+		// ~100 km em um minuto
+		{Latitude: -22.65, Longitude: -46.63, Timestamp: at(time.Minute)},
+		{Latitude: 123, Longitude: -46.63, Timestamp: at(90 * time.Second)},
+		// ~1 km em dois minutos, em relação ao último ponto aceito
+		{Latitude: -23.541, Longitude: -46.63, Timestamp: at(2 * time.Minute)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Accepted)
+	assert.Equal(t, 3, result.Rejected)
+	require.Len(t, result.Errors, 3)
+	assert.Contains(t, result.Errors[0], "location[1]: ")
+	assert.Contains(t, result.Errors[0], "timestamp is in the future")
+	assert.Contains(t, result.Errors[1], "location[3]: ")
+	assert.Contains(t, result.Errors[2], "location[2]: ")
+	assert.Contains(t, result.Errors[2], "too far from the previous position")
+
+	require.Len(t, *stored, 2)
+	assert.Equal(t, -23.55, (*stored)[0].Latitude)
+	assert.Equal(t, -23.541, (*stored)[1].Latitude)
+
+	latest, err := deps.buffer.GetLatestLocation(ctx, participant.EventID, participant.ID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, -23.541, latest.Latitude)
+}
+
+func TestLocationService_CreateLocationsBatch_OlderThanCachedKeepsLatest(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+	participant, stored := newTestBatchParticipant(ctx, deps)
+
+	current := newTestParticipantLocation(participant.ID, -23.56, -46.64)
+	current.EventID = participant.EventID
+	current.Timestamp = time.Now().Add(-time.Minute)
+	require.NoError(t, deps.buffer.Push(ctx, current))
+
+	older := time.Now().Add(-time.Hour)
+	result, err := svc.CreateLocationsBatch(ctx, participant.ID, testutil.TestEntityID, []dto.CreateLocationRequest{
+		{Latitude: -23.55, Longitude: -46.63, Timestamp: &older},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Accepted)
+	require.Len(t, *stored, 1)
+
+	// Histórico gravado, mas a posição atual continua a do cache
+	latest, err := deps.buffer.GetLatestLocation(ctx, participant.EventID, participant.ID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, -23.56, latest.Latitude)
+}
+
+func TestLocationService_CreateLocationsBatch_TooLarge(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestLocationService(t)
+	svc.maxBatchSize = 2
+
+	_, err := svc.CreateLocationsBatch(ctx, testutil.TestParticipantID, testutil.TestEntityID, make([]dto.CreateLocationRequest, 3))
+	assert.ErrorIs(t, err, domain.ErrBatchTooLarge)
+	deps.participantRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// dryRunNotificationService monta as mesmas mensagens do NotificationService real, mas só
+// as registra no log, sem enviar nada. Usado pelo worker em modo dry run
+type dryRunNotificationService struct {
+	previewer NotificationService // Previews não enviam nada e vão direto para o serviço real
+	logger    *zap.Logger
+}
+
+// NewDryRunNotificationService cria um NotificationService que apenas loga os envios
+func NewDryRunNotificationService(previewer NotificationService, logger *zap.Logger) NotificationService {
+	return &dryRunNotificationService{previewer: previewer, logger: logger}
+}
+
+func (s *dryRunNotificationService) SendConfirmationRequest(ctx context.Context, event *domain.Event, participant *domain.Participant) error {
+	name, phone, ok := s.participantContact(participant)
+	if !ok {
+		return nil
+	}
+	s.logSend(event, participant.ID.String(), phone, confirmationRequestMessage(event, name))
+	return nil
+}
+
+func (s *dryRunNotificationService) SendReminder(ctx context.Context, event *domain.Event, participant *domain.Participant) error {
+	name, phone, ok := s.participantContact(participant)
+	if !ok {
+		return nil
+	}
+	s.logSend(event, participant.ID.String(), phone, reminderMessage(event, name))
+	return nil
+}
+
+func (s *dryRunNotificationService) SendReminderDigest(ctx context.Context, participant *domain.Participant, events []*domain.Event) error {
+	name, phone, ok := s.participantContact(participant)
+	if !ok {
+		return nil
+	}
+	s.logSend(events[0], participant.ID.String(), phone, reminderDigestMessage(events, name))
+	return nil
+}
+
+func (s *dryRunNotificationService) SendLocationRequest(ctx context.Context, event *domain.Event, participant *domain.Participant) error {
+	name, phone, ok := s.participantContact(participant)
+	if !ok {
+		return nil
+	}
+	s.logSend(event, participant.ID.String(), phone, locationRequestMessage(event, name))
+	return nil
+}
+
+func (s *dryRunNotificationService) SendETAUpdate(ctx context.Context, event *domain.Event, participant *domain.Participant, etaMinutes int) error {
+	s.logger.Info("[dry run] ETA update not sent",
+		zap.String("event_id", event.ID.String()),
+		zap.String("participant_id", participant.ID.String()),
+		zap.Int("eta_minutes", etaMinutes),
+	)
+	return nil
+}
+
+func (s *dryRunNotificationService) SendOrganizerSummary(ctx context.Context, event *domain.Event, organizer *domain.User, stats domain.EventStats) error {
+	if organizer.Phone == nil {
+		return nil
+	}
+	s.logSend(event, "", *organizer.Phone, organizerSummaryMessage(event, organizer, stats))
+	return nil
+}
+
+func (s *dryRunNotificationService) SendOrganizerWrapUp(ctx context.Context, event *domain.Event, organizer *domain.User, stats domain.EventStats) error {
+	if organizer.Phone == nil {
+		return nil
+	}
+	s.logSend(event, "", *organizer.Phone, organizerWrapUpMessage(event, organizer, stats))
+	return nil
+}
+
+func (s *dryRunNotificationService) SendMessage(ctx context.Context, entityID uuid.UUID, phoneNumber string, message string) error {
+	s.logger.Info("[dry run] WhatsApp message not sent",
+		zap.String("channel", "whatsapp"),
+		zap.String("entity_id", entityID.String()),
+		zap.String("phone", phoneNumber),
+		zap.String("message", message),
+	)
+	return nil
+}
+
+// SendBroadcast usa o preview do serviço real para saber quantos participantes receberiam
+// o broadcast e loga a mensagem uma única vez
+func (s *dryRunNotificationService) SendBroadcast(ctx context.Context, event *domain.Event, groupID *uuid.UUID, message string) (int, error) {
+	preview, err := s.previewer.PreviewBroadcast(ctx, event.EntityID, event.ID, groupID, message)
+	if err != nil {
+		return 0, err
+	}
+
+	fields := []zap.Field{
+		zap.String("channel", "whatsapp"),
+		zap.String("event_id", event.ID.String()),
+		zap.Int("recipients", preview.Recipients),
+		zap.String("message", broadcastMessage(event, event.Locale, message)),
+	}
+	if groupID != nil {
+		fields = append(fields, zap.String("group_id", groupID.String()))
+	}
+	s.logger.Info("[dry run] WhatsApp broadcast not sent", fields...)
+	return preview.Recipients, nil
+}
+
+func (s *dryRunNotificationService) PreviewBroadcast(ctx context.Context, entID, eventID uuid.UUID, groupID *uuid.UUID, message string) (*dto.BroadcastPreviewResponse, error) {
+	return s.previewer.PreviewBroadcast(ctx, entID, eventID, groupID, message)
+}
+
+// participantContact retorna nome e telefone do participante; false (com aviso) se não houver telefone
+func (s *dryRunNotificationService) participantContact(participant *domain.Participant) (string, string, bool) {
+	if participant.Entity == nil || participant.Entity.PhoneNumber == nil {
+		s.logger.Warn("Participant has no phone number",
+			zap.String("participant_id", participant.ID.String()),
+		)
+		return "", "", false
+	}
+	return participant.Entity.Name, *participant.Entity.PhoneNumber, true
+}
+
+func (s *dryRunNotificationService) logSend(event *domain.Event, participantID, phone, message string) {
+	fields := []zap.Field{
+		zap.String("channel", "whatsapp"),
+		zap.String("event_id", event.ID.String()),
+		zap.String("phone", phone),
+		zap.String("message", message),
+	}
+	if participantID != "" {
+		fields = append(fields, zap.String("participant_id", participantID))
+	}
+	s.logger.Info("[dry run] WhatsApp message not sent", fields...)
+}
@@ -2,11 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
 	"event-coming/internal/whatsapp"
+	"event-coming/pkg/timefmt"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -18,28 +24,57 @@ type NotificationService interface {
 	// Enviar lembrete
 	SendReminder(ctx context.Context, event *domain.Event, participant *domain.Participant) error
 
+	// Enviar um único lembrete com todos os eventos do dia do participante
+	SendReminderDigest(ctx context.Context, participant *domain.Participant, events []*domain.Event) error
+
 	// Enviar pedido de localização
 	SendLocationRequest(ctx context.Context, event *domain.Event, participant *domain.Participant) error
 
 	// Enviar atualização de ETA
 	SendETAUpdate(ctx context.Context, event *domain.Event, participant *domain.Participant, etaMinutes int) error
 
-	// Enviar notificação genérica
-	SendMessage(ctx context.Context, phoneNumber string, message string) error
+	// Enviar ao organizador o resumo de presença antes do início
+	SendOrganizerSummary(ctx context.Context, event *domain.Event, organizer *domain.User, stats domain.EventStats) error
+
+	// Enviar ao organizador o resumo final após o fechamento
+	SendOrganizerWrapUp(ctx context.Context, event *domain.Event, organizer *domain.User, stats domain.EventStats) error
+
+	// Enviar notificação genérica pelo remetente da entidade
+	SendMessage(ctx context.Context, entityID uuid.UUID, phoneNumber string, message string) error
+
+	// Enviar um broadcast aos confirmados do evento, ou só aos confirmados do grupo
+	// informado. Retorna quantos participantes receberam a mensagem
+	SendBroadcast(ctx context.Context, event *domain.Event, groupID *uuid.UUID, message string) (int, error)
+
+	// Renderizar um broadcast aos confirmados (do evento ou do grupo) e contar quem o
+	// receberia, sem enviar nada
+	PreviewBroadcast(ctx context.Context, entID, eventID uuid.UUID, groupID *uuid.UUID, message string) (*dto.BroadcastPreviewResponse, error)
 }
 
 type notificationServiceImpl struct {
-	whatsappClient *whatsapp.Client
-	logger         *zap.Logger
+	senders         *WhatsAppSenderService
+	deliveryRepo    repository.NotificationDeliveryRepository
+	eventRepo       repository.EventRepository
+	participantRepo repository.ParticipantRepository
+	groupRepo       repository.ParticipantGroupRepository
+	logger          *zap.Logger
 }
 
 func NewNotificationService(
-	whatsappClient *whatsapp.Client,
+	senders *WhatsAppSenderService,
+	deliveryRepo repository.NotificationDeliveryRepository,
+	eventRepo repository.EventRepository,
+	participantRepo repository.ParticipantRepository,
+	groupRepo repository.ParticipantGroupRepository,
 	logger *zap.Logger,
 ) NotificationService {
 	return &notificationServiceImpl{
-		whatsappClient: whatsappClient,
-		logger:         logger,
+		senders:         senders,
+		deliveryRepo:    deliveryRepo,
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+		groupRepo:       groupRepo,
+		logger:          logger,
 	}
 }
 
@@ -53,21 +88,9 @@ func (s *notificationServiceImpl) SendConfirmationRequest(ctx context.Context, e
 	}
 	name := participant.Entity.Name
 	phone := *participant.Entity.PhoneNumber
-	message := fmt.Sprintf(
-		"🎫 *Confirmação de Presença*\n\n"+
-			"Olá %s!\n\n"+
-			"Você está convidado para o evento:\n"+
-			"📌 *%s*\n"+
-			"📅 %s\n\n"+
-			"Por favor, confirme sua presença respondendo:\n"+
-			"✅ *SIM* - para confirmar\n"+
-			"❌ *NÃO* - para recusar",
-		name,
-		event.Name,
-		event.StartTime.Format("02/01/2006 às 15:04"),
-	)
+	message := confirmationRequestMessage(event, name)
 
-	return s.SendMessage(ctx, phone, message)
+	return s.sendToParticipant(ctx, event, participant, phone, message)
 }
 
 // SendReminder envia lembrete do evento
@@ -80,21 +103,25 @@ func (s *notificationServiceImpl) SendReminder(ctx context.Context, event *domai
 	}
 	name := participant.Entity.Name
 	phone := *participant.Entity.PhoneNumber
-	message := fmt.Sprintf(
-		"⏰ *Lembrete de Evento*\n\n"+
-			"Olá %s!\n\n"+
-			"Seu evento está chegando:\n"+
-			"📌 *%s*\n"+
-			"📅 %s\n"+
-			"📍 %s\n\n"+
-			"Não se esqueça! 🎉",
-		name,
-		event.Name,
-		event.StartTime.Format("02/01/2006 às 15:04"),
-		getLocationAddress(event),
-	)
+	message := reminderMessage(event, name)
 
-	return s.SendMessage(ctx, phone, message)
+	return s.sendToParticipant(ctx, event, participant, phone, message)
+}
+
+// SendReminderDigest envia um lembrete agrupando os eventos do dia; a entrega é registrada
+// no primeiro evento da lista
+func (s *notificationServiceImpl) SendReminderDigest(ctx context.Context, participant *domain.Participant, events []*domain.Event) error {
+	if participant.Entity == nil || participant.Entity.PhoneNumber == nil {
+		s.logger.Warn("Participant has no phone number",
+			zap.String("participant_id", participant.ID.String()),
+		)
+		return nil
+	}
+	name := participant.Entity.Name
+	phone := *participant.Entity.PhoneNumber
+	message := reminderDigestMessage(events, name)
+
+	return s.sendToParticipant(ctx, events[0], participant, phone, message)
 }
 
 // SendLocationRequest solicita a localização do participante
@@ -107,16 +134,9 @@ func (s *notificationServiceImpl) SendLocationRequest(ctx context.Context, event
 	}
 	name := participant.Entity.Name
 	phone := *participant.Entity.PhoneNumber
-	message := fmt.Sprintf(
-		"📍 *Compartilhe sua Localização*\n\n"+
-			"Olá %s!\n\n"+
-			"O evento *%s* está prestes a começar.\n\n"+
-			"Por favor, compartilhe sua localização atual para calcularmos seu tempo de chegada.",
-		name,
-		event.Name,
-	)
+	message := locationRequestMessage(event, name)
 
-	return s.SendMessage(ctx, phone, message)
+	return s.sendToParticipant(ctx, event, participant, phone, message)
 }
 
 // SendETAUpdate envia atualização do tempo estimado de chegada
@@ -141,20 +161,352 @@ func (s *notificationServiceImpl) SendETAUpdate(ctx context.Context, event *doma
 	return nil
 }
 
-// SendMessage envia mensagem genérica via WhatsApp
-func (s *notificationServiceImpl) SendMessage(ctx context.Context, phoneNumber string, message string) error {
-	if s.whatsappClient == nil {
-		s.logger.Warn("WhatsApp client not configured, skipping message",
-			zap.String("phone", phoneNumber),
+// SendOrganizerSummary envia ao organizador quantos confirmaram, recusaram e ainda não responderam
+func (s *notificationServiceImpl) SendOrganizerSummary(ctx context.Context, event *domain.Event, organizer *domain.User, stats domain.EventStats) error {
+	if organizer.Phone == nil {
+		s.logger.Warn("Organizer has no phone number",
+			zap.String("user_id", organizer.ID.String()),
 		)
 		return nil
 	}
+	message := organizerSummaryMessage(event, organizer, stats)
+
+	return s.SendMessage(ctx, event.EntityID, *organizer.Phone, message)
+}
+
+// SendOrganizerWrapUp envia ao organizador o resultado do evento encerrado
+func (s *notificationServiceImpl) SendOrganizerWrapUp(ctx context.Context, event *domain.Event, organizer *domain.User, stats domain.EventStats) error {
+	if organizer.Phone == nil {
+		s.logger.Warn("Organizer has no phone number",
+			zap.String("user_id", organizer.ID.String()),
+		)
+		return nil
+	}
+	message := organizerWrapUpMessage(event, organizer, stats)
+
+	return s.SendMessage(ctx, event.EntityID, *organizer.Phone, message)
+}
+
+// SendMessage envia mensagem genérica via WhatsApp pelo remetente da entidade. Sem cliente
+// configurado retorna domain.ErrChannelUnavailable
+func (s *notificationServiceImpl) SendMessage(ctx context.Context, entityID uuid.UUID, phoneNumber string, message string) error {
+	client, err := s.senders.ClientFor(ctx, entityID)
+	if err != nil {
+		return err
+	}
 
 	s.logger.Info("Sending WhatsApp message",
 		zap.String("phone", phoneNumber),
+		zap.String("entity_id", entityID.String()),
 	)
 
-	return s.whatsappClient.SendTextMessage(ctx, phoneNumber, message)
+	return client.SendTextMessage(ctx, phoneNumber, message)
+}
+
+// errBroadcastGroupGone indica que o grupo alvo do broadcast foi apagado depois do agendamento
+var errBroadcastGroupGone = errors.New("broadcast group no longer exists")
+
+// broadcastLocales são os idiomas em que o preview do broadcast é renderizado
+var broadcastLocales = []string{timefmt.LocalePT, timefmt.LocaleEN, timefmt.LocaleES}
+
+// SendBroadcast envia a mensagem, no idioma do evento, aos confirmados com telefone válido.
+// Uma falha de envio para um participante é registrada e não interrompe os demais, para que
+// um retry não repita a mensagem para quem já recebeu
+func (s *notificationServiceImpl) SendBroadcast(ctx context.Context, event *domain.Event, groupID *uuid.UUID, message string) (int, error) {
+	audience, err := s.broadcastAudience(ctx, event, groupID)
+	if err != nil {
+		return 0, err
+	}
+
+	// O remetente é resolvido uma vez para todo o broadcast
+	client, err := s.senders.ClientFor(ctx, event.EntityID)
+	if err != nil {
+		return 0, err
+	}
+
+	text := broadcastMessage(event, event.Locale, message)
+	sent := 0
+	for _, p := range audience {
+		phone, ok := participantPhone(p)
+		if !ok {
+			continue
+		}
+		if err := s.deliver(ctx, client, event, p, phone, text); err != nil {
+			s.logger.Warn("Failed to send broadcast",
+				zap.String("event_id", event.ID.String()),
+				zap.String("participant_id", p.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// PreviewBroadcast renderiza a mensagem em cada idioma e conta os confirmados que têm um
+// telefone válido para recebê-la. Nada é enviado
+func (s *notificationServiceImpl) PreviewBroadcast(ctx context.Context, entID, eventID uuid.UUID, groupID *uuid.UUID, message string) (*dto.BroadcastPreviewResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.Features.NotificationsEnabled() {
+		return nil, domain.ErrFeatureDisabled
+	}
+
+	audience, err := s.broadcastAudience(ctx, event, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &dto.BroadcastPreviewResponse{
+		Locale:   event.Locale,
+		GroupID:  groupID,
+		Messages: make(map[string]string, len(broadcastLocales)),
+	}
+	for _, locale := range broadcastLocales {
+		preview.Messages[locale] = broadcastMessage(event, locale, message)
+	}
+
+	for _, p := range audience {
+		preview.Confirmed++
+		if _, ok := participantPhone(p); ok {
+			preview.Recipients++
+		} else {
+			preview.Unreachable++
+		}
+	}
+
+	return preview, nil
+}
+
+// broadcastAudience retorna os participantes que recebem o broadcast: os confirmados do
+// evento ou, com groupID, só os confirmados que são membros do grupo
+func (s *notificationServiceImpl) broadcastAudience(ctx context.Context, event *domain.Event, groupID *uuid.UUID) ([]*domain.Participant, error) {
+	var members map[uuid.UUID]struct{}
+	if groupID != nil {
+		if _, err := s.groupRepo.GetByID(ctx, *groupID, event.ID, event.EntityID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: %w", errBroadcastGroupGone, err)
+			}
+			return nil, err
+		}
+		ids, err := s.groupRepo.ListMemberIDs(ctx, *groupID, event.EntityID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list group members: %w", err)
+		}
+		members = make(map[uuid.UUID]struct{}, len(ids))
+		for _, id := range ids {
+			members[id] = struct{}{}
+		}
+	}
+
+	participants, err := s.participantRepo.ListAllByEvent(ctx, event.ID, event.EntityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	// Broadcasts são do evento: as cópias dos convidados da série nas ocorrências ficam de fora
+	return selectBroadcastRecipients(participantsOfInstance(participants, nil), members), nil
+}
+
+// selectBroadcastRecipients filtra os confirmados; com members não nil, só os que estão no grupo
+func selectBroadcastRecipients(participants []*domain.Participant, members map[uuid.UUID]struct{}) []*domain.Participant {
+	var recipients []*domain.Participant
+	for _, p := range participants {
+		if p.Status != domain.ParticipantStatusConfirmed {
+			continue
+		}
+		if members != nil {
+			if _, ok := members[p.ID]; !ok {
+				continue
+			}
+		}
+		recipients = append(recipients, p)
+	}
+	return recipients
+}
+
+// participantPhone retorna o telefone do cadastro do participante, se tiver algum dígito,
+// ou o telefone normalizado gravado no próprio participante (convidados sem cadastro)
+func participantPhone(participant *domain.Participant) (string, bool) {
+	if participant.RefEntity != nil && participant.RefEntity.PhoneNumber != nil {
+		if phone := *participant.RefEntity.PhoneNumber; domain.NormalizePhoneNumber(phone) != "" {
+			return phone, true
+		}
+	}
+	if participant.PhoneNumber != "" {
+		return participant.PhoneNumber, true
+	}
+	return "", false
+}
+
+// sendToParticipant envia a mensagem pelo remetente da entidade do evento e registra a entrega
+// para acompanhar os status do WhatsApp. Sem cliente configurado retorna domain.ErrChannelUnavailable
+func (s *notificationServiceImpl) sendToParticipant(ctx context.Context, event *domain.Event, participant *domain.Participant, phoneNumber, message string) error {
+	client, err := s.senders.ClientFor(ctx, event.EntityID)
+	if err != nil {
+		return err
+	}
+	return s.deliver(ctx, client, event, participant, phoneNumber, message)
+}
+
+// deliver envia a mensagem pelo cliente informado e registra a entrega
+func (s *notificationServiceImpl) deliver(ctx context.Context, client *whatsapp.Client, event *domain.Event, participant *domain.Participant, phoneNumber, message string) error {
+	s.logger.Info("Sending WhatsApp message",
+		zap.String("phone", phoneNumber),
+		zap.String("participant_id", participant.ID.String()),
+	)
+
+	messageID, err := client.SendTextMessageWithID(ctx, phoneNumber, message)
+	if err != nil {
+		return err
+	}
+
+	if s.deliveryRepo == nil || messageID == "" {
+		return nil
+	}
+
+	eventID := event.ID
+	participantID := participant.ID
+	delivery := &domain.NotificationDelivery{
+		ID:                uuid.New(),
+		EntityID:          participant.EntityID,
+		EventID:           &eventID,
+		ParticipantID:     &participantID,
+		Channel:           "whatsapp",
+		Recipient:         phoneNumber,
+		ProviderMessageID: messageID,
+		Status:            domain.DeliveryStatusSent,
+	}
+	if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		// A mensagem já foi enviada, falha no log não deve gerar reenvio
+		s.logger.Warn("Failed to record notification delivery",
+			zap.String("message_id", messageID),
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
+
+// confirmationRequestMessage monta o pedido de confirmação de presença
+func confirmationRequestMessage(event *domain.Event, name string) string {
+	return fmt.Sprintf(
+		"🎫 *Confirmação de Presença*\n\n"+
+			"Olá %s!\n\n"+
+			"Você está convidado para o evento:\n"+
+			"📌 *%s*\n"+
+			"📅 %s\n\n"+
+			"Por favor, confirme sua presença respondendo:\n"+
+			"✅ *SIM* - para confirmar\n"+
+			"❌ *NÃO* - para recusar",
+		name,
+		event.Name,
+		dto.FormatEventTime(event, event.StartTime),
+	)
+}
+
+// reminderMessage monta o lembrete do evento
+func reminderMessage(event *domain.Event, name string) string {
+	return fmt.Sprintf(
+		"⏰ *Lembrete de Evento*\n\n"+
+			"Olá %s!\n\n"+
+			"Seu evento está chegando:\n"+
+			"📌 *%s*\n"+
+			"📅 %s\n"+
+			"📍 %s\n\n"+
+			"Não se esqueça! 🎉",
+		name,
+		event.Name,
+		dto.FormatEventTime(event, event.StartTime),
+		getLocationAddress(event),
+	)
+}
+
+// reminderDigestMessage monta o lembrete único com os eventos do dia
+func reminderDigestMessage(events []*domain.Event, name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "⏰ *Lembrete dos Eventos de Hoje*\n\nOlá %s!\n\nVocê tem %d eventos chegando:\n", name, len(events))
+	for _, event := range events {
+		fmt.Fprintf(&b, "\n📌 *%s*\n📅 %s\n📍 %s\n",
+			event.Name,
+			dto.FormatEventTime(event, event.StartTime),
+			getLocationAddress(event),
+		)
+	}
+	b.WriteString("\nNão se esqueça! 🎉")
+	return b.String()
+}
+
+// locationRequestMessage monta o pedido de localização
+func locationRequestMessage(event *domain.Event, name string) string {
+	return fmt.Sprintf(
+		"📍 *Compartilhe sua Localização*\n\n"+
+			"Olá %s!\n\n"+
+			"O evento *%s* está prestes a começar.\n\n"+
+			"Por favor, compartilhe sua localização atual para calcularmos seu tempo de chegada.",
+		name,
+		event.Name,
+	)
+}
+
+// organizerSummaryMessage monta o resumo de presença do organizador
+func organizerSummaryMessage(event *domain.Event, organizer *domain.User, stats domain.EventStats) string {
+	return fmt.Sprintf(
+		"📋 *Resumo do Evento*\n\n"+
+			"Olá %s!\n\n"+
+			"📌 *%s*\n"+
+			"📅 %s\n\n"+
+			"✅ Confirmados: %d\n"+
+			"⏳ Sem resposta: %d\n"+
+			"❌ Recusaram: %d\n"+
+			"👥 Total: %d",
+		organizer.Name,
+		event.Name,
+		dto.FormatEventTime(event, event.StartTime),
+		stats.Confirmed,
+		stats.Pending,
+		stats.Denied,
+		stats.Total,
+	)
+}
+
+// organizerWrapUpMessage monta o resumo final do organizador
+func organizerWrapUpMessage(event *domain.Event, organizer *domain.User, stats domain.EventStats) string {
+	return fmt.Sprintf(
+		"🏁 *Evento Encerrado*\n\n"+
+			"Olá %s!\n\n"+
+			"📌 *%s*\n\n"+
+			"📍 Check-ins: %d\n"+
+			"🚫 Não compareceram: %d\n"+
+			"✅ Confirmados sem check-in: %d\n"+
+			"⏳ Sem resposta: %d\n"+
+			"❌ Recusaram: %d\n"+
+			"👥 Total: %d",
+		organizer.Name,
+		event.Name,
+		stats.CheckedIn,
+		stats.NoShow,
+		stats.Confirmed,
+		stats.Pending,
+		stats.Denied,
+		stats.Total,
+	)
+}
+
+// broadcastMessage monta o broadcast do organizador com o nome e o horário do evento no idioma informado
+func broadcastMessage(event *domain.Event, locale, message string) string {
+	return fmt.Sprintf(
+		"📢 *%s*\n"+
+			"📅 %s\n\n"+
+			"%s",
+		event.Name,
+		timefmt.Format(event.StartTime, timefmt.LoadLocation(event.Timezone), locale),
+		strings.TrimSpace(message),
+	)
 }
 
 // getLocationAddress retorna o endereço do evento ou coordenadas
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+	"event-coming/pkg/timefmt"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNotificationService_PreviewBroadcast_CountsReachableConfirmed(t *testing.T) {
+	ctx := context.Background()
+	eventRepo := new(mocks.MockEventRepository)
+	participantRepo := new(mocks.MockParticipantRepository)
+	deliveryRepo := new(mocks.MockNotificationDeliveryRepository)
+	svc := NewNotificationService(nil, deliveryRepo, eventRepo, participantRepo, new(mocks.MockParticipantGroupRepository), zap.NewNop())
+
+	event := testutil.NewTestEvent()
+	registered := "+55 11 99999-0000"
+	blank := "---"
+
+	withPhone := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	withPhone.PhoneNumber = "5511999990001"
+	withRegisteredPhone := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	withRegisteredPhone.RefEntity = &domain.Entity{PhoneNumber: &registered}
+	// Sem telefone válido: não recebe o broadcast
+	withoutPhone := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	withoutPhone.RefEntity = &domain.Entity{PhoneNumber: &blank}
+	// Só os confirmados entram na contagem
+	pending := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusPending)
+	pending.PhoneNumber = "5511999990002"
+
+	eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.Participant{withPhone, withRegisteredPhone, withoutPhone, pending}, nil)
+
+	preview, err := svc.PreviewBroadcast(ctx, event.EntityID, event.ID, nil, " Trazer documento ")
+	require.NoError(t, err)
+	assert.Equal(t, 3, preview.Confirmed)
+	assert.Equal(t, 2, preview.Recipients)
+	assert.Equal(t, 1, preview.Unreachable)
+	assert.Len(t, preview.Messages, len(broadcastLocales))
+	assert.Contains(t, preview.Messages[timefmt.LocalePT], "Trazer documento")
+	deliveryRepo.AssertNotCalled(t, "Create")
+}
+
+func TestNotificationService_NilClientIsChannelUnavailable(t *testing.T) {
+	ctx := context.Background()
+	deliveryRepo := new(mocks.MockNotificationDeliveryRepository)
+	senderRepo := new(mocks.MockWhatsAppSenderRepository)
+	senderRepo.On("GetByEntity", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+	// Nem a entidade nem o número global configurados
+	senders := NewWhatsAppSenderService(senderRepo, nil, &config.WhatsAppConfig{}, nil, zap.NewNop())
+	svc := NewNotificationService(senders, deliveryRepo, new(mocks.MockEventRepository), new(mocks.MockParticipantRepository), new(mocks.MockParticipantGroupRepository), zap.NewNop())
+
+	phone := "+5511999990000"
+	participant := testutil.NewTestParticipant()
+	participant.Entity = &domain.Entity{Name: "Maria", PhoneNumber: &phone}
+	event := testutil.NewTestEvent()
+
+	assert.ErrorIs(t, svc.SendMessage(ctx, event.EntityID, phone, "Olá"), domain.ErrChannelUnavailable)
+	assert.ErrorIs(t, svc.SendConfirmationRequest(ctx, event, participant), domain.ErrChannelUnavailable)
+	assert.ErrorIs(t, svc.SendReminder(ctx, event, participant), domain.ErrChannelUnavailable)
+	deliveryRepo.AssertNotCalled(t, "Create")
+}
+
+func TestSelectBroadcastRecipients(t *testing.T) {
+	vip := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	vip.ID = uuid.New()
+	guest := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	guest.ID = uuid.New()
+	pendingVIP := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusPending)
+	pendingVIP.ID = uuid.New()
+	participants := []*domain.Participant{vip, guest, pendingVIP}
+
+	tests := []struct {
+		name    string
+		members map[uuid.UUID]struct{}
+		want    []*domain.Participant
+	}{
+		{name: "whole event", want: []*domain.Participant{vip, guest}},
+		{name: "group", members: map[uuid.UUID]struct{}{vip.ID: {}, pendingVIP.ID: {}}, want: []*domain.Participant{vip}},
+		{name: "empty group", members: map[uuid.UUID]struct{}{}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, selectBroadcastRecipients(participants, tt.members))
+		})
+	}
+}
+
+func TestNotificationService_PreviewBroadcast_GroupScoped(t *testing.T) {
+	ctx := context.Background()
+	eventRepo := new(mocks.MockEventRepository)
+	participantRepo := new(mocks.MockParticipantRepository)
+	groupRepo := new(mocks.MockParticipantGroupRepository)
+	svc := NewNotificationService(nil, new(mocks.MockNotificationDeliveryRepository), eventRepo, participantRepo, groupRepo, zap.NewNop())
+
+	event := testutil.NewTestEvent()
+	group := &domain.ParticipantGroup{ID: uuid.New(), EventID: event.ID, EntityID: event.EntityID, Name: "VIPs"}
+	member := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	member.ID = uuid.New()
+	member.PhoneNumber = "5511999990001"
+	outsider := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	outsider.ID = uuid.New()
+	outsider.PhoneNumber = "5511999990002"
+
+	eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	groupRepo.On("GetByID", ctx, group.ID, event.ID, event.EntityID).Return(group, nil)
+	groupRepo.On("ListMemberIDs", ctx, group.ID, event.EntityID).Return([]uuid.UUID{member.ID}, nil)
+	participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return([]*domain.Participant{member, outsider}, nil)
+
+	preview, err := svc.PreviewBroadcast(ctx, event.EntityID, event.ID, &group.ID, "Credenciamento na sala VIP")
+	require.NoError(t, err)
+	assert.Equal(t, 1, preview.Confirmed)
+	assert.Equal(t, 1, preview.Recipients)
+
+	// Grupo de outro evento
+	other := uuid.New()
+	groupRepo.On("GetByID", ctx, other, event.ID, event.EntityID).Return(nil, domain.ErrNotFound)
+	_, err = svc.PreviewBroadcast(ctx, event.EntityID, event.ID, &other, "Oi")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ParticipantGroupService gerencia os grupos de participantes de um evento ("VIPs",
+// "palestrantes") e seus membros
+type ParticipantGroupService struct {
+	groupRepo       repository.ParticipantGroupRepository
+	participantRepo repository.ParticipantRepository
+	eventRepo       repository.EventRepository
+	logger          *zap.Logger
+}
+
+// NewParticipantGroupService cria um novo serviço de grupos de participantes
+func NewParticipantGroupService(
+	groupRepo repository.ParticipantGroupRepository,
+	participantRepo repository.ParticipantRepository,
+	eventRepo repository.EventRepository,
+	logger *zap.Logger,
+) *ParticipantGroupService {
+	return &ParticipantGroupService{
+		groupRepo:       groupRepo,
+		participantRepo: participantRepo,
+		eventRepo:       eventRepo,
+		logger:          logger,
+	}
+}
+
+// Create cria um grupo no evento
+func (s *ParticipantGroupService) Create(ctx context.Context, entID, eventID uuid.UUID, req *dto.CreateParticipantGroupRequest) (*dto.ParticipantGroupResponse, error) {
+	if _, err := s.eventRepo.GetByID(ctx, eventID, entID); err != nil {
+		return nil, err
+	}
+
+	group := &domain.ParticipantGroup{
+		ID:          uuid.New(),
+		EntityID:    entID,
+		EventID:     eventID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	return dto.ToParticipantGroupResponse(group), nil
+}
+
+// List lista os grupos do evento
+func (s *ParticipantGroupService) List(ctx context.Context, entID, eventID uuid.UUID) ([]*dto.ParticipantGroupResponse, error) {
+	if _, err := s.eventRepo.GetByID(ctx, eventID, entID); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.groupRepo.ListByEvent(ctx, eventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	responses := make([]*dto.ParticipantGroupResponse, len(groups))
+	for i, g := range groups {
+		responses[i] = dto.ToParticipantGroupResponse(g)
+	}
+	return responses, nil
+}
+
+// Update altera o nome e/ou a descrição do grupo
+func (s *ParticipantGroupService) Update(ctx context.Context, entID, eventID, groupID uuid.UUID, req *dto.UpdateParticipantGroupRequest) (*dto.ParticipantGroupResponse, error) {
+	group, err := s.groupRepo.GetByID(ctx, groupID, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = req.Description
+	}
+
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return dto.ToParticipantGroupResponse(group), nil
+}
+
+// Delete remove o grupo e seus vínculos; os participantes continuam no evento
+func (s *ParticipantGroupService) Delete(ctx context.Context, entID, eventID, groupID uuid.UUID) error {
+	if _, err := s.groupRepo.GetByID(ctx, groupID, eventID, entID); err != nil {
+		return err
+	}
+	return s.groupRepo.Delete(ctx, groupID, entID)
+}
+
+// AddMembers adiciona participantes do evento ao grupo. Participantes de outro evento
+// retornam domain.ErrInvalidInput; quem já é membro é ignorado
+func (s *ParticipantGroupService) AddMembers(ctx context.Context, entID, eventID, groupID uuid.UUID, participantIDs []uuid.UUID) error {
+	if _, err := s.groupRepo.GetByID(ctx, groupID, eventID, entID); err != nil {
+		return err
+	}
+
+	participants, err := s.participantRepo.ListAllByEvent(ctx, eventID, entID)
+	if err != nil {
+		return fmt.Errorf("failed to list participants: %w", err)
+	}
+	inEvent := make(map[uuid.UUID]struct{}, len(participants))
+	for _, p := range participants {
+		inEvent[p.ID] = struct{}{}
+	}
+	for _, id := range participantIDs {
+		if _, ok := inEvent[id]; !ok {
+			return fmt.Errorf("participant %s is not in the event: %w", id, domain.ErrInvalidInput)
+		}
+	}
+
+	if err := s.groupRepo.AddMembers(ctx, groupID, entID, participantIDs); err != nil {
+		return fmt.Errorf("failed to add group members: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember tira um participante do grupo
+func (s *ParticipantGroupService) RemoveMember(ctx context.Context, entID, eventID, groupID, participantID uuid.UUID) error {
+	if _, err := s.groupRepo.GetByID(ctx, groupID, eventID, entID); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.RemoveMembers(ctx, groupID, entID, []uuid.UUID{participantID}); err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+	return nil
+}
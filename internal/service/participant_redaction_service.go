@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// redactionBatchSize limita quantos eventos uma rodada da redação automática processa
+const redactionBatchSize = 50
+
+// ParticipantRedactionService apaga os dados pessoais dos participantes de eventos que já
+// terminaram: o vínculo com a pessoa cadastrada (nome, telefone e e-mail), o telefone
+// gravado no participante, metadata, anotações e motivo de recusa, as localizações do
+// evento e o telefone dos destinatários das notificações, além do diff das entradas do
+// audit log sobre esses participantes. Status e horários continuam, para os relatórios.
+// É irreversível
+type ParticipantRedactionService struct {
+	eventRepo       repository.EventRepository
+	participantRepo repository.ParticipantRepository
+	locationRepo    repository.LocationRepository
+	deliveryRepo    repository.NotificationDeliveryRepository
+	auditRepo       repository.AuditLogRepository
+	transactor      repository.Transactor
+	locationBuffer  *cache.LocationBuffer
+	audit           *AuditService
+	retention       time.Duration
+	logger          *zap.Logger
+}
+
+// NewParticipantRedactionService cria um novo serviço de redação de dados de participantes
+func NewParticipantRedactionService(
+	eventRepo repository.EventRepository,
+	participantRepo repository.ParticipantRepository,
+	locationRepo repository.LocationRepository,
+	deliveryRepo repository.NotificationDeliveryRepository,
+	auditRepo repository.AuditLogRepository,
+	transactor repository.Transactor,
+	locationBuffer *cache.LocationBuffer,
+	audit *AuditService,
+	cfg *config.WorkerConfig,
+	logger *zap.Logger,
+) *ParticipantRedactionService {
+	return &ParticipantRedactionService{
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+		locationRepo:    locationRepo,
+		deliveryRepo:    deliveryRepo,
+		auditRepo:       auditRepo,
+		transactor:      transactor,
+		locationBuffer:  locationBuffer,
+		audit:           audit,
+		retention:       cfg.ParticipantDataRetention,
+		logger:          logger,
+	}
+}
+
+// Redact apaga os dados pessoais dos participantes do evento. O evento precisa estar
+// concluído ou cancelado (senão domain.ErrEventNotFinished); repetir só alcança
+// participantes ainda não redigidos
+func (s *ParticipantRedactionService) Redact(ctx context.Context, entID, eventID uuid.UUID) (*dto.ParticipantRedactionResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Status != domain.EventStatusCompleted && event.Status != domain.EventStatusCancelled {
+		return nil, domain.ErrEventNotFinished
+	}
+
+	return s.redact(ctx, event, time.Now())
+}
+
+// RedactExpired redige os eventos concluídos ou cancelados que terminaram há mais que a
+// retenção configurada e retorna quantos foram redigidos. Sem retenção não faz nada
+func (s *ParticipantRedactionService) RedactExpired(ctx context.Context, now time.Time) (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	events, err := s.eventRepo.ListPendingRedaction(ctx, now.Add(-s.retention), redactionBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list events pending redaction: %w", err)
+	}
+
+	redacted := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := s.redact(ctx, event, now); err != nil {
+			s.logger.Error("Failed to redact event participants",
+				zap.String("event_id", event.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		redacted++
+	}
+
+	return redacted, nil
+}
+
+// redact apaga os dados numa transação, limpa as últimas localizações em cache e registra
+// a redação no audit log
+func (s *ParticipantRedactionService) redact(ctx context.Context, event *domain.Event, now time.Time) (*dto.ParticipantRedactionResponse, error) {
+	result := &dto.ParticipantRedactionResponse{
+		EventID:    event.ID,
+		RedactedAt: now,
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if result.Participants, err = s.participantRepo.RedactByEvent(ctx, event.ID, event.EntityID, now); err != nil {
+			return fmt.Errorf("failed to redact participants: %w", err)
+		}
+		if result.Locations, err = s.locationRepo.DeleteByEvent(ctx, event.ID, event.EntityID); err != nil {
+			return fmt.Errorf("failed to delete locations: %w", err)
+		}
+		if result.Deliveries, err = s.deliveryRepo.RedactRecipients(ctx, event.ID, event.EntityID); err != nil {
+			return fmt.Errorf("failed to redact notification recipients: %w", err)
+		}
+		if result.AuditLogs, err = s.auditRepo.RedactEventParticipants(ctx, event.ID, event.EntityID); err != nil {
+			return fmt.Errorf("failed to redact participant audit logs: %w", err)
+		}
+		return s.eventRepo.MarkParticipantsRedacted(ctx, event.ID, event.EntityID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.locationBuffer != nil {
+		if err := s.locationBuffer.ClearEvent(ctx, event.ID); err != nil {
+			s.logger.Warn("Failed to clear cached event locations",
+				zap.String("event_id", event.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	s.audit.Record(ctx, event.EntityID, domain.AuditActionRedact, domain.AuditTargetEvent, event.ID, nil, result)
+
+	s.logger.Info("Redacted event participants",
+		zap.String("event_id", event.ID.String()),
+		zap.Int64("participants", result.Participants),
+		zap.Int64("locations", result.Locations),
+		zap.Int64("deliveries", result.Deliveries),
+		zap.Int64("audit_logs", result.AuditLogs),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/cache"
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type participantRedactionServiceDeps struct {
+	eventRepo       *mocks.MockEventRepository
+	participantRepo *mocks.MockParticipantRepository
+	locationRepo    *mocks.MockLocationRepository
+	deliveryRepo    *mocks.MockNotificationDeliveryRepository
+	auditRepo       *mocks.MockAuditLogRepository
+	transactor      *mocks.MockTransactor
+	locationBuffer  *cache.LocationBuffer
+}
+
+func newTestParticipantRedactionService(t *testing.T, retention time.Duration) (*ParticipantRedactionService, *participantRedactionServiceDeps) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	deps := &participantRedactionServiceDeps{
+		eventRepo:       new(mocks.MockEventRepository),
+		participantRepo: new(mocks.MockParticipantRepository),
+		locationRepo:    new(mocks.MockLocationRepository),
+		deliveryRepo:    new(mocks.MockNotificationDeliveryRepository),
+		auditRepo:       new(mocks.MockAuditLogRepository),
+		transactor:      new(mocks.MockTransactor),
+		locationBuffer:  cache.NewLocationBuffer(client),
+	}
+	deps.transactor.On("WithinTransaction", mock.Anything).Return(nil)
+
+	svc := NewParticipantRedactionService(deps.eventRepo, deps.participantRepo, deps.locationRepo, deps.deliveryRepo,
+		deps.auditRepo, deps.transactor, deps.locationBuffer, NewAuditService(deps.auditRepo, zap.NewNop()),
+		&config.WorkerConfig{ParticipantDataRetention: retention}, zap.NewNop())
+	return svc, deps
+}
+
+// expectRedaction configura a redação do evento, com participants participantes ainda não
+// redigidos, e guarda a entrada do audit log
+func (d *participantRedactionServiceDeps) expectRedaction(event *domain.Event, participants int64, entry **domain.AuditLog) {
+	d.participantRepo.On("RedactByEvent", mock.Anything, event.ID, event.EntityID, mock.AnythingOfType("time.Time")).Return(participants, nil).Once()
+	d.locationRepo.On("DeleteByEvent", mock.Anything, event.ID, event.EntityID).Return(int64(4), nil)
+	d.deliveryRepo.On("RedactRecipients", mock.Anything, event.ID, event.EntityID).Return(int64(2), nil)
+	d.auditRepo.On("RedactEventParticipants", mock.Anything, event.ID, event.EntityID).Return(int64(1), nil)
+	d.eventRepo.On("MarkParticipantsRedacted", mock.Anything, event.ID, event.EntityID, mock.AnythingOfType("time.Time")).Return(nil)
+	d.auditRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.AuditLog")).
+		Run(func(args mock.Arguments) { *entry = args.Get(1).(*domain.AuditLog) }).
+		Return(nil)
+}
+
+func TestParticipantRedactionService_Redact_ClearsPersonalDataAndKeepsStatus(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestParticipantRedactionService(t, 0)
+
+	event := testutil.NewTestEvent()
+	event.Status = domain.EventStatusCompleted
+	location := testutil.NewTestLocation()
+	require.NoError(t, deps.locationBuffer.SetLatestLocation(ctx, location, time.Now().Add(time.Hour)))
+
+	var entry *domain.AuditLog
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.expectRedaction(event, 3, &entry)
+
+	result, err := svc.Redact(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, event.ID, result.EventID)
+	assert.Equal(t, int64(3), result.Participants)
+	assert.Equal(t, int64(4), result.Locations)
+	assert.Equal(t, int64(2), result.Deliveries)
+	assert.Equal(t, int64(1), result.AuditLogs)
+
+	// Os dados pessoais (telefone incluído) são apagados numa única escrita que não toca
+	// status nem horários: nada muda o status dos participantes nem os remove
+	deps.participantRepo.AssertNumberOfCalls(t, "RedactByEvent", 1)
+	deps.participantRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	deps.participantRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	deps.participantRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	deps.transactor.AssertNumberOfCalls(t, "WithinTransaction", 1)
+
+	// Localizações apagadas do banco e do buffer
+	deps.locationRepo.AssertCalled(t, "DeleteByEvent", mock.Anything, event.ID, event.EntityID)
+	cached, err := deps.locationBuffer.GetLatestLocation(ctx, location.EventID, location.ParticipantID)
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+
+	require.NotNil(t, entry)
+	assert.Equal(t, domain.AuditActionRedact, entry.Action)
+	assert.Equal(t, domain.AuditTargetEvent, entry.TargetType)
+	assert.Equal(t, event.ID, entry.TargetID)
+}
+
+func TestParticipantRedactionService_Redact_RejectsUnfinishedEvents(t *testing.T) {
+	for _, status := range []domain.EventStatus{domain.EventStatusDraft, domain.EventStatusScheduled, domain.EventStatusActive} {
+		t.Run(string(status), func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestParticipantRedactionService(t, 0)
+
+			event := testutil.NewTestEvent()
+			event.Status = status
+			deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+
+			_, err := svc.Redact(ctx, event.EntityID, event.ID)
+			assert.ErrorIs(t, err, domain.ErrEventNotFinished)
+			deps.transactor.AssertNotCalled(t, "WithinTransaction", mock.Anything)
+			deps.participantRepo.AssertNotCalled(t, "RedactByEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestParticipantRedactionService_Redact_SecondRunOnlyRedactsNewParticipants(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestParticipantRedactionService(t, 0)
+
+	event := testutil.NewTestEvent()
+	event.Status = domain.EventStatusCancelled
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+
+	var entry *domain.AuditLog
+	deps.expectRedaction(event, 3, &entry)
+	first, err := svc.Redact(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), first.Participants)
+
+	// Evento já redigido não é recusado; RedactByEvent só alcança quem ainda não tem
+	// redacted_at, então só o participante adicionado depois é contado
+	event.ParticipantsRedactedAt = &first.RedactedAt
+	deps.participantRepo.On("RedactByEvent", mock.Anything, event.ID, event.EntityID, mock.AnythingOfType("time.Time")).Return(int64(1), nil).Once()
+	second, err := svc.Redact(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), second.Participants)
+	deps.auditRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestParticipantRedactionService_RedactExpired(t *testing.T) {
+	ctx := context.Background()
+	retention := 30 * 24 * time.Hour
+	svc, deps := newTestParticipantRedactionService(t, retention)
+	now := time.Now()
+
+	ok := testutil.NewTestEvent()
+	ok.Status = domain.EventStatusCompleted
+	failing := testutil.NewTestEvent()
+	failing.ID = uuid.New()
+	failing.Status = domain.EventStatusCompleted
+
+	var entry *domain.AuditLog
+	deps.eventRepo.On("ListPendingRedaction", ctx, now.Add(-retention), redactionBatchSize).Return([]*domain.Event{failing, ok}, nil)
+	deps.participantRepo.On("RedactByEvent", mock.Anything, failing.ID, failing.EntityID, now).Return(int64(0), assert.AnError)
+	deps.expectRedaction(ok, 2, &entry)
+
+	// Uma falha não interrompe os demais eventos
+	redacted, err := svc.RedactExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, redacted)
+	deps.eventRepo.AssertCalled(t, "MarkParticipantsRedacted", mock.Anything, ok.ID, ok.EntityID, now)
+	deps.eventRepo.AssertNotCalled(t, "MarkParticipantsRedacted", mock.Anything, failing.ID, mock.Anything, mock.Anything)
+}
+
+func TestParticipantRedactionService_RedactExpired_DisabledWithoutRetention(t *testing.T) {
+	svc, deps := newTestParticipantRedactionService(t, 0)
+
+	redacted, err := svc.RedactExpired(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, redacted)
+	deps.eventRepo.AssertNotCalled(t, "ListPendingRedaction", mock.Anything, mock.Anything, mock.Anything)
+}
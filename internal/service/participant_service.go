@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
+	"event-coming/internal/config"
 	"event-coming/internal/domain"
 	"event-coming/internal/dto"
 	"event-coming/internal/repository"
@@ -16,53 +20,186 @@ import (
 type ParticipantService struct {
 	participantRepo repository.ParticipantRepository
 	eventRepo       repository.EventRepository
+	transactor      repository.Transactor
+	eventCache      *EventCacheService
+	audit           *AuditService
+	statusHistory   *StatusHistoryService
+	webhooks        *WebhookDispatcher
+	notifications   NotificationService
+	eventUpdates    EventUpdatePublisher
+	rsvp            *config.RSVPConfig
+	limits          *config.ParticipantConfig
+	phones          *PhoneNormalizer
 }
 
 // NewParticipantService cria um novo serviço de participantes
 func NewParticipantService(
 	participantRepo repository.ParticipantRepository,
 	eventRepo repository.EventRepository,
+	transactor repository.Transactor,
+	eventCache *EventCacheService,
+	audit *AuditService,
+	statusHistory *StatusHistoryService,
+	webhooks *WebhookDispatcher,
+	notifications NotificationService,
+	eventUpdates EventUpdatePublisher,
+	rsvp *config.RSVPConfig,
+	limits *config.ParticipantConfig,
+	phones *PhoneNormalizer,
 ) *ParticipantService {
 	return &ParticipantService{
 		participantRepo: participantRepo,
 		eventRepo:       eventRepo,
+		transactor:      transactor,
+		eventCache:      eventCache,
+		audit:           audit,
+		statusHistory:   statusHistory,
+		webhooks:        webhooks,
+		notifications:   notifications,
+		eventUpdates:    eventUpdates,
+		rsvp:            rsvp,
+		limits:          limits,
+		phones:          phones,
 	}
 }
 
+// phoneBackfillBatchSize é quantos participantes BackfillPhoneNumbers carrega por consulta
+const phoneBackfillBatchSize = 500
+
+// BackfillPhoneNumbers preenche o telefone normalizado dos participantes gravados antes
+// da coluna existir, a partir do telefone da entidade referenciada. Executado uma vez
+// pelo comando backfill-phone-numbers do worker. Retorna quantos foram preenchidos
+func (s *ParticipantService) BackfillPhoneNumbers(ctx context.Context) (int, error) {
+	filled := 0
+	afterID := uuid.Nil
+	regions := make(map[uuid.UUID]string)
+
+	for {
+		participants, err := s.participantRepo.ListMissingPhoneNumber(ctx, afterID, phoneBackfillBatchSize)
+		if err != nil {
+			return filled, fmt.Errorf("failed to list participants without phone number: %w", err)
+		}
+
+		for _, p := range participants {
+			afterID = p.ID
+			if p.RefEntity == nil || p.RefEntity.PhoneNumber == nil {
+				continue
+			}
+
+			region, ok := regions[p.EntityID]
+			if !ok {
+				region = s.phones.Region(ctx, p.EntityID)
+				regions[p.EntityID] = region
+			}
+			phone := domain.NormalizePhoneNumberForRegion(*p.RefEntity.PhoneNumber, region)
+			if phone == "" {
+				continue
+			}
+
+			if err := s.participantRepo.SetPhoneNumber(ctx, p.ID, p.EntityID, phone); err != nil {
+				return filled, fmt.Errorf("failed to backfill participant %s phone number: %w", p.ID, err)
+			}
+			filled++
+		}
+
+		if len(participants) < phoneBackfillBatchSize {
+			return filled, nil
+		}
+	}
+}
+
+// syncCache atualiza a confirmação do participante no cache do evento (best effort)
+func (s *ParticipantService) syncCache(ctx context.Context, participant *domain.Participant, event *domain.Event) {
+	if s.eventCache == nil {
+		return
+	}
+
+	_ = s.eventCache.SetConfirmation(ctx, participant.EntityID, participant.EventID, participant, event.EndTime)
+}
+
+// syncStatusChange atualiza o cache do evento e publica os novos totais, carregando o
+// evento uma única vez para os dois (best effort)
+func (s *ParticipantService) syncStatusChange(ctx context.Context, participant *domain.Participant) {
+	if s.eventCache == nil {
+		return
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, participant.EventID, participant.EntityID)
+	if err != nil {
+		return
+	}
+
+	s.syncCache(ctx, participant, event)
+	s.publishEventUpdate(ctx, participant, event)
+}
+
+// publishEventUpdate publica no WebSocket do evento os totais por status, lidos do cache
+// já atualizado pelo syncCache (best effort)
+func (s *ParticipantService) publishEventUpdate(ctx context.Context, participant *domain.Participant, event *domain.Event) {
+	if s.eventCache == nil || s.eventUpdates == nil {
+		return
+	}
+
+	counts, err := s.eventCache.GetStatusCounts(ctx, event)
+	if err != nil {
+		return
+	}
+
+	_ = s.eventUpdates.PublishEventUpdate(ctx, participant.EntityID.String(), participant.EventID.String(), counts)
+}
+
 // Create cria um novo participante vinculado a um evento
 func (s *ParticipantService) Create(ctx context.Context, entID, eventID uuid.UUID, req *dto.CreateParticipantRequest) (*dto.ParticipantResponse, error) {
 	// Verificar se o evento existe
 	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			return nil, fmt.Errorf("event not found")
-		}
 		return nil, fmt.Errorf("failed to get event: %w", err)
 	}
 
+	// Telefone gravado e comparado só pelos dígitos, com o código do país da região da entidade
+	req.PhoneNumber = s.phones.Normalize(ctx, entID, req.PhoneNumber)
+
 	// Verificar se já existe participante com mesmo telefone neste evento
 	existing, err := s.participantRepo.GetByPhoneNumber(ctx, req.PhoneNumber, eventID, entID)
-	if err != nil && err != domain.ErrNotFound {
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
 		return nil, fmt.Errorf("failed to check existing participant: %w", err)
 	}
 	if existing != nil {
-		return nil, fmt.Errorf("participant with this phone number already exists in this event")
+		return nil, fmt.Errorf("participant with this phone number already exists in this event: %w", domain.ErrConflict)
+	}
+
+	return s.insert(ctx, entID, event, req)
+}
+
+// insert cria o participante no evento, sem as verificações do Create (evento e telefone)
+func (s *ParticipantService) insert(ctx context.Context, entID uuid.UUID, event *domain.Event, req *dto.CreateParticipantRequest) (*dto.ParticipantResponse, error) {
+	if req.Series {
+		if req.InstanceID != nil {
+			return nil, fmt.Errorf("series participants can't have an instance_id: %w", domain.ErrInvalidInput)
+		}
+		if event.RRuleString == nil || *event.RRuleString == "" {
+			return nil, domain.ErrEventNotRecurring
+		}
 	}
 
-	// Criar participante
 	participant := &domain.Participant{
-		ID:         uuid.New(),
-		EventID:    event.ID,
-		InstanceID: req.InstanceID,
-		EntityID:   entID,
-		Status:     domain.ParticipantStatusPending,
-		Metadata:   req.Metadata,
+		ID:          uuid.New(),
+		EventID:     event.ID,
+		InstanceID:  req.InstanceID,
+		EntityID:    entID,
+		Status:      domain.ParticipantStatusPending,
+		Metadata:    req.Metadata,
+		PhoneNumber: req.PhoneNumber,
+		Series:      req.Series,
 	}
 
 	if err := s.participantRepo.Create(ctx, participant); err != nil {
 		return nil, fmt.Errorf("failed to create participant: %w", err)
 	}
 
+	s.syncCache(ctx, participant, event)
+	s.audit.Record(ctx, entID, domain.AuditActionCreate, domain.AuditTargetParticipant, participant.ID, nil, participant)
+
 	return dto.ToParticipantResponse(participant), nil
 }
 
@@ -85,11 +222,14 @@ func (s *ParticipantService) Update(ctx context.Context, entID, participantID uu
 
 	// Preparar input de atualização
 	input := &domain.UpdateParticipantInput{
-		Name:        req.Name,
-		PhoneNumber: req.PhoneNumber,
-		Email:       req.Email,
-		Status:      req.Status,
-		Metadata:    req.Metadata,
+		Name:            req.Name,
+		PhoneNumber:     req.PhoneNumber,
+		Email:           req.Email,
+		Status:          req.Status,
+		Metadata:        req.Metadata,
+		Notes:           req.Notes,
+		ClearNotes:      req.IsNull("notes"),
+		ExpectedVersion: req.Version,
 	}
 
 	// Atualizar timestamps de status
@@ -107,7 +247,14 @@ func (s *ParticipantService) Update(ctx context.Context, entID, participantID uu
 		}
 	}
 
-	if err := s.participantRepo.Update(ctx, participantID, entID, input); err != nil {
+	var status domain.ParticipantStatus
+	if req.Status != nil {
+		status = *req.Status
+	}
+	err = s.holdSeat(ctx, entID, participant, status, func(ctx context.Context) error {
+		return s.participantRepo.Update(ctx, participantID, entID, input)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update participant: %w", err)
 	}
 
@@ -117,23 +264,71 @@ func (s *ParticipantService) Update(ctx context.Context, entID, participantID uu
 		return nil, err
 	}
 
+	if req.Status != nil {
+		s.syncStatusChange(ctx, updated)
+	}
+
+	action := domain.AuditActionUpdate
+	if updated.Status != participant.Status {
+		action = domain.AuditActionStatusChange
+	}
+	s.audit.Record(ctx, entID, action, domain.AuditTargetParticipant, participantID, participant, updated)
+	s.statusHistory.RecordParticipant(ctx, entID, participantID, participant.Status, updated.Status)
+	s.webhooks.DispatchParticipantStatus(ctx, participant, updated)
+
 	return dto.ToParticipantResponse(updated), nil
 }
 
 // Delete remove um participante
 func (s *ParticipantService) Delete(ctx context.Context, entID, participantID uuid.UUID) error {
-	return s.participantRepo.Delete(ctx, participantID, entID)
+	participant, err := s.participantRepo.GetByID(ctx, participantID, entID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.participantRepo.Delete(ctx, participantID, entID); err != nil {
+		return err
+	}
+
+	if s.eventCache != nil {
+		_ = s.eventCache.DeleteConfirmation(ctx, entID, participant.EventID, participantID)
+	}
+
+	s.audit.Record(ctx, entID, domain.AuditActionDelete, domain.AuditTargetParticipant, participantID, participant, nil)
+
+	return nil
 }
 
-// ListByEvent lista participantes de um evento
-func (s *ParticipantService) ListByEvent(ctx context.Context, entID, eventID uuid.UUID, page, perPage int) ([]*dto.ParticipantResponse, int64, error) {
+// ListByEvent lista participantes de um evento; com groupID, só os membros do grupo, e com
+// instanceID, só os inscritos naquela ocorrência
+func (s *ParticipantService) ListByEvent(ctx context.Context, entID, eventID uuid.UUID, groupID, instanceID *uuid.UUID, page, perPage int) ([]*dto.ParticipantResponse, int64, error) {
+	if groupID != nil && instanceID != nil {
+		return nil, 0, fmt.Errorf("group_id and instance_id can't be combined: %w", domain.ErrInvalidInput)
+	}
+
 	// Verificar se o evento existe
 	_, err := s.eventRepo.GetByID(ctx, eventID, entID)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	participants, total, err := s.participantRepo.ListByEvent(ctx, eventID, entID, page, perPage)
+	var participants []*domain.Participant
+	var total int64
+	switch {
+	case groupID != nil:
+		participants, total, err = s.participantRepo.ListByGroup(ctx, eventID, *groupID, entID, page, perPage)
+	case instanceID != nil:
+		instance, ierr := s.eventRepo.GetInstanceByID(ctx, *instanceID, entID)
+		if ierr != nil {
+			return nil, 0, ierr
+		}
+		if instance.EventID != eventID {
+			return nil, 0, domain.ErrNotFound
+		}
+		participants, total, err = s.participantRepo.ListByEventInstance(ctx, *instanceID, entID, page, perPage)
+	default:
+		participants, total, err = s.participantRepo.ListByEvent(ctx, eventID, entID, page, perPage)
+	}
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list participants: %w", err)
 	}
@@ -148,7 +343,301 @@ func (s *ParticipantService) ListByEvent(ctx context.Context, entID, eventID uui
 
 // UpdateStatus atualiza apenas o status do participante
 func (s *ParticipantService) UpdateStatus(ctx context.Context, entID, participantID uuid.UUID, status domain.ParticipantStatus) error {
-	return s.participantRepo.UpdateStatus(ctx, participantID, entID, status)
+	before, _ := s.participantRepo.GetByID(ctx, participantID, entID)
+
+	err := s.holdSeat(ctx, entID, before, status, func(ctx context.Context) error {
+		return s.participantRepo.UpdateStatus(ctx, participantID, entID, status)
+	})
+	if err != nil {
+		return err
+	}
+
+	if updated, err := s.participantRepo.GetByID(ctx, participantID, entID); err == nil {
+		s.syncStatusChange(ctx, updated)
+		s.audit.Record(ctx, entID, domain.AuditActionStatusChange, domain.AuditTargetParticipant, participantID, before, updated)
+		if before != nil {
+			s.statusHistory.RecordParticipant(ctx, entID, participantID, before.Status, updated.Status)
+		}
+		s.webhooks.DispatchParticipantStatus(ctx, before, updated)
+	}
+
+	return nil
+}
+
+// holdSeat aplica a mudança de status respeitando a capacidade do evento. Só quem passa a
+// ocupar uma vaga (confirmado ou check-in vindo de outro status) é verificado: a linha do
+// evento é travada (SELECT ... FOR UPDATE) na mesma transação da contagem e da gravação,
+// então confirmações simultâneas disputando a última vaga são serializadas e só uma passa.
+// O status atual do participante é relido depois da trava, já que before pode estar
+// desatualizado por outra mudança concorrente. Evento lotado retorna ErrEventFull; sem
+// capacidade não há trava
+func (s *ParticipantService) holdSeat(ctx context.Context, entID uuid.UUID, before *domain.Participant, status domain.ParticipantStatus, apply func(ctx context.Context) error) error {
+	if before == nil || !status.HoldsSeat() {
+		return apply(ctx)
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, before.EventID, entID)
+	if err != nil {
+		return err
+	}
+	if event.Capacity == nil {
+		return apply(ctx)
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		locked, err := s.eventRepo.GetForUpdate(ctx, before.EventID, entID)
+		if err != nil {
+			return err
+		}
+
+		current, err := s.participantRepo.GetByID(ctx, before.ID, entID)
+		if err != nil {
+			return err
+		}
+		if current.Status.HoldsSeat() {
+			return apply(ctx)
+		}
+
+		counts, err := s.participantRepo.CountByEventStatus(ctx, before.EventID, entID)
+		if err != nil {
+			return err
+		}
+		taken := counts[domain.ParticipantStatusConfirmed] + counts[domain.ParticipantStatusCheckedIn]
+		if !locked.HasSeat(taken) {
+			return domain.ErrEventFull
+		}
+
+		return apply(ctx)
+	})
+}
+
+// GetStatusHistory retorna a linha do tempo de status do participante (pending →
+// confirmed → checked_in...), com a origem de cada mudança
+func (s *ParticipantService) GetStatusHistory(ctx context.Context, entID, participantID uuid.UUID) ([]*dto.ParticipantStatusEventResponse, error) {
+	if _, err := s.participantRepo.GetByID(ctx, participantID, entID); err != nil {
+		return nil, err
+	}
+	return s.statusHistory.ListParticipant(ctx, participantID)
+}
+
+// GrantLocationConsent registra que o participante aceitou compartilhar a localização
+func (s *ParticipantService) GrantLocationConsent(ctx context.Context, entID, participantID uuid.UUID) error {
+	return s.setLocationConsent(ctx, entID, participantID, true)
+}
+
+// RevokeLocationConsent registra que o participante não quer mais compartilhar a localização
+func (s *ParticipantService) RevokeLocationConsent(ctx context.Context, entID, participantID uuid.UUID) error {
+	return s.setLocationConsent(ctx, entID, participantID, false)
+}
+
+func (s *ParticipantService) setLocationConsent(ctx context.Context, entID, participantID uuid.UUID, consent bool) error {
+	before, _ := s.participantRepo.GetByID(ctx, participantID, entID)
+
+	if err := s.participantRepo.SetLocationConsent(ctx, participantID, entID, consent); err != nil {
+		return err
+	}
+
+	if updated, err := s.participantRepo.GetByID(ctx, participantID, entID); err == nil {
+		s.audit.Record(ctx, entID, domain.AuditActionUpdate, domain.AuditTargetParticipant, participantID, before, updated)
+	}
+
+	return nil
+}
+
+// SetDeclineReason registra o motivo da recusa do participante; nil ou "" remove
+func (s *ParticipantService) SetDeclineReason(ctx context.Context, entID, participantID uuid.UUID, reason *string) error {
+	if reason != nil {
+		trimmed := strings.TrimSpace(*reason)
+		if trimmed == "" {
+			reason = nil
+		} else {
+			reason = &trimmed
+		}
+	}
+
+	before, _ := s.participantRepo.GetByID(ctx, participantID, entID)
+
+	if err := s.participantRepo.SetDeclineReason(ctx, participantID, entID, reason); err != nil {
+		return err
+	}
+
+	if updated, err := s.participantRepo.GetByID(ctx, participantID, entID); err == nil {
+		s.audit.Record(ctx, entID, domain.AuditActionUpdate, domain.AuditTargetParticipant, participantID, before, updated)
+	}
+
+	return nil
+}
+
+// ResendConfirmation reenvia na hora o pedido de confirmação a um participante que
+// ainda não respondeu
+func (s *ParticipantService) ResendConfirmation(ctx context.Context, entID, participantID uuid.UUID) error {
+	participant, err := s.participantRepo.GetByID(ctx, participantID, entID)
+	if err != nil {
+		return err
+	}
+	if participant.Status != domain.ParticipantStatusPending {
+		return domain.ErrParticipantNotPending
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, participant.EventID, entID)
+	if err != nil {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+	if !event.Features.NotificationsEnabled() {
+		return domain.ErrFeatureDisabled
+	}
+
+	if err := s.notifications.SendConfirmationRequest(ctx, event, participant); err != nil {
+		return fmt.Errorf("failed to send confirmation request: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateInviteLink gera o link da página pública de RSVP do participante, com o token
+// assinado que permite responder sem login
+func (s *ParticipantService) GenerateInviteLink(ctx context.Context, entID, participantID uuid.UUID) (*dto.InviteLinkResponse, error) {
+	participant, err := s.participantRepo.GetByID(ctx, participantID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, participant.EventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	if !event.Features.PublicRSVPEnabled() {
+		return nil, domain.ErrFeatureDisabled
+	}
+
+	token, expiresAt, err := signRSVPToken(s.rsvp, participant, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign rsvp token: %w", err)
+	}
+
+	link, err := url.Parse(s.rsvp.PageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rsvp page url: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return &dto.InviteLinkResponse{
+		URL:       link.String(),
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ViewInvite retorna os dados da página de RSVP do token e registra a abertura do convite
+func (s *ParticipantService) ViewInvite(ctx context.Context, token string) (*dto.InviteResponse, error) {
+	participant, event, err := s.participantFromInvite(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if participant.InviteViewedAt == nil {
+		if err := s.participantRepo.MarkInviteViewed(ctx, participant.ID, participant.EntityID); err != nil {
+			return nil, fmt.Errorf("failed to mark invite as viewed: %w", err)
+		}
+	}
+
+	return dto.ToInviteResponse(participant, event), nil
+}
+
+// RespondToInvite aplica a resposta (confirmed/denied) dada pela página de RSVP. O motivo
+// só é guardado na recusa; confirmar remove um motivo anterior
+func (s *ParticipantService) RespondToInvite(ctx context.Context, token string, status domain.ParticipantStatus, reason *string) (*dto.InviteResponse, error) {
+	participant, event, err := s.participantFromInvite(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Presença já registrada ou evento encerrado: a resposta não muda mais nada
+	switch {
+	case participant.Status == domain.ParticipantStatusCheckedIn, participant.Status == domain.ParticipantStatusNoShow:
+		return nil, domain.ErrInviteClosed
+	case event.Status == domain.EventStatusCancelled, event.Status == domain.EventStatusCompleted:
+		return nil, domain.ErrInviteClosed
+	}
+
+	ctx = domain.WithStatusSource(ctx, domain.StatusSourceRSVP)
+	if _, err := s.Update(ctx, participant.EntityID, participant.ID, &dto.UpdateParticipantRequest{
+		Status: &status,
+	}); err != nil {
+		return nil, err
+	}
+	participant.Status = status
+
+	if status != domain.ParticipantStatusDenied {
+		reason = nil
+	}
+	if reason != nil || participant.DeclineReason != nil {
+		if err := s.SetDeclineReason(ctx, participant.EntityID, participant.ID, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	return dto.ToInviteResponse(participant, event), nil
+}
+
+// Reconcile aplica manualmente uma resposta que se perdeu no provedor (webhook fora do ar
+// até o WhatsApp desistir dos retries). A mudança entra no histórico com origem reconcile
+// e no log de auditoria com o usuário que a aplicou
+func (s *ParticipantService) Reconcile(ctx context.Context, entID, participantID uuid.UUID, status domain.ParticipantStatus, reason *string) (*dto.ParticipantResponse, error) {
+	participant, err := s.participantRepo.GetByID(ctx, participantID, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = domain.WithStatusSource(ctx, domain.StatusSourceReconcile)
+	updated, err := s.Update(ctx, entID, participantID, &dto.UpdateParticipantRequest{
+		Status: &status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if status != domain.ParticipantStatusDenied {
+		reason = nil
+	}
+	if reason != nil || participant.DeclineReason != nil {
+		if err := s.SetDeclineReason(ctx, entID, participantID, reason); err != nil {
+			return nil, err
+		}
+		updated.DeclineReason = reason
+	}
+
+	return updated, nil
+}
+
+// participantFromInvite valida o token do convite e carrega o participante e o evento.
+// Um participante removido depois do envio do link invalida o token, e links de eventos
+// com o RSVP público desligado deixam de funcionar
+func (s *ParticipantService) participantFromInvite(ctx context.Context, token string) (*domain.Participant, *domain.Event, error) {
+	participantID, entID, err := parseRSVPToken(s.rsvp, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	participant, err := s.participantRepo.GetByID(ctx, participantID, entID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, domain.ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, participant.EventID, entID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	if !event.Features.PublicRSVPEnabled() {
+		return nil, nil, domain.ErrFeatureDisabled
+	}
+
+	return participant, event, nil
 }
 
 // ConfirmParticipant confirma a participação
@@ -159,38 +648,296 @@ func (s *ParticipantService) ConfirmParticipant(ctx context.Context, entID, part
 	})
 }
 
-// CheckInParticipant faz check-in do participante
-func (s *ParticipantService) CheckInParticipant(ctx context.Context, entID, participantID uuid.UUID) (*dto.ParticipantResponse, error) {
+// CheckInParticipant faz check-in do participante. Fora da janela de check-in do evento (ou
+// da ocorrência, para participantes de uma) retorna ErrCheckInClosed, a não ser que
+// overrideWindow seja informado por um admin
+func (s *ParticipantService) CheckInParticipant(ctx context.Context, entID, participantID uuid.UUID, overrideWindow bool) (*dto.ParticipantResponse, error) {
+	if !overrideWindow {
+		if err := s.checkInOpen(ctx, entID, participantID, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
 	status := domain.ParticipantStatusCheckedIn
 	return s.Update(ctx, entID, participantID, &dto.UpdateParticipantRequest{
 		Status: &status,
 	})
 }
 
+// checkInOpen verifica se o check-in do participante está dentro da janela do evento
+func (s *ParticipantService) checkInOpen(ctx context.Context, entID, participantID uuid.UUID, at time.Time) error {
+	participant, err := s.participantRepo.GetByID(ctx, participantID, entID)
+	if err != nil {
+		return err
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, participant.EventID, entID)
+	if err != nil {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+
+	start, end := event.StartTime, event.EndTime
+	if participant.InstanceID != nil {
+		instance, err := s.eventRepo.GetInstanceByID(ctx, *participant.InstanceID, entID)
+		if err != nil {
+			return fmt.Errorf("failed to get event instance: %w", err)
+		}
+		start, end = instance.StartTime, instance.EndTime
+	}
+
+	return event.CheckCheckIn(start, end, at)
+}
+
+// BatchUpdateStatus atualiza o status de vários participantes do evento.
+// Cada id passa pelo mesmo fluxo do Update (cache, auditoria, webhooks);
+// uma falha não interrompe os demais.
+func (s *ParticipantService) BatchUpdateStatus(ctx context.Context, entID, eventID uuid.UUID, ids []uuid.UUID, status domain.ParticipantStatus) []*dto.BatchStatusResult {
+	results := make([]*dto.BatchStatusResult, len(ids))
+
+	for i, id := range ids {
+		result := &dto.BatchStatusResult{ParticipantID: id}
+		results[i] = result
+
+		participant, err := s.participantRepo.GetByID(ctx, id, entID)
+		if err == nil && participant.EventID != eventID {
+			err = domain.ErrNotFound
+		}
+		if err != nil {
+			result.Err = err
+			continue
+		}
+
+		updated, err := s.Update(ctx, entID, id, &dto.UpdateParticipantRequest{Status: &status})
+		if err != nil {
+			result.Err = err
+			continue
+		}
+
+		result.Success = true
+		result.Participant = updated
+	}
+
+	return results
+}
+
 // BatchCreate cria múltiplos participantes de uma vez
-func (s *ParticipantService) BatchCreate(ctx context.Context, entID, eventID uuid.UUID, req *dto.BatchCreateParticipantsRequest) ([]*dto.ParticipantResponse, []error) {
+func (s *ParticipantService) BatchCreate(ctx context.Context, entID, eventID uuid.UUID, req *dto.BatchCreateParticipantsRequest) ([]*dto.ParticipantResponse, []error, error) {
+	if limit := s.limits.MaxBatchSize; limit > 0 && len(req.Participants) > limit {
+		return nil, nil, fmt.Errorf("%d participants, limit is %d: %w", len(req.Participants), limit, domain.ErrBatchTooLarge)
+	}
+
 	// Verificar se o evento existe
-	_, err := s.eventRepo.GetByID(ctx, eventID, entID)
+	event, err := s.eventRepo.GetByID(ctx, eventID, entID)
 	if err != nil {
-		return nil, []error{fmt.Errorf("event not found: %w", err)}
+		return nil, nil, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	chunkSize := s.limits.BatchChunkSize
+	if chunkSize <= 0 {
+		chunkSize = len(req.Participants)
 	}
 
 	var responses []*dto.ParticipantResponse
-	var errors []error
+	var errs []error
+
+	region := s.phones.Region(ctx, entID)
+
+	// Telefones já usados no evento (no banco ou antes no próprio lote)
+	taken := make(map[string]bool)
 
-	for i, pReq := range req.Participants {
-		resp, err := s.Create(ctx, entID, eventID, &pReq)
+	for start := 0; start < len(req.Participants); start += chunkSize {
+		chunk := req.Participants[start:min(start+chunkSize, len(req.Participants))]
+
+		// Uma consulta por chunk para os duplicados, em vez de uma por participante.
+		// Telefones normalizados como no Create
+		phones := make([]string, len(chunk))
+		for i := range chunk {
+			chunk[i].PhoneNumber = domain.NormalizePhoneNumberForRegion(chunk[i].PhoneNumber, region)
+			phones[i] = chunk[i].PhoneNumber
+		}
+		existing, err := s.participantRepo.ExistingPhoneNumbers(ctx, eventID, entID, phones)
 		if err != nil {
-			errors = append(errors, fmt.Errorf("participant[%d]: %w", i, err))
+			return responses, errs, fmt.Errorf("failed to check existing participants: %w", err)
+		}
+		for _, phone := range existing {
+			taken[phone] = true
+		}
+
+		for i := range chunk {
+			index := start + i
+			pReq := &chunk[i]
+			if taken[pReq.PhoneNumber] {
+				errs = append(errs, fmt.Errorf("participant[%d]: participant with this phone number already exists in this event: %w", index, domain.ErrConflict))
+				continue
+			}
+
+			resp, err := s.insert(ctx, entID, event, pReq)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("participant[%d]: %w", index, err))
+				continue
+			}
+			taken[pReq.PhoneNumber] = true
+			responses = append(responses, resp)
+		}
+	}
+
+	return responses, errs, nil
+}
+
+// CopyFromEvent copia os participantes de outro evento da entidade para o evento de destino,
+// como novos convites pendentes (mesmo contato e metadata). Quem já está no destino, ou
+// aparece repetido na origem, é pulado; a comparação é pelo telefone (só dígitos) e, na
+// falta dele, pela entidade referenciada (sem nenhum dos dois, não há como deduplicar)
+func (s *ParticipantService) CopyFromEvent(ctx context.Context, entID, sourceEventID, targetEventID uuid.UUID) (*dto.CopyParticipantsResponse, error) {
+	if sourceEventID == targetEventID {
+		return nil, fmt.Errorf("source and target are the same event: %w", domain.ErrInvalidInput)
+	}
+
+	// Os dois eventos precisam ser da entidade (senão ErrNotFound)
+	target, err := s.eventRepo.GetByID(ctx, targetEventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target event: %w", err)
+	}
+	if _, err := s.eventRepo.GetByID(ctx, sourceEventID, entID); err != nil {
+		return nil, fmt.Errorf("failed to get source event: %w", err)
+	}
+
+	existing, err := s.participantRepo.ListAllByEvent(ctx, targetEventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list target participants: %w", err)
+	}
+	source, err := s.participantRepo.ListAllByEvent(ctx, sourceEventID, entID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source participants: %w", err)
+	}
+
+	taken := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		if key := participantContactKey(p); key != "" {
+			taken[key] = true
+		}
+	}
+
+	result := &dto.CopyParticipantsResponse{Participants: []*dto.ParticipantResponse{}}
+	for _, p := range source {
+		key := participantContactKey(p)
+		if key != "" && taken[key] {
+			result.Skipped++
 			continue
 		}
-		responses = append(responses, resp)
+
+		copied := &domain.Participant{
+			ID:          uuid.New(),
+			EventID:     target.ID,
+			EntityID:    entID,
+			RefEntityID: p.RefEntityID,
+			Status:      domain.ParticipantStatusPending,
+			Metadata:    p.Metadata,
+			PhoneNumber: p.PhoneNumber,
+		}
+		if err := s.participantRepo.Create(ctx, copied); err != nil {
+			return result, fmt.Errorf("failed to copy participant %s: %w", p.ID, err)
+		}
+		s.syncCache(ctx, copied, target)
+		s.audit.Record(ctx, entID, domain.AuditActionCreate, domain.AuditTargetParticipant, copied.ID, nil, copied)
+
+		if key != "" {
+			taken[key] = true
+		}
+		result.Copied++
+		result.Participants = append(result.Participants, dto.ToParticipantResponse(copied))
+	}
+
+	return result, nil
+}
+
+// participantContactKey identifica a pessoa por trás do participante: o telefone
+// normalizado do participante ou, sem ele, o telefone (só dígitos) ou o e-mail da entidade
+// referenciada e, por último, o id dela. Vazio se não houver nenhum
+func participantContactKey(p *domain.Participant) string {
+	if p.PhoneNumber != "" {
+		return "phone:" + p.PhoneNumber
+	}
+	if p.RefEntity != nil && p.RefEntity.PhoneNumber != nil {
+		if phone := domain.NormalizePhoneNumber(*p.RefEntity.PhoneNumber); phone != "" {
+			return "phone:" + phone
+		}
+	}
+	if p.RefEntity != nil && p.RefEntity.Email != nil {
+		if email := strings.ToLower(strings.TrimSpace(*p.RefEntity.Email)); email != "" {
+			return "email:" + email
+		}
+	}
+	if p.RefEntityID != nil {
+		return "entity:" + p.RefEntityID.String()
+	}
+	return ""
+}
+
+// ListEventsByPhone lista os eventos ativos em que o telefone participa, com o status
+// do participante em cada um. Sem participações retorna uma lista vazia.
+func (s *ParticipantService) ListEventsByPhone(ctx context.Context, phone string) ([]*dto.ParticipantEventResponse, error) {
+	phone = s.phones.NormalizeDefault(phone)
+	if phone == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	participations, err := s.eventRepo.ListActiveByParticipantPhone(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*dto.ParticipantEventResponse, 0, len(participations))
+	for _, e := range participations {
+		events = append(events, &dto.ParticipantEventResponse{
+			EventID:         e.ID,
+			EventName:       e.Name,
+			StartTime:       e.StartTime,
+			StartTimeLocal:  dto.FormatEventTime(&e.Event, e.StartTime),
+			LocationAddress: e.LocationAddress,
+			ParticipantID:   e.ParticipantID,
+			Status:          e.ParticipantStatus,
+		})
+	}
+
+	return events, nil
+}
+
+// ApplyResponseOption aplica ao participante a opção escolhida (lista/botão do WhatsApp)
+// conforme o mapa de opções do evento. Retorna domain.ErrNotFound se a opção não existe.
+func (s *ParticipantService) ApplyResponseOption(ctx context.Context, participant *domain.Participant, optionID string) (*domain.ResponseOption, error) {
+	event, err := s.eventRepo.GetByID(ctx, participant.EventID, participant.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	option, ok := event.ResponseOptions[optionID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	// Mescla os metadados da opção com os já existentes
+	metadata := make(map[string]interface{}, len(participant.Metadata)+len(option.Metadata)+1)
+	for k, v := range participant.Metadata {
+		metadata[k] = v
+	}
+	for k, v := range option.Metadata {
+		metadata[k] = v
+	}
+	metadata["response_option"] = optionID
+
+	_, err = s.Update(ctx, participant.EntityID, participant.ID, &dto.UpdateParticipantRequest{
+		Status:   option.Status,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return responses, errors
+	return &option, nil
 }
 
 // GetByPhoneNumber busca um participante pelo número de telefone em eventos ativos
 func (s *ParticipantService) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.Participant, error) {
-	return s.participantRepo.GetActiveByPhoneNumber(ctx, phoneNumber)
+	return s.participantRepo.GetActiveByPhoneNumber(ctx, s.phones.NormalizeDefault(phoneNumber))
 }
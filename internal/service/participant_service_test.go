@@ -0,0 +1,894 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+	"event-coming/internal/websocket"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParticipantService(t *testing.T) (*ParticipantService, *EventCacheService, *eventCacheServiceDeps) {
+	t.Helper()
+
+	cache, deps := newTestEventCacheService(t)
+	rsvp := &config.RSVPConfig{Secret: "test-rsvp-secret", TokenTTL: time.Hour, PageURL: "https://rsvp.example.com/invite"}
+	svc := NewParticipantService(deps.participantRepo, deps.eventRepo, nil, cache, nil, nil, nil, nil, nil, rsvp, &config.ParticipantConfig{}, nil)
+	return svc, cache, deps
+}
+
+// withStatus retorna uma cópia do participante com outro status
+func withStatus(participant *domain.Participant, status domain.ParticipantStatus) *domain.Participant {
+	updated := *participant
+	updated.Status = status
+	return &updated
+}
+
+func TestParticipantService_ConfirmParticipant_UpdatesCachedTotals(t *testing.T) {
+	ctx := context.Background()
+	svc, cache, deps := newTestParticipantService(t)
+
+	event := testutil.NewTestEvent()
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusPending
+	require.NoError(t, cache.SetConfirmation(ctx, event.EntityID, event.ID, participant, event.EndTime))
+	markWarmed(t, deps, event.EntityID, event.ID)
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).Return(participant, nil).Once()
+	deps.participantRepo.On("Update", ctx, participant.ID, event.EntityID, mock.Anything).Return(nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).
+		Return(withStatus(participant, domain.ParticipantStatusConfirmed), nil)
+
+	before, err := cache.GetEventCacheData(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, before.TotalConfirmed)
+	assert.Equal(t, 1, before.TotalPending)
+
+	_, err = svc.ConfirmParticipant(ctx, event.EntityID, participant.ID)
+	require.NoError(t, err)
+
+	after, err := cache.GetEventCacheData(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, after.TotalConfirmed)
+	assert.Equal(t, 0, after.TotalPending)
+}
+
+// recordingEventUpdates guarda os totais publicados no WebSocket do evento
+type recordingEventUpdates struct {
+	published []*websocket.EventUpdateData
+}
+
+func (p *recordingEventUpdates) PublishEventUpdate(ctx context.Context, entityID, eventID string, data *websocket.EventUpdateData) error {
+	p.published = append(p.published, data)
+	return nil
+}
+
+func TestParticipantService_ConfirmParticipant_PublishesEventTotals(t *testing.T) {
+	ctx := context.Background()
+	svc, cache, deps := newTestParticipantService(t)
+	publisher := &recordingEventUpdates{}
+	svc.eventUpdates = publisher
+
+	event := testutil.NewTestEvent()
+	participant := testutil.NewTestParticipant()
+	checkedIn := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusCheckedIn)
+	checkedIn.ID = uuid.New()
+	denied := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusDenied)
+	denied.ID = uuid.New()
+	for _, p := range []*domain.Participant{participant, checkedIn, denied} {
+		require.NoError(t, cache.SetConfirmation(ctx, event.EntityID, event.ID, p, event.EndTime))
+	}
+	markWarmed(t, deps, event.EntityID, event.ID)
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).Return(participant, nil).Once()
+	deps.participantRepo.On("Update", ctx, participant.ID, event.EntityID, mock.Anything).Return(nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).
+		Return(withStatus(participant, domain.ParticipantStatusConfirmed), nil)
+
+	_, err := svc.ConfirmParticipant(ctx, event.EntityID, participant.ID)
+	require.NoError(t, err)
+
+	// Totais lidos do cache, sem consultar o banco
+	require.Len(t, publisher.published, 1)
+	assert.Equal(t, &websocket.EventUpdateData{Total: 3, Confirmed: 2, CheckedIn: 1, Denied: 1}, publisher.published[0])
+	deps.participantRepo.AssertNotCalled(t, "ListAllByEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParticipantService_ConfirmParticipant_PublishesRemainingSeats(t *testing.T) {
+	ctx := context.Background()
+	svc, cache, deps := newTestParticipantService(t)
+	publisher := &recordingEventUpdates{}
+	svc.eventUpdates = publisher
+	transactor := new(mocks.MockTransactor)
+	transactor.On("WithinTransaction", ctx).Return(nil)
+	svc.transactor = transactor
+
+	event := testutil.NewTestEvent()
+	capacity := 3
+	event.Capacity = &capacity
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusPending
+	require.NoError(t, cache.SetConfirmation(ctx, event.EntityID, event.ID, participant, event.EndTime))
+	markWarmed(t, deps, event.EntityID, event.ID)
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("GetForUpdate", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("CountByEventStatus", ctx, event.ID, event.EntityID).
+		Return(map[domain.ParticipantStatus]int{domain.ParticipantStatusPending: 1, domain.ParticipantStatusConfirmed: 1}, nil).Once()
+	// Depois da confirmação o banco tem uma vaga ocupada que o cache ainda não conhece
+	deps.participantRepo.On("CountByEventStatus", ctx, event.ID, event.EntityID).
+		Return(map[domain.ParticipantStatus]int{domain.ParticipantStatusConfirmed: 2}, nil)
+	// Lido pelo serviço e de novo dentro da transação, depois da trava
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).Return(participant, nil).Twice()
+	deps.participantRepo.On("Update", ctx, participant.ID, event.EntityID, mock.Anything).Return(nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).
+		Return(withStatus(participant, domain.ParticipantStatusConfirmed), nil)
+
+	_, err := svc.ConfirmParticipant(ctx, event.EntityID, participant.ID)
+	require.NoError(t, err)
+
+	require.Len(t, publisher.published, 1)
+	published := publisher.published[0]
+	require.NotNil(t, published.Capacity)
+	require.NotNil(t, published.Remaining)
+	assert.Equal(t, 3, *published.Capacity)
+	// Vagas restantes pela contagem do banco, a mesma da verificação de lotação
+	assert.Equal(t, 1, *published.Remaining)
+	assert.Equal(t, 1, published.Confirmed)
+	// O evento é carregado pela verificação de lotação e uma vez para cache e totais
+	deps.eventRepo.AssertNumberOfCalls(t, "GetByID", 2)
+}
+
+func TestParticipantService_UpdateStatus_UpdatesCachedTotals(t *testing.T) {
+	ctx := context.Background()
+	svc, cache, deps := newTestParticipantService(t)
+
+	event := testutil.NewTestEvent()
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusConfirmed
+	require.NoError(t, cache.SetConfirmation(ctx, event.EntityID, event.ID, participant, event.EndTime))
+	markWarmed(t, deps, event.EntityID, event.ID)
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("UpdateStatus", ctx, participant.ID, event.EntityID, domain.ParticipantStatusDenied).Return(nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).
+		Return(withStatus(participant, domain.ParticipantStatusDenied), nil)
+
+	require.NoError(t, svc.UpdateStatus(ctx, event.EntityID, participant.ID, domain.ParticipantStatusDenied))
+
+	data, err := cache.GetEventCacheData(ctx, event.EntityID, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, data.TotalConfirmed)
+	assert.Equal(t, 1, data.TotalDenied)
+}
+
+func TestParticipantService_Delete_RemovesCachedConfirmation(t *testing.T) {
+	ctx := context.Background()
+	svc, cache, deps := newTestParticipantService(t)
+
+	event := testutil.NewTestEvent()
+	participant := testutil.NewTestParticipant()
+	require.NoError(t, cache.SetConfirmation(ctx, event.EntityID, event.ID, participant, event.EndTime))
+
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).Return(participant, nil)
+	deps.participantRepo.On("Delete", ctx, participant.ID, event.EntityID).Return(nil)
+
+	require.NoError(t, svc.Delete(ctx, event.EntityID, participant.ID))
+
+	assert.False(t, deps.redis.Exists(confirmationKey(event.EntityID, event.ID, participant.ID)))
+}
+
+func TestParticipantService_BatchUpdateStatus_MixedResults(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	event := testutil.NewTestEvent()
+	found := testutil.NewTestParticipant()
+	found.Status = domain.ParticipantStatusConfirmed
+	otherEvent := testutil.NewTestParticipant()
+	otherEvent.ID = uuid.New()
+	otherEvent.EventID = uuid.New()
+	missingID := uuid.New()
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("GetByID", ctx, found.ID, event.EntityID).Return(found, nil).Twice()
+	deps.participantRepo.On("Update", ctx, found.ID, event.EntityID, mock.AnythingOfType("*domain.UpdateParticipantInput")).Return(nil)
+	deps.participantRepo.On("GetByID", ctx, found.ID, event.EntityID).
+		Return(withStatus(found, domain.ParticipantStatusCheckedIn), nil)
+	deps.participantRepo.On("GetByID", ctx, missingID, event.EntityID).Return(nil, domain.ErrNotFound)
+	deps.participantRepo.On("GetByID", ctx, otherEvent.ID, event.EntityID).Return(otherEvent, nil)
+
+	results := svc.BatchUpdateStatus(ctx, event.EntityID, event.ID,
+		[]uuid.UUID{missingID, found.ID, otherEvent.ID}, domain.ParticipantStatusCheckedIn)
+
+	require.Len(t, results, 3)
+
+	// Uma falha não interrompe os demais, e a ordem dos ids é mantida
+	assert.Equal(t, missingID, results[0].ParticipantID)
+	assert.False(t, results[0].Success)
+	assert.ErrorIs(t, results[0].Err, domain.ErrNotFound)
+
+	assert.Equal(t, found.ID, results[1].ParticipantID)
+	assert.True(t, results[1].Success)
+	require.NotNil(t, results[1].Participant)
+	assert.Equal(t, domain.ParticipantStatusCheckedIn, results[1].Participant.Status)
+
+	// Participantes de outro evento são tratados como inexistentes
+	assert.False(t, results[2].Success)
+	assert.ErrorIs(t, results[2].Err, domain.ErrNotFound)
+	deps.participantRepo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+// lockingEventRepository emula o SELECT ... FOR UPDATE: GetForUpdate trava a linha do
+// evento até o fim da transação aberta por rowLockTransactor
+type lockingEventRepository struct {
+	*mocks.MockEventRepository
+	event *domain.Event
+	row   *sync.Mutex
+}
+
+func (r *lockingEventRepository) GetForUpdate(ctx context.Context, id, entityID uuid.UUID) (*domain.Event, error) {
+	r.row.Lock()
+	return r.event, nil
+}
+
+type rowLockTransactor struct {
+	row *sync.Mutex
+}
+
+func (t *rowLockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	defer t.row.Unlock()
+	return fn(ctx)
+}
+
+// seatCountingParticipantRepository conta as vagas ocupadas como o banco faria. O
+// contador não tem trava própria: só a trava da linha do evento serializa os acessos
+type seatCountingParticipantRepository struct {
+	*mocks.MockParticipantRepository
+	confirmed int
+}
+
+func (r *seatCountingParticipantRepository) CountByEventStatus(ctx context.Context, eventID, entityID uuid.UUID) (map[domain.ParticipantStatus]int, error) {
+	return map[domain.ParticipantStatus]int{domain.ParticipantStatusConfirmed: r.confirmed}, nil
+}
+
+func (r *seatCountingParticipantRepository) Update(ctx context.Context, id, entityID uuid.UUID, input *domain.UpdateParticipantInput) error {
+	r.confirmed++
+	return nil
+}
+
+func TestParticipantService_ConfirmParticipant_ConcurrentConfirmationsRespectCapacity(t *testing.T) {
+	ctx := context.Background()
+
+	event := testutil.NewTestEvent()
+	capacity := 1
+	event.Capacity = &capacity
+
+	row := &sync.Mutex{}
+	eventRepo := &lockingEventRepository{MockEventRepository: new(mocks.MockEventRepository), event: event, row: row}
+	eventRepo.On("GetByID", mock.Anything, event.ID, event.EntityID).Return(event, nil)
+	participantRepo := &seatCountingParticipantRepository{MockParticipantRepository: new(mocks.MockParticipantRepository)}
+
+	const attempts = 10
+	ids := make([]uuid.UUID, attempts)
+	for i := range ids {
+		participant := testutil.NewTestParticipant()
+		participant.ID = uuid.New()
+		participant.Status = domain.ParticipantStatusPending
+		ids[i] = participant.ID
+		participantRepo.On("GetByID", mock.Anything, participant.ID, event.EntityID).Return(participant, nil)
+	}
+
+	svc := NewParticipantService(participantRepo, eventRepo, &rowLockTransactor{row: row}, nil, nil, nil, nil, nil, nil, nil, &config.ParticipantConfig{}, nil)
+
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = svc.ConfirmParticipant(ctx, event.EntityID, id)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, domain.ErrEventFull)
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, participantRepo.confirmed)
+}
+
+func TestParticipantService_UpdateStatus_SeatHolderSkipsCapacityCheck(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+	transactor := new(mocks.MockTransactor)
+	transactor.On("WithinTransaction", ctx).Return(nil)
+	svc.transactor = transactor
+
+	event := testutil.NewTestEvent()
+	capacity := 1
+	event.Capacity = &capacity
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusConfirmed
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("GetForUpdate", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, participant.EntityID).Return(participant, nil).Twice()
+	deps.participantRepo.On("UpdateStatus", ctx, participant.ID, participant.EntityID, domain.ParticipantStatusCheckedIn).Return(nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, participant.EntityID).
+		Return(withStatus(participant, domain.ParticipantStatusCheckedIn), nil)
+
+	// Confirmado já ocupa a vaga: o check-in não disputa outra, mesmo com o evento lotado
+	require.NoError(t, svc.UpdateStatus(ctx, participant.EntityID, participant.ID, domain.ParticipantStatusCheckedIn))
+	deps.participantRepo.AssertNotCalled(t, "CountByEventStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParticipantService_UpdateStatus_RechecksStatusAfterLock(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+	transactor := new(mocks.MockTransactor)
+	transactor.On("WithinTransaction", ctx).Return(nil)
+	svc.transactor = transactor
+
+	event := testutil.NewTestEvent()
+	capacity := 1
+	event.Capacity = &capacity
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusConfirmed
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("GetForUpdate", ctx, event.ID, event.EntityID).Return(event, nil)
+	// Lido como confirmado, mas recusado por outra requisição antes da trava
+	deps.participantRepo.On("GetByID", ctx, participant.ID, participant.EntityID).Return(participant, nil).Once()
+	deps.participantRepo.On("GetByID", ctx, participant.ID, participant.EntityID).
+		Return(withStatus(participant, domain.ParticipantStatusDenied), nil)
+	deps.participantRepo.On("CountByEventStatus", ctx, event.ID, event.EntityID).
+		Return(map[domain.ParticipantStatus]int{domain.ParticipantStatusConfirmed: 1}, nil)
+
+	// A vaga liberada já foi ocupada por outro: o check-in disputa uma nova e o evento está lotado
+	err := svc.UpdateStatus(ctx, participant.EntityID, participant.ID, domain.ParticipantStatusCheckedIn)
+	assert.ErrorIs(t, err, domain.ErrEventFull)
+	deps.participantRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParticipantService_Create_StoresNormalizedPhoneNumber(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	event := testutil.NewTestEvent()
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("GetByPhoneNumber", ctx, "5511999999999", event.ID, event.EntityID).Return(nil, domain.ErrNotFound)
+	deps.participantRepo.On("Create", ctx, mock.MatchedBy(func(p *domain.Participant) bool {
+		return p.PhoneNumber == "5511999999999"
+	})).Return(nil)
+
+	_, err := svc.Create(ctx, event.EntityID, event.ID, &dto.CreateParticipantRequest{Name: "Maria", PhoneNumber: "+55 (11) 99999-9999"})
+	require.NoError(t, err)
+	deps.participantRepo.AssertExpectations(t)
+}
+
+func TestParticipantService_Create_CompletesLocalNumberWithEntityRegion(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	// A entidade sobrescreve a região padrão da aplicação
+	entityRepo := new(mocks.MockEntityRepository)
+	entity := testutil.NewTestEntity()
+	region := "PT"
+	entity.PhoneRegion = &region
+	entityRepo.On("GetByID", ctx, entity.ID).Return(entity, nil)
+	svc.phones = NewPhoneNormalizer(entityRepo, "BR", nil)
+
+	event := testutil.NewTestEvent()
+	event.EntityID = entity.ID
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("GetByPhoneNumber", ctx, "351912345678", event.ID, event.EntityID).Return(nil, domain.ErrNotFound)
+	deps.participantRepo.On("Create", ctx, mock.MatchedBy(func(p *domain.Participant) bool {
+		return p.PhoneNumber == "351912345678"
+	})).Return(nil)
+
+	_, err := svc.Create(ctx, event.EntityID, event.ID, &dto.CreateParticipantRequest{Name: "Maria", PhoneNumber: "912 345 678"})
+	require.NoError(t, err)
+	deps.participantRepo.AssertExpectations(t)
+}
+
+func TestParticipantService_BackfillPhoneNumbers_UsesReferencedEntityPhone(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	withPhone := testutil.NewTestParticipant()
+	withPhone.ID = uuid.New()
+	withPhone.RefEntity = testutil.NewTestEntity()
+	withoutPhone := testutil.NewTestParticipant()
+	withoutPhone.ID = uuid.New()
+	withoutPhone.RefEntity = testutil.NewTestEntity()
+	withoutPhone.RefEntity.PhoneNumber = nil
+
+	deps.participantRepo.On("ListMissingPhoneNumber", ctx, uuid.Nil, phoneBackfillBatchSize).
+		Return([]*domain.Participant{withPhone, withoutPhone}, nil)
+	deps.participantRepo.On("SetPhoneNumber", ctx, withPhone.ID, withPhone.EntityID, "5511888888888").Return(nil)
+
+	filled, err := svc.BackfillPhoneNumbers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, filled)
+	deps.participantRepo.AssertNumberOfCalls(t, "SetPhoneNumber", 1)
+}
+
+func TestParticipantService_ResendConfirmation_SendsToPendingParticipant(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+	notifier := &recordingNotifier{sent: map[string][]uuid.UUID{}}
+	svc.notifications = notifier
+
+	event := testutil.NewTestEvent()
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusPending
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, event.EntityID).Return(event, nil)
+
+	require.NoError(t, svc.ResendConfirmation(ctx, event.EntityID, participant.ID))
+	assert.Equal(t, []uuid.UUID{participant.ID}, notifier.sent["confirmation"])
+}
+
+func TestParticipantService_ResendConfirmation_OnlyPendingParticipants(t *testing.T) {
+	for _, status := range []domain.ParticipantStatus{
+		domain.ParticipantStatusConfirmed,
+		domain.ParticipantStatusDenied,
+		domain.ParticipantStatusCheckedIn,
+		domain.ParticipantStatusNoShow,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			ctx := context.Background()
+			svc, _, deps := newTestParticipantService(t)
+			notifier := &recordingNotifier{sent: map[string][]uuid.UUID{}}
+			svc.notifications = notifier
+
+			participant := withStatus(testutil.NewTestParticipant(), status)
+			deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+
+			err := svc.ResendConfirmation(ctx, testutil.TestEntityID, participant.ID)
+			assert.ErrorIs(t, err, domain.ErrParticipantNotPending)
+			assert.Empty(t, notifier.sent)
+			deps.eventRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestParticipantService_GenerateInviteLink_EmbedsSignedToken(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	participant := testutil.NewTestParticipant()
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	link, err := svc.GenerateInviteLink(ctx, testutil.TestEntityID, participant.ID)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(link.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "rsvp.example.com", parsed.Host)
+	assert.Equal(t, "/invite", parsed.Path)
+	assert.Equal(t, link.Token, parsed.Query().Get("token"))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), link.ExpiresAt, time.Minute)
+
+	participantID, entityID, err := parseRSVPToken(svc.rsvp, link.Token)
+	require.NoError(t, err)
+	assert.Equal(t, participant.ID, participantID)
+	assert.Equal(t, testutil.TestEntityID, entityID)
+}
+
+func TestParticipantService_ViewInvite_RecordsFirstOpen(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	event := testutil.NewTestEvent()
+	participant := testutil.NewTestParticipant()
+	token, _, err := signRSVPToken(svc.rsvp, participant, time.Now())
+	require.NoError(t, err)
+
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(event, nil)
+	deps.participantRepo.On("MarkInviteViewed", ctx, participant.ID, testutil.TestEntityID).Return(nil).Once()
+
+	resp, err := svc.ViewInvite(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, participant.ID, resp.ParticipantID)
+	assert.Equal(t, event.Name, resp.EventName)
+	deps.participantRepo.AssertExpectations(t)
+}
+
+func TestParticipantService_ViewInvite_LaterOpensAreNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	viewedAt := time.Now().Add(-time.Hour)
+	participant := testutil.NewTestParticipant()
+	participant.InviteViewedAt = &viewedAt
+	token, _, err := signRSVPToken(svc.rsvp, participant, time.Now())
+	require.NoError(t, err)
+
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	_, err = svc.ViewInvite(ctx, token)
+	require.NoError(t, err)
+	deps.participantRepo.AssertNotCalled(t, "MarkInviteViewed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParticipantService_ViewInvite_RejectsBadTokens(t *testing.T) {
+	svc, _, _ := newTestParticipantService(t)
+	participant := testutil.NewTestParticipant()
+
+	expired, _, err := signRSVPToken(svc.rsvp, participant, time.Now().Add(-2*time.Hour))
+	require.NoError(t, err)
+	foreign, _, err := signRSVPToken(&config.RSVPConfig{Secret: "other-secret", TokenTTL: time.Hour}, participant, time.Now())
+	require.NoError(t, err)
+
+	_, err = svc.ViewInvite(context.Background(), expired)
+	assert.ErrorIs(t, err, domain.ErrTokenExpired)
+	_, err = svc.ViewInvite(context.Background(), foreign)
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+}
+
+func TestParticipantService_PublicRSVPDisabled(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	off := false
+	event := testutil.NewTestEvent()
+	event.Features.PublicRSVP = &off
+	participant := testutil.NewTestParticipant()
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(event, nil)
+
+	_, err := svc.GenerateInviteLink(ctx, testutil.TestEntityID, participant.ID)
+	assert.ErrorIs(t, err, domain.ErrFeatureDisabled)
+
+	// Links enviados antes de desligar o recurso deixam de funcionar
+	token, _, err := signRSVPToken(svc.rsvp, participant, time.Now())
+	require.NoError(t, err)
+	_, err = svc.ViewInvite(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrFeatureDisabled)
+	_, err = svc.RespondToInvite(ctx, token, domain.ParticipantStatusConfirmed, nil)
+	assert.ErrorIs(t, err, domain.ErrFeatureDisabled)
+	deps.participantRepo.AssertNotCalled(t, "MarkInviteViewed", mock.Anything, mock.Anything, mock.Anything)
+	deps.participantRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParticipantService_ResendConfirmation_NotificationsDisabled(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+	notifier := &recordingNotifier{sent: map[string][]uuid.UUID{}}
+	svc.notifications = notifier
+
+	off := false
+	event := testutil.NewTestEvent()
+	event.Features.Notifications = &off
+	participant := testutil.NewTestParticipant()
+	participant.Status = domain.ParticipantStatusPending
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, event.EntityID).Return(event, nil)
+
+	err := svc.ResendConfirmation(ctx, event.EntityID, participant.ID)
+	assert.ErrorIs(t, err, domain.ErrFeatureDisabled)
+	assert.Empty(t, notifier.sent)
+}
+
+func TestParticipantService_RespondToInvite_ClosedAfterAttendance(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	participant := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusCheckedIn)
+	token, _, err := signRSVPToken(svc.rsvp, participant, time.Now())
+	require.NoError(t, err)
+
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+
+	_, err = svc.RespondToInvite(ctx, token, domain.ParticipantStatusDenied, nil)
+	assert.ErrorIs(t, err, domain.ErrInviteClosed)
+	deps.participantRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParticipantService_RespondToInvite_KeepsReasonOnlyWhenDenying(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     domain.ParticipantStatus
+		wantReason bool
+	}{
+		{name: "denied", status: domain.ParticipantStatusDenied, wantReason: true},
+		{name: "confirmed", status: domain.ParticipantStatusConfirmed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, _, deps := newTestParticipantService(t)
+
+			participant := testutil.NewTestParticipant()
+			token, _, err := signRSVPToken(svc.rsvp, participant, time.Now())
+			require.NoError(t, err)
+
+			deps.participantRepo.On("GetByID", mock.Anything, participant.ID, testutil.TestEntityID).Return(participant, nil)
+			deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+			deps.participantRepo.On("Update", mock.Anything, participant.ID, testutil.TestEntityID, mock.Anything).Return(nil)
+			deps.participantRepo.On("SetDeclineReason", mock.Anything, participant.ID, testutil.TestEntityID, mock.Anything).Return(nil)
+
+			reason := " Estarei viajando "
+			_, err = svc.RespondToInvite(ctx, token, tt.status, &reason)
+			require.NoError(t, err)
+
+			if !tt.wantReason {
+				deps.participantRepo.AssertNotCalled(t, "SetDeclineReason", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			deps.participantRepo.AssertCalled(t, "SetDeclineReason", mock.Anything, participant.ID, testutil.TestEntityID, mock.MatchedBy(func(got *string) bool {
+				return got != nil && *got == "Estarei viajando"
+			}))
+		})
+	}
+}
+
+// newBatchRequest monta um lote com um participante por telefone
+func newBatchRequest(phones ...string) *dto.BatchCreateParticipantsRequest {
+	req := &dto.BatchCreateParticipantsRequest{}
+	for _, phone := range phones {
+		req.Participants = append(req.Participants, dto.CreateParticipantRequest{PhoneNumber: phone})
+	}
+	return req
+}
+
+func TestParticipantService_BatchCreate_RejectsOversizedBatch(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+	svc.limits = &config.ParticipantConfig{MaxBatchSize: 2}
+
+	_, _, err := svc.BatchCreate(ctx, testutil.TestEntityID, testutil.TestEventID, newBatchRequest("+5511900000001", "+5511900000002", "+5511900000003"))
+	assert.ErrorIs(t, err, domain.ErrBatchTooLarge)
+	deps.eventRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+	deps.participantRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestParticipantService_BatchCreate_LooksUpDuplicatesOncePerChunk(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+	svc.limits = &config.ParticipantConfig{MaxBatchSize: 10, BatchChunkSize: 2}
+
+	event := testutil.NewTestEvent()
+	deps.eventRepo.On("GetByID", ctx, event.ID, testutil.TestEntityID).Return(event, nil)
+	deps.participantRepo.On("ExistingPhoneNumbers", ctx, event.ID, testutil.TestEntityID, []string{"5511900000001", "5511900000002"}).
+		Return([]string{"5511900000002"}, nil).Once()
+	deps.participantRepo.On("ExistingPhoneNumbers", ctx, event.ID, testutil.TestEntityID, []string{"5511900000001", "5511900000003"}).
+		Return([]string{}, nil).Once()
+	deps.participantRepo.On("Create", ctx, mock.AnythingOfType("*domain.Participant")).Return(nil)
+
+	// O terceiro repete o primeiro do lote; o segundo já está no evento
+	created, errs, err := svc.BatchCreate(ctx, testutil.TestEntityID, event.ID,
+		newBatchRequest("+55 11 90000-0001", "5511900000002", "(55) 11900000001", "+5511900000003"))
+	require.NoError(t, err)
+
+	require.Len(t, created, 2)
+	require.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[0], domain.ErrConflict)
+	assert.Contains(t, errs[0].Error(), "participant[1]")
+	assert.ErrorIs(t, errs[1], domain.ErrConflict)
+	assert.Contains(t, errs[1].Error(), "participant[2]")
+	deps.participantRepo.AssertNumberOfCalls(t, "ExistingPhoneNumbers", 2)
+	deps.participantRepo.AssertNumberOfCalls(t, "Create", 2)
+	deps.participantRepo.AssertNotCalled(t, "GetByPhoneNumber", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParticipantService_Update_PersistsNotes(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	notes := "VIP, precisa de vaga"
+	participant := testutil.NewTestParticipant()
+	updated := *participant
+	updated.Notes = &notes
+
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil).Once()
+	deps.participantRepo.On("Update", ctx, participant.ID, testutil.TestEntityID, mock.MatchedBy(func(input *domain.UpdateParticipantInput) bool {
+		return input.Notes != nil && *input.Notes == notes
+	})).Return(nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(&updated, nil).Once()
+
+	resp, err := svc.Update(ctx, testutil.TestEntityID, participant.ID, &dto.UpdateParticipantRequest{Notes: &notes})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Notes)
+	assert.Equal(t, notes, *resp.Notes)
+	deps.participantRepo.AssertExpectations(t)
+}
+
+func TestParticipantService_ViewInvite_OmitsNotes(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	notes := "VIP, precisa de vaga"
+	participant := testutil.NewTestParticipant()
+	participant.Notes = &notes
+	token, _, err := signRSVPToken(svc.rsvp, participant, time.Now())
+	require.NoError(t, err)
+
+	deps.participantRepo.On("GetByID", ctx, participant.ID, testutil.TestEntityID).Return(participant, nil)
+	deps.eventRepo.On("GetByID", ctx, participant.EventID, testutil.TestEntityID).Return(testutil.NewTestEvent(), nil)
+	deps.participantRepo.On("MarkInviteViewed", ctx, participant.ID, testutil.TestEntityID).Return(nil)
+
+	resp, err := svc.ViewInvite(ctx, token)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "notes")
+	assert.NotContains(t, string(body), notes)
+}
+
+// newTestParticipantWithPhone devolve um participante do evento com o telefone normalizado
+func newTestParticipantWithPhone(eventID uuid.UUID, phone string) *domain.Participant {
+	p := testutil.NewTestParticipant()
+	p.ID = uuid.New()
+	p.EventID = eventID
+	p.PhoneNumber = phone
+	return p
+}
+
+func TestParticipantService_CopyFromEvent_SkipsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	source := testutil.NewTestEvent()
+	target := testutil.NewTestEvent()
+	target.ID = uuid.New()
+
+	email := "Ana@Example.com"
+	present := newTestParticipantWithPhone(target.ID, "5511900000001")
+	byEmail := newTestParticipantWithPhone(target.ID, "")
+	byEmail.RefEntity = &domain.Entity{Email: &email}
+
+	alreadyThere := newTestParticipantWithPhone(source.ID, "5511900000001")
+	sameEmail := newTestParticipantWithPhone(source.ID, "")
+	lowerEmail := "ana@example.com"
+	sameEmail.RefEntity = &domain.Entity{Email: &lowerEmail}
+	newGuest := newTestParticipantWithPhone(source.ID, "5511900000002")
+	newGuest.Metadata = map[string]interface{}{"table": 4}
+	repeated := newTestParticipantWithPhone(source.ID, "5511900000002")
+
+	deps.eventRepo.On("GetByID", ctx, target.ID, testutil.TestEntityID).Return(target, nil)
+	deps.eventRepo.On("GetByID", ctx, source.ID, testutil.TestEntityID).Return(source, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, target.ID, testutil.TestEntityID).Return([]*domain.Participant{present, byEmail}, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, source.ID, testutil.TestEntityID).
+		Return([]*domain.Participant{alreadyThere, sameEmail, newGuest, repeated}, nil)
+
+	var created []*domain.Participant
+	deps.participantRepo.On("Create", ctx, mock.AnythingOfType("*domain.Participant")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(1).(*domain.Participant)) }).
+		Return(nil)
+
+	resp, err := svc.CopyFromEvent(ctx, testutil.TestEntityID, source.ID, target.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, resp.Copied)
+	assert.Equal(t, 3, resp.Skipped)
+	require.Len(t, created, 1)
+	assert.Equal(t, target.ID, created[0].EventID)
+	assert.Equal(t, "5511900000002", created[0].PhoneNumber)
+	assert.Equal(t, domain.ParticipantStatusPending, created[0].Status)
+	assert.Equal(t, newGuest.Metadata, created[0].Metadata)
+	assert.NotEqual(t, newGuest.ID, created[0].ID)
+}
+
+func TestParticipantService_CopyFromEvent_RejectsEventsOfOtherEntities(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	target := testutil.NewTestEvent()
+	foreignSource := uuid.New()
+
+	// O evento de origem não é da entidade: o repositório não o encontra
+	deps.eventRepo.On("GetByID", ctx, target.ID, testutil.TestEntityID).Return(target, nil)
+	deps.eventRepo.On("GetByID", ctx, foreignSource, testutil.TestEntityID).Return(nil, domain.ErrNotFound)
+
+	_, err := svc.CopyFromEvent(ctx, testutil.TestEntityID, foreignSource, target.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	deps.participantRepo.AssertNotCalled(t, "ListAllByEvent", mock.Anything, mock.Anything, mock.Anything)
+	deps.participantRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	_, err = svc.CopyFromEvent(ctx, testutil.TestEntityID, target.ID, target.ID)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestParticipantService_CheckInParticipant_Window(t *testing.T) {
+	thirty := 30
+	tests := []struct {
+		name           string
+		start          time.Duration // Início do evento em relação a agora
+		end            time.Duration
+		afterMinutes   *int
+		overrideWindow bool
+		wantErr        error
+	}{
+		{name: "in window", start: 2 * time.Hour, end: 4 * time.Hour},
+		{name: "too early", start: 72 * time.Hour, end: 74 * time.Hour, wantErr: domain.ErrCheckInClosed},
+		{name: "too late", start: -4 * time.Hour, end: -2 * time.Hour, afterMinutes: &thirty, wantErr: domain.ErrCheckInClosed},
+		{name: "late within the default window", start: -4 * time.Hour, end: -2 * time.Hour},
+		{name: "admin override", start: 72 * time.Hour, end: 74 * time.Hour, overrideWindow: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, _, deps := newTestParticipantService(t)
+
+			event := testutil.NewTestEvent()
+			event.StartTime = time.Now().Add(tt.start)
+			end := time.Now().Add(tt.end)
+			event.EndTime = &end
+			event.CheckInWindowAfterMinutes = tt.afterMinutes
+			participant := testutil.NewTestParticipant()
+			participant.Status = domain.ParticipantStatusConfirmed
+
+			// Lido na verificação da janela (sem override) e antes do update
+			reads := 2
+			if tt.overrideWindow {
+				reads = 1
+			}
+			deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+			deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).Return(participant, nil).Times(reads)
+			deps.participantRepo.On("Update", ctx, participant.ID, event.EntityID, mock.Anything).Return(nil)
+			deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).
+				Return(withStatus(participant, domain.ParticipantStatusCheckedIn), nil)
+
+			resp, err := svc.CheckInParticipant(ctx, event.EntityID, participant.ID, tt.overrideWindow)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				deps.participantRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, domain.ParticipantStatusCheckedIn, resp.Status)
+		})
+	}
+}
+
+func TestParticipantService_CheckInParticipant_UsesOccurrenceTimes(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+
+	// A série começou há um mês, mas a ocorrência do participante é daqui a uma hora
+	event := testutil.NewTestEvent()
+	event.StartTime = time.Now().AddDate(0, -1, 0)
+	event.EndTime = nil
+	instance := &domain.EventInstance{ID: uuid.New(), EventID: event.ID, EntityID: event.EntityID, StartTime: time.Now().Add(time.Hour)}
+	participant := testutil.NewTestParticipant()
+	participant.InstanceID = &instance.ID
+
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("GetInstanceByID", ctx, instance.ID, event.EntityID).Return(instance, nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).Return(participant, nil).Times(2)
+	deps.participantRepo.On("Update", ctx, participant.ID, event.EntityID, mock.Anything).Return(nil)
+	deps.participantRepo.On("GetByID", ctx, participant.ID, event.EntityID).
+		Return(withStatus(participant, domain.ParticipantStatusCheckedIn), nil)
+
+	_, err := svc.CheckInParticipant(ctx, event.EntityID, participant.ID, false)
+	require.NoError(t, err)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PhoneNormalizer normaliza telefones como domain.NormalizePhoneNumberForRegion, na
+// região da entidade (phone_region) ou, sem ela, na região padrão da aplicação. Um
+// PhoneNormalizer nil só mantém os dígitos
+type PhoneNormalizer struct {
+	entityRepo    repository.EntityRepository
+	defaultRegion string
+	logger        *zap.Logger
+}
+
+// NewPhoneNormalizer cria o normalizador com a região padrão da aplicação (pode ser vazia)
+func NewPhoneNormalizer(entityRepo repository.EntityRepository, defaultRegion string, logger *zap.Logger) *PhoneNormalizer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &PhoneNormalizer{
+		entityRepo:    entityRepo,
+		defaultRegion: strings.ToUpper(strings.TrimSpace(defaultRegion)),
+		logger:        logger,
+	}
+}
+
+// DefaultRegion retorna a região padrão da aplicação
+func (n *PhoneNormalizer) DefaultRegion() string {
+	if n == nil {
+		return ""
+	}
+	return n.defaultRegion
+}
+
+// Region retorna a região dos telefones da entidade. Se a entidade não puder ser
+// carregada, usa a região padrão em vez de falhar a operação
+func (n *PhoneNormalizer) Region(ctx context.Context, entID uuid.UUID) string {
+	if n == nil {
+		return ""
+	}
+	if n.entityRepo == nil {
+		return n.defaultRegion
+	}
+
+	entity, err := n.entityRepo.GetByID(ctx, entID)
+	if err != nil || entity == nil {
+		n.logger.Warn("Failed to load entity phone region, using default",
+			zap.String("entity_id", entID.String()),
+			zap.Error(err),
+		)
+		return n.defaultRegion
+	}
+	if entity.PhoneRegion != nil && *entity.PhoneRegion != "" {
+		return *entity.PhoneRegion
+	}
+	return n.defaultRegion
+}
+
+// Normalize normaliza um telefone informado para a entidade
+func (n *PhoneNormalizer) Normalize(ctx context.Context, entID uuid.UUID, phone string) string {
+	return domain.NormalizePhoneNumberForRegion(phone, n.Region(ctx, entID))
+}
+
+// NormalizeDefault normaliza um telefone sem entidade conhecida (webhooks, busca por
+// telefone), na região padrão da aplicação
+func (n *PhoneNormalizer) NormalizeDefault(phone string) string {
+	return domain.NormalizePhoneNumberForRegion(phone, n.DefaultRegion())
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ReplyIntent represents the intent detected in a free-text reply
+type ReplyIntent string
+
+const (
+	ReplyUnknown ReplyIntent = ""
+	ReplyConfirm ReplyIntent = "confirm_yes"
+	ReplyDeny    ReplyIntent = "confirm_no"
+	ReplyStatus  ReplyIntent = "status"
+
+	ReplyLocationOptIn  ReplyIntent = "location_opt_in"
+	ReplyLocationOptOut ReplyIntent = "location_opt_out"
+)
+
+// KeywordSet holds the confirmation, denial, status-request and location
+// sharing opt-in/opt-out keywords of a language
+type KeywordSet struct {
+	Confirm        []string
+	Deny           []string
+	Status         []string
+	LocationOptIn  []string
+	LocationOptOut []string
+}
+
+// DefaultKeywordSets are the built-in keywords per language
+var DefaultKeywordSets = map[string]KeywordSet{
+	"pt": {
+		Confirm: []string{"sim", "confirmo", "confirmado", "vou", "estarei", "presente", "claro", "ok"},
+		Deny:    []string{"nao", "nao vou", "nao posso", "nao irei", "recuso", "cancelar"},
+		Status:  []string{"status", "meus eventos", "eventos"},
+		// Os de opt-out incluem "nao" para vencerem a negação simples (frases maiores primeiro)
+		LocationOptIn:  []string{"compartilhar localizacao", "autorizo localizacao"},
+		LocationOptOut: []string{"nao compartilhar localizacao", "parar localizacao"},
+	},
+	"en": {
+		Confirm:        []string{"yes", "yeah", "yep", "sure", "confirm", "confirmed", "attending", "ok"},
+		Deny:           []string{"no", "nope", "not going", "cant", "cannot", "decline"},
+		Status:         []string{"status", "my events", "events"},
+		LocationOptIn:  []string{"share location", "share my location"},
+		LocationOptOut: []string{"stop location", "stop sharing location"},
+	},
+	"es": {
+		Confirm:        []string{"si", "confirmo", "voy", "asistire"},
+		Deny:           []string{"no voy", "no puedo", "no asistire"},
+		Status:         []string{"mis eventos", "estado"},
+		LocationOptIn:  []string{"compartir ubicacion", "autorizo ubicacion"},
+		LocationOptOut: []string{"no compartir ubicacion", "parar ubicacion"},
+	},
+}
+
+// accentReplacer removes common Latin accents so "não" matches "nao"
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+type keyword struct {
+	tokens []string
+	reply  ReplyIntent
+}
+
+// KeywordMatcher detects confirmation/denial intent in free-text replies
+type KeywordMatcher struct {
+	keywords []keyword
+}
+
+// NewDefaultKeywordMatcher creates a matcher from DefaultKeywordSets plus
+// extra configured confirmation/denial keywords
+func NewDefaultKeywordMatcher(confirm, deny []string) *KeywordMatcher {
+	sets := make(map[string]KeywordSet, len(DefaultKeywordSets)+1)
+	for lang, set := range DefaultKeywordSets {
+		sets[lang] = set
+	}
+	sets["custom"] = KeywordSet{Confirm: confirm, Deny: deny}
+	return NewKeywordMatcher(sets)
+}
+
+// NewKeywordMatcher creates a matcher from keyword sets (usually DefaultKeywordSets
+// merged with configured extras)
+func NewKeywordMatcher(sets map[string]KeywordSet) *KeywordMatcher {
+	m := &KeywordMatcher{}
+	for _, set := range sets {
+		for _, k := range set.Confirm {
+			m.add(k, ReplyConfirm)
+		}
+		for _, k := range set.Deny {
+			m.add(k, ReplyDeny)
+		}
+		for _, k := range set.Status {
+			m.add(k, ReplyStatus)
+		}
+		for _, k := range set.LocationOptIn {
+			m.add(k, ReplyLocationOptIn)
+		}
+		for _, k := range set.LocationOptOut {
+			m.add(k, ReplyLocationOptOut)
+		}
+	}
+
+	// Longer phrases first so "nao vou" wins over "vou"
+	sort.SliceStable(m.keywords, func(i, j int) bool {
+		return len(m.keywords[i].tokens) > len(m.keywords[j].tokens)
+	})
+
+	return m
+}
+
+func (m *KeywordMatcher) add(k string, reply ReplyIntent) {
+	tokens := tokenize(k)
+	if len(tokens) == 0 {
+		return
+	}
+	m.keywords = append(m.keywords, keyword{tokens: tokens, reply: reply})
+}
+
+// Match returns the intent of the text. Replies with both confirmation and denial
+// keywords are ambiguous and return ReplyUnknown.
+func (m *KeywordMatcher) Match(text string) ReplyIntent {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return ReplyUnknown
+	}
+
+	// Menu-style numeric answers only count when they are the whole reply
+	if len(tokens) == 1 {
+		switch tokens[0] {
+		case "1":
+			return ReplyConfirm
+		case "2":
+			return ReplyDeny
+		}
+	}
+
+	consumed := make([]bool, len(tokens))
+	found := ReplyUnknown
+	for _, k := range m.keywords {
+		if !consumeSequence(tokens, consumed, k.tokens) {
+			continue
+		}
+		if found != ReplyUnknown && found != k.reply {
+			return ReplyUnknown
+		}
+		found = k.reply
+	}
+
+	return found
+}
+
+// consumeSequence marks the first unconsumed occurrence of seq in tokens
+func consumeSequence(tokens []string, consumed []bool, seq []string) bool {
+	for i := 0; i+len(seq) <= len(tokens); i++ {
+		match := true
+		for j := range seq {
+			if consumed[i+j] || tokens[i+j] != seq[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			for j := range seq {
+				consumed[i+j] = true
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize lowercases, strips accents and punctuation and splits into words
+func tokenize(text string) []string {
+	text = accentReplacer.Replace(strings.ToLower(text))
+	text = strings.ReplaceAll(text, "'", "")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeywordMatcher_Match(t *testing.T) {
+	m := NewKeywordMatcher(DefaultKeywordSets)
+
+	tests := []struct {
+		text string
+		want ReplyIntent
+	}{
+		{text: "Sim!", want: ReplyConfirm},
+		{text: "  sim  ", want: ReplyConfirm},
+		{text: "YES please", want: ReplyConfirm},
+		{text: "Yes, I'm attending", want: ReplyConfirm},
+		{text: "Não", want: ReplyDeny},
+		{text: "NÃO VOU", want: ReplyDeny},
+		{text: "não vou, desculpa", want: ReplyDeny},
+		{text: "Sí, asistiré", want: ReplyConfirm},
+		{text: "No puedo", want: ReplyDeny},
+		{text: "1", want: ReplyConfirm},
+		{text: "2", want: ReplyDeny},
+		{text: "Status", want: ReplyStatus},
+		{text: "meus eventos?", want: ReplyStatus},
+		{text: "My events", want: ReplyStatus},
+		{text: "1 2 3", want: ReplyUnknown},
+		{text: "sim e não", want: ReplyUnknown},
+		{text: "qual o endereço?", want: ReplyUnknown},
+		{text: "", want: ReplyUnknown},
+		{text: "!!!", want: ReplyUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, m.Match(tt.text))
+		})
+	}
+}
+
+func TestKeywordMatcher_CustomKeywords(t *testing.T) {
+	sets := map[string]KeywordSet{
+		"custom": {Confirm: []string{"Bora"}, Deny: []string{"Fica pra próxima"}},
+	}
+	m := NewKeywordMatcher(sets)
+
+	assert.Equal(t, ReplyConfirm, m.Match("bora!!"))
+	assert.Equal(t, ReplyDeny, m.Match("Fica pra proxima"))
+	assert.Equal(t, ReplyUnknown, m.Match("sim"))
+}
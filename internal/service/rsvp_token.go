@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// rsvpTokenPurpose distingue os tokens de RSVP de qualquer outro JWT assinado com o mesmo segredo
+const rsvpTokenPurpose = "rsvp"
+
+// signRSVPToken gera o token assinado que identifica o participante no link de convite
+func signRSVPToken(cfg *config.RSVPConfig, participant *domain.Participant, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(cfg.TokenTTL)
+	claims := jwt.MapClaims{
+		"sub":       participant.ID.String(),
+		"entity_id": participant.EntityID.String(),
+		"purpose":   rsvpTokenPurpose,
+		"iat":       now.Unix(),
+		"exp":       expiresAt.Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.Secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// parseRSVPToken valida o token e retorna o participante e a entidade que ele identifica.
+// Retorna domain.ErrTokenExpired ou domain.ErrInvalidToken
+func parseRSVPToken(cfg *config.RSVPConfig, tokenString string) (participantID, entityID uuid.UUID, err error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, domain.ErrInvalidToken
+		}
+		return []byte(cfg.Secret), nil
+	})
+	if err != nil || !token.Valid {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return uuid.Nil, uuid.Nil, domain.ErrTokenExpired
+		}
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != rsvpTokenPurpose {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidToken
+	}
+
+	sub, _ := claims["sub"].(string)
+	ent, _ := claims["entity_id"].(string)
+	if participantID, err = uuid.Parse(sub); err != nil {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidToken
+	}
+	if entityID, err = uuid.Parse(ent); err != nil {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidToken
+	}
+	return participantID, entityID, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"event-coming/internal/domain"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// participantRetryError indica que participantes da task falharam e ainda têm tentativas:
+// a task volta para pending sem consumir um retry da task
+type participantRetryError struct {
+	pending int
+}
+
+func (e *participantRetryError) Error() string {
+	return fmt.Sprintf("%d participant(s) to retry", e.pending)
+}
+
+// taskSends acompanha os envios de uma task por participante: quem já foi notificado e
+// quantos envios falharam para cada um. É gravado no Metadata da task, para que o retry
+// só reenvie a quem falhou e pule quem esgotou as próprias tentativas
+type taskSends struct {
+	maxAttempts int
+	notified    map[uuid.UUID]bool
+	attempts    map[uuid.UUID]int
+	retrying    int  // Falharam nesta execução e ainda têm tentativas
+	changed     bool // Algo a gravar no Metadata
+}
+
+// newTaskSends lê o progresso gravado no Metadata da task
+func newTaskSends(task *domain.Scheduler, maxAttempts int) *taskSends {
+	sends := &taskSends{
+		maxAttempts: max(maxAttempts, 1),
+		notified:    map[uuid.UUID]bool{},
+		attempts:    map[uuid.UUID]int{},
+	}
+
+	// O Metadata vem do jsonb como tipos genéricos; o round trip pelo JSON os converte
+	var notified []uuid.UUID
+	if decodeMetadata(task.Metadata[domain.SchedulerMetadataNotified], &notified) {
+		for _, id := range notified {
+			sends.notified[id] = true
+		}
+	}
+	var attempts map[uuid.UUID]int
+	if decodeMetadata(task.Metadata[domain.SchedulerMetadataSendAttempts], &attempts) {
+		sends.attempts = attempts
+	}
+	return sends
+}
+
+func decodeMetadata(value interface{}, out interface{}) bool {
+	if value == nil {
+		return false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
+
+// skip indica se o participante já foi notificado ou esgotou as tentativas
+func (t *taskSends) skip(id uuid.UUID) bool {
+	return t.notified[id] || t.attempts[id] >= t.maxAttempts
+}
+
+// sent registra o participante como notificado
+func (t *taskSends) sent(id uuid.UUID) {
+	t.notified[id] = true
+	t.changed = true
+}
+
+// failed registra um envio que falhou; retorna true se o participante esgotou as tentativas
+func (t *taskSends) failed(id uuid.UUID) bool {
+	t.attempts[id]++
+	t.changed = true
+	if t.attempts[id] >= t.maxAttempts {
+		return true
+	}
+	t.retrying++
+	return false
+}
+
+// metadata retorna o Metadata da task com o progresso dos envios
+func (t *taskSends) metadata(task *domain.Scheduler) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(task.Metadata)+2)
+	for k, v := range task.Metadata {
+		metadata[k] = v
+	}
+
+	notified := make([]string, 0, len(t.notified))
+	for id := range t.notified {
+		notified = append(notified, id.String())
+	}
+	attempts := make(map[string]int, len(t.attempts))
+	for id, n := range t.attempts {
+		attempts[id.String()] = n
+	}
+	metadata[domain.SchedulerMetadataNotified] = notified
+	metadata[domain.SchedulerMetadataSendAttempts] = attempts
+	return metadata
+}
+
+// sendToParticipants envia a cada participante que ainda não foi notificado pela task,
+// respeitando o limite de tentativas de cada um. Um erro que interrompe a task
+// (abortsTask) grava o progresso e é retornado; se algum participante falhou e ainda
+// tem tentativas, retorna *participantRetryError
+func (s *schedulerServiceImpl) sendToParticipants(
+	ctx context.Context,
+	task *domain.Scheduler,
+	participants []*domain.Participant,
+	kind string,
+	send func(p *domain.Participant) error,
+) error {
+	sends := newTaskSends(task, s.workerConfig.NotificationMaxAttempts)
+
+	for _, p := range participants {
+		if sends.skip(p.ID) {
+			continue
+		}
+
+		err := send(p)
+		if err == nil {
+			sends.sent(p.ID)
+			continue
+		}
+		if abortsTask(err) {
+			// Quem já recebeu não recebe de novo quando a task voltar
+			if sends.changed {
+				_ = s.saveTaskSends(ctx, task, sends)
+			}
+			return err
+		}
+
+		exhausted := sends.failed(p.ID)
+		s.logger.Error("Failed to send "+kind,
+			zap.String("task_id", task.ID.String()),
+			zap.String("participant_id", p.ID.String()),
+			zap.Int("attempts", sends.attempts[p.ID]),
+			zap.Bool("gave_up", exhausted),
+			zap.Error(err),
+		)
+	}
+
+	if sends.retrying == 0 {
+		return nil
+	}
+	// Sem o progresso gravado as tentativas não avançam: o retry passa a contar como
+	// retry da task, que tem limite
+	if err := s.saveTaskSends(ctx, task, sends); err != nil {
+		return fmt.Errorf("failed to save task send progress: %w", err)
+	}
+	return &participantRetryError{pending: sends.retrying}
+}
+
+// saveTaskSends grava o progresso dos envios no Metadata da task
+func (s *schedulerServiceImpl) saveTaskSends(ctx context.Context, task *domain.Scheduler, sends *taskSends) error {
+	metadata := sends.metadata(task)
+	if err := s.schedulerRepo.UpdateMetadata(ctx, task.ID, task.EntityID, metadata); err != nil {
+		s.logger.Error("Failed to save task send progress",
+			zap.String("task_id", task.ID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+	task.Metadata = metadata
+	return nil
+}
@@ -2,15 +2,38 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"event-coming/internal/config"
 	"event-coming/internal/domain"
 	"event-coming/internal/repository"
+	"event-coming/internal/whatsapp"
+	"event-coming/pkg/timefmt"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// staleClaimTimeout é quanto tempo uma task pode ficar reservada (running) antes de
+// ser considerada abandonada (worker morto no meio do lote) e reservada de novo
+const staleClaimTimeout = 15 * time.Minute
+
+// permanentTaskError marca um erro de task que não se resolve com retry: a task é marcada
+// como failed na hora, sem gastar as tentativas restantes
+type permanentTaskError struct {
+	err error
+}
+
+func (e *permanentTaskError) Error() string { return e.err.Error() }
+func (e *permanentTaskError) Unwrap() error { return e.err }
+
+// Limites da listagem de tasks a disparar (visão operacional)
+const (
+	defaultDueLimit = 100
+	maxDueLimit     = 500
+)
+
 // SchedulerService define os métodos do serviço de agendamento
 type SchedulerService interface {
 	// Criar agendamento
@@ -24,13 +47,28 @@ type SchedulerService interface {
 
 	// Processar tasks pendentes (chamado pelo worker)
 	ProcessPendingTasks(ctx context.Context, limit int) (int, error)
+
+	// Executar uma task pendente imediatamente, ignorando o horário agendado
+	RunNow(ctx context.Context, id uuid.UUID, orgID uuid.UUID) (*domain.Scheduler, error)
+
+	// Listar as tasks pendentes de todas as entidades que disparam na janela (admin)
+	ListDueBetween(ctx context.Context, from, to time.Time, limit int) ([]*domain.DueScheduler, error)
+
+	// Completar os eventos ainda active depois do fim, das entidades que optaram (chamado pelo worker)
+	AutoCompleteEnded(ctx context.Context, limit int) (int, error)
 }
 
 type schedulerServiceImpl struct {
 	schedulerRepo       repository.SchedulerRepository
 	participantRepo     repository.ParticipantRepository
 	eventRepo           repository.EventRepository
+	entityRepo          repository.EntityRepository
+	userRepo            repository.UserRepository
 	notificationService NotificationService
+	eventCache          *EventCacheService
+	statusHistory       *StatusHistoryService
+	webhooks            *WebhookDispatcher
+	workerConfig        *config.WorkerConfig
 	logger              *zap.Logger
 }
 
@@ -38,14 +76,31 @@ func NewSchedulerService(
 	schedulerRepo repository.SchedulerRepository,
 	participantRepo repository.ParticipantRepository,
 	eventRepo repository.EventRepository,
+	entityRepo repository.EntityRepository,
+	userRepo repository.UserRepository,
 	notificationService NotificationService,
+	eventCache *EventCacheService,
+	statusHistory *StatusHistoryService,
+	webhooks *WebhookDispatcher,
+	workerConfig *config.WorkerConfig,
 	logger *zap.Logger,
 ) SchedulerService {
+	// Em dry run as mensagens são só registradas no log
+	if workerConfig.DryRun {
+		notificationService = NewDryRunNotificationService(notificationService, logger)
+	}
+
 	return &schedulerServiceImpl{
 		schedulerRepo:       schedulerRepo,
 		participantRepo:     participantRepo,
 		eventRepo:           eventRepo,
+		entityRepo:          entityRepo,
+		userRepo:            userRepo,
 		notificationService: notificationService,
+		eventCache:          eventCache,
+		statusHistory:       statusHistory,
+		webhooks:            webhooks,
+		workerConfig:        workerConfig,
 		logger:              logger,
 	}
 }
@@ -87,6 +142,69 @@ func (s *schedulerServiceImpl) GetByID(ctx context.Context, id uuid.UUID, orgID
 	return s.schedulerRepo.GetByID(ctx, id, orgID)
 }
 
+// ListDueBetween lista as tasks pendentes, de todas as entidades, agendadas entre from e to
+// (inclusive), na ordem em que o worker vai dispará-las
+func (s *schedulerServiceImpl) ListDueBetween(ctx context.Context, from, to time.Time, limit int) ([]*domain.DueScheduler, error) {
+	if to.Before(from) {
+		return nil, domain.ErrInvalidInput
+	}
+	if limit <= 0 {
+		limit = defaultDueLimit
+	}
+	limit = min(limit, maxDueLimit)
+
+	return s.schedulerRepo.ListDueBetween(ctx, from, to, limit)
+}
+
+// AutoCompleteEnded completa os eventos que continuam active depois do fim mais a carência
+// configurada, nas entidades com auto_complete_events. Cada evento passa pelo mesmo
+// fechamento do scheduler de closure (no_show incluído) e o organizador recebe o resumo
+// final. Falhas em um evento são registradas e não interrompem os demais
+func (s *schedulerServiceImpl) AutoCompleteEnded(ctx context.Context, limit int) (int, error) {
+	endedBefore := time.Now().Add(-s.workerConfig.AutoCompleteGrace)
+	events, err := s.eventRepo.ListEndedActive(ctx, endedBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return completed, ctx.Err()
+		}
+
+		if err := s.closeEvent(ctx, event); err != nil {
+			s.logger.Error("Failed to auto-complete event",
+				zap.String("event_id", event.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		// Em dry run o evento continua active e voltaria a cada ciclo: o resumo não é logado
+		if s.workerConfig.DryRun {
+			continue
+		}
+		completed++
+
+		s.logger.Info("Event auto-completed",
+			zap.String("event_id", event.ID.String()),
+			zap.String("entity_id", event.EntityID.String()),
+		)
+
+		if !event.Features.Allows(domain.SchedulerActionOrganizerWrapUp) {
+			continue
+		}
+		if err := s.sendOrganizerSummary(ctx, event, domain.SchedulerActionOrganizerWrapUp); err != nil && !errors.Is(err, domain.ErrChannelUnavailable) {
+			s.logger.Warn("Failed to send organizer wrap-up",
+				zap.String("event_id", event.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return completed, nil
+}
+
 // Cancel cancela um agendamento pendente
 func (s *schedulerServiceImpl) Cancel(ctx context.Context, id uuid.UUID, orgID uuid.UUID) error {
 	scheduler, err := s.schedulerRepo.GetByID(ctx, id, orgID)
@@ -102,10 +220,12 @@ func (s *schedulerServiceImpl) Cancel(ctx context.Context, id uuid.UUID, orgID u
 	return s.schedulerRepo.Update(ctx, scheduler)
 }
 
-// ProcessPendingTasks processa as tasks pendentes
+// ProcessPendingTasks reserva e processa as tasks pendentes. Se o ctx for cancelado no
+// meio do lote, as tasks ainda não iniciadas são devolvidas para pending
 func (s *schedulerServiceImpl) ProcessPendingTasks(ctx context.Context, limit int) (int, error) {
-	// Buscar tasks pendentes que já passaram do horário
-	tasks, err := s.schedulerRepo.ListPending(ctx, time.Now(), limit)
+	// Reservar tasks pendentes que já passaram do horário
+	now := time.Now()
+	tasks, err := s.schedulerRepo.ClaimPending(ctx, now, now.Add(-staleClaimTimeout), limit)
 	if err != nil {
 		return 0, err
 	}
@@ -117,36 +237,270 @@ func (s *schedulerServiceImpl) ProcessPendingTasks(ctx context.Context, limit in
 	s.logger.Debug("Found pending tasks", zap.Int("count", len(tasks)))
 
 	processed := 0
-	for _, task := range tasks {
-		if err := s.processTask(ctx, task); err != nil {
-			s.logger.Error("Failed to process task",
-				zap.String("task_id", task.ID.String()),
-				zap.String("action", string(task.Action)),
-				zap.Error(err),
-			)
+	for i, task := range tasks {
+		if ctx.Err() != nil {
+			s.releaseClaims(ctx, tasks[i:])
+			break
+		}
+		if s.deferForQuietHours(ctx, task, now) {
+			continue
+		}
+		if err := s.runTask(ctx, task); err != nil {
+			continue
+		}
+		processed++
+	}
 
-			// Incrementar retries
-			_ = s.schedulerRepo.IncrementRetries(ctx, task.ID, task.EntityID)
+	return processed, nil
+}
 
-			// Se excedeu max retries, marcar como falha
-			if task.Retries+1 >= task.MaxRetries {
-				_ = s.schedulerRepo.MarkAsFailed(ctx, task.ID, task.EntityID, err.Error())
-			}
-			continue
+// deferForQuietHours reagenda a task para o fim da janela de silêncio da entidade quando
+// ela dispararia dentro dela. O fechamento não envia mensagens e nunca é adiado; se não
+// der para carregar a entidade ou o evento, a task segue normalmente. Uma task anterior
+// ao início do evento (confirmação, lembrete...) não é adiada para depois dele: é pulada
+func (s *schedulerServiceImpl) deferForQuietHours(ctx context.Context, task *domain.Scheduler, now time.Time) bool {
+	if task.Action == domain.SchedulerActionClosure {
+		return false
+	}
+
+	entity, err := s.entityRepo.GetByID(ctx, task.EntityID)
+	if err != nil {
+		s.logger.Warn("Failed to load entity for quiet hours",
+			zap.String("task_id", task.ID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+	quiet, ok := entity.QuietHours()
+	if !ok {
+		return false
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, task.EventID, task.EntityID)
+	if err != nil {
+		s.logger.Warn("Failed to load event for quiet hours",
+			zap.String("task_id", task.ID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	next := quiet.NextAllowed(now, timefmt.LoadLocation(event.Timezone))
+	if !next.After(now) {
+		return false
+	}
+
+	if task.ScheduledAt.Before(event.StartTime) && !next.Before(event.StartTime) {
+		s.skipForQuietHours(ctx, task, next)
+		return true
+	}
+
+	if err := s.schedulerRepo.Reschedule(ctx, task.ID, task.EntityID, next); err != nil {
+		s.logger.Error("Failed to defer task for quiet hours",
+			zap.String("task_id", task.ID.String()),
+			zap.Error(err),
+		)
+		s.releaseClaims(ctx, []*domain.Scheduler{task})
+		return true
+	}
+
+	s.logger.Info("Task deferred for quiet hours",
+		zap.String("task_id", task.ID.String()),
+		zap.String("action", string(task.Action)),
+		zap.Time("scheduled_at", next),
+	)
+	return true
+}
+
+// skipForQuietHours marca como pulada a task que só poderia sair depois do início do
+// evento, quando já não serve mais
+func (s *schedulerServiceImpl) skipForQuietHours(ctx context.Context, task *domain.Scheduler, next time.Time) {
+	reason := "quiet hours last until after the event start"
+	task.Status = domain.SchedulerStatusSkipped
+	task.ErrorMessage = &reason
+	if err := s.schedulerRepo.Update(ctx, task); err != nil {
+		s.logger.Error("Failed to skip task for quiet hours",
+			zap.String("task_id", task.ID.String()),
+			zap.Error(err),
+		)
+		s.releaseClaims(ctx, []*domain.Scheduler{task})
+		return
+	}
+
+	s.logger.Info("Task skipped, quiet hours last until after the event start",
+		zap.String("task_id", task.ID.String()),
+		zap.String("action", string(task.Action)),
+		zap.Time("quiet_hours_end", next),
+	)
+}
+
+// releaseClaims devolve tasks reservadas para pending. Usa um contexto próprio porque
+// normalmente é chamado justamente quando o ctx do lote foi cancelado
+func (s *schedulerServiceImpl) releaseClaims(ctx context.Context, tasks []*domain.Scheduler) {
+	if len(tasks) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := s.schedulerRepo.ReleaseClaims(releaseCtx, ids); err != nil {
+		s.logger.Error("Failed to release scheduler claims",
+			zap.Int("count", len(ids)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.logger.Info("Released scheduler claims", zap.Int("count", len(ids)))
+}
+
+// RunNow executa uma task pendente imediatamente (testes e recuperação),
+// pelo mesmo fluxo do worker
+func (s *schedulerServiceImpl) RunNow(ctx context.Context, id uuid.UUID, orgID uuid.UUID) (*domain.Scheduler, error) {
+	task, err := s.schedulerRepo.GetByID(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserva a task para o worker não processá-la ao mesmo tempo
+	if err := s.schedulerRepo.Claim(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Running scheduler task on demand",
+		zap.String("task_id", task.ID.String()),
+		zap.Time("scheduled_at", task.ScheduledAt),
+	)
+
+	if err := s.runTask(ctx, task); err != nil {
+		return nil, err
+	}
+
+	return s.schedulerRepo.GetByID(ctx, id, orgID)
+}
+
+// runTask processa uma task reservada e registra o resultado: processada, ou
+// retry/falha quando o processamento dá erro
+func (s *schedulerServiceImpl) runTask(ctx context.Context, task *domain.Scheduler) error {
+	err := s.processTask(ctx, task)
+
+	// Sem canal configurado um retry falharia igual: a task é dada como processada
+	if errors.Is(err, domain.ErrChannelUnavailable) {
+		s.logger.Warn("Notification channel not configured, task processed without sending",
+			zap.String("task_id", task.ID.String()),
+			zap.String("action", string(task.Action)),
+		)
+		err = nil
+	}
+
+	// API do WhatsApp fora do ar: a task volta para depois do cooldown do circuit breaker
+	// sem consumir uma tentativa
+	var circuitOpen *whatsapp.CircuitOpenError
+	if errors.As(err, &circuitOpen) && ctx.Err() == nil {
+		s.deferForOpenCircuit(ctx, task, time.Now().Add(circuitOpen.RetryAfter))
+		return err
+	}
+
+	// Participantes que falharam e ainda têm tentativas: a task volta para pending sem
+	// consumir um retry; quem já foi notificado não recebe de novo
+	var participantRetry *participantRetryError
+	if errors.As(err, &participantRetry) && ctx.Err() == nil {
+		s.logger.Warn("Task has participants to retry",
+			zap.String("task_id", task.ID.String()),
+			zap.String("action", string(task.Action)),
+			zap.Int("participants", participantRetry.pending),
+		)
+		_ = s.schedulerRepo.ReleaseClaims(ctx, []uuid.UUID{task.ID})
+		return err
+	}
+
+	if err != nil {
+		// Interrompida pelo shutdown: não conta como tentativa
+		if ctx.Err() != nil {
+			s.releaseClaims(ctx, []*domain.Scheduler{task})
+			return err
 		}
 
-		// Marcar como processado
-		if err := s.schedulerRepo.MarkAsProcessed(ctx, task.ID, task.EntityID); err != nil {
-			s.logger.Error("Failed to mark task as processed",
+		// Falha que um retry não resolve: a task falha de vez
+		var permanent *permanentTaskError
+		if errors.As(err, &permanent) {
+			s.logger.Error("Task failed permanently",
 				zap.String("task_id", task.ID.String()),
+				zap.String("action", string(task.Action)),
 				zap.Error(err),
 			)
+			_ = s.schedulerRepo.MarkAsFailed(ctx, task.ID, task.EntityID, err.Error())
+			return err
 		}
 
-		processed++
+		s.logger.Error("Failed to process task",
+			zap.String("task_id", task.ID.String()),
+			zap.String("action", string(task.Action)),
+			zap.Error(err),
+		)
+
+		// Incrementar retries
+		_ = s.schedulerRepo.IncrementRetries(ctx, task.ID, task.EntityID)
+
+		// Se excedeu max retries, marcar como falha; senão volta para pending
+		if task.Retries+1 >= task.MaxRetries {
+			_ = s.schedulerRepo.MarkAsFailed(ctx, task.ID, task.EntityID, err.Error())
+		} else {
+			_ = s.schedulerRepo.ReleaseClaims(ctx, []uuid.UUID{task.ID})
+		}
+		return err
 	}
 
-	return processed, nil
+	// Em dry run a task só é marcada como processada se configurado; senão volta para pending
+	if s.workerConfig.DryRun && !s.workerConfig.DryRunMarkProcessed {
+		s.logger.Info("[dry run] Task left pending",
+			zap.String("task_id", task.ID.String()),
+			zap.String("action", string(task.Action)),
+		)
+		_ = s.schedulerRepo.ReleaseClaims(ctx, []uuid.UUID{task.ID})
+		return nil
+	}
+
+	// Marcar como processado
+	if err := s.schedulerRepo.MarkAsProcessed(ctx, task.ID, task.EntityID); err != nil {
+		s.logger.Error("Failed to mark task as processed",
+			zap.String("task_id", task.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
+
+// deferForOpenCircuit devolve a task para pending em retryAt, liberando o claim se
+// não for possível reagendá-la
+func (s *schedulerServiceImpl) deferForOpenCircuit(ctx context.Context, task *domain.Scheduler, retryAt time.Time) {
+	if err := s.schedulerRepo.Reschedule(ctx, task.ID, task.EntityID, retryAt); err != nil {
+		s.logger.Error("Failed to defer task for open WhatsApp circuit",
+			zap.String("task_id", task.ID.String()),
+			zap.Error(err),
+		)
+		s.releaseClaims(ctx, []*domain.Scheduler{task})
+		return
+	}
+
+	s.logger.Warn("WhatsApp circuit open, task deferred",
+		zap.String("task_id", task.ID.String()),
+		zap.String("action", string(task.Action)),
+		zap.Time("scheduled_at", retryAt),
+	)
+}
+
+// abortsTask indica se o erro de um envio interrompe os envios aos demais participantes:
+// sem canal ou com o circuit breaker aberto, os próximos falhariam igual
+func abortsTask(err error) bool {
+	return errors.Is(err, domain.ErrChannelUnavailable) || errors.Is(err, whatsapp.ErrCircuitOpen)
 }
 
 // processTask processa uma task individual
@@ -170,12 +524,53 @@ func (s *schedulerServiceImpl) processTask(ctx context.Context, task *domain.Sch
 	case domain.SchedulerActionLocation:
 		return s.processLocationRequest(ctx, task)
 
+	case domain.SchedulerActionOrganizerSummary, domain.SchedulerActionOrganizerWrapUp:
+		return s.processOrganizerSummary(ctx, task)
+
+	case domain.SchedulerActionBroadcast:
+		return s.processBroadcast(ctx, task)
+
 	default:
 		s.logger.Warn("Unknown scheduler action", zap.String("action", string(task.Action)))
 		return nil
 	}
 }
 
+// logFeatureDisabled registra uma task ignorada porque o recurso foi desligado no evento
+// depois de o scheduler ser criado
+func (s *schedulerServiceImpl) logFeatureDisabled(task *domain.Scheduler) {
+	s.logger.Info("Task skipped, feature disabled for the event",
+		zap.String("task_id", task.ID.String()),
+		zap.String("action", string(task.Action)),
+		zap.String("event_id", task.EventID.String()),
+	)
+}
+
+// taskParticipants lista os participantes a que a task se refere: os da ocorrência, para
+// tasks de uma instância, ou os do evento sem instância. Assim as cópias dos convidados da
+// série em cada ocorrência não recebem a mensagem de novo
+func (s *schedulerServiceImpl) taskParticipants(ctx context.Context, task *domain.Scheduler) ([]*domain.Participant, error) {
+	participants, err := s.participantRepo.ListAllByEvent(ctx, task.EventID, task.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	return participantsOfInstance(participants, task.InstanceID), nil
+}
+
+// participantsOfInstance filtra os participantes da instância; com instanceID nil, os do
+// evento que não pertencem a nenhuma ocorrência
+func participantsOfInstance(participants []*domain.Participant, instanceID *uuid.UUID) []*domain.Participant {
+	filtered := make([]*domain.Participant, 0, len(participants))
+	for _, p := range participants {
+		switch {
+		case instanceID == nil && p.InstanceID == nil,
+			instanceID != nil && p.InstanceID != nil && *p.InstanceID == *instanceID:
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
 // processConfirmation envia pedido de confirmação para participantes
 func (s *schedulerServiceImpl) processConfirmation(ctx context.Context, task *domain.Scheduler) error {
 	// Buscar evento
@@ -183,29 +578,29 @@ func (s *schedulerServiceImpl) processConfirmation(ctx context.Context, task *do
 	if err != nil {
 		return err
 	}
+	if !event.Features.Allows(task.Action) {
+		s.logFeatureDisabled(task)
+		return nil
+	}
 
 	// Buscar participantes pendentes
-	participants, _, err := s.participantRepo.ListByEvent(ctx, task.EventID, task.EntityID, 1, 1000)
+	participants, err := s.taskParticipants(ctx, task)
 	if err != nil {
 		return err
 	}
 
 	// Filtrar apenas pendentes
+	pending := make([]*domain.Participant, 0, len(participants))
 	for _, p := range participants {
-		if p.Status != domain.ParticipantStatusPending {
-			continue
-		}
-
-		if err := s.notificationService.SendConfirmationRequest(ctx, event, p); err != nil {
-			s.logger.Error("Failed to send confirmation",
-				zap.String("participant_id", p.ID.String()),
-				zap.Error(err),
-			)
-			// Continua com os outros participantes
+		if p.Status == domain.ParticipantStatusPending {
+			pending = append(pending, p)
 		}
 	}
 
-	return nil
+	// Uma falha não impede os outros participantes
+	return s.sendToParticipants(ctx, task, pending, "confirmation", func(p *domain.Participant) error {
+		return s.notificationService.SendConfirmationRequest(ctx, event, p)
+	})
 }
 
 // processReminder envia lembretes para participantes confirmados
@@ -215,36 +610,179 @@ func (s *schedulerServiceImpl) processReminder(ctx context.Context, task *domain
 	if err != nil {
 		return err
 	}
+	if !event.Features.Allows(task.Action) {
+		s.logFeatureDisabled(task)
+		return nil
+	}
 
 	// Buscar participantes confirmados
-	participants, _, err := s.participantRepo.ListByEvent(ctx, task.EventID, task.EntityID, 1, 1000)
+	participants, err := s.taskParticipants(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	entity, err := s.entityRepo.GetByID(ctx, task.EntityID)
 	if err != nil {
 		return err
 	}
 
 	// Filtrar apenas confirmados
+	confirmed := make([]*domain.Participant, 0, len(participants))
 	for _, p := range participants {
-		if p.Status != domain.ParticipantStatusConfirmed {
-			continue
+		// Já lembrado no resumo disparado por outro evento do mesmo dia
+		if p.Status == domain.ParticipantStatusConfirmed && p.ReminderDigestAt == nil {
+			confirmed = append(confirmed, p)
 		}
+	}
 
-		if err := s.notificationService.SendReminder(ctx, event, p); err != nil {
-			s.logger.Error("Failed to send reminder",
-				zap.String("participant_id", p.ID.String()),
-				zap.Error(err),
-			)
+	return s.sendToParticipants(ctx, task, confirmed, "reminder", func(p *domain.Participant) error {
+		if entity.ReminderDigest && p.PhoneNumber != "" {
+			sent, err := s.sendReminderDigest(ctx, event, p)
+			if sent {
+				// Resumo enviado: reenviar por não ter marcado as participações duplicaria
+				if err != nil {
+					s.logger.Error("Failed to mark reminder digest",
+						zap.String("participant_id", p.ID.String()),
+						zap.Error(err),
+					)
+				}
+				return nil
+			}
+			if err != nil {
+				return err
+			}
 		}
+		return s.notificationService.SendReminder(ctx, event, p)
+	})
+}
+
+// sendReminderDigest agrupa os eventos do participante no mesmo dia (no fuso do evento) em
+// um único lembrete e marca as participações cobertas, para que os lembretes dos outros
+// eventos não sejam reenviados. Retorna false se só houver este evento no dia
+func (s *schedulerServiceImpl) sendReminderDigest(ctx context.Context, event *domain.Event, p *domain.Participant) (bool, error) {
+	loc := timefmt.LoadLocation(event.Timezone)
+	start := event.StartTime.In(loc)
+	dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	sameDay, err := s.eventRepo.ListReminderDigest(ctx, p.EntityID, p.PhoneNumber, dayStart, dayEnd)
+	if err != nil {
+		return false, err
 	}
 
-	return nil
+	// Eventos com notificações desligadas não entram no resumo
+	events := make([]*domain.Event, 0, len(sameDay))
+	ids := make([]uuid.UUID, 0, len(sameDay))
+	for _, pe := range sameDay {
+		if !pe.Features.NotificationsEnabled() {
+			continue
+		}
+		events = append(events, &pe.Event)
+		ids = append(ids, pe.ParticipantID)
+	}
+	if len(events) < 2 {
+		return false, nil
+	}
+
+	if err := s.notificationService.SendReminderDigest(ctx, p, events); err != nil {
+		return false, err
+	}
+
+	if s.workerConfig.DryRun {
+		return true, nil
+	}
+	return true, s.participantRepo.MarkReminderDigest(ctx, p.EntityID, ids)
 }
 
-// processClosure fecha o evento
+// processClosure fecha o evento da task
 func (s *schedulerServiceImpl) processClosure(ctx context.Context, task *domain.Scheduler) error {
+	event, err := s.eventRepo.GetByID(ctx, task.EventID, task.EntityID)
+	if err != nil {
+		return err
+	}
+
+	return s.closeEvent(ctx, event)
+}
+
+// closeEvent marca o evento como completed e, se o evento optou por isso, marca os ausentes
+// como no_show
+func (s *schedulerServiceImpl) closeEvent(ctx context.Context, event *domain.Event) error {
+	if s.workerConfig.DryRun {
+		s.logger.Info("[dry run] Event not completed",
+			zap.String("event_id", event.ID.String()),
+			zap.Bool("mark_no_shows", event.MarkNoShows),
+		)
+		return nil
+	}
+
 	// Atualizar status do evento para completed
-	return s.eventRepo.Update(ctx, task.EventID, task.EntityID, &domain.UpdateEventInput{
+	if err := s.eventRepo.Update(ctx, event.ID, event.EntityID, &domain.UpdateEventInput{
 		Status: func() *domain.EventStatus { s := domain.EventStatusCompleted; return &s }(),
-	})
+	}); err != nil {
+		return err
+	}
+
+	if event.Status != domain.EventStatusCompleted {
+		completed := *event
+		completed.Status = domain.EventStatusCompleted
+		s.webhooks.DispatchEventStatus(ctx, event, &completed)
+	}
+
+	if !event.MarkNoShows {
+		return nil
+	}
+
+	// Confirmados que nunca fizeram check-in
+	marked, err := s.participantRepo.MarkNoShows(ctx, event.ID, event.EntityID)
+	if err != nil {
+		return err
+	}
+
+	ctx = domain.WithStatusSource(ctx, domain.StatusSourceSystem)
+	for _, id := range marked {
+		s.statusHistory.RecordParticipant(ctx, event.EntityID, id, domain.ParticipantStatusConfirmed, domain.ParticipantStatusNoShow)
+	}
+	s.syncNoShowsCache(ctx, event, marked)
+
+	s.logger.Info("Participants marked as no-show",
+		zap.String("event_id", event.ID.String()),
+		zap.Int("count", len(marked)),
+	)
+
+	return nil
+}
+
+// syncNoShowsCache atualiza no cache do evento a confirmação dos participantes marcados
+// como no_show, como as outras mudanças de status fazem (best effort)
+func (s *schedulerServiceImpl) syncNoShowsCache(ctx context.Context, event *domain.Event, marked []uuid.UUID) {
+	if s.eventCache == nil || len(marked) == 0 {
+		return
+	}
+
+	participants, err := s.participantRepo.ListAllByEvent(ctx, event.ID, event.EntityID)
+	if err != nil {
+		s.logger.Warn("Failed to load no-show participants for the cache",
+			zap.String("event_id", event.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	markedSet := make(map[uuid.UUID]struct{}, len(marked))
+	for _, id := range marked {
+		markedSet[id] = struct{}{}
+	}
+	for _, p := range participants {
+		if _, ok := markedSet[p.ID]; !ok {
+			continue
+		}
+		if err := s.eventCache.SetConfirmation(ctx, event.EntityID, event.ID, p, event.EndTime); err != nil {
+			s.logger.Warn("Failed to update no-show in the event cache",
+				zap.String("participant_id", p.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
 // processLocationRequest solicita localização dos participantes
@@ -254,25 +792,109 @@ func (s *schedulerServiceImpl) processLocationRequest(ctx context.Context, task
 	if err != nil {
 		return err
 	}
+	if !event.Features.Allows(task.Action) {
+		s.logFeatureDisabled(task)
+		return nil
+	}
 
 	// Buscar participantes confirmados que ainda não fizeram check-in
-	participants, _, err := s.participantRepo.ListByEvent(ctx, task.EventID, task.EntityID, 1, 1000)
+	participants, err := s.taskParticipants(ctx, task)
 	if err != nil {
 		return err
 	}
 
+	confirmed := make([]*domain.Participant, 0, len(participants))
 	for _, p := range participants {
-		if p.Status != domain.ParticipantStatusConfirmed {
-			continue
+		if p.Status == domain.ParticipantStatusConfirmed {
+			confirmed = append(confirmed, p)
 		}
+	}
 
-		if err := s.notificationService.SendLocationRequest(ctx, event, p); err != nil {
-			s.logger.Error("Failed to send location request",
-				zap.String("participant_id", p.ID.String()),
-				zap.Error(err),
+	return s.sendToParticipants(ctx, task, confirmed, "location request", func(p *domain.Participant) error {
+		return s.notificationService.SendLocationRequest(ctx, event, p)
+	})
+}
+
+// processOrganizerSummary envia ao criador do evento a contagem de participantes por status:
+// antes do início (organizer_summary) ou após o fechamento (organizer_wrap_up)
+func (s *schedulerServiceImpl) processOrganizerSummary(ctx context.Context, task *domain.Scheduler) error {
+	event, err := s.eventRepo.GetByID(ctx, task.EventID, task.EntityID)
+	if err != nil {
+		return err
+	}
+	if !event.Features.Allows(task.Action) {
+		s.logFeatureDisabled(task)
+		return nil
+	}
+
+	return s.sendOrganizerSummary(ctx, event, task.Action)
+}
+
+// sendOrganizerSummary conta os participantes por status e envia ao criador do evento o
+// resumo (organizer_summary) ou o resultado final (organizer_wrap_up)
+func (s *schedulerServiceImpl) sendOrganizerSummary(ctx context.Context, event *domain.Event, action domain.SchedulerAction) error {
+	organizer, err := s.userRepo.GetByID(ctx, event.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	counts, err := s.participantRepo.CountByEventStatus(ctx, event.ID, event.EntityID)
+	if err != nil {
+		return err
+	}
+	stats := domain.NewEventStatsFromCounts(counts)
+
+	if action == domain.SchedulerActionOrganizerWrapUp {
+		return s.notificationService.SendOrganizerWrapUp(ctx, event, organizer, stats)
+	}
+	return s.notificationService.SendOrganizerSummary(ctx, event, organizer, stats)
+}
+
+// processBroadcast envia o broadcast agendado pelo organizador. Metadata sem mensagem ou com
+// grupo inválido não tem como dar certo num retry: a task é dada como processada
+func (s *schedulerServiceImpl) processBroadcast(ctx context.Context, task *domain.Scheduler) error {
+	event, err := s.eventRepo.GetByID(ctx, task.EventID, task.EntityID)
+	if err != nil {
+		return err
+	}
+	if !event.Features.Allows(task.Action) {
+		s.logFeatureDisabled(task)
+		return nil
+	}
+
+	message, _ := task.Metadata[domain.BroadcastMetadataMessage].(string)
+	if message == "" {
+		s.logger.Warn("Broadcast task without message",
+			zap.String("task_id", task.ID.String()),
+		)
+		return nil
+	}
+
+	var groupID *uuid.UUID
+	if raw, ok := task.Metadata[domain.BroadcastMetadataGroupID].(string); ok && raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			s.logger.Warn("Broadcast task with invalid group_id",
+				zap.String("task_id", task.ID.String()),
+				zap.String("group_id", raw),
 			)
+			return nil
 		}
+		groupID = &parsed
+	}
+
+	sent, err := s.notificationService.SendBroadcast(ctx, event, groupID, message)
+	if errors.Is(err, errBroadcastGroupGone) {
+		return &permanentTaskError{err: err}
+	}
+	if err != nil {
+		return err
 	}
 
+	s.logger.Info("Broadcast sent",
+		zap.String("task_id", task.ID.String()),
+		zap.String("event_id", event.ID.String()),
+		zap.Int("recipients", sent),
+	)
 	return nil
 }
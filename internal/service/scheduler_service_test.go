@@ -0,0 +1,886 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+	"event-coming/internal/whatsapp"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type schedulerServiceDeps struct {
+	schedulerRepo   *mocks.MockSchedulerRepository
+	participantRepo *mocks.MockParticipantRepository
+	eventRepo       *mocks.MockEventRepository
+	entityRepo      *mocks.MockEntityRepository
+	userRepo        *mocks.MockUserRepository
+	eventCache      *EventCacheService
+	cacheDeps       *eventCacheServiceDeps
+	notifier        *recordingNotifier
+}
+
+// recordingNotifier registra, por tipo de notificação, os participantes notificados, e os
+// resumos enviados ao organizador. Com err preenchido, os envios aos participantes falham;
+// failFor faz falhar só os envios aos participantes listados
+type recordingNotifier struct {
+	sent      map[string][]uuid.UUID
+	summaries []organizerSummary
+	err       error
+	failFor   map[uuid.UUID]error
+}
+
+// organizerSummary é um resumo enviado ao organizador do evento
+type organizerSummary struct {
+	kind      string
+	organizer uuid.UUID
+	stats     domain.EventStats
+}
+
+func (n *recordingNotifier) record(kind string, participant *domain.Participant) error {
+	if n.err != nil {
+		return n.err
+	}
+	if err := n.failFor[participant.ID]; err != nil {
+		return err
+	}
+	n.sent[kind] = append(n.sent[kind], participant.ID)
+	return nil
+}
+
+func (n *recordingNotifier) SendConfirmationRequest(ctx context.Context, event *domain.Event, participant *domain.Participant) error {
+	return n.record("confirmation", participant)
+}
+
+func (n *recordingNotifier) SendReminder(ctx context.Context, event *domain.Event, participant *domain.Participant) error {
+	return n.record("reminder", participant)
+}
+
+func (n *recordingNotifier) SendReminderDigest(ctx context.Context, participant *domain.Participant, events []*domain.Event) error {
+	return n.record("reminder_digest", participant)
+}
+
+func (n *recordingNotifier) SendBroadcast(ctx context.Context, event *domain.Event, groupID *uuid.UUID, message string) (int, error) {
+	return 0, n.err
+}
+
+func (n *recordingNotifier) PreviewBroadcast(ctx context.Context, entID, eventID uuid.UUID, groupID *uuid.UUID, message string) (*dto.BroadcastPreviewResponse, error) {
+	return &dto.BroadcastPreviewResponse{}, nil
+}
+
+func (n *recordingNotifier) SendLocationRequest(ctx context.Context, event *domain.Event, participant *domain.Participant) error {
+	return n.record("location", participant)
+}
+
+func (n *recordingNotifier) SendETAUpdate(ctx context.Context, event *domain.Event, participant *domain.Participant, etaMinutes int) error {
+	return n.record("eta", participant)
+}
+
+func (n *recordingNotifier) SendMessage(ctx context.Context, entityID uuid.UUID, phoneNumber string, message string) error {
+	return nil
+}
+
+func (n *recordingNotifier) SendOrganizerSummary(ctx context.Context, event *domain.Event, organizer *domain.User, stats domain.EventStats) error {
+	n.summaries = append(n.summaries, organizerSummary{kind: "summary", organizer: organizer.ID, stats: stats})
+	return nil
+}
+
+func (n *recordingNotifier) SendOrganizerWrapUp(ctx context.Context, event *domain.Event, organizer *domain.User, stats domain.EventStats) error {
+	n.summaries = append(n.summaries, organizerSummary{kind: "wrap_up", organizer: organizer.ID, stats: stats})
+	return nil
+}
+
+func newTestSchedulerService(t *testing.T) (SchedulerService, *schedulerServiceDeps) {
+	t.Helper()
+	return newTestSchedulerServiceWithConfig(t, &config.WorkerConfig{})
+}
+
+// newTestSchedulerServiceWithConfig monta o serviço com a configuração de worker informada
+func newTestSchedulerServiceWithConfig(t *testing.T, workerConfig *config.WorkerConfig) (SchedulerService, *schedulerServiceDeps) {
+	t.Helper()
+
+	eventCache, cacheDeps := newTestEventCacheService(t)
+	deps := &schedulerServiceDeps{
+		schedulerRepo:   new(mocks.MockSchedulerRepository),
+		participantRepo: cacheDeps.participantRepo,
+		eventRepo:       cacheDeps.eventRepo,
+		entityRepo:      new(mocks.MockEntityRepository),
+		userRepo:        new(mocks.MockUserRepository),
+		eventCache:      eventCache,
+		cacheDeps:       cacheDeps,
+		notifier:        &recordingNotifier{sent: map[string][]uuid.UUID{}},
+	}
+	svc := NewSchedulerService(deps.schedulerRepo, deps.participantRepo, deps.eventRepo, deps.entityRepo, deps.userRepo, deps.notifier, eventCache, nil, nil, workerConfig, zap.NewNop())
+	return svc, deps
+}
+
+// newTestClosureTask devolve uma task de encerramento pendente para o evento
+func newTestClosureTask(event *domain.Event) *domain.Scheduler {
+	return &domain.Scheduler{
+		ID:         uuid.New(),
+		EntityID:   event.EntityID,
+		EventID:    event.ID,
+		Action:     domain.SchedulerActionClosure,
+		Status:     domain.SchedulerStatusPending,
+		MaxRetries: 3,
+	}
+}
+
+// completesEvent casa com a atualização que marca o evento como concluído
+var completesEvent = mock.MatchedBy(func(input *domain.UpdateEventInput) bool {
+	return input.Status != nil && *input.Status == domain.EventStatusCompleted
+})
+
+func TestSchedulerService_Closure_MarksNoShowsWhenOptedIn(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+
+	event := testutil.NewTestEvent()
+	event.MarkNoShows = true
+	task := newTestClosureTask(event)
+
+	noShow := testutil.NewTestParticipant()
+	noShow.Status = domain.ParticipantStatusNoShow
+	checkedIn := testutil.NewTestParticipant()
+	checkedIn.ID = uuid.New()
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("Update", ctx, event.ID, event.EntityID, completesEvent).Return(nil)
+	deps.participantRepo.On("MarkNoShows", ctx, event.ID, event.EntityID).Return([]uuid.UUID{noShow.ID}, nil)
+	deps.participantRepo.On("ListAllByEvent", mock.Anything, event.ID, event.EntityID).Return([]*domain.Participant{noShow, checkedIn}, nil)
+
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	deps.participantRepo.AssertCalled(t, "MarkNoShows", ctx, event.ID, event.EntityID)
+
+	// Só o participante marcado como no_show é atualizado no cache
+	cached, err := deps.cacheDeps.client.Get(ctx, confirmationKey(event.EntityID, event.ID, noShow.ID)).Result()
+	require.NoError(t, err)
+	assert.Contains(t, cached, string(domain.ParticipantStatusNoShow))
+	assert.False(t, deps.cacheDeps.redis.Exists(confirmationKey(event.EntityID, event.ID, checkedIn.ID)))
+}
+
+func TestSchedulerService_Closure_SkipsNoShowsWhenNotOptedIn(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+
+	event := testutil.NewTestEvent()
+	event.MarkNoShows = false
+	task := newTestClosureTask(event)
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("Update", ctx, event.ID, event.EntityID, completesEvent).Return(nil)
+
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	deps.participantRepo.AssertNotCalled(t, "MarkNoShows", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchedulerService_Closure_NothingMarkedSkipsCache(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+
+	event := testutil.NewTestEvent()
+	event.MarkNoShows = true
+	task := newTestClosureTask(event)
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("Update", ctx, event.ID, event.EntityID, completesEvent).Return(nil)
+	deps.participantRepo.On("MarkNoShows", ctx, event.ID, event.EntityID).Return([]uuid.UUID{}, nil)
+
+	_, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	deps.participantRepo.AssertNotCalled(t, "ListAllByEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchedulerService_RunNow_ProcessesEachAction(t *testing.T) {
+	tests := []struct {
+		action domain.SchedulerAction
+		// notificação enviada e status do participante que a recebe; vazia no encerramento
+		notification string
+		status       domain.ParticipantStatus
+	}{
+		{action: domain.SchedulerActionConfirmation, notification: "confirmation", status: domain.ParticipantStatusPending},
+		{action: domain.SchedulerActionReminder, notification: "reminder", status: domain.ParticipantStatusConfirmed},
+		{action: domain.SchedulerActionLocation, notification: "location", status: domain.ParticipantStatusConfirmed},
+		{action: domain.SchedulerActionClosure},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestSchedulerService(t)
+
+			event := testutil.NewTestEvent()
+			task := newTestClosureTask(event)
+			task.Action = tt.action
+			task.ScheduledAt = time.Now().Add(24 * time.Hour) // ainda não venceu
+			processed := *task
+			processed.Status = domain.SchedulerStatusProcessed
+
+			target := testutil.NewTestParticipant()
+			target.Status = tt.status
+			other := testutil.NewTestParticipant()
+			other.ID = uuid.New()
+			other.Status = domain.ParticipantStatusDenied
+
+			deps.schedulerRepo.On("GetByID", ctx, task.ID, task.EntityID).Return(task, nil).Once()
+			deps.schedulerRepo.On("Claim", ctx, task.ID, task.EntityID).Return(nil)
+			deps.schedulerRepo.On("GetByID", ctx, task.ID, task.EntityID).Return(&processed, nil)
+			deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+			deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+			deps.eventRepo.On("Update", ctx, event.ID, event.EntityID, completesEvent).Return(nil)
+			deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+			deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).
+				Return([]*domain.Participant{target, other}, nil)
+
+			result, err := svc.RunNow(ctx, task.ID, task.EntityID)
+			require.NoError(t, err)
+			assert.Equal(t, domain.SchedulerStatusProcessed, result.Status)
+			deps.schedulerRepo.AssertCalled(t, "MarkAsProcessed", ctx, task.ID, task.EntityID)
+
+			if tt.notification == "" {
+				deps.eventRepo.AssertCalled(t, "Update", ctx, event.ID, event.EntityID, completesEvent)
+				assert.Empty(t, deps.notifier.sent)
+				return
+			}
+			assert.Equal(t, map[string][]uuid.UUID{tt.notification: {target.ID}}, deps.notifier.sent)
+		})
+	}
+}
+
+func TestSchedulerService_RunNow_RejectsTasksNotPending(t *testing.T) {
+	for _, status := range []domain.SchedulerStatus{domain.SchedulerStatusProcessed, domain.SchedulerStatusFailed, domain.SchedulerStatusSkipped} {
+		t.Run(string(status), func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestSchedulerService(t)
+
+			task := newTestClosureTask(testutil.NewTestEvent())
+			task.Status = status
+			deps.schedulerRepo.On("GetByID", ctx, task.ID, task.EntityID).Return(task, nil)
+			// A reserva é condicional ao status pending
+			deps.schedulerRepo.On("Claim", ctx, task.ID, task.EntityID).Return(domain.ErrSchedulerNotPending)
+
+			_, err := svc.RunNow(ctx, task.ID, task.EntityID)
+			assert.ErrorIs(t, err, domain.ErrSchedulerNotPending)
+			deps.schedulerRepo.AssertNotCalled(t, "MarkAsProcessed", mock.Anything, mock.Anything, mock.Anything)
+			deps.eventRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSchedulerService_ProcessPendingTasks_ReleasesClaimsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc, deps := newTestSchedulerService(t)
+
+	event := testutil.NewTestEvent()
+	tasks := []*domain.Scheduler{newTestClosureTask(event), newTestClosureTask(event), newTestClosureTask(event)}
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return(tasks, nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, tasks[0].ID, tasks[0].EntityID).Return(nil)
+	deps.schedulerRepo.On("ReleaseClaims", mock.Anything, mock.Anything).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	// O prazo de shutdown estoura enquanto a primeira task roda
+	deps.eventRepo.On("Update", ctx, event.ID, event.EntityID, completesEvent).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(nil)
+
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+
+	// A task em andamento termina; as não iniciadas voltam para pending
+	assert.Equal(t, 1, processed)
+	deps.schedulerRepo.AssertCalled(t, "MarkAsProcessed", ctx, tasks[0].ID, tasks[0].EntityID)
+	deps.schedulerRepo.AssertCalled(t, "ReleaseClaims", mock.Anything, []uuid.UUID{tasks[1].ID, tasks[2].ID})
+	deps.eventRepo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+// quietAroundNow devolve uma entidade com janela de silêncio de uma hora antes a uma hora
+// depois de now (UTC) e o fim da janela
+func quietAroundNow(now time.Time) (*domain.Entity, time.Time) {
+	start := now.UTC().Add(-time.Hour).Format("15:04")
+	end := now.UTC().Add(time.Hour)
+	endStr := end.Format("15:04")
+
+	entity := testutil.NewTestEntity()
+	entity.QuietHoursStart = &start
+	entity.QuietHoursEnd = &endStr
+	return entity, end.Truncate(time.Minute)
+}
+
+// newTestReminderTask devolve uma task de lembrete pendente para o evento
+func newTestReminderTask(event *domain.Event) *domain.Scheduler {
+	task := newTestClosureTask(event)
+	task.Action = domain.SchedulerActionReminder
+	task.ScheduledAt = time.Now().Add(-time.Minute)
+	return task
+}
+
+func TestSchedulerService_ProcessPendingTasks_DefersReminderInsideQuietHours(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+
+	entity, quietEnd := quietAroundNow(time.Now())
+	event := testutil.NewTestEvent()
+	event.Timezone = "UTC"
+	event.StartTime = time.Now().Add(3 * time.Hour)
+	task := newTestReminderTask(event)
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(entity, nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	// Reagendada para o fim da janela
+	deps.schedulerRepo.On("Reschedule", ctx, task.ID, task.EntityID, mock.MatchedBy(func(at time.Time) bool {
+		return at.Equal(quietEnd)
+	})).Return(nil)
+
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+	assert.Empty(t, deps.notifier.sent)
+
+	deps.schedulerRepo.AssertExpectations(t)
+	deps.schedulerRepo.AssertNotCalled(t, "MarkAsFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchedulerService_ProcessPendingTasks_SkipsReminderWhenQuietHoursPassEventStart(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+
+	entity, _ := quietAroundNow(time.Now())
+	event := testutil.NewTestEvent()
+	event.Timezone = "UTC"
+	event.StartTime = time.Now().Add(30 * time.Minute)
+	task := newTestReminderTask(event)
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(entity, nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.schedulerRepo.On("Update", ctx, mock.MatchedBy(func(s *domain.Scheduler) bool {
+		return s.ID == task.ID && s.Status == domain.SchedulerStatusSkipped
+	})).Return(nil)
+
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+	assert.Empty(t, deps.notifier.sent)
+	deps.schedulerRepo.AssertNotCalled(t, "Reschedule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	deps.schedulerRepo.AssertExpectations(t)
+}
+
+func TestSchedulerService_ListDueBetween_Window(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+
+	from := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	due := []*domain.DueScheduler{{Scheduler: domain.Scheduler{ID: uuid.New(), ScheduledAt: from}}}
+	deps.schedulerRepo.On("ListDueBetween", ctx, from, from.Add(time.Hour), defaultDueLimit).Return(due, nil)
+	deps.schedulerRepo.On("ListDueBetween", ctx, from, from, defaultDueLimit).Return(due, nil)
+
+	got, err := svc.ListDueBetween(ctx, from, from.Add(time.Hour), 0)
+	require.NoError(t, err)
+	assert.Equal(t, due, got)
+
+	// Janela vazia (from == to) é válida; to antes de from não
+	_, err = svc.ListDueBetween(ctx, from, from, 0)
+	require.NoError(t, err)
+
+	_, err = svc.ListDueBetween(ctx, from, from.Add(-time.Second), 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	deps.schedulerRepo.AssertNumberOfCalls(t, "ListDueBetween", 2)
+}
+
+func TestSchedulerService_ListDueBetween_Limit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "default", limit: 0, want: defaultDueLimit},
+		{name: "negative uses default", limit: -1, want: defaultDueLimit},
+		{name: "within bounds", limit: 20, want: 20},
+		{name: "capped", limit: maxDueLimit + 1, want: maxDueLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestSchedulerService(t)
+
+			from := time.Now()
+			to := from.Add(time.Hour)
+			deps.schedulerRepo.On("ListDueBetween", ctx, from, to, tt.want).Return([]*domain.DueScheduler{}, nil)
+
+			_, err := svc.ListDueBetween(ctx, from, to, tt.limit)
+			require.NoError(t, err)
+			deps.schedulerRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSchedulerService_OrganizerSummary_SendsCountsByStatus(t *testing.T) {
+	tests := []struct {
+		action domain.SchedulerAction
+		kind   string
+	}{
+		{action: domain.SchedulerActionOrganizerSummary, kind: "summary"},
+		{action: domain.SchedulerActionOrganizerWrapUp, kind: "wrap_up"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestSchedulerService(t)
+
+			event := testutil.NewTestEvent()
+			organizer := testutil.NewTestUser()
+			event.CreatedBy = organizer.ID
+			task := newTestClosureTask(event)
+			task.Action = tt.action
+
+			deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+			deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+			deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+			deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+			deps.userRepo.On("GetByID", ctx, organizer.ID).Return(organizer, nil)
+			deps.participantRepo.On("CountByEventStatus", ctx, event.ID, event.EntityID).Return(map[domain.ParticipantStatus]int{
+				domain.ParticipantStatusConfirmed: 5,
+				domain.ParticipantStatusPending:   3,
+				domain.ParticipantStatusDenied:    2,
+				domain.ParticipantStatusCheckedIn: 4,
+				domain.ParticipantStatusNoShow:    1,
+			}, nil)
+
+			processed, err := svc.ProcessPendingTasks(ctx, 10)
+			require.NoError(t, err)
+			assert.Equal(t, 1, processed)
+
+			require.Len(t, deps.notifier.summaries, 1)
+			summary := deps.notifier.summaries[0]
+			assert.Equal(t, tt.kind, summary.kind)
+			assert.Equal(t, organizer.ID, summary.organizer)
+			assert.Equal(t, domain.EventStats{Total: 15, Pending: 3, Confirmed: 5, Denied: 2, CheckedIn: 4, NoShow: 1}, summary.stats)
+			// Participantes não recebem nada
+			assert.Empty(t, deps.notifier.sent)
+		})
+	}
+}
+
+func TestSchedulerService_ProcessPendingTasks_DryRunSkipsNotifications(t *testing.T) {
+	tests := []struct {
+		name          string
+		markProcessed bool
+	}{
+		{name: "left pending", markProcessed: false},
+		{name: "marked processed", markProcessed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestSchedulerServiceWithConfig(t, &config.WorkerConfig{DryRun: true, DryRunMarkProcessed: tt.markProcessed})
+
+			event := testutil.NewTestEvent()
+			event.StartTime = time.Now().Add(3 * time.Hour)
+			task := newTestReminderTask(event)
+			participant := testutil.NewTestParticipant()
+			participant.Status = domain.ParticipantStatusConfirmed
+
+			deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+			deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+			deps.schedulerRepo.On("ReleaseClaims", ctx, []uuid.UUID{task.ID}).Return(nil)
+			deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+			deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+			deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).
+				Return([]*domain.Participant{participant}, nil)
+
+			processed, err := svc.ProcessPendingTasks(ctx, 10)
+			require.NoError(t, err)
+
+			// O processamento segue normalmente, mas nada chega ao NotificationService
+			assert.Equal(t, 1, processed)
+			assert.Empty(t, deps.notifier.sent)
+			if tt.markProcessed {
+				deps.schedulerRepo.AssertCalled(t, "MarkAsProcessed", ctx, task.ID, task.EntityID)
+				deps.schedulerRepo.AssertNotCalled(t, "ReleaseClaims", mock.Anything, mock.Anything)
+			} else {
+				deps.schedulerRepo.AssertCalled(t, "ReleaseClaims", ctx, []uuid.UUID{task.ID})
+				deps.schedulerRepo.AssertNotCalled(t, "MarkAsProcessed", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSchedulerService_Closure_DryRunKeepsEventOpen(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerServiceWithConfig(t, &config.WorkerConfig{DryRun: true, DryRunMarkProcessed: true})
+
+	event := testutil.NewTestEvent()
+	task := newTestClosureTask(event)
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	deps.eventRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchedulerService_ProcessPendingTasks_ReminderDigest(t *testing.T) {
+	tests := []struct {
+		name   string
+		digest bool
+		want   func(first, second *domain.Participant) map[string][]uuid.UUID
+	}{
+		{
+			name:   "digest on",
+			digest: true,
+			want: func(first, _ *domain.Participant) map[string][]uuid.UUID {
+				return map[string][]uuid.UUID{"reminder_digest": {first.ID}}
+			},
+		},
+		{
+			name: "digest off",
+			want: func(first, second *domain.Participant) map[string][]uuid.UUID {
+				return map[string][]uuid.UUID{"reminder": {first.ID, second.ID}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestSchedulerService(t)
+
+			entity := testutil.NewTestEntity()
+			entity.ReminderDigest = tt.digest
+
+			// Dois eventos no mesmo dia com a mesma pessoa confirmada
+			morning := testutil.NewTestEvent()
+			morning.Timezone = "UTC"
+			morning.StartTime = time.Now().UTC().Truncate(24 * time.Hour).Add(36 * time.Hour)
+			evening := testutil.NewTestEvent()
+			evening.ID = uuid.New()
+			evening.Timezone = "UTC"
+			evening.StartTime = morning.StartTime.Add(8 * time.Hour)
+
+			first := newTestParticipantWithPhone(morning.ID, "+5511999990000")
+			first.Status = domain.ParticipantStatusConfirmed
+			second := newTestParticipantWithPhone(evening.ID, "+5511999990000")
+			second.ID = uuid.New()
+			second.Status = domain.ParticipantStatusConfirmed
+
+			tasks := []*domain.Scheduler{newTestReminderTask(morning), newTestReminderTask(evening)}
+			tasks[1].ID = uuid.New()
+
+			deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return(tasks, nil)
+			deps.schedulerRepo.On("MarkAsProcessed", ctx, mock.Anything, mock.Anything).Return(nil)
+			deps.entityRepo.On("GetByID", ctx, entity.ID).Return(entity, nil)
+			deps.eventRepo.On("GetByID", ctx, morning.ID, morning.EntityID).Return(morning, nil)
+			deps.eventRepo.On("GetByID", ctx, evening.ID, evening.EntityID).Return(evening, nil)
+			deps.participantRepo.On("ListAllByEvent", ctx, morning.ID, morning.EntityID).
+				Return([]*domain.Participant{first}, nil)
+			deps.participantRepo.On("ListAllByEvent", ctx, evening.ID, evening.EntityID).
+				Return([]*domain.Participant{second}, nil)
+			deps.eventRepo.On("ListReminderDigest", ctx, entity.ID, "+5511999990000", mock.Anything, mock.Anything).
+				Return([]*domain.ParticipantEvent{
+					{Event: *morning, ParticipantID: first.ID, ParticipantStatus: domain.ParticipantStatusConfirmed},
+					{Event: *evening, ParticipantID: second.ID, ParticipantStatus: domain.ParticipantStatusConfirmed},
+				}, nil)
+			// O resumo marca as duas participações; o lembrete do segundo evento as encontra marcadas
+			deps.participantRepo.On("MarkReminderDigest", ctx, entity.ID, []uuid.UUID{first.ID, second.ID}).
+				Run(func(mock.Arguments) {
+					now := time.Now()
+					second.ReminderDigestAt = &now
+				}).
+				Return(nil)
+
+			processed, err := svc.ProcessPendingTasks(ctx, 10)
+			require.NoError(t, err)
+			assert.Equal(t, 2, processed)
+			assert.Equal(t, tt.want(first, second), deps.notifier.sent)
+			if !tt.digest {
+				deps.eventRepo.AssertNotCalled(t, "ListReminderDigest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSchedulerService_ProcessPendingTasks_NotificationsDisabled(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+
+	// Recurso desligado depois de o lembrete ser agendado
+	off := false
+	event := testutil.NewTestEvent()
+	event.Features.Notifications = &off
+	task := newTestReminderTask(event)
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+	deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Empty(t, deps.notifier.sent)
+	deps.participantRepo.AssertNotCalled(t, "ListAllByEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchedulerService_ProcessPendingTasks_ChannelUnavailableIsNotRetried(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+	deps.notifier.err = domain.ErrChannelUnavailable
+
+	event := testutil.NewTestEvent()
+	task := newTestReminderTask(event)
+	first := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	second := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	second.ID = uuid.New()
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+	deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.Participant{first, second}, nil)
+
+	// Sem canal a task é dada como processada, sem retry nem falha
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	deps.schedulerRepo.AssertCalled(t, "MarkAsProcessed", ctx, task.ID, task.EntityID)
+	deps.schedulerRepo.AssertNotCalled(t, "MarkAsFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	deps.schedulerRepo.AssertNotCalled(t, "IncrementRetries", mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, deps.notifier.sent)
+}
+
+func TestSchedulerService_ProcessPendingTasks_OpenCircuitDefersWithoutRetry(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+	deps.notifier.err = &whatsapp.CircuitOpenError{RetryAfter: 30 * time.Second}
+
+	event := testutil.NewTestEvent()
+	task := newTestReminderTask(event)
+	first := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	second := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	second.ID = uuid.New()
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("Reschedule", ctx, task.ID, task.EntityID, mock.AnythingOfType("time.Time")).Return(nil)
+	deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.Participant{first, second}, nil)
+
+	// Com o circuito aberto a task volta para depois do cooldown sem gastar tentativa
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+
+	deps.schedulerRepo.AssertNumberOfCalls(t, "Reschedule", 1)
+	retryAt := deps.schedulerRepo.Calls[len(deps.schedulerRepo.Calls)-1].Arguments.Get(3).(time.Time)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), retryAt, 5*time.Second)
+	deps.schedulerRepo.AssertNotCalled(t, "IncrementRetries", mock.Anything, mock.Anything, mock.Anything)
+	deps.schedulerRepo.AssertNotCalled(t, "MarkAsFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	deps.schedulerRepo.AssertNotCalled(t, "MarkAsProcessed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchedulerService_ProcessPendingTasks_ParticipantExhaustsAttempts(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerServiceWithConfig(t, &config.WorkerConfig{NotificationMaxAttempts: 2})
+
+	event := testutil.NewTestEvent()
+	task := newTestReminderTask(event)
+	first := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	failing := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	failing.ID = uuid.New()
+	last := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	last.ID = uuid.New()
+	deps.notifier.failFor = map[uuid.UUID]error{failing.ID: errors.New("invalid phone number")}
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("ReleaseClaims", ctx, []uuid.UUID{task.ID}).Return(nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+	deps.schedulerRepo.On("UpdateMetadata", ctx, task.ID, task.EntityID, mock.Anything).Return(nil)
+	deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).
+		Return([]*domain.Participant{first, failing, last}, nil)
+
+	// Primeira execução: os outros são notificados e a task volta para pending sem gastar
+	// retry, com o progresso gravado
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+	assert.Equal(t, []uuid.UUID{first.ID, last.ID}, deps.notifier.sent["reminder"])
+	deps.schedulerRepo.AssertCalled(t, "ReleaseClaims", ctx, []uuid.UUID{task.ID})
+	deps.schedulerRepo.AssertNotCalled(t, "IncrementRetries", mock.Anything, mock.Anything, mock.Anything)
+	deps.schedulerRepo.AssertNotCalled(t, "MarkAsProcessed", mock.Anything, mock.Anything, mock.Anything)
+
+	// A task volta do banco com o Metadata gravado
+	deps.schedulerRepo.AssertNumberOfCalls(t, "UpdateMetadata", 1)
+	for _, call := range deps.schedulerRepo.Calls {
+		if call.Method == "UpdateMetadata" {
+			task.Metadata = roundTripMetadata(t, call.Arguments.Get(3).(map[string]interface{}))
+		}
+	}
+
+	// Segunda execução: só o que falhou é tentado de novo; esgotado, a task é processada
+	processed, err = svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, []uuid.UUID{first.ID, last.ID}, deps.notifier.sent["reminder"])
+	deps.schedulerRepo.AssertCalled(t, "MarkAsProcessed", ctx, task.ID, task.EntityID)
+	deps.schedulerRepo.AssertNumberOfCalls(t, "ReleaseClaims", 1)
+	deps.schedulerRepo.AssertNotCalled(t, "IncrementRetries", mock.Anything, mock.Anything, mock.Anything)
+	deps.schedulerRepo.AssertNotCalled(t, "MarkAsFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// roundTripMetadata simula a leitura do Metadata gravado no jsonb
+func roundTripMetadata(t *testing.T, metadata map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	return decoded
+}
+
+func TestSchedulerService_ProcessPendingTasks_RemindsOnlyTheTaskOccurrence(t *testing.T) {
+	event := testutil.NewTestEvent()
+	monday, tuesday := uuid.New(), uuid.New()
+
+	// Convidado da série, com uma cópia em cada ocorrência
+	series := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	series.ID = uuid.New()
+	onMonday := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	onMonday.ID = uuid.New()
+	onMonday.InstanceID = &monday
+	onTuesday := withStatus(testutil.NewTestParticipant(), domain.ParticipantStatusConfirmed)
+	onTuesday.ID = uuid.New()
+	onTuesday.InstanceID = &tuesday
+	participants := []*domain.Participant{series, onMonday, onTuesday}
+
+	tests := []struct {
+		name       string
+		instanceID *uuid.UUID
+		want       []uuid.UUID
+	}{
+		{name: "task do evento", want: []uuid.UUID{series.ID}},
+		{name: "task da ocorrência", instanceID: &tuesday, want: []uuid.UUID{onTuesday.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, deps := newTestSchedulerService(t)
+
+			task := newTestReminderTask(event)
+			task.InstanceID = tt.instanceID
+
+			deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+			deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+			deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+			deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+			deps.participantRepo.On("ListAllByEvent", ctx, event.ID, event.EntityID).Return(participants, nil)
+
+			_, err := svc.ProcessPendingTasks(ctx, 10)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, deps.notifier.sent["reminder"])
+		})
+	}
+}
+
+func TestSchedulerService_ProcessPendingTasks_BroadcastToDeletedGroupFailsWithoutRetry(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+	deps.notifier.err = fmt.Errorf("%w: %w", errBroadcastGroupGone, domain.ErrNotFound)
+
+	event := testutil.NewTestEvent()
+	task := newTestClosureTask(event)
+	task.Action = domain.SchedulerActionBroadcast
+	task.Metadata = map[string]interface{}{
+		domain.BroadcastMetadataMessage: "Credenciamento na sala VIP",
+		domain.BroadcastMetadataGroupID: uuid.New().String(),
+	}
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("MarkAsFailed", ctx, task.ID, task.EntityID, mock.AnythingOfType("string")).Return(nil)
+	deps.entityRepo.On("GetByID", ctx, event.EntityID).Return(testutil.NewTestEntity(), nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+
+	processed, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+	deps.schedulerRepo.AssertCalled(t, "MarkAsFailed", ctx, task.ID, task.EntityID, mock.AnythingOfType("string"))
+	deps.schedulerRepo.AssertNotCalled(t, "IncrementRetries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchedulerService_AutoCompleteEnded(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerServiceWithConfig(t, &config.WorkerConfig{AutoCompleteGrace: time.Hour})
+
+	// Terminou há 3h: passa da carência. O outro terminou há 30min, ainda dentro dela, e o
+	// terceiro ainda está acontecendo
+	ended := testutil.NewTestEvent()
+	ended.Status = domain.EventStatusActive
+	ended.MarkNoShows = true
+	endedAt := time.Now().Add(-3 * time.Hour)
+	ended.EndTime = &endedAt
+
+	withinGrace := testutil.NewTestEvent()
+	withinGrace.ID = uuid.New()
+	withinGrace.Status = domain.EventStatusActive
+	graceEnd := time.Now().Add(-30 * time.Minute)
+	withinGrace.EndTime = &graceEnd
+
+	running := testutil.NewTestEvent()
+	running.ID = uuid.New()
+	running.Status = domain.EventStatusActive
+	runningEnd := time.Now().Add(time.Hour)
+	running.EndTime = &runningEnd
+
+	// O repositório filtra pelo fim do evento: o corte pedido inclui só o que passou da carência
+	deps.eventRepo.On("ListEndedActive", ctx, mock.MatchedBy(func(endedBefore time.Time) bool {
+		return ended.EndTime.Before(endedBefore) && !withinGrace.EndTime.Before(endedBefore) && !running.EndTime.Before(endedBefore)
+	}), 10).Return([]*domain.Event{ended}, nil)
+	deps.eventRepo.On("Update", ctx, ended.ID, ended.EntityID, completesEvent).Return(nil)
+	deps.participantRepo.On("MarkNoShows", ctx, ended.ID, ended.EntityID).Return([]uuid.UUID{}, nil)
+	deps.userRepo.On("GetByID", ctx, ended.CreatedBy).Return(testutil.NewTestUser(), nil)
+	deps.participantRepo.On("CountByEventStatus", ctx, ended.ID, ended.EntityID).Return(map[domain.ParticipantStatus]int{}, nil)
+
+	completed, err := svc.AutoCompleteEnded(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	deps.participantRepo.AssertCalled(t, "MarkNoShows", ctx, ended.ID, ended.EntityID)
+	deps.eventRepo.AssertNotCalled(t, "Update", mock.Anything, withinGrace.ID, mock.Anything, mock.Anything)
+	deps.eventRepo.AssertNotCalled(t, "Update", mock.Anything, running.ID, mock.Anything, mock.Anything)
+	require.Len(t, deps.notifier.summaries, 1)
+	assert.Equal(t, "wrap_up", deps.notifier.summaries[0].kind)
+}
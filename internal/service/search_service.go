@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// searchMinQueryLength evita buscas por um único caractere, que casariam com quase tudo
+	searchMinQueryLength = 2
+	// searchLimitPerType limita quantos resultados de cada tipo a busca retorna
+	searchLimitPerType = 10
+)
+
+// SearchService gerencia a busca global da entidade
+type SearchService struct {
+	eventRepo       repository.EventRepository
+	participantRepo repository.ParticipantRepository
+}
+
+// NewSearchService cria um novo serviço de busca
+func NewSearchService(eventRepo repository.EventRepository, participantRepo repository.ParticipantRepository) *SearchService {
+	return &SearchService{
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+	}
+}
+
+// Global busca eventos (pelo nome) e participantes (pelo nome ou telefone) da entidade
+func (s *SearchService) Global(ctx context.Context, entID uuid.UUID, query string) (*dto.SearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < searchMinQueryLength {
+		return nil, fmt.Errorf("query must have at least %d characters: %w", searchMinQueryLength, domain.ErrInvalidInput)
+	}
+
+	events, err := s.eventRepo.Search(ctx, entID, query, searchLimitPerType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search events: %w", err)
+	}
+
+	participants, err := s.participantRepo.Search(ctx, entID, query, searchLimitPerType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search participants: %w", err)
+	}
+
+	results := make([]*dto.SearchResult, 0, len(events)+len(participants))
+	for _, e := range events {
+		results = append(results, dto.EventSearchResult(e))
+	}
+	for _, p := range participants {
+		results = append(results, dto.ParticipantSearchResult(p))
+	}
+
+	return &dto.SearchResponse{
+		Query:   query,
+		Results: results,
+	}, nil
+}
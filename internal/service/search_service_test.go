@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchService_Global_MatchesEventsAndParticipants(t *testing.T) {
+	ctx := context.Background()
+	eventRepo := new(mocks.MockEventRepository)
+	participantRepo := new(mocks.MockParticipantRepository)
+	svc := NewSearchService(eventRepo, participantRepo)
+
+	event := testutil.NewTestEvent()
+	event.Name = "Ana's birthday"
+	participant := testutil.NewTestParticipant()
+	participant.RefEntity = testutil.NewTestEntity()
+	participant.RefEntity.Name = "Ana Souza"
+
+	eventRepo.On("Search", mock.Anything, testutil.TestEntityID, "ana", searchLimitPerType).
+		Return([]*domain.Event{event}, nil)
+	participantRepo.On("Search", mock.Anything, testutil.TestEntityID, "ana", searchLimitPerType).
+		Return([]*domain.Participant{participant}, nil)
+
+	resp, err := svc.Global(ctx, testutil.TestEntityID, "  ana ")
+	require.NoError(t, err)
+
+	assert.Equal(t, "ana", resp.Query)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, dto.SearchResultEvent, resp.Results[0].Type)
+	assert.Equal(t, event.ID, resp.Results[0].ID)
+	assert.Equal(t, "Ana's birthday", resp.Results[0].Name)
+	assert.Equal(t, dto.SearchResultParticipant, resp.Results[1].Type)
+	assert.Equal(t, participant.ID, resp.Results[1].ID)
+	assert.Equal(t, "Ana Souza", resp.Results[1].Name)
+	require.NotNil(t, resp.Results[1].EventID)
+	assert.Equal(t, participant.EventID, *resp.Results[1].EventID)
+	eventRepo.AssertExpectations(t)
+	participantRepo.AssertExpectations(t)
+}
+
+func TestSearchService_Global_RejectsShortQuery(t *testing.T) {
+	eventRepo := new(mocks.MockEventRepository)
+	participantRepo := new(mocks.MockParticipantRepository)
+	svc := NewSearchService(eventRepo, participantRepo)
+
+	_, err := svc.Global(context.Background(), testutil.TestEntityID, " a ")
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	eventRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	participantRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxStatusHistory limita quantas mudanças de status a linha do tempo de um participante retorna
+const maxStatusHistory = 500
+
+// StatusHistoryService registra as mudanças de status dos participantes, com o autor e a
+// origem (admin, whatsapp, rsvp, system), para montar a linha do tempo de cada um
+type StatusHistoryService struct {
+	repo   repository.StatusHistoryRepository
+	logger *zap.Logger
+}
+
+// NewStatusHistoryService cria um novo serviço de histórico de status
+func NewStatusHistoryService(repo repository.StatusHistoryRepository, logger *zap.Logger) *StatusHistoryService {
+	return &StatusHistoryService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// RecordParticipant registra a mudança de status do participante. Autor e origem vêm do
+// contexto; status iguais não geram registro. Best effort: falhas só são logadas
+func (s *StatusHistoryService) RecordParticipant(ctx context.Context, entID, participantID uuid.UUID, from, to domain.ParticipantStatus) {
+	if s == nil || from == to {
+		return
+	}
+
+	entry := &domain.StatusHistory{
+		ID:           uuid.New(),
+		ResourceType: domain.StatusResourceParticipant,
+		ResourceID:   participantID,
+		EntityID:     entID,
+		OldStatus:    string(from),
+		NewStatus:    string(to),
+		ChangedBy:    domain.ActorFromContext(ctx),
+		Source:       domain.StatusSourceFromContext(ctx),
+	}
+
+	if err := s.repo.Create(ctx, entry); err != nil {
+		s.logger.Warn("Failed to record participant status change",
+			zap.String("participant_id", participantID.String()),
+			zap.String("new_status", string(to)),
+			zap.Error(err),
+		)
+	}
+}
+
+// ListParticipant retorna as mudanças de status do participante em ordem cronológica
+func (s *StatusHistoryService) ListParticipant(ctx context.Context, participantID uuid.UUID) ([]*dto.ParticipantStatusEventResponse, error) {
+	entries, _, err := s.repo.ListByResource(ctx, domain.StatusResourceParticipant, participantID, 1, maxStatusHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status history: %w", err)
+	}
+
+	// O repositório devolve do mais recente para o mais antigo
+	responses := make([]*dto.ParticipantStatusEventResponse, len(entries))
+	for i, entry := range entries {
+		responses[len(entries)-1-i] = dto.ToParticipantStatusEventResponse(entry)
+	}
+	return responses, nil
+}
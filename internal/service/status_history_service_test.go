@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestStatusHistoryService devolve o serviço e a lista das mudanças gravadas no repositório
+func newTestStatusHistoryService() (*StatusHistoryService, *[]*domain.StatusHistory) {
+	var recorded []*domain.StatusHistory
+	repo := new(mocks.MockStatusHistoryRepository)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.StatusHistory")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(1).(*domain.StatusHistory)) }).
+		Return(nil)
+	return NewStatusHistoryService(repo, zap.NewNop()), &recorded
+}
+
+// expectStatusChange prepara o participante para ser lido com o status antigo e, depois
+// da atualização, com o novo
+func expectStatusChange(participantRepo *mocks.MockParticipantRepository, participant *domain.Participant, to domain.ParticipantStatus, reads int) {
+	participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(participant, nil).Times(reads)
+	participantRepo.On("GetByID", mock.Anything, participant.ID, participant.EntityID).Return(withStatus(participant, to), nil)
+}
+
+func TestStatusHistory_AdminUpdateRecordsActor(t *testing.T) {
+	svc, _, deps := newTestParticipantService(t)
+	history, recorded := newTestStatusHistoryService()
+	svc.statusHistory = history
+
+	participant := testutil.NewTestParticipant()
+	expectStatusChange(deps.participantRepo, participant, domain.ParticipantStatusConfirmed, 1)
+	deps.participantRepo.On("UpdateStatus", mock.Anything, participant.ID, participant.EntityID, domain.ParticipantStatusConfirmed).Return(nil)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(testutil.NewTestEvent(), nil)
+
+	ctx := domain.WithActor(context.Background(), testutil.TestUserID)
+	require.NoError(t, svc.UpdateStatus(ctx, participant.EntityID, participant.ID, domain.ParticipantStatusConfirmed))
+
+	require.Len(t, *recorded, 1)
+	entry := (*recorded)[0]
+	assert.Equal(t, domain.StatusSourceAdmin, entry.Source)
+	require.NotNil(t, entry.ChangedBy)
+	assert.Equal(t, testutil.TestUserID, *entry.ChangedBy)
+	assert.Equal(t, string(domain.ParticipantStatusPending), entry.OldStatus)
+	assert.Equal(t, string(domain.ParticipantStatusConfirmed), entry.NewStatus)
+}
+
+func TestStatusHistory_RSVPResponse(t *testing.T) {
+	ctx := context.Background()
+	svc, _, deps := newTestParticipantService(t)
+	history, recorded := newTestStatusHistoryService()
+	svc.statusHistory = history
+
+	participant := testutil.NewTestParticipant()
+	token, _, err := signRSVPToken(svc.rsvp, participant, time.Now())
+	require.NoError(t, err)
+
+	// Lido pelo token e antes da atualização
+	expectStatusChange(deps.participantRepo, participant, domain.ParticipantStatusDenied, 2)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(testutil.NewTestEvent(), nil)
+	deps.participantRepo.On("Update", mock.Anything, participant.ID, participant.EntityID, mock.Anything).Return(nil)
+
+	_, err = svc.RespondToInvite(ctx, token, domain.ParticipantStatusDenied, nil)
+	require.NoError(t, err)
+
+	require.Len(t, *recorded, 1)
+	assert.Equal(t, domain.StatusSourceRSVP, (*recorded)[0].Source)
+	assert.Nil(t, (*recorded)[0].ChangedBy)
+}
+
+func TestStatusHistory_WhatsAppReply(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestInboundMessageService(t)
+	history, recorded := newTestStatusHistoryService()
+	svc.participantService.statusHistory = history
+
+	participant := testutil.NewTestParticipant()
+	msg := newTestInboundMessage("whatsapp", "wamid.history", domain.InboundMessageReply)
+	msg.ReplyID = "confirm_yes"
+	deps.participantRepo.On("GetActiveByPhoneNumber", mock.Anything, msg.From).Return(participant, nil)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(testutil.NewTestEvent(), nil)
+	deps.participantRepo.On("UpdateStatus", mock.Anything, participant.ID, participant.EntityID, domain.ParticipantStatusConfirmed).Return(nil)
+	expectStatusChange(deps.participantRepo, participant, domain.ParticipantStatusConfirmed, 1)
+
+	require.NoError(t, svc.Handle(ctx, msg))
+
+	require.Len(t, *recorded, 1)
+	assert.Equal(t, domain.StatusSourceWhatsApp, (*recorded)[0].Source)
+}
+
+func TestStatusHistory_NoShowOnClosure(t *testing.T) {
+	ctx := context.Background()
+	svc, deps := newTestSchedulerService(t)
+	history, recorded := newTestStatusHistoryService()
+	svc.(*schedulerServiceImpl).statusHistory = history
+
+	event := testutil.NewTestEvent()
+	event.MarkNoShows = true
+	task := newTestClosureTask(event)
+	marked := []uuid.UUID{uuid.New(), uuid.New()}
+
+	deps.schedulerRepo.On("ClaimPending", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).Return([]*domain.Scheduler{task}, nil)
+	deps.schedulerRepo.On("MarkAsProcessed", ctx, task.ID, task.EntityID).Return(nil)
+	deps.eventRepo.On("GetByID", ctx, event.ID, event.EntityID).Return(event, nil)
+	deps.eventRepo.On("Update", ctx, event.ID, event.EntityID, completesEvent).Return(nil)
+	deps.participantRepo.On("MarkNoShows", ctx, event.ID, event.EntityID).Return(marked, nil)
+	deps.participantRepo.On("ListAllByEvent", mock.Anything, event.ID, event.EntityID).Return([]*domain.Participant{}, nil)
+
+	_, err := svc.ProcessPendingTasks(ctx, 10)
+	require.NoError(t, err)
+
+	require.Len(t, *recorded, len(marked))
+	for i, entry := range *recorded {
+		assert.Equal(t, marked[i], entry.ResourceID)
+		assert.Equal(t, domain.StatusSourceSystem, entry.Source)
+		assert.Equal(t, string(domain.ParticipantStatusNoShow), entry.NewStatus)
+	}
+}
+
+func TestStatusHistory_ReconcileRecordsSourceAndActor(t *testing.T) {
+	svc, _, deps := newTestParticipantService(t)
+	history, recorded := newTestStatusHistoryService()
+	svc.statusHistory = history
+
+	participant := testutil.NewTestParticipant()
+	// Lido pelo reconcile e antes da atualização
+	expectStatusChange(deps.participantRepo, participant, domain.ParticipantStatusConfirmed, 2)
+	deps.participantRepo.On("Update", mock.Anything, participant.ID, participant.EntityID, mock.Anything).Return(nil)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(testutil.NewTestEvent(), nil)
+
+	// O motivo só vale para recusas
+	reason := "respondeu sim"
+	ctx := domain.WithActor(context.Background(), testutil.TestUserID)
+	resp, err := svc.Reconcile(ctx, participant.EntityID, participant.ID, domain.ParticipantStatusConfirmed, &reason)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ParticipantStatusConfirmed, resp.Status)
+	assert.Nil(t, resp.DeclineReason)
+	deps.participantRepo.AssertNotCalled(t, "SetDeclineReason", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	require.Len(t, *recorded, 1)
+	entry := (*recorded)[0]
+	assert.Equal(t, domain.StatusSourceReconcile, entry.Source)
+	require.NotNil(t, entry.ChangedBy)
+	assert.Equal(t, testutil.TestUserID, *entry.ChangedBy)
+	assert.Equal(t, string(domain.ParticipantStatusPending), entry.OldStatus)
+	assert.Equal(t, string(domain.ParticipantStatusConfirmed), entry.NewStatus)
+}
+
+func TestStatusHistory_ReconcileDenialKeepsReason(t *testing.T) {
+	svc, _, deps := newTestParticipantService(t)
+	history, recorded := newTestStatusHistoryService()
+	svc.statusHistory = history
+
+	participant := testutil.NewTestParticipant()
+	reason := "viagem"
+	expectStatusChange(deps.participantRepo, participant, domain.ParticipantStatusDenied, 2)
+	deps.participantRepo.On("Update", mock.Anything, participant.ID, participant.EntityID, mock.Anything).Return(nil)
+	deps.participantRepo.On("SetDeclineReason", mock.Anything, participant.ID, participant.EntityID, &reason).Return(nil)
+	deps.eventRepo.On("GetByID", mock.Anything, participant.EventID, participant.EntityID).Return(testutil.NewTestEvent(), nil)
+
+	ctx := domain.WithActor(context.Background(), testutil.TestUserID)
+	resp, err := svc.Reconcile(ctx, participant.EntityID, participant.ID, domain.ParticipantStatusDenied, &reason)
+	require.NoError(t, err)
+	require.NotNil(t, resp.DeclineReason)
+	assert.Equal(t, reason, *resp.DeclineReason)
+
+	require.Len(t, *recorded, 1)
+	assert.Equal(t, domain.StatusSourceReconcile, (*recorded)[0].Source)
+	assert.Equal(t, string(domain.ParticipantStatusDenied), (*recorded)[0].NewStatus)
+}
+
+func TestStatusHistory_SameStatusIsNotRecorded(t *testing.T) {
+	history, recorded := newTestStatusHistoryService()
+	history.RecordParticipant(context.Background(), testutil.TestEntityID, uuid.New(), domain.ParticipantStatusConfirmed, domain.ParticipantStatusConfirmed)
+	assert.Empty(t, *recorded)
+}
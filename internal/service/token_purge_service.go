@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/repository"
+)
+
+// TokenPurgeService apaga os tokens de autenticação que não servem mais (refresh tokens
+// expirados ou revogados, tokens de reset de senha expirados ou usados), para as tabelas
+// não crescerem sem limite
+type TokenPurgeService struct {
+	refreshTokens repository.RefreshTokenRepository
+	resetTokens   repository.PasswordResetTokenRepository
+	retention     time.Duration
+}
+
+// TokenPurgeResult conta os tokens apagados em uma execução
+type TokenPurgeResult struct {
+	RefreshTokens       int64
+	PasswordResetTokens int64
+}
+
+// Total retorna quantos tokens foram apagados
+func (r TokenPurgeResult) Total() int64 {
+	return r.RefreshTokens + r.PasswordResetTokens
+}
+
+// NewTokenPurgeService cria um novo serviço de limpeza de tokens
+func NewTokenPurgeService(
+	refreshTokens repository.RefreshTokenRepository,
+	resetTokens repository.PasswordResetTokenRepository,
+	cfg *config.WorkerConfig,
+) *TokenPurgeService {
+	retention := cfg.TokenRetention
+	if retention < 0 {
+		retention = 0
+	}
+
+	return &TokenPurgeService{
+		refreshTokens: refreshTokens,
+		resetTokens:   resetTokens,
+		retention:     retention,
+	}
+}
+
+// Purge apaga os tokens que expiraram, foram revogados ou usados há mais que a retenção
+func (s *TokenPurgeService) Purge(ctx context.Context, now time.Time) (TokenPurgeResult, error) {
+	var result TokenPurgeResult
+	before := now.Add(-s.retention)
+
+	deleted, err := s.refreshTokens.DeleteExpired(ctx, before)
+	if err != nil {
+		return result, fmt.Errorf("failed to purge refresh tokens: %w", err)
+	}
+	result.RefreshTokens = deleted
+
+	deleted, err = s.resetTokens.DeleteExpired(ctx, before)
+	if err != nil {
+		return result, fmt.Errorf("failed to purge password reset tokens: %w", err)
+	}
+	result.PasswordResetTokens = deleted
+
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenPurgeService_Purge_UsesRetentionCutoff(t *testing.T) {
+	ctx := context.Background()
+	refreshTokens := new(mocks.MockRefreshTokenRepository)
+	resetTokens := new(mocks.MockPasswordResetTokenRepository)
+	svc := NewTokenPurgeService(refreshTokens, resetTokens, &config.WorkerConfig{TokenRetention: 7 * 24 * time.Hour})
+
+	// Só os tokens vencidos, revogados ou usados antes do corte são apagados
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	cutoff := now.AddDate(0, 0, -7)
+	refreshTokens.On("DeleteExpired", ctx, cutoff).Return(int64(3), nil)
+	resetTokens.On("DeleteExpired", ctx, cutoff).Return(int64(2), nil)
+
+	result, err := svc.Purge(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.RefreshTokens)
+	assert.Equal(t, int64(2), result.PasswordResetTokens)
+	assert.Equal(t, int64(5), result.Total())
+}
+
+func TestTokenPurgeService_Purge_NegativeRetentionPurgesUpToNow(t *testing.T) {
+	ctx := context.Background()
+	refreshTokens := new(mocks.MockRefreshTokenRepository)
+	resetTokens := new(mocks.MockPasswordResetTokenRepository)
+	svc := NewTokenPurgeService(refreshTokens, resetTokens, &config.WorkerConfig{TokenRetention: -time.Hour})
+
+	// Uma retenção negativa não apaga tokens ainda válidos
+	now := time.Now()
+	refreshTokens.On("DeleteExpired", ctx, now).Return(int64(0), nil)
+	resetTokens.On("DeleteExpired", ctx, now).Return(int64(0), nil)
+
+	result, err := svc.Purge(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, result.Total())
+}
+
+func TestTokenPurgeService_Purge_RefreshTokenFailure(t *testing.T) {
+	ctx := context.Background()
+	refreshTokens := new(mocks.MockRefreshTokenRepository)
+	resetTokens := new(mocks.MockPasswordResetTokenRepository)
+	svc := NewTokenPurgeService(refreshTokens, resetTokens, &config.WorkerConfig{TokenRetention: time.Hour})
+
+	dbErr := errors.New("connection reset")
+	refreshTokens.On("DeleteExpired", ctx, mock.AnythingOfType("time.Time")).Return(int64(0), dbErr)
+
+	_, err := svc.Purge(ctx, time.Now())
+	assert.ErrorIs(t, err, dbErr)
+	resetTokens.AssertNotCalled(t, "DeleteExpired", mock.Anything, mock.Anything)
+}
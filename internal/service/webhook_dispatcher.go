@@ -0,0 +1,298 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Headers sent with every outbound webhook
+const (
+	WebhookSignatureHeader = "X-Event-Coming-Signature"
+	WebhookEventHeader     = "X-Event-Coming-Event"
+	WebhookDeliveryHeader  = "X-Event-Coming-Delivery"
+)
+
+// WebhookPayload is the JSON body POSTed to subscribers
+type WebhookPayload struct {
+	ID         uuid.UUID               `json:"id"`
+	Type       domain.WebhookEventType `json:"type"`
+	EntityID   uuid.UUID               `json:"entity_id"`
+	OccurredAt time.Time               `json:"occurred_at"`
+	Data       interface{}             `json:"data"`
+}
+
+// WebhookDispatcher delivers signed lifecycle callbacks to the webhooks registered by an entity.
+// Deliveries run in goroutines tracked by the dispatcher, so Shutdown can wait for them.
+type WebhookDispatcher struct {
+	repo         repository.WebhookRepository
+	client       *http.Client
+	maxAttempts  int
+	retryBackoff time.Duration
+	logger       *zap.Logger
+
+	// ctx is cancelled by Shutdown when in-flight deliveries don't finish in time
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewWebhookDispatcher creates a new webhook dispatcher
+func NewWebhookDispatcher(repo repository.WebhookRepository, cfg *config.OutboundWebhookConfig, logger *zap.Logger) *WebhookDispatcher {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		repo:         repo,
+		client:       newWebhookHTTPClient(cfg.Timeout),
+		maxAttempts:  maxAttempts,
+		retryBackoff: cfg.RetryBackoff,
+		logger:       logger,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Shutdown stops accepting deliveries and waits for the in-flight ones. If ctx ends first,
+// pending retries are abandoned (and recorded as failed) before returning ctx's error.
+func (d *WebhookDispatcher) Shutdown(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// SignWebhookPayload returns the signature header value ("sha256=<hex>") of body,
+// the HMAC-SHA256 with the subscription secret
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatch sends the event to every active subscription of the entity that wants it.
+// Deliveries run in the background; failures are only logged and recorded in the delivery log.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, entID uuid.UUID, eventType domain.WebhookEventType, data interface{}) {
+	if d == nil {
+		return
+	}
+
+	subscriptions, err := d.repo.ListActiveSubscriptions(ctx, entID)
+	if err != nil {
+		d.logger.Warn("Failed to list webhook subscriptions",
+			zap.String("entity_id", entID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	payload := WebhookPayload{
+		ID:         uuid.New(),
+		Type:       eventType,
+		EntityID:   entID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.logger.Error("Failed to encode webhook payload", zap.Error(err))
+		return
+	}
+
+	// A entrega não é cancelada junto com a requisição que a originou, só pelo Shutdown
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(eventType) {
+			continue
+		}
+		if !d.track() {
+			d.logger.Warn("Webhook dropped, dispatcher is shutting down",
+				zap.String("subscription_id", subscription.ID.String()),
+				zap.String("event_type", string(eventType)),
+			)
+			continue
+		}
+		go func() {
+			defer d.wg.Done()
+			d.deliver(d.ctx, subscription, payload, body)
+		}()
+	}
+}
+
+// track registers a delivery with the wait group unless Shutdown has started
+func (d *WebhookDispatcher) track() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return false
+	}
+	d.wg.Add(1)
+	return true
+}
+
+// deliver POSTs the payload, retrying 5xx and network errors with exponential backoff,
+// and records the outcome in the delivery log
+func (d *WebhookDispatcher) deliver(ctx context.Context, subscription *domain.WebhookSubscription, payload WebhookPayload, body []byte) {
+	delivery := &domain.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		EntityID:       subscription.EntityID,
+		EventType:      payload.Type,
+	}
+	_ = json.Unmarshal(body, &delivery.Payload)
+
+	backoff := d.retryBackoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		delivery.Attempts = attempt
+
+		statusCode, err := d.send(ctx, subscription, payload, body)
+		if statusCode != 0 {
+			delivery.StatusCode = &statusCode
+		}
+
+		if err == nil && statusCode < 300 {
+			delivery.Success = true
+			delivery.ErrorMessage = nil
+			break
+		}
+
+		message := fmt.Sprintf("status %d", statusCode)
+		if err != nil {
+			message = err.Error()
+		}
+		delivery.ErrorMessage = &message
+
+		// 4xx (e 3xx, que não é seguido) não é retentado: o destino rejeitou o payload.
+		// Um destino não público também não, a próxima tentativa falharia igual
+		if err == nil && statusCode < 500 {
+			break
+		}
+		if errors.Is(err, errWebhookTarget) || errors.Is(err, domain.ErrInvalidInput) {
+			break
+		}
+
+		if attempt < d.maxAttempts {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				message := "delivery abandoned on shutdown"
+				delivery.ErrorMessage = &message
+				attempt = d.maxAttempts
+			}
+			backoff *= 2
+		}
+	}
+
+	if !delivery.Success {
+		d.logger.Warn("Webhook delivery failed",
+			zap.String("subscription_id", subscription.ID.String()),
+			zap.String("event_type", string(payload.Type)),
+			zap.Int("attempts", delivery.Attempts),
+		)
+	}
+
+	// Registrado mesmo quando o Shutdown cancelou as tentativas
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := d.repo.CreateDelivery(recordCtx, delivery); err != nil {
+		d.logger.Warn("Failed to record webhook delivery", zap.Error(err))
+	}
+}
+
+// send performs a single signed POST and returns the response status code. The URL is
+// validated again because the webhook may have been saved before the current rules
+func (d *WebhookDispatcher) send(ctx context.Context, subscription *domain.WebhookSubscription, payload WebhookPayload, body []byte) (int, error) {
+	if err := validateWebhookURL(ctx, subscription.URL); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, body))
+	req.Header.Set(WebhookEventHeader, string(payload.Type))
+	req.Header.Set(WebhookDeliveryHeader, payload.ID.String())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// eventStatusWebhooks maps event status transitions to webhook events
+var eventStatusWebhooks = map[domain.EventStatus]domain.WebhookEventType{
+	domain.EventStatusActive:    domain.WebhookEventEventActivated,
+	domain.EventStatusCancelled: domain.WebhookEventEventCancelled,
+	domain.EventStatusCompleted: domain.WebhookEventEventCompleted,
+}
+
+// participantStatusWebhooks maps participant status transitions to webhook events
+var participantStatusWebhooks = map[domain.ParticipantStatus]domain.WebhookEventType{
+	domain.ParticipantStatusConfirmed: domain.WebhookEventParticipantConfirmed,
+	domain.ParticipantStatusCheckedIn: domain.WebhookEventParticipantCheckedIn,
+}
+
+// DispatchEventStatus dispatches the webhook for an event that moved to a new status, if any
+func (d *WebhookDispatcher) DispatchEventStatus(ctx context.Context, before, after *domain.Event) {
+	if before != nil && before.Status == after.Status {
+		return
+	}
+	if eventType, ok := eventStatusWebhooks[after.Status]; ok {
+		d.Dispatch(ctx, after.EntityID, eventType, after)
+	}
+}
+
+// DispatchParticipantStatus dispatches the webhook for a participant that moved to a new status, if any
+func (d *WebhookDispatcher) DispatchParticipantStatus(ctx context.Context, before, after *domain.Participant) {
+	if before != nil && before.Status == after.Status {
+		return
+	}
+	if eventType, ok := participantStatusWebhooks[after.Status]; ok {
+		d.Dispatch(ctx, after.EntityID, eventType, after)
+	}
+}
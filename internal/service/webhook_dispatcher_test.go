@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// webhookTestURL é um endereço público (TEST-NET-3), aceito pela validação de destino
+const webhookTestURL = "https://203.0.113.10/hooks"
+
+// roundTripperFunc entrega as requisições direto a um handler, sem abrir conexões
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// webhookReceiver registra as requisições recebidas e responde com os status da fila
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w := httptest.NewRecorder()
+	w.WriteHeader(status)
+	return w.Result(), nil
+}
+
+func newTestWebhookDispatcher(receiver *webhookReceiver) (*WebhookDispatcher, *mocks.MockWebhookRepository) {
+	repo := new(mocks.MockWebhookRepository)
+	d := NewWebhookDispatcher(repo, &config.OutboundWebhookConfig{
+		MaxAttempts:  3,
+		RetryBackoff: time.Millisecond,
+	}, zap.NewNop())
+	d.client = &http.Client{Transport: roundTripperFunc(receiver.RoundTrip)}
+	return d, repo
+}
+
+func newTestWebhookSubscription() *domain.WebhookSubscription {
+	return &domain.WebhookSubscription{
+		ID:       uuid.New(),
+		EntityID: testutil.TestEntityID,
+		URL:      webhookTestURL,
+		Secret:   "whsec_test",
+		Active:   true,
+	}
+}
+
+// dispatchAndWait dispara o webhook e espera a entrega registrada
+func dispatchAndWait(t *testing.T, d *WebhookDispatcher, repo *mocks.MockWebhookRepository, subscription *domain.WebhookSubscription) *domain.WebhookDelivery {
+	t.Helper()
+
+	var delivery *domain.WebhookDelivery
+	repo.On("ListActiveSubscriptions", mock.Anything, subscription.EntityID).Return([]*domain.WebhookSubscription{subscription}, nil)
+	repo.On("CreateDelivery", mock.Anything, mock.AnythingOfType("*domain.WebhookDelivery")).
+		Run(func(args mock.Arguments) { delivery = args.Get(1).(*domain.WebhookDelivery) }).
+		Return(nil)
+
+	d.Dispatch(context.Background(), subscription.EntityID, domain.WebhookEventEventCreated, testutil.NewTestEvent())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, d.Shutdown(ctx))
+	require.NotNil(t, delivery)
+	return delivery
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Vetor conhecido de HMAC-SHA256
+	assert.Equal(t,
+		"sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		SignWebhookPayload("key", []byte("The quick brown fox jumps over the lazy dog")),
+	)
+}
+
+func TestWebhookDispatcher_Dispatch_SignsPayload(t *testing.T) {
+	receiver := &webhookReceiver{}
+	d, repo := newTestWebhookDispatcher(receiver)
+	subscription := newTestWebhookSubscription()
+
+	delivery := dispatchAndWait(t, d, repo, subscription)
+	assert.True(t, delivery.Success)
+	assert.Equal(t, 1, delivery.Attempts)
+
+	require.Len(t, receiver.requests, 1)
+	req := receiver.requests[0]
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, SignWebhookPayload(subscription.Secret, receiver.bodies[0]), req.Header.Get(WebhookSignatureHeader))
+	assert.Equal(t, string(domain.WebhookEventEventCreated), req.Header.Get(WebhookEventHeader))
+	assert.NotEmpty(t, req.Header.Get(WebhookDeliveryHeader))
+}
+
+func TestWebhookDispatcher_Dispatch_RetriesServerErrors(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}}
+	d, repo := newTestWebhookDispatcher(receiver)
+
+	delivery := dispatchAndWait(t, d, repo, newTestWebhookSubscription())
+	assert.True(t, delivery.Success)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Nil(t, delivery.ErrorMessage)
+	assert.Len(t, receiver.requests, 3)
+
+	// Todas as tentativas levam a mesma entrega
+	assert.Equal(t, receiver.requests[0].Header.Get(WebhookDeliveryHeader), receiver.requests[2].Header.Get(WebhookDeliveryHeader))
+}
+
+func TestWebhookDispatcher_Dispatch_GivesUpAfterMaxAttempts(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{500, 500, 500, 500}}
+	d, repo := newTestWebhookDispatcher(receiver)
+
+	delivery := dispatchAndWait(t, d, repo, newTestWebhookSubscription())
+	assert.False(t, delivery.Success)
+	assert.Equal(t, 3, delivery.Attempts)
+	require.NotNil(t, delivery.StatusCode)
+	assert.Equal(t, http.StatusInternalServerError, *delivery.StatusCode)
+	assert.Len(t, receiver.requests, 3)
+}
+
+func TestWebhookDispatcher_Dispatch_DoesNotRetryClientErrors(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadRequest}}
+	d, repo := newTestWebhookDispatcher(receiver)
+
+	delivery := dispatchAndWait(t, d, repo, newTestWebhookSubscription())
+	assert.False(t, delivery.Success)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Len(t, receiver.requests, 1)
+}
+
+func TestWebhookDispatcher_Dispatch_RejectsPrivateTargets(t *testing.T) {
+	receiver := &webhookReceiver{}
+	d, repo := newTestWebhookDispatcher(receiver)
+	subscription := newTestWebhookSubscription()
+	subscription.URL = "https://169.254.169.254/latest/meta-data"
+
+	delivery := dispatchAndWait(t, d, repo, subscription)
+	assert.False(t, delivery.Success)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Empty(t, receiver.requests)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// WebhookService manages the outbound webhook subscriptions of an entity
+type WebhookService struct {
+	repo repository.WebhookRepository
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(repo repository.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// Create registers a webhook. The signing secret is only returned here. The URL must be
+// https and resolve only to public addresses.
+func (s *WebhookService) Create(ctx context.Context, entID uuid.UUID, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error) {
+	if err := validateWebhookURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	secret := ""
+	if req.Secret != nil {
+		secret = *req.Secret
+	} else {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	subscription := &domain.WebhookSubscription{
+		ID:       uuid.New(),
+		EntityID: entID,
+		URL:      req.URL,
+		Secret:   secret,
+		Events:   req.Events,
+		Active:   true,
+	}
+
+	if err := s.repo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	resp := dto.ToWebhookResponse(subscription)
+	resp.Secret = secret
+	return resp, nil
+}
+
+// List returns the webhooks of an entity
+func (s *WebhookService) List(ctx context.Context, entID uuid.UUID) ([]*dto.WebhookResponse, error) {
+	subscriptions, err := s.repo.ListSubscriptions(ctx, entID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.WebhookResponse, len(subscriptions))
+	for i, sub := range subscriptions {
+		responses[i] = dto.ToWebhookResponse(sub)
+	}
+	return responses, nil
+}
+
+// Delete removes a webhook
+func (s *WebhookService) Delete(ctx context.Context, entID, webhookID uuid.UUID) error {
+	return s.repo.DeleteSubscription(ctx, webhookID, entID)
+}
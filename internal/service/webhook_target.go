@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"event-coming/internal/domain"
+)
+
+// errWebhookTarget is returned when a webhook would reach a non-public address
+var errWebhookTarget = errors.New("webhook target address is not allowed")
+
+// cgnatBlock is the carrier-grade NAT range (RFC 6598), not covered by net.IP.IsPrivate
+var cgnatBlock = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP reports whether ip may receive webhooks: loopback, private, link-local
+// (including cloud metadata at 169.254.169.254), multicast and unspecified are not
+func isPublicIP(ip net.IP) bool {
+	switch {
+	case ip.IsLoopback(), ip.IsPrivate(), ip.IsUnspecified(),
+		ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast(),
+		ip.IsInterfaceLocalMulticast(), ip.IsMulticast():
+		return false
+	case ip.To4() != nil && (ip.To4()[0] == 0 || cgnatBlock.Contains(ip)):
+		return false
+	}
+	return true
+}
+
+// validateWebhookURL accepts only https URLs whose host resolves exclusively to public
+// addresses. The dialer of newWebhookHTTPClient checks again at send time, since DNS
+// may change after the webhook is saved.
+func validateWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook url", domain.ErrInvalidInput)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: webhook url must use https", domain.ErrInvalidInput)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: webhook url has no host", domain.ErrInvalidInput)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidInput, errWebhookTarget)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: webhook host does not resolve", domain.ErrInvalidInput)
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidInput, errWebhookTarget)
+		}
+	}
+	return nil
+}
+
+// newWebhookHTTPClient returns the client used for deliveries: it refuses to connect to
+// non-public addresses (checked on the resolved IP of every connection), ignores proxy
+// settings and does not follow redirects, so a 3xx is recorded as a failed delivery.
+func newWebhookHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return errWebhookTarget
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
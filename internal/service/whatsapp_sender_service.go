@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/repository"
+	"event-coming/internal/whatsapp"
+	"event-coming/pkg/secretbox"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// WhatsAppSenderService gerencia o número WhatsApp próprio de cada entidade e escolhe o
+// cliente usado em cada envio: o da entidade, se configurado, ou o global
+type WhatsAppSenderService struct {
+	senderRepo repository.WhatsAppSenderRepository
+	global     *whatsapp.Client // nil quando o número global não está configurado
+	config     *config.WhatsAppConfig
+	tokens     *secretbox.Box // nil sem chave de criptografia configurada
+	logger     *zap.Logger
+
+	// Circuit breakers dos remetentes próprios por phone_number_id: o cliente é recriado
+	// a cada envio, o breaker precisa sobreviver entre eles
+	breakersMu sync.Mutex
+	breakers   map[string]*whatsapp.CircuitBreaker
+}
+
+// NewWhatsAppSenderService cria um novo serviço de remetentes WhatsApp
+func NewWhatsAppSenderService(
+	senderRepo repository.WhatsAppSenderRepository,
+	global *whatsapp.Client,
+	cfg *config.WhatsAppConfig,
+	tokens *secretbox.Box,
+	logger *zap.Logger,
+) *WhatsAppSenderService {
+	return &WhatsAppSenderService{
+		senderRepo: senderRepo,
+		global:     global,
+		config:     cfg,
+		tokens:     tokens,
+		logger:     logger,
+		breakers:   make(map[string]*whatsapp.CircuitBreaker),
+	}
+}
+
+// Set configura (ou substitui) o remetente da entidade, guardando o token criptografado.
+// Sem chave de criptografia retorna domain.ErrEncryptionUnavailable
+func (s *WhatsAppSenderService) Set(ctx context.Context, entID uuid.UUID, req *dto.SetWhatsAppSenderRequest) (*dto.WhatsAppSenderResponse, error) {
+	if s.tokens == nil {
+		return nil, domain.ErrEncryptionUnavailable
+	}
+
+	sealed, err := s.tokens.Seal(req.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+
+	sender := &domain.WhatsAppSender{
+		EntityID:      entID,
+		PhoneNumberID: req.PhoneNumberID,
+		AccessToken:   sealed,
+	}
+	if err := s.senderRepo.Upsert(ctx, sender); err != nil {
+		return nil, fmt.Errorf("failed to save whatsapp sender: %w", err)
+	}
+
+	return s.Get(ctx, entID)
+}
+
+// Get retorna o remetente da entidade; domain.ErrNotFound se ela usa o número global
+func (s *WhatsAppSenderService) Get(ctx context.Context, entID uuid.UUID) (*dto.WhatsAppSenderResponse, error) {
+	sender, err := s.senderRepo.GetByEntity(ctx, entID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToWhatsAppSenderResponse(sender), nil
+}
+
+// Delete remove o remetente da entidade, que volta a enviar pelo número global
+func (s *WhatsAppSenderService) Delete(ctx context.Context, entID uuid.UUID) error {
+	return s.senderRepo.Delete(ctx, entID)
+}
+
+// ClientFor retorna o cliente que envia em nome da entidade. Sem remetente próprio usa o
+// global; sem nenhum dos dois retorna domain.ErrChannelUnavailable. Um remetente que não
+// pode ser descriptografado é um erro: enviar pelo número global trocaria o remetente
+func (s *WhatsAppSenderService) ClientFor(ctx context.Context, entID uuid.UUID) (*whatsapp.Client, error) {
+	sender, err := s.senderRepo.GetByEntity(ctx, entID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get whatsapp sender: %w", err)
+		}
+		if s.global == nil {
+			return nil, domain.ErrChannelUnavailable
+		}
+		return s.global, nil
+	}
+
+	if s.tokens == nil {
+		return nil, fmt.Errorf("entity %s has a whatsapp sender but no encryption key is configured: %w", entID, domain.ErrEncryptionUnavailable)
+	}
+	token, err := s.tokens.Open(sender.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt whatsapp sender token: %w", err)
+	}
+
+	cfg := *s.config
+	cfg.PhoneNumberID = sender.PhoneNumberID
+	cfg.AccessToken = token
+	return whatsapp.NewClientWithBreaker(&cfg, s.breakerFor(sender.PhoneNumberID)), nil
+}
+
+// breakerFor retorna o circuit breaker do número, criando-o no primeiro envio
+func (s *WhatsAppSenderService) breakerFor(phoneNumberID string) *whatsapp.CircuitBreaker {
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+
+	breaker, ok := s.breakers[phoneNumberID]
+	if !ok {
+		breaker = whatsapp.NewCircuitBreaker(s.config.BreakerThreshold, s.config.BreakerCooldown)
+		s.breakers[phoneNumberID] = breaker
+	}
+	return breaker
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/dto"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+	"event-coming/internal/whatsapp"
+	"event-coming/pkg/secretbox"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// whatsappRequest guarda o número remetente (path) e o token usados em um envio
+type whatsappRequest struct {
+	path          string
+	authorization string
+}
+
+// newTestWhatsAppServer simula a Cloud API e registra os envios recebidos
+func newTestWhatsAppServer(t *testing.T) (*config.WhatsAppConfig, *[]whatsappRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []whatsappRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, whatsappRequest{path: r.URL.Path, authorization: r.Header.Get("Authorization")})
+		mu.Unlock()
+		w.Write([]byte(`{"messages":[{"id":"wamid.test"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.WhatsAppConfig{
+		BaseURL:       server.URL,
+		APIVersion:    "v18.0",
+		PhoneNumberID: "global-number",
+		AccessToken:   "global-token",
+	}
+	return cfg, &requests
+}
+
+func newTestSecretBox(t *testing.T) *secretbox.Box {
+	t.Helper()
+	box, err := secretbox.New(bytes.Repeat([]byte{7}, secretbox.KeySize))
+	require.NoError(t, err)
+	return box
+}
+
+func TestWhatsAppSenderService_EntitySenderIsSelected(t *testing.T) {
+	ctx := context.Background()
+	cfg, requests := newTestWhatsAppServer(t)
+	box := newTestSecretBox(t)
+	senderRepo := new(mocks.MockWhatsAppSenderRepository)
+	senders := NewWhatsAppSenderService(senderRepo, whatsapp.NewClient(cfg), cfg, box, zap.NewNop())
+
+	sealed, err := box.Seal("entity-token")
+	require.NoError(t, err)
+	withSender := testutil.TestEntityID
+	withoutSender := uuid.New()
+	senderRepo.On("GetByEntity", ctx, withSender).
+		Return(&domain.WhatsAppSender{EntityID: withSender, PhoneNumberID: "entity-number", AccessToken: sealed}, nil)
+	senderRepo.On("GetByEntity", ctx, withoutSender).Return(nil, domain.ErrNotFound)
+
+	svc := NewNotificationService(senders, new(mocks.MockNotificationDeliveryRepository), new(mocks.MockEventRepository), new(mocks.MockParticipantRepository), new(mocks.MockParticipantGroupRepository), zap.NewNop())
+	require.NoError(t, svc.SendMessage(ctx, withSender, "5511999990000", "Olá"))
+	require.NoError(t, svc.SendMessage(ctx, withoutSender, "5511999990000", "Olá"))
+
+	require.Len(t, *requests, 2)
+	// A entidade com remetente próprio envia pelo seu número, com o token descriptografado
+	assert.Equal(t, "/v18.0/entity-number/messages", (*requests)[0].path)
+	assert.Equal(t, "Bearer entity-token", (*requests)[0].authorization)
+	// As demais usam o número global
+	assert.Equal(t, "/v18.0/global-number/messages", (*requests)[1].path)
+	assert.Equal(t, "Bearer global-token", (*requests)[1].authorization)
+}
+
+func TestWhatsAppSenderService_ClientFor_Unavailable(t *testing.T) {
+	ctx := context.Background()
+	cfg, _ := newTestWhatsAppServer(t)
+	senderRepo := new(mocks.MockWhatsAppSenderRepository)
+	senderRepo.On("GetByEntity", ctx, testutil.TestEntityID).
+		Return(&domain.WhatsAppSender{EntityID: testutil.TestEntityID, PhoneNumberID: "entity-number", AccessToken: "sealed"}, nil)
+	withoutSender := uuid.New()
+	senderRepo.On("GetByEntity", ctx, withoutSender).Return(nil, domain.ErrNotFound)
+
+	// Sem chave, o remetente da entidade não é trocado silenciosamente pelo global
+	senders := NewWhatsAppSenderService(senderRepo, whatsapp.NewClient(cfg), cfg, nil, zap.NewNop())
+	_, err := senders.ClientFor(ctx, testutil.TestEntityID)
+	assert.ErrorIs(t, err, domain.ErrEncryptionUnavailable)
+
+	// Sem remetente próprio nem global, o canal está indisponível
+	senders = NewWhatsAppSenderService(senderRepo, nil, cfg, nil, zap.NewNop())
+	_, err = senders.ClientFor(ctx, withoutSender)
+	assert.ErrorIs(t, err, domain.ErrChannelUnavailable)
+}
+
+func TestWhatsAppSenderService_Set_EncryptsToken(t *testing.T) {
+	ctx := context.Background()
+	box := newTestSecretBox(t)
+	senderRepo := new(mocks.MockWhatsAppSenderRepository)
+	senders := NewWhatsAppSenderService(senderRepo, nil, &config.WhatsAppConfig{}, box, zap.NewNop())
+
+	var stored *domain.WhatsAppSender
+	senderRepo.On("Upsert", ctx, mock.AnythingOfType("*domain.WhatsAppSender")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.WhatsAppSender) }).
+		Return(nil)
+	senderRepo.On("GetByEntity", ctx, testutil.TestEntityID).
+		Return(&domain.WhatsAppSender{EntityID: testutil.TestEntityID, PhoneNumberID: "entity-number"}, nil)
+
+	resp, err := senders.Set(ctx, testutil.TestEntityID, &dto.SetWhatsAppSenderRequest{PhoneNumberID: "entity-number", AccessToken: "entity-token"})
+	require.NoError(t, err)
+	assert.Equal(t, "entity-number", resp.PhoneNumberID)
+
+	require.NotNil(t, stored)
+	assert.Equal(t, "entity-number", stored.PhoneNumberID)
+	assert.NotEqual(t, "entity-token", stored.AccessToken)
+	token, err := box.Open(stored.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "entity-token", token)
+
+	// Sem chave de criptografia o token não é gravado
+	senders = NewWhatsAppSenderService(senderRepo, nil, &config.WhatsAppConfig{}, nil, zap.NewNop())
+	_, err = senders.Set(ctx, testutil.TestEntityID, &dto.SetWhatsAppSenderRequest{PhoneNumberID: "entity-number", AccessToken: "entity-token"})
+	assert.ErrorIs(t, err, domain.ErrEncryptionUnavailable)
+	senderRepo.AssertNumberOfCalls(t, "Upsert", 1)
+}
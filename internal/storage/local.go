@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps objects as files under a base directory
+type LocalStorage struct {
+	baseDir string
+}
+
+// NewLocalStorage creates a local filesystem storage, creating baseDir if needed
+func NewLocalStorage(baseDir string) (*LocalStorage, error) {
+	if baseDir == "" {
+		return nil, errors.New("local storage path is empty")
+	}
+	if err := os.MkdirAll(baseDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStorage{baseDir: filepath.Clean(baseDir)}, nil
+}
+
+// path resolves a key inside the base directory, rejecting keys that escape it
+func (s *LocalStorage) path(key string) (string, error) {
+	p := filepath.Join(s.baseDir, filepath.FromSlash(key))
+	if !strings.HasPrefix(p, s.baseDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return p, nil
+}
+
+// Put writes the object atomically (temp file + rename)
+func (s *LocalStorage) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op depois do rename
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
+
+// Get opens the object for reading
+func (s *LocalStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+// Delete removes the object; deleting a missing object is not an error
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
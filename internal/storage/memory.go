@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// MemoryStorage keeps objects in memory. Meant for development and tests:
+// contents are lost on restart and not shared between instances.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: make(map[string][]byte)}
+}
+
+// Put stores a copy of the object
+func (s *MemoryStorage) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.objects[key] = data
+	s.mu.Unlock()
+	return nil
+}
+
+// Get returns a reader over the object
+func (s *MemoryStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	data, ok := s.objects[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete removes the object; deleting a missing object is not an error
+func (s *MemoryStorage) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.objects, key)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"event-coming/internal/config"
+)
+
+// ErrObjectNotFound is returned when no object exists under the key
+var ErrObjectNotFound = errors.New("storage: object not found")
+
+// Storage stores file contents (event attachments) by key
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Drivers de storage suportados
+const (
+	DriverLocal  = "local"
+	DriverMemory = "memory"
+	DriverS3     = "s3"
+)
+
+// New creates the storage selected by cfg.Driver
+func New(cfg *config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case DriverLocal, "":
+		return NewLocalStorage(cfg.LocalPath)
+	case DriverMemory:
+		return NewMemoryStorage(), nil
+	case DriverS3:
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"event-coming/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStorageContract exercises the behavior every Storage backend must share
+func testStorageContract(t *testing.T, s Storage) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := s.Get(ctx, "events/missing")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	content := "agenda do evento"
+	require.NoError(t, s.Put(ctx, "events/1/agenda", strings.NewReader(content), int64(len(content)), "application/pdf"))
+
+	r, err := s.Get(ctx, "events/1/agenda")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, r.Close())
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	// Overwriting replaces the contents
+	require.NoError(t, s.Put(ctx, "events/1/agenda", strings.NewReader("v2"), 2, "application/pdf"))
+	r, err = s.Get(ctx, "events/1/agenda")
+	require.NoError(t, err)
+	data, _ = io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "v2", string(data))
+
+	require.NoError(t, s.Delete(ctx, "events/1/agenda"))
+	_, err = s.Get(ctx, "events/1/agenda")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	// Deleting a missing object is not an error
+	assert.NoError(t, s.Delete(ctx, "events/1/agenda"))
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorageContract(t, NewMemoryStorage())
+}
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	testStorageContract(t, s)
+}
+
+func TestLocalStorage_RejectsKeysOutsideBaseDir(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	err = s.Put(context.Background(), "../escape", strings.NewReader("x"), 1, "text/plain")
+	assert.Error(t, err)
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := New(&config.StorageConfig{Driver: "ftp"})
+	assert.Error(t, err)
+}
@@ -28,6 +28,14 @@ func (m *MockEventRepository) GetByID(ctx context.Context, id uuid.UUID, entityI
 	return args.Get(0).(*domain.Event), args.Error(1)
 }
 
+func (m *MockEventRepository) GetForUpdate(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.Event, error) {
+	args := m.Called(ctx, id, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Event), args.Error(1)
+}
+
 func (m *MockEventRepository) Update(ctx context.Context, id uuid.UUID, entityID uuid.UUID, input *domain.UpdateEventInput) error {
 	args := m.Called(ctx, id, entityID, input)
 	return args.Error(0)
@@ -54,11 +62,85 @@ func (m *MockEventRepository) ListByStatus(ctx context.Context, entityID uuid.UU
 	return args.Get(0).([]*domain.Event), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockEventRepository) ListByParticipantPhone(ctx context.Context, phoneNumber string, page, perPage int) ([]*domain.ParticipantEvent, int64, error) {
+	args := m.Called(ctx, phoneNumber, page, perPage)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.ParticipantEvent), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockEventRepository) ListActiveByParticipantPhone(ctx context.Context, phoneNumber string) ([]*domain.ParticipantEvent, error) {
+	args := m.Called(ctx, phoneNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ParticipantEvent), args.Error(1)
+}
+
+func (m *MockEventRepository) FindDuplicate(ctx context.Context, entityID uuid.UUID, name string, from, to time.Time) (*domain.Event, error) {
+	args := m.Called(ctx, entityID, name, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) GetByImportUID(ctx context.Context, entityID uuid.UUID, uid string) (*domain.Event, error) {
+	args := m.Called(ctx, entityID, uid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) ListReminderDigest(ctx context.Context, entityID uuid.UUID, phoneNumber string, from, to time.Time) ([]*domain.ParticipantEvent, error) {
+	args := m.Called(ctx, entityID, phoneNumber, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ParticipantEvent), args.Error(1)
+}
+
+func (m *MockEventRepository) Search(ctx context.Context, entityID uuid.UUID, query string, limit int) ([]*domain.Event, error) {
+	args := m.Called(ctx, entityID, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) ListEndedActive(ctx context.Context, endedBefore time.Time, limit int) ([]*domain.Event, error) {
+	args := m.Called(ctx, endedBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) ListPendingRedaction(ctx context.Context, endedBefore time.Time, limit int) ([]*domain.Event, error) {
+	args := m.Called(ctx, endedBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Event), args.Error(1)
+}
+
+func (m *MockEventRepository) MarkParticipantsRedacted(ctx context.Context, id uuid.UUID, entityID uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, entityID, at)
+	return args.Error(0)
+}
+
 func (m *MockEventRepository) CreateInstance(ctx context.Context, instance *domain.EventInstance) error {
 	args := m.Called(ctx, instance)
 	return args.Error(0)
 }
 
+func (m *MockEventRepository) UpdateInstance(ctx context.Context, instance *domain.EventInstance) error {
+	args := m.Called(ctx, instance)
+	return args.Error(0)
+}
+
 func (m *MockEventRepository) GetInstanceByID(ctx context.Context, id uuid.UUID, entityID uuid.UUID) (*domain.EventInstance, error) {
 	args := m.Called(ctx, id, entityID)
 	if args.Get(0) == nil {
@@ -119,6 +201,14 @@ func (m *MockParticipantRepository) ListByEventInstance(ctx context.Context, ins
 	return args.Get(0).([]*domain.Participant), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockParticipantRepository) ListByGroup(ctx context.Context, eventID, groupID, entityID uuid.UUID, page, perPage int) ([]*domain.Participant, int64, error) {
+	args := m.Called(ctx, eventID, groupID, entityID, page, perPage)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Participant), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockParticipantRepository) UpdateStatus(ctx context.Context, id uuid.UUID, entityID uuid.UUID, status domain.ParticipantStatus) error {
 	args := m.Called(ctx, id, entityID, status)
 	return args.Error(0)
@@ -132,6 +222,27 @@ func (m *MockParticipantRepository) GetByPhoneNumber(ctx context.Context, phoneN
 	return args.Get(0).(*domain.Participant), args.Error(1)
 }
 
+func (m *MockParticipantRepository) MarkReminderDigest(ctx context.Context, entityID uuid.UUID, ids []uuid.UUID) error {
+	args := m.Called(ctx, entityID, ids)
+	return args.Error(0)
+}
+
+func (m *MockParticipantRepository) ListAllByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.Participant, error) {
+	args := m.Called(ctx, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepository) ExistingPhoneNumbers(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID, phoneNumbers []string) ([]string, error) {
+	args := m.Called(ctx, eventID, entityID, phoneNumbers)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockParticipantRepository) GetActiveByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.Participant, error) {
 	args := m.Called(ctx, phoneNumber)
 	if args.Get(0) == nil {
@@ -140,6 +251,63 @@ func (m *MockParticipantRepository) GetActiveByPhoneNumber(ctx context.Context,
 	return args.Get(0).(*domain.Participant), args.Error(1)
 }
 
+func (m *MockParticipantRepository) MarkNoShows(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockParticipantRepository) CountByEventStatus(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (map[domain.ParticipantStatus]int, error) {
+	args := m.Called(ctx, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[domain.ParticipantStatus]int), args.Error(1)
+}
+
+func (m *MockParticipantRepository) ListMissingPhoneNumber(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Participant, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepository) SetPhoneNumber(ctx context.Context, id uuid.UUID, entityID uuid.UUID, phoneNumber string) error {
+	args := m.Called(ctx, id, entityID, phoneNumber)
+	return args.Error(0)
+}
+
+func (m *MockParticipantRepository) SetLocationConsent(ctx context.Context, id uuid.UUID, entityID uuid.UUID, consent bool) error {
+	args := m.Called(ctx, id, entityID, consent)
+	return args.Error(0)
+}
+
+func (m *MockParticipantRepository) SetDeclineReason(ctx context.Context, id uuid.UUID, entityID uuid.UUID, reason *string) error {
+	args := m.Called(ctx, id, entityID, reason)
+	return args.Error(0)
+}
+
+func (m *MockParticipantRepository) MarkInviteViewed(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
+	args := m.Called(ctx, id, entityID)
+	return args.Error(0)
+}
+
+func (m *MockParticipantRepository) RedactByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID, at time.Time) (int64, error) {
+	args := m.Called(ctx, eventID, entityID, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockParticipantRepository) Search(ctx context.Context, entityID uuid.UUID, query string, limit int) ([]*domain.Participant, error) {
+	args := m.Called(ctx, entityID, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Participant), args.Error(1)
+}
+
 // MockLocationRepository is a mock implementation of LocationRepository
 type MockLocationRepository struct {
 	mock.Mock
@@ -171,6 +339,19 @@ func (m *MockLocationRepository) GetLatestByEvent(ctx context.Context, eventID u
 	return args.Get(0).([]*domain.Location), args.Error(1)
 }
 
+func (m *MockLocationRepository) DeleteByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, eventID, entityID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockLocationRepository) GetFirstByEvent(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) ([]*domain.Location, error) {
+	args := m.Called(ctx, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Location), args.Error(1)
+}
+
 func (m *MockLocationRepository) GetHistory(ctx context.Context, participantID uuid.UUID, entityID uuid.UUID, from, to time.Time) ([]*domain.Location, error) {
 	args := m.Called(ctx, participantID, entityID, from, to)
 	if args.Get(0) == nil {
@@ -215,6 +396,14 @@ func (m *MockSchedulerRepository) ListPending(ctx context.Context, before time.T
 	return args.Get(0).([]*domain.Scheduler), args.Error(1)
 }
 
+func (m *MockSchedulerRepository) ListDueBetween(ctx context.Context, from, to time.Time, limit int) ([]*domain.DueScheduler, error) {
+	args := m.Called(ctx, from, to, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.DueScheduler), args.Error(1)
+}
+
 func (m *MockSchedulerRepository) MarkAsProcessed(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
 	args := m.Called(ctx, id, entityID)
 	return args.Error(0)
@@ -229,3 +418,31 @@ func (m *MockSchedulerRepository) IncrementRetries(ctx context.Context, id uuid.
 	args := m.Called(ctx, id, entityID)
 	return args.Error(0)
 }
+
+func (m *MockSchedulerRepository) ClaimPending(ctx context.Context, before time.Time, staleBefore time.Time, limit int) ([]*domain.Scheduler, error) {
+	args := m.Called(ctx, before, staleBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Scheduler), args.Error(1)
+}
+
+func (m *MockSchedulerRepository) Claim(ctx context.Context, id uuid.UUID, entityID uuid.UUID) error {
+	args := m.Called(ctx, id, entityID)
+	return args.Error(0)
+}
+
+func (m *MockSchedulerRepository) ReleaseClaims(ctx context.Context, ids []uuid.UUID) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockSchedulerRepository) UpdateMetadata(ctx context.Context, id uuid.UUID, entityID uuid.UUID, metadata map[string]interface{}) error {
+	args := m.Called(ctx, id, entityID, metadata)
+	return args.Error(0)
+}
+
+func (m *MockSchedulerRepository) Reschedule(ctx context.Context, id uuid.UUID, entityID uuid.UUID, scheduledAt time.Time) error {
+	args := m.Called(ctx, id, entityID, scheduledAt)
+	return args.Error(0)
+}
@@ -77,6 +77,24 @@ func (m *MockUserRepository) GetEntityUsers(ctx context.Context, entityID uuid.U
 	return args.Get(0).([]*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetEntityMembership(ctx context.Context, userID, entityID uuid.UUID) (*domain.UserEntity, error) {
+	args := m.Called(ctx, userID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserEntity), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateEntityRole(ctx context.Context, userID, entityID uuid.UUID, role domain.UserRole) error {
+	args := m.Called(ctx, userID, entityID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) LockEntityUsersByRole(ctx context.Context, entityID uuid.UUID, role domain.UserRole) (int64, error) {
+	args := m.Called(ctx, entityID, role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // MockRefreshTokenRepository is a mock implementation of RefreshTokenRepository
 type MockRefreshTokenRepository struct {
 	mock.Mock
@@ -110,9 +128,9 @@ func (m *MockRefreshTokenRepository) RevokeAllByUserID(ctx context.Context, user
 	return args.Error(0)
 }
 
-func (m *MockRefreshTokenRepository) DeleteExpired(ctx context.Context) error {
-	args := m.Called(ctx)
-	return args.Error(0)
+func (m *MockRefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 // MockPasswordResetTokenRepository is a mock implementation of PasswordResetTokenRepository
@@ -143,9 +161,9 @@ func (m *MockPasswordResetTokenRepository) DeleteByUserID(ctx context.Context, u
 	return args.Error(0)
 }
 
-func (m *MockPasswordResetTokenRepository) DeleteExpired(ctx context.Context) error {
-	args := m.Called(ctx)
-	return args.Error(0)
+func (m *MockPasswordResetTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 // MockEntityRepository is a mock implementation of EntityRepository
@@ -199,3 +217,317 @@ func (m *MockEntityRepository) GetByDocument(ctx context.Context, document strin
 	}
 	return args.Get(0).(*domain.Entity), args.Error(1)
 }
+
+func (m *MockEntityRepository) ListDescendants(ctx context.Context, rootID uuid.UUID, maxDepth int) ([]*domain.EntityDescendant, error) {
+	args := m.Called(ctx, rootID, maxDepth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.EntityDescendant), args.Error(1)
+}
+
+// MockNotificationDeliveryRepository is a mock implementation of NotificationDeliveryRepository
+type MockNotificationDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationDeliveryRepository) Create(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationDeliveryRepository) GetByProviderMessageID(ctx context.Context, providerMessageID string) (*domain.NotificationDelivery, error) {
+	args := m.Called(ctx, providerMessageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationDeliveryRepository) UpdateStatus(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationDeliveryRepository) RedactRecipients(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, eventID, entityID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationDeliveryRepository) CountByEventStatus(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (map[domain.DeliveryStatus]int64, error) {
+	args := m.Called(ctx, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[domain.DeliveryStatus]int64), args.Error(1)
+}
+
+// MockAuditLogRepository is a mock implementation of AuditLogRepository
+type MockAuditLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditLogRepository) List(ctx context.Context, query *domain.AuditLogQuery) ([]*domain.AuditLog, int64, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.AuditLog), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAuditLogRepository) RedactEventParticipants(ctx context.Context, eventID uuid.UUID, entityID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, eventID, entityID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockStatusHistoryRepository is a mock implementation of StatusHistoryRepository
+type MockStatusHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatusHistoryRepository) Create(ctx context.Context, history *domain.StatusHistory) error {
+	args := m.Called(ctx, history)
+	return args.Error(0)
+}
+
+func (m *MockStatusHistoryRepository) ListByResource(ctx context.Context, resourceType domain.StatusResourceType, resourceID uuid.UUID, page, perPage int) ([]*domain.StatusHistory, int64, error) {
+	args := m.Called(ctx, resourceType, resourceID, page, perPage)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.StatusHistory), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStatusHistoryRepository) ListByEntity(ctx context.Context, entityID uuid.UUID, resourceType *domain.StatusResourceType, page, perPage int) ([]*domain.StatusHistory, int64, error) {
+	args := m.Called(ctx, entityID, resourceType, page, perPage)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domain.StatusHistory), args.Get(1).(int64), args.Error(2)
+}
+
+// MockTransactor is a mock implementation of Transactor that runs fn directly
+type MockTransactor struct {
+	mock.Mock
+}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+// MockWebhookRepository is a mock implementation of WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListSubscriptions(ctx context.Context, entityID uuid.UUID) ([]*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListActiveSubscriptions(ctx context.Context, entityID uuid.UUID) ([]*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, id, entityID uuid.UUID) error {
+	args := m.Called(ctx, id, entityID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListByEntity(ctx context.Context, entityID uuid.UUID) ([]*domain.APIKey, error) {
+	args := m.Called(ctx, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id, entityID uuid.UUID) error {
+	args := m.Called(ctx, id, entityID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockAttachmentRepository is a mock implementation of AttachmentRepository
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) GetByID(ctx context.Context, id, eventID, entityID uuid.UUID) (*domain.Attachment, error) {
+	args := m.Called(ctx, id, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.Attachment, error) {
+	args := m.Called(ctx, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) Delete(ctx context.Context, id, entityID uuid.UUID) error {
+	args := m.Called(ctx, id, entityID)
+	return args.Error(0)
+}
+
+// MockParticipantGroupRepository is a mock implementation of ParticipantGroupRepository
+type MockParticipantGroupRepository struct {
+	mock.Mock
+}
+
+func (m *MockParticipantGroupRepository) Create(ctx context.Context, group *domain.ParticipantGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockParticipantGroupRepository) GetByID(ctx context.Context, id, eventID, entityID uuid.UUID) (*domain.ParticipantGroup, error) {
+	args := m.Called(ctx, id, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ParticipantGroup), args.Error(1)
+}
+
+func (m *MockParticipantGroupRepository) ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.ParticipantGroup, error) {
+	args := m.Called(ctx, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ParticipantGroup), args.Error(1)
+}
+
+func (m *MockParticipantGroupRepository) Update(ctx context.Context, group *domain.ParticipantGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockParticipantGroupRepository) Delete(ctx context.Context, id, entityID uuid.UUID) error {
+	args := m.Called(ctx, id, entityID)
+	return args.Error(0)
+}
+
+func (m *MockParticipantGroupRepository) AddMembers(ctx context.Context, groupID, entityID uuid.UUID, participantIDs []uuid.UUID) error {
+	args := m.Called(ctx, groupID, entityID, participantIDs)
+	return args.Error(0)
+}
+
+func (m *MockParticipantGroupRepository) RemoveMembers(ctx context.Context, groupID, entityID uuid.UUID, participantIDs []uuid.UUID) error {
+	args := m.Called(ctx, groupID, entityID, participantIDs)
+	return args.Error(0)
+}
+
+func (m *MockParticipantGroupRepository) ListMemberIDs(ctx context.Context, groupID, entityID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, groupID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+// MockWhatsAppSenderRepository is a mock implementation of WhatsAppSenderRepository
+type MockWhatsAppSenderRepository struct {
+	mock.Mock
+}
+
+func (m *MockWhatsAppSenderRepository) Upsert(ctx context.Context, sender *domain.WhatsAppSender) error {
+	args := m.Called(ctx, sender)
+	return args.Error(0)
+}
+
+func (m *MockWhatsAppSenderRepository) GetByEntity(ctx context.Context, entityID uuid.UUID) (*domain.WhatsAppSender, error) {
+	args := m.Called(ctx, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WhatsAppSender), args.Error(1)
+}
+
+func (m *MockWhatsAppSenderRepository) Delete(ctx context.Context, entityID uuid.UUID) error {
+	args := m.Called(ctx, entityID)
+	return args.Error(0)
+}
+
+// MockEventOrganizerRepository is a mock implementation of EventOrganizerRepository
+type MockEventOrganizerRepository struct {
+	mock.Mock
+}
+
+func (m *MockEventOrganizerRepository) Add(ctx context.Context, organizer *domain.EventOrganizer) error {
+	args := m.Called(ctx, organizer)
+	return args.Error(0)
+}
+
+func (m *MockEventOrganizerRepository) Remove(ctx context.Context, eventID, userID, entityID uuid.UUID) error {
+	args := m.Called(ctx, eventID, userID, entityID)
+	return args.Error(0)
+}
+
+func (m *MockEventOrganizerRepository) ListByEvent(ctx context.Context, eventID, entityID uuid.UUID) ([]*domain.EventOrganizer, error) {
+	args := m.Called(ctx, eventID, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.EventOrganizer), args.Error(1)
+}
+
+func (m *MockEventOrganizerRepository) IsOrganizer(ctx context.Context, eventID, userID, entityID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, eventID, userID, entityID)
+	return args.Bool(0), args.Error(1)
+}
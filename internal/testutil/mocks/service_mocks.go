@@ -60,6 +60,19 @@ func (m *MockAuthService) ResetPassword(ctx context.Context, req dto.ResetPasswo
 	return args.Get(0).(*dto.ResetPasswordResponse), args.Error(1)
 }
 
+func (m *MockAuthService) DeactivateUser(ctx context.Context, adminUserID, targetUserID uuid.UUID) error {
+	args := m.Called(ctx, adminUserID, targetUserID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) Impersonate(ctx context.Context, adminUserID, targetUserID uuid.UUID) (*dto.ImpersonateResponse, error) {
+	args := m.Called(ctx, adminUserID, targetUserID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImpersonateResponse), args.Error(1)
+}
+
 // MockEntityService is a mock implementation of EntityService
 type MockEntityService struct {
 	mock.Mock
@@ -284,6 +297,14 @@ func (m *MockSchedulerService) ProcessPendingTasks(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockSchedulerService) RunNow(ctx context.Context, id uuid.UUID, orgID uuid.UUID) (*domain.Scheduler, error) {
+	args := m.Called(ctx, id, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Scheduler), args.Error(1)
+}
+
 // MockETAService is a mock implementation of ETAService
 type MockETAService struct {
 	mock.Mock
@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"time"
+
+	"event-coming/internal/config"
+)
+
+const (
+	// Tempo máximo para escrever uma mensagem
+	defaultWriteWait = 10 * time.Second
+
+	// Tempo máximo para ler o próximo pong
+	defaultPongWait = 60 * time.Second
+
+	// Tamanho máximo da mensagem
+	defaultMaxMessageSize = 4096
+
+	// Tamanho padrão do buffer de envio por cliente
+	defaultSendBufferSize = 256
+)
+
+// Config define os parâmetros de heartbeat, limites e buffers do hub
+type Config struct {
+	// Tempo máximo para escrever uma mensagem
+	WriteWait time.Duration
+	// Tempo máximo sem pong antes de desconectar o cliente por inatividade
+	PongWait time.Duration
+	// Período de envio de pings (deve ser menor que PongWait)
+	PingPeriod time.Duration
+	// Tamanho máximo de uma mensagem recebida do cliente
+	MaxMessageSize int64
+	// Tamanho do buffer de envio por cliente
+	SendBufferSize int
+	// Política aplicada quando o buffer de envio está cheio
+	BackpressurePolicy BackpressurePolicy
+	// Quantidade de mensagens recentes reenviadas ao conectar
+	ReplaySize int
+}
+
+// DefaultConfig retorna a configuração padrão do hub
+func DefaultConfig() Config {
+	return Config{
+		WriteWait:          defaultWriteWait,
+		PongWait:           defaultPongWait,
+		PingPeriod:         (defaultPongWait * 9) / 10,
+		MaxMessageSize:     defaultMaxMessageSize,
+		SendBufferSize:     defaultSendBufferSize,
+		BackpressurePolicy: BackpressureDisconnect,
+	}
+}
+
+// NewConfig cria a configuração do hub a partir da configuração da aplicação,
+// usando os valores padrão para campos não informados
+func NewConfig(cfg *config.WebSocketConfig) Config {
+	c := DefaultConfig()
+
+	if cfg.WriteWait > 0 {
+		c.WriteWait = cfg.WriteWait
+	}
+	if cfg.PongWait > 0 {
+		c.PongWait = cfg.PongWait
+		c.PingPeriod = (cfg.PongWait * 9) / 10
+	}
+	if cfg.PingPeriod > 0 && cfg.PingPeriod < c.PongWait {
+		c.PingPeriod = cfg.PingPeriod
+	}
+	if cfg.MaxMessageSize > 0 {
+		c.MaxMessageSize = cfg.MaxMessageSize
+	}
+	if cfg.SendBufferSize > 0 {
+		c.SendBufferSize = cfg.SendBufferSize
+	}
+	if BackpressurePolicy(cfg.BackpressurePolicy) == BackpressureDropOldest {
+		c.BackpressurePolicy = BackpressureDropOldest
+	}
+
+	// O replay não pode exceder o buffer de envio do cliente
+	c.ReplaySize = cfg.ReplaySize
+	if c.ReplaySize > c.SendBufferSize {
+		c.ReplaySize = c.SendBufferSize
+	}
+
+	return c
+}
@@ -0,0 +1,47 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig_Defaults(t *testing.T) {
+	cfg := NewConfig(&config.WebSocketConfig{})
+
+	assert.Equal(t, DefaultConfig(), cfg)
+	assert.Equal(t, 10*time.Second, cfg.WriteWait)
+	assert.Equal(t, 60*time.Second, cfg.PongWait)
+	assert.Equal(t, 54*time.Second, cfg.PingPeriod)
+	assert.Equal(t, int64(4096), cfg.MaxMessageSize)
+}
+
+func TestNewConfig_Overrides(t *testing.T) {
+	cfg := NewConfig(&config.WebSocketConfig{
+		WriteWait:          5 * time.Second,
+		PongWait:           120 * time.Second,
+		MaxMessageSize:     8192,
+		SendBufferSize:     32,
+		BackpressurePolicy: string(BackpressureDropOldest),
+		ReplaySize:         100,
+	})
+
+	assert.Equal(t, 5*time.Second, cfg.WriteWait)
+	assert.Equal(t, 120*time.Second, cfg.PongWait)
+	assert.Equal(t, 108*time.Second, cfg.PingPeriod, "ping period follows pong wait")
+	assert.Equal(t, int64(8192), cfg.MaxMessageSize)
+	assert.Equal(t, 32, cfg.SendBufferSize)
+	assert.Equal(t, BackpressureDropOldest, cfg.BackpressurePolicy)
+	assert.Equal(t, 32, cfg.ReplaySize, "replay is bounded by the send buffer")
+}
+
+func TestNewConfig_PingPeriodMustBeShorterThanPongWait(t *testing.T) {
+	cfg := NewConfig(&config.WebSocketConfig{PongWait: 30 * time.Second, PingPeriod: 10 * time.Second})
+	assert.Equal(t, 10*time.Second, cfg.PingPeriod)
+
+	cfg = NewConfig(&config.WebSocketConfig{PongWait: 30 * time.Second, PingPeriod: time.Minute})
+	assert.Equal(t, 27*time.Second, cfg.PingPeriod)
+}
@@ -3,7 +3,11 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,18 +15,20 @@ import (
 	"go.uber.org/zap"
 )
 
-const (
-	// Tempo máximo para escrever uma mensagem
-	writeWait = 10 * time.Second
+// ErrSendBufferFull indica que o buffer de envio do cliente está cheio
+var ErrSendBufferFull = errors.New("client send buffer is full")
 
-	// Tempo máximo para ler o próximo pong
-	pongWait = 60 * time.Second
+// ErrClientClosed indica que o cliente já foi desconectado pelo hub
+var ErrClientClosed = errors.New("client is closed")
 
-	// Período de envio de pings (deve ser menor que pongWait)
-	pingPeriod = (pongWait * 9) / 10
+// BackpressurePolicy define o que fazer quando o buffer de envio de um cliente está cheio
+type BackpressurePolicy string
 
-	// Tamanho máximo da mensagem
-	maxMessageSize = 4096
+const (
+	// BackpressureDropOldest descarta a mensagem mais antiga da fila do cliente
+	BackpressureDropOldest BackpressurePolicy = "drop_oldest"
+	// BackpressureDisconnect desconecta o cliente lento
+	BackpressureDisconnect BackpressurePolicy = "disconnect"
 )
 
 // MessageType define o tipo de mensagem WebSocket
@@ -36,6 +42,7 @@ const (
 	MessageTypeEventUpdate      MessageType = "event_update"
 	MessageTypePing             MessageType = "ping"
 	MessageTypePong             MessageType = "pong"
+	MessageTypeError            MessageType = "error"
 )
 
 // Message representa uma mensagem WebSocket
@@ -55,50 +62,164 @@ type LocationUpdateData struct {
 	Distance        *float64 `json:"distance_meters,omitempty"`
 }
 
+// ETAUpdateData representa o ETA recalculado de um participante
+type ETAUpdateData struct {
+	ParticipantID  string  `json:"participant_id"`
+	ETAMinutes     int     `json:"eta_minutes"`
+	DistanceMeters float64 `json:"distance_meters"`
+}
+
+// EventUpdateData representa os totais de participantes do evento por status, publicados
+// a cada mudança de status para dashboards
+type EventUpdateData struct {
+	Total     int `json:"total"`
+	Confirmed int `json:"confirmed"` // Inclui quem já fez check-in
+	CheckedIn int `json:"checked_in"`
+	Pending   int `json:"pending"`
+	Denied    int `json:"denied"`
+	NoShow    int `json:"no_show"`
+	// Só com capacidade definida no evento; Remaining nunca é negativo
+	Capacity  *int `json:"capacity,omitempty"`
+	Remaining *int `json:"remaining,omitempty"`
+}
+
+// InboundLocationData representa uma localização enviada pelo cliente
+type InboundLocationData struct {
+	ParticipantID string     `json:"participant_id,omitempty"`
+	Latitude      float64    `json:"latitude"`
+	Longitude     float64    `json:"longitude"`
+	Accuracy      *float64   `json:"accuracy,omitempty"`
+	Altitude      *float64   `json:"altitude,omitempty"`
+	Speed         *float64   `json:"speed,omitempty"`
+	Heading       *float64   `json:"heading,omitempty"`
+	Timestamp     *time.Time `json:"timestamp,omitempty"`
+}
+
+// ErrorData representa um erro enviado ao cliente
+type ErrorData struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// InboundHandler processa mensagens enviadas pelos clientes
+type InboundHandler interface {
+	HandleLocation(ctx context.Context, client *Client, data *InboundLocationData) error
+}
+
+// InboundError é um erro do InboundHandler que pode ser mostrado ao cliente. Qualquer
+// outro erro chega ao cliente só como uma mensagem genérica
+type InboundError struct {
+	Code    string
+	Message string
+}
+
+func (e *InboundError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// ChannelListener é notificado quando um evento ganha o primeiro cliente local
+// ou perde o último
+type ChannelListener interface {
+	OnChannelActive(entityID, eventID string)
+	OnChannelIdle(entityID, eventID string)
+}
+
+// channelEvent é a ativação ou desativação de um canal, a entregar ao ChannelListener
+type channelEvent struct {
+	entityID string
+	eventID  string
+	active   bool
+}
+
+// PresenceData representa dados de entrada/saída de um usuário no evento
+type PresenceData struct {
+	UserID   string    `json:"user_id"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// PresenceInfo descreve um usuário conectado a um evento
+type PresenceInfo struct {
+	UserID      string    `json:"user_id"`
+	JoinedAt    time.Time `json:"joined_at"`
+	Connections int       `json:"connections"`
+}
+
 // Client representa uma conexão WebSocket
 type Client struct {
 	ID             string
 	EntityID string
 	EventID        string
 	UserID         string
+	ParticipantID  string
 	conn           *websocket.Conn
 	send           chan []byte
 	hub            *Hub
 	logger         *zap.Logger
+
+	// Marcado pelo hub (com h.mu travado) ao fechar send; SendTo não envia depois disso
+	closed bool
 }
 
 // NewClient cria um novo cliente WebSocket
-func NewClient(conn *websocket.Conn, hub *Hub, entityID, eventID, userID string, logger *zap.Logger) *Client {
+// participantID é opcional e identifica o participante autorizado a enviar localizações
+func NewClient(conn *websocket.Conn, hub *Hub, entityID, eventID, userID, participantID string, logger *zap.Logger) *Client {
 	return &Client{
 		ID:             uuid.New().String(),
 		EntityID: entityID,
 		EventID:        eventID,
 		UserID:         userID,
+		ParticipantID:  participantID,
 		conn:           conn,
-		send:           make(chan []byte, 256),
+		send:           make(chan []byte, hub.cfg.SendBufferSize),
 		hub:            hub,
 		logger:         logger,
 	}
 }
 
+// NewStreamClient cria um cliente sem conexão WebSocket, para o stream SSE: recebe o mesmo
+// fan-out do hub, mas quem escreve as mensagens na resposta HTTP é o handler (Messages).
+// Não envia localizações
+func NewStreamClient(hub *Hub, entityID, eventID, userID string, logger *zap.Logger) *Client {
+	return NewClient(nil, hub, entityID, eventID, userID, "", logger)
+}
+
+// Messages retorna as mensagens destinadas ao cliente; o canal é fechado quando o hub o
+// desconecta (backpressure, CloseStreams ou Drain)
+func (c *Client) Messages() <-chan []byte {
+	return c.send
+}
+
+// isStream indica se o cliente é um stream SSE, sem conexão WebSocket
+func (c *Client) isStream() bool {
+	return c.conn == nil
+}
+
 // ReadPump lê mensagens do WebSocket
 func (c *Client) ReadPump() {
 	defer func() {
-		c.hub.unregister <- c
+		c.hub.Unregister(c)
 		c.conn.Close()
 	}()
 
-	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	cfg := c.hub.cfg
+	c.conn.SetReadLimit(cfg.MaxMessageSize)
+	// Sem pong dentro de PongWait a leitura expira e o cliente é desconectado por inatividade
+	c.conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
 	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
 		return nil
 	})
 
 	for {
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				c.logger.Info("WebSocket client idle timeout",
+					zap.String("client_id", c.ID),
+					zap.Duration("pong_wait", cfg.PongWait),
+				)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				c.logger.Error("WebSocket read error", zap.Error(err))
 			}
 			break
@@ -111,22 +232,76 @@ func (c *Client) ReadPump() {
 			continue
 		}
 
-		// Responder ping com pong
-		if msg.Type == MessageTypePing {
-			pong := Message{
+		switch msg.Type {
+		case MessageTypePing:
+			// Responder ping com pong (pelo hub: send pode ter sido fechado por backpressure)
+			if err := c.hub.SendTo(c, &Message{
 				Type:      MessageTypePong,
 				Timestamp: time.Now(),
+			}); err != nil {
+				c.logger.Debug("Failed to send pong to client", zap.Error(err))
 			}
-			if data, err := json.Marshal(pong); err == nil {
-				c.send <- data
-			}
+
+		case MessageTypeLocationUpdate:
+			c.handleInboundLocation(msg.Data)
+
+		default:
+			// Clientes só podem enviar ping e location_update
+			c.sendError("message_not_allowed", "Message type not allowed")
+		}
+	}
+}
+
+// handleInboundLocation valida e repassa uma localização enviada pelo cliente
+func (c *Client) handleInboundLocation(raw json.RawMessage) {
+	if c.hub.inbound == nil {
+		c.sendError("message_not_allowed", "Message type not allowed")
+		return
+	}
+
+	var data InboundLocationData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		c.sendError("invalid_payload", "Invalid location payload")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.hub.cfg.WriteWait)
+	defer cancel()
+
+	if err := c.hub.inbound.HandleLocation(ctx, c, &data); err != nil {
+		c.logger.Warn("Inbound location rejected",
+			zap.String("client_id", c.ID),
+			zap.String("participant_id", c.ParticipantID),
+			zap.Error(err),
+		)
+		var inboundErr *InboundError
+		if errors.As(err, &inboundErr) {
+			c.sendError(inboundErr.Code, inboundErr.Message)
+			return
 		}
+		c.sendError("location_rejected", "Location could not be processed")
+	}
+}
+
+// sendError envia uma mensagem de erro apenas para este cliente
+func (c *Client) sendError(code, message string) {
+	payload, err := json.Marshal(&ErrorData{Code: code, Message: message})
+	if err != nil {
+		return
+	}
+	if err := c.hub.SendTo(c, &Message{
+		Type:      MessageTypeError,
+		Timestamp: time.Now(),
+		Data:      payload,
+	}); err != nil {
+		c.logger.Debug("Failed to send error to client", zap.Error(err))
 	}
 }
 
 // WritePump envia mensagens para o WebSocket
 func (c *Client) WritePump() {
-	ticker := time.NewTicker(pingPeriod)
+	cfg := c.hub.cfg
+	ticker := time.NewTicker(cfg.PingPeriod)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
@@ -135,7 +310,7 @@ func (c *Client) WritePump() {
 	for {
 		select {
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
 			if !ok {
 				// Hub fechou o canal
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
@@ -153,7 +328,7 @@ func (c *Client) WritePump() {
 			}
 
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
@@ -164,12 +339,35 @@ func (c *Client) WritePump() {
 // Hub gerencia todas as conexões WebSocket
 type Hub struct {
 	// Clientes registrados por evento (org:event -> clients)
-	clients    map[string]map[*Client]bool
+	clients map[string]map[*Client]bool
+	// Presença por evento (org:event -> userID -> info)
+	presence   map[string]map[string]*PresenceInfo
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan *BroadcastMessage
-	mu         sync.RWMutex
-	logger     *zap.Logger
+	// Fechado quando Run termina; a partir daí ninguém lê register/unregister
+	done   chan struct{}
+	mu     sync.RWMutex
+	logger *zap.Logger
+
+	cfg      Config
+	inbound  InboundHandler
+	listener ChannelListener
+
+	// Ativações/desativações geradas com h.mu travado; notifyChannels as entrega ao
+	// listener depois de destravar, na ordem em que ocorreram (notifyMu serializa a entrega)
+	channelEvents []channelEvent
+	notifyMu      sync.Mutex
+
+	// Métricas de backpressure
+	droppedMessages     atomic.Int64
+	disconnectedClients atomic.Int64
+}
+
+// HubStats contém as métricas de backpressure do hub
+type HubStats struct {
+	DroppedMessages     int64 `json:"dropped_messages"`
+	DisconnectedClients int64 `json:"disconnected_clients"`
 }
 
 // BroadcastMessage representa uma mensagem para broadcast
@@ -180,18 +378,23 @@ type BroadcastMessage struct {
 }
 
 // NewHub cria um novo hub
-func NewHub(logger *zap.Logger) *Hub {
+func NewHub(cfg Config, logger *zap.Logger) *Hub {
 	return &Hub{
+		cfg:        cfg,
 		clients:    make(map[string]map[*Client]bool),
+		presence:   make(map[string]map[string]*PresenceInfo),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast:  make(chan *BroadcastMessage, 256),
+		done:       make(chan struct{}),
 		logger:     logger,
 	}
 }
 
 // Run inicia o loop principal do hub
 func (h *Hub) Run(ctx context.Context) {
+	defer close(h.done)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -217,13 +420,14 @@ func getChannelKey(entityID, eventID string) string {
 
 func (h *Hub) addClient(client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
 
 	key := getChannelKey(client.EntityID, client.EventID)
 	if h.clients[key] == nil {
 		h.clients[key] = make(map[*Client]bool)
+		h.queueChannelEvent(client, true)
 	}
 	h.clients[key][client] = true
+	joined := h.trackJoin(key, client)
 
 	h.logger.Info("Client connected",
 		zap.String("client_id", client.ID),
@@ -231,51 +435,284 @@ func (h *Hub) addClient(client *Client) {
 		zap.String("event_id", client.EventID),
 		zap.Int("total_clients", len(h.clients[key])),
 	)
+	h.mu.Unlock()
+	h.notifyChannels()
+
+	if joined != nil {
+		h.publishPresence(client.EntityID, client.EventID, MessageTypeParticipantJoin, joined)
+	}
 }
 
 func (h *Hub) removeClient(client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
+	left := h.detachClient(client)
+	h.mu.Unlock()
+	h.notifyChannels()
+
+	if left != nil {
+		h.publishPresence(client.EntityID, client.EventID, MessageTypeParticipantLeave, left)
+	}
+}
 
+// detachClient remove o cliente do hub e atualiza a presença.
+// Deve ser chamado com h.mu travado. Retorna os dados de saída quando
+// a última conexão do usuário no evento foi encerrada.
+func (h *Hub) detachClient(client *Client) *PresenceData {
 	key := getChannelKey(client.EntityID, client.EventID)
-	if clients, ok := h.clients[key]; ok {
-		if _, exists := clients[client]; exists {
-			delete(clients, client)
-			close(client.send)
+	clients, ok := h.clients[key]
+	if !ok {
+		return nil
+	}
+	if _, exists := clients[client]; !exists {
+		return nil
+	}
 
-			h.logger.Info("Client disconnected",
-				zap.String("client_id", client.ID),
-				zap.String("org_id", client.EntityID),
-				zap.String("event_id", client.EventID),
-				zap.Int("remaining_clients", len(clients)),
-			)
+	delete(clients, client)
+	client.closed = true
+	close(client.send)
 
-			// Remove o canal se não há mais clientes
-			if len(clients) == 0 {
-				delete(h.clients, key)
-			}
+	h.logger.Info("Client disconnected",
+		zap.String("client_id", client.ID),
+		zap.String("org_id", client.EntityID),
+		zap.String("event_id", client.EventID),
+		zap.Int("remaining_clients", len(clients)),
+	)
+
+	// Remove o canal se não há mais clientes
+	if len(clients) == 0 {
+		delete(h.clients, key)
+		h.queueChannelEvent(client, false)
+	}
+
+	return h.trackLeave(key, client)
+}
+
+// queueChannelEvent guarda a ativação/desativação do canal do cliente para notifyChannels.
+// Deve ser chamado com h.mu travado
+func (h *Hub) queueChannelEvent(client *Client, active bool) {
+	if h.listener == nil {
+		return
+	}
+	h.channelEvents = append(h.channelEvents, channelEvent{
+		entityID: client.EntityID,
+		eventID:  client.EventID,
+		active:   active,
+	})
+}
+
+// notifyChannels entrega ao listener as ativações/desativações pendentes. Chamado sem
+// h.mu travado: o listener pode demorar sem segurar SendTo, Broadcast e o loop do hub
+func (h *Hub) notifyChannels() {
+	if h.listener == nil {
+		return
+	}
+
+	h.notifyMu.Lock()
+	defer h.notifyMu.Unlock()
+
+	h.mu.Lock()
+	events := h.channelEvents
+	h.channelEvents = nil
+	h.mu.Unlock()
+
+	for _, ev := range events {
+		if ev.active {
+			h.listener.OnChannelActive(ev.entityID, ev.eventID)
+		} else {
+			h.listener.OnChannelIdle(ev.entityID, ev.eventID)
 		}
 	}
 }
 
+// trackJoin registra a presença do usuário do cliente.
+// Retorna os dados de entrada apenas na primeira conexão do usuário.
+func (h *Hub) trackJoin(key string, client *Client) *PresenceData {
+	if client.UserID == "" {
+		return nil
+	}
+
+	users := h.presence[key]
+	if users == nil {
+		users = make(map[string]*PresenceInfo)
+		h.presence[key] = users
+	}
+
+	if info, ok := users[client.UserID]; ok {
+		info.Connections++
+		return nil
+	}
+
+	info := &PresenceInfo{
+		UserID:      client.UserID,
+		JoinedAt:    time.Now(),
+		Connections: 1,
+	}
+	users[client.UserID] = info
+
+	return &PresenceData{UserID: info.UserID, JoinedAt: info.JoinedAt}
+}
+
+// trackLeave remove uma conexão do usuário da presença.
+// Retorna os dados de saída quando não restam conexões do usuário.
+func (h *Hub) trackLeave(key string, client *Client) *PresenceData {
+	users, ok := h.presence[key]
+	if !ok {
+		return nil
+	}
+	info, ok := users[client.UserID]
+	if !ok {
+		return nil
+	}
+
+	info.Connections--
+	if info.Connections > 0 {
+		return nil
+	}
+
+	delete(users, client.UserID)
+	if len(users) == 0 {
+		delete(h.presence, key)
+	}
+
+	return &PresenceData{UserID: info.UserID, JoinedAt: info.JoinedAt}
+}
+
+// publishPresence envia uma mensagem de entrada/saída para os clientes do evento.
+// Chamado a partir do loop do hub, por isso entrega direto sem passar pelo canal de broadcast.
+func (h *Hub) publishPresence(entityID, eventID string, msgType MessageType, data *PresenceData) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		h.logger.Warn("Failed to marshal presence data", zap.Error(err))
+		return
+	}
+
+	msg, err := json.Marshal(&Message{
+		Type:      msgType,
+		Timestamp: time.Now(),
+		Data:      payload,
+	})
+	if err != nil {
+		h.logger.Warn("Failed to marshal presence message", zap.Error(err))
+		return
+	}
+
+	h.broadcastToEvent(&BroadcastMessage{
+		EntityID: entityID,
+		EventID:  eventID,
+		Message:  msg,
+	})
+}
+
 func (h *Hub) broadcastToEvent(msg *BroadcastMessage) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	h.mu.Lock()
 
 	key := getChannelKey(msg.EntityID, msg.EventID)
 	clients, ok := h.clients[key]
 	if !ok {
+		h.mu.Unlock()
 		return
 	}
 
+	var left []*PresenceData
 	for client := range clients {
-		select {
-		case client.send <- msg.Message:
-		default:
-			// Buffer cheio, fecha a conexão
-			close(client.send)
-			delete(clients, client)
+		if h.trySend(client, msg.Message) {
+			continue
 		}
+
+		// Buffer cheio e política de desconexão: fecha a conexão
+		h.disconnectedClients.Add(1)
+		h.logger.Warn("Disconnecting slow WebSocket client",
+			zap.String("client_id", client.ID),
+			zap.String("event_id", client.EventID),
+		)
+		if data := h.detachClient(client); data != nil {
+			left = append(left, data)
+		}
+	}
+	h.mu.Unlock()
+	h.notifyChannels()
+
+	for _, data := range left {
+		h.publishPresence(msg.EntityID, msg.EventID, MessageTypeParticipantLeave, data)
+	}
+}
+
+// trySend envia a mensagem sem bloquear, aplicando a política de backpressure.
+// Retorna false quando o cliente deve ser desconectado.
+func (h *Hub) trySend(client *Client, message []byte) bool {
+	select {
+	case client.send <- message:
+		return true
+	default:
+	}
+
+	if h.cfg.BackpressurePolicy != BackpressureDropOldest {
+		return false
+	}
+
+	// Descarta a mensagem mais antiga e tenta novamente
+	select {
+	case <-client.send:
+		h.droppedMessages.Add(1)
+	default:
+	}
+
+	select {
+	case client.send <- message:
+		return true
+	default:
+		h.droppedMessages.Add(1)
+		return true
+	}
+}
+
+// SetInboundHandler define quem processa mensagens enviadas pelos clientes.
+// Deve ser chamado antes de aceitar conexões.
+func (h *Hub) SetInboundHandler(handler InboundHandler) {
+	h.inbound = handler
+}
+
+// SetChannelListener define quem é notificado sobre canais ativos/ociosos
+func (h *Hub) SetChannelListener(listener ChannelListener) {
+	h.listener = listener
+}
+
+// SendTo envia uma mensagem apenas para um cliente, sem broadcast e sem bloquear.
+// Usado para o replay do histórico antes do cliente ser registrado e para respostas
+// ao próprio cliente (pong, erros). Cliente já desconectado retorna ErrClientClosed.
+func (h *Hub) SendTo(client *Client, msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	// O hub fecha send com h.mu travado; o RLock impede enviar num canal fechado
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if client.closed {
+		return ErrClientClosed
+	}
+
+	select {
+	case client.send <- data:
+		return nil
+	default:
+		h.droppedMessages.Add(1)
+		return ErrSendBufferFull
+	}
+}
+
+// Config retorna a configuração do hub (intervalos de ping e prazo de escrita, usados
+// também pelo stream SSE)
+func (h *Hub) Config() Config {
+	return h.cfg
+}
+
+// GetStats retorna as métricas de backpressure do hub
+func (h *Hub) GetStats() HubStats {
+	return HubStats{
+		DroppedMessages:     h.droppedMessages.Load(),
+		DisconnectedClients: h.disconnectedClients.Load(),
 	}
 }
 
@@ -307,7 +744,124 @@ func (h *Hub) GetClientCount(entityID, eventID string) int {
 	return 0
 }
 
-// Register registra um cliente
+// GetPresence retorna os usuários conectados a um evento
+func (h *Hub) GetPresence(entityID, eventID string) []PresenceInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	users := h.presence[getChannelKey(entityID, eventID)]
+	presence := make([]PresenceInfo, 0, len(users))
+	for _, info := range users {
+		presence = append(presence, *info)
+	}
+
+	sort.Slice(presence, func(i, j int) bool {
+		return presence[i].JoinedAt.Before(presence[j].JoinedAt)
+	})
+
+	return presence
+}
+
+// Drain envia um close frame ("server restarting") a todos os clientes e aguarda,
+// até o limite do contexto, que eles se desconectem. Clientes restantes são
+// fechados à força quando o contexto expira. Com o loop do hub (Run) já parado, os
+// clientes que se desconectam são removidos direto pelo Unregister.
+func (h *Hub) Drain(ctx context.Context) error {
+	clients := h.snapshotClients()
+	if len(clients) == 0 {
+		return nil
+	}
+
+	h.logger.Info("Draining WebSocket clients", zap.Int("clients", len(clients)))
+
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server restarting")
+	deadline := time.Now().Add(h.cfg.WriteWait)
+	for _, client := range clients {
+		// Stream SSE não tem close frame: desconectar encerra a resposta
+		if client.isStream() {
+			h.Unregister(client)
+			continue
+		}
+		if err := client.conn.WriteControl(websocket.CloseMessage, closeMsg, deadline); err != nil {
+			h.logger.Debug("Failed to send close frame",
+				zap.String("client_id", client.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if len(h.snapshotClients()) == 0 {
+			h.logger.Info("WebSocket clients drained")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			remaining := h.snapshotClients()
+			h.logger.Warn("Drain timeout, closing remaining WebSocket clients",
+				zap.Int("remaining", len(remaining)),
+			)
+			for _, client := range remaining {
+				if !client.isStream() {
+					client.conn.Close()
+				}
+			}
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshotClients retorna todos os clientes conectados
+func (h *Hub) snapshotClients() []*Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var clients []*Client
+	for _, eventClients := range h.clients {
+		for client := range eventClients {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// Register registra um cliente. Com o hub parado o cliente não é registrado e seu canal
+// de envio é fechado, encerrando a conexão em vez de deixá-la esperando
 func (h *Hub) Register(client *Client) {
-	h.register <- client
+	select {
+	case h.register <- client:
+	case <-h.done:
+		h.mu.Lock()
+		if !client.closed {
+			client.closed = true
+			close(client.send)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// Unregister desconecta um cliente; cliente já desconectado é ignorado. Com o hub parado
+// o cliente é removido direto, sem esperar pelo loop (Run) que já terminou
+func (h *Hub) Unregister(client *Client) {
+	select {
+	case h.unregister <- client:
+	case <-h.done:
+		h.removeClient(client)
+	}
+}
+
+// CloseStreams desconecta os clientes SSE, encerrando suas respostas para que o shutdown
+// do servidor HTTP não fique esperando por elas. Os clientes WebSocket não são afetados
+func (h *Hub) CloseStreams() {
+	for _, client := range h.snapshotClients() {
+		if client.isStream() {
+			h.Unregister(client)
+		}
+	}
 }
@@ -0,0 +1,426 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testEntityID = "22222222-2222-2222-2222-222222222222"
+	testEventID  = "33333333-3333-3333-3333-333333333333"
+)
+
+func newTestHub(cfg config.WebSocketConfig) *Hub {
+	return NewHub(NewConfig(&cfg), zap.NewNop())
+}
+
+func newTestClient(hub *Hub, userID string) *Client {
+	return NewClient(nil, hub, testEntityID, testEventID, userID, "", zap.NewNop())
+}
+
+// drainMessages lê as mensagens já enfileiradas para o cliente
+func drainMessages(t *testing.T, client *Client) []Message {
+	t.Helper()
+
+	var messages []Message
+	for {
+		select {
+		case data, ok := <-client.send:
+			if !ok {
+				return messages
+			}
+			var msg Message
+			require.NoError(t, json.Unmarshal(data, &msg))
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+func presenceUsers(hub *Hub) []string {
+	var users []string
+	for _, info := range hub.GetPresence(testEntityID, testEventID) {
+		users = append(users, info.UserID)
+	}
+	return users
+}
+
+func TestHub_PresenceJoinAndLeave(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{})
+
+	watcher := newTestClient(hub, "watcher")
+	hub.addClient(watcher)
+	drainMessages(t, watcher)
+
+	alice := newTestClient(hub, "alice")
+	hub.addClient(alice)
+
+	messages := drainMessages(t, watcher)
+	require.Len(t, messages, 1)
+	assert.Equal(t, MessageTypeParticipantJoin, messages[0].Type)
+	var joined PresenceData
+	require.NoError(t, json.Unmarshal(messages[0].Data, &joined))
+	assert.Equal(t, "alice", joined.UserID)
+
+	assert.ElementsMatch(t, []string{"watcher", "alice"}, presenceUsers(hub))
+
+	hub.removeClient(alice)
+
+	messages = drainMessages(t, watcher)
+	require.Len(t, messages, 1)
+	assert.Equal(t, MessageTypeParticipantLeave, messages[0].Type)
+
+	assert.Equal(t, []string{"watcher"}, presenceUsers(hub))
+}
+
+func TestHub_PresenceCountsConnectionsPerUser(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{})
+
+	watcher := newTestClient(hub, "watcher")
+	hub.addClient(watcher)
+
+	first := newTestClient(hub, "alice")
+	second := newTestClient(hub, "alice")
+	hub.addClient(first)
+	hub.addClient(second)
+	drainMessages(t, watcher)
+
+	for _, info := range hub.GetPresence(testEntityID, testEventID) {
+		if info.UserID == "alice" {
+			assert.Equal(t, 2, info.Connections)
+		}
+	}
+
+	// A primeira conexão fechada não tira o usuário da presença
+	hub.removeClient(first)
+	assert.Empty(t, drainMessages(t, watcher))
+	assert.Len(t, hub.GetPresence(testEntityID, testEventID), 2)
+
+	hub.removeClient(second)
+	messages := drainMessages(t, watcher)
+	require.Len(t, messages, 1)
+	assert.Equal(t, MessageTypeParticipantLeave, messages[0].Type)
+	assert.Len(t, hub.GetPresence(testEntityID, testEventID), 1)
+}
+
+func TestHub_PresenceCleanedUpWhenLastClientLeaves(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{})
+
+	client := newTestClient(hub, "alice")
+	hub.addClient(client)
+	hub.removeClient(client)
+
+	assert.Empty(t, hub.GetPresence(testEntityID, testEventID))
+	assert.Equal(t, 0, hub.GetClientCount(testEntityID, testEventID))
+	assert.Empty(t, hub.presence)
+	assert.Empty(t, hub.clients)
+
+	// Um segundo unregister do mesmo cliente é ignorado
+	hub.removeClient(client)
+	assert.Empty(t, hub.presence)
+}
+
+func TestHub_PresenceCleanedUpOnAbnormalDisconnect(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{})
+
+	watcher := newTestClient(hub, "watcher")
+	stuck := newTestClient(hub, "stuck")
+	hub.addClient(watcher)
+	hub.addClient(stuck)
+	drainMessages(t, watcher)
+
+	// Enche o buffer do cliente travado para que o próximo broadcast o derrube
+	for len(stuck.send) < cap(stuck.send) {
+		stuck.send <- []byte("{}")
+	}
+
+	hub.broadcastToEvent(&BroadcastMessage{EntityID: testEntityID, EventID: testEventID, Message: []byte(`{"type":"event_update"}`)})
+
+	presence := hub.GetPresence(testEntityID, testEventID)
+	require.Len(t, presence, 1)
+	assert.Equal(t, "watcher", presence[0].UserID)
+	assert.Equal(t, 1, hub.GetClientCount(testEntityID, testEventID))
+
+	messages := drainMessages(t, watcher)
+	require.Len(t, messages, 2)
+	assert.Equal(t, MessageTypeEventUpdate, messages[0].Type)
+	assert.Equal(t, MessageTypeParticipantLeave, messages[1].Type)
+}
+
+func TestHub_AnonymousClientsAreNotTracked(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{})
+
+	client := newTestClient(hub, "")
+	hub.addClient(client)
+
+	assert.Equal(t, 1, hub.GetClientCount(testEntityID, testEventID))
+	assert.Empty(t, hub.GetPresence(testEntityID, testEventID))
+}
+
+// fillSendBuffer enche o buffer de envio do cliente com mensagens numeradas
+func fillSendBuffer(client *Client) {
+	for i := 0; len(client.send) < cap(client.send); i++ {
+		client.send <- []byte(fmt.Sprintf(`{"type":"event_update","data":%d}`, i))
+	}
+}
+
+func TestHub_Backpressure_DisconnectPolicy(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{SendBufferSize: 4, BackpressurePolicy: string(BackpressureDisconnect)})
+
+	slow := newTestClient(hub, "slow")
+	fast := newTestClient(hub, "fast")
+	hub.addClient(slow)
+	hub.addClient(fast)
+	drainMessages(t, fast)
+	fillSendBuffer(slow)
+
+	hub.broadcastToEvent(&BroadcastMessage{EntityID: testEntityID, EventID: testEventID, Message: []byte(`{"type":"event_update"}`)})
+
+	assert.Equal(t, 1, hub.GetClientCount(testEntityID, testEventID))
+	assert.Equal(t, []string{"fast"}, presenceUsers(hub))
+	assert.Equal(t, HubStats{DisconnectedClients: 1}, hub.GetStats())
+
+	// O canal do cliente desconectado é fechado depois das mensagens pendentes
+	drainMessages(t, slow)
+	_, open := <-slow.send
+	assert.False(t, open)
+
+	assert.ErrorIs(t, hub.SendTo(slow, &Message{Type: MessageTypePong}), ErrClientClosed)
+}
+
+func TestHub_Backpressure_DropOldestPolicy(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{SendBufferSize: 4, BackpressurePolicy: string(BackpressureDropOldest)})
+
+	slow := newTestClient(hub, "slow")
+	hub.addClient(slow)
+	drainMessages(t, slow)
+	fillSendBuffer(slow)
+
+	hub.broadcastToEvent(&BroadcastMessage{EntityID: testEntityID, EventID: testEventID, Message: []byte(`{"type":"eta_update"}`)})
+
+	assert.Equal(t, 1, hub.GetClientCount(testEntityID, testEventID))
+	assert.Equal(t, HubStats{DroppedMessages: 1}, hub.GetStats())
+
+	messages := drainMessages(t, slow)
+	require.Len(t, messages, 4)
+	assert.JSONEq(t, "1", string(messages[0].Data), "the oldest message is dropped")
+	assert.Equal(t, MessageTypeETAUpdate, messages[3].Type)
+}
+
+func TestHub_SendTo_DoesNotBlockOnFullBuffer(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{SendBufferSize: 2})
+
+	client := newTestClient(hub, "alice")
+	hub.addClient(client)
+	fillSendBuffer(client)
+
+	assert.ErrorIs(t, hub.SendTo(client, &Message{Type: MessageTypePong}), ErrSendBufferFull)
+	assert.Equal(t, int64(1), hub.GetStats().DroppedMessages)
+}
+
+func TestNewHub_DefaultsUnknownPolicyToDisconnect(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{BackpressurePolicy: "unknown"})
+
+	assert.Equal(t, BackpressureDisconnect, hub.cfg.BackpressurePolicy)
+	assert.Equal(t, defaultSendBufferSize, hub.cfg.SendBufferSize)
+}
+
+// dialTestHub sobe um servidor que registra cada conexão no hub e conecta um cliente a ele
+func dialTestHub(t *testing.T, hub *Hub, userID string) *websocket.Conn {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := NewClient(conn, hub, testEntityID, testEventID, userID, "", zap.NewNop())
+		hub.Register(client)
+		go client.WritePump()
+		go client.ReadPump()
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.Eventually(t, func() bool {
+		return hub.GetClientCount(testEntityID, testEventID) > 0
+	}, time.Second, 10*time.Millisecond)
+	return conn
+}
+
+func TestHub_UnregistersClientMissingPongs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := DefaultConfig()
+	cfg.PongWait = 200 * time.Millisecond
+	cfg.PingPeriod = 50 * time.Millisecond
+	hub := NewHub(cfg, zap.NewNop())
+	go hub.Run(ctx)
+
+	// O cliente nunca lê, então os pings do servidor ficam sem pong
+	dialTestHub(t, hub, "idle")
+
+	require.Eventually(t, func() bool {
+		return hub.GetClientCount(testEntityID, testEventID) == 0
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Empty(t, hub.GetPresence(testEntityID, testEventID))
+}
+
+func TestHub_Drain_ClosesRegisteredClients(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := NewHub(DefaultConfig(), zap.NewNop())
+	go hub.Run(ctx)
+
+	conn := dialTestHub(t, hub, "alice")
+
+	// O cliente lê até receber o close frame e responde com o próprio close
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	drainCtx, drainCancel := context.WithTimeout(ctx, 2*time.Second)
+	defer drainCancel()
+	require.NoError(t, hub.Drain(drainCtx))
+
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, <-closed, &closeErr)
+	assert.Equal(t, websocket.CloseServiceRestart, closeErr.Code)
+	assert.Equal(t, "server restarting", closeErr.Text)
+	assert.Equal(t, 0, hub.GetClientCount(testEntityID, testEventID))
+}
+
+func TestHub_Drain_ForcesCloseAfterTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := NewHub(DefaultConfig(), zap.NewNop())
+	go hub.Run(ctx)
+
+	// O cliente nunca lê, então não responde ao close frame
+	dialTestHub(t, hub, "stuck")
+
+	drainCtx, drainCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer drainCancel()
+	assert.ErrorIs(t, hub.Drain(drainCtx), context.DeadlineExceeded)
+
+	require.Eventually(t, func() bool {
+		return hub.GetClientCount(testEntityID, testEventID) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHub_Drain_WithoutClients(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{})
+
+	assert.NoError(t, hub.Drain(context.Background()))
+}
+
+func TestHub_CloseStreams_DisconnectsOnlyStreamClients(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := NewHub(DefaultConfig(), zap.NewNop())
+	go hub.Run(ctx)
+
+	dialTestHub(t, hub, "alice")
+	stream := NewStreamClient(hub, testEntityID, testEventID, "bob", zap.NewNop())
+	hub.Register(stream)
+	require.Eventually(t, func() bool {
+		return hub.GetClientCount(testEntityID, testEventID) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// O stream recebe o mesmo fan-out dos clientes WebSocket, presença incluída
+	require.NoError(t, hub.Broadcast(testEntityID, testEventID, &Message{Type: MessageTypeEventUpdate, Timestamp: time.Now()}))
+	var received []MessageType
+	require.Eventually(t, func() bool {
+		for _, msg := range drainMessages(t, stream) {
+			received = append(received, msg.Type)
+		}
+		return len(received) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []MessageType{MessageTypeParticipantJoin, MessageTypeEventUpdate}, received)
+
+	hub.CloseStreams()
+
+	// Messages é fechado e só o cliente WebSocket continua conectado
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-stream.Messages():
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, hub.GetClientCount(testEntityID, testEventID))
+	assert.Equal(t, []string{"alice"}, presenceUsers(hub))
+}
+
+func TestHub_StoppedHub_DoesNotBlockClients(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	hub := NewHub(DefaultConfig(), zap.NewNop())
+	stopped := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(stopped)
+	}()
+
+	stream := NewStreamClient(hub, testEntityID, testEventID, "bob", zap.NewNop())
+	hub.Register(stream)
+	require.Eventually(t, func() bool {
+		return hub.GetClientCount(testEntityID, testEventID) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+
+	// Ninguém mais lê os canais do hub: os clientes são removidos (ou recusados) direto
+	late := NewStreamClient(hub, testEntityID, testEventID, "carol", zap.NewNop())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		hub.CloseStreams()
+		hub.Unregister(stream)
+		hub.Register(late)
+		require.NoError(t, hub.Drain(context.Background()))
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("hub blocked after Run returned")
+	}
+
+	assert.Equal(t, 0, hub.GetClientCount(testEntityID, testEventID))
+	assert.Empty(t, hub.GetPresence(testEntityID, testEventID))
+	// Os canais de envio estão fechados: ler o que sobrou (presença) termina
+	for _, client := range []*Client{stream, late} {
+		for range client.Messages() {
+		}
+	}
+}
@@ -4,26 +4,49 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
+// subscriptionOp representa uma inscrição ou remoção de inscrição em um canal
+type subscriptionOp struct {
+	channel   string
+	subscribe bool
+}
+
+// recentTTL é o tempo de retenção do histórico de mensagens de um evento
+const recentTTL = 24 * time.Hour
+
 // PubSub gerencia a comunicação entre instâncias via Redis
 type PubSub struct {
-	client *redis.Client
-	hub    *Hub
-	logger *zap.Logger
+	client     *redis.Client
+	hub        *Hub
+	logger     *zap.Logger
+	replaySize int
+
+	// Fila de inscrições na ordem em que o Hub as gerou. Sem limite para que enqueue
+	// nunca bloqueie o Hub; opsSignal avisa a goroutine de Start que há operações
+	opsMu     sync.Mutex
+	ops       []subscriptionOp
+	opsSignal chan struct{}
 }
 
 // NewPubSub cria um novo gerenciador de PubSub
+// e passa a receber do Hub os eventos de ativação dos canais
 func NewPubSub(client *redis.Client, hub *Hub, logger *zap.Logger) *PubSub {
-	return &PubSub{
-		client: client,
-		hub:    hub,
-		logger: logger,
+	p := &PubSub{
+		client:     client,
+		hub:        hub,
+		logger:     logger,
+		replaySize: hub.cfg.ReplaySize,
+		opsSignal:  make(chan struct{}, 1),
 	}
+	hub.SetChannelListener(p)
+	return p
 }
 
 // getRedisChannel retorna o nome do canal Redis para um evento
@@ -31,6 +54,11 @@ func getRedisChannel(entityID, eventID string) string {
 	return fmt.Sprintf("ws:event:%s:%s", entityID, eventID)
 }
 
+// getRecentKey retorna a chave Redis do histórico recente de um evento
+func getRecentKey(entityID, eventID string) string {
+	return fmt.Sprintf("ws:recent:%s:%s", entityID, eventID)
+}
+
 // Publish publica uma mensagem no Redis para todas as instâncias
 func (p *PubSub) Publish(ctx context.Context, entityID, eventID string, msg *Message) error {
 	data, err := json.Marshal(msg)
@@ -43,6 +71,13 @@ func (p *PubSub) Publish(ctx context.Context, entityID, eventID string, msg *Mes
 		return fmt.Errorf("failed to publish to Redis: %w", err)
 	}
 
+	if err := p.storeRecent(ctx, entityID, eventID, data); err != nil {
+		p.logger.Warn("Failed to store message history",
+			zap.String("channel", channel),
+			zap.Error(err),
+		)
+	}
+
 	p.logger.Debug("Published message to Redis",
 		zap.String("channel", channel),
 		zap.String("type", string(msg.Type)),
@@ -51,35 +86,79 @@ func (p *PubSub) Publish(ctx context.Context, entityID, eventID string, msg *Mes
 	return nil
 }
 
-// Subscribe se inscreve em um canal de evento e repassa para o Hub local
-func (p *PubSub) Subscribe(ctx context.Context, entityID, eventID string) error {
-	channel := getRedisChannel(entityID, eventID)
-	pubsub := p.client.Subscribe(ctx, channel)
+// storeRecent guarda a mensagem no histórico limitado do evento
+func (p *PubSub) storeRecent(ctx context.Context, entityID, eventID string, data []byte) error {
+	if p.replaySize <= 0 {
+		return nil
+	}
+
+	key := getRecentKey(entityID, eventID)
+	pipe := p.client.Pipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, int64(p.replaySize-1))
+	pipe.Expire(ctx, key, recentTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
 
-	// Verificar se a inscrição foi bem-sucedida
-	_, err := pubsub.Receive(ctx)
+// GetRecent retorna as últimas mensagens do evento, da mais antiga para a mais recente
+func (p *PubSub) GetRecent(ctx context.Context, entityID, eventID string) ([]*Message, error) {
+	if p.replaySize <= 0 {
+		return nil, nil
+	}
+
+	items, err := p.client.LRange(ctx, getRecentKey(entityID, eventID), 0, int64(p.replaySize-1)).Result()
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to channel: %w", err)
+		return nil, fmt.Errorf("failed to get message history: %w", err)
+	}
+
+	messages := make([]*Message, 0, len(items))
+	// A lista é armazenada da mais recente para a mais antiga
+	for i := len(items) - 1; i >= 0; i-- {
+		var msg Message
+		if err := json.Unmarshal([]byte(items[i]), &msg); err != nil {
+			p.logger.Warn("Failed to unmarshal message history", zap.Error(err))
+			continue
+		}
+		messages = append(messages, &msg)
 	}
 
-	p.logger.Info("Subscribed to Redis channel", zap.String("channel", channel))
+	return messages, nil
+}
+
+// Start abre a conexão de inscrição no Redis e processa as mensagens dos canais ativos.
+// Os canais são inscritos e removidos conforme clientes locais entram e saem dos eventos.
+func (p *PubSub) Start(ctx context.Context) error {
+	sub := p.client.Subscribe(ctx)
+
+	p.logger.Info("Redis PubSub started")
 
-	// Processar mensagens em goroutine
 	go func() {
-		defer pubsub.Close()
+		defer sub.Close()
 
-		ch := pubsub.Channel()
+		ch := sub.Channel()
 		for {
 			select {
 			case <-ctx.Done():
-				p.logger.Info("Unsubscribing from Redis channel", zap.String("channel", channel))
+				p.logger.Info("Redis PubSub stopping")
 				return
 
+			case <-p.opsSignal:
+				for _, op := range p.takeOps() {
+					p.applySubscription(ctx, sub, op)
+				}
+
 			case redisMsg, ok := <-ch:
 				if !ok {
 					return
 				}
 
+				entityID, eventID, ok := parseChannel(redisMsg.Channel)
+				if !ok {
+					p.logger.Warn("Unexpected Redis channel", zap.String("channel", redisMsg.Channel))
+					continue
+				}
+
 				var msg Message
 				if err := json.Unmarshal([]byte(redisMsg.Payload), &msg); err != nil {
 					p.logger.Warn("Failed to unmarshal Redis message", zap.Error(err))
@@ -97,68 +176,72 @@ func (p *PubSub) Subscribe(ctx context.Context, entityID, eventID string) error
 	return nil
 }
 
-// SubscribeAll se inscreve em todos os eventos ativos
-// Usa pattern matching do Redis
-func (p *PubSub) SubscribeAll(ctx context.Context) error {
-	pattern := "ws:event:*"
-	pubsub := p.client.PSubscribe(ctx, pattern)
-
-	// Verificar se a inscrição foi bem-sucedida
-	_, err := pubsub.Receive(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to subscribe to pattern: %w", err)
+// applySubscription inscreve ou remove a inscrição de um canal
+func (p *PubSub) applySubscription(ctx context.Context, sub *redis.PubSub, op subscriptionOp) {
+	if op.subscribe {
+		if err := sub.Subscribe(ctx, op.channel); err != nil {
+			p.logger.Warn("Failed to subscribe to Redis channel",
+				zap.String("channel", op.channel),
+				zap.Error(err),
+			)
+			return
+		}
+		p.logger.Info("Subscribed to Redis channel", zap.String("channel", op.channel))
+		return
 	}
 
-	p.logger.Info("Subscribed to Redis pattern", zap.String("pattern", pattern))
-
-	// Processar mensagens em goroutine
-	go func() {
-		defer pubsub.Close()
-
-		ch := pubsub.Channel()
-		for {
-			select {
-			case <-ctx.Done():
-				p.logger.Info("Unsubscribing from Redis pattern", zap.String("pattern", pattern))
-				return
+	if err := sub.Unsubscribe(ctx, op.channel); err != nil {
+		p.logger.Warn("Failed to unsubscribe from Redis channel",
+			zap.String("channel", op.channel),
+			zap.Error(err),
+		)
+		return
+	}
+	p.logger.Info("Unsubscribed from Redis channel", zap.String("channel", op.channel))
+}
 
-			case redisMsg, ok := <-ch:
-				if !ok {
-					return
-				}
+// OnChannelActive é chamado pelo Hub quando o primeiro cliente local entra em um evento
+func (p *PubSub) OnChannelActive(entityID, eventID string) {
+	p.enqueue(subscriptionOp{channel: getRedisChannel(entityID, eventID), subscribe: true})
+}
 
-				// Extrair entityID e eventID do canal
-				// Formato: ws:event:{entityID}:{eventID}
-				var entityID, eventID string
-				_, err := fmt.Sscanf(redisMsg.Channel, "ws:event:%s", &entityID)
-				if err != nil {
-					// Tentar parse manual
-					entityID, eventID = parseChannel(redisMsg.Channel)
-				}
+// OnChannelIdle é chamado pelo Hub quando o último cliente local sai de um evento
+func (p *PubSub) OnChannelIdle(entityID, eventID string) {
+	p.enqueue(subscriptionOp{channel: getRedisChannel(entityID, eventID), subscribe: false})
+}
 
-				var msg Message
-				if err := json.Unmarshal([]byte(redisMsg.Payload), &msg); err != nil {
-					p.logger.Warn("Failed to unmarshal Redis message", zap.Error(err))
-					continue
-				}
+// enqueue agenda a operação na ordem em que o Hub a gerou, sem bloquear: a goroutine de
+// Start pode estar esperando pelo Hub (Broadcast) enquanto o Hub notifica um canal
+func (p *PubSub) enqueue(op subscriptionOp) {
+	p.opsMu.Lock()
+	p.ops = append(p.ops, op)
+	p.opsMu.Unlock()
+
+	select {
+	case p.opsSignal <- struct{}{}:
+	default:
+		// Já há um aviso pendente; a goroutine de Start levará esta operação junto
+	}
+}
 
-				// Broadcast para clientes locais
-				if err := p.hub.Broadcast(entityID, eventID, &msg); err != nil {
-					p.logger.Error("Failed to broadcast message", zap.Error(err))
-				}
-			}
-		}
-	}()
+// takeOps retira da fila as operações pendentes, na ordem em que foram agendadas
+func (p *PubSub) takeOps() []subscriptionOp {
+	p.opsMu.Lock()
+	defer p.opsMu.Unlock()
 
-	return nil
+	ops := p.ops
+	p.ops = nil
+	return ops
 }
 
 // parseChannel extrai entityID e eventID do nome do canal
-func parseChannel(channel string) (entityID, eventID string) {
+func parseChannel(channel string) (entityID, eventID string, ok bool) {
 	// ws:event:{entityID}:{eventID}
-	var prefix string
-	fmt.Sscanf(channel, "%s:%s:%s", &prefix, &entityID, &eventID)
-	return
+	parts := strings.Split(channel, ":")
+	if len(parts) != 4 || parts[0] != "ws" || parts[1] != "event" {
+		return "", "", false
+	}
+	return parts[2], parts[3], true
 }
 
 // PublishLocationUpdate publica uma atualização de localização
@@ -176,3 +259,35 @@ func (p *PubSub) PublishLocationUpdate(ctx context.Context, entityID, eventID st
 
 	return p.Publish(ctx, entityID, eventID, msg)
 }
+
+// PublishETAUpdate publica o ETA recalculado de um participante
+func (p *PubSub) PublishETAUpdate(ctx context.Context, entityID, eventID string, data *ETAUpdateData) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	msg := &Message{
+		Type:      MessageTypeETAUpdate,
+		Timestamp: time.Now(),
+		Data:      jsonData,
+	}
+
+	return p.Publish(ctx, entityID, eventID, msg)
+}
+
+// PublishEventUpdate publica os totais de participantes do evento
+func (p *PubSub) PublishEventUpdate(ctx context.Context, entityID, eventID string, data *EventUpdateData) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	msg := &Message{
+		Type:      MessageTypeEventUpdate,
+		Timestamp: time.Now(),
+		Data:      jsonData,
+	}
+
+	return p.Publish(ctx, entityID, eventID, msg)
+}
@@ -0,0 +1,230 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestPubSub(t *testing.T, cfg config.WebSocketConfig) (*PubSub, *Hub, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	hub := newTestHub(cfg)
+	return NewPubSub(client, hub, zap.NewNop()), hub, mr
+}
+
+func newTestMessage(t *testing.T, n int) *Message {
+	t.Helper()
+	data, err := json.Marshal(n)
+	require.NoError(t, err)
+	return &Message{Type: MessageTypeLocationUpdate, Timestamp: time.Now(), Data: data}
+}
+
+func TestPubSub_GetRecent_ReturnsBoundedHistoryOldestFirst(t *testing.T) {
+	ctx := context.Background()
+	ps, _, _ := newTestPubSub(t, config.WebSocketConfig{ReplaySize: 3})
+
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, ps.Publish(ctx, testEntityID, testEventID, newTestMessage(t, i)))
+	}
+
+	messages, err := ps.GetRecent(ctx, testEntityID, testEventID)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	for i, msg := range messages {
+		assert.JSONEq(t, []string{"3", "4", "5"}[i], string(msg.Data))
+	}
+
+	// O histórico é por evento
+	other, err := ps.GetRecent(ctx, testEntityID, "another-event")
+	require.NoError(t, err)
+	assert.Empty(t, other)
+}
+
+func TestPubSub_GetRecent_DisabledWithoutReplaySize(t *testing.T) {
+	ctx := context.Background()
+	ps, _, _ := newTestPubSub(t, config.WebSocketConfig{})
+
+	require.NoError(t, ps.Publish(ctx, testEntityID, testEventID, newTestMessage(t, 1)))
+
+	messages, err := ps.GetRecent(ctx, testEntityID, testEventID)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestNewPubSub_ReplaySizeBoundedBySendBuffer(t *testing.T) {
+	ps, _, _ := newTestPubSub(t, config.WebSocketConfig{SendBufferSize: 4, ReplaySize: 50})
+
+	assert.Equal(t, 4, ps.replaySize)
+}
+
+func TestHub_SendTo_ReplaysSnapshotToSingleClient(t *testing.T) {
+	ctx := context.Background()
+	ps, hub, _ := newTestPubSub(t, config.WebSocketConfig{ReplaySize: 10})
+
+	require.NoError(t, ps.Publish(ctx, testEntityID, testEventID, newTestMessage(t, 1)))
+	require.NoError(t, ps.Publish(ctx, testEntityID, testEventID, newTestMessage(t, 2)))
+
+	other := newTestClient(hub, "other")
+	hub.addClient(other)
+	drainMessages(t, other)
+
+	newcomer := newTestClient(hub, "newcomer")
+	recent, err := ps.GetRecent(ctx, testEntityID, testEventID)
+	require.NoError(t, err)
+	for _, msg := range recent {
+		require.NoError(t, hub.SendTo(newcomer, msg))
+	}
+
+	messages := drainMessages(t, newcomer)
+	require.Len(t, messages, 2)
+	assert.JSONEq(t, "1", string(messages[0].Data))
+	assert.JSONEq(t, "2", string(messages[1].Data))
+
+	// O replay não é um broadcast
+	assert.Empty(t, drainMessages(t, other))
+}
+
+// channelRecorder registra as notificações de canal do Hub
+type channelRecorder struct {
+	events []string
+}
+
+func (r *channelRecorder) OnChannelActive(entityID, eventID string) {
+	r.events = append(r.events, "active:"+getChannelKey(entityID, eventID))
+}
+
+func (r *channelRecorder) OnChannelIdle(entityID, eventID string) {
+	r.events = append(r.events, "idle:"+getChannelKey(entityID, eventID))
+}
+
+func TestHub_ChannelListener_FirstAndLastClient(t *testing.T) {
+	hub := newTestHub(config.WebSocketConfig{})
+	recorder := &channelRecorder{}
+	hub.SetChannelListener(recorder)
+
+	key := getChannelKey(testEntityID, testEventID)
+	first := newTestClient(hub, "alice")
+	second := newTestClient(hub, "bob")
+
+	hub.addClient(first)
+	hub.addClient(second)
+	assert.Equal(t, []string{"active:" + key}, recorder.events)
+
+	hub.removeClient(first)
+	assert.Equal(t, []string{"active:" + key}, recorder.events)
+
+	hub.removeClient(second)
+	assert.Equal(t, []string{"active:" + key, "idle:" + key}, recorder.events)
+
+	// Um novo cliente reativa o canal
+	hub.addClient(newTestClient(hub, "carol"))
+	assert.Equal(t, []string{"active:" + key, "idle:" + key, "active:" + key}, recorder.events)
+}
+
+func TestPubSub_SubscribesOnlyWhileEventHasLocalClients(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ps, hub, mr := newTestPubSub(t, config.WebSocketConfig{})
+	go hub.Run(ctx)
+	require.NoError(t, ps.Start(ctx))
+
+	channel := getRedisChannel(testEntityID, testEventID)
+	assert.Empty(t, mr.PubSubChannels(""))
+
+	client := newTestClient(hub, "alice")
+	hub.Register(client)
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(channel)[channel] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Mensagens publicadas por outra instância chegam ao cliente local
+	require.NoError(t, ps.Publish(ctx, testEntityID, testEventID, newTestMessage(t, 1)))
+	require.Eventually(t, func() bool {
+		for _, msg := range drainMessages(t, client) {
+			if msg.Type == MessageTypeLocationUpdate {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	// Eventos sem clientes locais não são inscritos
+	assert.Equal(t, []string{channel}, mr.PubSubChannels(""))
+
+	hub.unregister <- client
+	require.Eventually(t, func() bool {
+		return len(mr.PubSubChannels("")) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPubSub_ChannelEventsDoNotBlockHubWhenQueuesAreSaturated(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ps, hub, mr := newTestPubSub(t, config.WebSocketConfig{})
+	require.NoError(t, ps.Start(ctx))
+
+	// Sem o loop do hub rodando, o broadcast fica cheio e a goroutine do PubSub trava em
+	// hub.Broadcast ao receber a próxima mensagem do Redis
+	hub.addClient(newTestClient(hub, "alice"))
+	channel := getRedisChannel(testEntityID, testEventID)
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(channel)[channel] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	for len(hub.broadcast) < cap(hub.broadcast) {
+		hub.broadcast <- &BroadcastMessage{EntityID: testEntityID, EventID: "filler"}
+	}
+	require.NoError(t, ps.Publish(ctx, testEntityID, testEventID, newTestMessage(t, 1)))
+
+	// Mais canais ativados do que cabia na antiga fila de inscrições (256)
+	const events = 600
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < events; i++ {
+			hub.addClient(NewClient(nil, hub, testEntityID, fmt.Sprintf("event-%d", i), "bob", "", zap.NewNop()))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("hub blocked notifying channel activations")
+	}
+	assert.Equal(t, 0, hub.GetClientCount(testEntityID, "filler"))
+
+	// Com o hub de volta, o PubSub destrava e aplica todas as inscrições, na ordem
+	go hub.Run(ctx)
+	require.Eventually(t, func() bool {
+		return len(mr.PubSubChannels("")) == events+1
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestParseChannel(t *testing.T) {
+	entityID, eventID, ok := parseChannel(getRedisChannel(testEntityID, testEventID))
+	require.True(t, ok)
+	assert.Equal(t, testEntityID, entityID)
+	assert.Equal(t, testEventID, eventID)
+
+	_, _, ok = parseChannel("ws:other:" + testEntityID + ":" + testEventID)
+	assert.False(t, ok)
+	_, _, ok = parseChannel("ws:event:" + testEntityID)
+	assert.False(t, ok)
+}
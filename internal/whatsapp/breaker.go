@@ -0,0 +1,153 @@
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the API while the circuit breaker is open
+var ErrCircuitOpen = errors.New("whatsapp circuit breaker is open")
+
+// CircuitOpenError is the ErrCircuitOpen returned by a send, with the time left until
+// the breaker lets a probe request through
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrCircuitOpen, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreaker stops calling the API after threshold consecutive failures. It stays
+// open for the cooldown, failing fast, then half-opens and lets a single probe through:
+// a successful probe closes it, a failed one opens it for another cooldown. A nil
+// CircuitBreaker is disabled and always allows the request
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a breaker; a threshold <= 0 disables it and returns nil
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+}
+
+// State returns the current state, reporting an open breaker whose cooldown has elapsed
+// as half-open
+func (b *CircuitBreaker) State() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && b.retryAfter() <= 0 {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a request may be sent. While open it returns a
+// *CircuitOpenError; once the cooldown elapses it half-opens and allows one probe,
+// failing the other requests until the probe's outcome is recorded
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if wait := b.retryAfter(); wait > 0 {
+			return &CircuitOpenError{RetryAfter: wait}
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return &CircuitOpenError{RetryAfter: b.cooldown}
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a request the API answered, closing the breaker
+func (b *CircuitBreaker) Success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a request the API failed to answer. It opens the breaker after
+// threshold consecutive failures, or at once when the half-open probe fails
+func (b *CircuitBreaker) Failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Release gives up an allowed request without an outcome (e.g. the caller's context
+// ended), so a half-open breaker can let another probe through
+func (b *CircuitBreaker) Release() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *CircuitBreaker) retryAfter() time.Duration {
+	return b.openedAt.Add(b.cooldown).Sub(b.now())
+}
@@ -0,0 +1,145 @@
+package whatsapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBreaker returns a breaker whose clock is advanced by the returned function
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, func(time.Duration)) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(threshold, cooldown)
+	breaker.now = func() time.Time { return now }
+	return breaker, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestCircuitBreaker_ClosedOpenHalfOpenClosed(t *testing.T) {
+	breaker, advance := newTestBreaker(3, time.Minute)
+
+	// Closed: failures below the threshold keep letting requests through
+	for i := 0; i < 2; i++ {
+		require.NoError(t, breaker.Allow())
+		breaker.Failure()
+	}
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	// The third consecutive failure opens it
+	require.NoError(t, breaker.Allow())
+	breaker.Failure()
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	// Open: fails fast until the cooldown ends
+	advance(40 * time.Second)
+	err := breaker.Allow()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	var openErr *CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, 20*time.Second, openErr.RetryAfter)
+
+	// Half-open: a single probe goes through, the others still fail fast
+	advance(20 * time.Second)
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	require.NoError(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+	// The probe succeeds: closed again, with the failure count reset
+	breaker.Success()
+	assert.Equal(t, CircuitClosed, breaker.State())
+	require.NoError(t, breaker.Allow())
+	breaker.Failure()
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreaker_FailedProbeReopens(t *testing.T) {
+	breaker, advance := newTestBreaker(1, time.Minute)
+
+	breaker.Failure()
+	advance(time.Minute)
+	require.NoError(t, breaker.Allow())
+	breaker.Failure()
+
+	assert.Equal(t, CircuitOpen, breaker.State())
+	var openErr *CircuitOpenError
+	require.ErrorAs(t, breaker.Allow(), &openErr)
+	assert.Equal(t, time.Minute, openErr.RetryAfter, "a failed probe starts a new cooldown")
+}
+
+func TestCircuitBreaker_ReleasedProbeAllowsAnother(t *testing.T) {
+	breaker, advance := newTestBreaker(1, time.Minute)
+
+	breaker.Failure()
+	advance(time.Minute)
+	require.NoError(t, breaker.Allow())
+	breaker.Release()
+
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestCircuitBreaker_NilIsDisabled(t *testing.T) {
+	breaker := NewCircuitBreaker(0, time.Minute)
+	require.Nil(t, breaker)
+
+	breaker.Failure()
+	assert.NoError(t, breaker.Allow())
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestClient_CircuitBreakerFailsFastWhileAPIIsDown(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(&config.WhatsAppConfig{
+		BaseURL:          server.URL,
+		APIVersion:       "v18.0",
+		PhoneNumberID:    "123",
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	})
+
+	for i := 0; i < 2; i++ {
+		err := client.SendTextMessage(context.Background(), "5511999990000", "Olá")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	assert.Equal(t, CircuitOpen, client.CircuitState())
+
+	err := client.SendTextMessage(context.Background(), "5511999990000", "Olá")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), requests.Load(), "no request is sent while the circuit is open")
+}
+
+func TestClient_CircuitBreakerIgnoresRejectedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(&config.WhatsAppConfig{
+		BaseURL:          server.URL,
+		APIVersion:       "v18.0",
+		PhoneNumberID:    "123",
+		BreakerThreshold: 1,
+		BreakerCooldown:  time.Minute,
+	})
+
+	// A bad number is the request's fault, not a sign the API is down
+	for i := 0; i < 3; i++ {
+		err := client.SendTextMessage(context.Background(), "invalid", "Olá")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	assert.Equal(t, CircuitClosed, client.CircuitState())
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -11,52 +12,49 @@ import (
 	"event-coming/internal/config"
 )
 
+// defaultSendTimeout applies when the config doesn't set a send timeout
+const defaultSendTimeout = 30 * time.Second
+
 // Client handles WhatsApp Cloud API interactions
 type Client struct {
-	config     *config.WhatsAppConfig
-	httpClient *http.Client
-	baseURL    string
+	config      *config.WhatsAppConfig
+	httpClient  *http.Client
+	baseURL     string
+	sendTimeout time.Duration
+	breaker     *CircuitBreaker // nil when disabled
 }
 
-// NewClient creates a new WhatsApp client
+// NewClient creates a new WhatsApp client with its own circuit breaker, configured by
+// BreakerThreshold and BreakerCooldown
 func NewClient(cfg *config.WhatsAppConfig) *Client {
-	return &Client{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL: fmt.Sprintf("%s/%s/%s", cfg.BaseURL, cfg.APIVersion, cfg.PhoneNumberID),
-	}
+	return NewClientWithBreaker(cfg, NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown))
 }
 
-// SendTemplateMessage sends a template message
-func (c *Client) SendTemplateMessage(ctx context.Context, req *TemplateMessageRequest) error {
-	url := fmt.Sprintf("%s/messages", c.baseURL)
-
-	body, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// NewClientWithBreaker creates a WhatsApp client that shares breaker (nil disables it),
+// for clients recreated per send that must remember the failures of the same number
+func NewClientWithBreaker(cfg *config.WhatsAppConfig, breaker *CircuitBreaker) *Client {
+	sendTimeout := cfg.SendTimeout
+	if sendTimeout <= 0 {
+		sendTimeout = defaultSendTimeout
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.AccessToken))
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+	return &Client{
+		config:      cfg,
+		httpClient:  &http.Client{},
+		baseURL:     fmt.Sprintf("%s/%s/%s", cfg.BaseURL, cfg.APIVersion, cfg.PhoneNumberID),
+		sendTimeout: sendTimeout,
+		breaker:     breaker,
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// CircuitState returns the state of the client's circuit breaker
+func (c *Client) CircuitState() CircuitState {
+	return c.breaker.State()
+}
 
-	return nil
+// SendTemplateMessage sends a template message
+func (c *Client) SendTemplateMessage(ctx context.Context, req *TemplateMessageRequest) error {
+	return c.postMessage(ctx, req, nil)
 }
 
 // SendConfirmationRequest sends a confirmation request to a participant
@@ -112,8 +110,13 @@ func (c *Client) SendLocationRequest(ctx context.Context, phoneNumber, participa
 
 // SendTextMessage sends a plain text message
 func (c *Client) SendTextMessage(ctx context.Context, phoneNumber, message string) error {
-	url := fmt.Sprintf("%s/messages", c.baseURL)
+	_, err := c.SendTextMessageWithID(ctx, phoneNumber, message)
+	return err
+}
 
+// SendTextMessageWithID sends a plain text message and returns the WhatsApp message id,
+// used to correlate later status callbacks
+func (c *Client) SendTextMessageWithID(ctx context.Context, phoneNumber, message string) (string, error) {
 	payload := map[string]interface{}{
 		"messaging_product": "whatsapp",
 		"recipient_type":    "individual",
@@ -125,12 +128,73 @@ func (c *Client) SendTextMessage(ctx context.Context, phoneNumber, message strin
 		},
 	}
 
+	var sendResp SendMessageResponse
+	if err := c.postMessage(ctx, payload, &sendResp); err != nil {
+		return "", err
+	}
+	if len(sendResp.Messages) == 0 {
+		// Message was accepted, the id is only needed for delivery tracking
+		return "", nil
+	}
+
+	return sendResp.Messages[0].ID, nil
+}
+
+// postMessage posts a payload to the messages endpoint, bounded by the send timeout
+// and by ctx. When out is set, the response body is decoded into it; a body that
+// can't be decoded is ignored, since the message was already accepted.
+// While the circuit breaker is open it fails fast with a *CircuitOpenError
+func (c *Client) postMessage(ctx context.Context, payload interface{}, out interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err := c.breaker.Allow(); err != nil {
+		return err
+	}
+
+	err = c.send(ctx, body, out)
+	switch {
+	case err == nil:
+		c.breaker.Success()
+	case ctx.Err() != nil:
+		// The caller gave up: says nothing about the API's health
+		c.breaker.Release()
+	case isUnavailable(err):
+		c.breaker.Failure()
+	default:
+		// The API answered, the request itself was rejected
+		c.breaker.Success()
+	}
+	return err
+}
+
+// statusError is a response with an unexpected status code
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.code)
+}
+
+// isUnavailable reports whether err means the API is down or overloaded (transport
+// errors, timeouts, 5xx and 429), as opposed to a request it rejected
+func isUnavailable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= http.StatusInternalServerError || statusErr.code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// send performs the request of postMessage
+func (c *Client) send(ctx context.Context, body []byte, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.sendTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/messages", c.baseURL), bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -140,13 +204,19 @@ func (c *Client) SendTextMessage(ctx context.Context, phoneNumber, message strin
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("whatsapp request timed out after %s: %w", c.sendTimeout, err)
+		}
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return &statusError{code: resp.StatusCode}
 	}
 
+	if out != nil {
+		_ = json.NewDecoder(resp.Body).Decode(out)
+	}
 	return nil
 }
@@ -0,0 +1,74 @@
+package whatsapp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlowServer answers after delay, or as soon as the client gives up on the request
+func newSlowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The body must be consumed for the server to notice the client going away
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-time.After(delay):
+			w.Write([]byte(`{"messages":[{"id":"wamid.slow"}]}`))
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(server *httptest.Server, sendTimeout time.Duration) *Client {
+	return NewClient(&config.WhatsAppConfig{
+		BaseURL:       server.URL,
+		APIVersion:    "v18.0",
+		PhoneNumberID: "123",
+		AccessToken:   "token",
+		SendTimeout:   sendTimeout,
+	})
+}
+
+func TestClient_SendTimeout(t *testing.T) {
+	client := newTestClient(newSlowServer(t, 2*time.Second), 50*time.Millisecond)
+
+	start := time.Now()
+	err := client.SendTextMessage(context.Background(), "5511999990000", "Olá")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestClient_SendRespectsCallerContext(t *testing.T) {
+	client := newTestClient(newSlowServer(t, 2*time.Second), time.Minute)
+
+	// The worker's context ends before the send timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := client.SendTemplateMessage(ctx, &TemplateMessageRequest{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestClient_SendWithinTimeout(t *testing.T) {
+	client := newTestClient(newSlowServer(t, 10*time.Millisecond), time.Second)
+
+	id, err := client.SendTextMessageWithID(context.Background(), "5511999990000", "Olá")
+	require.NoError(t, err)
+	assert.Equal(t, "wamid.slow", id)
+}
@@ -0,0 +1,106 @@
+package whatsapp
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"event-coming/internal/domain"
+)
+
+// Provider is the provider name used in inbound messages
+const Provider = "whatsapp"
+
+// InboundParser converts WhatsApp webhook payloads into domain.InboundMessage
+type InboundParser struct{}
+
+// NewInboundParser creates a new WhatsApp inbound parser
+func NewInboundParser() *InboundParser {
+	return &InboundParser{}
+}
+
+// Provider returns the provider name
+func (p *InboundParser) Provider() string {
+	return Provider
+}
+
+// Parse decodes a raw webhook body and returns its messages
+func (p *InboundParser) Parse(body []byte) ([]*domain.InboundMessage, error) {
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	return p.ParsePayload(&payload), nil
+}
+
+// ParsePayload returns the messages of an already decoded webhook payload
+func (p *InboundParser) ParsePayload(payload *WebhookPayload) []*domain.InboundMessage {
+	var messages []*domain.InboundMessage
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			for _, msg := range change.Value.Messages {
+				messages = append(messages, toInboundMessage(msg))
+			}
+		}
+	}
+	return messages
+}
+
+// toInboundMessage maps a WhatsApp message to the provider-agnostic type
+func toInboundMessage(msg Message) *domain.InboundMessage {
+	inbound := &domain.InboundMessage{
+		Provider:          Provider,
+		ProviderMessageID: msg.ID,
+		From:              msg.From,
+		Type:              domain.InboundMessageUnknown,
+		Timestamp:         time.Now(),
+	}
+
+	if ts, err := strconv.ParseInt(msg.Timestamp, 10, 64); err == nil {
+		inbound.Timestamp = time.Unix(ts, 0)
+	}
+
+	switch msg.Type {
+	case "text":
+		if msg.Text != nil {
+			inbound.Type = domain.InboundMessageText
+			inbound.Text = msg.Text.Body
+		}
+	case "location":
+		if msg.Location != nil {
+			inbound.Type = domain.InboundMessageLocation
+			inbound.Location = &domain.InboundLocation{
+				Latitude:  msg.Location.Latitude,
+				Longitude: msg.Location.Longitude,
+			}
+		}
+	case "button":
+		if msg.Button != nil {
+			inbound.Type = domain.InboundMessageReply
+			inbound.ReplyID = msg.Button.Payload
+			inbound.Text = msg.Button.Text
+		}
+	case "interactive":
+		if msg.Interactive == nil {
+			break
+		}
+		switch {
+		case msg.Interactive.ListReply != nil:
+			inbound.Type = domain.InboundMessageReply
+			inbound.ReplyID = msg.Interactive.ListReply.ID
+			inbound.Text = msg.Interactive.ListReply.Title
+		case msg.Interactive.ButtonReply != nil:
+			inbound.Type = domain.InboundMessageReply
+			inbound.ReplyID = msg.Interactive.ButtonReply.Payload
+			if inbound.ReplyID == "" {
+				inbound.ReplyID = msg.Interactive.ButtonReply.ID
+			}
+			inbound.Text = msg.Interactive.ButtonReply.Title
+		}
+	}
+
+	return inbound
+}
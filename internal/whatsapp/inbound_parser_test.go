@@ -0,0 +1,67 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+
+	"event-coming/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboundParser_Parse(t *testing.T) {
+	body := []byte(`{
+		"object": "whatsapp_business_account",
+		"entry": [{"changes": [
+			{"field": "messages", "value": {"messages": [
+				{"id": "wamid.text", "from": "5511999999999", "timestamp": "1700000000", "type": "text", "text": {"body": "Sim"}},
+				{"id": "wamid.loc", "from": "5511999999999", "timestamp": "1700000001", "type": "location", "location": {"latitude": -23.55, "longitude": -46.63}},
+				{"id": "wamid.button", "from": "5511999999999", "timestamp": "1700000002", "type": "button", "button": {"payload": "confirm_yes", "text": "Yes"}},
+				{"id": "wamid.list", "from": "5511999999999", "timestamp": "1700000003", "type": "interactive", "interactive": {"type": "list_reply", "list_reply": {"id": "slot-1", "title": "Morning"}}},
+				{"id": "wamid.reply", "from": "5511999999999", "timestamp": "1700000004", "type": "interactive", "interactive": {"type": "button_reply", "button_reply": {"id": "confirm_no", "title": "No"}}},
+				{"id": "wamid.image", "from": "5511999999999", "timestamp": "1700000005", "type": "image"}
+			]}},
+			{"field": "account_update", "value": {"messages": [{"id": "ignored", "type": "text", "text": {"body": "x"}}]}}
+		]}]
+	}`)
+
+	p := NewInboundParser()
+	assert.Equal(t, Provider, p.Provider())
+
+	messages, err := p.Parse(body)
+	require.NoError(t, err)
+	require.Len(t, messages, 6)
+
+	for _, msg := range messages {
+		assert.Equal(t, Provider, msg.Provider)
+		assert.Equal(t, "5511999999999", msg.From)
+	}
+
+	assert.Equal(t, domain.InboundMessageText, messages[0].Type)
+	assert.Equal(t, "Sim", messages[0].Text)
+	assert.Equal(t, "wamid.text", messages[0].ProviderMessageID)
+	assert.Equal(t, time.Unix(1700000000, 0), messages[0].Timestamp)
+
+	assert.Equal(t, domain.InboundMessageLocation, messages[1].Type)
+	require.NotNil(t, messages[1].Location)
+	assert.Equal(t, -23.55, messages[1].Location.Latitude)
+	assert.Equal(t, -46.63, messages[1].Location.Longitude)
+
+	assert.Equal(t, domain.InboundMessageReply, messages[2].Type)
+	assert.Equal(t, "confirm_yes", messages[2].ReplyID)
+
+	assert.Equal(t, domain.InboundMessageReply, messages[3].Type)
+	assert.Equal(t, "slot-1", messages[3].ReplyID)
+	assert.Equal(t, "Morning", messages[3].Text)
+
+	assert.Equal(t, domain.InboundMessageReply, messages[4].Type)
+	assert.Equal(t, "confirm_no", messages[4].ReplyID)
+
+	assert.Equal(t, domain.InboundMessageUnknown, messages[5].Type)
+}
+
+func TestInboundParser_Parse_InvalidBody(t *testing.T) {
+	_, err := NewInboundParser().Parse([]byte("not json"))
+	assert.Error(t, err)
+}
@@ -70,3 +70,11 @@ type Reply struct {
 	ID    string `json:"id"`
 	Title string `json:"title"`
 }
+
+// SendMessageResponse represents the Cloud API response to a sent message
+type SendMessageResponse struct {
+	MessagingProduct string `json:"messaging_product"`
+	Messages         []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
@@ -75,12 +75,23 @@ type Location struct {
 type ButtonReply struct {
 	Payload string `json:"payload"`
 	Text    string `json:"text"`
+	// Interactive button replies carry id/title instead of payload/text
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// ListReply represents the option picked in an interactive list message
+type ListReply struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
 }
 
 // InteractiveReply represents an interactive reply
 type InteractiveReply struct {
 	Type        string       `json:"type"`
 	ButtonReply *ButtonReply `json:"button_reply,omitempty"`
+	ListReply   *ListReply   `json:"list_reply,omitempty"`
 }
 
 // Status represents a message status update
@@ -91,6 +102,14 @@ type Status struct {
 	RecipientID  string       `json:"recipient_id"`
 	Conversation Conversation `json:"conversation,omitempty"`
 	Pricing      Pricing      `json:"pricing,omitempty"`
+	Errors       []StatusError `json:"errors,omitempty"`
+}
+
+// StatusError represents an error reported in a failed status update
+type StatusError struct {
+	Code    int    `json:"code"`
+	Title   string `json:"title"`
+	Message string `json:"message,omitempty"`
 }
 
 // Conversation represents conversation info
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"event-coming/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// ParticipantRedactionWorker apaga periodicamente os dados pessoais dos participantes de
+// eventos encerrados há mais que a retenção configurada
+type ParticipantRedactionWorker struct {
+	redactionService *service.ParticipantRedactionService
+	logger           *zap.Logger
+	interval         time.Duration
+	stopCh           chan struct{}
+	stopOnce         sync.Once
+	wg               sync.WaitGroup
+}
+
+// NewParticipantRedactionWorker cria um novo worker de redação de dados de participantes
+func NewParticipantRedactionWorker(redactionService *service.ParticipantRedactionService, logger *zap.Logger, interval time.Duration) *ParticipantRedactionWorker {
+	return &ParticipantRedactionWorker{
+		redactionService: redactionService,
+		logger:           logger,
+		interval:         interval,
+		stopCh:           make(chan struct{}),
+	}
+}
+
+// Start inicia o loop de redação; retorna logo se o intervalo não for positivo
+func (w *ParticipantRedactionWorker) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		w.logger.Info("Participant redaction worker disabled")
+		return
+	}
+
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	w.logger.Info("Participant redaction worker started", zap.Duration("interval", w.interval))
+
+	// Redigir imediatamente ao iniciar
+	w.redact(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Participant redaction worker stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("Participant redaction worker stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.redact(ctx)
+		}
+	}
+}
+
+// Stop para o worker e espera a rodada em andamento terminar
+func (w *ParticipantRedactionWorker) Stop() {
+	w.stopOnce.Do(func() { close(w.stopCh) })
+	w.wg.Wait()
+
+	w.logger.Info("Participant redaction worker stopped")
+}
+
+// redact redige os eventos cuja retenção já passou
+func (w *ParticipantRedactionWorker) redact(ctx context.Context) {
+	start := time.Now()
+
+	redacted, err := w.redactionService.RedactExpired(ctx, start)
+	if err != nil {
+		w.logger.Error("Failed to redact expired events", zap.Error(err))
+		return
+	}
+
+	if redacted > 0 {
+		w.logger.Info("Redacted expired events",
+			zap.Int("events", redacted),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/config"
+	"event-coming/internal/domain"
+	"event-coming/internal/service"
+	"event-coming/internal/testutil"
+	"event-coming/internal/testutil/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestParticipantRedactionWorker_Start_RedactsExpiredEvents(t *testing.T) {
+	eventRepo := new(mocks.MockEventRepository)
+	participantRepo := new(mocks.MockParticipantRepository)
+	locationRepo := new(mocks.MockLocationRepository)
+	deliveryRepo := new(mocks.MockNotificationDeliveryRepository)
+	auditRepo := new(mocks.MockAuditLogRepository)
+	transactor := new(mocks.MockTransactor)
+
+	retention := 30 * 24 * time.Hour
+	event := testutil.NewTestEvent()
+	event.Status = domain.EventStatusCompleted
+
+	// A primeira rodada encontra o evento; as seguintes não têm mais nada a redigir
+	cutoffs := make(chan time.Time, 16)
+	recordRound := func(args mock.Arguments) {
+		select {
+		case cutoffs <- args.Get(1).(time.Time):
+		default:
+		}
+	}
+	eventRepo.On("ListPendingRedaction", mock.Anything, mock.AnythingOfType("time.Time"), mock.Anything).
+		Run(recordRound).Return([]*domain.Event{event}, nil).Once()
+	eventRepo.On("ListPendingRedaction", mock.Anything, mock.AnythingOfType("time.Time"), mock.Anything).
+		Run(recordRound).Return([]*domain.Event{}, nil)
+	transactor.On("WithinTransaction", mock.Anything).Return(nil)
+	participantRepo.On("RedactByEvent", mock.Anything, event.ID, event.EntityID, mock.AnythingOfType("time.Time")).Return(int64(2), nil)
+	locationRepo.On("DeleteByEvent", mock.Anything, event.ID, event.EntityID).Return(int64(0), nil)
+	deliveryRepo.On("RedactRecipients", mock.Anything, event.ID, event.EntityID).Return(int64(0), nil)
+	auditRepo.On("RedactEventParticipants", mock.Anything, event.ID, event.EntityID).Return(int64(0), nil)
+	eventRepo.On("MarkParticipantsRedacted", mock.Anything, event.ID, event.EntityID, mock.AnythingOfType("time.Time")).Return(nil)
+
+	svc := service.NewParticipantRedactionService(eventRepo, participantRepo, locationRepo, deliveryRepo, auditRepo,
+		transactor, nil, nil, &config.WorkerConfig{ParticipantDataRetention: retention}, zap.NewNop())
+	w := NewParticipantRedactionWorker(svc, zap.NewNop(), 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	started := time.Now()
+	go func() {
+		w.Start(ctx)
+		close(done)
+	}()
+
+	// Redige ao iniciar e continua rodando a cada intervalo
+	var first time.Time
+	for round := 0; round < 3; round++ {
+		select {
+		case cutoff := <-cutoffs:
+			if round == 0 {
+				first = cutoff
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("round %d did not run", round+1)
+		}
+	}
+
+	cancel()
+	w.Stop()
+	<-done
+
+	eventRepo.AssertCalled(t, "MarkParticipantsRedacted", mock.Anything, event.ID, event.EntityID, mock.AnythingOfType("time.Time"))
+	participantRepo.AssertNumberOfCalls(t, "RedactByEvent", 1)
+
+	// O corte é o início da rodada menos a retenção
+	assert.WithinDuration(t, started.Add(-retention), first, time.Second)
+}
+
+func TestParticipantRedactionWorker_Start_DisabledWithoutInterval(t *testing.T) {
+	eventRepo := new(mocks.MockEventRepository)
+	svc := service.NewParticipantRedactionService(eventRepo, nil, nil, nil, nil, nil, nil, nil,
+		&config.WorkerConfig{ParticipantDataRetention: time.Hour}, zap.NewNop())
+	w := NewParticipantRedactionWorker(svc, zap.NewNop(), 0)
+
+	w.Start(context.Background())
+	w.Stop()
+
+	eventRepo.AssertNotCalled(t, "ListPendingRedaction", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -16,7 +16,10 @@ type SchedulerWorker struct {
 	logger           *zap.Logger
 	interval         time.Duration
 	batchSize        int
+	shutdownTimeout  time.Duration
 	stopCh           chan struct{}
+	abortCh          chan struct{}
+	stopOnce         sync.Once
 	wg               sync.WaitGroup
 }
 
@@ -26,6 +29,7 @@ func NewSchedulerWorker(
 	logger *zap.Logger,
 	interval time.Duration,
 	batchSize int,
+	shutdownTimeout time.Duration,
 ) *SchedulerWorker {
 	if batchSize <= 0 {
 		batchSize = 100
@@ -33,17 +37,23 @@ func NewSchedulerWorker(
 	if interval <= 0 {
 		interval = 30 * time.Second
 	}
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = 20 * time.Second
+	}
 
 	return &SchedulerWorker{
 		schedulerService: schedulerService,
 		logger:           logger,
 		interval:         interval,
 		batchSize:        batchSize,
+		shutdownTimeout:  shutdownTimeout,
 		stopCh:           make(chan struct{}),
+		abortCh:          make(chan struct{}),
 	}
 }
 
-// Start inicia o loop de processamento
+// Start inicia o loop de processamento. Cancelar o ctx para de buscar novos lotes,
+// mas o lote em andamento termina (ver Stop)
 func (w *SchedulerWorker) Start(ctx context.Context) {
 	w.wg.Add(1)
 	defer w.wg.Done()
@@ -53,8 +63,20 @@ func (w *SchedulerWorker) Start(ctx context.Context) {
 		zap.Int("batch_size", w.batchSize),
 	)
 
+	// O lote roda num contexto desacoplado do ctx: só é interrompido quando o
+	// prazo de shutdown estoura, e aí as tasks não iniciadas são liberadas
+	batchCtx, abort := context.WithCancel(context.WithoutCancel(ctx))
+	defer abort()
+	go func() {
+		select {
+		case <-w.abortCh:
+			abort()
+		case <-batchCtx.Done():
+		}
+	}()
+
 	// Processar imediatamente ao iniciar
-	w.processScheduledTasks(ctx)
+	w.processScheduledTasks(batchCtx)
 
 	ticker := time.NewTicker(w.interval)
 	defer ticker.Stop()
@@ -68,15 +90,32 @@ func (w *SchedulerWorker) Start(ctx context.Context) {
 			w.logger.Info("Scheduler worker stopping (stop signal)")
 			return
 		case <-ticker.C:
-			w.processScheduledTasks(ctx)
+			w.processScheduledTasks(batchCtx)
 		}
 	}
 }
 
-// Stop para o worker gracefully
+// Stop para o worker gracefully: espera o lote em andamento terminar por até
+// shutdownTimeout; depois disso interrompe o lote, que libera as tasks não iniciadas
 func (w *SchedulerWorker) Stop() {
-	close(w.stopCh)
-	w.wg.Wait()
+	w.stopOnce.Do(func() { close(w.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(w.shutdownTimeout):
+		w.logger.Warn("Scheduler batch still running at shutdown deadline, aborting",
+			zap.Duration("timeout", w.shutdownTimeout),
+		)
+		close(w.abortCh)
+		<-done
+	}
+
 	w.logger.Info("Scheduler worker stopped")
 }
 
@@ -96,4 +135,19 @@ func (w *SchedulerWorker) processScheduledTasks(ctx context.Context) {
 			zap.Duration("duration", time.Since(start)),
 		)
 	}
+
+	w.autoCompleteEvents(ctx)
+}
+
+// autoCompleteEvents completa os eventos que ficaram active depois do fim
+func (w *SchedulerWorker) autoCompleteEvents(ctx context.Context) {
+	completed, err := w.schedulerService.AutoCompleteEnded(ctx, w.batchSize)
+	if err != nil {
+		w.logger.Error("Failed to auto-complete ended events", zap.Error(err))
+		return
+	}
+
+	if completed > 0 {
+		w.logger.Info("Auto-completed ended events", zap.Int("count", completed))
+	}
 }
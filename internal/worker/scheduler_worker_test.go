@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"event-coming/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// slowSchedulerService simula um lote demorado: sinaliza o início e termina depois de
+// duration, ou antes se o ctx do lote for cancelado
+type slowSchedulerService struct {
+	service.SchedulerService
+	duration  time.Duration
+	started   chan struct{}
+	cancelled chan bool
+}
+
+func newSlowSchedulerService(duration time.Duration) *slowSchedulerService {
+	return &slowSchedulerService{
+		duration:  duration,
+		started:   make(chan struct{}, 1),
+		cancelled: make(chan bool, 1),
+	}
+}
+
+func (s *slowSchedulerService) ProcessPendingTasks(ctx context.Context, limit int) (int, error) {
+	select {
+	case s.started <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-time.After(s.duration):
+		s.cancelled <- false
+		return limit, nil
+	case <-ctx.Done():
+		s.cancelled <- true
+		return 0, nil
+	}
+}
+
+func (s *slowSchedulerService) AutoCompleteEnded(ctx context.Context, limit int) (int, error) {
+	return 0, nil
+}
+
+// startWorker inicia o worker e espera o primeiro lote começar
+func startWorker(t *testing.T, svc *slowSchedulerService, shutdownTimeout time.Duration) (*SchedulerWorker, context.CancelFunc) {
+	t.Helper()
+
+	w := NewSchedulerWorker(svc, zap.NewNop(), time.Hour, 10, shutdownTimeout)
+	ctx, cancel := context.WithCancel(context.Background())
+	go w.Start(ctx)
+
+	select {
+	case <-svc.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("batch did not start")
+	}
+	return w, cancel
+}
+
+func TestSchedulerWorker_Stop_FinishesInFlightBatch(t *testing.T) {
+	svc := newSlowSchedulerService(100 * time.Millisecond)
+	w, cancel := startWorker(t, svc, 2*time.Second)
+
+	// O shutdown cancela o ctx do worker e chama Stop, como o main faz
+	cancel()
+	w.Stop()
+
+	select {
+	case cancelled := <-svc.cancelled:
+		assert.False(t, cancelled, "in-flight batch should complete")
+	default:
+		t.Fatal("Stop returned before the batch finished")
+	}
+}
+
+func TestSchedulerWorker_Stop_AbortsBatchAfterDeadline(t *testing.T) {
+	svc := newSlowSchedulerService(time.Minute)
+	w, cancel := startWorker(t, svc, 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	w.Stop()
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	select {
+	case cancelled := <-svc.cancelled:
+		assert.True(t, cancelled, "batch should be cancelled so it releases its claims")
+	default:
+		t.Fatal("Stop returned before the batch was aborted")
+	}
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"event-coming/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// TokenPurgeWorker apaga periodicamente os tokens de autenticação vencidos
+type TokenPurgeWorker struct {
+	purgeService *service.TokenPurgeService
+	logger       *zap.Logger
+	interval     time.Duration
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+	wg           sync.WaitGroup
+}
+
+// NewTokenPurgeWorker cria um novo worker de limpeza de tokens
+func NewTokenPurgeWorker(purgeService *service.TokenPurgeService, logger *zap.Logger, interval time.Duration) *TokenPurgeWorker {
+	return &TokenPurgeWorker{
+		purgeService: purgeService,
+		logger:       logger,
+		interval:     interval,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start inicia o loop de limpeza; retorna logo se o intervalo não for positivo
+func (w *TokenPurgeWorker) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		w.logger.Info("Token purge worker disabled")
+		return
+	}
+
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	w.logger.Info("Token purge worker started", zap.Duration("interval", w.interval))
+
+	// Limpar imediatamente ao iniciar
+	w.purge(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Token purge worker stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("Token purge worker stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.purge(ctx)
+		}
+	}
+}
+
+// Stop para o worker e espera a limpeza em andamento terminar
+func (w *TokenPurgeWorker) Stop() {
+	w.stopOnce.Do(func() { close(w.stopCh) })
+	w.wg.Wait()
+
+	w.logger.Info("Token purge worker stopped")
+}
+
+// purge apaga os tokens vencidos
+func (w *TokenPurgeWorker) purge(ctx context.Context) {
+	start := time.Now()
+
+	result, err := w.purgeService.Purge(ctx, start)
+	if err != nil {
+		w.logger.Error("Failed to purge auth tokens", zap.Error(err))
+		return
+	}
+
+	if result.Total() > 0 {
+		w.logger.Info("Purged auth tokens",
+			zap.Int64("refresh_tokens", result.RefreshTokens),
+			zap.Int64("password_reset_tokens", result.PasswordResetTokens),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+}
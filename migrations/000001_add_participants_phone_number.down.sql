@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_participants_phone_trgm;
+DROP INDEX IF EXISTS idx_participants_phone_number;
+
+ALTER TABLE participants DROP COLUMN IF EXISTS phone_number;
@@ -0,0 +1,8 @@
+-- Normalized phone number (digits only) kept on the participant, used by /me/events and search.
+-- Existing rows are filled afterwards by `worker backfill-phone-numbers`.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS phone_number VARCHAR(20);
+
+CREATE INDEX IF NOT EXISTS idx_participants_phone_number ON participants (phone_number);
+CREATE INDEX IF NOT EXISTS idx_participants_phone_trgm ON participants USING gin (phone_number gin_trgm_ops);
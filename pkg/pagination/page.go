@@ -0,0 +1,46 @@
+package pagination
+
+import "sync/atomic"
+
+// Valores padrão de paginação por página; Configure os substitui a partir da config
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+var (
+	defaultPerPage atomic.Int64
+	maxPerPage     atomic.Int64
+)
+
+func init() {
+	defaultPerPage.Store(DefaultPerPage)
+	maxPerPage.Store(MaxPerPage)
+}
+
+// Configure sets the per_page used when the client sends none (or an invalid one) and
+// the largest per_page accepted. Non-positive values keep the current setting, and the
+// default never exceeds the max
+func Configure(defaultSize, maxSize int) {
+	if maxSize > 0 {
+		maxPerPage.Store(int64(maxSize))
+	}
+	if defaultSize > 0 {
+		defaultPerPage.Store(int64(defaultSize))
+	}
+	if defaultPerPage.Load() > maxPerPage.Load() {
+		defaultPerPage.Store(maxPerPage.Load())
+	}
+}
+
+// Normalize returns a valid page and per_page: page < 1 becomes 1, per_page < 1 becomes
+// the default and per_page above the max is capped at the max
+func Normalize(page, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = int(defaultPerPage.Load())
+	}
+	return page, min(perPage, int(maxPerPage.Load()))
+}
@@ -0,0 +1,61 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetLimits restores the default limits after a test that calls Configure
+func resetLimits(t *testing.T) {
+	t.Cleanup(func() { Configure(DefaultPerPage, MaxPerPage) })
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name        string
+		page        int
+		perPage     int
+		wantPage    int
+		wantPerPage int
+	}{
+		{name: "valid values are kept", page: 3, perPage: 50, wantPage: 3, wantPerPage: 50},
+		{name: "zero page", page: 0, perPage: 10, wantPage: 1, wantPerPage: 10},
+		{name: "negative page", page: -2, perPage: 10, wantPage: 1, wantPerPage: 10},
+		{name: "missing per_page uses the default", page: 1, perPage: 0, wantPage: 1, wantPerPage: DefaultPerPage},
+		{name: "negative per_page uses the default", page: 1, perPage: -5, wantPage: 1, wantPerPage: DefaultPerPage},
+		{name: "per_page at the max", page: 1, perPage: MaxPerPage, wantPage: 1, wantPerPage: MaxPerPage},
+		{name: "per_page above the max is capped", page: 1, perPage: MaxPerPage + 1, wantPage: 1, wantPerPage: MaxPerPage},
+		{name: "per_page of one", page: 1, perPage: 1, wantPage: 1, wantPerPage: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, perPage := Normalize(tt.page, tt.perPage)
+			assert.Equal(t, tt.wantPage, page)
+			assert.Equal(t, tt.wantPerPage, perPage)
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	resetLimits(t)
+
+	Configure(10, 50)
+	_, perPage := Normalize(1, 0)
+	assert.Equal(t, 10, perPage)
+	_, perPage = Normalize(1, 80)
+	assert.Equal(t, 50, perPage)
+
+	// Non-positive values keep the current setting
+	Configure(0, -1)
+	_, perPage = Normalize(1, 0)
+	assert.Equal(t, 10, perPage)
+	_, perPage = Normalize(1, 80)
+	assert.Equal(t, 50, perPage)
+
+	// The default never exceeds the max
+	Configure(40, 25)
+	_, perPage = Normalize(1, 0)
+	assert.Equal(t, 25, perPage)
+}
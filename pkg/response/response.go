@@ -1,6 +1,7 @@
 package response
 
 import (
+	"errors"
 	"net/http"
 
 	"event-coming/internal/domain"
@@ -17,15 +18,15 @@ type Response struct {
 
 // ErrorInfo represents error details
 type ErrorInfo struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
 	Details interface{} `json:"details,omitempty"`
 }
 
 // PaginatedResponse represents a paginated API response
 type PaginatedResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data"`
+	Success bool            `json:"success"`
+	Data    interface{}     `json:"data"`
 	Meta    *PaginationMeta `json:"meta"`
 }
 
@@ -35,6 +36,8 @@ type PaginationMeta struct {
 	PerPage    int   `json:"per_page"`
 	Total      int64 `json:"total"`
 	TotalPages int   `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+	HasPrev    bool  `json:"has_prev"`
 }
 
 // Success sends a successful response
@@ -69,9 +72,9 @@ func Error(c *gin.Context, statusCode int, code, message string) {
 	})
 }
 
-// ValidationError sends a validation error response
+// ValidationError sends a validation error response (422) with field-level details
 func ValidationError(c *gin.Context, details interface{}) {
-	c.JSON(http.StatusBadRequest, Response{
+	c.JSON(http.StatusUnprocessableEntity, Response{
 		Success: false,
 		Error: &ErrorInfo{
 			Code:    "validation_error",
@@ -81,45 +84,127 @@ func ValidationError(c *gin.Context, details interface{}) {
 	})
 }
 
-// HandleDomainError handles domain errors and sends appropriate responses
-func HandleDomainError(c *gin.Context, err error) {
-	switch err {
-	case domain.ErrNotFound:
-		Error(c, http.StatusNotFound, "not_found", "Resource not found")
-	case domain.ErrUnauthorized:
-		Error(c, http.StatusUnauthorized, "unauthorized", "Unauthorized")
-	case domain.ErrForbidden:
-		Error(c, http.StatusForbidden, "forbidden", "Forbidden")
-	case domain.ErrConflict:
-		Error(c, http.StatusConflict, "conflict", "Resource already exists")
-	case domain.ErrInvalidInput:
-		Error(c, http.StatusBadRequest, "invalid_input", "Invalid input")
-	case domain.ErrInvalidCredentials:
-		Error(c, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials")
-	case domain.ErrTokenExpired:
-		Error(c, http.StatusUnauthorized, "token_expired", "Token expired")
-	case domain.ErrInvalidToken:
-		Error(c, http.StatusUnauthorized, "invalid_token", "Invalid token")
-	default:
+// errorMapping is the HTTP response sent for a domain error
+type errorMapping struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+// domainErrors maps domain errors to HTTP responses. FromError matches them with
+// errors.Is, so errors wrapped with %w are mapped too.
+var domainErrors = []errorMapping{
+	{domain.ErrNotFound, http.StatusNotFound, "not_found", "Resource not found"},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, "unauthorized", "Unauthorized"},
+	{domain.ErrForbidden, http.StatusForbidden, "forbidden", "Forbidden"},
+	{domain.ErrConflict, http.StatusConflict, "conflict", "Resource already exists"},
+	{domain.ErrInvalidInput, http.StatusBadRequest, "invalid_input", "Invalid input"},
+	{domain.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials"},
+	{domain.ErrTokenExpired, http.StatusUnauthorized, "token_expired", "Token expired"},
+	{domain.ErrInvalidToken, http.StatusUnauthorized, "invalid_token", "Invalid token"},
+	{domain.ErrVersionConflict, http.StatusConflict, "version_conflict", "Resource was modified by another request"},
+	{domain.ErrLastOwner, http.StatusConflict, "last_owner", "Entity must keep at least one owner"},
+	{domain.ErrEventFull, http.StatusConflict, "event_full", "Event has reached its participant limit"},
+	{domain.ErrBatchTooLarge, http.StatusUnprocessableEntity, "batch_too_large", "Batch exceeds the maximum size"},
+	{domain.ErrDuplicateEvent, http.StatusConflict, "duplicate_event", "An event with the same name and start time already exists; set allow_duplicate to create it anyway"},
+	{domain.ErrParticipantNotPending, http.StatusConflict, "participant_not_pending", "Participant already confirmed or declined"},
+	{domain.ErrSchedulerNotPending, http.StatusConflict, "scheduler_not_pending", "Scheduler was already processed, failed or cancelled"},
+	{domain.ErrLocationConsentRequired, http.StatusForbidden, "location_consent_required", "Participant has not consented to location sharing"},
+	{domain.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge, "attachment_too_large", "Attachment exceeds the maximum size"},
+	{domain.ErrUnsupportedContentType, http.StatusUnsupportedMediaType, "unsupported_content_type", "Attachment content type is not allowed"},
+	{domain.ErrInviteClosed, http.StatusConflict, "invite_closed", "The event has ended or the participant already attended"},
+	{domain.ErrUnknownEventType, http.StatusUnprocessableEntity, "unknown_event_type", "Event type is not configured for the entity"},
+	{domain.ErrEventNotRecurring, http.StatusUnprocessableEntity, "event_not_recurring", "Event has no recurrence rule"},
+	{domain.ErrFeatureDisabled, http.StatusForbidden, "feature_disabled", "Feature is disabled for the event"},
+	{domain.ErrChannelUnavailable, http.StatusServiceUnavailable, "channel_unavailable", "Notification channel is not configured"},
+	{domain.ErrEncryptionUnavailable, http.StatusServiceUnavailable, "encryption_unavailable", "Secret encryption is not configured"},
+	{domain.ErrEventNotFinished, http.StatusConflict, "event_not_finished", "Event must be completed or cancelled first"},
+	{domain.ErrCheckInClosed, http.StatusConflict, "check_in_closed", "Check-in is only open around the event time"},
+	{domain.ErrTokenCheckUnavailable, http.StatusServiceUnavailable, "auth_unavailable", "Token could not be verified, try again later"},
+}
+
+func lookupError(err error) (errorMapping, bool) {
+	for _, m := range domainErrors {
+		if errors.Is(err, m.err) {
+			return m, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+// IsDomainError reports whether err (or an error it wraps) maps to a known HTTP status.
+// Handlers use it to log only unexpected errors.
+func IsDomainError(err error) bool {
+	_, ok := lookupError(err)
+	return ok
+}
+
+// StatusFromError returns the HTTP status FromError sends for err
+func StatusFromError(err error) int {
+	if m, ok := lookupError(err); ok {
+		return m.status
+	}
+	return http.StatusInternalServerError
+}
+
+// detailer is implemented by domain errors that carry data for the client
+// (e.g. the id of the conflicting resource)
+type detailer interface {
+	Details() any
+}
+
+// FromError sends the error response mapped from a domain error; anything else
+// becomes a 500 without leaking the error message
+func FromError(c *gin.Context, err error) {
+	m, ok := lookupError(err)
+	if !ok {
 		Error(c, http.StatusInternalServerError, "internal_error", "Internal server error")
+		return
+	}
+
+	info := &ErrorInfo{Code: m.code, Message: m.message}
+	var d detailer
+	if errors.As(err, &d) {
+		info.Details = d.Details()
 	}
+	c.JSON(m.status, Response{Success: false, Error: info})
 }
 
-// Paginated sends a paginated response
-func Paginated(c *gin.Context, data interface{}, page, perPage int, total int64) {
-	totalPages := int(total) / perPage
-	if int(total)%perPage != 0 {
-		totalPages++
+// CodeFromError returns the error code FromError sends for err ("internal_error" for
+// anything that is not a domain error), for results that report errors per item
+func CodeFromError(err error) string {
+	if m, ok := lookupError(err); ok {
+		return m.code
 	}
+	return "internal_error"
+}
 
+// Paginated sends a paginated response
+func Paginated(c *gin.Context, data interface{}, page, perPage int, total int64) {
 	c.JSON(http.StatusOK, PaginatedResponse{
 		Success: true,
 		Data:    data,
-		Meta: &PaginationMeta{
-			Page:       page,
-			PerPage:    perPage,
-			Total:      total,
-			TotalPages: totalPages,
-		},
+		Meta:    NewPaginationMeta(page, perPage, total),
 	})
 }
+
+// NewPaginationMeta computes the pagination metadata for a page of results
+func NewPaginationMeta(page, perPage int, total int64) *PaginationMeta {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = int(total) / perPage
+		if int(total)%perPage != 0 {
+			totalPages++
+		}
+	}
+
+	return &PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1 && totalPages > 0,
+	}
+}
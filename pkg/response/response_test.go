@@ -0,0 +1,134 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"event-coming/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPaginationMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    int
+		perPage int
+		total   int64
+		want    PaginationMeta
+	}{
+		{
+			name: "empty", page: 1, perPage: 20, total: 0,
+			want: PaginationMeta{Page: 1, PerPage: 20, Total: 0, TotalPages: 0},
+		},
+		{
+			name: "single partial page", page: 1, perPage: 20, total: 5,
+			want: PaginationMeta{Page: 1, PerPage: 20, Total: 5, TotalPages: 1},
+		},
+		{
+			name: "first of many", page: 1, perPage: 20, total: 45,
+			want: PaginationMeta{Page: 1, PerPage: 20, Total: 45, TotalPages: 3, HasNext: true},
+		},
+		{
+			name: "middle page", page: 2, perPage: 20, total: 45,
+			want: PaginationMeta{Page: 2, PerPage: 20, Total: 45, TotalPages: 3, HasNext: true, HasPrev: true},
+		},
+		{
+			name: "last page", page: 3, perPage: 20, total: 45,
+			want: PaginationMeta{Page: 3, PerPage: 20, Total: 45, TotalPages: 3, HasPrev: true},
+		},
+		{
+			name: "exact multiple", page: 2, perPage: 20, total: 40,
+			want: PaginationMeta{Page: 2, PerPage: 20, Total: 40, TotalPages: 2, HasPrev: true},
+		},
+		{
+			name: "past the end of an empty list", page: 2, perPage: 20, total: 0,
+			want: PaginationMeta{Page: 2, PerPage: 20, Total: 0, TotalPages: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, &tt.want, NewPaginationMeta(tt.page, tt.perPage, tt.total))
+		})
+	}
+}
+
+func TestFromError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{domain.ErrNotFound, http.StatusNotFound, "not_found"},
+		{domain.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+		{domain.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{domain.ErrConflict, http.StatusConflict, "conflict"},
+		{domain.ErrInvalidInput, http.StatusBadRequest, "invalid_input"},
+		{domain.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+		{domain.ErrTokenExpired, http.StatusUnauthorized, "token_expired"},
+		{domain.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+		{domain.ErrVersionConflict, http.StatusConflict, "version_conflict"},
+		{domain.ErrLastOwner, http.StatusConflict, "last_owner"},
+		{domain.ErrEventFull, http.StatusConflict, "event_full"},
+		{domain.ErrSchedulerNotPending, http.StatusConflict, "scheduler_not_pending"},
+		{domain.ErrLocationConsentRequired, http.StatusForbidden, "location_consent_required"},
+		{domain.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge, "attachment_too_large"},
+		{domain.ErrUnsupportedContentType, http.StatusUnsupportedMediaType, "unsupported_content_type"},
+		// Wrapped errors map like the error they wrap
+		{fmt.Errorf("event not found: %w", domain.ErrNotFound), http.StatusNotFound, "not_found"},
+		// Anything else is a 500 that doesn't leak the message
+		{errors.New("pq: connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			FromError(c, tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			var body Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.NotNil(t, body.Error)
+			assert.Equal(t, tt.code, body.Error.Code)
+			assert.NotContains(t, body.Error.Message, "pq:")
+
+			assert.Equal(t, tt.code, CodeFromError(tt.err))
+			assert.Equal(t, tt.status, StatusFromError(tt.err))
+			assert.Equal(t, tt.code != "internal_error", IsDomainError(tt.err))
+		})
+	}
+}
+
+func TestFromError_DuplicateEventDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	existingID := uuid.New()
+	FromError(c, fmt.Errorf("create event: %w", &domain.DuplicateEventError{ExistingEventID: existingID}))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details struct {
+				ExistingEventID uuid.UUID `json:"existing_event_id"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "duplicate_event", body.Error.Code)
+	assert.Equal(t, existingID, body.Error.Details.ExistingEventID)
+}
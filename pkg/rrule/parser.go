@@ -74,3 +74,25 @@ func (p *Parser) GenerateInstances(startTime time.Time, rrule string, until time
 
 	return instances, nil
 }
+
+// Exclude removes from instances the occurrences listed in exdates (RFC 5545 EXDATE)
+func Exclude(instances []time.Time, exdates []time.Time) []time.Time {
+	if len(exdates) == 0 {
+		return instances
+	}
+
+	kept := make([]time.Time, 0, len(instances))
+	for _, t := range instances {
+		excluded := false
+		for _, ex := range exdates {
+			if t.Equal(ex) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
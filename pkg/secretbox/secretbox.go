@@ -0,0 +1,72 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the key length in bytes (AES-256)
+const KeySize = 32
+
+// ErrMalformed is returned when a sealed value isn't valid base64 or is too short
+var ErrMalformed = errors.New("secretbox: malformed sealed value")
+
+// Box encrypts short secrets (tokens, passwords) for storage with AES-256-GCM. Sealed
+// values are base64 strings holding the random nonce followed by the ciphertext
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New creates a Box from a 32-byte key
+func New(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secretbox: key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// NewFromBase64 creates a Box from a base64 (standard encoding) 32-byte key, the format
+// used in the configuration
+func NewFromBase64(key string) (*Box, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("secretbox: key is not valid base64: %w", err)
+	}
+	return New(raw)
+}
+
+// Seal encrypts plaintext; sealing the same value twice gives different results
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal with the same key
+func (b *Box) Open(sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, ciphertext := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("secretbox: failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
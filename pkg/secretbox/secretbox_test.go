@@ -0,0 +1,55 @@
+package secretbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBox_SealOpen(t *testing.T) {
+	box, err := New(bytes.Repeat([]byte{1}, KeySize))
+	require.NoError(t, err)
+
+	first, err := box.Seal("EAAG-token")
+	require.NoError(t, err)
+	second, err := box.Seal("EAAG-token")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.NotContains(t, first, "EAAG-token")
+
+	plaintext, err := box.Open(first)
+	require.NoError(t, err)
+	assert.Equal(t, "EAAG-token", plaintext)
+}
+
+func TestBox_OpenFailures(t *testing.T) {
+	box, err := New(bytes.Repeat([]byte{1}, KeySize))
+	require.NoError(t, err)
+	other, err := New(bytes.Repeat([]byte{2}, KeySize))
+	require.NoError(t, err)
+
+	sealed, err := box.Seal("EAAG-token")
+	require.NoError(t, err)
+
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+
+	_, err = box.Open("not base64!")
+	assert.ErrorIs(t, err, ErrMalformed)
+	_, err = box.Open(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestNewFromBase64(t *testing.T) {
+	_, err := NewFromBase64(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize)))
+	assert.NoError(t, err)
+
+	_, err = NewFromBase64(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.Error(t, err)
+
+	_, err = NewFromBase64("not base64!")
+	assert.Error(t, err)
+}
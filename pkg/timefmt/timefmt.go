@@ -0,0 +1,66 @@
+package timefmt
+
+import (
+	"fmt"
+	"time"
+)
+
+// Idiomas suportados na formatação
+const (
+	LocalePT = "pt"
+	LocaleEN = "en"
+	LocaleES = "es"
+)
+
+// DefaultLocale is used for unknown or empty locales
+const DefaultLocale = LocalePT
+
+var weekdays = map[string][7]string{
+	LocalePT: {"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+	LocaleEN: {"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	LocaleES: {"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+}
+
+var months = [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// IsSupportedLocale reports whether locale has its own formatting
+func IsSupportedLocale(locale string) bool {
+	_, ok := weekdays[locale]
+	return ok
+}
+
+// LoadLocation returns the IANA time zone, falling back to UTC when name is empty or unknown
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Format renders t in loc for people reading the given locale, e.g.
+// "sáb, 14/06 às 14:00 (-03)" (pt) or "Sat, Jun 14, 14:00 (EDT)" (en)
+func Format(t time.Time, loc *time.Location, locale string) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if !IsSupportedLocale(locale) {
+		locale = DefaultLocale
+	}
+
+	t = t.In(loc)
+	zone, _ := t.Zone()
+	weekday := weekdays[locale][t.Weekday()]
+
+	switch locale {
+	case LocaleEN:
+		return fmt.Sprintf("%s, %s %d, %s (%s)", weekday, months[t.Month()-1], t.Day(), t.Format("15:04"), zone)
+	case LocaleES:
+		return fmt.Sprintf("%s, %s a las %s (%s)", weekday, t.Format("02/01"), t.Format("15:04"), zone)
+	default:
+		return fmt.Sprintf("%s, %s às %s (%s)", weekday, t.Format("02/01"), t.Format("15:04"), zone)
+	}
+}
@@ -0,0 +1,39 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	// Sábado, 14/06/2025 17:00 UTC
+	instant := time.Date(2025, time.June, 14, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		timezone string
+		locale   string
+		want     string
+	}{
+		{"pt in Sao Paulo", "America/Sao_Paulo", LocalePT, "sáb, 14/06 às 14:00 (-03)"},
+		{"en in New York", "America/New_York", LocaleEN, "Sat, Jun 14, 13:00 (EDT)"},
+		{"es in Madrid", "Europe/Madrid", LocaleES, "sáb, 14/06 a las 19:00 (CEST)"},
+		{"unknown locale falls back to pt", "America/Sao_Paulo", "fr", "sáb, 14/06 às 14:00 (-03)"},
+		{"unknown timezone falls back to UTC", "Mars/Olympus", LocaleEN, "Sat, Jun 14, 17:00 (UTC)"},
+		{"timezone changes the day", "Asia/Tokyo", LocaleEN, "Sun, Jun 15, 02:00 (JST)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Format(instant, LoadLocation(tt.timezone), tt.locale))
+		})
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	assert.Equal(t, time.UTC, LoadLocation(""))
+	assert.Equal(t, time.UTC, LoadLocation("Not/AZone"))
+	assert.Equal(t, "America/Sao_Paulo", LoadLocation("America/Sao_Paulo").String())
+}
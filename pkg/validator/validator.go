@@ -1,9 +1,12 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -12,28 +15,60 @@ var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New()
+	Validate.RegisterTagNameFunc(jsonTagName)
+
+	// gin validates `binding` tags with its own instance; report JSON names there too
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(jsonTagName)
+	}
+}
+
+// jsonTagName uses the JSON field name in error reports
+func jsonTagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
 }
 
 // ValidationError represents a validation error
 type ValidationError struct {
 	Field   string `json:"field"`
+	Rule    string `json:"rule"`
 	Message string `json:"message"`
 }
 
-// FormatValidationErrors formats validator errors into a slice of ValidationError
+// FormatValidationErrors formats validator errors into a slice of ValidationError.
+// Returns nil if err is not a validation error (e.g. malformed JSON).
 func FormatValidationErrors(err error) []ValidationError {
-	var errors []ValidationError
+	var result []ValidationError
 
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
 		for _, e := range validationErrors {
-			errors = append(errors, ValidationError{
-				Field:   strings.ToLower(e.Field()),
+			result = append(result, ValidationError{
+				Field:   fieldPath(e),
+				Rule:    e.Tag(),
 				Message: formatErrorMessage(e),
 			})
 		}
 	}
 
-	return errors
+	return result
+}
+
+// fieldPath returns the JSON path of the field without the root struct name
+// (e.g. "entity.name")
+func fieldPath(e validator.FieldError) string {
+	namespace := e.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return e.Field()
 }
 
 func formatErrorMessage(e validator.FieldError) string {